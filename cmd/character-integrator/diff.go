@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// diffOp is one line of an edit script
+type diffOp struct {
+	kind    byte // ' ' unchanged, '-' removed, '+' added
+	text    string
+	oldLine int // 1-based line number in the old text (0 for added lines)
+	newLine int // 1-based line number in the new text (0 for removed lines)
+}
+
+// unifiedDiff returns a unified diff between two texts, or "" when they are identical
+func unifiedDiff(oldName, newName, oldText, newText string) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are close enough to share context
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContextLines {
+				break
+			}
+		}
+
+		from := max(start-diffContextLines, 0)
+		to := min(end+diffContextLines, len(ops))
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&b, ops[from:to])
+		start = to
+	}
+
+	return b.String()
+}
+
+// writeHunk writes one @@ hunk for a slice of the edit script
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			if oldCount == 0 {
+				oldStart = op.oldLine
+			}
+			oldCount++
+		}
+		if op.kind != '-' {
+			if newCount == 0 {
+				newStart = op.newLine
+			}
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, op := range ops {
+		fmt.Fprintf(b, "%c%s\n", op.kind, op.text)
+	}
+}
+
+// hunkRange formats a start,count pair the way diff -u does
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// diffLines computes a line edit script using the longest common subsequence
+// Character files are small enough that the quadratic table is not a concern
+func diffLines(oldLines, newLines []string) []diffOp {
+	n, m := len(oldLines), len(newLines)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && oldLines[i] == newLines[j]:
+			ops = append(ops, diffOp{kind: ' ', text: oldLines[i], oldLine: i + 1, newLine: j + 1})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			// Removals come first on ties, so a replaced line reads - then + as in diff -u
+			ops = append(ops, diffOp{kind: '-', text: oldLines[i], oldLine: i + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', text: newLines[j], newLine: j + 1})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// splitLines splits text into lines without a trailing empty line
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns "line 1\n" through "line n\n"
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{
+			name:    "identical texts",
+			oldText: "a\nb\n",
+			newText: "a\nb\n",
+			want:    "",
+		},
+		{
+			name:    "change with context on both sides",
+			oldText: "a\nb\nc\nd\ne\nf\ng\nh\n",
+			newText: "a\nb\nc\nd\nE\nf\ng\nh\n",
+			want:    "--- old\n+++ new\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n",
+		},
+		{
+			name:    "lines added to an empty file",
+			oldText: "",
+			newText: "x\n",
+			want:    "--- old\n+++ new\n@@ -0,0 +1 @@\n+x\n",
+		},
+		{
+			name:    "appended key near the end",
+			oldText: "{\n  \"name\": \"Cat\"\n}\n",
+			newText: "{\n  \"name\": \"Cat\",\n  \"dialogBackend\": {}\n}\n",
+			want:    "--- old\n+++ new\n@@ -1,3 +1,4 @@\n {\n-  \"name\": \"Cat\"\n+  \"name\": \"Cat\",\n+  \"dialogBackend\": {}\n }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", tt.oldText, tt.newText); got != tt.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_Hunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed []int // 0-based lines of a 30-line file to change
+		hunks   int
+	}{
+		{"one change", []int{10}, 1},
+		{"changes sharing context", []int{10, 15}, 1},
+		{"distant changes", []int{2, 25}, 2},
+	}
+
+	oldLines := numberedLines(30)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newLines := append([]string(nil), oldLines...)
+			for _, line := range tt.changed {
+				newLines[line] += " (changed)"
+			}
+			diff := unifiedDiff("old", "new", strings.Join(oldLines, "\n")+"\n", strings.Join(newLines, "\n")+"\n")
+			if got := strings.Count(diff, "\n@@ "); got != tt.hunks {
+				t.Errorf("Expected %d hunks, got %d:\n%s", tt.hunks, got, diff)
+			}
+		})
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	ops := diffLines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+
+	var got []string
+	for _, op := range ops {
+		got = append(got, fmt.Sprintf("%c%s %d/%d", op.kind, op.text, op.oldLine, op.newLine))
+	}
+	want := []string{" a 1/1", "-b 2/0", " c 3/2", "+d 0/3"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected edit script %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// cardAvatarFile is where a PNG card's image is copied, serving as the character's still frame
+const cardAvatarFile = "avatar.png"
+
+// importedCharacter is the character.json written for an imported character card
+type importedCharacter struct {
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Animations    map[string]string      `json:"animations,omitempty"`
+	Dialogs       []Dialog               `json:"dialogs,omitempty"`
+	DialogBackend map[string]interface{} `json:"dialogBackend"`
+}
+
+// importCharacterCard converts a Character Card v2 JSON or PNG into character.json in
+// outputDir; a PNG card's image is kept as the character's idle and talking frame
+func importCharacterCard(cardPath, outputDir, userName string, settings integrationSettings, out io.Writer) error {
+	data, err := os.ReadFile(cardPath)
+	if err != nil {
+		return fmt.Errorf("failed to read character card: %w", err)
+	}
+	card, err := dialog.ParseCharacterCard(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cardPath, err)
+	}
+
+	target := filepath.Join(outputDir, "character.json")
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("%s already exists", target)
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputDir, err)
+	}
+
+	character, trainingLines := buildImportedCharacter(card, userName, settings)
+	if strings.EqualFold(filepath.Ext(cardPath), ".png") {
+		if err := os.WriteFile(filepath.Join(outputDir, cardAvatarFile), data, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", cardAvatarFile, err)
+		}
+		character.Animations = map[string]string{"idle": cardAvatarFile, "talking": cardAvatarFile}
+	}
+
+	encoded, err := json.MarshalIndent(character, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, append(encoded, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	fmt.Fprintf(out, "Imported %s into %s (%d training lines)\n", card.Name, target, trainingLines)
+	if character.Animations == nil {
+		fmt.Fprintf(out, "Add an \"animations\" section before loading the character\n")
+	}
+	return nil
+}
+
+// buildImportedCharacter maps the card onto character.json: the persona becomes the llm
+// systemPrompt, the character's example lines and greetings its training data, and the
+// greetings its click dialog; it also returns the number of training lines
+func buildImportedCharacter(card *dialog.CharacterCard, userName string, settings integrationSettings) (importedCharacter, int) {
+	greetings := card.Greetings(userName)
+	examples := card.Examples(userName)
+	for _, greeting := range greetings {
+		examples = append(examples, dialog.TrainingExample{Response: greeting})
+	}
+	trainingData := dialog.AppendTrainingData(nil, examples)
+	if len(trainingData) == 0 {
+		trainingData = generateDefaultTrainingData(map[string]interface{}{"name": card.Name})
+	}
+
+	llmConfig := createLLMBackendConfig(trainingData, settings)
+	llmConfig.SystemPrompt = card.Persona(userName)
+	llmConfigJSON, _ := json.Marshal(llmConfig)
+
+	dialogBackend := make(map[string]interface{})
+	setDialogBackendDefaults(dialogBackend)
+	backends := make(map[string]interface{})
+	addLLMBackendToConfig(backends, llmConfigJSON)
+	dialogBackend["backends"] = backends
+
+	// character.json descriptions are a one-line blurb; the full text is in the persona
+	description, _, _ := strings.Cut(card.Expand(card.Description, userName), "\n")
+	if description = strings.TrimSpace(description); description == "" {
+		description = "Imported from a character card"
+	}
+
+	character := importedCharacter{
+		Name:          card.Name,
+		Description:   description,
+		DialogBackend: dialogBackend,
+	}
+	if len(greetings) > 0 {
+		character.Dialogs = []Dialog{{Trigger: "click", Responses: greetings, Animation: "talking", Cooldown: 5}}
+	}
+	return character, len(trainingData)
+}
+
+// runImportCard handles --import-card <card> <output_dir> [--user NAME] [--config-template FILE]
+func runImportCard(args []string) {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "Option --import-card requires a card file and an output directory\n")
+		os.Exit(1)
+	}
+	cardPath, outputDir := args[0], args[1]
+
+	userName := "User"
+	integrator := NewCharacterAssetIntegrator("")
+	for i := 2; i < len(args); i++ {
+		if args[i] != "--user" && args[i] != "--config-template" {
+			fmt.Fprintf(os.Stderr, "Unknown option for --import-card: %s\n", args[i])
+			os.Exit(1)
+		}
+		if i+1 >= len(args) {
+			fmt.Fprintf(os.Stderr, "Option %s requires a value\n", args[i])
+			os.Exit(1)
+		}
+		i++
+		if args[i-1] == "--user" {
+			userName = args[i]
+			continue
+		}
+		if err := integrator.SetConfigTemplate(args[i]); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := importCharacterCard(cardPath, outputDir, userName, integrator.settings, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed: %v\n", err)
+		os.Exit(1)
+	}
+}
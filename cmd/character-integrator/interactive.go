@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// integrationSettings holds the per-file values written into the LLM configuration
+type integrationSettings struct {
+	model            string // Model alias from the models.json registry
+	modelPath        string // Model file, used instead of an alias
+	maxTokens        int
+	contextSize      int
+	keepTrainingData bool            // Reuse the character's existing dialog/Markov lines as training data
+	template         json.RawMessage // Optional --config-template values merged into every config
+}
+
+// Default model written into configs; the alias is used only when the user's models.json registers it
+const (
+	defaultModelAlias = "tinyllama-q4"
+	defaultModelPath  = "/models/tinyllama-1.1b-q4.gguf"
+)
+
+// defaultIntegrationSettings returns the values used when not running interactively
+func defaultIntegrationSettings() integrationSettings {
+	settings := integrationSettings{
+		modelPath:        defaultModelPath,
+		maxTokens:        50,
+		contextSize:      2048,
+		keepTrainingData: true,
+	}
+	if registryHasModel(defaultModelAlias) {
+		settings.setModel(defaultModelAlias)
+	}
+	return settings
+}
+
+// registryHasModel reports whether the default models.json registry defines alias
+func registryHasModel(alias string) bool {
+	path, err := dialog.DefaultModelRegistryPath()
+	if err != nil {
+		return false
+	}
+	registry, err := dialog.LoadModelRegistry(path)
+	if err != nil {
+		return false
+	}
+	_, exists := registry.Lookup(alias)
+	return exists
+}
+
+// setModel records a registry alias, or a model file when value is a path
+func (s *integrationSettings) setModel(value string) {
+	if strings.HasSuffix(value, ".gguf") || strings.Contains(value, "/") {
+		s.model, s.modelPath = "", value
+		return
+	}
+	s.model, s.modelPath = value, ""
+}
+
+// modelName returns the configured model alias or path
+func (s integrationSettings) modelName() string {
+	if s.modelPath != "" {
+		return s.modelPath
+	}
+	return s.model
+}
+
+// prompter asks questions on the terminal, returning defaults on empty input or EOF
+type prompter struct {
+	reader *bufio.Reader
+	out    io.Writer
+}
+
+// newPrompter creates a prompter reading answers from in and writing questions to out
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{reader: bufio.NewReader(in), out: out}
+}
+
+// readLine returns the trimmed answer, or "" on EOF
+func (p *prompter) readLine() string {
+	line, _ := p.reader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// askString prompts for a string value
+func (p *prompter) askString(label, defaultValue string) string {
+	fmt.Fprintf(p.out, "  %s [%s]: ", label, defaultValue)
+	if answer := p.readLine(); answer != "" {
+		return answer
+	}
+	return defaultValue
+}
+
+// askInt prompts for a positive integer, re-asking on invalid input
+func (p *prompter) askInt(label string, defaultValue int) int {
+	for {
+		fmt.Fprintf(p.out, "  %s [%d]: ", label, defaultValue)
+		answer := p.readLine()
+		if answer == "" {
+			return defaultValue
+		}
+		if value, err := strconv.Atoi(answer); err == nil && value > 0 {
+			return value
+		}
+		fmt.Fprintf(p.out, "  Please enter a positive number\n")
+	}
+}
+
+// askBool prompts for a yes/no answer, re-asking on invalid input
+func (p *prompter) askBool(label string, defaultValue bool) bool {
+	hint := "y/N"
+	if defaultValue {
+		hint = "Y/n"
+	}
+
+	for {
+		fmt.Fprintf(p.out, "  %s [%s]: ", label, hint)
+		switch strings.ToLower(p.readLine()) {
+		case "":
+			return defaultValue
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+		fmt.Fprintf(p.out, "  Please answer y or n\n")
+	}
+}
+
+// promptForFile asks for the settings to use for one character file
+// Answers become the defaults for the next file so batches stay quick
+func (c *CharacterAssetIntegrator) promptForFile(rawData map[string]interface{}) (integrationSettings, bool) {
+	name, _ := rawData["name"].(string)
+	if name == "" {
+		name = "(unnamed)"
+	}
+	existing := len(extractDialogResponses(rawData)) + len(extractMarkovTrainingData(rawData))
+
+	fmt.Printf("  Character: %s (%d existing dialog lines)\n", name, existing)
+	if !c.prompter.askBool("Add LLM configuration to this file?", true) {
+		return integrationSettings{}, false
+	}
+
+	settings := c.settings
+	settings.setModel(c.prompter.askString("Model (registry alias or .gguf path)", settings.modelName()))
+	settings.maxTokens = c.prompter.askInt("Max tokens per response", settings.maxTokens)
+	settings.contextSize = c.prompter.askInt("Context size", settings.contextSize)
+	if existing > 0 {
+		settings.keepTrainingData = c.prompter.askBool("Keep existing dialog lines as training data?", settings.keepTrainingData)
+	}
+
+	c.settings = settings
+	return settings, true
+}
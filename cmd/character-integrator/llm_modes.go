@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetUpdate makes the integrator replace existing llm backend blocks with freshly
+// generated configuration (from the defaults or --config-template) instead of skipping them
+func (c *CharacterAssetIntegrator) SetUpdate(update bool) {
+	c.update = update
+}
+
+// SetRemoveLLM makes the integrator strip the llm backend block from character files
+func (c *CharacterAssetIntegrator) SetRemoveLLM(remove bool) {
+	c.removeLLM = remove
+}
+
+// llmChange describes what a run does to a file's llm backend, for log messages
+type llmChange struct {
+	dryRun string // Printed instead of writing in dry run mode
+	done   string // Printed after the file is written
+}
+
+// LLM configuration changes made by the integrator modes
+var (
+	changeAdd     = llmChange{dryRun: "Would add LLM configuration", done: "Updated with LLM configuration"}
+	changeReplace = llmChange{dryRun: "Would replace LLM configuration", done: "Replaced LLM configuration"}
+	changeRemove  = llmChange{dryRun: "Would remove LLM configuration", done: "Removed LLM configuration"}
+)
+
+// removeCharacterLLMConfig strips the llm backend from one character file
+func (c *CharacterAssetIntegrator) removeCharacterLLMConfig(filePath string, rawData map[string]interface{}, originalData []byte, out io.Writer) error {
+	if !hasLLMConfig(rawData) {
+		fmt.Fprintf(out, "  No LLM config, skipping\n")
+		return errFileSkipped
+	}
+
+	if c.interactive && !c.prompter.askBool("Remove LLM configuration from this file?", true) {
+		fmt.Printf("  Skipped\n")
+		return errFileSkipped
+	}
+
+	removeLLMConfiguration(rawData)
+
+	if err := c.createBackupIfEnabled(filePath, originalData, out); err != nil {
+		return err
+	}
+	return c.writeUpdatedCharacterFile(filePath, rawData, originalData, changeRemove, out)
+}
+
+// removeLLMConfiguration deletes the llm backend and every reference to it
+// A default of "llm" moves to the first remaining fallback; a dialogBackend
+// left with no backends at all is removed
+func removeLLMConfiguration(data map[string]interface{}) {
+	dialogBackend := getDialogBackend(data)
+	backends := getBackendsConfig(dialogBackend)
+	delete(backends, "llm")
+
+	var remaining []string
+	if chain, ok := dialogBackend["fallbackChain"].([]interface{}); ok {
+		for _, entry := range chain {
+			if name, isString := entry.(string); isString && name != "llm" {
+				remaining = append(remaining, name)
+			}
+		}
+		if len(remaining) > 0 {
+			dialogBackend["fallbackChain"] = remaining
+		} else {
+			delete(dialogBackend, "fallbackChain")
+		}
+	}
+
+	if len(backends) == 0 {
+		delete(data, "dialogBackend")
+		return
+	}
+
+	if dialogBackend["defaultBackend"] == "llm" {
+		dialogBackend["defaultBackend"] = replacementDefaultBackend(remaining, backends)
+	}
+}
+
+// replacementDefaultBackend picks the first fallback that is still configured,
+// otherwise the first configured backend by name
+func replacementDefaultBackend(fallbackChain []string, backends map[string]interface{}) string {
+	for _, name := range fallbackChain {
+		if _, exists := backends[name]; exists {
+			return name
+		}
+	}
+
+	return sortedKeys(backends)[0]
+}
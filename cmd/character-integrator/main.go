@@ -2,11 +2,19 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/minilm/dialog"
 )
 
 // CharacterAssetIntegrator automatically adds LLM configuration to existing character files
@@ -14,8 +22,21 @@ type CharacterAssetIntegrator struct {
 	assetsPath    string
 	backupEnabled bool
 	dryRun        bool
+	validateOnly  bool
+	rollback      bool
+	cleanBackups  bool
+	filter        backupFilter // Limits --rollback and --clean-backups
+	update        bool         // Replace existing llm backend blocks instead of skipping them
+	removeLLM     bool         // Strip llm backend blocks instead of adding them
+	interactive   bool
+	jobs          int // Files processed concurrently (interactive mode always uses 1)
+	prompter      *prompter
+	settings      integrationSettings // Defaults for new LLM configuration; updated by interactive answers
 }
 
+// errFileSkipped marks a character file that was intentionally left unchanged
+var errFileSkipped = errors.New("file skipped")
+
 // CharacterJSON represents the structure of character configuration files
 type CharacterJSON struct {
 	Name          string               `json:"name"`
@@ -36,6 +57,7 @@ type Dialog struct {
 
 // DialogBackendConfig represents the dialog backend configuration
 type DialogBackendConfig struct {
+	SchemaVersion       int                        `json:"schemaVersion,omitempty"`
 	Enabled             bool                       `json:"enabled"`
 	DefaultBackend      string                     `json:"defaultBackend"`
 	FallbackChain       []string                   `json:"fallbackChain,omitempty"`
@@ -48,12 +70,14 @@ type DialogBackendConfig struct {
 
 // LLMBackendConfig represents LLM-specific backend configuration
 type LLMBackendConfig struct {
-	ModelPath        string            `json:"modelPath"`
+	Model            string            `json:"model,omitempty"`
+	ModelPath        string            `json:"modelPath,omitempty"`
 	MaxTokens        int               `json:"maxTokens"`
 	Temperature      float32           `json:"temperature"`
 	TopP             float32           `json:"topP"`
 	ContextSize      int               `json:"contextSize"`
 	Threads          int               `json:"threads"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
 	MarkovConfig     MarkovChainConfig `json:"markov_chain"`
 	MaxHistoryLength int               `json:"maxHistoryLength"`
 	TimeoutMs        int               `json:"timeoutMs"`
@@ -78,6 +102,8 @@ func NewCharacterAssetIntegrator(assetsPath string) *CharacterAssetIntegrator {
 		assetsPath:    assetsPath,
 		backupEnabled: true,
 		dryRun:        false,
+		jobs:          1,
+		settings:      defaultIntegrationSettings(),
 	}
 }
 
@@ -86,6 +112,19 @@ func (c *CharacterAssetIntegrator) SetDryRun(dryRun bool) {
 	c.dryRun = dryRun
 }
 
+// SetValidateOnly switches to schema validation, which never modifies files
+func (c *CharacterAssetIntegrator) SetValidateOnly(validateOnly bool) {
+	c.validateOnly = validateOnly
+}
+
+// SetInteractive enables or disables per-file prompts on the terminal
+func (c *CharacterAssetIntegrator) SetInteractive(interactive bool) {
+	c.interactive = interactive
+	if interactive && c.prompter == nil {
+		c.prompter = newPrompter(os.Stdin, os.Stdout)
+	}
+}
+
 // SetBackupEnabled enables or disables backup creation
 func (c *CharacterAssetIntegrator) SetBackupEnabled(enabled bool) {
 	c.backupEnabled = enabled
@@ -113,7 +152,23 @@ func (c *CharacterAssetIntegrator) processAllCharacterFiles(charactersPath strin
 		errorFiles:     []string{},
 	}
 
-	filepath.WalkDir(charactersPath, func(path string, d fs.DirEntry, err error) error {
+	files, _ := findCharacterFiles(charactersPath)
+	if c.jobs <= 1 || c.interactive {
+		for _, path := range files {
+			c.processFileAndTrackResults(path, results, os.Stdout)
+		}
+		return results
+	}
+
+	c.processFilesConcurrently(files, results)
+	return results
+}
+
+// findCharacterFiles returns all character.json files under the characters directory
+func findCharacterFiles(charactersPath string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(charactersPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -122,31 +177,46 @@ func (c *CharacterAssetIntegrator) processAllCharacterFiles(charactersPath strin
 			return nil
 		}
 
-		c.processFileAndTrackResults(path, results)
+		files = append(files, path)
 		return nil
 	})
 
-	return results
+	return files, err
 }
 
 // integrationResults tracks the results of processing character files
+// Safe for use by concurrent workers
 type integrationResults struct {
 	processedFiles []string
 	skippedFiles   []string
 	errorFiles     []string
+	mu             sync.Mutex
+}
+
+// record adds a file to the list matching its processing outcome
+func (r *integrationResults) record(path string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case errors.Is(err, errFileSkipped):
+		r.skippedFiles = append(r.skippedFiles, path)
+	case err != nil:
+		r.errorFiles = append(r.errorFiles, path)
+	default:
+		r.processedFiles = append(r.processedFiles, path)
+	}
 }
 
 // processFileAndTrackResults processes a single file and tracks the result
-func (c *CharacterAssetIntegrator) processFileAndTrackResults(path string, results *integrationResults) {
-	fmt.Printf("Processing: %s\n", path)
+func (c *CharacterAssetIntegrator) processFileAndTrackResults(path string, results *integrationResults, out io.Writer) {
+	fmt.Fprintf(out, "Processing: %s\n", path)
 
-	err := c.processCharacterFile(path)
-	if err != nil {
-		fmt.Printf("  Error: %v\n", err)
-		results.errorFiles = append(results.errorFiles, path)
-	} else {
-		results.processedFiles = append(results.processedFiles, path)
+	err := c.processCharacterFile(path, out)
+	if err != nil && !errors.Is(err, errFileSkipped) {
+		fmt.Fprintf(out, "  Error: %v\n", err)
 	}
+	results.record(path, err)
 }
 
 // printIntegrationSummary prints a summary of the integration results
@@ -157,6 +227,8 @@ func (c *CharacterAssetIntegrator) printIntegrationSummary(results *integrationR
 	fmt.Printf("Errors: %d files\n", len(results.errorFiles))
 
 	if len(results.errorFiles) > 0 {
+		// Concurrent workers finish in any order; keep the report stable
+		sort.Strings(results.errorFiles)
 		fmt.Printf("\nFiles with errors:\n")
 		for _, file := range results.errorFiles {
 			fmt.Printf("  - %s\n", file)
@@ -165,18 +237,35 @@ func (c *CharacterAssetIntegrator) printIntegrationSummary(results *integrationR
 }
 
 // processCharacterFile processes a single character.json file
-func (c *CharacterAssetIntegrator) processCharacterFile(filePath string) error {
+func (c *CharacterAssetIntegrator) processCharacterFile(filePath string, out io.Writer) error {
 	rawData, originalData, err := c.readAndParseCharacterFile(filePath)
 	if err != nil {
 		return err
 	}
 
+	if c.removeLLM {
+		return c.removeCharacterLLMConfig(filePath, rawData, originalData, out)
+	}
+
+	change := changeAdd
 	if hasLLMConfig(rawData) {
-		fmt.Printf("  Already has LLM config, skipping\n")
-		return nil
+		if !c.update {
+			fmt.Fprintf(out, "  Already has LLM config, skipping\n")
+			return errFileSkipped
+		}
+		change = changeReplace
 	}
 
-	err = c.updateCharacterWithLLMConfig(filePath, rawData, originalData)
+	settings := c.settings
+	if c.interactive {
+		var accepted bool
+		if settings, accepted = c.promptForFile(rawData); !accepted {
+			fmt.Printf("  Skipped\n")
+			return errFileSkipped
+		}
+	}
+
+	err = c.updateCharacterWithLLMConfig(filePath, rawData, originalData, settings, change, out)
 	if err != nil {
 		return err
 	}
@@ -200,50 +289,66 @@ func (c *CharacterAssetIntegrator) readAndParseCharacterFile(filePath string) (m
 }
 
 // updateCharacterWithLLMConfig adds LLM configuration and updates the file
-func (c *CharacterAssetIntegrator) updateCharacterWithLLMConfig(filePath string, rawData map[string]interface{}, originalData []byte) error {
+func (c *CharacterAssetIntegrator) updateCharacterWithLLMConfig(filePath string, rawData map[string]interface{}, originalData []byte, settings integrationSettings, change llmChange, out io.Writer) error {
 	personalityData := extractPersonalityData(rawData)
-	addLLMConfiguration(rawData, personalityData)
+	if !settings.keepTrainingData {
+		personalityData = limitTrainingDataSize(generateDefaultTrainingData(rawData), 5)
+	}
+	addLLMConfiguration(rawData, personalityData, settings)
 
-	err := c.createBackupIfEnabled(filePath, originalData)
+	err := c.createBackupIfEnabled(filePath, originalData, out)
 	if err != nil {
 		return err
 	}
 
-	return c.writeUpdatedCharacterFile(filePath, rawData)
+	return c.writeUpdatedCharacterFile(filePath, rawData, originalData, change, out)
 }
 
 // createBackupIfEnabled creates a backup file if backup is enabled
-func (c *CharacterAssetIntegrator) createBackupIfEnabled(filePath string, originalData []byte) error {
+// An existing backup is kept, so --rollback after repeated --update runs restores the original file
+func (c *CharacterAssetIntegrator) createBackupIfEnabled(filePath string, originalData []byte, out io.Writer) error {
 	if c.backupEnabled && !c.dryRun {
-		backupPath := filePath + ".backup"
+		backupPath := filePath + backupSuffix
+		if _, err := os.Stat(backupPath); err == nil {
+			fmt.Fprintf(out, "  Keeping existing backup: %s\n", backupPath)
+			return nil
+		}
 		if err := os.WriteFile(backupPath, originalData, 0644); err != nil {
 			return fmt.Errorf("failed to create backup: %w", err)
 		}
-		fmt.Printf("  Created backup: %s\n", backupPath)
+		fmt.Fprintf(out, "  Created backup: %s\n", backupPath)
 	}
 	return nil
 }
 
 // writeUpdatedCharacterFile writes the updated character data to file
-func (c *CharacterAssetIntegrator) writeUpdatedCharacterFile(filePath string, rawData map[string]interface{}) error {
-	if !c.dryRun {
-		updatedData, err := json.MarshalIndent(rawData, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal updated JSON: %w", err)
-		}
+// In dry run mode it prints a unified diff of the changes instead
+func (c *CharacterAssetIntegrator) writeUpdatedCharacterFile(filePath string, rawData map[string]interface{}, originalData []byte, change llmChange, out io.Writer) error {
+	updatedData, err := marshalPreservingFormat(rawData, originalData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated JSON: %w", err)
+	}
 
-		if err := os.WriteFile(filePath, updatedData, 0644); err != nil {
-			return fmt.Errorf("failed to write updated file: %w", err)
-		}
+	if c.dryRun {
+		fmt.Fprintf(out, "  %s (dry run)\n", change.dryRun)
+		printDryRunDiff(out, filePath, originalData, updatedData)
+		return nil
+	}
 
-		fmt.Printf("  Updated with LLM configuration\n")
-	} else {
-		fmt.Printf("  Would add LLM configuration (dry run)\n")
+	if err := os.WriteFile(filePath, updatedData, 0644); err != nil {
+		return fmt.Errorf("failed to write updated file: %w", err)
 	}
 
+	fmt.Fprintf(out, "  %s\n", change.done)
 	return nil
 }
 
+// printDryRunDiff prints the changes that would be written to a character file
+func printDryRunDiff(out io.Writer, filePath string, originalData, updatedData []byte) {
+	name := filepath.ToSlash(filePath)
+	fmt.Fprint(out, unifiedDiff("a/"+name, "b/"+name, string(originalData), string(updatedData)))
+}
+
 // hasLLMConfig checks if the character already has LLM configuration
 func hasLLMConfig(data map[string]interface{}) bool {
 	dialogBackend, exists := data["dialogBackend"]
@@ -485,8 +590,8 @@ func extractPersonalityData(data map[string]interface{}) []string {
 }
 
 // addLLMConfiguration adds LLM backend configuration to the character data
-func addLLMConfiguration(data map[string]interface{}, personalityData []string) {
-	llmConfig := createLLMBackendConfig(personalityData)
+func addLLMConfiguration(data map[string]interface{}, personalityData []string, settings integrationSettings) {
+	llmConfig := createLLMBackendConfig(personalityData, settings)
 	llmConfigJSON, _ := json.Marshal(llmConfig)
 
 	dialogBackend := getOrCreateDialogBackend(data)
@@ -500,13 +605,14 @@ func addLLMConfiguration(data map[string]interface{}, personalityData []string)
 }
 
 // createLLMBackendConfig creates a new LLM backend configuration with personality data
-func createLLMBackendConfig(personalityData []string) LLMBackendConfig {
-	return LLMBackendConfig{
-		ModelPath:        "/models/tinyllama-1.1b-q4.gguf",
-		MaxTokens:        50,
+func createLLMBackendConfig(personalityData []string, settings integrationSettings) LLMBackendConfig {
+	config := LLMBackendConfig{
+		Model:            settings.model,
+		ModelPath:        settings.modelPath,
+		MaxTokens:        settings.maxTokens,
 		Temperature:      0.8,
 		TopP:             0.9,
-		ContextSize:      2048,
+		ContextSize:      settings.contextSize,
 		Threads:          4,
 		MaxHistoryLength: 5,
 		TimeoutMs:        2000,
@@ -526,6 +632,17 @@ func createLLMBackendConfig(personalityData []string) LLMBackendConfig {
 			},
 		},
 	}
+
+	// Template values replace the defaults above; per-file settings and the
+	// character's own training data still take precedence
+	applyConfigTemplate(&config, settings.template)
+	config.Model = settings.model
+	config.ModelPath = settings.modelPath
+	config.MaxTokens = settings.maxTokens
+	config.ContextSize = settings.contextSize
+	config.MarkovConfig.TrainingData = personalityData
+
+	return config
 }
 
 // getOrCreateDialogBackend retrieves or creates the dialog backend configuration
@@ -545,6 +662,9 @@ func getOrCreateDialogBackend(data map[string]interface{}) map[string]interface{
 
 // setDialogBackendDefaults sets default values for dialog backend configuration
 func setDialogBackendDefaults(dialogBackend map[string]interface{}) {
+	if _, exists := dialogBackend["schemaVersion"]; !exists {
+		dialogBackend["schemaVersion"] = dialog.CurrentConfigSchemaVersion
+	}
 	if _, exists := dialogBackend["enabled"]; !exists {
 		dialogBackend["enabled"] = true
 	}
@@ -589,32 +709,149 @@ func addLLMBackendToConfig(backends map[string]interface{}, llmConfigJSON []byte
 
 func main() {
 	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <assets_path> [--dry-run] [--no-backup]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s <assets_path> [--dry-run] [--no-backup] [--interactive] [--validate] [--config-template <file>] [--jobs N] [--update|--remove-llm]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s <assets_path> --rollback|--clean-backups [--match <text>] [--since <date>] [--dry-run]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s --import-card <card.png|card.json> <output_dir> [--user NAME] [--config-template <file>]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nThis tool automatically adds LLM backend configuration to existing character.json files.\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
-		fmt.Fprintf(os.Stderr, "  --dry-run     Show what would be changed without modifying files\n")
-		fmt.Fprintf(os.Stderr, "  --no-backup   Don't create backup files (.backup)\n")
+		fmt.Fprintf(os.Stderr, "  --dry-run     Show a diff of what would be changed without modifying files\n")
+		fmt.Fprintf(os.Stderr, "  --no-backup   Don't create backup files (.backup); an existing backup is never overwritten\n")
+		fmt.Fprintf(os.Stderr, "  --interactive Prompt per file for model alias or path, token limits and training data\n")
+		fmt.Fprintf(os.Stderr, "  --validate    Check character files against the schema and report problems\n")
+		fmt.Fprintf(os.Stderr, "  --jobs N      Process N files concurrently (default: 1)\n")
+		fmt.Fprintf(os.Stderr, "  --config-template FILE  Merge LLM settings (model path, threads, timeouts) from a JSON file\n")
+		fmt.Fprintf(os.Stderr, "  --update      Replace existing LLM configuration with new defaults/template\n")
+		fmt.Fprintf(os.Stderr, "  --remove-llm  Remove the LLM backend configuration\n")
+		fmt.Fprintf(os.Stderr, "  --rollback    Restore character files from their backups and remove the backups\n")
+		fmt.Fprintf(os.Stderr, "  --clean-backups Delete backup files\n")
+		fmt.Fprintf(os.Stderr, "  --match TEXT  Only roll back or clean backups whose path contains TEXT\n")
+		fmt.Fprintf(os.Stderr, "  --since DATE  Only roll back or clean backups made on or after DATE (YYYY-MM-DD)\n")
+		fmt.Fprintf(os.Stderr, "  --import-card Convert a SillyTavern/TavernAI character card into a new character.json\n")
+		fmt.Fprintf(os.Stderr, "  --user NAME   Name that replaces {{user}} in an imported card (default: User)\n")
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  %s ./assets --dry-run\n", os.Args[0])
 		os.Exit(1)
 	}
 
+	if os.Args[1] == "--import-card" {
+		runImportCard(os.Args[2:])
+		return
+	}
+
 	assetsPath := os.Args[1]
 	integrator := NewCharacterAssetIntegrator(assetsPath)
 
 	// Parse command line options
+	var match string
+	var since time.Time
 	for i := 2; i < len(os.Args); i++ {
 		switch os.Args[i] {
 		case "--dry-run":
 			integrator.SetDryRun(true)
 		case "--no-backup":
 			integrator.SetBackupEnabled(false)
+		case "--interactive", "-i":
+			integrator.SetInteractive(true)
+		case "--validate":
+			integrator.SetValidateOnly(true)
+		case "--update":
+			integrator.SetUpdate(true)
+		case "--remove-llm":
+			integrator.SetRemoveLLM(true)
+		case "--rollback":
+			integrator.SetRollback(true)
+		case "--clean-backups":
+			integrator.SetCleanBackups(true)
+		case "--config-template":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Option %s requires a value\n", os.Args[i])
+				os.Exit(1)
+			}
+			i++
+			if err := integrator.SetConfigTemplate(os.Args[i]); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+		case "--jobs", "-j":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Option %s requires a value\n", os.Args[i])
+				os.Exit(1)
+			}
+			i++
+			jobs, err := strconv.Atoi(os.Args[i])
+			if err != nil || jobs < 1 {
+				fmt.Fprintf(os.Stderr, "Option --jobs must be a positive number, got %q\n", os.Args[i])
+				os.Exit(1)
+			}
+			integrator.SetJobs(jobs)
+		case "--match", "--since":
+			if i+1 >= len(os.Args) {
+				fmt.Fprintf(os.Stderr, "Option %s requires a value\n", os.Args[i])
+				os.Exit(1)
+			}
+			i++
+			if os.Args[i-1] == "--match" {
+				match = os.Args[i]
+				break
+			}
+			parsed, err := parseSinceDate(os.Args[i])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Option --since: %v\n", err)
+				os.Exit(1)
+			}
+			since = parsed
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown option: %s\n", os.Args[i])
 			os.Exit(1)
 		}
 	}
 
+	integrator.SetBackupFilter(match, since)
+
+	if integrator.rollback && integrator.cleanBackups {
+		fmt.Fprintf(os.Stderr, "Options --rollback and --clean-backups cannot be combined\n")
+		os.Exit(1)
+	}
+	if integrator.update && integrator.removeLLM {
+		fmt.Fprintf(os.Stderr, "Options --update and --remove-llm cannot be combined\n")
+		os.Exit(1)
+	}
+
+	if integrator.rollback || integrator.cleanBackups {
+		if integrator.dryRun {
+			fmt.Println("=== DRY RUN MODE - No files will be modified ===")
+		}
+
+		run, title, name := integrator.RollbackAll, "Character Backup Rollback", "Rollback"
+		if integrator.cleanBackups {
+			run, title, name = integrator.CleanBackups, "Character Backup Cleanup", "Backup cleanup"
+		}
+
+		fmt.Printf("%s\n", title)
+		fmt.Printf("Assets path: %s\n\n", assetsPath)
+
+		if err := run(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if integrator.validateOnly {
+		fmt.Printf("Character Schema Validation\n")
+		fmt.Printf("Assets path: %s\n\n", assetsPath)
+
+		invalid, err := integrator.ValidateAll()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Validation failed: %v\n", err)
+			os.Exit(1)
+		}
+		if invalid > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if integrator.dryRun {
 		fmt.Println("=== DRY RUN MODE - No files will be modified ===")
 	}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// jsonMember is one key/value pair of a JSON object with its byte offsets in the source
+type jsonMember struct {
+	key        string
+	value      json.RawMessage
+	start      int // Offset of the opening quote of the key
+	valueStart int
+	valueEnd   int
+}
+
+// marshalPreservingFormat encodes updated character data by patching the original
+// file: object keys keep their original order and spacing, new keys are appended,
+// and values that did not change are copied byte for byte, so version control
+// diffs only show the fields the integrator actually touched
+func marshalPreservingFormat(value interface{}, original []byte) ([]byte, error) {
+	unit := detectIndent(original)
+	encoded, err := encodePreserving(value, original, "", unit)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasSuffix(original, []byte("\n")) {
+		encoded = append(encoded, '\n')
+	}
+	return encoded, nil
+}
+
+// encodePreserving encodes value at the given indentation, reusing original where possible
+func encodePreserving(value interface{}, original json.RawMessage, prefix, unit string) ([]byte, error) {
+	original = bytes.TrimSpace(original)
+	if len(original) > 0 && jsonEqual(value, original) {
+		return original, nil
+	}
+
+	object, isMap := value.(map[string]interface{})
+	members, isObject := objectMembers(original)
+	if !isMap || !isObject {
+		return encodeIndented(value, prefix, unit)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	// Existing keys stay in place, each keeping the separator that preceded it
+	written := make(map[string]bool, len(object))
+	for i, member := range members {
+		newValue, exists := object[member.key]
+		if !exists {
+			continue
+		}
+
+		separator := original[1:members[0].start]
+		if len(written) > 0 {
+			separator = original[members[i-1].valueEnd:member.start]
+		}
+		encodedValue, err := encodePreserving(newValue, member.value, prefix+unit, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(separator)
+		buf.Write(original[member.start:member.valueStart])
+		buf.Write(encodedValue)
+		written[member.key] = true
+	}
+
+	// New keys are appended in sorted order
+	var added []string
+	for key := range object {
+		if !written[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, err := encodeIndented(key, "", "")
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := encodePreserving(object[key], nil, prefix+unit, unit)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.WriteString("\n" + prefix + unit)
+		buf.Write(encodedKey)
+		buf.WriteString(": ")
+		buf.Write(encodedValue)
+		written[key] = true
+	}
+
+	switch {
+	case len(written) == 0:
+		return []byte("{}"), nil
+	case len(members) > 0:
+		buf.Write(original[members[len(members)-1].valueEnd:])
+	default:
+		buf.WriteString("\n" + prefix + "}")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeIndented encodes a fresh value without escaping HTML characters in strings
+func encodeIndented(value interface{}, prefix, unit string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, unit)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// objectMembers returns the members of a raw JSON object in source order
+func objectMembers(raw json.RawMessage) ([]jsonMember, bool) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil {
+		return nil, false
+	}
+
+	var members []jsonMember
+	for decoder.More() {
+		previousEnd := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, _ := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		valueEnd := int(decoder.InputOffset())
+
+		members = append(members, jsonMember{
+			key:        key,
+			value:      value,
+			start:      previousEnd + bytes.IndexByte(raw[previousEnd:], '"'),
+			valueStart: valueEnd - len(value),
+			valueEnd:   valueEnd,
+		})
+	}
+
+	return members, true
+}
+
+// jsonEqual reports whether value encodes to the same JSON data as raw
+func jsonEqual(value interface{}, raw json.RawMessage) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	var a, b interface{}
+	if json.Unmarshal(encoded, &a) != nil || json.Unmarshal(raw, &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// detectIndent returns the indentation unit used by a JSON file (default: two spaces)
+func detectIndent(data []byte) string {
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\n' {
+			continue
+		}
+		j := i + 1
+		for j < len(data) && (data[j] == ' ' || data[j] == '\t') {
+			j++
+		}
+		if j > i+1 && j < len(data) && data[j] != '\n' && data[j] != '\r' {
+			return string(data[i+1 : j])
+		}
+	}
+	return "  "
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalPreservingFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		change   func(data map[string]interface{})
+		want     string
+	}{
+		{
+			name:     "unchanged file is copied byte for byte",
+			original: "{\n\t\"zeta\": 1,\n\t\"alpha\": {\"nested\": [1, 2, {\"deep\": \"caf\\u00e9 \\\"q\\\"\"}]},\n\t\"html\": \"\\u003cb\\u003e\"\n}\n",
+			change:   func(data map[string]interface{}) {},
+			want:     "{\n\t\"zeta\": 1,\n\t\"alpha\": {\"nested\": [1, 2, {\"deep\": \"caf\\u00e9 \\\"q\\\"\"}]},\n\t\"html\": \"\\u003cb\\u003e\"\n}\n",
+		},
+		{
+			name:     "nested change keeps key order and indentation",
+			original: "{\n    \"name\": \"Cat\",\n    \"dialogBackend\": {\n        \"enabled\": false,\n        \"backends\": {\"markov_chain\": {\"chainOrder\": 2}}\n    }\n}\n",
+			change: func(data map[string]interface{}) {
+				data["dialogBackend"].(map[string]interface{})["enabled"] = true
+			},
+			want: "{\n    \"name\": \"Cat\",\n    \"dialogBackend\": {\n        \"enabled\": true,\n        \"backends\": {\"markov_chain\": {\"chainOrder\": 2}}\n    }\n}\n",
+		},
+		{
+			name:     "new keys are appended sorted with the file's indentation",
+			original: "{\n\t\"name\": \"Cat\"\n}\n",
+			change: func(data map[string]interface{}) {
+				data["zed"] = "<3 \"quoted\""
+				data["alpha"] = map[string]interface{}{"x": 1}
+			},
+			want: "{\n\t\"name\": \"Cat\",\n\t\"alpha\": {\n\t\t\"x\": 1\n\t},\n\t\"zed\": \"<3 \\\"quoted\\\"\"\n}\n",
+		},
+		{
+			name:     "removed key keeps the surrounding separators",
+			original: "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3\n}",
+			change:   func(data map[string]interface{}) { delete(data, "b") },
+			want:     "{\n  \"a\": 1,\n  \"c\": 3\n}",
+		},
+		{
+			name:     "removed first key",
+			original: "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3\n}",
+			change:   func(data map[string]interface{}) { delete(data, "a") },
+			want:     "{\n  \"b\": 2,\n  \"c\": 3\n}",
+		},
+		{
+			name:     "every key removed",
+			original: "{\n  \"a\": 1\n}\n",
+			change:   func(data map[string]interface{}) { delete(data, "a") },
+			want:     "{}\n",
+		},
+		{
+			name:     "changed array is re-encoded at its depth",
+			original: "{\n  \"list\": [1, 2],\n  \"other\": [ 1 ]\n}\n",
+			change: func(data map[string]interface{}) {
+				data["list"] = append(data["list"].([]interface{}), 3.0)
+			},
+			want: "{\n  \"list\": [\n    1,\n    2,\n    3\n  ],\n  \"other\": [ 1 ]\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.original), &data); err != nil {
+				t.Fatalf("Invalid test input: %v", err)
+			}
+			tt.change(data)
+
+			got, err := marshalPreservingFormat(data, []byte(tt.original))
+			if err != nil {
+				t.Fatalf("marshalPreservingFormat failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+			if !jsonEqual(data, got) {
+				t.Errorf("Expected the output to decode to the updated data, got:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestDetectIndent(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"{\n  \"a\": 1\n}", "  "},
+		{"{\n\t\"a\": 1\n}", "\t"},
+		{"{\n    \"a\": {\n        \"b\": 1\n    }\n}", "    "},
+		{"{\"a\": 1}", "  "},
+		{"{\n\n   \n\"a\": 1\n}", "  "},
+	}
+	for _, tt := range tests {
+		if got := detectIndent([]byte(tt.data)); got != tt.want {
+			t.Errorf("detectIndent(%q): expected %q, got %q", tt.data, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupSuffix is appended to a character file's path when backing it up
+const backupSuffix = ".backup"
+
+// backupFilter limits rollback and cleanup to matching backups
+type backupFilter struct {
+	pathContains string    // Only backups whose path contains this substring (empty = all)
+	since        time.Time // Only backups modified at or after this time (zero = all)
+}
+
+// matches reports whether a backup passes the filter
+func (f backupFilter) matches(path string, modTime time.Time) bool {
+	if f.pathContains != "" && !strings.Contains(path, f.pathContains) {
+		return false
+	}
+	return f.since.IsZero() || !modTime.Before(f.since)
+}
+
+// SetBackupFilter restricts --rollback and --clean-backups to matching backups
+func (c *CharacterAssetIntegrator) SetBackupFilter(pathContains string, since time.Time) {
+	c.filter = backupFilter{pathContains: pathContains, since: since}
+}
+
+// SetRollback switches to restoring character files from their backups
+func (c *CharacterAssetIntegrator) SetRollback(rollback bool) {
+	c.rollback = rollback
+}
+
+// SetCleanBackups switches to deleting backup files
+func (c *CharacterAssetIntegrator) SetCleanBackups(clean bool) {
+	c.cleanBackups = clean
+}
+
+// RollbackAll restores every matching character file from its backup and removes the backup
+func (c *CharacterAssetIntegrator) RollbackAll() error {
+	backups, err := c.findMatchingBackups()
+	if err != nil {
+		return err
+	}
+
+	results := &integrationResults{}
+	for _, backupPath := range backups {
+		fmt.Printf("Restoring: %s\n", backupPath)
+		if err := c.restoreBackup(backupPath); err != nil {
+			fmt.Printf("  Error: %v\n", err)
+			results.errorFiles = append(results.errorFiles, backupPath)
+			continue
+		}
+		results.processedFiles = append(results.processedFiles, backupPath)
+	}
+
+	printBackupSummary("Rollback", "Restored", results)
+	return nil
+}
+
+// CleanBackups deletes every matching backup file
+func (c *CharacterAssetIntegrator) CleanBackups() error {
+	backups, err := c.findMatchingBackups()
+	if err != nil {
+		return err
+	}
+
+	results := &integrationResults{}
+	for _, backupPath := range backups {
+		fmt.Printf("Removing: %s\n", backupPath)
+		if c.dryRun {
+			fmt.Printf("  Would remove backup (dry run)\n")
+		} else if err := os.Remove(backupPath); err != nil {
+			fmt.Printf("  Error: %v\n", err)
+			results.errorFiles = append(results.errorFiles, backupPath)
+			continue
+		}
+		results.processedFiles = append(results.processedFiles, backupPath)
+	}
+
+	printBackupSummary("Backup Cleanup", "Removed", results)
+	return nil
+}
+
+// restoreBackup copies a backup over its character file, then deletes the backup
+func (c *CharacterAssetIntegrator) restoreBackup(backupPath string) error {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	// Refuse to replace a character file with something that is not JSON
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(data, &rawData); err != nil {
+		return fmt.Errorf("backup is not valid JSON: %w", err)
+	}
+
+	filePath := strings.TrimSuffix(backupPath, backupSuffix)
+	if c.dryRun {
+		fmt.Printf("  Would restore %s (dry run)\n", filePath)
+		return nil
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+	if err := os.Remove(backupPath); err != nil {
+		return fmt.Errorf("restored file but failed to remove backup: %w", err)
+	}
+
+	fmt.Printf("  Restored %s\n", filePath)
+	return nil
+}
+
+// findMatchingBackups returns character backups under the characters directory that pass the filter
+func (c *CharacterAssetIntegrator) findMatchingBackups() ([]string, error) {
+	charactersPath := filepath.Join(c.assetsPath, "characters")
+
+	if _, err := os.Stat(charactersPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("characters directory not found: %s", charactersPath)
+	}
+
+	var backups []string
+	err := filepath.WalkDir(charactersPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), "character.json"+backupSuffix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if c.filter.matches(path, info.ModTime()) {
+			backups = append(backups, path)
+		}
+		return nil
+	})
+
+	return backups, err
+}
+
+// printBackupSummary prints a summary of a rollback or cleanup run
+func printBackupSummary(title, verb string, results *integrationResults) {
+	fmt.Printf("\n=== %s Summary ===\n", title)
+	fmt.Printf("%s: %d files\n", verb, len(results.processedFiles))
+	fmt.Printf("Errors: %d files\n", len(results.errorFiles))
+
+	if len(results.errorFiles) > 0 {
+		fmt.Printf("\nFiles with errors:\n")
+		for _, file := range results.errorFiles {
+			fmt.Printf("  - %s\n", file)
+		}
+	}
+}
+
+// parseSinceDate parses a --since value as a date or RFC 3339 timestamp in local time
+func parseSinceDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD or RFC 3339)", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+const testCharacter = `{
+  "name": "Cat",
+  "dialogs": [
+    {"trigger": "click", "responses": ["Meow!", "Purr..."]}
+  ]
+}
+`
+
+// newTestAssets returns an empty assets directory, with no model registry in reach
+func newTestAssets(t *testing.T) string {
+	t.Helper()
+	t.Setenv(dialog.EnvModelRegistry, filepath.Join(t.TempDir(), "models.json"))
+	return t.TempDir()
+}
+
+// writeTestCharacter creates characters/<name>/character.json under assets
+func writeTestCharacter(t *testing.T, assets, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(assets, "characters", name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create character dir: %v", err)
+	}
+	path := filepath.Join(dir, "character.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write character: %v", err)
+	}
+	return path
+}
+
+// readFile returns a file's contents, failing the test when it cannot be read
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestRollbackAfterRepeatedUpdates(t *testing.T) {
+	assets := newTestAssets(t)
+	path := writeTestCharacter(t, assets, "cat", testCharacter)
+	integrator := NewCharacterAssetIntegrator(assets)
+
+	if err := integrator.processCharacterFile(path, io.Discard); err != nil {
+		t.Fatalf("Integration failed: %v", err)
+	}
+	integrated := readFile(t, path)
+	if integrated == testCharacter {
+		t.Fatal("Expected the integration to change the character file")
+	}
+
+	// A second --update run must not replace the backup of the original file
+	integrator.SetUpdate(true)
+	integrator.settings.maxTokens = 80
+	if err := integrator.processCharacterFile(path, io.Discard); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if readFile(t, path) == integrated {
+		t.Fatal("Expected the update to change the character file")
+	}
+	if backup := readFile(t, path+backupSuffix); backup != testCharacter {
+		t.Errorf("Expected the backup to keep the original file, got:\n%s", backup)
+	}
+
+	if err := integrator.RollbackAll(); err != nil {
+		t.Fatalf("RollbackAll failed: %v", err)
+	}
+	if restored := readFile(t, path); restored != testCharacter {
+		t.Errorf("Expected rollback to restore the original file, got:\n%s", restored)
+	}
+	if fileExists(path + backupSuffix) {
+		t.Error("Expected the backup removed after rollback")
+	}
+}
+
+func TestRollbackAfterRemoveLLM(t *testing.T) {
+	assets := newTestAssets(t)
+	path := writeTestCharacter(t, assets, "cat", testCharacter)
+	integrator := NewCharacterAssetIntegrator(assets)
+
+	if err := integrator.processCharacterFile(path, io.Discard); err != nil {
+		t.Fatalf("Integration failed: %v", err)
+	}
+	integrator.SetRemoveLLM(true)
+	if err := integrator.processCharacterFile(path, io.Discard); err != nil {
+		t.Fatalf("Removing the LLM config failed: %v", err)
+	}
+	if strings.Contains(readFile(t, path), `"llm"`) {
+		t.Errorf("Expected the llm backend removed, got:\n%s", readFile(t, path))
+	}
+
+	if err := integrator.RollbackAll(); err != nil {
+		t.Fatalf("RollbackAll failed: %v", err)
+	}
+	if restored := readFile(t, path); restored != testCharacter {
+		t.Errorf("Expected rollback to restore the original file, got:\n%s", restored)
+	}
+}
+
+func TestIntegrationModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(c *CharacterAssetIntegrator)
+		wantChange bool
+		wantBackup bool
+	}{
+		{"default", func(c *CharacterAssetIntegrator) {}, true, true},
+		{"no backup", func(c *CharacterAssetIntegrator) { c.SetBackupEnabled(false) }, true, false},
+		{"dry run", func(c *CharacterAssetIntegrator) { c.SetDryRun(true) }, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := newTestAssets(t)
+			path := writeTestCharacter(t, assets, "cat", testCharacter)
+			integrator := NewCharacterAssetIntegrator(assets)
+			tt.setup(integrator)
+
+			var out strings.Builder
+			if err := integrator.processCharacterFile(path, &out); err != nil {
+				t.Fatalf("Integration failed: %v", err)
+			}
+			if changed := readFile(t, path) != testCharacter; changed != tt.wantChange {
+				t.Errorf("Expected file changed = %v, got %v", tt.wantChange, changed)
+			}
+			if backup := fileExists(path + backupSuffix); backup != tt.wantBackup {
+				t.Errorf("Expected backup = %v, got %v", tt.wantBackup, backup)
+			}
+			if integrator.dryRun && !strings.Contains(out.String(), "+++ b/") {
+				t.Errorf("Expected the dry run to print a diff, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRollbackAll_Filter(t *testing.T) {
+	assets := newTestAssets(t)
+	cat := writeTestCharacter(t, assets, "cat", testCharacter)
+	dog := writeTestCharacter(t, assets, "dog", strings.Replace(testCharacter, "Cat", "Dog", 1))
+	integrator := NewCharacterAssetIntegrator(assets)
+	for _, path := range []string{cat, dog} {
+		if err := integrator.processCharacterFile(path, io.Discard); err != nil {
+			t.Fatalf("Integration failed: %v", err)
+		}
+	}
+
+	integrator.SetBackupFilter("dog", time.Time{})
+	if err := integrator.RollbackAll(); err != nil {
+		t.Fatalf("RollbackAll failed: %v", err)
+	}
+	if fileExists(dog+backupSuffix) || !strings.Contains(readFile(t, dog), `"Dog"`) || strings.Contains(readFile(t, dog), "dialogBackend") {
+		t.Errorf("Expected the matching character restored, got:\n%s", readFile(t, dog))
+	}
+	if !fileExists(cat + backupSuffix) {
+		t.Error("Expected the other character's backup kept")
+	}
+
+	// Backups made before --since are left alone
+	integrator.SetBackupFilter("", time.Now().Add(time.Hour))
+	if err := integrator.RollbackAll(); err != nil {
+		t.Fatalf("RollbackAll failed: %v", err)
+	}
+	if !fileExists(cat + backupSuffix) {
+		t.Error("Expected an older backup kept by --since")
+	}
+}
+
+func TestCleanBackups(t *testing.T) {
+	assets := newTestAssets(t)
+	path := writeTestCharacter(t, assets, "cat", testCharacter)
+	integrator := NewCharacterAssetIntegrator(assets)
+	if err := integrator.processCharacterFile(path, io.Discard); err != nil {
+		t.Fatalf("Integration failed: %v", err)
+	}
+	integrated := readFile(t, path)
+
+	integrator.SetDryRun(true)
+	if err := integrator.CleanBackups(); err != nil {
+		t.Fatalf("CleanBackups failed: %v", err)
+	}
+	if !fileExists(path + backupSuffix) {
+		t.Fatal("Expected a dry run to keep the backup")
+	}
+
+	integrator.SetDryRun(false)
+	if err := integrator.CleanBackups(); err != nil {
+		t.Fatalf("CleanBackups failed: %v", err)
+	}
+	if fileExists(path + backupSuffix) {
+		t.Error("Expected the backup removed")
+	}
+	if readFile(t, path) != integrated {
+		t.Error("Expected cleaning backups to leave the character file alone")
+	}
+}
+
+func TestRollbackAll_RejectsInvalidBackup(t *testing.T) {
+	assets := newTestAssets(t)
+	path := writeTestCharacter(t, assets, "cat", testCharacter)
+	if err := os.WriteFile(path+backupSuffix, []byte("not json"), 0o644); err != nil {
+		t.Fatalf("Failed to write backup: %v", err)
+	}
+
+	if err := NewCharacterAssetIntegrator(assets).RollbackAll(); err != nil {
+		t.Fatalf("RollbackAll failed: %v", err)
+	}
+	if readFile(t, path) != testCharacter || !fileExists(path+backupSuffix) {
+		t.Error("Expected an invalid backup to leave the character file and backup alone")
+	}
+}
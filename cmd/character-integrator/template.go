@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetConfigTemplate loads an LLM backend config template whose values are merged
+// into every generated configuration in place of the built-in defaults
+func (c *CharacterAssetIntegrator) SetConfigTemplate(path string) error {
+	template, err := loadConfigTemplate(path)
+	if err != nil {
+		return err
+	}
+
+	// Template values become the defaults shown by interactive prompts
+	settings := c.settings
+	config := createLLMBackendConfig(nil, settings)
+	applyConfigTemplate(&config, template)
+	settings.template = template
+	// Only the template's own model choice replaces the default model; an explicit
+	// modelPath takes precedence over an alias, as it does when the config is loaded
+	var model struct {
+		Model     string `json:"model"`
+		ModelPath string `json:"modelPath"`
+	}
+	json.Unmarshal(template, &model)
+	switch {
+	case model.ModelPath != "":
+		settings.setModel(model.ModelPath)
+	case model.Model != "":
+		settings.setModel(model.Model)
+	}
+	settings.maxTokens = config.MaxTokens
+	settings.contextSize = config.ContextSize
+
+	c.settings = settings
+	return nil
+}
+
+// loadConfigTemplate reads and checks a template file shaped like the llm backend block
+func loadConfigTemplate(path string) (json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config template: %w", err)
+	}
+
+	// Reject unknown keys so typos don't silently fall back to defaults
+	config := createLLMBackendConfig(nil, defaultIntegrationSettings())
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid config template %s: %w", path, err)
+	}
+
+	merged, _ := json.Marshal(config)
+	var llm map[string]interface{}
+	json.Unmarshal(merged, &llm)
+
+	v := &characterValidator{}
+	v.validateLLMBackend(llm)
+	if len(v.problems) > 0 {
+		messages := make([]string, len(v.problems))
+		for i, problem := range v.problems {
+			messages[i] = strings.TrimPrefix(problem.path, "dialogBackend.backends.llm.") + " " + problem.message
+		}
+		return nil, fmt.Errorf("invalid config template %s: %s", path, strings.Join(messages, "; "))
+	}
+
+	return json.RawMessage(data), nil
+}
+
+// applyConfigTemplate overlays template values onto a generated configuration
+// Nested objects such as markov_chain are merged field by field
+func applyConfigTemplate(config *LLMBackendConfig, template json.RawMessage) {
+	if len(template) == 0 {
+		return
+	}
+	// The template was validated when loaded, so decoding cannot fail here
+	json.Unmarshal(template, config)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetConfigTemplate_Model(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      string
+		wantModel     string
+		wantModelPath string
+	}{
+		{"alias only", `{"model": "phi-q4"}`, "phi-q4", ""},
+		{"path only", `{"modelPath": "/models/phi-2-q4.gguf"}`, "", "/models/phi-2-q4.gguf"},
+		{"path wins over alias", `{"model": "phi-q4", "modelPath": "/models/phi-2-q4.gguf"}`, "", "/models/phi-2-q4.gguf"},
+		{"no model keeps the default", `{"threads": 8}`, "", defaultModelPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assets := newTestAssets(t)
+			path := filepath.Join(t.TempDir(), "template.json")
+			if err := os.WriteFile(path, []byte(tt.template), 0o644); err != nil {
+				t.Fatalf("Failed to write template: %v", err)
+			}
+
+			integrator := NewCharacterAssetIntegrator(assets)
+			if err := integrator.SetConfigTemplate(path); err != nil {
+				t.Fatalf("SetConfigTemplate failed: %v", err)
+			}
+			config := createLLMBackendConfig(nil, integrator.settings)
+			if config.Model != tt.wantModel || config.ModelPath != tt.wantModelPath {
+				t.Errorf("Expected model %q and modelPath %q, got %q and %q",
+					tt.wantModel, tt.wantModelPath, config.Model, config.ModelPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// knownBackends lists the dialog backend names a character may reference: the llm
+// backend and the backends the host application provides
+var knownBackends = func() map[string]bool {
+	known := map[string]bool{"llm": true}
+	for _, name := range dialog.HostBackends() {
+		known[name] = true
+	}
+	return known
+}()
+
+// validationProblem describes one schema violation in a character file
+type validationProblem struct {
+	path    string // JSON path of the offending field, e.g. dialogs[2].trigger
+	message string
+}
+
+// characterValidator collects problems while checking a parsed character file
+type characterValidator struct {
+	problems []validationProblem
+}
+
+// addf records a problem at the given JSON path
+func (v *characterValidator) addf(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, validationProblem{path: path, message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateAll checks every character.json file against the schema without modifying anything
+// Returns the number of files with problems
+func (c *CharacterAssetIntegrator) ValidateAll() (int, error) {
+	charactersPath := filepath.Join(c.assetsPath, "characters")
+
+	if _, err := os.Stat(charactersPath); os.IsNotExist(err) {
+		return 0, fmt.Errorf("characters directory not found: %s", charactersPath)
+	}
+
+	files, err := findCharacterFiles(charactersPath)
+	if err != nil {
+		return 0, err
+	}
+
+	invalidFiles := 0
+	totalProblems := 0
+	for _, path := range files {
+		problems := validateCharacterFile(path)
+		if len(problems) == 0 {
+			fmt.Printf("OK:      %s\n", path)
+			continue
+		}
+
+		invalidFiles++
+		totalProblems += len(problems)
+		fmt.Printf("INVALID: %s\n", path)
+		for _, problem := range problems {
+			fmt.Printf("  - %s: %s\n", problem.path, problem.message)
+		}
+	}
+
+	fmt.Printf("\n=== Validation Summary ===\n")
+	fmt.Printf("Valid: %d files\n", len(files)-invalidFiles)
+	fmt.Printf("Invalid: %d files\n", invalidFiles)
+	fmt.Printf("Problems: %d\n", totalProblems)
+
+	return invalidFiles, nil
+}
+
+// validateCharacterFile reads a character file and returns every schema problem found
+func validateCharacterFile(path string) []validationProblem {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []validationProblem{{path: "(file)", message: fmt.Sprintf("failed to read file: %v", err)}}
+	}
+
+	var rawData map[string]interface{}
+	if err := json.Unmarshal(data, &rawData); err != nil {
+		return []validationProblem{{path: "(file)", message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	v := &characterValidator{}
+	v.validateCharacter(rawData)
+	return v.problems
+}
+
+// validateCharacter checks top-level character fields
+func (v *characterValidator) validateCharacter(data map[string]interface{}) {
+	v.requireString(data, "name", "name")
+	v.requireString(data, "description", "description")
+
+	animations, ok := v.object(data, "animations", "animations", true)
+	if ok {
+		if len(animations) == 0 {
+			v.addf("animations", "must define at least one animation")
+		}
+		for _, name := range sortedKeys(animations) {
+			if path, isString := animations[name].(string); !isString || path == "" {
+				v.addf("animations."+name, "must be a non-empty file path")
+			}
+		}
+	}
+
+	if dialogs, ok := v.array(data, "dialogs", "dialogs"); ok {
+		for i, dialog := range dialogs {
+			v.validateDialog(fmt.Sprintf("dialogs[%d]", i), dialog)
+		}
+	}
+
+	if behavior, ok := v.object(data, "behavior", "behavior", false); ok {
+		v.numberInRange(behavior, "idleTimeout", "behavior.idleTimeout", 0, -1)
+		v.numberInRange(behavior, "defaultSize", "behavior.defaultSize", 1, -1)
+	}
+
+	if dialogBackend, ok := v.object(data, "dialogBackend", "dialogBackend", false); ok {
+		v.validateDialogBackend(dialogBackend)
+	}
+}
+
+// validateDialog checks one entry of the dialogs array
+func (v *characterValidator) validateDialog(path string, value interface{}) {
+	dialog, ok := value.(map[string]interface{})
+	if !ok {
+		v.addf(path, "must be an object")
+		return
+	}
+
+	v.requireString(dialog, "trigger", path+".trigger")
+	v.numberInRange(dialog, "cooldown", path+".cooldown", 0, -1)
+
+	responses, ok := v.array(dialog, "responses", path+".responses")
+	if !ok {
+		if _, exists := dialog["responses"]; !exists {
+			v.addf(path+".responses", "is required")
+		}
+		return
+	}
+	if len(responses) == 0 {
+		v.addf(path+".responses", "must contain at least one response")
+	}
+	for i, response := range responses {
+		if text, isString := response.(string); !isString || strings.TrimSpace(text) == "" {
+			v.addf(fmt.Sprintf("%s.responses[%d]", path, i), "must be a non-empty string")
+		}
+	}
+}
+
+// validateDialogBackend checks backend selection and per-backend configuration
+func (v *characterValidator) validateDialogBackend(backend map[string]interface{}) {
+	enabled, _ := backend["enabled"].(bool)
+
+	defaultBackend, hasDefault := backend["defaultBackend"].(string)
+	if enabled && (!hasDefault || defaultBackend == "") {
+		v.addf("dialogBackend.defaultBackend", "is required when the dialog backend is enabled")
+	}
+	if defaultBackend != "" && !knownBackends[defaultBackend] {
+		v.addf("dialogBackend.defaultBackend", "unknown backend %q (known: %s)", defaultBackend, knownBackendList())
+	}
+
+	if chain, ok := v.array(backend, "fallbackChain", "dialogBackend.fallbackChain"); ok {
+		for i, entry := range chain {
+			path := fmt.Sprintf("dialogBackend.fallbackChain[%d]", i)
+			if name, isString := entry.(string); !isString {
+				v.addf(path, "must be a backend name")
+			} else if !knownBackends[name] {
+				v.addf(path, "unknown backend %q (known: %s)", name, knownBackendList())
+			}
+		}
+	}
+
+	v.numberInRange(backend, "confidenceThreshold", "dialogBackend.confidenceThreshold", 0, 1)
+	v.numberInRange(backend, "schemaVersion", "dialogBackend.schemaVersion", 0, dialog.CurrentConfigSchemaVersion)
+
+	backends, ok := v.object(backend, "backends", "dialogBackend.backends", false)
+	if !ok {
+		return
+	}
+	for _, name := range sortedKeys(backends) {
+		if !knownBackends[name] {
+			v.addf("dialogBackend.backends."+name, "unknown backend (known: %s)", knownBackendList())
+		}
+	}
+	if llm, ok := v.object(backends, "llm", "dialogBackend.backends.llm", false); ok {
+		v.validateLLMBackend(llm)
+	}
+}
+
+// validateLLMBackend checks the LLM backend configuration written by the integrator
+func (v *characterValidator) validateLLMBackend(llm map[string]interface{}) {
+	const prefix = "dialogBackend.backends.llm."
+
+	if _, hasModel := llm["model"]; hasModel {
+		v.requireString(llm, "model", prefix+"model")
+	} else {
+		v.requireString(llm, "modelPath", prefix+"modelPath")
+	}
+	if modelPath, ok := llm["modelPath"].(string); ok && modelPath != "" && !strings.HasSuffix(modelPath, ".gguf") {
+		v.addf(prefix+"modelPath", "must point to a .gguf model file")
+	}
+
+	v.numberInRange(llm, "maxTokens", prefix+"maxTokens", 1, 4096)
+	v.numberInRange(llm, "temperature", prefix+"temperature", 0, 2)
+	v.numberInRange(llm, "topP", prefix+"topP", 0, 1)
+	v.numberInRange(llm, "contextSize", prefix+"contextSize", 128, -1)
+	v.numberInRange(llm, "threads", prefix+"threads", 1, -1)
+	v.numberInRange(llm, "maxHistoryLength", prefix+"maxHistoryLength", 0, -1)
+	v.numberInRange(llm, "timeoutMs", prefix+"timeoutMs", 0, -1)
+}
+
+// requireString records a problem unless the field is a non-empty string
+func (v *characterValidator) requireString(data map[string]interface{}, key, path string) {
+	value, exists := data[key]
+	if !exists {
+		v.addf(path, "is required")
+		return
+	}
+	if text, ok := value.(string); !ok || strings.TrimSpace(text) == "" {
+		v.addf(path, "must be a non-empty string")
+	}
+}
+
+// object returns a nested object, recording a problem if it has the wrong type or is missing but required
+func (v *characterValidator) object(data map[string]interface{}, key, path string, required bool) (map[string]interface{}, bool) {
+	value, exists := data[key]
+	if !exists {
+		if required {
+			v.addf(path, "is required")
+		}
+		return nil, false
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		v.addf(path, "must be an object")
+	}
+	return object, ok
+}
+
+// array returns an optional array field, recording a problem if it has the wrong type
+func (v *characterValidator) array(data map[string]interface{}, key, path string) ([]interface{}, bool) {
+	value, exists := data[key]
+	if !exists {
+		return nil, false
+	}
+
+	array, ok := value.([]interface{})
+	if !ok {
+		v.addf(path, "must be an array")
+	}
+	return array, ok
+}
+
+// numberInRange checks an optional numeric field against min and max (max < 0 = unbounded)
+func (v *characterValidator) numberInRange(data map[string]interface{}, key, path string, min, max float64) {
+	value, exists := data[key]
+	if !exists {
+		return
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		v.addf(path, "must be a number")
+		return
+	}
+	if number < min || (max >= 0 && number > max) {
+		if max >= 0 {
+			v.addf(path, "must be between %g and %g, got %g", min, max, number)
+		} else {
+			v.addf(path, "must be at least %g, got %g", min, number)
+		}
+	}
+}
+
+// knownBackendList returns the known backend names for messages
+func knownBackendList() string {
+	names := make([]string, 0, len(knownBackends))
+	for name := range knownBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// sortedKeys returns map keys in a stable order for reproducible reports
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"sync"
+)
+
+// SetJobs sets how many character files are processed concurrently
+func (c *CharacterAssetIntegrator) SetJobs(jobs int) {
+	if jobs < 1 {
+		jobs = 1
+	}
+	c.jobs = jobs
+}
+
+// processFilesConcurrently processes files on a pool of c.jobs workers
+// Each file's log lines are buffered and written in one piece so output from
+// different files never interleaves
+func (c *CharacterAssetIntegrator) processFilesConcurrently(files []string, results *integrationResults) {
+	paths := make(chan string)
+	var outputMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < c.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out bytes.Buffer
+			for path := range paths {
+				out.Reset()
+				c.processFileAndTrackResults(path, results, &out)
+
+				outputMu.Lock()
+				os.Stdout.Write(out.Bytes())
+				outputMu.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range files {
+		paths <- path
+	}
+	close(paths)
+	wg.Wait()
+}
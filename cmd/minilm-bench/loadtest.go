@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// loadOptions holds the settings for -sessions load test mode
+type loadOptions struct {
+	sessions int
+	workers  int
+	duration time.Duration
+	think    time.Duration
+	triggers []string // Equal-weight mix; empty uses the library's default mix
+}
+
+// runLoadMode simulates many concurrent sessions and prints the report
+func runLoadMode(manager *dialog.DialogManager, opts loadOptions, jsonOutput bool, out io.Writer) error {
+	config := dialog.LoadTestConfig{
+		Sessions:    opts.sessions,
+		Workers:     opts.workers,
+		DurationMs:  int(opts.duration.Milliseconds()),
+		ThinkTimeMs: int(opts.think.Milliseconds()),
+	}
+	if len(opts.triggers) > 0 {
+		config.TriggerMix = make(map[string]int, len(opts.triggers))
+		for _, trigger := range opts.triggers {
+			config.TriggerMix[trigger] = 1
+		}
+	}
+
+	report, err := dialog.RunLoadTest(context.Background(), manager, config)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	printLoadReport(out, report, opts)
+	return nil
+}
+
+// printLoadReport writes a human-readable load test report
+func printLoadReport(out io.Writer, r dialog.LoadTestReport, opts loadOptions) {
+	workers := opts.workers
+	if workers <= 0 {
+		workers = opts.sessions
+	}
+	fmt.Fprintf(out, "\n=== Load Test Results ===\n")
+	fmt.Fprintf(out, "Sessions: %d (workers %d) for %.1fs\n", opts.sessions, workers, r.Duration.Seconds())
+	fmt.Fprintf(out, "Requests: %d (%.1f/sec), errors: %d, fallbacks: %d\n", r.Requests, r.Throughput, r.Errors, r.Fallbacks)
+	fmt.Fprintf(out, "\nLatency:\n")
+	fmt.Fprintf(out, "  p50: %.1fms\n", milliseconds(r.P50))
+	fmt.Fprintf(out, "  p95: %.1fms\n", milliseconds(r.P95))
+	fmt.Fprintf(out, "  p99: %.1fms\n", milliseconds(r.P99))
+	fmt.Fprintf(out, "\nTriggers:\n")
+	for _, trigger := range sortedCounts(r.Triggers) {
+		fmt.Fprintf(out, "  %-12s %d\n", trigger, r.Triggers[trigger])
+	}
+	fmt.Fprintf(out, "\nQueue and memory:\n")
+	fmt.Fprintf(out, "  peak queue depth: %d\n", r.PeakQueueDepth)
+	if len(r.Evictions) == 0 {
+		fmt.Fprintf(out, "  evictions:        none\n")
+	}
+	for _, reason := range sortedCounts(r.Evictions) {
+		fmt.Fprintf(out, "  evicted (%s): %d\n", reason, r.Evictions[reason])
+	}
+	fmt.Fprintf(out, "  heap start:       %.2fMB\n", float64(r.HeapStart)/(1<<20))
+	fmt.Fprintf(out, "  heap peak:        %.2fMB\n", float64(r.HeapPeak)/(1<<20))
+	fmt.Fprintf(out, "  heap end:         %.2fMB\n", float64(r.HeapEnd)/(1<<20))
+	fmt.Fprintf(out, "  heap growth:      %.2fMB\n", float64(r.HeapGrowth())/(1<<20))
+}
+
+// sortedCounts returns map keys in a stable order
+func sortedCounts(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
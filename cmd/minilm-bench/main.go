@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// benchOptions holds the command line settings for one benchmark run
+type benchOptions struct {
+	configPath  string
+	modelPath   string
+	iterations  int
+	warmup      int
+	concurrency int
+	triggers    []string
+	target      time.Duration
+	jsonOutput  bool
+}
+
+func main() {
+	var opts benchOptions
+	var load loadOptions
+	var triggers, replayPath, debugAddr string
+	flag.StringVar(&opts.configPath, "config", "", "character.json or LLM backend config JSON (default: built-in config)")
+	flag.StringVar(&opts.modelPath, "model", "", "GGUF model path, overriding the config's modelPath")
+	flag.IntVar(&opts.iterations, "n", 100, "Number of measured generations")
+	flag.IntVar(&opts.warmup, "warmup", 5, "Generations to run before measuring")
+	flag.IntVar(&opts.concurrency, "concurrency", 1, "Concurrent generation workers")
+	flag.StringVar(&triggers, "triggers", "click,feed,pet,talk,hover", "Comma-separated triggers to cycle through")
+	flag.DurationVar(&opts.target, "target", 500*time.Millisecond, "p95 latency target; the run fails if exceeded")
+	flag.BoolVar(&opts.jsonOutput, "json", false, "Print the report as JSON")
+	flag.IntVar(&load.sessions, "sessions", 0, "Run a load test simulating this many concurrent sessions instead of the benchmark")
+	flag.IntVar(&load.workers, "workers", 0, "Concurrent callers in load test mode (default: one per session)")
+	flag.DurationVar(&load.duration, "duration", 10*time.Second, "How long to generate load in load test mode")
+	flag.DurationVar(&load.think, "think", 0, "Pause between each worker's requests in load test mode")
+	flag.StringVar(&debugAddr, "debug-addr", "", "Serve /stats and /debug/pprof/ on this address while running, e.g. localhost:6060")
+	flag.StringVar(&replayPath, "replay", "", "Replay a session recorded with minilm-chat -record and compare the responses")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nRuns repeated generations and reports latency percentiles, tokens/sec,\n")
+		fmt.Fprintf(os.Stderr, "memory usage and fallback rate for the LLM backend. With -sessions it instead\n")
+		fmt.Fprintf(os.Stderr, "simulates many concurrent sessions and reports throughput, memory evictions,\n")
+		fmt.Fprintf(os.Stderr, "queue depth and heap growth. With -replay it re-runs a recorded session and\n")
+		fmt.Fprintf(os.Stderr, "shows which responses changed.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s -config assets/characters/default/character.json -model /models/tinyllama-1.1b-q4.gguf -n 200\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config assets/characters/default/character.json -sessions 500 -duration 30s\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -config assets/characters/default/character.json -model /models/new.gguf -replay session.jsonl\n", os.Args[0])
+	}
+	flag.Parse()
+
+	opts.triggers = strings.Split(triggers, ",")
+	if opts.iterations < 1 || opts.concurrency < 1 || opts.warmup < 0 || triggers == "" ||
+		load.sessions < 0 || load.workers < 0 || load.duration <= 0 || load.think < 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	config, err := loadLLMConfig(opts.configPath, opts.modelPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	manager, backend, err := setupBenchmark(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize backend: %v\n", err)
+		os.Exit(1)
+	}
+	modelType := "unknown"
+	if info, ok := backend.GetModelInfo(); ok {
+		modelType = info.ModelType
+	}
+	if modelType == mockModelType {
+		fmt.Fprintf(os.Stderr, "Warning: model %s did not load; measuring the mock model, not production inference\n", config.ModelPath)
+	}
+	defer backend.Close()
+
+	if debugAddr != "" {
+		server := &http.Server{
+			Addr:    debugAddr,
+			Handler: dialog.NewDebugHandler(manager, dialog.DebugHandlerOptions{Pprof: true}),
+		}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "Debug server: %v\n", err)
+			}
+		}()
+		defer server.Close()
+		fmt.Fprintf(os.Stderr, "Serving runtime stats at http://%s/stats and profiles at http://%s/debug/pprof/\n", debugAddr, debugAddr)
+	}
+
+	if replayPath != "" {
+		if err := runReplayMode(backend, replayPath, opts.jsonOutput, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Replay failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if load.sessions > 0 {
+		// Only pass -triggers through when given, so the default realistic mix applies otherwise
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "triggers" {
+				load.triggers = opts.triggers
+			}
+		})
+		if err := runLoadMode(manager, load, opts.jsonOutput, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Load test failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	report := runBenchmark(manager, opts)
+	report.setModel(config.ModelPath, modelType)
+
+	if opts.jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		report.print(os.Stdout)
+	}
+
+	if !report.TargetMet {
+		os.Exit(1)
+	}
+}
+
+// loadLLMConfig reads the llm backend block from a character file or a bare LLM config
+func loadLLMConfig(path, modelPath string) (dialog.LLMConfig, error) {
+	config := dialog.LLMConfig{
+		ModelPath: "/models/tinyllama-1.1b-q4.gguf",
+		MarkovConfig: dialog.MarkovChainConfig{
+			TrainingData: []string{
+				"Hello there! I'm so happy to see you again!",
+				"Thanks for spending time with me today.",
+				"What would you like to do together?",
+			},
+		},
+	}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, err
+		}
+
+		var character struct {
+			DialogBackend *struct {
+				Backends map[string]json.RawMessage `json:"backends"`
+			} `json:"dialogBackend"`
+		}
+		if err := json.Unmarshal(data, &character); err != nil {
+			return config, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		raw := json.RawMessage(data)
+		if character.DialogBackend != nil {
+			llm, exists := character.DialogBackend.Backends["llm"]
+			if !exists {
+				return config, fmt.Errorf("%s has no llm backend configured", path)
+			}
+			raw = llm
+		}
+		if err := json.Unmarshal(raw, &config); err != nil {
+			return config, fmt.Errorf("failed to parse LLM config: %w", err)
+		}
+	}
+
+	if modelPath != "" {
+		config.ModelPath = modelPath
+	}
+	return config, nil
+}
+
+// setupBenchmark creates a manager with only the LLM backend so every fallback is visible
+func setupBenchmark(config dialog.LLMConfig) (*dialog.DialogManager, *dialog.LLMBackend, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backend := dialog.NewLLMBackend()
+	if err := backend.Initialize(configJSON); err != nil {
+		return nil, nil, err
+	}
+
+	manager := dialog.NewDialogManager(false)
+	manager.RegisterBackend("llm", backend)
+	manager.SetDefaultBackend("llm")
+	return manager, backend, nil
+}
+
+// runBenchmark performs the warmup and measured generations and collects the report
+func runBenchmark(manager *dialog.DialogManager, opts benchOptions) benchReport {
+	for i := 0; i < opts.warmup; i++ {
+		manager.GenerateDialog(benchContext(opts.triggers, i, -1))
+	}
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	samples := make([]sample, opts.iterations)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for w := 0; w < opts.concurrency; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := range jobs {
+				context := benchContext(opts.triggers, i, worker)
+				began := time.Now()
+				response, err := manager.GenerateDialog(context)
+				samples[i] = sample{
+					latency:  time.Since(began),
+					tokens:   estimateTokens(response.Text),
+					fallback: err != nil || response.ResponseType == "fallback",
+				}
+			}
+		}(w)
+	}
+	for i := 0; i < opts.iterations; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	wall := time.Since(start)
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+
+	return newBenchReport(samples, wall, opts, before, after)
+}
+
+// benchContext builds a realistic dialog context, rotating triggers and mood
+// Each worker keeps its own conversation so history grows as in real use
+func benchContext(triggers []string, i, worker int) dialog.DialogContext {
+	return dialog.DialogContext{
+		Trigger:       triggers[i%len(triggers)],
+		InteractionID: fmt.Sprintf("bench-%d", worker),
+		Timestamp:     time.Now(),
+		CurrentStats: map[string]float64{
+			"happiness": 70,
+			"energy":    60,
+		},
+		CurrentMood:       float64(40 + (i*7)%60),
+		CurrentAnimation:  "idle",
+		RelationshipLevel: "friend",
+		ConversationTurn:  i + 1,
+		FallbackResponses: []string{"Hello!"},
+		FallbackAnimation: "talking",
+	}
+}
+
+// estimateTokens matches the library's rough four-characters-per-token estimate
+func estimateTokens(text string) int {
+	return len(text) / 4
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// runReplayMode re-runs a recorded session against the benchmark's backend and prints
+// how the responses changed
+func runReplayMode(backend dialog.DialogBackend, path string, jsonOutput bool, out io.Writer) error {
+	records, err := dialog.LoadSessionRecording(path)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%s has no recorded calls", path)
+	}
+
+	replay := dialog.ReplaySession(backend, records)
+	if jsonOutput {
+		data, _ := json.MarshalIndent(replay.Summary(), "", "  ")
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	fmt.Fprint(out, replay.Report())
+	return nil
+}
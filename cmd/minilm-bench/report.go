@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// sample is the outcome of one measured generation
+type sample struct {
+	latency  time.Duration
+	tokens   int
+	fallback bool
+}
+
+// benchReport summarizes a benchmark run
+type benchReport struct {
+	ModelPath    string  `json:"modelPath"`
+	ModelType    string  `json:"modelType"` // Model that served the run, "mock" when modelPath did not load
+	Iterations   int     `json:"iterations"`
+	Concurrency  int     `json:"concurrency"`
+	WallMs       float64 `json:"wallMs"`
+	MeanMs       float64 `json:"meanMs"`
+	P50Ms        float64 `json:"p50Ms"`
+	P95Ms        float64 `json:"p95Ms"`
+	P99Ms        float64 `json:"p99Ms"`
+	MaxMs        float64 `json:"maxMs"`
+	TokensPerSec float64 `json:"tokensPerSec"` // Per generation stream (tokens / time spent generating)
+	Throughput   float64 `json:"throughput"`   // Generations per second across all workers
+	FallbackRate float64 `json:"fallbackRate"`
+	HeapGrowthMB float64 `json:"heapGrowthMb"`
+	AllocPerOpKB float64 `json:"allocPerOpKb"`
+	SysMB        float64 `json:"sysMb"`
+	TargetMs     float64 `json:"targetMs"`
+	TargetMet    bool    `json:"targetMet"` // p95 latency within the target
+}
+
+// newBenchReport computes percentiles, rates and memory usage from the samples
+func newBenchReport(samples []sample, wall time.Duration, opts benchOptions, before, after runtime.MemStats) benchReport {
+	latencies := make([]time.Duration, len(samples))
+	var total time.Duration
+	tokens, fallbacks := 0, 0
+	for i, s := range samples {
+		latencies[i] = s.latency
+		total += s.latency
+		tokens += s.tokens
+		if s.fallback {
+			fallbacks++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	n := float64(len(samples))
+	report := benchReport{
+		Iterations:   len(samples),
+		Concurrency:  opts.concurrency,
+		WallMs:       milliseconds(wall),
+		MeanMs:       milliseconds(total) / n,
+		P50Ms:        milliseconds(percentile(latencies, 50)),
+		P95Ms:        milliseconds(percentile(latencies, 95)),
+		P99Ms:        milliseconds(percentile(latencies, 99)),
+		MaxMs:        milliseconds(latencies[len(latencies)-1]),
+		Throughput:   n / wall.Seconds(),
+		FallbackRate: float64(fallbacks) / n,
+		HeapGrowthMB: (float64(after.HeapAlloc) - float64(before.HeapAlloc)) / (1 << 20),
+		AllocPerOpKB: float64(after.TotalAlloc-before.TotalAlloc) / n / 1024,
+		SysMB:        float64(after.Sys) / (1 << 20),
+		TargetMs:     milliseconds(opts.target),
+	}
+	if total > 0 {
+		report.TokensPerSec = float64(tokens) / total.Seconds()
+	}
+	report.TargetMet = report.P95Ms <= report.TargetMs
+
+	return report
+}
+
+// mockModelType is the ModelInfo.ModelType of the backend's fallback mock model
+const mockModelType = "mock"
+
+// setModel records the model that served the run; a mock model says nothing about
+// production latency, so the target is never met with it
+func (r *benchReport) setModel(path, modelType string) {
+	r.ModelPath = path
+	r.ModelType = modelType
+	if modelType == mockModelType {
+		r.TargetMet = false
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// print writes a human-readable report
+func (r benchReport) print(out io.Writer) {
+	fmt.Fprintf(out, "\n=== Benchmark Results ===\n")
+	if r.ModelType == mockModelType {
+		fmt.Fprintf(out, "Model: mock (modelPath %s not loaded)\n", r.ModelPath)
+	} else {
+		fmt.Fprintf(out, "Model: %s (%s)\n", r.ModelPath, r.ModelType)
+	}
+	fmt.Fprintf(out, "Generations: %d (concurrency %d) in %.0fms\n", r.Iterations, r.Concurrency, r.WallMs)
+	fmt.Fprintf(out, "\nLatency:\n")
+	fmt.Fprintf(out, "  mean: %.1fms\n", r.MeanMs)
+	fmt.Fprintf(out, "  p50:  %.1fms\n", r.P50Ms)
+	fmt.Fprintf(out, "  p95:  %.1fms\n", r.P95Ms)
+	fmt.Fprintf(out, "  p99:  %.1fms\n", r.P99Ms)
+	fmt.Fprintf(out, "  max:  %.1fms\n", r.MaxMs)
+	fmt.Fprintf(out, "\nThroughput:\n")
+	fmt.Fprintf(out, "  tokens/sec:      %.1f (estimated)\n", r.TokensPerSec)
+	fmt.Fprintf(out, "  generations/sec: %.1f\n", r.Throughput)
+	fmt.Fprintf(out, "  fallback rate:   %.1f%%\n", r.FallbackRate*100)
+	fmt.Fprintf(out, "\nMemory:\n")
+	fmt.Fprintf(out, "  heap growth:     %.2fMB\n", r.HeapGrowthMB)
+	fmt.Fprintf(out, "  allocated/op:    %.1fKB\n", r.AllocPerOpKB)
+	fmt.Fprintf(out, "  process (sys):   %.1fMB\n", r.SysMB)
+
+	status := "PASS"
+	switch {
+	case r.ModelType == mockModelType:
+		status = "NOT MET (mock model, production latency not measured)"
+	case !r.TargetMet:
+		status = "FAIL"
+	}
+	fmt.Fprintf(out, "\nTarget p95 <= %.0fms: %s\n", r.TargetMs, status)
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// characterFile is the subset of character.json used by the chat REPL
+type characterFile struct {
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	Dialogs       []dialogEntry   `json:"dialogs"`
+	DialogBackend json.RawMessage `json:"dialogBackend"`
+}
+
+// dialogEntry is a scripted dialog from character.json, used for fallback lines
+type dialogEntry struct {
+	Trigger   string   `json:"trigger"`
+	Responses []string `json:"responses"`
+	Animation string   `json:"animation"`
+}
+
+func main() {
+	sessionID := flag.String("session", "minilm-chat", "InteractionID used for conversation memory")
+	debug := flag.Bool("debug", false, "Enable dialog manager debug logging and show response metadata")
+	typing := flag.Float64("typing", 0, "Reveal responses at this many characters per second (0 = instantly)")
+	evolveMood := flag.Bool("mood", false, "Evolve mood from triggers, feedback and time with a mood engine")
+	statePath := flag.String("state", "", "Restore conversation memory from this file at start and save it on exit")
+	recordPath := flag.String("record", "", "Append every backend call to this JSONL session recording (see minilm-bench -replay)")
+	overrides, err := dialog.EnvConfigOverrides(os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid environment: %v\n", err)
+		os.Exit(1)
+	}
+	overrides.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <character.json>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nLoads a character, initializes its dialog backends and starts an interactive chat.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s assets/characters/default/character.json\n", os.Args[0])
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	character, err := loadCharacter(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load character: %v\n", err)
+		os.Exit(1)
+	}
+
+	manager, err := setupDialogManager(character, overrides, *debug)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up dialog backends: %v\n", err)
+		os.Exit(1)
+	}
+
+	manager.Use(dialog.TemporalContext())
+	if *evolveMood {
+		engine, err := dialog.NewMoodEngine(dialog.MoodConfig{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create mood engine: %v\n", err)
+			os.Exit(1)
+		}
+		manager.SetMoodEngine(engine)
+	}
+
+	if key, ok := os.LookupEnv(dialog.EnvStateKey); ok {
+		if err := setStateKey(manager, key); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid %s: %v\n", dialog.EnvStateKey, err)
+			os.Exit(1)
+		}
+	}
+	if *statePath != "" {
+		if err := loadState(manager, *statePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to restore state: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *recordPath != "" {
+		recorder, err := dialog.CreateSessionRecording(*recordPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start recording: %v\n", err)
+			os.Exit(1)
+		}
+		manager.SetSessionRecorder(recorder)
+		defer func() {
+			if err := recorder.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Recording: %v\n", err)
+			}
+		}()
+	}
+
+	session := newChatSession(manager, character, *sessionID, *debug)
+	session.memory = dialog.NewUserMemory()
+	manager.SetUserMemory(session.memory)
+	if *typing > 0 {
+		pacer, err := dialog.NewPacer(dialog.PacingConfig{CharsPerSecond: *typing, Jitter: 0.3, PunctuationPauseMs: 250})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -typing: %v\n", err)
+			os.Exit(1)
+		}
+		session.pacer = pacer
+	}
+	session.run(os.Stdin, os.Stdout)
+
+	if *statePath != "" {
+		if err := saveState(manager, *statePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to save state: %v\n", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := manager.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Shutdown: %v\n", err)
+	}
+}
+
+// setStateKey encrypts the -state file with a hex-encoded AES key
+func setStateKey(manager *dialog.DialogManager, encoded string) error {
+	key, err := dialog.ParseStateKey(encoded)
+	if err != nil {
+		return err
+	}
+	return manager.SetStateEncryptionKey(key)
+}
+
+// loadState restores a checkpoint written by saveState; a missing file means a fresh start
+func loadState(manager *dialog.DialogManager, path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return manager.LoadState(data)
+}
+
+// saveState checkpoints the dialog state so the next run remembers this conversation
+func saveState(manager *dialog.DialogManager, path string) error {
+	data, err := manager.SaveState()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// loadCharacter reads a character file and checks it has a dialog backend configuration
+func loadCharacter(path string) (*characterFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var character characterFile
+	if err := json.Unmarshal(data, &character); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(character.DialogBackend) == 0 {
+		return nil, fmt.Errorf("%s has no dialogBackend section (add one with character-integrator)", path)
+	}
+	if character.Name == "" {
+		character.Name = "Character"
+	}
+
+	return &character, nil
+}
+
+// setupDialogManager initializes and registers every backend the character configures
+// Backends this module does not implement are reported and skipped
+func setupDialogManager(character *characterFile, overrides dialog.ConfigOverrides, debug bool) (*dialog.DialogManager, error) {
+	config, err := dialog.LoadDialogBackendConfigWithOverrides(character.DialogBackend, overrides)
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, fmt.Errorf("dialogBackend is disabled for %s", character.Name)
+	}
+
+	manager := dialog.NewDialogManager(debug)
+	for name, raw := range config.Backends {
+		backend, ok := newBackend(name)
+		if !ok {
+			fmt.Printf("Skipping backend %q: not provided by minilm\n", name)
+			continue
+		}
+		if err := backend.Initialize(raw); err != nil {
+			return nil, fmt.Errorf("failed to initialize backend %q: %w", name, err)
+		}
+		if reporter, ok := backend.(dialog.HardwareFitReporter); ok {
+			if report, checked := reporter.HardwareFit(); checked {
+				for _, warning := range report.Warnings {
+					fmt.Printf("Warning (%s): %s\n", name, warning.Message)
+				}
+			}
+		}
+		manager.RegisterBackend(name, backend)
+	}
+
+	registered := make(map[string]bool)
+	for _, name := range manager.GetRegisteredBackends() {
+		registered[name] = true
+	}
+
+	// Use the configured default when available, else the first usable fallback
+	defaultBackend := ""
+	for _, name := range append([]string{config.DefaultBackend}, config.FallbackChain...) {
+		if registered[name] {
+			defaultBackend = name
+			break
+		}
+	}
+	if defaultBackend == "" && registered["llm"] {
+		defaultBackend = "llm"
+	}
+	if defaultBackend == "" {
+		return nil, fmt.Errorf("none of the configured backends are available")
+	}
+	if defaultBackend != config.DefaultBackend {
+		fmt.Printf("Default backend %q is not available, using %q\n", config.DefaultBackend, defaultBackend)
+	}
+	if err := manager.SetDefaultBackend(defaultBackend); err != nil {
+		return nil, err
+	}
+
+	var chain []string
+	for _, name := range config.FallbackChain {
+		if registered[name] && name != defaultBackend {
+			chain = append(chain, name)
+		}
+	}
+	manager.SetFallbackChain(chain)
+
+	routes := make(map[string]string)
+	for trigger, name := range config.TriggerRoutes {
+		if !registered[name] {
+			fmt.Printf("Skipping route %q -> %q: backend not available\n", trigger, name)
+			continue
+		}
+		routes[trigger] = name
+	}
+	if err := manager.SetTriggerRoutes(routes); err != nil {
+		return nil, err
+	}
+	if name := config.OverloadBackend; name != "" {
+		if !registered[name] {
+			fmt.Printf("Skipping overload backend %q: backend not available\n", name)
+		} else if err := manager.SetOverloadBackend(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ConfidenceThreshold > 0 {
+		if err := manager.SetConfidenceThreshold(config.ConfidenceThreshold); err != nil {
+			return nil, err
+		}
+	}
+	if err := manager.ApplyTimeoutConfig(config); err != nil {
+		return nil, err
+	}
+
+	return manager, nil
+}
+
+// newBackend creates an uninitialized backend for a configured backend name
+func newBackend(name string) (dialog.DialogBackend, bool) {
+	switch name {
+	case "llm":
+		return dialog.NewLLMBackend(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,516 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// chatSession holds the simulated character state sent with every trigger
+type chatSession struct {
+	manager      *dialog.DialogManager
+	character    *characterFile
+	baseID       string
+	session      int // Incremented by /reset so conversation memory starts fresh
+	debug        bool
+	mood         float64
+	stats        map[string]float64
+	relationship string
+	timeOfDay    string
+	turn         int
+	lastResponse string
+	animation    string
+	pacer        *dialog.Pacer         // Types responses out progressively when set
+	lastContext  dialog.DialogContext  // Context of the latest trigger, reused by /regenerate
+	lastReply    dialog.DialogResponse // Latest response, rejected by /regenerate
+	memory       *dialog.UserMemory    // Facts about the user set with /remember
+}
+
+// newChatSession creates a session with neutral starting state
+func newChatSession(manager *dialog.DialogManager, character *characterFile, sessionID string, debug bool) *chatSession {
+	return &chatSession{
+		manager:      manager,
+		character:    character,
+		baseID:       sessionID,
+		debug:        debug,
+		mood:         70,
+		stats:        map[string]float64{"happiness": 70, "energy": 70, "trust": 50},
+		relationship: "friend",
+		timeOfDay:    dialog.TimeOfDayAt(time.Now()),
+		animation:    "idle",
+	}
+}
+
+// run reads commands until /quit or EOF
+func (s *chatSession) run(in io.Reader, out io.Writer) {
+	fmt.Fprintf(out, "Chatting with %s", s.character.Name)
+	if s.character.Description != "" {
+		fmt.Fprintf(out, " - %s", s.character.Description)
+	}
+	fmt.Fprintf(out, "\nType /help for commands.\n\n")
+
+	// Greet the user on birthdays, holidays and other configured occasions
+	if due := s.manager.DueCalendarEvents(s.interactionID(), time.Now()); len(due) > 0 {
+		s.trigger(dialog.CalendarEventTrigger, "", out)
+	}
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+		if quit := s.handle(strings.TrimSpace(scanner.Text()), out); quit {
+			return
+		}
+	}
+}
+
+// handle executes one line of input, returning true when the user quits
+func (s *chatSession) handle(line string, out io.Writer) bool {
+	if line == "" {
+		return false
+	}
+	if !strings.HasPrefix(line, "/") {
+		s.trigger("talk", line, out)
+		return false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "/"))
+	command, args := strings.ToLower(fields[0]), fields[1:]
+
+	switch command {
+	case "quit", "exit", "q":
+		return true
+	case "help", "?":
+		s.printHelp(out)
+	case "state":
+		s.printState(out)
+	case "mood":
+		if value, ok := parseLevel(args, out); ok {
+			s.mood = value
+			fmt.Fprintf(out, "Mood set to %.0f\n", value)
+		}
+	case "stat":
+		if len(args) != 2 {
+			fmt.Fprintf(out, "Usage: /stat <name> <0-100>\n")
+			break
+		}
+		if value, ok := parseLevel(args[1:], out); ok {
+			s.stats[args[0]] = value
+			fmt.Fprintf(out, "%s set to %.0f\n", args[0], value)
+		}
+	case "relationship":
+		if len(args) != 1 {
+			fmt.Fprintf(out, "Usage: /relationship <level>\n")
+			break
+		}
+		s.relationship = args[0]
+	case "time":
+		if len(args) != 1 {
+			fmt.Fprintf(out, "Usage: /time <morning|afternoon|evening|night>\n")
+			break
+		}
+		s.timeOfDay = args[0]
+	case "remember":
+		if len(args) < 2 {
+			fmt.Fprintf(out, "Usage: /remember <key> <value>\n")
+			break
+		}
+		fact := dialog.UserFact{Key: args[0], Value: strings.Join(args[1:], " ")}
+		if err := s.memory.Remember(s.interactionID(), fact); err != nil {
+			fmt.Fprintf(out, "%v\n", err)
+			break
+		}
+		fmt.Fprintf(out, "Remembered %s: %s\n", args[0], fact.Value)
+	case "forget":
+		if len(args) != 1 {
+			fmt.Fprintf(out, "Usage: /forget <key>\n")
+			break
+		}
+		s.memory.Forget(s.interactionID(), args[0])
+	case "prompt":
+		s.previewPrompt(args, out)
+	case "export":
+		if len(args) != 1 {
+			fmt.Fprintf(out, "Usage: /export <file.md|file.html>\n")
+			break
+		}
+		s.exportTranscript(args[0], out)
+	case "train":
+		if len(args) != 1 {
+			fmt.Fprintf(out, "Usage: /train <file.jsonl|file.json>\n")
+			break
+		}
+		s.exportTrainingData(args[0], out)
+	case "card":
+		if len(args) < 1 || len(args) > 2 {
+			fmt.Fprintf(out, "Usage: /card <file.json|file.png> [image.png]\n")
+			break
+		}
+		s.exportCharacterCard(args[0], args[1:], out)
+	case "regenerate", "retry":
+		if s.lastContext.Trigger == "" {
+			fmt.Fprintf(out, "Nothing to regenerate yet\n")
+			break
+		}
+		previous := s.lastReply
+		s.respond(s.lastContext, &previous, out)
+	case "reset":
+		s.session++
+		s.turn = 0
+		s.lastResponse = ""
+		s.lastContext = dialog.DialogContext{}
+		fmt.Fprintf(out, "Started a new conversation (%s)\n", s.interactionID())
+	default:
+		s.trigger(command, "", out)
+	}
+
+	return false
+}
+
+// trigger generates and prints a response for a trigger and what the user typed, if anything
+func (s *chatSession) trigger(trigger, message string, out io.Writer) {
+	s.turn++
+	s.respond(s.context(trigger, message), nil, out)
+}
+
+// context builds the context sent for a trigger from the session state
+func (s *chatSession) context(trigger, message string) dialog.DialogContext {
+	return dialog.DialogContext{
+		Trigger:           trigger,
+		UserMessage:       message,
+		InteractionID:     s.interactionID(),
+		Timestamp:         time.Now(),
+		CurrentStats:      copyStats(s.stats),
+		CurrentMood:       s.mood,
+		CurrentAnimation:  s.animation,
+		RelationshipLevel: s.relationship,
+		TimeOfDay:         s.timeOfDay,
+		LastResponse:      s.lastResponse,
+		ConversationTurn:  s.turn,
+		FallbackResponses: s.fallbackResponses(trigger),
+		FallbackAnimation: "talking",
+	}
+}
+
+// previewPrompt prints the prompt the LLM backend would be sent for the next trigger
+func (s *chatSession) previewPrompt(args []string, out io.Writer) {
+	backend, ok := s.manager.GetBackend("llm")
+	llm, isLLM := backend.(*dialog.LLMBackend)
+	if !ok || !isLLM {
+		fmt.Fprintf(out, "No LLM backend to preview\n")
+		return
+	}
+
+	trigger, message := "talk", ""
+	if len(args) > 0 {
+		trigger, message = args[0], strings.Join(args[1:], " ")
+	}
+	context := s.context(trigger, message)
+	context.ConversationTurn = s.turn + 1
+	prompt, estimate := llm.PreviewPrompt(dialog.EnrichTemporalContext(context, time.Now(), time.Time{}))
+
+	fmt.Fprintf(out, "%s\n", prompt)
+	fmt.Fprintf(out, "  [~%d prompt + %d reply tokens of %d", estimate.Prompt, estimate.Response, estimate.ContextSize)
+	if estimate.Trimmed {
+		fmt.Fprintf(out, "; trimmed to fit %d", estimate.Budget)
+	}
+	fmt.Fprintf(out, "]\n")
+}
+
+// exportTranscript saves the current conversation as Markdown, or HTML for .html paths
+func (s *chatSession) exportTranscript(path string, out io.Writer) {
+	backend, ok := s.manager.GetBackend("llm")
+	llm, isLLM := backend.(*dialog.LLMBackend)
+	if !ok || !isLLM {
+		fmt.Fprintf(out, "No LLM backend to export from\n")
+		return
+	}
+
+	opts := dialog.TranscriptOptions{CharacterName: s.character.Name}
+	if lower := strings.ToLower(path); strings.HasSuffix(lower, ".html") || strings.HasSuffix(lower, ".htm") {
+		opts.Format = dialog.TranscriptHTML
+	}
+	transcript, err := llm.GetContextManager().ExportTranscript(s.interactionID(), opts)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, []byte(transcript), 0o600); err != nil {
+		fmt.Fprintf(out, "Failed to save transcript: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "Saved transcript to %s\n", path)
+}
+
+// exportTrainingData saves well-received exchanges from all stored conversations as
+// fine-tuning JSONL, or for .json paths as an array for markov_chain.trainingData
+func (s *chatSession) exportTrainingData(path string, out io.Writer) {
+	backend, ok := s.manager.GetBackend("llm")
+	llm, isLLM := backend.(*dialog.LLMBackend)
+	if !ok || !isLLM {
+		fmt.Fprintf(out, "No LLM backend to export from\n")
+		return
+	}
+
+	examples := llm.ExportTrainingData(dialog.TrainingExportOptions{})
+	if len(examples) == 0 {
+		fmt.Fprintf(out, "No exchanges with positive feedback or high engagement to export\n")
+		return
+	}
+
+	var data bytes.Buffer
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		encoded, err := json.MarshalIndent(dialog.AppendTrainingData(nil, examples), "", "  ")
+		if err != nil {
+			fmt.Fprintf(out, "Failed to encode training data: %v\n", err)
+			return
+		}
+		data.Write(encoded)
+		data.WriteByte('\n')
+	} else if err := dialog.WriteTrainingJSONL(&data, examples); err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, data.Bytes(), 0o600); err != nil {
+		fmt.Fprintf(out, "Failed to save training data: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "Saved %d training examples to %s\n", len(examples), path)
+}
+
+// exportCharacterCard saves the character, its llm configuration and well-received
+// exchanges as a Character Card v2 JSON, or for .png paths embedded in image (default:
+// the existing file at path)
+func (s *chatSession) exportCharacterCard(path string, image []string, out io.Writer) {
+	var backends struct {
+		Backends map[string]json.RawMessage `json:"backends"`
+	}
+	var config dialog.LLMConfig
+	if err := json.Unmarshal(s.character.DialogBackend, &backends); err == nil && len(backends.Backends["llm"]) > 0 {
+		if err := json.Unmarshal(backends.Backends["llm"], &config); err != nil {
+			fmt.Fprintf(out, "Failed to read the llm configuration: %v\n", err)
+			return
+		}
+	}
+
+	source := dialog.CharacterCardSource{Name: s.character.Name, Description: s.character.Description, Config: config}
+	for _, entry := range s.character.Dialogs {
+		if entry.Trigger == "click" {
+			source.Greetings = append(source.Greetings, entry.Responses...)
+		}
+	}
+	if backend, ok := s.manager.GetBackend("llm"); ok {
+		if llm, isLLM := backend.(*dialog.LLMBackend); isLLM {
+			source.Learned = llm.ExportTrainingData(dialog.TrainingExportOptions{})
+		}
+	}
+	card, err := dialog.ExportCharacterCard(source)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return
+	}
+
+	var data []byte
+	if strings.HasSuffix(strings.ToLower(path), ".png") {
+		imagePath := path
+		if len(image) > 0 {
+			imagePath = image[0]
+		}
+		png, readErr := os.ReadFile(imagePath)
+		if readErr != nil {
+			fmt.Fprintf(out, "A PNG card needs an image: %v\n", readErr)
+			return
+		}
+		data, err = card.EmbedPNG(png)
+	} else {
+		data, err = card.Encode()
+	}
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		fmt.Fprintf(out, "Failed to save character card: %v\n", err)
+		return
+	}
+	fmt.Fprintf(out, "Saved %s with %d learned exchanges to %s\n", card.Name, len(source.Learned), path)
+}
+
+// respond generates and prints a response, replacing previous when it is set
+func (s *chatSession) respond(context dialog.DialogContext, previous *dialog.DialogResponse, out io.Writer) {
+	start := time.Now()
+	var response dialog.DialogResponse
+	var err error
+	if s.pacer != nil {
+		fmt.Fprintf(out, "%s: ", s.character.Name)
+		emit := func(update dialog.TypingUpdate) {
+			fmt.Fprint(out, update.Delta)
+		}
+		if previous != nil {
+			if response, err = s.manager.RegenerateDialog(context, *previous); err == nil {
+				s.pacer.Type(gocontext.Background(), response.Text, emit)
+			}
+		} else {
+			response, err = s.manager.GenerateDialogPaced(gocontext.Background(), context, s.pacer, emit)
+		}
+		fmt.Fprintln(out)
+	} else if previous != nil {
+		response, err = s.manager.RegenerateDialog(context, *previous)
+	} else {
+		response, err = s.manager.GenerateDialog(context)
+	}
+	elapsed := time.Since(start)
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+	}
+
+	s.lastContext = context
+	s.lastReply = response
+	s.lastResponse = response.Text
+	s.mood = min(100, max(0, s.mood+response.MoodDelta))
+	if response.Animation != "" {
+		s.animation = response.Animation
+	}
+
+	if s.pacer == nil {
+		fmt.Fprintf(out, "%s: %s\n", s.character.Name, response.Text)
+	}
+	fmt.Fprintf(out, "  [animation=%s confidence=%.2f tone=%s %dms]\n",
+		response.Animation, response.Confidence, response.EmotionalTone, elapsed.Milliseconds())
+	if response.MoodDelta != 0 {
+		fmt.Fprintf(out, "  [mood %+.1f -> %.0f]\n", response.MoodDelta, s.mood)
+	}
+	for _, action := range response.Actions {
+		fmt.Fprintf(out, "  [action %s %v]\n", action.Name, action.Arguments)
+	}
+	if s.debug && len(response.Metadata) > 0 {
+		for _, key := range sortedKeys(response.Metadata) {
+			fmt.Fprintf(out, "  %s: %v\n", key, response.Metadata[key])
+		}
+	}
+}
+
+// fallbackResponses uses the character's scripted lines for the trigger, or all lines if none match
+func (s *chatSession) fallbackResponses(trigger string) []string {
+	var matching, all []string
+	for _, entry := range s.character.Dialogs {
+		all = append(all, entry.Responses...)
+		if entry.Trigger == trigger {
+			matching = append(matching, entry.Responses...)
+		}
+	}
+	if len(matching) > 0 {
+		return matching
+	}
+	return all
+}
+
+// interactionID returns the conversation key for the current session
+func (s *chatSession) interactionID() string {
+	if s.session == 0 {
+		return s.baseID
+	}
+	return fmt.Sprintf("%s-%d", s.baseID, s.session)
+}
+
+// printHelp lists commands and the triggers the character scripts
+func (s *chatSession) printHelp(out io.Writer) {
+	fmt.Fprintf(out, "Chat:\n")
+	fmt.Fprintf(out, "  <message>            Say something; sent with the talk trigger\n")
+	fmt.Fprintf(out, "Triggers:\n")
+	fmt.Fprintf(out, "  /<trigger>           Send any trigger, e.g. /click, /feed, /pet\n")
+	if triggers := s.characterTriggers(); len(triggers) > 0 {
+		fmt.Fprintf(out, "                       Scripted by this character: %s\n", strings.Join(triggers, ", "))
+	}
+	fmt.Fprintf(out, "State:\n")
+	fmt.Fprintf(out, "  /mood <0-100>        Set the overall mood\n")
+	fmt.Fprintf(out, "  /stat <name> <0-100> Set a stat such as happiness, energy or trust\n")
+	fmt.Fprintf(out, "  /relationship <lvl>  Set the relationship level\n")
+	fmt.Fprintf(out, "  /time <time of day>  Set morning, afternoon, evening or night\n")
+	fmt.Fprintf(out, "  /remember <key> <v>  Tell the character a fact, e.g. /remember name Sam\n")
+	fmt.Fprintf(out, "  /forget <key>        Remove facts under a key\n")
+	fmt.Fprintf(out, "  /state               Show the current state\n")
+	fmt.Fprintf(out, "  /prompt [trigger]    Show the prompt the next trigger would send, without sending it\n")
+	fmt.Fprintf(out, "  /regenerate          Replace the last response with a different one\n")
+	fmt.Fprintf(out, "  /export <file>       Save the conversation as Markdown, or HTML for .html files\n")
+	fmt.Fprintf(out, "  /train <file>        Save well-received exchanges as fine-tuning JSONL, or .json trainingData\n")
+	fmt.Fprintf(out, "  /card <file> [image] Save the character and what it learned as a character card (JSON, or PNG from image)\n")
+	fmt.Fprintf(out, "  /reset               Start a new conversation with empty memory\n")
+	fmt.Fprintf(out, "  /quit                Exit\n")
+}
+
+// printState shows the state sent with the next trigger
+func (s *chatSession) printState(out io.Writer) {
+	fmt.Fprintf(out, "Session: %s (turn %d)\n", s.interactionID(), s.turn)
+	fmt.Fprintf(out, "Mood: %.0f  Relationship: %s  Time: %s  Animation: %s\n", s.mood, s.relationship, s.timeOfDay, s.animation)
+	names := make([]string, 0, len(s.stats))
+	for name := range s.stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s: %.0f\n", name, s.stats[name])
+	}
+	for _, fact := range s.memory.Facts(s.interactionID()) {
+		fmt.Fprintf(out, "  remembers %s: %s\n", fact.Key, fact.Value)
+	}
+}
+
+// characterTriggers returns the distinct triggers defined in the character's dialogs
+func (s *chatSession) characterTriggers() []string {
+	seen := make(map[string]bool)
+	var triggers []string
+	for _, entry := range s.character.Dialogs {
+		if entry.Trigger != "" && !seen[entry.Trigger] {
+			seen[entry.Trigger] = true
+			triggers = append(triggers, entry.Trigger)
+		}
+	}
+	return triggers
+}
+
+// parseLevel parses a single 0-100 argument, printing a message when invalid
+func parseLevel(args []string, out io.Writer) (float64, bool) {
+	if len(args) != 1 {
+		fmt.Fprintf(out, "Expected one value between 0 and 100\n")
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || value < 0 || value > 100 {
+		fmt.Fprintf(out, "Expected a value between 0 and 100, got %q\n", args[0])
+		return 0, false
+	}
+	return value, true
+}
+
+// copyStats returns a copy so later /stat commands don't alter past contexts
+func copyStats(stats map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(stats))
+	for name, value := range stats {
+		copied[name] = value
+	}
+	return copied
+}
+
+// sortedKeys returns metadata keys in a stable order
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
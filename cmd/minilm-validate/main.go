@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/opd-ai/minilm/dialog"
+)
+
+// fileReport is the -json output for one config file
+type fileReport struct {
+	File        string                    `json:"file"`
+	Valid       bool                      `json:"valid"`
+	Diagnostics []dialog.ConfigDiagnostic `json:"diagnostics"`
+}
+
+func main() {
+	strict := flag.Bool("strict", false, "Treat warnings as errors")
+	jsonOutput := flag.Bool("json", false, "Print diagnostics as JSON")
+	schema := flag.String("schema", "", "Print the JSON Schema for \"character\", \"dialogBackend\" or \"llm\" configs and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <config.json>...\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nChecks character files, dialogBackend configs or LLM backend configs without\n")
+		fmt.Fprintf(os.Stderr, "loading a model and reports each problem with its JSON path. Exits with status 1\n")
+		fmt.Fprintf(os.Stderr, "when any file has errors (or warnings, with -strict).\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s assets/characters/*/character.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -schema character > character.schema.json\n", os.Args[0])
+	}
+	flag.Parse()
+
+	if *schema != "" {
+		data, err := dialog.ConfigSchema(*schema)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	reports := make([]fileReport, 0, flag.NArg())
+	failed := false
+	for _, path := range flag.Args() {
+		report := validateFile(path, *strict)
+		failed = failed || !report.Valid
+		reports = append(reports, report)
+	}
+
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, report := range reports {
+			printReport(report)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// validateFile diagnoses one config file; unreadable files are reported as an error at $
+func validateFile(path string, strict bool) fileReport {
+	report := fileReport{File: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		report.Diagnostics = []dialog.ConfigDiagnostic{{Path: "$", Severity: dialog.SeverityError, Message: err.Error()}}
+	} else {
+		report.Diagnostics = dialog.DiagnoseConfig(data)
+	}
+	if report.Diagnostics == nil {
+		report.Diagnostics = []dialog.ConfigDiagnostic{}
+	}
+
+	report.Valid = true
+	for _, diagnostic := range report.Diagnostics {
+		if diagnostic.Severity == dialog.SeverityError || strict {
+			report.Valid = false
+		}
+	}
+	return report
+}
+
+// printReport writes one line per diagnostic, or "ok" when there are none
+func printReport(report fileReport) {
+	if len(report.Diagnostics) == 0 {
+		fmt.Printf("%s: ok\n", report.File)
+		return
+	}
+	for _, diagnostic := range report.Diagnostics {
+		fmt.Printf("%s: %s\n", report.File, diagnostic)
+	}
+}
@@ -37,6 +37,12 @@
 package dialog
 
 import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
 	"github.com/opd-ai/minilm/internal/dialog"
 )
 
@@ -50,10 +56,22 @@ type DialogBackend = dialog.DialogBackend
 // character state, interaction history, and conversation context.
 type DialogContext = dialog.DialogContext
 
+// ContextBuilder assembles a DialogContext fluently, validating values and
+// filling defaults for the timestamp, current animation and fallbacks.
+type ContextBuilder = dialog.ContextBuilder
+
 // DialogResponse contains the generated response and associated metadata
 // including confidence scores, emotional tone, and animation triggers.
 type DialogResponse = dialog.DialogResponse
 
+// Action is a host action the character requested in DialogResponse.Actions,
+// such as playing an animation or setting a reminder.
+type Action = dialog.Action
+
+// ToolDefinition describes a host action characters may request
+// (LLMConfig.Tools). The model sees its name, description and parameters.
+type ToolDefinition = dialog.ToolDefinition
+
 // UserFeedback captures user response to dialog for backend learning
 // and adaptation mechanisms.
 type UserFeedback = dialog.UserFeedback
@@ -71,6 +89,573 @@ type BackendInfo = dialog.BackendInfo
 // backend selection and graceful degradation.
 type DialogManager = dialog.DialogManager
 
+// ContextManager handles per-interaction conversation history used to build prompts.
+// Obtain the instance used by an LLM backend via LLMBackend.GetContextManager.
+type ContextManager = dialog.ContextManager
+
+// ConversationExchange represents a single turn stored in conversation history.
+type ConversationExchange = dialog.ConversationExchange
+
+// ConversationHistory holds the stored exchanges for one interaction.
+type ConversationHistory = dialog.ConversationHistory
+
+// ConversationExport is the versioned JSON document produced by ContextManager.Export
+// and accepted by ContextManager.Import.
+type ConversationExport = dialog.ConversationExport
+
+// ContextState is the checkpoint of every conversation written by
+// ContextManager.SaveState (and LLMBackend.SaveState).
+type ContextState = dialog.ContextState
+
+// DialogState is the checkpoint written by DialogManager.SaveState: the state
+// of each registered backend keyed by backend name.
+type DialogState = dialog.DialogState
+
+// ConversationAnalytics summarizes engagement, feedback, response types and
+// activity times across stored conversations.
+type ConversationAnalytics = dialog.ConversationAnalytics
+
+// TriggerAnalytics aggregates engagement and feedback for a single trigger.
+type TriggerAnalytics = dialog.TriggerAnalytics
+
+// DialogAnalytics combines DialogManager response statistics with conversation
+// analytics merged from the registered backends.
+type DialogAnalytics = dialog.DialogAnalytics
+
+// AnalyticsProvider is implemented by backends that can report conversation analytics.
+type AnalyticsProvider = dialog.AnalyticsProvider
+
+// BackendHealth reports whether a backend is healthy along with its model
+// state, queue depth, last error and average latency.
+type BackendHealth = dialog.BackendHealth
+
+// HealthReport aggregates BackendHealth for all registered backends
+// (DialogManager.Health).
+type HealthReport = dialog.HealthReport
+
+// HealthReporter is implemented by backends that track runtime health statistics.
+type HealthReporter = dialog.HealthReporter
+
+// OverloadReporter is implemented by backends that can tell when they are too slow
+// to serve interactive requests even at their lowest quality; LLMBackend does so
+// when LLMConfig.AdaptiveQuality is set.
+type OverloadReporter = dialog.OverloadReporter
+
+// ResponseVariant is one candidate from DialogManager.GenerateDialogVariants or
+// LLMBackend.GenerateResponseVariants, with its score and sampling settings.
+type ResponseVariant = dialog.ResponseVariant
+
+// DialogFuture is a generation started by DialogManager.GenerateDialogAsync. Poll Ready
+// or select on Done from an event loop, read the response with Result, and call Cancel
+// to abandon it.
+type DialogFuture = dialog.DialogFuture
+
+// VariantGenerator is implemented by backends that produce several candidate
+// responses. Pass the one the user keeps to AcceptResponse so it enters memory.
+type VariantGenerator = dialog.VariantGenerator
+
+// Regenerator is implemented by backends that can replace a response the user
+// disliked with one that avoids repeating it (see DialogManager.RegenerateDialog).
+type Regenerator = dialog.Regenerator
+
+// PersonaProfile is a personality (description, traits and example lines) that
+// LLMConfig.Personas blends with others by weight.
+type PersonaProfile = dialog.PersonaProfile
+
+// PersonaBlend is a weighted mix of persona profiles with normalized weights.
+type PersonaBlend = dialog.PersonaBlend
+
+// MoodConfig sets a MoodEngine's baseline, decay half-life, per-trigger effects
+// and feedback effects.
+type MoodConfig = dialog.MoodConfig
+
+// MoodEngine evolves mood from triggers, feedback and elapsed time. Install it
+// with DialogManager.SetMoodEngine; responses then carry a MoodDelta to apply.
+type MoodEngine = dialog.MoodEngine
+
+// CalendarEvent is a dated occasion such as a birthday, holiday or anniversary
+// (LLMConfig.Events). On its date the prompt mentions it.
+type CalendarEvent = dialog.CalendarEvent
+
+// TriggerDefinition describes a custom trigger such as "homework_done" for the
+// prompt and gives it a default animation (LLMConfig.Triggers, RegisterTrigger).
+type TriggerDefinition = dialog.TriggerDefinition
+
+// CalendarProvider is implemented by backends configured with calendar events.
+type CalendarProvider = dialog.CalendarProvider
+
+// DriftRule nudges a personality trait each time an interaction matches its
+// trigger and time of day.
+type DriftRule = dialog.DriftRule
+
+// DriftConfig lists drift rules and the largest change allowed from a trait's
+// value in the context.
+type DriftConfig = dialog.DriftConfig
+
+// PersonalityDrift slowly shifts each conversation's personality traits as
+// interaction patterns repeat. Install it with DialogManager.SetPersonalityDrift.
+type PersonalityDrift = dialog.PersonalityDrift
+
+// PersonalityDriftExport is the portable JSON document produced by
+// PersonalityDrift.Export.
+type PersonalityDriftExport = dialog.PersonalityDriftExport
+
+// UserFact is something the character knows about the user, such as their name
+// or a preference. Facts under FactLikes and FactDislikes accumulate; other keys
+// hold one value.
+type UserFact = dialog.UserFact
+
+// UserMemory stores facts about the user per InteractionID. Install it with
+// DialogManager.SetUserMemory so prompts mention what the character knows.
+type UserMemory = dialog.UserMemory
+
+// ExtractionConfig sets the confidence thresholds and lifetime of facts a
+// UserMemory extracts from exchanges (see UserMemory.EnableExtraction).
+type ExtractionConfig = dialog.ExtractionConfig
+
+// FactExtractor pulls names, birthdays and simple preferences out of what the
+// user says and what the character repeats back.
+type FactExtractor = dialog.FactExtractor
+
+// UserMemoryExport is the portable JSON document produced by UserMemory.Export.
+type UserMemoryExport = dialog.UserMemoryExport
+
+// Reminder is a daily line a ProactiveScheduler says at a fixed local time.
+type Reminder = dialog.Reminder
+
+// ProactiveConfig sets when a ProactiveScheduler speaks up unprompted: idle
+// chatter, check-ins (more often when mood is low) and daily reminders.
+type ProactiveConfig = dialog.ProactiveConfig
+
+// ProactiveHandler receives each unprompted response with its context.
+type ProactiveHandler = dialog.ProactiveHandler
+
+// ProactiveScheduler lets a character start conversations instead of only
+// reacting. Call RecordInteraction on user input and Tick (or Run) periodically.
+type ProactiveScheduler = dialog.ProactiveScheduler
+
+// Character is a participant in conversations between characters. Register it
+// with DialogManager.RegisterCharacter; Backend picks the backend that speaks for it.
+type Character = dialog.Character
+
+// CharacterConversation configures DialogManager.RunCharacterConversation:
+// the participants in speaking order, the number of lines and an optional topic.
+type CharacterConversation = dialog.CharacterConversation
+
+// CharacterTurn is one line of a multi-character conversation with its context.
+type CharacterTurn = dialog.CharacterTurn
+
+// WorldEvent is something that happened to a character on the shared desktop,
+// e.g. {Character: "Pip", Description: "got fed"}.
+type WorldEvent = dialog.WorldEvent
+
+// WorldConfig sets how many events a WorldContext keeps and for how long.
+type WorldConfig = dialog.WorldConfig
+
+// WorldContext is a store of recent events shared by the characters on one
+// desktop. Attach it to each character's manager with DialogManager.SetWorldContext.
+type WorldContext = dialog.WorldContext
+
+// SpeechHints tell a text-to-speech engine how to say a response: the text to
+// speak, SSML with prosody and emphasis, and a suggested voice and emotion.
+type SpeechHints = dialog.SpeechHints
+
+// SpeechConfig picks the voice for speech hints, optionally per emotional tone.
+type SpeechConfig = dialog.SpeechConfig
+
+// TextToSpeech is implemented by hosts to speak responses. Register one with
+// DialogManager.SetSpeech; Speak should queue the audio and return promptly.
+type TextToSpeech = dialog.TextToSpeech
+
+// VoiceInput is a user utterance transcribed by a speech-to-text engine.
+type VoiceInput = dialog.VoiceInput
+
+// VoiceConfig sets the recognizer confidence a VoicePipeline answers and how
+// long transcripts may be.
+type VoiceConfig = dialog.VoiceConfig
+
+// VoicePipeline turns transcribed utterances into dialog turns so the character
+// answers what the user actually said.
+type VoicePipeline = dialog.VoicePipeline
+
+// PacingConfig sets the typing speed, jitter and punctuation pauses of a Pacer.
+type PacingConfig = dialog.PacingConfig
+
+// Pacer reveals responses word by word so instant responses still look typed.
+// Use Type for a callback, Stream for a channel, or DialogManager.GenerateDialogPaced.
+type Pacer = dialog.Pacer
+
+// TypingUpdate is one step of a paced response: the text so far and the word just typed.
+type TypingUpdate = dialog.TypingUpdate
+
+// NewPacer creates a pacer; CharsPerSecond defaults to 30.
+func NewPacer(config PacingConfig) (*Pacer, error) {
+	return dialog.NewPacer(config)
+}
+
+// AutoThreadCount returns the thread count LLMConfig.Threads 0 resolves to on this
+// machine: one inference thread per physical performance core, leaving a core free
+// for foreground apps on larger machines.
+func AutoThreadCount() int {
+	return dialog.AutoThreadCount()
+}
+
+// ResourceStats reports estimated model memory, conversation memory, cache sizes
+// and queue depth (LLMBackend.GetResourceStats, DialogManager.GetResourceStats).
+// TotalBytes sums the memory estimates.
+type ResourceStats = dialog.ResourceStats
+
+// RuntimeStats is a snapshot of goroutines, heap, backend queue depth, loaded
+// models and resource usage from DialogManager.RuntimeStats.
+type RuntimeStats = dialog.RuntimeStats
+
+// DebugHandlerOptions controls what NewDebugHandler serves. Go profiles under
+// /debug/pprof/ are only served when Pprof is set.
+type DebugHandlerOptions = dialog.DebugHandlerOptions
+
+// MemoryStats summarizes the conversations held by a ContextManager.
+type MemoryStats = dialog.MemoryStats
+
+// EvictionCounts tallies evictions, dropped conversations and lost exchanges for one
+// eviction reason (ContextManager.EvictionStats, MemoryStats.Evictions).
+type EvictionCounts = dialog.EvictionCounts
+
+// Eviction describes memory a ContextManager dropped, as passed to ContextManager.OnEviction.
+type Eviction = dialog.Eviction
+
+// CacheStats describes the size of one in-memory cache.
+type CacheStats = dialog.CacheStats
+
+// ResourceReporter is implemented by backends that report resource usage.
+type ResourceReporter = dialog.ResourceReporter
+
+// ModelFileInfo describes a GGUF model file: size, architecture, quantization
+// and shape as read from its header.
+type ModelFileInfo = dialog.ModelFileInfo
+
+// HardwareFitReport compares a model's estimated RAM needs with available system
+// memory and its thread count with available CPUs, with structured warnings.
+type HardwareFitReport = dialog.HardwareFitReport
+
+// HardwareWarning is one finding of a hardware fit check; Code is one of the
+// Warning* constants.
+type HardwareWarning = dialog.HardwareWarning
+
+// HardwareFitReporter is implemented by backends that check whether their model
+// fits the host machine, such as LLMBackend.
+type HardwareFitReporter = dialog.HardwareFitReporter
+
+// ErrInsufficientMemory is returned when initializing a backend whose model is
+// estimated not to fit in available memory. Set LLMConfig.AllowMemoryOvercommit
+// to load it anyway.
+var ErrInsufficientMemory = dialog.ErrInsufficientMemory
+
+// Hardware fit warning codes.
+const (
+	WarningInsufficientMemory  = dialog.WarningInsufficientMemory
+	WarningLowMemoryHeadroom   = dialog.WarningLowMemoryHeadroom
+	WarningThreadsExceedCPUs   = dialog.WarningThreadsExceedCPUs
+	WarningUnquantizedModel    = dialog.WarningUnquantizedModel
+	WarningUnknownQuantization = dialog.WarningUnknownQuantization
+	WarningUnreadableMetadata  = dialog.WarningUnreadableMetadata
+	WarningContextTooLong      = dialog.WarningContextTooLong
+)
+
+// InspectModelFile reads the size and GGUF metadata (architecture, quantization,
+// layer count, context length) of a model file.
+func InspectModelFile(path string) (ModelFileInfo, error) {
+	return dialog.InspectModelFile(path)
+}
+
+// NewDebugHandler returns an http.Handler serving DialogManager.RuntimeStats as
+// JSON at /stats and, with DebugHandlerOptions.Pprof, Go profiles under
+// /debug/pprof/ for go tool pprof. Serve it on an address only operators can
+// reach.
+//
+// Example:
+//
+//	go http.ListenAndServe("localhost:6060",
+//		dialog.NewDebugHandler(manager, dialog.DebugHandlerOptions{Pprof: true}))
+//	// go tool pprof http://localhost:6060/debug/pprof/profile?seconds=10
+func NewDebugHandler(dm *DialogManager, opts DebugHandlerOptions) http.Handler {
+	return dialog.NewDebugHandler(dm, opts)
+}
+
+// CheckHardwareFit estimates the RAM a model needs for the given context size and
+// compares it, and the thread count, with what the machine has available.
+func CheckHardwareFit(modelPath string, contextSize, threads int) (HardwareFitReport, error) {
+	return dialog.CheckHardwareFit(modelPath, contextSize, threads)
+}
+
+// HubScheme prefixes LLMConfig.ModelPath values naming a HuggingFace Hub file,
+// e.g. hf://TheBloke/TinyLlama-1.1B-Chat-GGUF/tinyllama.Q4_K_M.gguf.
+const HubScheme = dialog.HubScheme
+
+// Environment variables read when resolving Hub models: the cache directory and
+// an access token for gated repositories.
+const (
+	EnvModelCache = dialog.EnvModelCache
+	EnvHubToken   = dialog.EnvHubToken
+)
+
+// HubModelRef identifies one file in a HuggingFace Hub repository.
+type HubModelRef = dialog.HubModelRef
+
+// IsHubModelPath reports whether a model path uses HubScheme.
+func IsHubModelPath(modelPath string) bool {
+	return dialog.IsHubModelPath(modelPath)
+}
+
+// ParseHubModelPath splits an hf:// model path into repository, file and
+// revision (hf://owner/repo@revision/file pins a revision).
+func ParseHubModelPath(modelPath string) (HubModelRef, error) {
+	return dialog.ParseHubModelPath(modelPath)
+}
+
+// DefaultModelCacheDir returns $MINILM_MODEL_CACHE, or minilm/models in the
+// user cache directory.
+func DefaultModelCacheDir() (string, error) {
+	return dialog.DefaultModelCacheDir()
+}
+
+// ResolveHubModel returns the cached local copy of an hf:// model, downloading
+// and verifying it first when it is not cached. An empty cacheDir uses
+// DefaultModelCacheDir; an empty expectedSHA256 trusts the checksum the Hub reports.
+func ResolveHubModel(ctx context.Context, modelPath, cacheDir, expectedSHA256 string) (string, error) {
+	return dialog.ResolveHubModel(ctx, modelPath, cacheDir, expectedSHA256)
+}
+
+// EnvModelRegistry names the models.json file used to resolve LLMConfig.Model
+// aliases.
+const EnvModelRegistry = dialog.EnvModelRegistry
+
+// ModelRegistry maps model aliases such as "tinyllama-q4" to model files and
+// their recommended settings, so character files need no host-specific paths.
+type ModelRegistry = dialog.ModelRegistry
+
+// ModelRegistryEntry describes one registered model.
+type ModelRegistryEntry = dialog.ModelRegistryEntry
+
+// RecommendedSettings are the generation settings a registered model applies
+// when the character's config leaves them unset.
+type RecommendedSettings = dialog.RecommendedSettings
+
+// LoadModelRegistry reads a models.json file, resolving relative model paths
+// against the file's directory.
+func LoadModelRegistry(path string) (*ModelRegistry, error) {
+	return dialog.LoadModelRegistry(path)
+}
+
+// DefaultModelRegistryPath returns $MINILM_MODEL_REGISTRY, or minilm/models.json
+// in the user config directory.
+func DefaultModelRegistryPath() (string, error) {
+	return dialog.DefaultModelRegistryPath()
+}
+
+// PromptCacheStats reports how many prompt tokens a model reused from its KV
+// cache; LLM backends include it in BackendHealth.
+type PromptCacheStats = dialog.PromptCacheStats
+
+// PromptCacher is implemented by models that reuse the KV cache for the prompt
+// prefix shared with the previous request.
+type PromptCacher = dialog.PromptCacher
+
+// EventType identifies a dialog lifecycle event such as EventFallbackUsed.
+type EventType = dialog.EventType
+
+// DialogEvent describes a lifecycle event published on a DialogManager's EventBus.
+type DialogEvent = dialog.DialogEvent
+
+// EventHandler receives dialog events. Handlers run synchronously and should
+// return quickly.
+type EventHandler = dialog.EventHandler
+
+// EventBus dispatches dialog events to callback and channel subscribers
+// (DialogManager.Events).
+type EventBus = dialog.EventBus
+
+// EventPublisher is implemented by backends that emit their own events, such
+// as memory evictions. RegisterBackend connects them to the manager's bus.
+type EventPublisher = dialog.EventPublisher
+
+// Dialog lifecycle event types.
+const (
+	EventGenerationStarted   = dialog.EventGenerationStarted
+	EventGenerationCompleted = dialog.EventGenerationCompleted
+	EventResponseGenerated   = dialog.EventResponseGenerated
+	EventFallbackUsed        = dialog.EventFallbackUsed
+	EventBackendError        = dialog.EventBackendError
+	EventMemoryEvicted       = dialog.EventMemoryEvicted
+	EventSpeechError         = dialog.EventSpeechError
+)
+
+// Eviction reasons reported with EventMemoryEvicted.
+const (
+	EvictionReasonHistoryLimit = dialog.EvictionReasonHistoryLimit
+	EvictionReasonCapacity     = dialog.EvictionReasonCapacity
+	EvictionReasonExpired      = dialog.EvictionReasonExpired
+	EvictionReasonMemoryBudget = dialog.EvictionReasonMemoryBudget
+)
+
+// DialogHandler produces a dialog response for a context. Middleware wraps handlers.
+type DialogHandler = dialog.DialogHandler
+
+// Middleware wraps DialogManager generation with cross-cutting behavior such as
+// logging, context rewriting, response vetoing or latency metrics. Register with
+// DialogManager.Use.
+type Middleware = dialog.Middleware
+
+// PreHookFunc runs before generation and may mutate the context or short-circuit
+// with its own response.
+type PreHookFunc = dialog.PreHookFunc
+
+// PostHookFunc runs after generation and may rewrite or veto the response.
+type PostHookFunc = dialog.PostHookFunc
+
+// ErrTimeout indicates generation did not finish within its time budget.
+var ErrTimeout = dialog.ErrTimeout
+
+// ErrBackendBusy indicates a backend could not accept the request right now.
+var ErrBackendBusy = dialog.ErrBackendBusy
+
+// ErrShuttingDown is returned by GenerateDialog after DialogManager.Shutdown
+// has been called. The canned fallback response is returned alongside it.
+var ErrShuttingDown = dialog.ErrShuttingDown
+
+// ErrStateEncrypted is returned by DialogManager.LoadState for an encrypted
+// checkpoint when no key was set with SetStateEncryptionKey.
+var ErrStateEncrypted = dialog.ErrStateEncrypted
+
+// ErrUnknownTenant is returned by GenerateDialog for a DialogContext.TenantID
+// that was not registered with DialogManager.RegisterTenant.
+var ErrUnknownTenant = dialog.ErrUnknownTenant
+
+// ErrNotInitialized is returned when a backend or model is used before
+// Initialize succeeded.
+var ErrNotInitialized = dialog.ErrNotInitialized
+
+// ErrModelNotFound is returned when the configured model file does not exist.
+var ErrModelNotFound = dialog.ErrModelNotFound
+
+// ErrConfigInvalid matches every ConfigError with errors.Is.
+var ErrConfigInvalid = dialog.ErrConfigInvalid
+
+// ErrInvalidContext is returned by ContextBuilder.Build for invalid values.
+var ErrInvalidContext = dialog.ErrInvalidContext
+
+// ConfigError reports a configuration value that failed validation. Field is
+// the JSON name of the offending setting, such as "modelPath".
+type ConfigError = dialog.ConfigError
+
+// IsTransientError reports whether an error is worth retrying (timeouts, busy backends).
+func IsTransientError(err error) bool {
+	return dialog.IsTransientError(err)
+}
+
+// ErrResponseVetoed is a convenience error hooks can return to reject a response.
+var ErrResponseVetoed = dialog.ErrResponseVetoed
+
+// ResponseValidator checks generated responses; rejected responses are regenerated
+// with a higher temperature before the backend falls back. Register custom
+// validators with LLMBackend.AddValidator.
+type ResponseValidator = dialog.ResponseValidator
+
+// ResponseValidatorFunc adapts a function into a ResponseValidator.
+type ResponseValidatorFunc = dialog.ResponseValidatorFunc
+
+// LengthValidator rejects responses outside a character length range.
+type LengthValidator = dialog.LengthValidator
+
+// BannedContentValidator rejects responses containing banned phrases.
+type BannedContentValidator = dialog.BannedContentValidator
+
+// PersonaMarkerValidator requires at least one persona marker in each response.
+type PersonaMarkerValidator = dialog.PersonaMarkerValidator
+
+// RepetitionValidator rejects responses nearly identical to one of the last
+// replies in the same conversation (ValidationConfig.RepetitionWindow).
+type RepetitionValidator = dialog.RepetitionValidator
+
+// Grammar is a parsed GBNF grammar. Production models constrain sampling with
+// it (LLMConfig.Grammar); Match checks any text against the root rule.
+type Grammar = dialog.Grammar
+
+// PatternValidator rejects responses matching any blocked regular expression
+// or missing any required one (LLMConfig.BlockedPatterns and RequiredPatterns).
+type PatternValidator = dialog.PatternValidator
+
+// GrammarValidator rejects responses a Grammar does not match. The LLM backend
+// adds one automatically when LLMConfig.Grammar or GrammarFile is set.
+type GrammarValidator = dialog.GrammarValidator
+
+// Response formats for LLMConfig.ResponseFormat. In JSON mode the model replies
+// with {"text", "emotion", "animation"}, constrained by a generated grammar, and
+// its choices replace the keyword heuristics for animation and emotional tone.
+const (
+	ResponseFormatText = dialog.ResponseFormatText
+	ResponseFormatJSON = dialog.ResponseFormatJSON
+)
+
+// Common UserFact keys and sources. Any other key is allowed.
+const (
+	FactName            = dialog.FactName
+	FactBirthday        = dialog.FactBirthday
+	FactLikes           = dialog.FactLikes
+	FactDislikes        = dialog.FactDislikes
+	FactSourceHost      = dialog.FactSourceHost
+	FactSourceExtracted = dialog.FactSourceExtracted
+)
+
+// Triggers a ProactiveScheduler uses for unprompted dialog. Reminder text is
+// passed in TopicContext[ProactiveTriggerReminder].
+const (
+	ProactiveTriggerIdle     = dialog.ProactiveTriggerIdle
+	ProactiveTriggerCheckIn  = dialog.ProactiveTriggerCheckIn
+	ProactiveTriggerReminder = dialog.ProactiveTriggerReminder
+)
+
+// Request priorities for DialogContext.Priority. With LLMConfig.MaxConcurrentGenerations
+// set, waiting interactive requests always run before background ones; a
+// ProactiveScheduler sends its dialog at PriorityBackground.
+const (
+	PriorityInteractive = dialog.PriorityInteractive
+	PriorityBackground  = dialog.PriorityBackground
+)
+
+// CalendarEventTrigger is the trigger to raise when DialogManager.DueCalendarEvents
+// reports an event, so the character greets the user on the occasion.
+const CalendarEventTrigger = dialog.CalendarEventTrigger
+
+// ConversationStarterTrigger is the trigger DialogManager.GenerateConversationStarter
+// generates for, letting backends and middleware tell opening lines from reactions.
+const ConversationStarterTrigger = dialog.ConversationStarterTrigger
+
+// CharacterChatTrigger is the trigger for each line of a multi-character
+// conversation. The topic, when set, is passed in TopicContext[CharacterChatTrigger].
+const CharacterChatTrigger = dialog.CharacterChatTrigger
+
+// Triggers raised by a VoicePipeline. VoiceUnclearTrigger is used when the
+// recognizer's confidence is too low, so the character asks the user to repeat.
+const (
+	VoiceTrigger        = dialog.VoiceTrigger
+	VoiceUnclearTrigger = dialog.VoiceUnclearTrigger
+)
+
+// Transcript formats for TranscriptOptions.Format. HTML transcripts are
+// standalone pages with inline styles.
+const (
+	TranscriptMarkdown = dialog.TranscriptMarkdown
+	TranscriptHTML     = dialog.TranscriptHTML
+)
+
+// Calendar event kinds. Birthdays and anniversaries with a starting year are
+// described with their age; any other kind is a user-defined occasion.
+const (
+	CalendarKindBirthday    = dialog.CalendarKindBirthday
+	CalendarKindHoliday     = dialog.CalendarKindHoliday
+	CalendarKindAnniversary = dialog.CalendarKindAnniversary
+)
+
 // Configuration types for backend setup
 
 // LLMConfig defines configuration options for the LLM backend including
@@ -81,6 +666,249 @@ type LLMConfig = dialog.LLMConfig
 // extraction and training data management.
 type MarkovChainConfig = dialog.MarkovChainConfig
 
+// ValidationConfig configures built-in response validators and the
+// regeneration policy (LLMConfig.Validation).
+type ValidationConfig = dialog.ValidationConfig
+
+// UserDataStore is implemented by backends holding per-interaction user data,
+// so DialogManager.ExportUserData and DeleteUserData can reach it.
+type UserDataStore = dialog.UserDataStore
+
+// UserDataExport is the machine-readable bundle returned by
+// DialogManager.ExportUserData: backend history, facts, pending facts, drift and mood.
+type UserDataExport = dialog.UserDataExport
+
+// LLMUserData is the LLM backend's entry in UserDataExport.Backends.
+type LLMUserData = dialog.LLMUserData
+
+// TranscriptFormat selects Markdown or HTML output for RenderTranscript.
+type TranscriptFormat = dialog.TranscriptFormat
+
+// TranscriptOptions sets the format, title and speaker labels of a rendered
+// transcript.
+type TranscriptOptions = dialog.TranscriptOptions
+
+// TrainingExample is a well-received exchange as a fine-tuning prompt/response pair
+// (ContextManager.TrainingExamples).
+type TrainingExample = dialog.TrainingExample
+
+// TrainingExportOptions sets which stored exchanges become training data: those with
+// positive feedback, or without feedback but with at least MinEngagement.
+type TrainingExportOptions = dialog.TrainingExportOptions
+
+// CharacterCard is a SillyTavern/TavernAI character card (Character Card v2, or a
+// flat v1 card) read with ParseCharacterCard.
+type CharacterCard = dialog.CharacterCard
+
+// CharacterCardSource is a MiniLM character (name, greetings, llm config and
+// learned exchanges) to export with ExportCharacterCard.
+type CharacterCardSource = dialog.CharacterCardSource
+
+// RedactionConfig configures PII redaction of conversation history
+// (LLMConfig.Redaction).
+type RedactionConfig = dialog.RedactionConfig
+
+// RedactionPattern is a custom regular expression PII detector.
+type RedactionPattern = dialog.RedactionPattern
+
+// PIIDetector replaces the personal details it recognizes in text. Implement
+// it to plug custom detectors into a Redactor.
+type PIIDetector = dialog.PIIDetector
+
+// RegexDetector is a PIIDetector replacing every match of a regular expression.
+type RegexDetector = dialog.RegexDetector
+
+// Redactor runs PII detectors over conversation text.
+type Redactor = dialog.Redactor
+
+// Built-in PII detector names for RedactionConfig.Detectors.
+const (
+	DetectorEmail   = dialog.DetectorEmail
+	DetectorPhone   = dialog.DetectorPhone
+	DetectorAddress = dialog.DetectorAddress
+)
+
+// NewRedactor builds a redactor from built-in detectors ("email", "phone",
+// "address"; all when none are listed) and custom patterns.
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	return dialog.NewRedactor(config)
+}
+
+// NewRedactingBackend wraps a backend, such as one calling a third-party API,
+// so it only sees contexts with personal details redacted.
+func NewRedactingBackend(backend DialogBackend, redactor *Redactor) DialogBackend {
+	return dialog.NewRedactingBackend(backend, redactor)
+}
+
+// RetryConfig configures retries with exponential backoff for transient
+// generation failures (LLMConfig.Retry).
+type RetryConfig = dialog.RetryConfig
+
+// AdaptiveQualityConfig shortens responses while recent latencies exceed a target
+// and restores them once latencies recover (LLMConfig.AdaptiveQuality).
+type AdaptiveQualityConfig = dialog.AdaptiveQualityConfig
+
+// RateLimitConfig configures per-interaction coalescing, debouncing and rate limiting
+// (DialogManager.SetRateLimit). Requests are keyed by InteractionID and Trigger.
+type RateLimitConfig = dialog.RateLimitConfig
+
+// TenantConfig limits one tenant namespace on a shared DialogManager
+// (DialogManager.RegisterTenant): its conversation memory and rate limit.
+type TenantConfig = dialog.TenantConfig
+
+// TenantHistoryStore is implemented by backends that keep each tenant's
+// conversation history isolated, such as the LLM backend.
+type TenantHistoryStore = dialog.TenantHistoryStore
+
+// ExperimentConfig describes an A/B split of default-backend traffic between
+// two registered backends (DialogManager.StartExperiment).
+type ExperimentConfig = dialog.ExperimentConfig
+
+// ExperimentResults reports per-arm assignments, responses and user
+// engagement for an A/B experiment.
+type ExperimentResults = dialog.ExperimentResults
+
+// ExperimentArmResult reports outcomes for one arm of an A/B experiment.
+type ExperimentArmResult = dialog.ExperimentArmResult
+
+// EnsembleBackend asks several registered backends for a response to the same
+// context and returns the best one, trading latency for quality.
+type EnsembleBackend = dialog.EnsembleBackend
+
+// EnsembleConfig lists an ensemble's member backends, the validators that
+// discard candidates and how much weight the reranker gets.
+type EnsembleConfig = dialog.EnsembleConfig
+
+// EnsembleCandidate is one member backend's scored response.
+type EnsembleCandidate = dialog.EnsembleCandidate
+
+// Reranker scores candidate responses to a context between 0 and 1. Its scores
+// are blended with confidence in response variants (LLMBackend.SetReranker),
+// ensembles (EnsembleBackend.SetReranker) and the fallback chain's best-of
+// selection (DialogManager.SetReranker).
+type Reranker = dialog.Reranker
+
+// RerankerFunc adapts a function into a Reranker.
+type RerankerFunc = dialog.RerankerFunc
+
+// HeuristicReranker is the built-in Reranker. It favors responses related to
+// the trigger, user message and topics that do not repeat the last response,
+// fit a preferred length and end as a complete sentence.
+type HeuristicReranker = dialog.HeuristicReranker
+
+// ModelFixture scripts deterministic model output for tests: a sequence of
+// replies or failures, trigger and text rules, a default reply, latency and
+// seeded failure injection. Set LLMConfig.MockFixture to a fixture file to use
+// one in place of the model.
+type ModelFixture = dialog.ModelFixture
+
+// FixtureRule maps a trigger or prompt text to scripted replies, returned in
+// rotation, or to an error.
+type FixtureRule = dialog.FixtureRule
+
+// FixtureStep is one scripted reply or failure in a ModelFixture sequence.
+// Errors named "timeout" and "busy" wrap ErrTimeout and ErrBackendBusy.
+type FixtureStep = dialog.FixtureStep
+
+// FixtureModel replays a ModelFixture. Its output depends only on the fixture
+// and call order, unlike the keyword-based mock model.
+type FixtureModel = dialog.FixtureModel
+
+// PromptStrategy lays out the prompt an LLMBackend sends to the model from
+// rendered PromptParts. Set one with LLMBackend.SetPromptStrategy or
+// WithPromptStrategy to reorder sections or use a chat format.
+type PromptStrategy = dialog.PromptStrategy
+
+// PromptStrategyFunc adapts an ordinary function into a PromptStrategy.
+type PromptStrategyFunc = dialog.PromptStrategyFunc
+
+// PromptParts are the rendered sections of a prompt: header, character state,
+// history, current situation and response guidelines, plus the exchanges and
+// context they were rendered from. Custom strategies must fit MaxTokens
+// themselves.
+type PromptParts = dialog.PromptParts
+
+// TokenEstimate sizes a prompt returned by LLMBackend.PreviewPrompt against
+// the model's context window, with per-section counts and whether sections
+// were trimmed to fit the budget.
+type TokenEstimate = dialog.TokenEstimate
+
+// ChatMLPromptStrategy formats prompts as ChatML system, user and assistant
+// turns for chat-tuned models.
+type ChatMLPromptStrategy = dialog.ChatMLPromptStrategy
+
+// ProductionLLMModel is the model interface LLMBackend generates with. Implement
+// it to plug in remote inference or another runtime with LLMBackend.SetModel
+// or WithProductionModel.
+type ProductionLLMModel = dialog.ProductionLLMModel
+
+// PredictOptions are the per-request sampling settings passed to
+// ProductionLLMModel.PredictWithOptions.
+type PredictOptions = dialog.PredictOptions
+
+// ModelInfo describes a loaded model (ProductionLLMModel.GetModelInfo).
+type ModelInfo = dialog.ModelInfo
+
+// Clock supplies the current time used for conversation timestamps, memory
+// decay, retention, rate limiting and event timestamps (SetClock). Latency
+// measurements always use the system clock.
+type Clock = dialog.Clock
+
+// ManualClock is a Clock that only moves when Advance or Set is called, for
+// deterministic tests and session replays.
+type ManualClock = dialog.ManualClock
+
+// Transcript is a recorded sequence of DialogContexts replayed through a
+// backend by ReplayTranscript, with optional per-turn expectations, a random
+// seed and a manual clock start for reproducible prompts.
+type Transcript = dialog.Transcript
+
+// TranscriptTurn is one recorded request in a Transcript.
+type TranscriptTurn = dialog.TranscriptTurn
+
+// ResponseExpectation holds structural assertions on a response, such as
+// required substrings, length bounds, response type and minimum confidence,
+// for checking output that varies between runs.
+type ResponseExpectation = dialog.ResponseExpectation
+
+// TranscriptResult records the prompt and response of every replayed turn.
+// GoldenText renders it for comparison with a golden file.
+type TranscriptResult = dialog.TranscriptResult
+
+// TurnResult is the outcome of one replayed transcript turn.
+type TurnResult = dialog.TurnResult
+
+// SessionRecord is one backend call captured by a SessionRecorder: the
+// context, the prompt the backend built, its response or error, the latency
+// and any feedback the user gave afterwards.
+type SessionRecord = dialog.SessionRecord
+
+// SessionRecorder writes the backend calls a DialogManager makes, and feedback
+// on their responses, to a JSONL stream. Attach it with
+// DialogManager.SetSessionRecorder.
+type SessionRecorder = dialog.SessionRecorder
+
+// SessionReplay is the outcome of ReplaySession. Summary counts what changed
+// and Report renders the recorded and replayed responses side by side.
+type SessionReplay = dialog.SessionReplay
+
+// ReplayTurn compares one recorded call with the replayed response.
+type ReplayTurn = dialog.ReplayTurn
+
+// ReplaySummary aggregates a SessionReplay: changed responses, split by the
+// feedback they had received, prompt changes, errors and mean latency before
+// and after.
+type ReplaySummary = dialog.ReplaySummary
+
+// LoadTestConfig configures a simulated multi-session load test (RunLoadTest):
+// the number of distinct InteractionIDs, concurrent workers, duration and
+// the relative weights of the triggers sent.
+type LoadTestConfig = dialog.LoadTestConfig
+
+// LoadTestReport summarizes a load test run with throughput, latency
+// percentiles, memory evictions, peak queue depth and heap growth.
+type LoadTestReport = dialog.LoadTestReport
+
 // DialogBackendConfig represents JSON configuration for dialog backends
 // including fallback chains and global settings.
 type DialogBackendConfig = dialog.DialogBackendConfig
@@ -104,31 +932,532 @@ func NewDialogManager(debug bool) *DialogManager {
 }
 
 // NewLLMBackend creates a new LLM-powered dialog backend with conservative
-// defaults optimized for consumer CPU hardware.
+// defaults optimized for consumer CPU hardware. Options configure it in code;
+// JSON passed to Initialize is applied over them and may be empty.
 //
 // Default settings:
 //   - MaxTokens: 50 (suitable for desktop pet responses)
 //   - Temperature: 0.7 (balanced creativity and consistency)
 //   - ContextSize: 2048 (fits most consumer hardware)
-//   - Threads: 4 (optimal for 4-8 core CPUs)
+//   - Threads: 0 (one per physical performance core, see AutoThreadCount)
 //   - Timeout: 2 seconds (responsive UX)
 //
 // Example:
 //
-//	backend := NewLLMBackend()
-//	config := LLMConfig{
+//	backend := NewLLMBackend(
+//		WithModelPath("/models/tinyllama-1.1b-q4.gguf"),
+//		WithLogger(log.New(os.Stderr, "pet: ", log.LstdFlags)),
+//	)
+//	err := backend.Initialize(nil)
+//
+// InitializeConfig takes a complete LLMConfig instead:
+//
+//	err := NewLLMBackend().InitializeConfig(LLMConfig{
 //		ModelPath: "/models/tinyllama-1.1b-q4.gguf",
 //		MarkovConfig: MarkovChainConfig{
-//			TrainingData: []string{
-//				"Hello! I'm so happy to see you! 😊",
-//				"Thanks for spending time with me!",
-//			},
+//			TrainingData: []string{"Hello! I'm so happy to see you! 😊"},
+//		},
+//	})
+func NewLLMBackend(opts ...LLMOption) *LLMBackend {
+	return dialog.NewLLMBackend(opts...)
+}
+
+// LLMOption configures an LLMBackend created by NewLLMBackend.
+type LLMOption = dialog.LLMOption
+
+// WithConfig starts an LLMBackend from cfg; later options change individual
+// settings.
+func WithConfig(cfg LLMConfig) LLMOption {
+	return dialog.WithConfig(cfg)
+}
+
+// WithModelPath sets the GGUF model file, or an hf:// path to download.
+func WithModelPath(path string) LLMOption {
+	return dialog.WithModelPath(path)
+}
+
+// WithModel sets a model alias looked up in the models.json registry.
+func WithModel(alias string) LLMOption {
+	return dialog.WithModel(alias)
+}
+
+// WithModelRegistry resolves model aliases with registry instead of the
+// default models.json.
+func WithModelRegistry(registry *ModelRegistry) LLMOption {
+	return dialog.WithModelRegistry(registry)
+}
+
+// WithProductionModel supplies the model implementation instead of loading
+// one from modelPath, which becomes optional. The backend initializes the
+// model and frees it on Close.
+//
+// Example:
+//
+//	backend := NewLLMBackend(WithProductionModel(remoteModel))
+//	err := backend.Initialize(nil)
+func WithProductionModel(model ProductionLLMModel) LLMOption {
+	return dialog.WithProductionModel(model)
+}
+
+// WithTimeout sets the response time budget.
+func WithTimeout(timeout time.Duration) LLMOption {
+	return dialog.WithTimeout(timeout)
+}
+
+// WithLogger sends the backend's warnings to logger instead of standard output.
+func WithLogger(logger *log.Logger) LLMOption {
+	return dialog.WithLogger(logger)
+}
+
+// WithClock timestamps and ages the backend's history and dates its prompts
+// with clock instead of the package clock set by SetClock.
+func WithClock(clock Clock) LLMOption {
+	return dialog.WithClock(clock)
+}
+
+// WithPromptStrategy replaces the default prompt layout; see PromptStrategy.
+func WithPromptStrategy(strategy PromptStrategy) LLMOption {
+	return dialog.WithPromptStrategy(strategy)
+}
+
+// ManagerOption configures a DialogManager created by NewDialogManagerWithOptions.
+type ManagerOption = dialog.ManagerOption
+
+// NewDialogManagerWithOptions creates a dialog manager configured by opts,
+// applied in order. The first backend added becomes the default unless
+// WithDefaultBackend names another.
+//
+// Example:
+//
+//	manager, err := NewDialogManagerWithOptions(
+//		WithBackend("llm", llmBackend),
+//		WithBackend("markov", markovBackend),
+//		WithFallbackChain("markov"),
+//		WithMiddleware(LoggingMiddleware(nil)),
+//	)
+func NewDialogManagerWithOptions(opts ...ManagerOption) (*DialogManager, error) {
+	return dialog.NewDialogManagerWithOptions(opts...)
+}
+
+// WithDebug enables debug mode.
+func WithDebug(debug bool) ManagerOption {
+	return dialog.WithDebug(debug)
+}
+
+// WithBackend registers a backend under name.
+func WithBackend(name string, backend DialogBackend) ManagerOption {
+	return dialog.WithBackend(name, backend)
+}
+
+// WithDefaultBackend sets the primary backend.
+func WithDefaultBackend(name string) ManagerOption {
+	return dialog.WithDefaultBackend(name)
+}
+
+// WithFallbackChain sets the backends tried when the default one fails.
+func WithFallbackChain(names ...string) ManagerOption {
+	return dialog.WithFallbackChain(names...)
+}
+
+// WithConfidenceThreshold sets the confidence below which the fallback chain
+// is tried.
+func WithConfidenceThreshold(threshold float64) ManagerOption {
+	return dialog.WithConfidenceThreshold(threshold)
+}
+
+// WithResponseTimeout bounds the time GenerateDialog spends across the default
+// backend and its fallback chain.
+func WithResponseTimeout(timeout time.Duration) ManagerOption {
+	return dialog.WithResponseTimeout(timeout)
+}
+
+// WithBackendTimeout bounds each call to the named backend, so a slow primary
+// gives up in time for its fallbacks.
+//
+// Example:
+//
+//	manager, err := dialog.NewDialogManagerWithOptions(
+//		dialog.WithBackend("llm", llmBackend),
+//		dialog.WithBackend("markov", markovBackend),
+//		dialog.WithFallbackChain("markov"),
+//		dialog.WithBackendTimeout("llm", 2*time.Second),
+//		dialog.WithBackendTimeout("markov", 50*time.Millisecond),
+//	)
+func WithBackendTimeout(name string, timeout time.Duration) ManagerOption {
+	return dialog.WithBackendTimeout(name, timeout)
+}
+
+// WithOverloadBackend routes requests to the named cheaper backend while the
+// backend that would serve them reports itself overloaded (OverloadReporter).
+//
+// Example:
+//
+//	manager, err := dialog.NewDialogManagerWithOptions(
+//		dialog.WithBackend("llm", llmBackend), // LLMConfig.AdaptiveQuality set
+//		dialog.WithBackend("markov", markovBackend),
+//		dialog.WithOverloadBackend("markov"),
+//	)
+func WithOverloadBackend(name string) ManagerOption {
+	return dialog.WithOverloadBackend(name)
+}
+
+// WithMiddleware wraps generation with middleware, outermost first.
+func WithMiddleware(middleware ...Middleware) ManagerOption {
+	return dialog.WithMiddleware(middleware...)
+}
+
+// WithReranker sets the reranker used to pick the best below-threshold response.
+func WithReranker(reranker Reranker) ManagerOption {
+	return dialog.WithReranker(reranker)
+}
+
+// NewEnsembleBackend creates an ensemble over backends registered with manager.
+// Initialize it with an EnsembleConfig, then register it like any backend.
+func NewEnsembleBackend(manager *DialogManager) *EnsembleBackend {
+	return dialog.NewEnsembleBackend(manager)
+}
+
+// NewContextManager creates a standalone conversation history store keeping up to
+// maxHistory exchanges per interaction.
+func NewContextManager(maxHistory int) *ContextManager {
+	return dialog.NewContextManager(maxHistory)
+}
+
+// RenderTranscript renders a conversation history as a readable Markdown or
+// HTML transcript with timestamps, triggers, responses and feedback.
+// ContextManager.ExportTranscript does the same for a stored conversation.
+//
+// Example:
+//
+//	text, err := backend.GetContextManager().ExportTranscript("user-123",
+//		dialog.TranscriptOptions{Format: dialog.TranscriptHTML, CharacterName: "Buddy"})
+func RenderTranscript(history ConversationHistory, opts TranscriptOptions) (string, error) {
+	return dialog.RenderTranscript(history, opts)
+}
+
+// WriteTrainingJSONL writes training examples as JSONL, one {"prompt", "response"}
+// object per line, for fine-tuning.
+//
+// Example:
+//
+//	examples := backend.ExportTrainingData(dialog.TrainingExportOptions{FeedbackOnly: true})
+//	f, _ := os.Create("finetune.jsonl")
+//	defer f.Close()
+//	err := dialog.WriteTrainingJSONL(f, examples)
+func WriteTrainingJSONL(w io.Writer, examples []TrainingExample) error {
+	return dialog.WriteTrainingJSONL(w, examples)
+}
+
+// AppendTrainingData adds the examples' responses to a character's
+// MarkovChainConfig.TrainingData, skipping sentences it already has.
+func AppendTrainingData(trainingData []string, examples []TrainingExample) []string {
+	return dialog.AppendTrainingData(trainingData, examples)
+}
+
+// ParseCharacterCard reads a character card from its JSON, or from a PNG with the
+// card embedded in a "chara" or "ccv3" text chunk. Persona gives the card's
+// instructions for LLMConfig.SystemPrompt, Examples its example dialog as training
+// pairs and Greetings its opening lines.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("mochi.png")
+//	card, err := dialog.ParseCharacterCard(data)
+//	config := dialog.LLMConfig{
+//		ModelPath:    "models/tinyllama.gguf",
+//		SystemPrompt: card.Persona("you"),
+//		MarkovConfig: dialog.MarkovChainConfig{
+//			TrainingData: dialog.AppendTrainingData(nil, card.Examples("you")),
 //		},
 //	}
-//	configJSON, _ := json.Marshal(config)
-//	err := backend.Initialize(configJSON)
-func NewLLMBackend() *LLMBackend {
-	return dialog.NewLLMBackend()
+func ParseCharacterCard(data []byte) (*CharacterCard, error) {
+	return dialog.ParseCharacterCard(data)
+}
+
+// ExportCharacterCard converts a MiniLM character and the exchanges it learned into
+// a character card for other tools. Encode writes it as Character Card v2 JSON and
+// EmbedPNG stores it in a PNG image.
+//
+// Example:
+//
+//	card, err := dialog.ExportCharacterCard(dialog.CharacterCardSource{
+//		Name:      "Mochi",
+//		Greetings: []string{"Hi there!"},
+//		Config:    llmConfig,
+//		Learned:   backend.ExportTrainingData(dialog.TrainingExportOptions{}),
+//	})
+//	image, _ := os.ReadFile("mochi.png")
+//	png, err := card.EmbedPNG(image)
+func ExportCharacterCard(source CharacterCardSource) (*CharacterCard, error) {
+	return dialog.ExportCharacterCard(source)
+}
+
+// NewFixtureModel creates a model that replays the given fixture.
+func NewFixtureModel(fixture ModelFixture) (*FixtureModel, error) {
+	return dialog.NewFixtureModel(fixture)
+}
+
+// LoadModelFixture reads a ModelFixture from a JSON file, rejecting unknown fields.
+//
+// Example fixture:
+//
+//	{
+//		"sequence": [{"response": "Welcome back!"}, {"error": "timeout"}],
+//		"rules": [{"trigger": "feed", "responses": ["Yum!", "More please!"]}],
+//		"default": "Hello!",
+//		"latencyMs": 50
+//	}
+func LoadModelFixture(path string) (ModelFixture, error) {
+	return dialog.LoadModelFixture(path)
+}
+
+// NewUserMemory creates an empty UserMemory.
+func NewUserMemory() *UserMemory {
+	return dialog.NewUserMemory()
+}
+
+// NewProactiveScheduler creates a ProactiveScheduler that generates through
+// manager. base supplies the current character state (InteractionID, mood and
+// stats) for each generation, and handler receives the responses.
+func NewProactiveScheduler(manager *DialogManager, config ProactiveConfig, base func() DialogContext, handler ProactiveHandler) (*ProactiveScheduler, error) {
+	return dialog.NewProactiveScheduler(manager, config, base, handler)
+}
+
+// NewWorldContext creates an empty shared world, applying defaults for unset values.
+func NewWorldContext(config WorldConfig) (*WorldContext, error) {
+	return dialog.NewWorldContext(config)
+}
+
+// DeriveSpeechHints works out how to say a response from its text and
+// EmotionalTone. Single *starred* or ALL-CAPS words are stressed, while starred
+// phrases such as "*wags tail*" and emoji are left unspoken.
+func DeriveSpeechHints(response DialogResponse, config SpeechConfig) SpeechHints {
+	return dialog.DeriveSpeechHints(response, config)
+}
+
+// NewVoicePipeline creates a VoicePipeline generating through manager,
+// applying defaults for unset values.
+func NewVoicePipeline(manager *DialogManager, config VoiceConfig) (*VoicePipeline, error) {
+	return dialog.NewVoicePipeline(manager, config)
+}
+
+// NewFactExtractor creates a FactExtractor, applying defaults for unset values.
+func NewFactExtractor(config ExtractionConfig) (*FactExtractor, error) {
+	return dialog.NewFactExtractor(config)
+}
+
+// NewPersonalityDrift creates a PersonalityDrift, applying defaults for unset
+// values. It fails when there are no rules or a rule has no trait or step.
+func NewPersonalityDrift(config DriftConfig) (*PersonalityDrift, error) {
+	return dialog.NewPersonalityDrift(config)
+}
+
+// NewPersonaBlend normalizes the profile weights into a blend. It fails when a
+// profile has no name, is listed twice or has a weight that is not positive.
+func NewPersonaBlend(profiles []PersonaProfile) (*PersonaBlend, error) {
+	return dialog.NewPersonaBlend(profiles)
+}
+
+// NewMoodEngine creates a MoodEngine, applying defaults for unset values.
+func NewMoodEngine(config MoodConfig) (*MoodEngine, error) {
+	return dialog.NewMoodEngine(config)
+}
+
+// ParseGrammar parses GBNF grammar source, which must define a root rule.
+//
+// Example grammar constraining output to a small JSON object:
+//
+//	root ::= "{\"mood\": \"" ("happy" | "sleepy") "\"}"
+func ParseGrammar(source string) (*Grammar, error) {
+	return dialog.ParseGrammar(source)
+}
+
+// LoadGrammarFile reads and parses a .gbnf grammar file.
+func LoadGrammarFile(path string) (*Grammar, error) {
+	return dialog.LoadGrammarFile(path)
+}
+
+// NewManualClock creates a ManualClock stopped at start.
+func NewManualClock(start time.Time) *ManualClock {
+	return dialog.NewManualClock(start)
+}
+
+// SetRandomSeed reseeds every random choice the dialog system makes, including
+// mock model replies, fallback selection, experiment assignment and retry
+// jitter, so runs with the same inputs produce the same responses.
+//
+// The source and clock are shared by the whole package; tests that change them
+// should not run in parallel with others.
+func SetRandomSeed(seed int64) {
+	dialog.SetRandomSeed(seed)
+}
+
+// NewContext starts building a DialogContext for an interaction raising trigger.
+//
+// Example:
+//
+//	ctx, err := dialog.NewContext("click").
+//	    WithMood(80).
+//	    WithTrait("cheerful", 0.9).
+//	    WithStat("hunger", 30).
+//	    Build()
+func NewContext(trigger string) *ContextBuilder {
+	return dialog.NewContext(trigger)
+}
+
+// RegisterTrigger adds or replaces a custom trigger for every backend, so
+// prompts describe it in words and responses without a more specific animation
+// play its default one. Registering a built-in trigger overrides its description.
+//
+// Example:
+//
+//	dialog.RegisterTrigger(dialog.TriggerDefinition{
+//	    Name:        "battery_low",
+//	    Description: "let the laptop battery run low",
+//	    Animation:   "sleepy",
+//	})
+func RegisterTrigger(definition TriggerDefinition) error {
+	return dialog.RegisterTrigger(definition)
+}
+
+// UnregisterTrigger removes a custom trigger, restoring the built-in
+// description if there is one.
+func UnregisterTrigger(name string) {
+	dialog.UnregisterTrigger(name)
+}
+
+// LookupTrigger returns the registered definition of a custom trigger.
+func LookupTrigger(name string) (TriggerDefinition, bool) {
+	return dialog.LookupTrigger(name)
+}
+
+// SetClock replaces the clock used for timestamps, memory decay, retention and
+// rate limiting. Passing nil restores the system clock.
+//
+// Example:
+//
+//	clock := dialog.NewManualClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+//	dialog.SetClock(clock)
+//	defer dialog.SetClock(nil)
+//	clock.Advance(2 * time.Hour) // conversation memory ages without sleeping
+func SetClock(clock Clock) {
+	dialog.SetClock(clock)
+}
+
+// LoadTranscript reads a Transcript from a JSON file.
+func LoadTranscript(path string) (Transcript, error) {
+	return dialog.LoadTranscript(path)
+}
+
+// ReplayTranscript sends each turn of a transcript to the backend in order and
+// checks the turn's expectations. Prompts are recorded for backends that
+// build them, such as the LLM backend.
+//
+// Example:
+//
+//	result := dialog.ReplayTranscript(backend, transcript)
+//	for _, failure := range result.Failures() {
+//		t.Error(failure)
+//	}
+//	golden, _ := os.ReadFile("testdata/session.golden")
+//	if string(golden) != result.GoldenText() {
+//		t.Error("prompt or response changed")
+//	}
+func ReplayTranscript(backend DialogBackend, transcript Transcript) TranscriptResult {
+	return dialog.ReplayTranscript(backend, transcript)
+}
+
+// NewSessionRecorder creates a recorder writing JSONL to w.
+func NewSessionRecorder(w io.Writer) *SessionRecorder {
+	return dialog.NewSessionRecorder(w)
+}
+
+// CreateSessionRecording creates a recorder appending to the file at path.
+// Close it to flush the file and report any write error.
+//
+// Example:
+//
+//	recorder, err := dialog.CreateSessionRecording("session.jsonl")
+//	if err != nil {
+//		return err
+//	}
+//	defer recorder.Close()
+//	manager.SetSessionRecorder(recorder)
+func CreateSessionRecording(path string) (*SessionRecorder, error) {
+	return dialog.CreateSessionRecording(path)
+}
+
+// LoadSessionRecording reads a recording written by a SessionRecorder, with
+// feedback attached to the calls it was given on.
+func LoadSessionRecording(path string) ([]SessionRecord, error) {
+	return dialog.LoadSessionRecording(path)
+}
+
+// ReplaySession sends each recorded context to the backend in order, with the
+// package clock held at the context's timestamp, so a new configuration or
+// model can be compared with the recorded session.
+//
+// Example:
+//
+//	records, _ := dialog.LoadSessionRecording("session.jsonl")
+//	replay := dialog.ReplaySession(candidate, records)
+//	fmt.Print(replay.Report())
+func ReplaySession(backend DialogBackend, records []SessionRecord) SessionReplay {
+	return dialog.ReplaySession(backend, records)
+}
+
+// NewEventBus creates an event bus with no subscribers. DialogManager creates
+// its own; use this for standalone ContextManagers.
+func NewEventBus() *EventBus {
+	return dialog.NewEventBus()
+}
+
+// PreHook adapts a function that runs before generation into Middleware.
+//
+// Example:
+//
+//	manager.Use(dialog.PreHook(func(ctx *dialog.DialogContext) (*dialog.DialogResponse, error) {
+//		if ctx.TimeOfDay == "" {
+//			ctx.TimeOfDay = "evening"
+//		}
+//		return nil, nil
+//	}))
+func PreHook(hook PreHookFunc) Middleware {
+	return dialog.PreHook(hook)
+}
+
+// PostHook adapts a function that runs after generation into Middleware.
+func PostHook(hook PostHookFunc) Middleware {
+	return dialog.PostHook(hook)
+}
+
+// LoggingMiddleware logs each generation's trigger, latency and outcome.
+// A nil logger uses the standard library default logger.
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return dialog.LoggingMiddleware(logger)
+}
+
+// TemporalContext returns middleware that fills empty TimeOfDay, DayOfWeek,
+// IsWeekend and IdleDuration so prompts always have temporal grounding. Idle
+// time is measured from the previous request with the same InteractionID.
+func TemporalContext() Middleware {
+	return dialog.TemporalContext()
+}
+
+// TimeOfDayAt maps a clock time to "morning", "afternoon", "evening" or "night".
+func TimeOfDayAt(t time.Time) string {
+	return dialog.TimeOfDayAt(t)
+}
+
+// EnrichTemporalContext fills empty temporal fields of ctx from now and the time
+// of the previous interaction (zero to use ctx.InteractionHistory).
+func EnrichTemporalContext(ctx DialogContext, now, lastInteraction time.Time) DialogContext {
+	return dialog.EnrichTemporalContext(ctx, now, lastInteraction)
+}
+
+// LatencyMiddleware reports the duration of every generation to the callback.
+func LatencyMiddleware(record func(context DialogContext, latency time.Duration)) Middleware {
+	return dialog.LatencyMiddleware(record)
 }
 
 // Utility functions for configuration management
@@ -139,7 +1468,7 @@ func NewLLMBackend() *LLMBackend {
 // Validation rules:
 //   - DefaultBackend is required when dialog system is enabled
 //   - ConfidenceThreshold must be between 0 and 1
-//   - ResponseTimeout must be non-negative
+//   - ResponseTimeout and BackendTimeouts must be non-negative
 //
 // Example:
 //
@@ -158,6 +1487,9 @@ func ValidateBackendConfig(config DialogBackendConfig) error {
 // LoadDialogBackendConfig loads backend configuration from JSON data.
 // Sets sensible defaults for missing optional fields.
 //
+// Configs written for an older schemaVersion are migrated first; see
+// MigrateDialogBackendConfig.
+//
 // Default values:
 //   - ConfidenceThreshold: 0.5
 //   - ResponseTimeout: 1000ms
@@ -178,11 +1510,135 @@ func LoadDialogBackendConfig(data []byte) (DialogBackendConfig, error) {
 	return dialog.LoadDialogBackendConfig(data)
 }
 
+// ConfigOverrides replaces LLM backend settings (modelPath, threads,
+// timeoutMs) from a character file at deploy time. Zero values leave the JSON
+// setting unchanged.
+type ConfigOverrides = dialog.ConfigOverrides
+
+// EnvConfigOverrides reads MINILM_MODEL_PATH, MINILM_THREADS (a number or
+// "auto") and MINILM_TIMEOUT_MS through lookup, usually os.LookupEnv.
+//
+// Combine it with ConfigOverrides.RegisterFlags so command-line flags take
+// precedence over the environment, which takes precedence over JSON:
+//
+//	overrides, err := EnvConfigOverrides(os.LookupEnv)
+//	overrides.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//	config, err := LoadDialogBackendConfigWithOverrides(data, overrides)
+func EnvConfigOverrides(lookup func(string) (string, bool)) (ConfigOverrides, error) {
+	return dialog.EnvConfigOverrides(lookup)
+}
+
+// LoadDialogBackendConfigWithOverrides loads backend configuration like
+// LoadDialogBackendConfig, then applies overrides to the "llm" backend when
+// the config has one.
+func LoadDialogBackendConfigWithOverrides(data []byte, overrides ConfigOverrides) (DialogBackendConfig, error) {
+	return dialog.LoadDialogBackendConfigWithOverrides(data, overrides)
+}
+
+// EncryptedState is the JSON envelope written by EncryptState and by
+// DialogManager.SaveState when a state key is set.
+type EncryptedState = dialog.EncryptedState
+
+// ParseStateKey decodes a hex-encoded 16, 24 or 32 byte AES key, such as the
+// value of EnvStateKey.
+func ParseStateKey(encoded string) ([]byte, error) {
+	return dialog.ParseStateKey(encoded)
+}
+
+// EncryptState seals persisted data, such as a ContextManager.Export, with
+// AES-GCM under a host-provided key so it is unreadable on disk.
+func EncryptState(key, plaintext []byte) ([]byte, error) {
+	return dialog.EncryptState(key, plaintext)
+}
+
+// DecryptState opens data written by EncryptState. A wrong key or modified
+// data returns an error.
+func DecryptState(key, data []byte) ([]byte, error) {
+	return dialog.DecryptState(key, data)
+}
+
+// IsEncryptedState reports whether data was written by EncryptState.
+func IsEncryptedState(data []byte) bool {
+	return dialog.IsEncryptedState(data)
+}
+
+// MigrateDialogBackendConfig upgrades dialogBackend JSON written for an older
+// schemaVersion to CurrentConfigSchemaVersion. Configs without a schemaVersion
+// are treated as version 0. Data that is already current is returned unchanged,
+// and configs from a newer version than this package supports are rejected.
+//
+// LoadDialogBackendConfig calls this automatically; use it directly to upgrade
+// character files on disk.
+func MigrateDialogBackendConfig(data []byte) ([]byte, error) {
+	return dialog.MigrateDialogBackendConfig(data)
+}
+
+// ConfigDiagnostic is one problem DiagnoseConfig found, located by a JSON path
+// such as $.dialogBackend.fallbackChain[1].
+type ConfigDiagnostic = dialog.ConfigDiagnostic
+
+// Severities of a ConfigDiagnostic. Only errors make a config unusable;
+// warnings flag settings that probably do not do what was intended.
+const (
+	SeverityError   = dialog.SeverityError
+	SeverityWarning = dialog.SeverityWarning
+)
+
+// DiagnoseConfig checks a character file, a dialogBackend config or a bare
+// LLM backend config without loading a model and returns every problem found:
+// missing model, fixture and grammar files, maxTokens that leave no room for a
+// prompt in contextSize, defaultBackend and fallbackChain entries that name
+// unconfigured backends, out-of-range settings and malformed JSON.
+//
+// Example:
+//
+//	for _, diagnostic := range DiagnoseConfig(data) {
+//		fmt.Println(diagnostic)
+//	}
+func DiagnoseConfig(data []byte) []ConfigDiagnostic {
+	return dialog.DiagnoseConfig(data)
+}
+
+// HostBackends returns the backends the host application registers itself, such
+// as markov_chain and simple_random. Characters may reference them without a
+// block in backends; DiagnoseConfig reports that the host must register them.
+func HostBackends() []string {
+	return dialog.HostBackends()
+}
+
+// ConfigSchema returns the JSON Schema (draft 2020-12) generated from the config
+// structs, for editor autocomplete and validation in asset pipelines:
+// SchemaLLMConfig for an llm backend block, SchemaDialogBackend for a
+// dialogBackend section, or SchemaCharacter for a character file.
+//
+// Example:
+//
+//	schema, err := dialog.ConfigSchema(dialog.SchemaCharacter)
+//	err = os.WriteFile("character.schema.json", schema, 0o644)
+func ConfigSchema(name string) ([]byte, error) {
+	return dialog.ConfigSchema(name)
+}
+
+// RunLoadTest drives a configured DialogManager with many concurrent simulated
+// sessions, exercising conversation memory eviction and backend queueing. It
+// runs until config.DurationMs elapses, config.MaxRequests have been sent or
+// ctx is cancelled.
+//
+// Example:
+//
+//	report, err := RunLoadTest(ctx, manager, LoadTestConfig{Sessions: 500, DurationMs: 30000})
+//	fmt.Printf("%.1f req/s, p95 %v, heap growth %d bytes\n", report.Throughput, report.P95, report.HeapGrowth())
+func RunLoadTest(ctx context.Context, dm *DialogManager, config LoadTestConfig) (LoadTestReport, error) {
+	return dialog.RunLoadTest(ctx, dm, config)
+}
+
 // UpdateBackendMemory records interaction outcomes for backend learning.
 // This enables backends to adapt based on user interactions and feedback.
 //
-// The method finds the backend that can handle the given context and
-// updates its memory with the interaction outcome.
+// The method finds the learning-capable backend that can handle the given
+// context and updates its memory with the interaction outcome. It returns a
+// *CapabilityError when no backend advertises CapabilityLearning.
 //
 // Example:
 //
@@ -191,13 +1647,116 @@ func LoadDialogBackendConfig(data []byte) (DialogBackendConfig, error) {
 //		Engagement: 0.9,
 //	}
 //	UpdateBackendMemory(manager, context, response, feedback)
-func UpdateBackendMemory(dm *DialogManager, context DialogContext, response DialogResponse, feedback *UserFeedback) {
-	dm.UpdateBackendMemory(context, response, feedback)
+func UpdateBackendMemory(dm *DialogManager, context DialogContext, response DialogResponse, feedback *UserFeedback) error {
+	return dm.UpdateBackendMemory(context, response, feedback)
 }
 
+// StreamingBackend is implemented by backends that deliver a response in
+// chunks as it is produced. DialogManager.GenerateDialogStream only routes to
+// backends that also advertise CapabilityStreaming.
+type StreamingBackend = dialog.StreamingBackend
+
+// CapabilityError reports a request that needed a capability its backend does
+// not advertise in BackendInfo.Capabilities, such as streaming from a backend
+// without CapabilityStreaming. errors.Is(err, ErrCapabilityUnsupported)
+// matches every CapabilityError.
+type CapabilityError = dialog.CapabilityError
+
+// ErrCapabilityUnsupported matches every CapabilityError with errors.Is.
+var ErrCapabilityUnsupported = dialog.ErrCapabilityUnsupported
+
+// Capabilities a backend can advertise in BackendInfo.Capabilities.
+// DialogManager routes streaming requests only to CapabilityStreaming backends
+// and learning updates only to CapabilityLearning backends.
+const (
+	CapabilityContextAware      = dialog.CapabilityContextAware
+	CapabilityPersonalityDriven = dialog.CapabilityPersonalityDriven
+	CapabilityLearning          = dialog.CapabilityLearning
+	CapabilityStreaming         = dialog.CapabilityStreaming
+)
+
 // Version and metadata
 
 const (
+	// ConversationExportVersion is the format version written by ContextManager.Export
+	ConversationExportVersion = dialog.ConversationExportVersion
+
+	// PersonalityDriftExportVersion is the format version written by PersonalityDrift.Export
+	PersonalityDriftExportVersion = dialog.PersonalityDriftExportVersion
+
+	// UserMemoryExportVersion is the format version written by UserMemory.Export
+	UserMemoryExportVersion = dialog.UserMemoryExportVersion
+
+	// ContextStateVersion is the format version written by ContextManager.SaveState
+	ContextStateVersion = dialog.ContextStateVersion
+
+	// DialogStateVersion is the format version written by DialogManager.SaveState
+	DialogStateVersion = dialog.DialogStateVersion
+
+	// UserDataExportVersion is the format version written by
+	// DialogManager.ExportUserData
+	UserDataExportVersion = dialog.UserDataExportVersion
+
+	// CurrentConfigSchemaVersion is the DialogBackendConfig schemaVersion this
+	// package reads; older configs are migrated when loaded
+	CurrentConfigSchemaVersion = dialog.CurrentConfigSchemaVersion
+
+	// EnvModelPath, EnvThreads and EnvTimeoutMs are the environment variables
+	// read by EnvConfigOverrides
+	EnvModelPath = dialog.EnvModelPath
+	EnvThreads   = dialog.EnvThreads
+	EnvTimeoutMs = dialog.EnvTimeoutMs
+
+	// EnvStateKey holds the hex-encoded AES key minilm-chat uses to encrypt
+	// its -state file
+	EnvStateKey = dialog.EnvStateKey
+
+	// EncryptedStateVersion and EncryptedStateAlgorithm identify the envelope
+	// written by EncryptState
+	EncryptedStateVersion   = dialog.EncryptedStateVersion
+	EncryptedStateAlgorithm = dialog.EncryptedStateAlgorithm
+
+	// MetadataExperiment and MetadataExperimentArm are the DialogResponse.Metadata
+	// keys identifying the experiment and arm that produced a response
+	MetadataExperiment    = dialog.MetadataExperiment
+	MetadataExperimentArm = dialog.MetadataExperimentArm
+
+	// MetadataEscalated marks a below-threshold response chosen because no
+	// backend in the fallback chain met the confidence threshold; its value is
+	// the backend that produced it
+	MetadataEscalated = dialog.MetadataEscalated
+
+	// MetadataEnsembleBackend, MetadataEnsembleScore and MetadataEnsembleCandidates
+	// record which member of an EnsembleBackend produced a response, its score
+	// and how many candidates passed validation
+	MetadataEnsembleBackend    = dialog.MetadataEnsembleBackend
+	MetadataEnsembleScore      = dialog.MetadataEnsembleScore
+	MetadataEnsembleCandidates = dialog.MetadataEnsembleCandidates
+
+	// MetadataRateLimit marks responses served by rate limiting instead of a
+	// fresh generation; values are RateLimitCoalesced, RateLimitDebounced and
+	// RateLimitExceeded
+	MetadataRateLimit  = dialog.MetadataRateLimit
+	RateLimitCoalesced = dialog.RateLimitCoalesced
+	RateLimitDebounced = dialog.RateLimitDebounced
+	RateLimitExceeded  = dialog.RateLimitExceeded
+
+	// HistorySelectionImportant and HistorySelectionRelevant are the
+	// LLMConfig.HistorySelection strategies for choosing prompt history
+	HistorySelectionImportant = dialog.HistorySelectionImportant
+	HistorySelectionRelevant  = dialog.HistorySelectionRelevant
+
+	// PersonaSummaryHeuristic and PersonaSummaryModel are the LLMConfig.PersonaSummary
+	// modes for distilling training data into a persona paragraph
+	PersonaSummaryHeuristic = dialog.PersonaSummaryHeuristic
+	PersonaSummaryModel     = dialog.PersonaSummaryModel
+
+	// SchemaLLMConfig, SchemaDialogBackend and SchemaCharacter name the schemas
+	// ConfigSchema generates
+	SchemaLLMConfig     = dialog.SchemaLLMConfig
+	SchemaDialogBackend = dialog.SchemaDialogBackend
+	SchemaCharacter     = dialog.SchemaCharacter
+
 	// Version represents the current version of the dialog API
 	Version = "1.0.0"
 
@@ -223,10 +1782,22 @@ func GetAPIInfo() map[string]interface{} {
 			"personality_extraction",
 			"fallback_chains",
 			"memory_tracking",
+			"analytics",
+			"middleware",
+			"response_validation",
+			"experiments",
+			"health_checks",
+			"events",
+			"rate_limiting",
+			"load_testing",
+			"model_fixtures",
+			"deterministic_mode",
+			"transcript_replay",
 		},
 		"backends": []string{
 			"llm",
 			"mock",
+			"fixture",
 		},
 	}
 }
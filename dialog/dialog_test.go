@@ -1,6 +1,7 @@
 package dialog
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -206,6 +207,52 @@ func TestLoadDialogBackendConfig(t *testing.T) {
 	}
 }
 
+// TestMigrateDialogBackendConfig tests that unversioned configs are upgraded
+func TestMigrateDialogBackendConfig(t *testing.T) {
+	migrated, err := MigrateDialogBackendConfig([]byte(`{"enabled": true, "defaultBackend": "llm"}`))
+	if err != nil {
+		t.Fatalf("MigrateDialogBackendConfig() failed: %v", err)
+	}
+
+	config, err := LoadDialogBackendConfig(migrated)
+	if err != nil {
+		t.Fatalf("LoadDialogBackendConfig() failed on migrated config: %v", err)
+	}
+	if config.SchemaVersion != CurrentConfigSchemaVersion {
+		t.Errorf("Expected schemaVersion %d, got %d", CurrentConfigSchemaVersion, config.SchemaVersion)
+	}
+}
+
+// TestRunLoadTest tests the public load test wrapper against an LLM backend
+func TestRunLoadTest(t *testing.T) {
+	manager := NewDialogManager(false)
+	backend := NewLLMBackend()
+	configJSON, _ := json.Marshal(LLMConfig{
+		ModelPath: "/path/to/model.gguf",
+		MarkovConfig: MarkovChainConfig{
+			TrainingData: []string{"Hello there! I'm happy to see you!"},
+		},
+	})
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	manager.RegisterBackend("llm", backend)
+	manager.SetDefaultBackend("llm")
+
+	report, err := RunLoadTest(context.Background(), manager, LoadTestConfig{
+		Sessions:    10,
+		Workers:     4,
+		MaxRequests: 20,
+		Seed:        1,
+	})
+	if err != nil {
+		t.Fatalf("RunLoadTest() failed: %v", err)
+	}
+	if report.Requests != 20 {
+		t.Errorf("Expected 20 requests, got %d", report.Requests)
+	}
+}
+
 // TestVersionInfo tests the version and API information functions
 func TestVersionInfo(t *testing.T) {
 	version := GetVersion()
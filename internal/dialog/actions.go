@@ -0,0 +1,109 @@
+package dialog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ToolDefinition describes a host action a character may request, such as
+// "play_animation", "open_minigame" or "set_reminder"
+type ToolDefinition struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  map[string]string `json:"parameters,omitempty"` // Argument names and what they mean
+}
+
+// Action is a host action requested by the character in a response
+type Action struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+var (
+	toolNamePattern   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	actionCallPattern = regexp.MustCompile(`\[\[\s*([A-Za-z][A-Za-z0-9_-]*)((?:\s+[A-Za-z0-9_-]+\s*=\s*"[^"]*")*)\s*\]\]`)
+	actionArgPattern  = regexp.MustCompile(`([A-Za-z0-9_-]+)\s*=\s*"([^"]*)"`)
+)
+
+// applyTools registers the host actions the model may request
+func (llm *LLMBackend) applyTools(cfg LLMConfig) error {
+	tools := make(map[string]ToolDefinition, len(cfg.Tools))
+	for _, tool := range cfg.Tools {
+		if !toolNamePattern.MatchString(tool.Name) {
+			return configErrorf("tools", "invalid tool name %q: use letters, digits, _ and -", tool.Name)
+		}
+		if _, exists := tools[tool.Name]; exists {
+			return configErrorf("tools", "tool %q is defined twice", tool.Name)
+		}
+		tools[tool.Name] = tool
+	}
+	llm.tools = cfg.Tools
+	llm.toolSet = tools
+	return nil
+}
+
+// extractActionCalls removes [[name key="value"]] calls from text and returns them as actions
+func extractActionCalls(text string) (string, []Action) {
+	var actions []Action
+	for _, match := range actionCallPattern.FindAllStringSubmatch(text, -1) {
+		action := Action{Name: match[1]}
+		for _, arg := range actionArgPattern.FindAllStringSubmatch(match[2], -1) {
+			if action.Arguments == nil {
+				action.Arguments = make(map[string]string)
+			}
+			action.Arguments[arg[1]] = arg[2]
+		}
+		actions = append(actions, action)
+	}
+	if len(actions) == 0 {
+		return text, nil
+	}
+
+	remaining := actionCallPattern.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(remaining), " "), actions
+}
+
+// checkActions rejects actions that are not configured or use unknown arguments
+func (llm *LLMBackend) checkActions(actions []Action) error {
+	for _, action := range actions {
+		tool, exists := llm.toolSet[action.Name]
+		if !exists {
+			return fmt.Errorf("response requests unknown action %q", action.Name)
+		}
+		for name := range action.Arguments {
+			if _, known := tool.Parameters[name]; !known {
+				return fmt.Errorf("action %q has unknown argument %q", action.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// toolInstructions lists the available actions and, in text mode, how to request them
+func toolInstructions(tools []ToolDefinition, structured bool) string {
+	var instructions strings.Builder
+	if structured {
+		instructions.WriteString("- You may ask the app to act by listing actions in \"actions\". Available actions:\n")
+	} else {
+		instructions.WriteString("- You may ask the app to act by adding [[action argument=\"value\"]] to your reply. Available actions:\n")
+	}
+	for _, tool := range tools {
+		names := make([]string, 0, len(tool.Parameters))
+		for name := range tool.Parameters {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		instructions.WriteString("  - " + tool.Name)
+		if tool.Description != "" {
+			instructions.WriteString(": " + tool.Description)
+		}
+		for _, name := range names {
+			instructions.WriteString(fmt.Sprintf(" (%s: %s)", name, tool.Parameters[name]))
+		}
+		instructions.WriteString("\n")
+	}
+	return instructions.String()
+}
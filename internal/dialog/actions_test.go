@@ -0,0 +1,90 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+var petTools = []ToolDefinition{
+	{Name: "play_animation", Description: "Play an animation", Parameters: map[string]string{"animation": "which animation"}},
+	{Name: "set_reminder", Description: "Remind the user later", Parameters: map[string]string{"time": "HH:MM", "text": "what to say"}},
+	{Name: "open_minigame", Description: "Start the fetch minigame"},
+}
+
+func TestExtractActionCalls(t *testing.T) {
+	text, actions := extractActionCalls(`Dinner time! [[set_reminder time="18:00" text="Feed me"]] See you then [[open_minigame]]`)
+	if text != "Dinner time! See you then" {
+		t.Errorf("Expected calls removed from the text, got %q", text)
+	}
+	if len(actions) != 2 || actions[0].Name != "set_reminder" || actions[0].Arguments["time"] != "18:00" ||
+		actions[0].Arguments["text"] != "Feed me" || actions[1].Name != "open_minigame" || actions[1].Arguments != nil {
+		t.Errorf("Unexpected actions: %+v", actions)
+	}
+
+	plain := "No [actions] here [[ or here"
+	if text, actions := extractActionCalls(plain); text != plain || actions != nil {
+		t.Errorf("Expected text without calls unchanged, got %q %+v", text, actions)
+	}
+}
+
+func TestLLMBackend_ToolCalls(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{
+		`Wheee! [[do_backflip]]`,
+		`Wheee! [[play_animation animation="spin" speed="fast"]]`,
+		`Wheee! [[play_animation animation="spin"]]`,
+	}}
+	backend := newScriptedBackend(t, LLMConfig{Tools: petTools, FallbackEnabled: false}, model)
+	context := DialogContext{Trigger: "play", InteractionID: "user-1"}
+
+	response, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+	if response.Text != "Wheee!" || len(response.Actions) != 1 || response.Actions[0].Arguments["animation"] != "spin" {
+		t.Errorf("Expected a known action with known arguments, got %+v", response)
+	}
+	if model.callCount() != 3 {
+		t.Errorf("Expected unknown actions and arguments to be regenerated, got %d calls", model.callCount())
+	}
+	if history := backend.contextManager.GetHistory("user-1", 0); history[0].Response != "Wheee!" {
+		t.Errorf("Expected the text without calls in memory, got %+v", history)
+	}
+
+	prompt := backend.buildPrompt(context)
+	if !strings.Contains(prompt, `[[action argument="value"]]`) || !strings.Contains(prompt, "set_reminder: Remind the user later (text: what to say) (time: HH:MM)") {
+		t.Errorf("Expected the tools described in the prompt, got %q", prompt)
+	}
+}
+
+func TestLLMBackend_ToolCallsInJSONMode(t *testing.T) {
+	reply := `{"text": "Let's play!", "emotion": "excited", "animation": "happy", "actions": [{"name": "open_minigame"}]}`
+	model := &scriptedTestModel{responses: []string{reply}}
+	backend := newScriptedBackend(t, LLMConfig{Tools: petTools, ResponseFormat: ResponseFormatJSON}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "play"})
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+	if len(response.Actions) != 1 || response.Actions[0].Name != "open_minigame" {
+		t.Errorf("Expected the JSON action, got %+v", response)
+	}
+
+	grammar, err := ParseGrammar(model.calls[0].Grammar)
+	if err != nil || !grammar.Match(reply) {
+		t.Errorf("Expected the JSON grammar to allow actions (%v)", err)
+	}
+}
+
+func TestLLMBackend_ToolConfigErrors(t *testing.T) {
+	configs := map[string]string{
+		"invalid name": `{"modelPath": "/fake/path.gguf", "tools": [{"name": "play animation"}]}`,
+		"duplicate":    `{"modelPath": "/fake/path.gguf", "tools": [{"name": "wave"}, {"name": "wave"}]}`,
+	}
+	for name, config := range configs {
+		backend := NewLLMBackend()
+		if err := backend.Initialize([]byte(config)); err == nil {
+			t.Errorf("%s: expected Initialize to fail", name)
+			backend.Close()
+		}
+	}
+}
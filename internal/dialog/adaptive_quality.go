@@ -0,0 +1,193 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AdaptiveQualityConfig trades response length for latency while the backend is under load
+// Every window generations the slowest recent latencies (90th percentile, queue wait
+// included) are compared with the target: above it maxTokens is cut by a quarter, below
+// three quarters of it a quarter is restored. Once already at minTokens and still too
+// slow, the backend reports itself overloaded so DialogManager can route to its
+// overload backend, and tries again after recoveryMs
+type AdaptiveQualityConfig struct {
+	TargetLatencyMs int `json:"targetLatencyMs,omitempty"` // Interactive latency to stay under (0 = disabled)
+	Window          int `json:"window,omitempty"`          // Generations judged per adjustment (default: 10)
+	MinTokens       int `json:"minTokens,omitempty"`       // Smallest maxTokens it trims to (default: 16)
+	RecoveryMs      int `json:"recoveryMs,omitempty"`      // Time reported overloaded before trying again (default: 5000)
+}
+
+// OverloadReporter is implemented by backends that can tell when they are too slow to serve
+// interactive requests even at their lowest quality
+type OverloadReporter interface {
+	Overloaded() bool
+}
+
+// adaptiveQuality is the resolved form of AdaptiveQualityConfig and its recent latencies
+type adaptiveQuality struct {
+	target    time.Duration
+	window    int
+	minTokens int
+	recovery  time.Duration
+
+	mu              sync.Mutex
+	samples         []time.Duration // Latencies since the last adjustment
+	scale           float64         // Fraction of maxTokens currently allowed
+	overloadedUntil time.Time
+}
+
+// applyAdaptiveQuality enables latency-adaptive maxTokens when a target latency is set
+func (llm *LLMBackend) applyAdaptiveQuality(cfg AdaptiveQualityConfig) error {
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"targetLatencyMs", cfg.TargetLatencyMs},
+		{"window", cfg.Window},
+		{"minTokens", cfg.MinTokens},
+		{"recoveryMs", cfg.RecoveryMs},
+	} {
+		if field.value < 0 {
+			return configErrorf("adaptiveQuality."+field.name, "adaptiveQuality.%s must be non-negative, got %d", field.name, field.value)
+		}
+	}
+
+	llm.adaptive = nil
+	if cfg.TargetLatencyMs == 0 {
+		return nil
+	}
+	adaptive := &adaptiveQuality{
+		target:    time.Duration(cfg.TargetLatencyMs) * time.Millisecond,
+		window:    10,
+		minTokens: 16,
+		recovery:  5 * time.Second,
+		scale:     1,
+	}
+	if cfg.Window > 0 {
+		adaptive.window = cfg.Window
+	}
+	if cfg.MinTokens > 0 {
+		adaptive.minTokens = cfg.MinTokens
+	}
+	if cfg.RecoveryMs > 0 {
+		adaptive.recovery = time.Duration(cfg.RecoveryMs) * time.Millisecond
+	}
+	llm.adaptive = adaptive
+	return nil
+}
+
+// maxTokens returns the response length currently allowed out of the configured maxTokens
+func (a *adaptiveQuality) maxTokens(configured int) int {
+	if a == nil {
+		return configured
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.tokensAt(configured, a.scale)
+}
+
+// tokensAt scales configured maxTokens, never below minTokens (or configured when smaller)
+func (a *adaptiveQuality) tokensAt(configured int, scale float64) int {
+	return max(int(float64(configured)*scale), min(a.minTokens, configured))
+}
+
+// observe records a generation's latency and adjusts the scale once a window is complete
+// configured is the backend's maxTokens, used to tell when the floor has been reached
+func (a *adaptiveQuality) observe(latency time.Duration, configured int) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.samples = append(a.samples, latency)
+	if len(a.samples) < a.window {
+		return
+	}
+	slow := latencyPercentile(a.sortedSamples(), 90)
+	a.samples = a.samples[:0]
+
+	switch {
+	case slow > a.target:
+		if a.tokensAt(configured, a.scale) <= min(a.minTokens, configured) {
+			a.overloadedUntil = currentTime().Add(a.recovery)
+			return
+		}
+		a.scale *= 0.75
+	case slow < a.target*3/4 && a.scale < 1:
+		a.scale = min(a.scale/0.75, 1)
+	}
+}
+
+// sortedSamples returns a sorted copy of the window's latencies
+func (a *adaptiveQuality) sortedSamples() []time.Duration {
+	sorted := append([]time.Duration(nil), a.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// overloaded reports whether the backend is still too slow at its lowest quality; after
+// the recovery period requests return to it and the next window decides again
+func (a *adaptiveQuality) overloaded() bool {
+	if a == nil {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return currentTime().Before(a.overloadedUntil)
+}
+
+// Overloaded reports whether recent generations missed the adaptive quality target even
+// with maxTokens at its floor; always false without adaptiveQuality
+func (llm *LLMBackend) Overloaded() bool {
+	llm.mu.RLock()
+	adaptive := llm.adaptive
+	llm.mu.RUnlock()
+	return adaptive.overloaded()
+}
+
+// observeLatency feeds a generation's latency to adaptive quality; abandoned requests are
+// left out since their latency says nothing about load
+func (llm *LLMBackend) observeLatency(latency time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	llm.adaptive.observe(latency, llm.maxTokens)
+}
+
+// SetOverloadBackend routes requests away from a backend that reports itself overloaded
+// (see OverloadReporter) to the named cheaper backend, e.g. a markov chain standing in for
+// an llm under load, until it recovers; an empty name removes the route
+func (dm *DialogManager) SetOverloadBackend(name string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if name != "" {
+		if _, exists := dm.backends[name]; !exists {
+			return fmt.Errorf("overload backend '%s' not registered", name)
+		}
+	}
+	dm.overloadBackend = name
+	return nil
+}
+
+// relieveOverload returns the overload backend in place of name while name reports itself
+// overloaded, otherwise name
+func (dm *DialogManager) relieveOverload(name string) string {
+	dm.mu.RLock()
+	overloadBackend := dm.overloadBackend
+	backend := dm.backends[name]
+	dm.mu.RUnlock()
+
+	if overloadBackend == "" || overloadBackend == name {
+		return name
+	}
+	if reporter, ok := backend.(OverloadReporter); ok && reporter.Overloaded() {
+		return overloadBackend
+	}
+	return name
+}
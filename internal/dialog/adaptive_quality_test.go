@@ -0,0 +1,157 @@
+package dialog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// overloadTestBackend is a fixedTestBackend that reports overload on demand
+type overloadTestBackend struct {
+	fixedTestBackend
+	overloaded bool
+}
+
+func (b *overloadTestBackend) Overloaded() bool { return b.overloaded }
+
+func TestAdaptiveQuality_DegradesAndRecovers(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+	defer SetClock(nil)
+
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.applyAdaptiveQuality(AdaptiveQualityConfig{TargetLatencyMs: 1000, Window: 2, MinTokens: 20}); err != nil {
+		t.Fatalf("applyAdaptiveQuality failed: %v", err)
+	}
+	adaptive := backend.adaptive
+	window := func(latency time.Duration) {
+		adaptive.observe(latency, 50)
+		adaptive.observe(latency, 50)
+	}
+
+	// A partial window changes nothing
+	adaptive.observe(2*time.Second, 50)
+	if got := adaptive.maxTokens(50); got != 50 {
+		t.Errorf("Expected full maxTokens before a window completes, got %d", got)
+	}
+	adaptive.observe(2*time.Second, 50)
+	if got := adaptive.maxTokens(50); got != 37 {
+		t.Errorf("Expected maxTokens cut by a quarter, got %d", got)
+	}
+
+	window(2 * time.Second)
+	window(2 * time.Second)
+	if got := adaptive.maxTokens(50); got != 21 {
+		t.Errorf("Expected maxTokens to keep shrinking, got %d", got)
+	}
+	window(2 * time.Second)
+	if got := adaptive.maxTokens(50); got != 20 || backend.Overloaded() {
+		t.Errorf("Expected maxTokens at the floor without overload, got %d (overloaded %v)", got, backend.Overloaded())
+	}
+
+	// Still too slow at the floor reports overload until the recovery period passes
+	window(2 * time.Second)
+	if !backend.Overloaded() {
+		t.Error("Expected overload once slow at the floor")
+	}
+	clock.Advance(5 * time.Second)
+	if backend.Overloaded() {
+		t.Error("Expected overload to lift after recoveryMs")
+	}
+
+	// Latencies near the target hold quality; well under it restore it step by step
+	window(900 * time.Millisecond)
+	if got := adaptive.maxTokens(50); got != 20 {
+		t.Errorf("Expected maxTokens held near the target, got %d", got)
+	}
+	for range 5 {
+		window(100 * time.Millisecond)
+	}
+	if got := adaptive.maxTokens(50); got != 50 {
+		t.Errorf("Expected full maxTokens once latencies recover, got %d", got)
+	}
+}
+
+func TestLLMBackend_AdaptiveMaxTokens(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hello there!"}}
+	backend := newScriptedBackend(t, LLMConfig{
+		MaxTokens:       40,
+		AdaptiveQuality: AdaptiveQualityConfig{TargetLatencyMs: 1000, Window: 1},
+	}, model)
+
+	backend.adaptive.observe(2*time.Second, backend.maxTokens)
+	if health := backend.GetHealth(); health.MaxTokens != 30 {
+		t.Errorf("Expected health to report maxTokens 30, got %d", health.MaxTokens)
+	}
+	if _, err := backend.GenerateResponse(DialogContext{Trigger: "click"}); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if got := model.calls[0].MaxTokens; got != 30 {
+		t.Errorf("Expected the degraded maxTokens in the prediction, got %d", got)
+	}
+
+	// The fast generation above counts as a recovered window
+	if _, err := backend.GenerateResponse(DialogContext{Trigger: "click"}); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if got := model.calls[1].MaxTokens; got != 40 {
+		t.Errorf("Expected maxTokens restored after a fast window, got %d", got)
+	}
+}
+
+func TestLLMBackend_AdaptiveQualityConfig(t *testing.T) {
+	backend := NewLLMBackend()
+	defer backend.Close()
+
+	config, _ := json.Marshal(LLMConfig{
+		ModelPath:       "/fake/path.gguf",
+		AdaptiveQuality: AdaptiveQualityConfig{TargetLatencyMs: 500, Window: -1},
+	})
+	if err := backend.Initialize(config); err == nil {
+		t.Error("Expected a negative window to be rejected")
+	}
+
+	if err := backend.applyAdaptiveQuality(AdaptiveQualityConfig{}); err != nil || backend.adaptive != nil {
+		t.Errorf("Expected adaptive quality disabled without a target, got %v (%v)", backend.adaptive, err)
+	}
+	if backend.Overloaded() {
+		t.Error("Expected a backend without adaptive quality never to be overloaded")
+	}
+}
+
+func TestDialogManager_OverloadBackend(t *testing.T) {
+	primary := &overloadTestBackend{fixedTestBackend: fixedTestBackend{response: DialogResponse{Text: "Long answer", Confidence: 0.9}}}
+	cheap := &fixedTestBackend{response: DialogResponse{Text: "Short", Confidence: 0.9}}
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", primary)
+	dm.RegisterBackend("markov", cheap)
+	dm.SetDefaultBackend("llm")
+	if err := dm.SetOverloadBackend("missing"); err == nil {
+		t.Error("Expected an unregistered overload backend to be rejected")
+	}
+	if err := dm.SetOverloadBackend("markov"); err != nil {
+		t.Fatalf("SetOverloadBackend failed: %v", err)
+	}
+
+	generate := func() string {
+		response, err := dm.GenerateDialog(DialogContext{Trigger: "click"})
+		if err != nil {
+			t.Fatalf("GenerateDialog failed: %v", err)
+		}
+		return response.Text
+	}
+
+	if got := generate(); got != "Long answer" {
+		t.Errorf("Expected the default backend while it keeps up, got %q", got)
+	}
+	primary.overloaded = true
+	if got := generate(); got != "Short" {
+		t.Errorf("Expected the overload backend while the default is overloaded, got %q", got)
+	}
+	primary.overloaded = false
+	if got := generate(); got != "Long answer" {
+		t.Errorf("Expected the default backend once it recovers, got %q", got)
+	}
+}
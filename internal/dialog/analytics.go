@@ -0,0 +1,281 @@
+package dialog
+
+import (
+	"sort"
+	"sync"
+)
+
+// TriggerAnalytics aggregates how users responded to dialog for a single trigger
+type TriggerAnalytics struct {
+	Count            int     `json:"count"`            // Exchanges recorded for this trigger
+	FeedbackCount    int     `json:"feedbackCount"`    // Exchanges that received feedback
+	PositiveFeedback int     `json:"positiveFeedback"` // Exchanges with positive feedback
+	PositiveRatio    float64 `json:"positiveRatio"`    // PositiveFeedback / FeedbackCount
+	AvgEngagement    float64 `json:"avgEngagement"`    // Mean engagement of exchanges with feedback
+}
+
+// ConversationAnalytics summarizes stored conversation history across all interactions
+type ConversationAnalytics struct {
+	Conversations    int                         `json:"conversations"`
+	TotalExchanges   int                         `json:"totalExchanges"`
+	FeedbackCount    int                         `json:"feedbackCount"`
+	PositiveFeedback int                         `json:"positiveFeedback"`
+	PositiveRatio    float64                     `json:"positiveRatio"`
+	AvgEngagement    float64                     `json:"avgEngagement"`
+	Triggers         map[string]TriggerAnalytics `json:"triggers"`
+	ResponseTypes    map[string]int              `json:"responseTypes"`
+	HourlyActivity   [24]int                     `json:"hourlyActivity"`  // Exchanges per hour of day (local time)
+	WeekdayActivity  [7]int                      `json:"weekdayActivity"` // Exchanges per weekday, Sunday first
+	BusiestHours     []int                       `json:"busiestHours"`    // Up to 3 hours with the most activity
+}
+
+// DialogAnalytics combines manager-level response statistics with backend conversation analytics
+type DialogAnalytics struct {
+	TotalResponses    int                   `json:"totalResponses"`
+	FallbackResponses int                   `json:"fallbackResponses"` // Responses from the manager's final fallback
+	BackendUsage      map[string]int        `json:"backendUsage"`      // Responses served per backend name
+	ResponseTypes     map[string]int        `json:"responseTypes"`     // Response types returned to the host
+	Conversations     ConversationAnalytics `json:"conversations"`     // Merged from analytics-capable backends
+}
+
+// AnalyticsProvider is implemented by backends that can report conversation analytics
+type AnalyticsProvider interface {
+	GetAnalytics() ConversationAnalytics
+}
+
+// GetAnalytics reports engagement, feedback and activity statistics over all stored conversations
+func (cm *ContextManager) GetAnalytics() ConversationAnalytics {
+	cm.rlockAll()
+	defer cm.runlockAll()
+
+	acc := newAnalyticsAccumulator()
+	for _, history := range cm.allConversations() {
+		acc.conversations++
+		for _, exchange := range history.Exchanges {
+			acc.addExchange(exchange)
+		}
+	}
+
+	return acc.result()
+}
+
+// GetAnalytics reports analytics for the conversations handled by this backend
+func (llm *LLMBackend) GetAnalytics() ConversationAnalytics {
+	return llm.GetContextManager().GetAnalytics()
+}
+
+// analyticsAccumulator collects raw sums so averages can be computed and merged correctly
+type analyticsAccumulator struct {
+	conversations    int
+	totalExchanges   int
+	feedbackCount    int
+	positiveFeedback int
+	engagementSum    float64
+	triggers         map[string]*triggerAccumulator
+	responseTypes    map[string]int
+	hourly           [24]int
+	weekday          [7]int
+}
+
+// triggerAccumulator collects raw sums for a single trigger
+type triggerAccumulator struct {
+	count            int
+	feedbackCount    int
+	positiveFeedback int
+	engagementSum    float64
+}
+
+// newAnalyticsAccumulator creates an empty accumulator
+func newAnalyticsAccumulator() *analyticsAccumulator {
+	return &analyticsAccumulator{
+		triggers:      make(map[string]*triggerAccumulator),
+		responseTypes: make(map[string]int),
+	}
+}
+
+// addExchange folds a single exchange into the running totals
+func (a *analyticsAccumulator) addExchange(exchange ConversationExchange) {
+	a.totalExchanges++
+
+	trigger := a.triggers[exchange.Trigger]
+	if trigger == nil {
+		trigger = &triggerAccumulator{}
+		a.triggers[exchange.Trigger] = trigger
+	}
+	trigger.count++
+
+	if exchange.FeedbackReceived {
+		a.feedbackCount++
+		trigger.feedbackCount++
+		a.engagementSum += exchange.EngagementScore
+		trigger.engagementSum += exchange.EngagementScore
+		if exchange.UserFeedback {
+			a.positiveFeedback++
+			trigger.positiveFeedback++
+		}
+	}
+
+	if exchange.ResponseType != "" {
+		a.responseTypes[exchange.ResponseType]++
+	}
+
+	if !exchange.Timestamp.IsZero() {
+		local := exchange.Timestamp.Local()
+		a.hourly[local.Hour()]++
+		a.weekday[local.Weekday()]++
+	}
+}
+
+// addAnalytics merges previously computed analytics by reconstructing their sums
+func (a *analyticsAccumulator) addAnalytics(other ConversationAnalytics) {
+	a.conversations += other.Conversations
+	a.totalExchanges += other.TotalExchanges
+	a.feedbackCount += other.FeedbackCount
+	a.positiveFeedback += other.PositiveFeedback
+	a.engagementSum += other.AvgEngagement * float64(other.FeedbackCount)
+
+	for name, stats := range other.Triggers {
+		trigger := a.triggers[name]
+		if trigger == nil {
+			trigger = &triggerAccumulator{}
+			a.triggers[name] = trigger
+		}
+		trigger.count += stats.Count
+		trigger.feedbackCount += stats.FeedbackCount
+		trigger.positiveFeedback += stats.PositiveFeedback
+		trigger.engagementSum += stats.AvgEngagement * float64(stats.FeedbackCount)
+	}
+
+	for responseType, count := range other.ResponseTypes {
+		a.responseTypes[responseType] += count
+	}
+	for hour, count := range other.HourlyActivity {
+		a.hourly[hour] += count
+	}
+	for day, count := range other.WeekdayActivity {
+		a.weekday[day] += count
+	}
+}
+
+// result converts the accumulated sums into a ConversationAnalytics report
+func (a *analyticsAccumulator) result() ConversationAnalytics {
+	analytics := ConversationAnalytics{
+		Conversations:    a.conversations,
+		TotalExchanges:   a.totalExchanges,
+		FeedbackCount:    a.feedbackCount,
+		PositiveFeedback: a.positiveFeedback,
+		PositiveRatio:    safeRatio(float64(a.positiveFeedback), a.feedbackCount),
+		AvgEngagement:    safeRatio(a.engagementSum, a.feedbackCount),
+		Triggers:         make(map[string]TriggerAnalytics, len(a.triggers)),
+		ResponseTypes:    make(map[string]int, len(a.responseTypes)),
+		HourlyActivity:   a.hourly,
+		WeekdayActivity:  a.weekday,
+		BusiestHours:     busiestHours(a.hourly, 3),
+	}
+
+	for name, trigger := range a.triggers {
+		analytics.Triggers[name] = TriggerAnalytics{
+			Count:            trigger.count,
+			FeedbackCount:    trigger.feedbackCount,
+			PositiveFeedback: trigger.positiveFeedback,
+			PositiveRatio:    safeRatio(float64(trigger.positiveFeedback), trigger.feedbackCount),
+			AvgEngagement:    safeRatio(trigger.engagementSum, trigger.feedbackCount),
+		}
+	}
+	for responseType, count := range a.responseTypes {
+		analytics.ResponseTypes[responseType] = count
+	}
+
+	return analytics
+}
+
+// safeRatio divides by count, returning 0 when there is nothing to average
+func safeRatio(sum float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// busiestHours returns up to limit hours with activity, most active first
+func busiestHours(hourly [24]int, limit int) []int {
+	hours := make([]int, 0, 24)
+	for hour, count := range hourly {
+		if count > 0 {
+			hours = append(hours, hour)
+		}
+	}
+
+	sort.SliceStable(hours, func(i, j int) bool {
+		return hourly[hours[i]] > hourly[hours[j]]
+	})
+
+	if len(hours) > limit {
+		hours = hours[:limit]
+	}
+	return hours
+}
+
+// responseStats tracks responses served by a DialogManager
+type responseStats struct {
+	total         int
+	fallbacks     int
+	backendUsage  map[string]int
+	responseTypes map[string]int
+	mu            sync.Mutex
+}
+
+// newResponseStats creates empty manager response statistics
+func newResponseStats() *responseStats {
+	return &responseStats{
+		backendUsage:  make(map[string]int),
+		responseTypes: make(map[string]int),
+	}
+}
+
+// record counts a response served by the named backend
+func (rs *responseStats) record(backendName string, response DialogResponse) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.total++
+	if backendName == "" {
+		rs.fallbacks++
+	} else {
+		rs.backendUsage[backendName]++
+	}
+	if response.ResponseType != "" {
+		rs.responseTypes[response.ResponseType]++
+	}
+}
+
+// GetAnalytics reports which backends served responses and merges conversation
+// analytics from every registered backend that implements AnalyticsProvider
+func (dm *DialogManager) GetAnalytics() DialogAnalytics {
+	dm.stats.mu.Lock()
+	analytics := DialogAnalytics{
+		TotalResponses:    dm.stats.total,
+		FallbackResponses: dm.stats.fallbacks,
+		BackendUsage:      make(map[string]int, len(dm.stats.backendUsage)),
+		ResponseTypes:     make(map[string]int, len(dm.stats.responseTypes)),
+	}
+	for name, count := range dm.stats.backendUsage {
+		analytics.BackendUsage[name] = count
+	}
+	for responseType, count := range dm.stats.responseTypes {
+		analytics.ResponseTypes[responseType] = count
+	}
+	dm.stats.mu.Unlock()
+
+	acc := newAnalyticsAccumulator()
+	dm.mu.RLock()
+	for _, backend := range dm.backends {
+		if provider, ok := backend.(AnalyticsProvider); ok {
+			acc.addAnalytics(provider.GetAnalytics())
+		}
+	}
+	dm.mu.RUnlock()
+	analytics.Conversations = acc.result()
+
+	return analytics
+}
@@ -0,0 +1,121 @@
+package dialog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestContextManager_GetAnalytics(t *testing.T) {
+	cm := NewContextManager(10)
+	defer cm.Close()
+
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "Hi!", ResponseType: "casual"})
+	cm.UpdateFeedback("user-1", true, 0.8)
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "Hey?", ResponseType: "inquisitive"})
+	cm.UpdateFeedback("user-1", false, 0.2)
+	cm.RecordExchange("user-2", ConversationExchange{Trigger: "feed", Response: "Yum!", ResponseType: "casual"})
+
+	analytics := cm.GetAnalytics()
+
+	if analytics.Conversations != 2 {
+		t.Errorf("Expected 2 conversations, got %d", analytics.Conversations)
+	}
+	if analytics.TotalExchanges != 3 {
+		t.Errorf("Expected 3 exchanges, got %d", analytics.TotalExchanges)
+	}
+	if analytics.FeedbackCount != 2 || analytics.PositiveFeedback != 1 {
+		t.Errorf("Expected 2 feedback with 1 positive, got %d/%d", analytics.FeedbackCount, analytics.PositiveFeedback)
+	}
+	if analytics.PositiveRatio != 0.5 {
+		t.Errorf("Expected positive ratio 0.5, got %f", analytics.PositiveRatio)
+	}
+
+	click := analytics.Triggers["click"]
+	if click.Count != 2 || click.AvgEngagement != 0.5 {
+		t.Errorf("Unexpected click analytics: %+v", click)
+	}
+	feed := analytics.Triggers["feed"]
+	if feed.Count != 1 || feed.FeedbackCount != 0 || feed.AvgEngagement != 0 {
+		t.Errorf("Unexpected feed analytics: %+v", feed)
+	}
+
+	if analytics.ResponseTypes["casual"] != 2 || analytics.ResponseTypes["inquisitive"] != 1 {
+		t.Errorf("Unexpected response type distribution: %v", analytics.ResponseTypes)
+	}
+
+	currentHour := time.Now().Hour()
+	if analytics.HourlyActivity[currentHour] != 3 {
+		t.Errorf("Expected 3 exchanges in hour %d, got %d", currentHour, analytics.HourlyActivity[currentHour])
+	}
+	if len(analytics.BusiestHours) != 1 || analytics.BusiestHours[0] != currentHour {
+		t.Errorf("Expected busiest hour %d, got %v", currentHour, analytics.BusiestHours)
+	}
+}
+
+func TestContextManager_GetAnalyticsEmpty(t *testing.T) {
+	cm := NewContextManager(10)
+	defer cm.Close()
+
+	analytics := cm.GetAnalytics()
+	if analytics.TotalExchanges != 0 || analytics.PositiveRatio != 0 || len(analytics.BusiestHours) != 0 {
+		t.Errorf("Expected empty analytics, got %+v", analytics)
+	}
+}
+
+func TestBusiestHours(t *testing.T) {
+	var hourly [24]int
+	hourly[9] = 2
+	hourly[21] = 5
+	hourly[13] = 1
+	hourly[3] = 4
+
+	hours := busiestHours(hourly, 3)
+	expected := []int{21, 3, 9}
+	if len(hours) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, hours)
+	}
+	for i := range expected {
+		if hours[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, hours)
+		}
+	}
+}
+
+func TestDialogManager_GetAnalytics(t *testing.T) {
+	dm := NewDialogManager(false)
+
+	backend := NewLLMBackend()
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: "/fake/path.gguf"})
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	defer backend.Close()
+
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+
+	context := DialogContext{Trigger: "feed", InteractionID: "user-1"}
+	response, _ := dm.GenerateDialog(context)
+	dm.UpdateBackendMemory(context, response, &UserFeedback{Positive: true, Engagement: 1.0})
+
+	// Unregistered default produces a manager-level fallback
+	empty := NewDialogManager(false)
+	empty.GenerateDialog(context)
+
+	analytics := dm.GetAnalytics()
+	if analytics.TotalResponses != 1 || analytics.BackendUsage["llm"] != 1 {
+		t.Errorf("Expected one response served by llm, got %+v", analytics)
+	}
+	if analytics.Conversations.Triggers["feed"].PositiveRatio != 1.0 {
+		t.Errorf("Expected feed positive ratio 1.0, got %+v", analytics.Conversations.Triggers["feed"])
+	}
+	if analytics.ResponseTypes[response.ResponseType] != 1 {
+		t.Errorf("Expected response type %q to be counted, got %v", response.ResponseType, analytics.ResponseTypes)
+	}
+
+	fallback := empty.GetAnalytics()
+	if fallback.FallbackResponses != 1 || fallback.ResponseTypes["fallback"] != 1 {
+		t.Errorf("Expected one fallback response, got %+v", fallback)
+	}
+}
@@ -0,0 +1,149 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SetResponseTimeout bounds the time GenerateDialog spends across the default backend and
+// its fallback chain (0 = unbounded); backends left without budget are skipped
+func (dm *DialogManager) SetResponseTimeout(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("response timeout must be non-negative, got %v", timeout)
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.responseTimeout = timeout
+	return nil
+}
+
+// SetBackendTimeout bounds each call to the named backend (0 = removes the bound), so a
+// slow primary gives up in time for its fallbacks, e.g. 2s for llm and 50ms for a markov chain
+// The call is further cut short by whatever remains of the response timeout
+func (dm *DialogManager) SetBackendTimeout(name string, timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("timeout for backend '%s' must be non-negative, got %v", name, timeout)
+	}
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if timeout == 0 {
+		delete(dm.backendTimeouts, name)
+		return nil
+	}
+	if dm.backendTimeouts == nil {
+		dm.backendTimeouts = make(map[string]time.Duration)
+	}
+	dm.backendTimeouts[name] = timeout
+	return nil
+}
+
+// ApplyTimeoutConfig sets each backend's timeout from config.BackendTimeouts; once any is
+// set, config.ResponseTimeout also bounds the whole chain. Without per-backend timeouts
+// each backend keeps its own timeoutMs and the chain is unbounded
+func (dm *DialogManager) ApplyTimeoutConfig(config DialogBackendConfig) error {
+	if len(config.BackendTimeouts) == 0 {
+		return nil
+	}
+	for name, ms := range config.BackendTimeouts {
+		if err := dm.SetBackendTimeout(name, time.Duration(ms)*time.Millisecond); err != nil {
+			return err
+		}
+	}
+	return dm.SetResponseTimeout(time.Duration(config.ResponseTimeout) * time.Millisecond)
+}
+
+// responseDeadline is when a request starting now must finish (zero = no deadline)
+func (dm *DialogManager) responseDeadline() time.Time {
+	dm.mu.RLock()
+	timeout := dm.responseTimeout
+	dm.mu.RUnlock()
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
+
+// backendTimeout is how long a call to the named backend may take: its own timeout, cut
+// short by the time left before deadline; 0 means no limit and a negative value none left
+func (dm *DialogManager) backendTimeout(name string, deadline time.Time) time.Duration {
+	dm.mu.RLock()
+	timeout := dm.backendTimeouts[name]
+	dm.mu.RUnlock()
+
+	if deadline.IsZero() {
+		return timeout
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return -1
+	}
+	if timeout == 0 || remaining < timeout {
+		timeout = remaining
+	}
+	return timeout
+}
+
+// generateWithin calls backend.GenerateResponse, giving up after timeout (0 = no limit)
+// The context carries the limit as TimeoutMs and as its request context, so backends that
+// honor either stop early; one that ignores both is left to finish on its own
+func generateWithin(backend DialogBackend, dialogContext DialogContext, timeout time.Duration) (DialogResponse, error) {
+	return runWithin(dialogContext, timeout, backend.GenerateResponse)
+}
+
+// streamWithin calls streaming.GenerateResponseStream like generateWithin; chunks the
+// backend emits after timeout are dropped
+func streamWithin(streaming StreamingBackend, dialogContext DialogContext, timeout time.Duration, emit func(chunk string)) (DialogResponse, error) {
+	if timeout <= 0 {
+		return streaming.GenerateResponseStream(dialogContext, emit)
+	}
+
+	var mu sync.Mutex
+	open := true
+	response, err := runWithin(dialogContext, timeout, func(dialogContext DialogContext) (DialogResponse, error) {
+		ctx := dialogContext.requestContext()
+		return streaming.GenerateResponseStream(dialogContext, func(chunk string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if open && ctx.Err() == nil {
+				emit(chunk)
+			}
+		})
+	})
+	mu.Lock()
+	open = false
+	mu.Unlock()
+	return response, err
+}
+
+// runWithin calls generate with dialogContext limited to timeout (0 = no limit)
+func runWithin(dialogContext DialogContext, timeout time.Duration, generate func(DialogContext) (DialogResponse, error)) (DialogResponse, error) {
+	if timeout <= 0 {
+		return generate(dialogContext)
+	}
+
+	ctx, cancel := context.WithTimeout(dialogContext.requestContext(), timeout)
+	defer cancel()
+	dialogContext.requestCtx = ctx
+	if ms := max(int(timeout.Milliseconds()), 1); dialogContext.TimeoutMs == 0 || ms < dialogContext.TimeoutMs {
+		dialogContext.TimeoutMs = ms
+	}
+
+	type outcome struct {
+		response DialogResponse
+		err      error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		response, err := generate(dialogContext)
+		done <- outcome{response, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.response, result.err
+	case <-ctx.Done():
+		return DialogResponse{}, fmt.Errorf("no response within %v: %w", timeout, deadlineError(ctx))
+	}
+}
@@ -0,0 +1,115 @@
+package dialog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowTestBackend answers after delay, ignoring any deadline, and keeps the last context
+type slowTestBackend struct {
+	fixedTestBackend
+	delay time.Duration
+	seen  chan DialogContext
+}
+
+func (b *slowTestBackend) GenerateResponse(ctx DialogContext) (DialogResponse, error) {
+	b.seen <- ctx
+	time.Sleep(b.delay)
+	return b.response, b.err
+}
+
+// newTimeoutTestManager returns a manager whose default backend takes delay to answer and
+// whose fallback answers at once
+func newTimeoutTestManager(t *testing.T, delay time.Duration) (*DialogManager, *slowTestBackend) {
+	t.Helper()
+	slow := &slowTestBackend{
+		fixedTestBackend: fixedTestBackend{response: DialogResponse{Text: "Slow", Confidence: 0.9}},
+		delay:            delay,
+		seen:             make(chan DialogContext, 4),
+	}
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("slow", slow)
+	dm.RegisterBackend("fast", &fixedTestBackend{response: DialogResponse{Text: "Fast", Confidence: 0.9}})
+	dm.SetDefaultBackend("slow")
+	dm.SetFallbackChain([]string{"fast"})
+	return dm, slow
+}
+
+func TestDialogManager_SetBackendTimeout(t *testing.T) {
+	dm, slow := newTimeoutTestManager(t, time.Second)
+	if err := dm.SetBackendTimeout("slow", 20*time.Millisecond); err != nil {
+		t.Fatalf("SetBackendTimeout failed: %v", err)
+	}
+	errs, _ := eventRecorder(dm.Events(), EventBackendError)
+
+	start := time.Now()
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "click", TimeoutMs: 5000})
+	if err != nil || response.Text != "Fast" {
+		t.Errorf("Expected the fallback to answer, got %q (%v)", response.Text, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the slow backend to be abandoned after its timeout, took %v", elapsed)
+	}
+	if ctx := <-slow.seen; ctx.TimeoutMs != 20 {
+		t.Errorf("Expected the backend's timeout to tighten TimeoutMs to 20, got %d", ctx.TimeoutMs)
+	}
+	if len(*errs) != 1 || !errors.Is((*errs)[0].Error, ErrTimeout) {
+		t.Errorf("Expected one timeout error event, got %+v", *errs)
+	}
+
+	if err := dm.SetBackendTimeout("slow", -time.Second); err == nil {
+		t.Error("Expected an error for a negative timeout")
+	}
+}
+
+func TestDialogManager_SetResponseTimeout(t *testing.T) {
+	dm, _ := newTimeoutTestManager(t, 200*time.Millisecond)
+	dm.SetResponseTimeout(30 * time.Millisecond)
+	errs, _ := eventRecorder(dm.Events(), EventBackendError)
+
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "click", FallbackResponses: []string{"Hmm?"}})
+	if err != nil || response.Text != "Hmm?" {
+		t.Errorf("Expected the canned fallback once the budget is spent, got %q (%v)", response.Text, err)
+	}
+	// The fast fallback is skipped without being called, so only the slow one reports
+	if len(*errs) != 1 || (*errs)[0].Backend != "slow" {
+		t.Errorf("Expected only the slow backend's timeout, got %+v", *errs)
+	}
+}
+
+func TestDialogManager_ApplyTimeoutConfig(t *testing.T) {
+	dm, _ := newTimeoutTestManager(t, 0)
+
+	dm.ApplyTimeoutConfig(DialogBackendConfig{ResponseTimeout: 1000})
+	if dm.responseTimeout != 0 {
+		t.Errorf("Expected responseTimeout to stay unenforced without backendTimeouts, got %v", dm.responseTimeout)
+	}
+
+	dm.ApplyTimeoutConfig(DialogBackendConfig{ResponseTimeout: 1000, BackendTimeouts: map[string]int{"slow": 800, "fast": 50}})
+	if dm.responseTimeout != time.Second || dm.backendTimeouts["slow"] != 800*time.Millisecond || dm.backendTimeouts["fast"] != 50*time.Millisecond {
+		t.Errorf("Expected the configured timeouts, got %v and %v", dm.responseTimeout, dm.backendTimeouts)
+	}
+}
+
+func TestDiagnoseConfig_BackendTimeouts(t *testing.T) {
+	diagnostics := DiagnoseConfig([]byte(`{
+		"enabled": true,
+		"defaultBackend": "llm",
+		"fallbackChain": ["markov_chain"],
+		"responseTimeout": 1000,
+		"backendTimeouts": {"llm": 1000, "markov_chain": -5, "simple": 10},
+		"backends": {"llm": {}, "markov_chain": {}}
+	}`))
+
+	if d := findDiagnostic(diagnostics, "$.backendTimeouts.llm"); d == nil || !strings.Contains(d.Message, "fallback chain") {
+		t.Errorf("Expected a warning that llm uses the whole budget, got %+v", diagnostics)
+	}
+	if d := findDiagnostic(diagnostics, "$.backendTimeouts.markov_chain"); d == nil || d.Severity != SeverityError {
+		t.Errorf("Expected an error for a negative timeout, got %+v", diagnostics)
+	}
+	if d := findDiagnostic(diagnostics, "$.backendTimeouts.simple"); d == nil || d.Severity != SeverityWarning {
+		t.Errorf("Expected a warning for an unconfigured backend, got %+v", diagnostics)
+	}
+}
@@ -0,0 +1,165 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CalendarEventTrigger is the trigger hosts raise to greet the user on a calendar event
+const CalendarEventTrigger = "calendar_event"
+
+// Calendar event kinds; any other kind is treated as a user-defined occasion
+const (
+	CalendarKindBirthday    = "birthday"
+	CalendarKindHoliday     = "holiday"
+	CalendarKindAnniversary = "anniversary"
+)
+
+// CalendarEvent is a dated occasion the character knows about, such as the user's
+// birthday, a holiday or the anniversary of adopting the pet
+type CalendarEvent struct {
+	Name        string `json:"name"`                  // e.g. "Alex's birthday", "New Year's Day"
+	Date        string `json:"date"`                  // "MM-DD" every year, or "YYYY-MM-DD" every year from then on
+	Kind        string `json:"kind,omitempty"`        // "birthday", "holiday", "anniversary" or a custom kind
+	Description string `json:"description,omitempty"` // Extra detail for the prompt
+}
+
+// CalendarProvider is implemented by backends configured with calendar events
+type CalendarProvider interface {
+	// EventsOn returns the events that fall on the date of t
+	EventsOn(t time.Time) []CalendarEvent
+}
+
+// calendarDate is a parsed CalendarEvent date
+type calendarDate struct {
+	year  int // 0 when the event has no starting year
+	month time.Month
+	day   int
+}
+
+// parseCalendarDate parses "MM-DD" or "YYYY-MM-DD"
+func parseCalendarDate(date string) (calendarDate, error) {
+	if parsed, err := time.Parse("2006-01-02", date); err == nil {
+		return calendarDate{year: parsed.Year(), month: parsed.Month(), day: parsed.Day()}, nil
+	}
+	// Parse against a leap year so "02-29" is accepted
+	parsed, err := time.Parse("2006-01-02", "2000-"+date)
+	if err != nil {
+		return calendarDate{}, fmt.Errorf("date %q must be MM-DD or YYYY-MM-DD", date)
+	}
+	return calendarDate{month: parsed.Month(), day: parsed.Day()}, nil
+}
+
+// matches reports whether the event falls on the date of t
+// Events on February 29 fall on February 28 in other years
+func (d calendarDate) matches(t time.Time) bool {
+	if d.year != 0 && t.Year() < d.year {
+		return false
+	}
+	if d.month == time.February && d.day == 29 && !isLeapYear(t.Year()) {
+		return t.Month() == time.February && t.Day() == 28
+	}
+	return t.Month() == d.month && t.Day() == d.day
+}
+
+// isLeapYear reports whether year has a February 29
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// validateCalendarEvents checks every event has a name and a valid date
+func validateCalendarEvents(events []CalendarEvent) error {
+	for i, event := range events {
+		if strings.TrimSpace(event.Name) == "" {
+			return fmt.Errorf("event %d has no name", i)
+		}
+		if _, err := parseCalendarDate(event.Date); err != nil {
+			return fmt.Errorf("event %q: %w", event.Name, err)
+		}
+	}
+	return nil
+}
+
+// calendarEventsOn returns the events that fall on the date of t
+func calendarEventsOn(events []CalendarEvent, t time.Time) []CalendarEvent {
+	var matching []CalendarEvent
+	for _, event := range events {
+		date, err := parseCalendarDate(event.Date)
+		if err == nil && date.matches(t) {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+// describeCalendarEvent renders an event for the prompt, counting the years for
+// birthdays and anniversaries with a starting year
+func describeCalendarEvent(event CalendarEvent, t time.Time) string {
+	description := event.Name
+	date, _ := parseCalendarDate(event.Date)
+	if years := t.Year() - date.year; date.year != 0 && years > 0 {
+		switch event.Kind {
+		case CalendarKindBirthday:
+			description += fmt.Sprintf(" (turning %d)", years)
+		case CalendarKindAnniversary:
+			description += fmt.Sprintf(" (%s)", describeYears(years))
+		}
+	}
+	if event.Description != "" {
+		description += ": " + event.Description
+	}
+	return description
+}
+
+// describeYears renders a whole number of years
+func describeYears(years int) string {
+	if years == 1 {
+		return "1 year"
+	}
+	return fmt.Sprintf("%d years", years)
+}
+
+// applyCalendar registers the character's calendar events
+func (llm *LLMBackend) applyCalendar(cfg LLMConfig) error {
+	if err := validateCalendarEvents(cfg.Events); err != nil {
+		return invalidConfig("events", err)
+	}
+	llm.calendar = cfg.Events
+	return nil
+}
+
+// EventsOn returns the configured calendar events that fall on the date of t
+func (llm *LLMBackend) EventsOn(t time.Time) []CalendarEvent {
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+	return calendarEventsOn(llm.calendar, t)
+}
+
+// DueCalendarEvents returns the default backend's calendar events that fall on the date of
+// now and have not been returned for the conversation yet; hosts that get any should raise
+// CalendarEventTrigger so the character greets the user proactively
+func (dm *DialogManager) DueCalendarEvents(interactionID string, now time.Time) []CalendarEvent {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	provider, ok := dm.backends[dm.defaultBackend].(CalendarProvider)
+	if !ok {
+		return nil
+	}
+
+	day := now.Format("2006-01-02")
+	var due []CalendarEvent
+	for _, event := range provider.EventsOn(now) {
+		key := interactionID + "\x00" + event.Name
+		if dm.greetedEvents[key] == day {
+			continue
+		}
+		if dm.greetedEvents == nil {
+			dm.greetedEvents = make(map[string]string)
+		}
+		dm.greetedEvents[key] = day
+		due = append(due, event)
+	}
+	return due
+}
@@ -0,0 +1,113 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarEventsOn(t *testing.T) {
+	events := []CalendarEvent{
+		{Name: "New Year's Day", Date: "01-01", Kind: CalendarKindHoliday},
+		{Name: "Adoption day", Date: "2022-06-01", Kind: CalendarKindAnniversary},
+		{Name: "Leap birthday", Date: "02-29", Kind: CalendarKindBirthday},
+	}
+
+	if got := calendarEventsOn(events, time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)); len(got) != 1 || got[0].Name != "New Year's Day" {
+		t.Errorf("Expected New Year's Day, got %v", got)
+	}
+	if got := calendarEventsOn(events, time.Date(2021, 6, 1, 9, 0, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("Expected no events before the starting year, got %v", got)
+	}
+	if got := calendarEventsOn(events, time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC)); len(got) != 1 {
+		t.Errorf("Expected February 29 event on February 28 in a common year, got %v", got)
+	}
+	if got := calendarEventsOn(events, time.Date(2024, 2, 28, 9, 0, 0, 0, time.UTC)); len(got) != 0 {
+		t.Errorf("Expected February 29 event to wait for the 29th in a leap year, got %v", got)
+	}
+}
+
+func TestDescribeCalendarEvent(t *testing.T) {
+	day := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		event    CalendarEvent
+		expected string
+	}{
+		{CalendarEvent{Name: "Adoption day", Date: "2022-06-01", Kind: CalendarKindAnniversary}, "Adoption day (3 years)"},
+		{CalendarEvent{Name: "Alex's birthday", Date: "1995-06-01", Kind: CalendarKindBirthday, Description: "they love cake"}, "Alex's birthday (turning 30): they love cake"},
+		{CalendarEvent{Name: "Adoption day", Date: "2025-06-01", Kind: CalendarKindAnniversary}, "Adoption day"},
+		{CalendarEvent{Name: "Pet day", Date: "06-01"}, "Pet day"},
+	}
+	for _, test := range tests {
+		if got := describeCalendarEvent(test.event, day); got != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, got)
+		}
+	}
+}
+
+func TestLLMBackend_InvalidCalendarEvents(t *testing.T) {
+	configs := []string{
+		`{"modelPath": "/fake/path.gguf", "events": [{"name": "", "date": "01-01"}]}`,
+		`{"modelPath": "/fake/path.gguf", "events": [{"name": "Oops", "date": "13-01"}]}`,
+		`{"modelPath": "/fake/path.gguf", "events": [{"name": "Oops", "date": "June 1st"}]}`,
+	}
+	for _, config := range configs {
+		if err := NewLLMBackend().Initialize(json.RawMessage(config)); err == nil {
+			t.Errorf("Expected error for %s", config)
+		}
+	}
+}
+
+func TestLLMBackend_PromptMentionsTodaysEvents(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{Events: []CalendarEvent{
+		{Name: "Alex's birthday", Date: "06-01", Kind: CalendarKindBirthday},
+		{Name: "Halloween", Date: "10-31", Kind: CalendarKindHoliday},
+	}}, &scriptedTestModel{})
+
+	prompt := backend.buildPrompt(DialogContext{Trigger: CalendarEventTrigger, Timestamp: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)})
+	if !strings.Contains(prompt, "- Today is a special day: Alex's birthday") {
+		t.Errorf("Expected prompt to mention the birthday, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "came by on a special day") {
+		t.Errorf("Expected calendar trigger description in prompt, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Halloween") {
+		t.Errorf("Expected other events left out of the prompt, got:\n%s", prompt)
+	}
+}
+
+func TestDialogManager_DueCalendarEvents(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", newScriptedBackend(t, LLMConfig{Events: []CalendarEvent{
+		{Name: "Alex's birthday", Date: "06-01", Kind: CalendarKindBirthday},
+	}}, &scriptedTestModel{}))
+	dm.SetDefaultBackend("llm")
+
+	birthday := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	if due := dm.DueCalendarEvents("pet", birthday); len(due) != 1 || due[0].Name != "Alex's birthday" {
+		t.Fatalf("Expected the birthday to be due, got %v", due)
+	}
+	if due := dm.DueCalendarEvents("pet", birthday.Add(3*time.Hour)); len(due) != 0 {
+		t.Errorf("Expected the birthday reported once per day, got %v", due)
+	}
+	if due := dm.DueCalendarEvents("other", birthday); len(due) != 1 {
+		t.Errorf("Expected the birthday due for another conversation, got %v", due)
+	}
+	if due := dm.DueCalendarEvents("pet", birthday.AddDate(1, 0, 0)); len(due) != 1 {
+		t.Errorf("Expected the birthday due again next year, got %v", due)
+	}
+	if due := dm.DueCalendarEvents("pet", birthday.AddDate(0, 0, 1)); len(due) != 0 {
+		t.Errorf("Expected nothing due the next day, got %v", due)
+	}
+}
+
+func TestDialogManager_DueCalendarEventsWithoutProvider(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", struct{ DialogBackend }{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{})})
+	dm.SetDefaultBackend("llm")
+
+	if due := dm.DueCalendarEvents("pet", time.Now()); due != nil {
+		t.Errorf("Expected no events from a backend without a calendar, got %v", due)
+	}
+}
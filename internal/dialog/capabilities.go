@@ -0,0 +1,176 @@
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Capabilities a backend can advertise in BackendInfo.Capabilities
+const (
+	CapabilityContextAware      = "context_aware"
+	CapabilityPersonalityDriven = "personality_driven"
+	CapabilityLearning          = "learning_enabled"  // UpdateMemory adapts future responses
+	CapabilityStreaming         = "streaming_capable" // Implements StreamingBackend
+)
+
+// ErrCapabilityUnsupported matches every CapabilityError with errors.Is
+var ErrCapabilityUnsupported = errors.New("backend capability not supported")
+
+// CapabilityError reports a request that needed a capability its backend does not advertise
+// Backend is empty when no registered backend has the capability
+type CapabilityError struct {
+	Backend    string
+	Capability string
+}
+
+// Error describes the missing capability
+func (e *CapabilityError) Error() string {
+	if e.Backend == "" {
+		return fmt.Sprintf("no registered backend supports %s", e.Capability)
+	}
+	return fmt.Sprintf("backend '%s' does not support %s", e.Backend, e.Capability)
+}
+
+// Is makes errors.Is(err, ErrCapabilityUnsupported) true for any CapabilityError
+func (e *CapabilityError) Is(target error) bool {
+	return target == ErrCapabilityUnsupported
+}
+
+// StreamingBackend is implemented by backends that deliver a response in chunks as it is produced
+// Backends must emit only text they will return, so a failed backend never leaves partial output
+type StreamingBackend interface {
+	GenerateResponseStream(context DialogContext, emit func(chunk string)) (DialogResponse, error)
+}
+
+// HasCapability reports whether the backend advertises capability
+func (info BackendInfo) HasCapability(capability string) bool {
+	for _, advertised := range info.Capabilities {
+		if advertised == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// streamingBackend returns backend as a StreamingBackend when it both advertises and implements streaming
+func streamingBackend(backend DialogBackend) (StreamingBackend, bool) {
+	if backend == nil || !backend.GetBackendInfo().HasCapability(CapabilityStreaming) {
+		return nil, false
+	}
+	streaming, ok := backend.(StreamingBackend)
+	return streaming, ok
+}
+
+// GenerateDialogStream produces a response like GenerateDialog, delivering its text through emit
+// Only backends advertising CapabilityStreaming are tried, routed like GenerateDialog and then
+// in fallback chain order;
+// when none is registered the fallback response is returned with a CapabilityError. Responses
+// that did not come from a streaming backend (rate-limited repeats, hook short-circuits,
+// fallbacks) are emitted whole
+func (dm *DialogManager) GenerateDialogStream(context DialogContext, emit func(chunk string)) (DialogResponse, error) {
+	if !dm.beginRequest() {
+		return dm.createFallbackResponse(context), ErrShuttingDown
+	}
+	defer dm.inFlight.Done()
+
+	start := time.Now()
+	dm.events.Publish(DialogEvent{
+		Type:          EventGenerationStarted,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+	})
+
+	streamed := false
+	handler := dm.buildHandlerChainWith(func(context DialogContext) (DialogResponse, error) {
+		return dm.generateStreaming(context, func(chunk string) {
+			streamed = true
+			emit(chunk)
+		})
+	})
+
+	response, err := handler(context)
+	if err != nil {
+		response = dm.createFallbackResponse(context)
+		dm.stats.record("", response)
+	}
+	if !streamed && response.Text != "" {
+		emit(response.Text)
+	}
+
+	dm.events.Publish(DialogEvent{
+		Type:          EventGenerationCompleted,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+		Response:      &response,
+		Error:         err,
+		Latency:       time.Since(start),
+	})
+	return response, err
+}
+
+// generateStreaming runs the streaming-capable backends of the routed backend and fallback chain,
+// each within its backend timeout
+func (dm *DialogManager) generateStreaming(context DialogContext, emit func(chunk string)) (DialogResponse, error) {
+	first, arm, exp := dm.routeBackend(context)
+	dm.mu.RLock()
+	candidates := append([]string{first}, dm.fallbackChain...)
+	dm.mu.RUnlock()
+	deadline := dm.responseDeadline()
+
+	supported := false
+	for i, name := range candidates {
+		response, latency, streams, ok := dm.tryStreamingBackend(name, context, deadline, emit)
+		supported = supported || streams
+		if i == 0 && arm != nil {
+			if !ok {
+				arm.recordFailure()
+			} else {
+				response = arm.annotate(response, exp.config.Name)
+				arm.recordResponse(response)
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		// Emitted text cannot be withdrawn, so unlike GenerateDialog low confidence is accepted
+		dm.stats.record(name, response)
+		dm.publishResponse(EventResponseGenerated, name, context, response, latency)
+		if i > 0 {
+			dm.publishResponse(EventFallbackUsed, name, context, response, latency)
+		}
+		return response, nil
+	}
+
+	if !supported {
+		return DialogResponse{}, &CapabilityError{Backend: candidates[0], Capability: CapabilityStreaming}
+	}
+	response := dm.createFallbackResponse(context)
+	dm.stats.record("", response)
+	dm.events.Publish(DialogEvent{
+		Type:          EventFallbackUsed,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+		Response:      &response,
+	})
+	return response, nil
+}
+
+// tryStreamingBackend streams a response from the named backend within its timeout,
+// reporting its latency, whether the backend streams at all and whether it succeeded
+func (dm *DialogManager) tryStreamingBackend(name string, context DialogContext, deadline time.Time, emit func(chunk string)) (DialogResponse, time.Duration, bool, bool) {
+	backend, exists := dm.GetBackend(name)
+	if !exists {
+		return DialogResponse{}, 0, false, false
+	}
+	streaming, streams := streamingBackend(backend)
+	if !streams || !backend.CanHandle(context) {
+		return DialogResponse{}, 0, streams, false
+	}
+
+	response, latency, err := dm.timedCall(name, backend, context, deadline, func(context DialogContext, timeout time.Duration) (DialogResponse, error) {
+		return streamWithin(streaming, context, timeout, emit)
+	})
+	return response, latency, true, err == nil
+}
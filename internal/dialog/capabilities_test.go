@@ -0,0 +1,186 @@
+package dialog
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// plainTestBackend is an LLM backend that advertises no capabilities
+type plainTestBackend struct {
+	*LLMBackend
+}
+
+func (plainTestBackend) GetBackendInfo() BackendInfo {
+	return BackendInfo{Name: "plain"}
+}
+
+// streamingTestBackend is an LLM backend that streams its responses word by word
+type streamingTestBackend struct {
+	*LLMBackend
+}
+
+func (streamingTestBackend) GetBackendInfo() BackendInfo {
+	return BackendInfo{Name: "streaming", Capabilities: []string{CapabilityStreaming}}
+}
+
+func (b streamingTestBackend) GenerateResponseStream(context DialogContext, emit func(chunk string)) (DialogResponse, error) {
+	response, err := b.GenerateResponse(context)
+	if err != nil {
+		return response, err
+	}
+	for _, chunk := range splitTypingChunks(response.Text) {
+		emit(chunk)
+	}
+	return response, nil
+}
+
+// newStreamingTestManager returns a manager whose default backend streams responses
+func newStreamingTestManager(t *testing.T, responses ...string) *DialogManager {
+	t.Helper()
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", streamingTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: responses})})
+	dm.SetDefaultBackend("llm")
+	return dm
+}
+
+func TestDialogManager_GenerateDialogStream(t *testing.T) {
+	dm := newStreamingTestManager(t, "Hello there, friend!")
+	var chunks []string
+	response, err := dm.GenerateDialogStream(DialogContext{Trigger: "click", InteractionID: "pet"}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("GenerateDialogStream failed: %v", err)
+	}
+	if len(chunks) < 2 || strings.Join(chunks, "") != response.Text {
+		t.Errorf("Expected the response delivered in chunks, got %q for %q", chunks, response.Text)
+	}
+}
+
+func TestDialogManager_GenerateDialogStreamSkipsNonStreamingBackends(t *testing.T) {
+	dm := newStreamingTestManager(t, "Streamed reply")
+	plain := plainTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Plain reply"}})}
+	dm.RegisterBackend("plain", plain)
+	dm.SetDefaultBackend("plain")
+	dm.SetFallbackChain([]string{"llm"})
+
+	response, err := dm.GenerateDialogStream(DialogContext{Trigger: "click"}, func(string) {})
+	if err != nil || response.Text != "Streamed reply" {
+		t.Errorf("Expected the streaming fallback to answer, got %q, %v", response.Text, err)
+	}
+	if response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"}); response.Text != "Plain reply" {
+		t.Errorf("Expected GenerateDialog to keep using the default backend, got %q", response.Text)
+	}
+}
+
+func TestDialogManager_GenerateDialogStreamWithoutStreamingBackend(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("plain", plainTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hi"}})})
+	dm.SetDefaultBackend("plain")
+
+	var emitted []string
+	response, err := dm.GenerateDialogStream(DialogContext{Trigger: "click", FallbackResponses: []string{"Hmm?"}}, func(chunk string) {
+		emitted = append(emitted, chunk)
+	})
+	var capabilityErr *CapabilityError
+	if !errors.As(err, &capabilityErr) || capabilityErr.Backend != "plain" || !errors.Is(err, ErrCapabilityUnsupported) {
+		t.Fatalf("Expected a CapabilityError for the default backend, got %v", err)
+	}
+	if response.Text != "Hmm?" || len(emitted) != 1 || emitted[0] != "Hmm?" {
+		t.Errorf("Expected the fallback response emitted whole, got %q (emitted %q)", response.Text, emitted)
+	}
+}
+
+// stallingStreamBackend streams one chunk, then waits for its request context to end before
+// emitting another
+type stallingStreamBackend struct {
+	streamingTestBackend
+}
+
+func (b stallingStreamBackend) GenerateResponseStream(context DialogContext, emit func(chunk string)) (DialogResponse, error) {
+	emit("Hmm")
+	<-context.requestContext().Done()
+	emit(" too late")
+	return DialogResponse{Text: "Hmm too late", Confidence: 1}, nil
+}
+
+func TestDialogManager_GenerateDialogStreamRouting(t *testing.T) {
+	dm := newStreamingTestManager(t, "Default reply")
+	dm.RegisterBackend("chat", streamingTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Routed reply"}})})
+	dm.RegisterBackend("mochi", streamingTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Mochi reply"}})})
+	if err := dm.SetTriggerRoutes(map[string]string{"chat": "chat"}); err != nil {
+		t.Fatalf("SetTriggerRoutes failed: %v", err)
+	}
+	if err := dm.RegisterCharacter(Character{Name: "Mochi", Backend: "mochi"}); err != nil {
+		t.Fatalf("RegisterCharacter failed: %v", err)
+	}
+
+	if response, err := dm.GenerateDialogStream(DialogContext{Trigger: "chat"}, func(string) {}); err != nil || response.Text != "Routed reply" {
+		t.Errorf("Expected the trigger route to answer, got %q, %v", response.Text, err)
+	}
+	if response, err := dm.GenerateDialogStream(DialogContext{Trigger: "chat", Speaker: "Mochi"}, func(string) {}); err != nil || response.Text != "Mochi reply" {
+		t.Errorf("Expected the character's backend to answer, got %q, %v", response.Text, err)
+	}
+}
+
+func TestDialogManager_GenerateDialogStreamBackendTimeout(t *testing.T) {
+	dm := newStreamingTestManager(t, "Fallback reply")
+	dm.RegisterBackend("slow", stallingStreamBackend{streamingTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{})}})
+	dm.SetDefaultBackend("slow")
+	dm.SetFallbackChain([]string{"llm"})
+	if err := dm.SetBackendTimeout("slow", 20*time.Millisecond); err != nil {
+		t.Fatalf("SetBackendTimeout failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var chunks []string
+	response, err := dm.GenerateDialogStream(DialogContext{Trigger: "click"}, func(chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, chunk)
+	})
+	if err != nil || response.Text != "Fallback reply" {
+		t.Fatalf("Expected the fallback to answer after the timeout, got %q, %v", response.Text, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Contains(strings.Join(chunks, ""), "too late") {
+		t.Errorf("Expected chunks after the timeout to be dropped, got %q", chunks)
+	}
+}
+
+// The LLM backend only returns whole validated responses, so it must not claim to stream
+func TestLLMBackend_DoesNotAdvertiseStreaming(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello there, friend!")
+	backend, _ := dm.GetBackend("llm")
+	if backend.GetBackendInfo().HasCapability(CapabilityStreaming) {
+		t.Error("Expected the LLM backend not to advertise streaming")
+	}
+	if _, err := dm.GenerateDialogStream(DialogContext{Trigger: "click"}, func(string) {}); !errors.Is(err, ErrCapabilityUnsupported) {
+		t.Errorf("Expected a capability error streaming from the LLM backend, got %v", err)
+	}
+}
+
+func TestDialogManager_UpdateBackendMemoryRequiresLearning(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("plain", plainTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{})})
+	err := dm.UpdateBackendMemory(DialogContext{InteractionID: "pet"}, DialogResponse{}, &UserFeedback{Positive: true})
+	if !errors.Is(err, ErrCapabilityUnsupported) {
+		t.Errorf("Expected a capability error without a learning backend, got %v", err)
+	}
+
+	learner, _ := newRateLimitTestManager(t, "Hi!")
+	learner.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet"})
+	if err := learner.UpdateBackendMemory(DialogContext{InteractionID: "pet"}, DialogResponse{}, &UserFeedback{Positive: true, Engagement: 1}); err != nil {
+		t.Errorf("Expected the learning backend to accept feedback, got %v", err)
+	}
+	backend, _ := learner.GetBackend("llm")
+	if history := backend.(*LLMBackend).GetContextManager().GetHistory("pet", 0); len(history) != 1 || !history[0].FeedbackReceived {
+		t.Errorf("Expected feedback recorded, got %+v", history)
+	}
+}
@@ -0,0 +1,236 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pngSignature starts every PNG file
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// cardTextKeywords are the PNG tEXt keywords holding a card's base64 JSON, preferred first
+var cardTextKeywords = []string{"ccv3", "chara"}
+
+// cardStartPattern separates the example conversations in mes_example
+var cardStartPattern = regexp.MustCompile(`(?i)<start>`)
+
+// CharacterCard is a SillyTavern/TavernAI character card (spec chara_card_v2, or a
+// flat v1 card), the format most shared roleplay characters are published in
+// Text fields may contain the {{char}} and {{user}} placeholders
+type CharacterCard struct {
+	Name                    string                     `json:"name"`
+	Description             string                     `json:"description"`
+	Personality             string                     `json:"personality"`
+	Scenario                string                     `json:"scenario"`
+	FirstMessage            string                     `json:"first_mes"`
+	MessageExamples         string                     `json:"mes_example"`
+	SystemPrompt            string                     `json:"system_prompt"`
+	PostHistoryInstructions string                     `json:"post_history_instructions"`
+	AlternateGreetings      []string                   `json:"alternate_greetings"`
+	Tags                    []string                   `json:"tags"`
+	Creator                 string                     `json:"creator"`
+	CreatorNotes            string                     `json:"creator_notes"`
+	CharacterVersion        string                     `json:"character_version"`
+	Extensions              map[string]json.RawMessage `json:"extensions"` // Tool-specific data, kept as is
+}
+
+// ParseCharacterCard reads a character card from card JSON or from a PNG with the card
+// embedded in a "ccv3" or "chara" text chunk
+func ParseCharacterCard(data []byte) (*CharacterCard, error) {
+	if bytes.HasPrefix(data, pngSignature) {
+		text, err := pngCardText(data)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = base64.StdEncoding.DecodeString(strings.TrimSpace(text)); err != nil {
+			return nil, fmt.Errorf("invalid character card data in PNG: %w", err)
+		}
+	}
+
+	var envelope struct {
+		Spec string          `json:"spec"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid character card JSON: %w", err)
+	}
+	// v2 and v3 cards wrap the fields in data; v1 cards are flat
+	if envelope.Spec != "" && len(envelope.Data) > 0 {
+		data = envelope.Data
+	}
+
+	var card CharacterCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("invalid character card JSON: %w", err)
+	}
+	card.Name = strings.TrimSpace(card.Name)
+	if card.Name == "" {
+		return nil, fmt.Errorf("character card has no name")
+	}
+	return &card, nil
+}
+
+// pngChunk is one chunk of a PNG file
+type pngChunk struct {
+	kind string
+	data []byte
+}
+
+// pngChunks splits a PNG file into its chunks, up to and including IEND
+func pngChunks(data []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+	var chunks []pngChunk
+	for offset := len(pngSignature); offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		kind := string(data[offset+4 : offset+8])
+		start := offset + 8
+		if length < 0 || start+length+4 > len(data) {
+			return nil, fmt.Errorf("truncated PNG chunk %q", kind)
+		}
+		chunks = append(chunks, pngChunk{kind: kind, data: data[start : start+length]})
+		if kind == "IEND" {
+			return chunks, nil
+		}
+		offset = start + length + 4 // Skip the CRC
+	}
+	return nil, fmt.Errorf("PNG has no IEND chunk")
+}
+
+// cardChunkKeyword returns the keyword of a tEXt chunk holding a card, or ""
+func cardChunkKeyword(chunk pngChunk) string {
+	if chunk.kind != "tEXt" {
+		return ""
+	}
+	keyword, _, found := bytes.Cut(chunk.data, []byte{0})
+	if !found {
+		return ""
+	}
+	for _, known := range cardTextKeywords {
+		if strings.EqualFold(string(keyword), known) {
+			return known
+		}
+	}
+	return ""
+}
+
+// pngCardText returns the text of the PNG's card chunk
+func pngCardText(data []byte) (string, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return "", err
+	}
+	texts := make(map[string]string)
+	for _, chunk := range chunks {
+		if keyword := cardChunkKeyword(chunk); keyword != "" {
+			_, text, _ := bytes.Cut(chunk.data, []byte{0})
+			texts[keyword] = string(text)
+		}
+	}
+	for _, keyword := range cardTextKeywords {
+		if text, ok := texts[keyword]; ok {
+			return text, nil
+		}
+	}
+	return "", fmt.Errorf("PNG has no embedded character card")
+}
+
+// Expand replaces the card's placeholders: {{char}} and <BOT> with the character's
+// name, {{user}} and <USER> with userName
+func (c *CharacterCard) Expand(text, userName string) string {
+	replacer := strings.NewReplacer(
+		"{{char}}", c.Name, "{{Char}}", c.Name, "{{CHAR}}", c.Name, "<BOT>", c.Name, "<bot>", c.Name,
+		"{{user}}", userName, "{{User}}", userName, "{{USER}}", userName, "<USER>", userName, "<user>", userName,
+	)
+	return strings.TrimSpace(replacer.Replace(text))
+}
+
+// Persona combines the card's system prompt, description, personality and scenario into
+// standing instructions for LLMConfig.SystemPrompt, with placeholders expanded; text
+// repeated from an earlier section, as in a card exported from MiniLM, is left out
+func (c *CharacterCard) Persona(userName string) string {
+	var persona strings.Builder
+	add := func(section string) {
+		if section != "" && !strings.Contains(persona.String(), section) {
+			if persona.Len() > 0 {
+				persona.WriteString("\n")
+			}
+			persona.WriteString(section)
+		}
+	}
+	add(c.Expand(c.SystemPrompt, userName))
+	add("You are " + c.Name + ".")
+	add(c.Expand(c.Description, userName))
+	if personality := c.Expand(c.Personality, userName); personality != "" {
+		add(c.Name + "'s personality: " + personality)
+	}
+	if scenario := c.Expand(c.Scenario, userName); scenario != "" {
+		add("Scenario: " + scenario)
+	}
+	return persona.String()
+}
+
+// Greetings returns the first message and alternate greetings, placeholders expanded
+func (c *CharacterCard) Greetings(userName string) []string {
+	var greetings []string
+	for _, greeting := range append([]string{c.FirstMessage}, c.AlternateGreetings...) {
+		if greeting = c.Expand(greeting, userName); greeting != "" {
+			greetings = append(greetings, greeting)
+		}
+	}
+	return greetings
+}
+
+// Examples parses the mes_example conversations into training pairs: each of the
+// character's lines with the user line before it as the prompt ("" when it speaks first)
+// Lines without a speaker prefix continue the previous line
+func (c *CharacterCard) Examples(userName string) []TrainingExample {
+	speakers := map[string]string{"{{char}}:": "char", "<bot>:": "char", c.Name + ":": "char", "{{user}}:": "user", "<user>:": "user"}
+	speakerOf := func(line string) (string, string) {
+		for prefix, speaker := range speakers {
+			if len(line) >= len(prefix) && strings.EqualFold(line[:len(prefix)], prefix) {
+				return speaker, line[len(prefix):]
+			}
+		}
+		return "", line
+	}
+
+	var examples []TrainingExample
+	for _, block := range cardStartPattern.Split(c.MessageExamples, -1) {
+		var prompt, speaker string
+		var turn []string
+		finish := func() {
+			text := c.Expand(strings.Join(turn, " "), userName)
+			switch {
+			case text == "":
+			case speaker == "user":
+				prompt = text
+			case speaker == "char":
+				examples = append(examples, TrainingExample{Prompt: prompt, Response: text})
+				prompt = ""
+			}
+			turn = nil
+		}
+		for _, line := range strings.Split(block, "\n") {
+			line = strings.TrimSpace(line)
+			next, text := speakerOf(line)
+			if next == "" {
+				if line != "" && speaker != "" {
+					turn = append(turn, line)
+				}
+				continue
+			}
+			finish()
+			speaker = next
+			turn = append(turn, strings.TrimSpace(text))
+		}
+		finish()
+	}
+	return examples
+}
@@ -0,0 +1,160 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// cardExampleLimit caps the example exchanges written to a card's mes_example
+const cardExampleLimit = 12
+
+// CharacterCardSource is a MiniLM character to export as a character card
+type CharacterCardSource struct {
+	Name        string            // Character name
+	Description string            // character.json description
+	Greetings   []string          // Opening lines, e.g. the click dialog responses
+	Config      LLMConfig         // The llm backend: systemPrompt, personas and training data
+	Learned     []TrainingExample // Well-received exchanges from memory (TrainingExamples)
+}
+
+// ExportCharacterCard builds a character card from a character and what it learned:
+// the description and system prompt carry over (the system prompt standing in for a
+// missing description), the personas or a heuristic summary of the training data become
+// its personality, the greetings its first and alternate greetings, and learned
+// exchanges then training lines its example dialog
+func ExportCharacterCard(source CharacterCardSource) (*CharacterCard, error) {
+	name := strings.TrimSpace(source.Name)
+	if name == "" {
+		return nil, fmt.Errorf("character has no name")
+	}
+
+	card := &CharacterCard{
+		Name:         name,
+		Description:  strings.TrimSpace(source.Description),
+		SystemPrompt: strings.TrimSpace(source.Config.SystemPrompt),
+		CreatorNotes: "Exported from a MiniLM desktop pet.",
+	}
+	if card.Description == "" {
+		card.Description = card.SystemPrompt
+	}
+
+	if len(source.Config.Personas) > 0 {
+		if blend, err := NewPersonaBlend(source.Config.Personas); err == nil {
+			card.Personality = strings.TrimSpace(blend.Describe())
+		}
+	}
+	if card.Personality == "" && len(source.Config.MarkovConfig.TrainingData) > 0 {
+		card.Personality = heuristicPersonaSummary(source.Config.MarkovConfig.TrainingData)
+	}
+
+	for _, greeting := range source.Greetings {
+		if greeting = strings.TrimSpace(greeting); greeting == "" {
+			continue
+		}
+		if card.FirstMessage == "" {
+			card.FirstMessage = greeting
+		} else {
+			card.AlternateGreetings = append(card.AlternateGreetings, greeting)
+		}
+	}
+
+	examples := append([]TrainingExample(nil), source.Learned...)
+	for _, line := range source.Config.MarkovConfig.TrainingData {
+		examples = append(examples, TrainingExample{Response: line})
+	}
+	card.MessageExamples = formatCardExamples(examples)
+	return card, nil
+}
+
+// formatCardExamples writes up to cardExampleLimit examples as mes_example blocks,
+// skipping responses already written
+func formatCardExamples(examples []TrainingExample) string {
+	var blocks []string
+	seen := make(map[string]bool)
+	for _, example := range examples {
+		response := strings.TrimSpace(example.Response)
+		key := strings.ToLower(response)
+		if response == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		block := "<START>\n"
+		if prompt := strings.TrimSpace(example.Prompt); prompt != "" {
+			block += "{{user}}: " + prompt + "\n"
+		}
+		blocks = append(blocks, block+"{{char}}: "+response)
+		if len(blocks) == cardExampleLimit {
+			break
+		}
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// Encode writes the card as chara_card_v2 JSON, with every field the spec requires
+func (c *CharacterCard) Encode() ([]byte, error) {
+	data := *c
+	if data.AlternateGreetings == nil {
+		data.AlternateGreetings = []string{}
+	}
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
+	if data.Extensions == nil {
+		data.Extensions = map[string]json.RawMessage{}
+	}
+
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(struct {
+		Spec        string         `json:"spec"`
+		SpecVersion string         `json:"spec_version"`
+		Data        *CharacterCard `json:"data"`
+	}{"chara_card_v2", "2.0", &data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode character card: %w", err)
+	}
+	return encoded.Bytes(), nil
+}
+
+// EmbedPNG returns the PNG image with the card in a "chara" text chunk, the way card
+// images are shared; card chunks already in the image are replaced
+func (c *CharacterCard) EmbedPNG(png []byte) ([]byte, error) {
+	chunks, err := pngChunks(png)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := c.Encode()
+	if err != nil {
+		return nil, err
+	}
+	text := append([]byte("chara\x00"), base64.StdEncoding.EncodeToString(bytes.TrimSpace(encoded))...)
+
+	var out bytes.Buffer
+	out.Write(pngSignature)
+	for _, chunk := range chunks {
+		if cardChunkKeyword(chunk) != "" {
+			continue
+		}
+		if chunk.kind == "IEND" {
+			writePNGChunk(&out, "tEXt", text)
+		}
+		writePNGChunk(&out, chunk.kind, chunk.data)
+	}
+	return out.Bytes(), nil
+}
+
+// writePNGChunk writes a chunk with its length and CRC
+func writePNGChunk(out *bytes.Buffer, kind string, data []byte) {
+	binary.Write(out, binary.BigEndian, uint32(len(data)))
+	out.WriteString(kind)
+	out.Write(data)
+	binary.Write(out, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(kind), data...)))
+}
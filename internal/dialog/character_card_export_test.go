@@ -0,0 +1,87 @@
+package dialog
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportCharacterCard(t *testing.T) {
+	card, err := ExportCharacterCard(CharacterCardSource{
+		Name:        "Mochi",
+		Description: "A round white cat",
+		Greetings:   []string{"Hi there!", " ", "Mrrp?"},
+		Config: LLMConfig{
+			SystemPrompt: "You are Mochi.\nMochi is a sleepy cat.",
+			MarkovConfig: MarkovChainConfig{TrainingData: catLines},
+		},
+		Learned: []TrainingExample{{Prompt: "Want a treat?", Response: "Treats? Did someone say treats?! ~"}},
+	})
+	if err != nil {
+		t.Fatalf("ExportCharacterCard failed: %v", err)
+	}
+
+	if card.Description != "A round white cat" || card.SystemPrompt != "You are Mochi.\nMochi is a sleepy cat." || card.Personality != heuristicPersonaSummary(catLines) {
+		t.Errorf("Expected the description, system prompt and a summary of the training data, got %+v", card)
+	}
+	// Importing the card again does not repeat what the system prompt already says
+	if persona := card.Persona("Sam"); strings.Count(persona, "You are Mochi") != 1 {
+		t.Errorf("Expected the name introduced once, got %q", persona)
+	}
+	if card.FirstMessage != "Hi there!" || !reflect.DeepEqual(card.AlternateGreetings, []string{"Mrrp?"}) {
+		t.Errorf("Expected the greetings split into first and alternate, got %q and %v", card.FirstMessage, card.AlternateGreetings)
+	}
+	if !strings.HasPrefix(card.MessageExamples, "<START>\n{{user}}: Want a treat?\n{{char}}: Treats? Did someone say treats?! ~\n<START>\n{{char}}: Naps") {
+		t.Errorf("Expected learned exchanges before training lines, each once, got:\n%s", card.MessageExamples)
+	}
+	if strings.Count(card.MessageExamples, "<START>") != len(catLines) {
+		t.Errorf("Expected one block per distinct line, got:\n%s", card.MessageExamples)
+	}
+
+	if _, err := ExportCharacterCard(CharacterCardSource{}); err == nil {
+		t.Error("Expected a character without a name to be rejected")
+	}
+}
+
+func TestCharacterCard_EncodeRoundTrip(t *testing.T) {
+	card, err := ExportCharacterCard(CharacterCardSource{
+		Name:   "Rex",
+		Config: LLMConfig{Personas: []PersonaProfile{{Name: "loyal dog", Description: "eager to please", Weight: 1}}},
+	})
+	if err != nil {
+		t.Fatalf("ExportCharacterCard failed: %v", err)
+	}
+	if !strings.Contains(card.Personality, "100% loyal dog: eager to please") {
+		t.Errorf("Expected the persona blend as the personality, got %q", card.Personality)
+	}
+
+	encoded, err := card.Encode()
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	for _, want := range []string{`"spec": "chara_card_v2"`, `"alternate_greetings": []`, `"extensions": {}`} {
+		if !bytes.Contains(encoded, []byte(want)) {
+			t.Errorf("Expected %s in the card, got:\n%s", want, encoded)
+		}
+	}
+	parsed, err := ParseCharacterCard(encoded)
+	if err != nil || parsed.Name != "Rex" || parsed.Personality != card.Personality {
+		t.Errorf("Expected the card to parse back, got %+v (%v)", parsed, err)
+	}
+
+	// Embedding replaces the image's card rather than adding a second one
+	png, err := card.EmbedPNG(pngWithText("chara", "old card"))
+	if err != nil {
+		t.Fatalf("EmbedPNG failed: %v", err)
+	}
+	if bytes.Contains(png, []byte("old card")) {
+		t.Error("Expected the old card chunk to be replaced")
+	}
+	if parsed, err := ParseCharacterCard(png); err != nil || parsed.Name != "Rex" {
+		t.Errorf("Expected the embedded card to parse, got %+v (%v)", parsed, err)
+	}
+	if _, err := card.EmbedPNG([]byte("GIF89a")); err == nil {
+		t.Error("Expected a non-PNG image to be rejected")
+	}
+}
@@ -0,0 +1,104 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testCardV2 = `{
+	"spec": "chara_card_v2",
+	"spec_version": "2.0",
+	"data": {
+		"name": "Mochi",
+		"description": "{{char}} is a round white cat who lives on {{user}}'s desktop.",
+		"personality": "sleepy, affectionate",
+		"scenario": "",
+		"first_mes": "*yawns* Oh, hi {{user}}!",
+		"mes_example": "<START>\n{{user}}: Want a treat?\n{{char}}: Treats?!\nI'm awake now!\n<START>\nMochi: Mrrp~\n{{user}}: Hi Mochi\n<BOT>: Hi <USER>!",
+		"alternate_greetings": ["", "Purr..."],
+		"tags": ["cat"]
+	}
+}`
+
+// pngWithText builds a minimal PNG holding one tEXt chunk
+func pngWithText(keyword, text string) []byte {
+	var png bytes.Buffer
+	png.Write(pngSignature)
+	writePNGChunk(&png, "IHDR", make([]byte, 13))
+	writePNGChunk(&png, "tEXt", []byte(keyword+"\x00"+text))
+	writePNGChunk(&png, "IEND", nil)
+	return png.Bytes()
+}
+
+func TestParseCharacterCard(t *testing.T) {
+	card, err := ParseCharacterCard([]byte(testCardV2))
+	if err != nil {
+		t.Fatalf("ParseCharacterCard failed: %v", err)
+	}
+	if card.Name != "Mochi" || card.Personality != "sleepy, affectionate" || len(card.AlternateGreetings) != 2 {
+		t.Errorf("Expected the v2 data fields, got %+v", card)
+	}
+
+	fromPNG, err := ParseCharacterCard(pngWithText("chara", base64.StdEncoding.EncodeToString([]byte(testCardV2))))
+	if err != nil {
+		t.Fatalf("ParseCharacterCard failed on a PNG card: %v", err)
+	}
+	if !reflect.DeepEqual(fromPNG, card) {
+		t.Errorf("Expected the PNG card to match the JSON card, got %+v", fromPNG)
+	}
+
+	v1, err := ParseCharacterCard([]byte(`{"name": " Rex ", "description": "A dog", "first_mes": "Woof!"}`))
+	if err != nil || v1.Name != "Rex" || v1.FirstMessage != "Woof!" {
+		t.Errorf("Expected a flat v1 card to parse, got %+v (%v)", v1, err)
+	}
+
+	for name, data := range map[string][]byte{
+		"no name":      []byte(`{"spec": "chara_card_v2", "data": {"description": "Nobody"}}`),
+		"not JSON":     []byte("hello"),
+		"plain PNG":    pngWithText("Comment", "made with paint"),
+		"bad base64":   pngWithText("chara", "!!!"),
+		"truncated":    pngWithText("chara", "e30=")[:20],
+		"PNG non-card": pngWithText("chara", base64.StdEncoding.EncodeToString([]byte("[]"))),
+	} {
+		if _, err := ParseCharacterCard(data); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestCharacterCard_Conversion(t *testing.T) {
+	card, err := ParseCharacterCard([]byte(testCardV2))
+	if err != nil {
+		t.Fatalf("ParseCharacterCard failed: %v", err)
+	}
+
+	want := "You are Mochi.\nMochi is a round white cat who lives on Sam's desktop.\nMochi's personality: sleepy, affectionate"
+	if persona := card.Persona("Sam"); persona != want {
+		t.Errorf("Expected persona %q, got %q", want, persona)
+	}
+	if greetings := card.Greetings("Sam"); !reflect.DeepEqual(greetings, []string{"*yawns* Oh, hi Sam!", "Purr..."}) {
+		t.Errorf("Expected the non-empty greetings, got %v", greetings)
+	}
+
+	examples := card.Examples("Sam")
+	wantExamples := []TrainingExample{
+		{Prompt: "Want a treat?", Response: "Treats?! I'm awake now!"},
+		{Prompt: "", Response: "Mrrp~"},
+		{Prompt: "Hi Mochi", Response: "Hi Sam!"},
+	}
+	if !reflect.DeepEqual(examples, wantExamples) {
+		t.Errorf("Expected %+v, got %+v", wantExamples, examples)
+	}
+}
+
+func TestLLMBackend_SystemPrompt(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{SystemPrompt: "  You are Mochi, a sleepy cat.  "}, &scriptedTestModel{responses: []string{"Mrrp"}})
+
+	prompt := backend.buildPrompt(DialogContext{Trigger: "click"})
+	if !strings.HasPrefix(prompt, "You are Mochi, a sleepy cat.\n\n") {
+		t.Errorf("Expected the system prompt to open the prompt, got:\n%s", prompt)
+	}
+}
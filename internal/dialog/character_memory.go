@@ -0,0 +1,151 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// llmBackendState is LLMBackend's checkpoint: the shared history in ContextState form, so
+// checkpoints without characters stay readable by ContextManager.LoadState, plus one
+// ContextState per character and per tenant and character
+type llmBackendState struct {
+	ContextState
+	Characters map[string]json.RawMessage            `json:"characters,omitempty"`
+	Tenants    map[string]map[string]json.RawMessage `json:"tenants,omitempty"`
+}
+
+// contextFor returns the conversation memory for a request's tenant and character, creating
+// it on first use. Without either ID the backend's shared ContextManager is used
+func (llm *LLMBackend) contextFor(ctx DialogContext) *ContextManager {
+	if ctx.TenantID == "" && ctx.CharacterID == "" {
+		return llm.contextManager
+	}
+
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+	if ctx.TenantID != "" {
+		return llm.tenantContextLocked(ctx.TenantID, ctx.CharacterID)
+	}
+	characterID := ctx.CharacterID
+	if cm, exists := llm.characterContexts[characterID]; exists {
+		return cm
+	}
+	if llm.characterContexts == nil {
+		llm.characterContexts = make(map[string]*ContextManager)
+	}
+	cm := llm.newHistoryStore(0)
+	llm.characterContexts[characterID] = cm
+	return cm
+}
+
+// newHistoryStore creates a ContextManager with the backend's history settings
+func (llm *LLMBackend) newHistoryStore(maxConversations int) *ContextManager {
+	cm := NewContextManagerWithConfig(llm.maxHistoryLength, maxConversations, 0, 0)
+	cm.SetEventBus(llm.events)
+	cm.SetRedactor(llm.redactor)
+	cm.SetMaxBytes(llm.maxHistoryBytes)
+	cm.SetClock(llm.clock)
+	return cm
+}
+
+// GetCharacterContextManager returns the isolated history store used for DialogContext.CharacterID
+// The empty ID returns the shared store, like GetContextManager
+func (llm *LLMBackend) GetCharacterContextManager(characterID string) *ContextManager {
+	if characterID == "" {
+		return llm.GetContextManager()
+	}
+	return llm.contextFor(DialogContext{CharacterID: characterID})
+}
+
+// Characters lists the character IDs that have their own conversation memory
+func (llm *LLMBackend) Characters() []string {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	ids := make([]string, 0, len(llm.characterContexts))
+	for id := range llm.characterContexts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// characterContextManagers returns a snapshot of the per-character stores
+func (llm *LLMBackend) characterContextManagers() map[string]*ContextManager {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	managers := make(map[string]*ContextManager, len(llm.characterContexts))
+	for id, cm := range llm.characterContexts {
+		managers[id] = cm
+	}
+	return managers
+}
+
+// isolatedContextManagers returns every per-character and per-tenant store
+func (llm *LLMBackend) isolatedContextManagers() []*ContextManager {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	managers := make([]*ContextManager, 0, len(llm.characterContexts))
+	for _, cm := range llm.characterContexts {
+		managers = append(managers, cm)
+	}
+	for _, tenant := range llm.tenantContexts {
+		for _, cm := range tenant.contexts {
+			managers = append(managers, cm)
+		}
+	}
+	return managers
+}
+
+// closeCharacterContexts stops every per-character store
+func (llm *LLMBackend) closeCharacterContexts() {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	for _, cm := range llm.characterContexts {
+		cm.Close()
+	}
+	llm.characterContexts = nil
+}
+
+// saveCharacterStates checkpoints each character's history, keyed by character ID
+func (llm *LLMBackend) saveCharacterStates() (map[string]json.RawMessage, error) {
+	managers := llm.characterContextManagers()
+	if len(managers) == 0 {
+		return nil, nil
+	}
+
+	states := make(map[string]json.RawMessage, len(managers))
+	for id, cm := range managers {
+		data, err := cm.SaveState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to save history of character '%s': %w", id, err)
+		}
+		states[id] = data
+	}
+	return states, nil
+}
+
+// loadCharacterStates replaces every character's history with the checkpointed ones
+func (llm *LLMBackend) loadCharacterStates(states map[string]json.RawMessage) error {
+	llm.closeCharacterContexts()
+
+	ids := make([]string, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if id == "" {
+			return fmt.Errorf("backend state has a character without an ID")
+		}
+		if err := llm.contextFor(DialogContext{CharacterID: id}).LoadState(states[id]); err != nil {
+			return fmt.Errorf("failed to load history of character '%s': %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLLMBackend_CharacterHistoryIsolated(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"I love fish!", "Bones are the best!"}})
+	backend.GenerateResponse(DialogContext{Trigger: "feed", InteractionID: "user", CharacterID: "cat"})
+	backend.GenerateResponse(DialogContext{Trigger: "feed", InteractionID: "user", CharacterID: "dog"})
+
+	if history := backend.GetCharacterContextManager("cat").GetHistory("user", 0); len(history) != 1 || history[0].Response != "I love fish!" {
+		t.Errorf("Expected only the cat's exchange in its history, got %+v", history)
+	}
+	if history := backend.GetContextManager().GetHistory("user", 0); len(history) != 0 {
+		t.Errorf("Expected character exchanges kept out of the shared history, got %+v", history)
+	}
+
+	prompt := backend.buildPrompt(DialogContext{Trigger: "click", InteractionID: "user", CharacterID: "dog"})
+	if strings.Contains(prompt, "I love fish!") || !strings.Contains(prompt, "Bones are the best!") {
+		t.Errorf("Expected only the dog's history in its prompt, got:\n%s", prompt)
+	}
+	if ids := backend.Characters(); len(ids) != 2 || ids[0] != "cat" {
+		t.Errorf("Expected both characters listed, got %v", ids)
+	}
+}
+
+func TestLLMBackend_CharacterFeedback(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Purr"}})
+	context := DialogContext{Trigger: "pet", InteractionID: "user", CharacterID: "cat"}
+	backend.GenerateResponse(context)
+	backend.UpdateMemory(context, DialogResponse{}, &UserFeedback{Positive: true, Engagement: 1})
+
+	if history := backend.GetCharacterContextManager("cat").GetHistory("user", 0); len(history) != 1 || !history[0].FeedbackReceived {
+		t.Errorf("Expected feedback recorded in the character's history, got %+v", history)
+	}
+}
+
+func TestLLMBackend_SaveLoadCharacterState(t *testing.T) {
+	source := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Shared", "Meow"}})
+	source.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user"})
+	source.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user", CharacterID: "cat"})
+	data, err := source.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	target := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{})
+	target.GetCharacterContextManager("stale").AddExchange("user", "click", "Old")
+	if err := target.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if history := target.GetCharacterContextManager("cat").GetHistory("user", 0); len(history) != 1 || history[0].Response != "Meow" {
+		t.Errorf("Expected the character's history restored, got %+v", history)
+	}
+	if history := target.GetContextManager().GetHistory("user", 0); len(history) != 1 || history[0].Response != "Shared" {
+		t.Errorf("Expected the shared history restored, got %+v", history)
+	}
+	if ids := target.Characters(); len(ids) != 1 {
+		t.Errorf("Expected characters missing from the checkpoint dropped, got %v", ids)
+	}
+
+	// Checkpoints of the shared history alone remain plain context states
+	cm := NewContextManager(5)
+	defer cm.Close()
+	if err := cm.LoadState(data); err != nil || len(cm.GetHistory("user", 0)) != 1 {
+		t.Errorf("Expected the backend checkpoint readable as a context state, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CharacterChatTrigger is the trigger for each line of a multi-character conversation
+// The topic, when one is set, is in TopicContext["character_chat"]
+const CharacterChatTrigger = "character_chat"
+
+// defaultCharacterTurns is how many lines a conversation runs when Turns is unset
+const defaultCharacterTurns = 4
+
+// Character is a participant in conversations between characters (pet-to-pet banter)
+type Character struct {
+	Name    string               // How the other characters refer to it, e.g. "Mochi"
+	Backend string               // Registered backend that speaks for it ("" = default routing)
+	Context func() DialogContext // Current state supplied by the host (optional)
+}
+
+// CharacterConversation configures a dialog between registered characters
+type CharacterConversation struct {
+	Participants []string               // Character names in speaking order; at least two
+	Turns        int                    // Lines to generate (default: 4)
+	Topic        string                 // What the first speaker brings up (optional)
+	Transcript   []ConversationExchange // Earlier lines to continue from (optional)
+}
+
+// CharacterTurn is one line of a multi-character conversation with the context it was generated for
+type CharacterTurn struct {
+	Speaker  string
+	Context  DialogContext
+	Response DialogResponse
+}
+
+// RegisterCharacter adds a character that can take part in RunCharacterConversation
+// Registering a name again replaces the earlier character
+func (dm *DialogManager) RegisterCharacter(character Character) error {
+	if character.Name == "" {
+		return fmt.Errorf("character needs a name")
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if character.Backend != "" {
+		if _, exists := dm.backends[character.Backend]; !exists {
+			return fmt.Errorf("backend '%s' for character '%s' not registered", character.Backend, character.Name)
+		}
+	}
+	if dm.characters == nil {
+		dm.characters = make(map[string]Character)
+	}
+	dm.characters[character.Name] = character
+	return nil
+}
+
+// RunCharacterConversation has the participants take turns speaking, each seeing the
+// shared transcript with speakers attributed, and returns the transcript with the new lines
+// onTurn, when set, receives each line as soon as it is generated; generation stops at
+// the first error, returning the lines so far
+func (dm *DialogManager) RunCharacterConversation(conversation CharacterConversation, onTurn func(CharacterTurn)) ([]ConversationExchange, error) {
+	cast, err := dm.conversationCast(conversation.Participants)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Turns < 0 {
+		return nil, fmt.Errorf("turns must be non-negative, got %d", conversation.Turns)
+	}
+	turns := conversation.Turns
+	if turns == 0 {
+		turns = defaultCharacterTurns
+	}
+
+	transcript := append([]ConversationExchange(nil), conversation.Transcript...)
+	next := nextSpeaker(cast, transcript)
+	for i := 0; i < turns; i++ {
+		speaker := cast[(next+i)%len(cast)]
+		context := characterTurnContext(speaker, cast, transcript, conversation.Topic)
+
+		response, err := dm.GenerateDialog(context)
+		if err != nil {
+			return transcript, err
+		}
+
+		transcript = append(transcript, ConversationExchange{
+			Timestamp:    context.Timestamp,
+			Trigger:      CharacterChatTrigger,
+			Response:     response.Text,
+			Speaker:      speaker.Name,
+			ResponseType: response.ResponseType,
+			Importance:   response.MemoryImportance,
+		})
+		if onTurn != nil {
+			onTurn(CharacterTurn{Speaker: speaker.Name, Context: context, Response: response})
+		}
+	}
+	return transcript, nil
+}
+
+// conversationCast looks up the registered characters taking part, in speaking order
+func (dm *DialogManager) conversationCast(names []string) ([]Character, error) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	cast := make([]Character, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		character, exists := dm.characters[name]
+		if !exists {
+			return nil, fmt.Errorf("character '%s' not registered", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("character '%s' listed twice", name)
+		}
+		seen[name] = true
+		cast = append(cast, character)
+	}
+	if len(cast) < 2 {
+		return nil, fmt.Errorf("a conversation needs at least two characters, got %d", len(cast))
+	}
+	return cast, nil
+}
+
+// nextSpeaker continues the rotation after the transcript's last speaker, or starts with the first
+func nextSpeaker(cast []Character, transcript []ConversationExchange) int {
+	if len(transcript) == 0 {
+		return 0
+	}
+	last := transcript[len(transcript)-1].Speaker
+	for i, character := range cast {
+		if character.Name == last {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// characterTurnContext builds the speaker's context for its next line
+// The speaker's own InteractionID keeps mood and memory per character (default: its name)
+func characterTurnContext(speaker Character, cast []Character, transcript []ConversationExchange, topic string) DialogContext {
+	var context DialogContext
+	if speaker.Context != nil {
+		context = speaker.Context()
+	}
+	if context.InteractionID == "" {
+		context.InteractionID = speaker.Name
+	}
+	context.Trigger = CharacterChatTrigger
+	context.Timestamp = currentTime()
+	context.Speaker = speaker.Name
+	context.Partners = make([]string, 0, len(cast)-1)
+	for _, character := range cast {
+		if character.Name != speaker.Name {
+			context.Partners = append(context.Partners, character.Name)
+		}
+	}
+	context.Conversation = append([]ConversationExchange(nil), transcript...)
+	context.ConversationTurn = len(transcript) + 1
+
+	if topic != "" {
+		topics := make(map[string]interface{}, len(context.TopicContext)+1)
+		for key, value := range context.TopicContext {
+			topics[key] = value
+		}
+		topics[CharacterChatTrigger] = topic
+		context.TopicContext = topics
+	}
+	return context
+}
+
+// buildCharacterChatInstructions tells the speaker who it is talking with and what to answer
+// It returns "" outside multi-character conversations
+func (pb *PromptBuilder) buildCharacterChatInstructions() string {
+	if pb.context.Trigger != CharacterChatTrigger || pb.context.Speaker == "" {
+		return ""
+	}
+
+	partners := joinNames(pb.context.Partners)
+	if len(pb.context.Conversation) == 0 {
+		instruction := fmt.Sprintf("- You are %s, starting a chat with %s", pb.context.Speaker, partners)
+		if topic, ok := pb.context.TopicContext[CharacterChatTrigger].(string); ok && topic != "" {
+			instruction += " about " + topic
+		}
+		return instruction + "\n"
+	}
+
+	last := pb.context.Conversation[len(pb.context.Conversation)-1].Speaker
+	if last == "" || last == pb.context.Speaker {
+		return fmt.Sprintf("- You are %s, chatting with %s; keep the conversation going\n", pb.context.Speaker, partners)
+	}
+	return fmt.Sprintf("- You are %s, chatting with %s; reply to what %s just said\n", pb.context.Speaker, partners, last)
+}
+
+// speakerName names who said an attributed exchange from the prompt's point of view
+func (pb *PromptBuilder) speakerName(speaker string) string {
+	if speaker == pb.context.Speaker {
+		return "You"
+	}
+	return speaker
+}
+
+// joinNames lists names in prose: "A", "A and B", "A, B and C"
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
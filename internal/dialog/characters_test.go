@@ -0,0 +1,130 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+// newCharacterTestManager registers Mochi and Pip, each spoken for by its own scripted backend
+func newCharacterTestManager(t *testing.T) (*DialogManager, *LLMBackend, *LLMBackend) {
+	t.Helper()
+
+	dm := NewDialogManager(false)
+	mochi := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Pip, want to chase the cursor? 🐾", "Race you to the corner!"}})
+	pip := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Only if I get a head start!", "Not fair!"}})
+	dm.RegisterBackend("mochi", mochi)
+	dm.RegisterBackend("pip", pip)
+	dm.SetDefaultBackend("mochi")
+
+	if err := dm.RegisterCharacter(Character{Name: "Mochi", Backend: "mochi",
+		Context: func() DialogContext { return DialogContext{InteractionID: "mochi-1", CurrentMood: 80} }}); err != nil {
+		t.Fatalf("Failed to register Mochi: %v", err)
+	}
+	if err := dm.RegisterCharacter(Character{Name: "Pip", Backend: "pip"}); err != nil {
+		t.Fatalf("Failed to register Pip: %v", err)
+	}
+	return dm, mochi, pip
+}
+
+func TestDialogManager_RegisterCharacterValidation(t *testing.T) {
+	dm := NewDialogManager(false)
+	if err := dm.RegisterCharacter(Character{}); err == nil {
+		t.Error("Expected error for a character without a name")
+	}
+	if err := dm.RegisterCharacter(Character{Name: "Mochi", Backend: "missing"}); err == nil {
+		t.Error("Expected error for an unregistered backend")
+	}
+}
+
+func TestDialogManager_RunCharacterConversation(t *testing.T) {
+	dm, _, _ := newCharacterTestManager(t)
+
+	var turns []CharacterTurn
+	transcript, err := dm.RunCharacterConversation(CharacterConversation{
+		Participants: []string{"Mochi", "Pip"},
+		Topic:        "the cursor",
+	}, func(turn CharacterTurn) { turns = append(turns, turn) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct{ speaker, text string }{
+		{"Mochi", "Pip, want to chase the cursor? 🐾"},
+		{"Pip", "Only if I get a head start!"},
+		{"Mochi", "Race you to the corner!"},
+		{"Pip", "Not fair!"},
+	}
+	if len(transcript) != len(expected) || len(turns) != len(expected) {
+		t.Fatalf("Expected %d lines, got %+v", len(expected), transcript)
+	}
+	for i, line := range transcript {
+		if line.Speaker != expected[i].speaker || line.Response != expected[i].text || line.Trigger != CharacterChatTrigger {
+			t.Errorf("Line %d: expected %s: %q, got %+v", i, expected[i].speaker, expected[i].text, line)
+		}
+	}
+
+	second := turns[1].Context
+	if second.Speaker != "Pip" || second.InteractionID != "Pip" || len(second.Partners) != 1 || second.Partners[0] != "Mochi" {
+		t.Errorf("Expected Pip's own context with Mochi as partner, got %+v", second)
+	}
+	if len(second.Conversation) != 1 || second.ConversationTurn != 2 || second.TopicContext[CharacterChatTrigger] != "the cursor" {
+		t.Errorf("Expected the shared transcript and topic, got %+v", second)
+	}
+	if first := turns[0].Context; first.InteractionID != "mochi-1" || first.CurrentMood != 80 {
+		t.Errorf("Expected Mochi's host-supplied state, got %+v", first)
+	}
+}
+
+func TestDialogManager_RunCharacterConversationContinues(t *testing.T) {
+	dm, _, _ := newCharacterTestManager(t)
+
+	earlier := []ConversationExchange{{Speaker: "Mochi", Trigger: CharacterChatTrigger, Response: "Hi Pip!"}}
+	transcript, err := dm.RunCharacterConversation(CharacterConversation{Participants: []string{"Mochi", "Pip"}, Turns: 1, Transcript: earlier}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(transcript) != 2 || transcript[1].Speaker != "Pip" {
+		t.Errorf("Expected Pip to answer the earlier line, got %+v", transcript)
+	}
+
+	invalid := []CharacterConversation{
+		{Participants: []string{"Mochi"}},
+		{Participants: []string{"Mochi", "Mochi"}},
+		{Participants: []string{"Mochi", "Rex"}},
+		{Participants: []string{"Mochi", "Pip"}, Turns: -1},
+	}
+	for _, conversation := range invalid {
+		if _, err := dm.RunCharacterConversation(conversation, nil); err == nil {
+			t.Errorf("Expected error for %+v", conversation)
+		}
+	}
+}
+
+func TestPromptBuilder_CharacterChat(t *testing.T) {
+	dm, _, pip := newCharacterTestManager(t)
+
+	var turns []CharacterTurn
+	dm.RunCharacterConversation(CharacterConversation{Participants: []string{"Mochi", "Pip"}, Turns: 3},
+		func(turn CharacterTurn) { turns = append(turns, turn) })
+
+	prompt := pip.buildPrompt(turns[2].Context)
+	for _, expected := range []string{
+		"- You are Mochi, chatting with Pip; reply to what Pip just said\n",
+		"You said: \"Pip, want to chase the cursor? 🐾\"",
+		"Pip said: \"Only if I get a head start!\"",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", expected, prompt)
+		}
+	}
+	if strings.Contains(prompt, "The user just performed") {
+		t.Errorf("Expected no user action in a character chat, got:\n%s", prompt)
+	}
+
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{Trigger: CharacterChatTrigger, Speaker: "Mochi", Partners: []string{"Pip", "Tofu"},
+		TopicContext: map[string]interface{}{CharacterChatTrigger: "snacks"}})
+	if prompt := pb.Build(); !strings.Contains(prompt, "- You are Mochi, starting a chat with Pip and Tofu about snacks\n") {
+		t.Errorf("Expected an opening instruction, got:\n%s", prompt)
+	}
+}
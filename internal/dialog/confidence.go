@@ -0,0 +1,73 @@
+package dialog
+
+import (
+	"context"
+	"math"
+)
+
+// PredictionStats describes how a completion was produced
+// Models that cannot report a statistic leave it at its zero value
+type PredictionStats struct {
+	TokenCount   int     `json:"tokenCount"`   // Generated tokens
+	MeanLogprob  float64 `json:"meanLogprob"`  // Average per-token log probability (<= 0)
+	HasLogprobs  bool    `json:"hasLogprobs"`  // Whether MeanLogprob is populated
+	HitMaxTokens bool    `json:"hitMaxTokens"` // Generation stopped at the token limit
+}
+
+// StatsPredictor is implemented by models that report generation statistics with each completion
+// LLMBackend uses it when available to derive response confidence
+type StatsPredictor interface {
+	PredictWithStats(ctx context.Context, prompt string, opts PredictOptions) (string, PredictionStats, error)
+}
+
+// generationResult carries generated text and the signals used to score confidence
+type generationResult struct {
+	text          string
+	stats         PredictionStats
+	truncated     bool // Sentences were dropped to fit the display limit
+	empty         bool // The model produced nothing usable and a placeholder was substituted
+	retries       int  // Transient failures retried before success
+	regenerations int  // Responses rejected by validators before this one
+}
+
+// Confidence scoring parameters
+const (
+	baseGenerationConfidence      = 0.85 // Used when the model does not report logprobs
+	minGenerationConfidence       = 0.05
+	maxGenerationConfidence       = 0.95
+	placeholderConfidence         = 0.2 // Empty output replaced with a generic greeting
+	retryConfidencePenalty        = 0.05
+	regenerationConfidencePenalty = 0.1
+	truncationConfidencePenalty   = 0.15
+)
+
+// confidence scores a successful generation between 0 and 1
+func (g generationResult) confidence() float64 {
+	if g.empty {
+		return placeholderConfidence
+	}
+
+	score := baseGenerationConfidence
+	if g.stats.HasLogprobs {
+		// Geometric-mean token probability: fluent, likely completions score near the top
+		score = 0.3 + 0.65*math.Exp(math.Min(g.stats.MeanLogprob, 0))
+	}
+
+	score -= float64(g.retries) * retryConfidencePenalty
+	score -= float64(g.regenerations) * regenerationConfidencePenalty
+	if g.truncated || g.stats.HitMaxTokens {
+		score -= truncationConfidencePenalty
+	}
+
+	return math.Max(minGenerationConfidence, math.Min(maxGenerationConfidence, score))
+}
+
+// predict calls the model, collecting generation statistics when the model reports them
+func (llm *LLMBackend) predict(ctx context.Context, prompt string, opts PredictOptions) (string, PredictionStats, error) {
+	if statsModel, ok := llm.model.(StatsPredictor); ok {
+		return statsModel.PredictWithStats(ctx, prompt, opts)
+	}
+
+	text, err := llm.model.PredictWithOptions(ctx, prompt, opts)
+	return text, PredictionStats{}, err
+}
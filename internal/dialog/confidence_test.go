@@ -0,0 +1,132 @@
+package dialog
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+)
+
+// statsTestModel wraps scriptedTestModel and reports fixed generation statistics
+type statsTestModel struct {
+	*scriptedTestModel
+	stats PredictionStats
+}
+
+func (m *statsTestModel) PredictWithStats(ctx context.Context, prompt string, opts PredictOptions) (string, PredictionStats, error) {
+	text, err := m.PredictWithOptions(ctx, prompt, opts)
+	return text, m.stats, err
+}
+
+func TestGenerationResult_Confidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   generationResult
+		expected float64
+	}{
+		{"clean generation", generationResult{}, baseGenerationConfidence},
+		{"placeholder", generationResult{empty: true}, placeholderConfidence},
+		{"one regeneration", generationResult{regenerations: 1}, 0.75},
+		{"retried twice", generationResult{retries: 2}, 0.75},
+		{"truncated", generationResult{truncated: true}, 0.70},
+		{"hit token limit", generationResult{stats: PredictionStats{HitMaxTokens: true}}, 0.70},
+		{"certain logprobs", generationResult{stats: PredictionStats{HasLogprobs: true}}, maxGenerationConfidence},
+		{"floor", generationResult{regenerations: 5, retries: 5, truncated: true}, minGenerationConfidence},
+	}
+
+	for _, tt := range tests {
+		if got := tt.result.confidence(); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected confidence %f, got %f", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestGenerationResult_ConfidenceFollowsLogprobs(t *testing.T) {
+	likely := generationResult{stats: PredictionStats{HasLogprobs: true, MeanLogprob: -0.2}}.confidence()
+	unlikely := generationResult{stats: PredictionStats{HasLogprobs: true, MeanLogprob: -3}}.confidence()
+
+	if likely <= unlikely {
+		t.Errorf("Expected likely completion to score higher: %f vs %f", likely, unlikely)
+	}
+	if unlikely >= defaultConfidenceThreshold {
+		t.Errorf("Expected low-probability completion below the default threshold, got %f", unlikely)
+	}
+}
+
+func TestLLMBackend_ConfidenceReflectsValidation(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"no", "Hello friend, nice to see you!"}}
+	backend := newScriptedBackend(t, LLMConfig{Validation: ValidationConfig{MinLength: 10}}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "confidence"})
+	if err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+
+	expected := baseGenerationConfidence - regenerationConfidencePenalty
+	if math.Abs(response.Confidence-expected) > 1e-9 {
+		t.Errorf("Expected confidence %f after one regeneration, got %f", expected, response.Confidence)
+	}
+}
+
+func TestLLMBackend_ConfidenceUsesModelStats(t *testing.T) {
+	model := &statsTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Hmm, maybe?"}},
+		stats:             PredictionStats{TokenCount: 4, HasLogprobs: true, MeanLogprob: -2.5},
+	}
+	backend := newScriptedBackend(t, LLMConfig{}, model.scriptedTestModel)
+	backend.model = model
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "stats"})
+	if err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if response.Confidence >= defaultConfidenceThreshold {
+		t.Errorf("Expected low confidence from poor logprobs, got %f", response.Confidence)
+	}
+}
+
+func TestLLMBackend_CleanResponseTextFlags(t *testing.T) {
+	backend := NewLLMBackend()
+
+	long := strings.Repeat("This is a fairly long sentence about nothing. ", 5)
+	if _, truncated, _ := backend.cleanResponseText(long); !truncated {
+		t.Error("Expected long response to be flagged as truncated")
+	}
+	if _, _, empty := backend.cleanResponseText("   "); !empty {
+		t.Error("Expected blank response to be flagged as empty")
+	}
+	if _, truncated, empty := backend.cleanResponseText("Hi there!"); truncated || empty {
+		t.Error("Expected short response to be unflagged")
+	}
+}
+
+func TestDialogManager_ConfidenceThresholdGating(t *testing.T) {
+	dm := NewDialogManager(false)
+	low := &statsTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Uh, what?"}},
+		stats:             PredictionStats{HasLogprobs: true, MeanLogprob: -2},
+	}
+	primary := newScriptedBackend(t, LLMConfig{}, low.scriptedTestModel)
+	primary.model = low
+	dm.RegisterBackend("primary", primary)
+	dm.RegisterBackend("secondary", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hello there!"}}))
+	dm.SetDefaultBackend("primary")
+	dm.SetFallbackChain([]string{"secondary"})
+
+	if err := dm.SetConfidenceThreshold(1.5); err == nil {
+		t.Error("Expected error for threshold above 1")
+	}
+
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"})
+	if response.Text != "Hello there!" {
+		t.Errorf("Expected low-confidence response to fall through, got %q", response.Text)
+	}
+
+	if err := dm.SetConfidenceThreshold(0.1); err != nil {
+		t.Fatalf("SetConfidenceThreshold failed: %v", err)
+	}
+	response, _ = dm.GenerateDialog(DialogContext{Trigger: "click"})
+	if response.Text != "Uh, what?" {
+		t.Errorf("Expected primary response with relaxed threshold, got %q", response.Text)
+	}
+}
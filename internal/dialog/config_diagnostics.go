@@ -0,0 +1,378 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Diagnostic severities; only errors make a config unusable
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// minPromptTokens is the smallest prompt budget that still fits personality, situation and instructions
+const minPromptTokens = 256
+
+// providedBackends are the backend names this module implements
+var providedBackends = map[string]bool{"llm": true}
+
+// hostBackends are the backends the desktop pet host application registers itself;
+// characters may reference them without a block in backends
+var hostBackends = []string{"markov_chain", "simple_random", "news_blog"}
+
+// HostBackends returns the names of backends the host application provides
+func HostBackends() []string {
+	return append([]string(nil), hostBackends...)
+}
+
+// isHostBackend reports whether name is provided by the host application
+func isHostBackend(name string) bool {
+	for _, host := range hostBackends {
+		if name == host {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigDiagnostic is one problem found in a config, located by a JSON path such as
+// $.dialogBackend.fallbackChain[1]
+type ConfigDiagnostic struct {
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// String formats the diagnostic as "path: severity: message"
+func (d ConfigDiagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Path, d.Severity, d.Message)
+}
+
+// configDiagnostics collects diagnostics while a config is checked
+type configDiagnostics []ConfigDiagnostic
+
+func (d *configDiagnostics) errorf(path, format string, args ...interface{}) {
+	*d = append(*d, ConfigDiagnostic{Path: path, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *configDiagnostics) warnf(path, format string, args ...interface{}) {
+	*d = append(*d, ConfigDiagnostic{Path: path, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// hasErrors reports whether any diagnostic from index start on is an error
+func (d configDiagnostics) hasErrors(start int) bool {
+	for _, diagnostic := range d[start:] {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// DiagnoseConfig checks a character file, a dialogBackend config or a bare LLM backend
+// config without loading any model, returning every problem found rather than the first
+// Relative file paths (modelPath, loraPath, mockFixture, grammarFile) are resolved against
+// the working directory, and model aliases with the registry at DefaultModelRegistryPath,
+// as LLMBackend.Initialize does
+func DiagnoseConfig(data []byte) []ConfigDiagnostic {
+	var diagnostics configDiagnostics
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		diagnostics.errorf("$", "%s", describeJSONError(data, err))
+		return diagnostics
+	}
+
+	switch {
+	case fields["dialogBackend"] != nil:
+		diagnostics.checkDialogBackendConfig("$.dialogBackend", fields["dialogBackend"])
+	case fields["name"] != nil || fields["animations"] != nil || fields["dialogs"] != nil:
+		diagnostics.warnf("$.dialogBackend", "character has no dialogBackend and will only use its static dialogs")
+	case fields["backends"] != nil || fields["defaultBackend"] != nil || fields["enabled"] != nil:
+		diagnostics.checkDialogBackendConfig("$", data)
+	default:
+		diagnostics.checkLLMConfig("$", data)
+	}
+	return diagnostics
+}
+
+// checkDialogBackendConfig checks backend selection and each backend's own config
+func (d *configDiagnostics) checkDialogBackendConfig(path string, data []byte) {
+	migrated, err := MigrateDialogBackendConfig(data)
+	if err != nil {
+		d.errorf(path+".schemaVersion", "%v", err)
+		return
+	}
+
+	var config DialogBackendConfig
+	if err := json.Unmarshal(migrated, &config); err != nil {
+		d.errorf(jsonErrorPath(path, err), "%s", describeJSONError(migrated, err))
+		return
+	}
+	if !config.Enabled {
+		d.warnf(path+".enabled", "dialog backend is disabled; the character will use its static dialogs")
+	}
+
+	switch {
+	case config.DefaultBackend == "" && config.Enabled:
+		d.errorf(path+".defaultBackend", "defaultBackend is required when the dialog system is enabled")
+	case config.DefaultBackend != "" && config.Backends[config.DefaultBackend] == nil:
+		d.unconfiguredBackend(path+".defaultBackend", config.DefaultBackend, config.Backends)
+	}
+
+	seen := make(map[string]bool)
+	for i, name := range config.FallbackChain {
+		entry := fmt.Sprintf("%s.fallbackChain[%d]", path, i)
+		switch {
+		case config.Backends[name] == nil:
+			d.unconfiguredBackend(entry, name, config.Backends)
+		case name == config.DefaultBackend:
+			d.warnf(entry, "backend %q is already the defaultBackend and is never used as a fallback", name)
+		case seen[name]:
+			d.warnf(entry, "backend %q is listed more than once", name)
+		}
+		seen[name] = true
+	}
+
+	triggers := make([]string, 0, len(config.TriggerRoutes))
+	for trigger := range config.TriggerRoutes {
+		triggers = append(triggers, trigger)
+	}
+	sort.Strings(triggers)
+	for _, trigger := range triggers {
+		name := config.TriggerRoutes[trigger]
+		if config.Backends[name] == nil {
+			d.unconfiguredBackend(path+".triggerRoutes."+trigger, name, config.Backends)
+		}
+	}
+
+	switch name := config.OverloadBackend; {
+	case name == "":
+	case config.Backends[name] == nil:
+		d.unconfiguredBackend(path+".overloadBackend", name, config.Backends)
+	case name == config.DefaultBackend:
+		d.warnf(path+".overloadBackend", "backend %q is the defaultBackend and cannot stand in for itself", name)
+	}
+
+	if config.ConfidenceThreshold < 0 || config.ConfidenceThreshold > 1 {
+		d.errorf(path+".confidenceThreshold", "must be between 0 and 1, got %g", config.ConfidenceThreshold)
+	}
+	if config.ResponseTimeout < 0 {
+		d.errorf(path+".responseTimeout", "must be non-negative, got %d", config.ResponseTimeout)
+	}
+	timed := make([]string, 0, len(config.BackendTimeouts))
+	for name := range config.BackendTimeouts {
+		timed = append(timed, name)
+	}
+	sort.Strings(timed)
+	for _, name := range timed {
+		entry := path + ".backendTimeouts." + name
+		switch timeout := config.BackendTimeouts[name]; {
+		case timeout < 0:
+			d.errorf(entry, "must be non-negative, got %d", timeout)
+		case config.Backends[name] == nil:
+			d.warnf(entry, "backend %q is not configured in backends (configured: %s)", name, configuredBackendNames(config.Backends))
+		case config.ResponseTimeout > 0 && timeout >= config.ResponseTimeout && name == config.DefaultBackend && len(config.FallbackChain) > 0:
+			d.warnf(entry, "%dms leaves no part of the %dms responseTimeout for the fallback chain", timeout, config.ResponseTimeout)
+		}
+	}
+
+	names := make([]string, 0, len(config.Backends))
+	for name := range config.Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry := path + ".backends." + name
+		if !providedBackends[name] {
+			d.warnf(entry, hostBackendWarning, name)
+			continue
+		}
+		d.checkLLMConfig(entry, config.Backends[name])
+	}
+}
+
+// hostBackendWarning reminds that a backend outside this module must be registered by the host
+const hostBackendWarning = "backend %q is not provided by minilm; the host application must register it"
+
+// unconfiguredBackend reports a reference to a backend missing from backends: a host
+// backend only needs registering, any other name is an error
+func (d *configDiagnostics) unconfiguredBackend(path, name string, backends map[string]json.RawMessage) {
+	if isHostBackend(name) {
+		d.warnf(path, hostBackendWarning, name)
+		return
+	}
+	d.errorf(path, "backend %q is not configured in backends (configured: %s)", name, configuredBackendNames(backends))
+}
+
+// checkLLMConfig checks an LLM backend config: referenced files, sampling ranges and
+// whether maxTokens leaves room for a prompt in the context window
+func (d *configDiagnostics) checkLLMConfig(path string, data []byte) {
+	var config LLMConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		d.errorf(jsonErrorPath(path, err), "%s", describeJSONError(data, err))
+		return
+	}
+	start := len(*d)
+	backend := NewLLMBackend()
+	defer backend.Close()
+
+	// Check the model and settings a registry alias stands for
+	if registry, missing := backend.missingModelRegistry(config); missing {
+		d.warnf(path+".model", "model registry %s does not exist; modelPath is used for model '%s'", registry, config.Model)
+	}
+	resolved, aliasErr := backend.resolveModelAlias(config)
+	if aliasErr != nil {
+		d.errorf(path+".model", "%v", aliasErr)
+	}
+	config = resolved
+
+	switch {
+	case aliasErr != nil:
+	case config.ModelPath == "":
+		d.errorf(path+".modelPath", "modelPath or model is required")
+	case config.MockFixture != "":
+	case IsHubModelPath(config.ModelPath):
+		// Downloaded on first load; the path itself is checked with the backend's validation below
+	case !strings.HasSuffix(config.ModelPath, ".gguf"):
+		d.warnf(path+".modelPath", "%q is not a .gguf file; the mock model will be used", config.ModelPath)
+	default:
+		d.checkFile(path+".modelPath", "model file", config.ModelPath)
+		d.checkContextSize(path+".contextSize", config)
+	}
+	if config.MockFixture != "" {
+		d.checkFile(path+".mockFixture", "fixture file", config.MockFixture)
+	}
+	if config.GrammarFile != "" {
+		d.checkFile(path+".grammarFile", "grammar file", config.GrammarFile)
+	}
+	if config.LoraPath != "" && !IsHubModelPath(config.LoraPath) {
+		d.checkFile(path+".loraPath", "LoRA adapter", config.LoraPath)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"maxTokens", config.MaxTokens},
+		{"contextSize", config.ContextSize},
+		{"threads", config.Threads},
+		{"timeoutMs", config.TimeoutMs},
+		{"maxHistoryBytes", config.MaxHistoryBytes},
+		{"adaptiveQuality.targetLatencyMs", config.AdaptiveQuality.TargetLatencyMs},
+	} {
+		if field.value < 0 {
+			d.errorf(path+"."+field.name, "must be non-negative, got %d", field.value)
+		}
+	}
+	if config.Temperature < 0 {
+		d.errorf(path+".temperature", "must be non-negative, got %g", config.Temperature)
+	}
+	if config.LoraScale < 0 {
+		d.errorf(path+".loraScale", "must be non-negative, got %g", config.LoraScale)
+	}
+	if config.TopP < 0 || config.TopP > 1 {
+		d.errorf(path+".topP", "must be between 0 and 1, got %g", config.TopP)
+	}
+
+	// Compare the values Initialize will use, so omitted fields are checked as their defaults
+	maxTokens, contextSize := backend.maxTokens, backend.contextSize
+	if config.MaxTokens > 0 {
+		maxTokens = config.MaxTokens
+	}
+	if config.ContextSize > 0 {
+		contextSize = config.ContextSize
+	}
+	switch budget := contextSize - maxTokens; {
+	case budget <= 0:
+		d.errorf(path+".maxTokens", "maxTokens (%d) must be smaller than contextSize (%d)", maxTokens, contextSize)
+	case budget < minPromptTokens:
+		d.warnf(path+".maxTokens", "maxTokens (%d) leaves only %d of contextSize (%d) tokens for the prompt; at least %d are recommended",
+			maxTokens, budget, contextSize, minPromptTokens)
+	}
+
+	if len(config.MarkovConfig.TrainingData) == 0 && len(config.Personas) == 0 {
+		d.warnf(path+".markov_chain.trainingData", "no training data; responses will not reflect the character's personality")
+	}
+	if config.FallbackEnabled && len(config.MarkovConfig.FallbackPhrases) == 0 {
+		d.warnf(path+".markov_chain.fallbackPhrases", "fallbackEnabled is set but there are no fallbackPhrases")
+	}
+
+	// Run the backend's own validation for settings not covered above
+	if d.hasErrors(start) {
+		return
+	}
+	if err := backend.applyConfig(config); err != nil {
+		d.errorf(path, "%v", err)
+	}
+}
+
+// checkContextSize warns when contextSize exceeds the context the model was trained with
+func (d *configDiagnostics) checkContextSize(path string, config LLMConfig) {
+	if config.ContextSize <= 0 {
+		return
+	}
+	info, err := InspectModelFile(config.ModelPath)
+	if err != nil || info.ContextLength <= 0 || config.ContextSize <= info.ContextLength {
+		return
+	}
+	d.warnf(path, "contextSize (%d) exceeds the %d tokens the model was trained with; it will be clamped",
+		config.ContextSize, info.ContextLength)
+}
+
+// checkFile reports a referenced file that is missing or is a directory
+func (d *configDiagnostics) checkFile(path, kind, name string) {
+	info, err := os.Stat(name)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		d.errorf(path, "%s %q does not exist", kind, name)
+	case err != nil:
+		d.errorf(path, "cannot read %s %q: %v", kind, name, err)
+	case info.IsDir():
+		d.errorf(path, "%s %q is a directory", kind, name)
+	}
+}
+
+// configuredBackendNames lists backend names for error messages
+func configuredBackendNames(backends map[string]json.RawMessage) string {
+	if len(backends) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// jsonErrorPath extends path with the field a type error occurred in
+func jsonErrorPath(path string, err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return path + "." + typeErr.Field
+	}
+	return path
+}
+
+// describeJSONError explains a decoding error, adding the line and column of syntax errors
+func describeJSONError(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		before := data[:min(int(syntaxErr.Offset), len(data))]
+		line := bytes.Count(before, []byte("\n")) + 1
+		column := len(before) - bytes.LastIndexByte(before, '\n')
+		return fmt.Sprintf("invalid JSON at line %d, column %d: %v", line, column, err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("expected %s, got JSON %s", typeErr.Type, typeErr.Value)
+	}
+	return err.Error()
+}
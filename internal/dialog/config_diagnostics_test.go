@@ -0,0 +1,129 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// findDiagnostic returns the first diagnostic at path, or nil
+func findDiagnostic(diagnostics []ConfigDiagnostic, path string) *ConfigDiagnostic {
+	for i := range diagnostics {
+		if diagnostics[i].Path == path {
+			return &diagnostics[i]
+		}
+	}
+	return nil
+}
+
+func TestDiagnoseConfig_CharacterFile(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "model.gguf")
+	os.WriteFile(model, []byte("GGUF"), 0o644)
+
+	diagnostics := DiagnoseConfig([]byte(`{
+		"name": "Pet",
+		"dialogBackend": {
+			"enabled": true,
+			"defaultBackend": "llm",
+			"fallbackChain": ["markov_chain", "simple", "simple_random"],
+			"backends": {
+				"llm": {"modelPath": "` + model + `", "markov_chain": {"trainingData": ["Hi!"]}},
+				"markov_chain": {}
+			}
+		}
+	}`))
+
+	unknown := findDiagnostic(diagnostics, "$.dialogBackend.fallbackChain[1]")
+	if unknown == nil || unknown.Severity != SeverityError || !strings.Contains(unknown.Message, `"simple"`) {
+		t.Errorf("Expected an error for the unconfigured fallback, got %+v", diagnostics)
+	}
+	if external := findDiagnostic(diagnostics, "$.dialogBackend.backends.markov_chain"); external == nil || external.Severity != SeverityWarning {
+		t.Errorf("Expected a warning for a backend minilm does not provide, got %+v", diagnostics)
+	}
+	// The integrator's default chain ends in simple_random, which the host provides
+	if host := findDiagnostic(diagnostics, "$.dialogBackend.fallbackChain[2]"); host == nil || host.Severity != SeverityWarning {
+		t.Errorf("Expected a warning for the host-provided simple_random, got %+v", diagnostics)
+	}
+	if len(diagnostics) != 3 {
+		t.Errorf("Expected no other diagnostics, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnoseConfig_LLMConfig(t *testing.T) {
+	diagnostics := DiagnoseConfig([]byte(`{
+		"modelPath": "/missing/model.gguf",
+		"maxTokens": 512,
+		"contextSize": 512,
+		"topP": 1.5
+	}`))
+
+	for _, path := range []string{"$.modelPath", "$.maxTokens", "$.topP"} {
+		if diagnostic := findDiagnostic(diagnostics, path); diagnostic == nil || diagnostic.Severity != SeverityError {
+			t.Errorf("Expected an error at %s, got %+v", path, diagnostics)
+		}
+	}
+	if diagnostic := findDiagnostic(diagnostics, "$.maxTokens"); diagnostic != nil && !strings.Contains(diagnostic.Message, "contextSize (512)") {
+		t.Errorf("Expected the context size in the message, got %q", diagnostic.Message)
+	}
+
+	diagnostics = DiagnoseConfig([]byte(`{"modelPath": "mock://model", "contextSize": 300, "markov_chain": {"trainingData": ["Hi!"]}}`))
+	if diagnostic := findDiagnostic(diagnostics, "$.maxTokens"); diagnostic == nil || diagnostic.Severity != SeverityWarning {
+		t.Errorf("Expected a warning for a small prompt budget, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnoseConfig_ContextSizeOverModel(t *testing.T) {
+	path := writeGGUF(t, "short.gguf", map[string]interface{}{
+		"general.architecture": "llama",
+		"llama.context_length": uint32(1024),
+	}, 64)
+
+	diagnostics := DiagnoseConfig([]byte(`{"modelPath": "` + path + `", "contextSize": 4096, "markov_chain": {"trainingData": ["Hi!"]}}`))
+	diagnostic := findDiagnostic(diagnostics, "$.contextSize")
+	if diagnostic == nil || diagnostic.Severity != SeverityWarning || !strings.Contains(diagnostic.Message, "1024") {
+		t.Errorf("Expected a warning that contextSize exceeds the trained context, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnoseConfig_BackendValidation(t *testing.T) {
+	diagnostics := DiagnoseConfig([]byte(`{"modelPath": "mock://model", "historySelection": "newest", "markov_chain": {"trainingData": ["Hi!"]}}`))
+	if diagnostic := findDiagnostic(diagnostics, "$"); diagnostic == nil || !strings.Contains(diagnostic.Message, "historySelection") {
+		t.Errorf("Expected the backend's own validation error, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnoseConfig_OverloadBackend(t *testing.T) {
+	diagnostics := DiagnoseConfig([]byte(`{
+		"enabled": true,
+		"defaultBackend": "llm",
+		"overloadBackend": "markov",
+		"backends": {"llm": {"modelPath": "mock://model", "adaptiveQuality": {"targetLatencyMs": -1}}}
+	}`))
+
+	if diagnostic := findDiagnostic(diagnostics, "$.overloadBackend"); diagnostic == nil || diagnostic.Severity != SeverityError {
+		t.Errorf("Expected an error for the unconfigured overload backend, got %+v", diagnostics)
+	}
+	if diagnostic := findDiagnostic(diagnostics, "$.backends.llm.adaptiveQuality.targetLatencyMs"); diagnostic == nil || diagnostic.Severity != SeverityError {
+		t.Errorf("Expected an error for the negative target latency, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnoseConfig_InvalidJSON(t *testing.T) {
+	diagnostics := DiagnoseConfig([]byte("{\n  \"modelPath\": \"a.gguf\",\n  oops\n}"))
+	if len(diagnostics) != 1 || !strings.Contains(diagnostics[0].Message, "line 3") {
+		t.Errorf("Expected the syntax error's line, got %+v", diagnostics)
+	}
+
+	diagnostics = DiagnoseConfig([]byte(`{"dialogBackend": {"enabled": true, "defaultBackend": "llm", "backends": {"llm": {"maxTokens": "many"}}}}`))
+	if findDiagnostic(diagnostics, "$.dialogBackend.backends.llm.maxTokens") == nil {
+		t.Errorf("Expected the type error located at the field, got %+v", diagnostics)
+	}
+}
+
+func TestDiagnoseConfig_CharacterWithoutBackend(t *testing.T) {
+	diagnostics := DiagnoseConfig([]byte(`{"name": "Pet", "dialogs": []}`))
+	if len(diagnostics) != 1 || diagnostics[0].Path != "$.dialogBackend" || diagnostics[0].Severity != SeverityWarning {
+		t.Errorf("Expected only a warning about the missing dialogBackend, got %+v", diagnostics)
+	}
+}
@@ -0,0 +1,83 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentConfigSchemaVersion is the DialogBackendConfig structure version this package reads
+// Configs without a schemaVersion are treated as version 0 and upgraded when loaded
+const CurrentConfigSchemaVersion = 1
+
+// configMigration upgrades a raw dialogBackend object by exactly one schema version
+type configMigration func(config map[string]interface{}) error
+
+// configMigrations maps a schema version to the migration that upgrades it to the next one
+// Add an entry here whenever DialogBackendConfig changes shape and bump CurrentConfigSchemaVersion
+var configMigrations = map[int]configMigration{
+	0: migrateConfigV0ToV1,
+}
+
+// migrateConfigV0ToV1 upgrades unversioned configs, which already share the v1 structure
+func migrateConfigV0ToV1(config map[string]interface{}) error {
+	return nil
+}
+
+// MigrateDialogBackendConfig upgrades dialogBackend JSON to CurrentConfigSchemaVersion
+// Data that is already current is returned unchanged
+func MigrateDialogBackendConfig(data []byte) ([]byte, error) {
+	return migrateConfig(data, CurrentConfigSchemaVersion, configMigrations)
+}
+
+// migrateConfig applies migrations in order until the config reaches the target version
+func migrateConfig(data []byte, target int, migrations map[int]configMigration) ([]byte, error) {
+	var config map[string]interface{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse dialog backend config: %w", err)
+	}
+	if config == nil {
+		config = make(map[string]interface{}) // JSON null, e.g. "dialogBackend": null
+	}
+
+	version, err := configSchemaVersion(config)
+	if err != nil {
+		return nil, err
+	}
+	if version > target {
+		return nil, fmt.Errorf("unsupported dialog backend config schemaVersion %d (latest supported: %d)", version, target)
+	}
+	if version == target {
+		return data, nil
+	}
+
+	for ; version < target; version++ {
+		migrate, exists := migrations[version]
+		if !exists {
+			return nil, fmt.Errorf("no migration from dialog backend config schemaVersion %d", version)
+		}
+		if err := migrate(config); err != nil {
+			return nil, fmt.Errorf("failed to migrate dialog backend config from schemaVersion %d: %w", version, err)
+		}
+	}
+	config["schemaVersion"] = target
+
+	migrated, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migrated dialog backend config: %w", err)
+	}
+	return migrated, nil
+}
+
+// configSchemaVersion reads schemaVersion from a raw config (missing or null = 0)
+func configSchemaVersion(config map[string]interface{}) (int, error) {
+	value, exists := config["schemaVersion"]
+	if !exists || value == nil {
+		return 0, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok || number < 0 || number != float64(int(number)) {
+		return 0, fmt.Errorf("schemaVersion must be a non-negative integer, got %v", value)
+	}
+	return int(number), nil
+}
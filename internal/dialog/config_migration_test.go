@@ -0,0 +1,100 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoadDialogBackendConfig_MigratesUnversionedConfig(t *testing.T) {
+	config, err := LoadDialogBackendConfig([]byte(`{"enabled": true, "defaultBackend": "llm"}`))
+	if err != nil {
+		t.Fatalf("Expected unversioned config to load, got %v", err)
+	}
+	if config.SchemaVersion != CurrentConfigSchemaVersion {
+		t.Errorf("Expected schemaVersion %d, got %d", CurrentConfigSchemaVersion, config.SchemaVersion)
+	}
+}
+
+func TestLoadDialogBackendConfig_RejectsNewerSchema(t *testing.T) {
+	_, err := LoadDialogBackendConfig([]byte(`{"schemaVersion": 99, "enabled": true, "defaultBackend": "llm"}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected unsupported schemaVersion error, got %v", err)
+	}
+}
+
+func TestMigrateDialogBackendConfig_CurrentIsUnchanged(t *testing.T) {
+	data := []byte(`{"schemaVersion": 1, "defaultBackend": "llm"}`)
+	migrated, err := MigrateDialogBackendConfig(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(migrated) != string(data) {
+		t.Errorf("Expected current config to be returned unchanged, got %s", migrated)
+	}
+}
+
+func TestMigrateConfig_AppliesMigrationsInOrder(t *testing.T) {
+	migrations := map[int]configMigration{
+		0: func(config map[string]interface{}) error {
+			config["timeout"] = 500.0
+			return nil
+		},
+		1: func(config map[string]interface{}) error {
+			// Renamed field in a hypothetical v2
+			config["responseTimeout"] = config["timeout"]
+			delete(config, "timeout")
+			return nil
+		},
+	}
+
+	migrated, err := migrateConfig([]byte(`{"defaultBackend": "llm"}`), 2, migrations)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var config map[string]interface{}
+	json.Unmarshal(migrated, &config)
+	if config["responseTimeout"] != 500.0 || config["timeout"] != nil {
+		t.Errorf("Expected migrations to run in order, got %v", config)
+	}
+	if config["schemaVersion"] != 2.0 {
+		t.Errorf("Expected schemaVersion 2, got %v", config["schemaVersion"])
+	}
+}
+
+func TestMigrateConfig_Errors(t *testing.T) {
+	if _, err := migrateConfig([]byte(`{}`), 2, map[int]configMigration{}); err == nil {
+		t.Error("Expected error for missing migration step")
+	}
+	if _, err := migrateConfig([]byte(`{"schemaVersion": "one"}`), 1, configMigrations); err == nil {
+		t.Error("Expected error for non-numeric schemaVersion")
+	}
+	if _, err := migrateConfig([]byte(`{"schemaVersion": 1.5}`), 1, configMigrations); err == nil {
+		t.Error("Expected error for fractional schemaVersion")
+	}
+}
+
+func TestMigrateDialogBackendConfig_Null(t *testing.T) {
+	for _, data := range []string{`null`, `{"schemaVersion": null, "defaultBackend": "llm"}`} {
+		migrated, err := MigrateDialogBackendConfig([]byte(data))
+		if err != nil {
+			t.Fatalf("Expected %s to migrate, got %v", data, err)
+		}
+		var config map[string]interface{}
+		if err := json.Unmarshal(migrated, &config); err != nil {
+			t.Fatalf("Migrated config is not an object: %s", migrated)
+		}
+		if config["schemaVersion"] != float64(CurrentConfigSchemaVersion) {
+			t.Errorf("Expected %s to be treated as unversioned, got %s", data, migrated)
+		}
+	}
+
+	config, err := LoadDialogBackendConfig([]byte("null"))
+	if err != nil {
+		t.Fatalf("Expected a null config to load as an empty one, got %v", err)
+	}
+	if config.Enabled || config.SchemaVersion != CurrentConfigSchemaVersion {
+		t.Errorf("Expected a disabled default config, got %+v", config)
+	}
+}
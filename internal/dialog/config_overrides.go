@@ -0,0 +1,170 @@
+package dialog
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by EnvConfigOverrides
+const (
+	EnvModelPath = "MINILM_MODEL_PATH"
+	EnvThreads   = "MINILM_THREADS"
+	EnvTimeoutMs = "MINILM_TIMEOUT_MS"
+)
+
+// ConfigOverrides replaces LLM backend settings from a character file at deploy time
+// Zero values leave the JSON setting unchanged
+type ConfigOverrides struct {
+	ModelPath   string // Replaces modelPath
+	Threads     int    // Replaces threads
+	AutoThreads bool   // Without Threads, replaces threads with 0 to pick the count from the CPU topology
+	TimeoutMs   int    // Replaces timeoutMs
+}
+
+// EnvConfigOverrides reads overrides from MINILM_* environment variables through lookup,
+// usually os.LookupEnv
+func EnvConfigOverrides(lookup func(string) (string, bool)) (ConfigOverrides, error) {
+	var overrides ConfigOverrides
+	if value, ok := lookup(EnvModelPath); ok {
+		overrides.ModelPath = value
+	}
+	if value, ok := lookup(EnvThreads); ok && value != "" {
+		if err := overrides.setThreads(value); err != nil {
+			return overrides, fmt.Errorf("invalid %s: %w", EnvThreads, err)
+		}
+	}
+	if value, ok := lookup(EnvTimeoutMs); ok && value != "" {
+		timeout, err := strconv.Atoi(value)
+		if err != nil || timeout < 0 {
+			return overrides, fmt.Errorf("invalid %s: must be a non-negative number of milliseconds, got %q", EnvTimeoutMs, value)
+		}
+		overrides.TimeoutMs = timeout
+	}
+	return overrides, nil
+}
+
+// RegisterFlags adds -model-path, -threads and -timeout-ms to fs
+// The current values become the flag defaults, so flags take precedence over environment variables
+func (o *ConfigOverrides) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.ModelPath, "model-path", o.ModelPath, "Override the LLM backend modelPath (env "+EnvModelPath+")")
+	fs.Var(threadsFlag{o}, "threads", "Override the LLM backend threads, a number or \"auto\" (env "+EnvThreads+")")
+	fs.IntVar(&o.TimeoutMs, "timeout-ms", o.TimeoutMs, "Override the LLM backend timeoutMs (env "+EnvTimeoutMs+")")
+}
+
+// setThreads parses a thread count, or "auto" or 0 for AutoThreads
+func (o *ConfigOverrides) setThreads(value string) error {
+	if strings.EqualFold(value, "auto") {
+		o.Threads, o.AutoThreads = 0, true
+		return nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return fmt.Errorf("threads must be a non-negative number or \"auto\", got %q", value)
+	}
+	o.Threads, o.AutoThreads = count, count == 0
+	return nil
+}
+
+// threadsFlag is the -threads flag, setting ConfigOverrides.Threads or AutoThreads
+type threadsFlag struct {
+	overrides *ConfigOverrides
+}
+
+// String returns the flag value as set, for flag defaults
+func (f threadsFlag) String() string {
+	switch {
+	case f.overrides == nil:
+		return ""
+	case f.overrides.AutoThreads:
+		return "auto"
+	case f.overrides.Threads > 0:
+		return strconv.Itoa(f.overrides.Threads)
+	}
+	return ""
+}
+
+// Set parses a thread count or "auto"
+func (f threadsFlag) Set(value string) error {
+	return f.overrides.setThreads(value)
+}
+
+// IsZero reports whether no override is set
+func (o ConfigOverrides) IsZero() bool {
+	return o == ConfigOverrides{}
+}
+
+// Apply overlays the set overrides on a backend's JSON config object
+func (o ConfigOverrides) Apply(config json.RawMessage) (json.RawMessage, error) {
+	if o.IsZero() {
+		return config, nil
+	}
+	if o.TimeoutMs < 0 {
+		return nil, fmt.Errorf("timeoutMs override must be non-negative, got %d", o.TimeoutMs)
+	}
+	if o.Threads < 0 {
+		return nil, fmt.Errorf("threads override must be non-negative, got %d", o.Threads)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &fields); err != nil {
+			return nil, fmt.Errorf("failed to parse backend config: %w", err)
+		}
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+	}
+
+	set := func(key string, value interface{}) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s override: %w", key, err)
+		}
+		fields[key] = data
+		return nil
+	}
+	if o.ModelPath != "" {
+		if err := set("modelPath", o.ModelPath); err != nil {
+			return nil, err
+		}
+	}
+	if o.Threads > 0 || o.AutoThreads {
+		if err := set("threads", o.Threads); err != nil {
+			return nil, err
+		}
+	}
+	if o.TimeoutMs > 0 {
+		if err := set("timeoutMs", o.TimeoutMs); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal backend config: %w", err)
+	}
+	return data, nil
+}
+
+// LoadDialogBackendConfigWithOverrides loads backend configuration like LoadDialogBackendConfig,
+// then applies overrides to the "llm" backend when the config has one
+func LoadDialogBackendConfigWithOverrides(data []byte, overrides ConfigOverrides) (DialogBackendConfig, error) {
+	config, err := LoadDialogBackendConfig(data)
+	if err != nil {
+		return config, err
+	}
+
+	raw, exists := config.Backends["llm"]
+	if !exists {
+		return config, nil
+	}
+	raw, err = overrides.Apply(raw)
+	if err != nil {
+		return config, fmt.Errorf("failed to apply overrides to backend 'llm': %w", err)
+	}
+	config.Backends["llm"] = raw
+	return config, nil
+}
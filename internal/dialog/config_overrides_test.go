@@ -0,0 +1,78 @@
+package dialog
+
+import (
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestEnvConfigOverrides(t *testing.T) {
+	overrides, err := EnvConfigOverrides(envLookup(map[string]string{
+		EnvModelPath: "/models/small.gguf",
+		EnvThreads:   "auto",
+		EnvTimeoutMs: "5000",
+	}))
+	if err != nil {
+		t.Fatalf("EnvConfigOverrides failed: %v", err)
+	}
+	expected := ConfigOverrides{ModelPath: "/models/small.gguf", AutoThreads: true, TimeoutMs: 5000}
+	if overrides != expected {
+		t.Errorf("Expected %+v, got %+v", expected, overrides)
+	}
+
+	if overrides, _ := EnvConfigOverrides(envLookup(nil)); !overrides.IsZero() {
+		t.Errorf("Expected no overrides without environment variables, got %+v", overrides)
+	}
+
+	for key, value := range map[string]string{EnvThreads: "many", EnvTimeoutMs: "-1"} {
+		if _, err := EnvConfigOverrides(envLookup(map[string]string{key: value})); err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected error naming %s, got %v", key, err)
+		}
+	}
+}
+
+func TestConfigOverrides_FlagsOverrideEnv(t *testing.T) {
+	overrides := ConfigOverrides{ModelPath: "/models/env.gguf", TimeoutMs: 3000}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-threads", "6", "-timeout-ms", "4000"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	expected := ConfigOverrides{ModelPath: "/models/env.gguf", Threads: 6, TimeoutMs: 4000}
+	if overrides != expected {
+		t.Errorf("Expected flags layered over the environment, got %+v", overrides)
+	}
+}
+
+func TestLoadDialogBackendConfigWithOverrides(t *testing.T) {
+	data := []byte(`{
+		"enabled": true,
+		"defaultBackend": "llm",
+		"backends": {"llm": {"modelPath": "/models/model.gguf", "threads": 2, "temperature": 0.7}}
+	}`)
+	config, err := LoadDialogBackendConfigWithOverrides(data, ConfigOverrides{AutoThreads: true, TimeoutMs: 5000})
+	if err != nil {
+		t.Fatalf("LoadDialogBackendConfigWithOverrides failed: %v", err)
+	}
+
+	var llm LLMConfig
+	if err := json.Unmarshal(config.Backends["llm"], &llm); err != nil {
+		t.Fatalf("Failed to parse overridden config: %v", err)
+	}
+	if llm.ModelPath != "/models/model.gguf" || llm.Threads != 0 || llm.TimeoutMs != 5000 || llm.Temperature != 0.7 {
+		t.Errorf("Expected only the set overrides to replace JSON values, got %+v", llm)
+	}
+
+	config, err = LoadDialogBackendConfigWithOverrides([]byte(`{"enabled": false}`), ConfigOverrides{ModelPath: "/m.gguf"})
+	if err != nil || len(config.Backends) != 0 {
+		t.Errorf("Expected configs without an llm backend left unchanged, got %+v, %v", config.Backends, err)
+	}
+}
@@ -0,0 +1,141 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Schema names for ConfigSchema
+const (
+	SchemaLLMConfig     = "llm"           // An llm backend block, LLMConfig
+	SchemaDialogBackend = "dialogBackend" // A dialogBackend section, DialogBackendConfig
+	SchemaCharacter     = "character"     // A character.json file with its dialogBackend section
+)
+
+// schemaEnums lists the accepted values of string fields, keyed by type and JSON name
+var schemaEnums = map[string][]string{
+	"LLMConfig.historySelection": {HistorySelectionImportant, HistorySelectionRelevant},
+	"LLMConfig.responseFormat":   {ResponseFormatText, ResponseFormatJSON},
+	"LLMConfig.personaSummary":   {PersonaSummaryHeuristic, PersonaSummaryModel},
+}
+
+// schemaGenerator builds JSON Schema from Go types, collecting struct types in $defs
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// ConfigSchema returns the JSON Schema (draft 2020-12) for a config type, generated from
+// the Go structs: SchemaLLMConfig, SchemaDialogBackend or SchemaCharacter
+// Config objects reject unknown keys so editors flag typos; backends other than llm and
+// character fields outside dialogBackend are left open for the host application
+func ConfigSchema(name string) ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]interface{})}
+
+	var root map[string]interface{}
+	switch name {
+	case SchemaLLMConfig:
+		root = g.ref(reflect.TypeOf(LLMConfig{}))
+		root["title"] = "MiniLM LLM backend configuration"
+	case SchemaDialogBackend:
+		root = g.ref(reflect.TypeOf(DialogBackendConfig{}))
+		root["title"] = "MiniLM dialogBackend configuration"
+	case SchemaCharacter:
+		root = map[string]interface{}{
+			"title": "MiniLM character",
+			"type":  "object",
+			"properties": map[string]interface{}{
+				"name":          map[string]interface{}{"type": "string", "minLength": 1},
+				"description":   map[string]interface{}{"type": "string"},
+				"dialogBackend": g.ref(reflect.TypeOf(DialogBackendConfig{})),
+			},
+			"required": []string{"name"},
+		}
+	default:
+		return nil, fmt.Errorf("unknown schema %q (want %q, %q or %q)", name, SchemaLLMConfig, SchemaDialogBackend, SchemaCharacter)
+	}
+
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$defs"] = g.defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaFor returns the schema of a Go type
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(json.RawMessage(nil)) {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// ref returns a reference to the struct's schema in $defs, generating it on first use
+func (g *schemaGenerator) ref(t reflect.Type) map[string]interface{} {
+	if _, exists := g.defs[t.Name()]; !exists {
+		g.defs[t.Name()] = nil // Reserve the name so recursive types terminate
+		properties := make(map[string]interface{})
+		g.addProperties(t, t.Name(), properties)
+		g.defs[t.Name()] = map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	}
+	return map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+}
+
+// addProperties adds the struct's JSON fields, including those of embedded structs
+func (g *schemaGenerator) addProperties(t reflect.Type, typeName string, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.addProperties(field.Type, typeName, properties)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaFor(field.Type)
+		if values, ok := schemaEnums[typeName+"."+name]; ok {
+			property["enum"] = values
+		}
+		properties[name] = property
+	}
+
+	// Backend blocks are opaque JSON to DialogBackendConfig, but the llm block is ours
+	if t == reflect.TypeOf(DialogBackendConfig{}) {
+		properties["backends"] = map[string]interface{}{
+			"type":                 "object",
+			"properties":           map[string]interface{}{"llm": g.ref(reflect.TypeOf(LLMConfig{}))},
+			"additionalProperties": map[string]interface{}{"type": "object"},
+		}
+	}
+}
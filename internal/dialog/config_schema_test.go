@@ -0,0 +1,110 @@
+package dialog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// decodeSchema generates a schema and decodes it for inspection
+func decodeSchema(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+	data, err := ConfigSchema(name)
+	if err != nil {
+		t.Fatalf("ConfigSchema(%q) failed: %v", name, err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("ConfigSchema(%q) returned invalid JSON: %v", name, err)
+	}
+	return schema
+}
+
+// schemaProperties returns the properties of a $defs entry
+func schemaProperties(schema map[string]interface{}, def string) map[string]interface{} {
+	defs, _ := schema["$defs"].(map[string]interface{})
+	definition, _ := defs[def].(map[string]interface{})
+	properties, _ := definition["properties"].(map[string]interface{})
+	return properties
+}
+
+func TestConfigSchema_LLMConfig(t *testing.T) {
+	schema := decodeSchema(t, SchemaLLMConfig)
+	if schema["$ref"] != "#/$defs/LLMConfig" || schema["$schema"] == nil {
+		t.Errorf("Expected a draft 2020-12 schema referencing LLMConfig, got %v", schema)
+	}
+
+	properties := schemaProperties(schema, "LLMConfig")
+	if got := properties["modelPath"]; !reflect.DeepEqual(got, map[string]interface{}{"type": "string"}) {
+		t.Errorf("Expected modelPath to be a string, got %v", got)
+	}
+	if got := properties["markov_chain"]; !reflect.DeepEqual(got, map[string]interface{}{"$ref": "#/$defs/MarkovChainConfig"}) {
+		t.Errorf("Expected markov_chain to reference its definition, got %v", got)
+	}
+	if got := properties["threads"]; !reflect.DeepEqual(got, map[string]interface{}{"type": "integer"}) {
+		t.Errorf("Expected threads to be an integer, got %v", got)
+	}
+	if got := properties["historySelection"].(map[string]interface{})["enum"]; !reflect.DeepEqual(got, []interface{}{"important", "relevant"}) {
+		t.Errorf("Expected historySelection to list its values, got %v", got)
+	}
+	if training := schemaProperties(schema, "MarkovChainConfig")["trainingData"]; !reflect.DeepEqual(training, map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}) {
+		t.Errorf("Expected trainingData to be a string array, got %v", training)
+	}
+}
+
+func TestConfigSchema_Character(t *testing.T) {
+	schema := decodeSchema(t, SchemaCharacter)
+	if !reflect.DeepEqual(schema["required"], []interface{}{"name"}) {
+		t.Errorf("Expected name to be required, got %v", schema["required"])
+	}
+	backends := schemaProperties(schema, "DialogBackendConfig")["backends"].(map[string]interface{})
+	if llm := backends["properties"].(map[string]interface{})["llm"]; !reflect.DeepEqual(llm, map[string]interface{}{"$ref": "#/$defs/LLMConfig"}) {
+		t.Errorf("Expected backends.llm to reference LLMConfig, got %v", llm)
+	}
+
+	if _, err := ConfigSchema("markov"); err == nil {
+		t.Error("Expected an unknown schema name to be rejected")
+	}
+}
+
+// The shipped characters only use keys the schema knows, so it can reject unknown ones
+func TestConfigSchema_MatchesCharacterAssets(t *testing.T) {
+	schema := decodeSchema(t, SchemaCharacter)
+	backendKeys := schemaProperties(schema, "DialogBackendConfig")
+	llmKeys := schemaProperties(schema, "LLMConfig")
+
+	files, _ := filepath.Glob("../../assets/characters/*/character.json")
+	if len(files) == 0 {
+		t.Skip("no character assets found")
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if len(data) == 0 {
+			continue // Placeholder character without content
+		}
+		var character struct {
+			DialogBackend map[string]json.RawMessage `json:"dialogBackend"`
+		}
+		if err := json.Unmarshal(data, &character); err != nil {
+			t.Fatalf("Failed to parse %s: %v", file, err)
+		}
+		for key := range character.DialogBackend {
+			if _, ok := backendKeys[key]; !ok {
+				t.Errorf("%s: dialogBackend.%s is not in the schema", file, key)
+			}
+		}
+
+		var backends map[string]map[string]json.RawMessage
+		json.Unmarshal(character.DialogBackend["backends"], &backends)
+		for key := range backends["llm"] {
+			if _, ok := llmKeys[key]; !ok {
+				t.Errorf("%s: dialogBackend.backends.llm.%s is not in the schema", file, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Defaults ContextBuilder fills when the host leaves them unset
+const (
+	defaultContextAnimation  = "idle"
+	defaultFallbackAnimation = "talking"
+	defaultFallbackResponse  = "Hello! 👋"
+)
+
+// ContextBuilder assembles a DialogContext step by step and checks it on Build, e.g.
+// NewContext("click").WithMood(80).WithTrait("cheerful", 0.9).Build()
+// Invalid values are collected and reported together rather than failing the chain
+type ContextBuilder struct {
+	ctx  DialogContext
+	errs []error
+}
+
+// NewContext starts a DialogContext for an interaction raising trigger
+func NewContext(trigger string) *ContextBuilder {
+	return &ContextBuilder{ctx: DialogContext{Trigger: trigger}}
+}
+
+// invalid records a validation failure reported by Build
+func (b *ContextBuilder) invalid(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Errorf("%w: %s", ErrInvalidContext, fmt.Sprintf(format, args...)))
+}
+
+// WithInteractionID sets the conversation the interaction belongs to
+func (b *ContextBuilder) WithInteractionID(id string) *ContextBuilder {
+	b.ctx.InteractionID = id
+	return b
+}
+
+// WithTenant sets the tenant namespace registered with RegisterTenant
+func (b *ContextBuilder) WithTenant(tenantID string) *ContextBuilder {
+	b.ctx.TenantID = tenantID
+	return b
+}
+
+// WithTimestamp sets when the interaction happened (default: the package clock's now)
+func (b *ContextBuilder) WithTimestamp(t time.Time) *ContextBuilder {
+	b.ctx.Timestamp = t
+	return b
+}
+
+// WithMood sets the overall mood, 0-100
+func (b *ContextBuilder) WithMood(mood float64) *ContextBuilder {
+	if mood < 0 || mood > 100 {
+		b.invalid("mood must be between 0 and 100, got %g", mood)
+	}
+	b.ctx.CurrentMood = mood
+	return b
+}
+
+// WithStat sets a character stat such as "hunger" or "energy"
+func (b *ContextBuilder) WithStat(name string, value float64) *ContextBuilder {
+	if name == "" {
+		b.invalid("stat name is required")
+		return b
+	}
+	if b.ctx.CurrentStats == nil {
+		b.ctx.CurrentStats = make(map[string]float64)
+	}
+	b.ctx.CurrentStats[name] = value
+	return b
+}
+
+// WithTrait sets a personality trait strength, 0-1
+func (b *ContextBuilder) WithTrait(name string, value float64) *ContextBuilder {
+	if name == "" {
+		b.invalid("trait name is required")
+		return b
+	}
+	if value < 0 || value > 1 {
+		b.invalid("trait %q must be between 0 and 1, got %g", name, value)
+	}
+	if b.ctx.PersonalityTraits == nil {
+		b.ctx.PersonalityTraits = make(map[string]float64)
+	}
+	b.ctx.PersonalityTraits[name] = value
+	return b
+}
+
+// WithAnimation sets the animation the character is currently playing (default: "idle")
+func (b *ContextBuilder) WithAnimation(animation string) *ContextBuilder {
+	b.ctx.CurrentAnimation = animation
+	return b
+}
+
+// WithRelationship sets the current relationship stage
+func (b *ContextBuilder) WithRelationship(level string) *ContextBuilder {
+	b.ctx.RelationshipLevel = level
+	return b
+}
+
+// WithAchievement records whether an achievement is unlocked
+func (b *ContextBuilder) WithAchievement(name string, unlocked bool) *ContextBuilder {
+	if b.ctx.AchievementStatus == nil {
+		b.ctx.AchievementStatus = make(map[string]bool)
+	}
+	b.ctx.AchievementStatus[name] = unlocked
+	return b
+}
+
+// WithHistory appends recent interactions
+func (b *ContextBuilder) WithHistory(records ...InteractionRecord) *ContextBuilder {
+	b.ctx.InteractionHistory = append(b.ctx.InteractionHistory, records...)
+	return b
+}
+
+// WithUserFacts appends what the character knows about the user
+func (b *ContextBuilder) WithUserFacts(facts ...UserFact) *ContextBuilder {
+	b.ctx.UserFacts = append(b.ctx.UserFacts, facts...)
+	return b
+}
+
+// WithUserMessage sets what the user typed
+func (b *ContextBuilder) WithUserMessage(message string) *ContextBuilder {
+	b.ctx.UserMessage = message
+	return b
+}
+
+// WithLastResponse sets the previous dialog response and the turn number
+func (b *ContextBuilder) WithLastResponse(response string, turn int) *ContextBuilder {
+	if turn < 0 {
+		b.invalid("conversation turn must be non-negative, got %d", turn)
+	}
+	b.ctx.LastResponse = response
+	b.ctx.ConversationTurn = turn
+	return b
+}
+
+// WithTopic sets a current conversation topic
+func (b *ContextBuilder) WithTopic(key string, value interface{}) *ContextBuilder {
+	if b.ctx.TopicContext == nil {
+		b.ctx.TopicContext = make(map[string]interface{})
+	}
+	b.ctx.TopicContext[key] = value
+	return b
+}
+
+// WithCharacter sets the character whose isolated history a shared backend uses
+func (b *ContextBuilder) WithCharacter(characterID string) *ContextBuilder {
+	b.ctx.CharacterID = characterID
+	return b
+}
+
+// WithTimeout overrides the backend's generation time budget
+func (b *ContextBuilder) WithTimeout(timeout time.Duration) *ContextBuilder {
+	if timeout < 0 {
+		b.invalid("timeout must be non-negative, got %s", timeout)
+	}
+	b.ctx.TimeoutMs = int(timeout / time.Millisecond)
+	return b
+}
+
+// WithPriority sets PriorityInteractive or PriorityBackground
+func (b *ContextBuilder) WithPriority(priority string) *ContextBuilder {
+	if priority != "" && priority != PriorityInteractive && priority != PriorityBackground {
+		b.invalid("priority must be %q or %q, got %q", PriorityInteractive, PriorityBackground, priority)
+	}
+	b.ctx.Priority = priority
+	return b
+}
+
+// WithFallback sets the animation and responses used if every backend fails
+// (default: "talking" and a friendly greeting)
+func (b *ContextBuilder) WithFallback(animation string, responses ...string) *ContextBuilder {
+	b.ctx.FallbackAnimation = animation
+	b.ctx.FallbackResponses = append([]string(nil), responses...)
+	return b
+}
+
+// Build validates the context and fills defaults for the timestamp, current animation
+// and fallbacks; every invalid value is reported, each matching ErrInvalidContext
+func (b *ContextBuilder) Build() (DialogContext, error) {
+	errs := b.errs
+	if strings.TrimSpace(b.ctx.Trigger) == "" {
+		errs = append([]error{fmt.Errorf("%w: trigger is required", ErrInvalidContext)}, errs...)
+	}
+	if len(errs) > 0 {
+		return DialogContext{}, errors.Join(errs...)
+	}
+
+	ctx := b.ctx
+	if ctx.Timestamp.IsZero() {
+		ctx.Timestamp = currentTime()
+	}
+	if ctx.CurrentAnimation == "" {
+		ctx.CurrentAnimation = defaultContextAnimation
+	}
+	if ctx.FallbackAnimation == "" {
+		ctx.FallbackAnimation = defaultFallbackAnimation
+	}
+	if len(ctx.FallbackResponses) == 0 {
+		ctx.FallbackResponses = []string{defaultFallbackResponse}
+	}
+
+	// Copy so contexts built from one builder do not share maps or slices
+	ctx.CurrentStats = maps.Clone(ctx.CurrentStats)
+	ctx.PersonalityTraits = maps.Clone(ctx.PersonalityTraits)
+	ctx.AchievementStatus = maps.Clone(ctx.AchievementStatus)
+	ctx.TopicContext = maps.Clone(ctx.TopicContext)
+	ctx.InteractionHistory = slices.Clone(ctx.InteractionHistory)
+	ctx.UserFacts = slices.Clone(ctx.UserFacts)
+	return ctx, nil
+}
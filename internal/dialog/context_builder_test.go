@@ -0,0 +1,66 @@
+package dialog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextBuilder(t *testing.T) {
+	now := time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)
+	SetClock(NewManualClock(now))
+	defer SetClock(nil)
+
+	builder := NewContext("click").WithMood(80).WithTrait("cheerful", 0.9).WithStat("hunger", 30)
+	ctx, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if ctx.Trigger != "click" || ctx.CurrentMood != 80 || ctx.PersonalityTraits["cheerful"] != 0.9 || ctx.CurrentStats["hunger"] != 30 {
+		t.Errorf("Expected the configured values, got %+v", ctx)
+	}
+	if !ctx.Timestamp.Equal(now) || ctx.CurrentAnimation != "idle" {
+		t.Errorf("Expected the clock's time and idle animation, got %v and %q", ctx.Timestamp, ctx.CurrentAnimation)
+	}
+	if ctx.FallbackAnimation != "talking" || len(ctx.FallbackResponses) != 1 {
+		t.Errorf("Expected default fallbacks, got %q and %v", ctx.FallbackAnimation, ctx.FallbackResponses)
+	}
+
+	builder.WithTrait("cheerful", 0.1)
+	if ctx.PersonalityTraits["cheerful"] != 0.9 {
+		t.Error("Expected built contexts not to share maps with the builder")
+	}
+}
+
+func TestContextBuilder_Validation(t *testing.T) {
+	_, err := NewContext("").WithMood(150).WithTrait("shy", 2).WithPriority("urgent").Build()
+	if !errors.Is(err, ErrInvalidContext) {
+		t.Fatalf("Expected ErrInvalidContext, got %v", err)
+	}
+	for _, expected := range []string{"trigger", "mood", "shy", "priority"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to mention %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestContextBuilder_Options(t *testing.T) {
+	ctx, err := NewContext("chat").
+		WithInteractionID("user-1").
+		WithUserMessage("Hi!").
+		WithLastResponse("Hello!", 2).
+		WithTimeout(1500*time.Millisecond).
+		WithPriority(PriorityBackground).
+		WithFallback("wave", "Be right back!").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if ctx.InteractionID != "user-1" || ctx.UserMessage != "Hi!" || ctx.ConversationTurn != 2 || ctx.TimeoutMs != 1500 {
+		t.Errorf("Expected the conversation options, got %+v", ctx)
+	}
+	if ctx.FallbackAnimation != "wave" || ctx.FallbackResponses[0] != "Be right back!" {
+		t.Errorf("Expected the custom fallback, got %q and %v", ctx.FallbackAnimation, ctx.FallbackResponses)
+	}
+}
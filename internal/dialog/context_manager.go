@@ -7,11 +7,16 @@ import (
 
 // ConversationExchange represents a single turn in a conversation
 type ConversationExchange struct {
-	Timestamp       time.Time `json:"timestamp"`
-	Trigger         string    `json:"trigger"`         // User action that triggered response
-	Response        string    `json:"response"`        // Character's response
-	UserFeedback    bool      `json:"userFeedback"`    // Whether user gave positive feedback
-	EngagementScore float64   `json:"engagementScore"` // Engagement level (0-1)
+	Timestamp        time.Time `json:"timestamp"`
+	Trigger          string    `json:"trigger"`                    // User action that triggered response
+	UserMessage      string    `json:"userMessage,omitempty"`      // What the user typed or said, when they used words
+	Response         string    `json:"response"`                   // Character's response
+	Speaker          string    `json:"speaker,omitempty"`          // Character who said Response in a multi-character conversation
+	ResponseType     string    `json:"responseType,omitempty"`     // Classification of the response
+	UserFeedback     bool      `json:"userFeedback"`               // Whether user gave positive feedback
+	FeedbackReceived bool      `json:"feedbackReceived,omitempty"` // Whether any feedback was recorded
+	EngagementScore  float64   `json:"engagementScore"`            // Engagement level (0-1)
+	Importance       float64   `json:"importance,omitempty"`       // How worth remembering this exchange is (0-1)
 }
 
 // ConversationHistory tracks the recent conversation exchanges for a character
@@ -24,14 +29,22 @@ type ConversationHistory struct {
 
 // ContextManager handles conversation history and context for dialog generation
 // Maintains a rolling window of recent exchanges to provide context for LLM prompts
+// Conversations are sharded by interaction ID so different users rarely contend
 type ContextManager struct {
-	conversations    map[string]*ConversationHistory
-	maxHistory       int
-	maxConversations int           // Maximum number of concurrent conversations (0 = unlimited)
-	cleanupInterval  time.Duration // How often to run cleanup
-	retentionPeriod  time.Duration // How long to keep conversations
-	cleanupTicker    *time.Ticker
-	mu               sync.RWMutex
+	shards             [contextShardCount]contextShard
+	maxHistory         int
+	maxConversations   int           // Maximum number of concurrent conversations (0 = unlimited)
+	cleanupInterval    time.Duration // How often to run cleanup
+	retentionPeriod    time.Duration // How long to keep conversations
+	importanceHalfLife time.Duration // How quickly exchange importance decays (0 = no decay)
+	cleanupTicker      *time.Ticker
+	stopCleanup        chan struct{}
+	closeOnce          sync.Once
+	events             *EventBus      // Receives memory eviction events (optional)
+	onEviction         func(Eviction) // Called with each eviction once locks are released (optional)
+	redactor           *Redactor      // Redacts exchanges as they are recorded (optional)
+	maxBytes           int64          // Estimated memory cap for stored conversations (0 = unlimited)
+	clock              Clock          // Timestamps and ages history (nil = the package clock)
 }
 
 // NewContextManager creates a new context manager with specified history length
@@ -56,12 +69,14 @@ func NewContextManagerWithConfig(maxHistory, maxConversations int, cleanupInterv
 	}
 
 	cm := &ContextManager{
-		conversations:    make(map[string]*ConversationHistory),
-		maxHistory:       maxHistory,
-		maxConversations: maxConversations,
-		cleanupInterval:  cleanupInterval,
-		retentionPeriod:  retentionPeriod,
+		maxHistory:         maxHistory,
+		maxConversations:   maxConversations,
+		cleanupInterval:    cleanupInterval,
+		retentionPeriod:    retentionPeriod,
+		importanceHalfLife: defaultImportanceHalfLife,
+		stopCleanup:        make(chan struct{}),
 	}
+	cm.resetConversations(false)
 
 	// Start cleanup routine with configurable interval
 	cm.cleanupTicker = time.NewTicker(cleanupInterval)
@@ -70,16 +85,61 @@ func NewContextManagerWithConfig(maxHistory, maxConversations int, cleanupInterv
 	return cm
 }
 
+// SetClock replaces the clock used to timestamp, age and expire this manager's history;
+// nil restores the package clock
+func (cm *ContextManager) SetClock(clock Clock) {
+	cm.lockAll()
+	defer cm.unlockAll()
+	cm.clock = clock
+}
+
+// now returns the time from the manager's clock, or the package clock when unset
+func (cm *ContextManager) now() time.Time {
+	if cm.clock != nil {
+		return cm.clock.Now()
+	}
+	return currentTime()
+}
+
 // AddExchange records a new conversation exchange
 func (cm *ContextManager) AddExchange(interactionID, trigger, response string) {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	cm.RecordExchange(interactionID, ConversationExchange{
+		Trigger:  trigger,
+		Response: response,
+	})
+}
+
+// RecordExchange records a conversation exchange including optional metadata
+// The timestamp is set to the current time when left empty
+func (cm *ContextManager) RecordExchange(interactionID string, exchange ConversationExchange) {
+	shard := cm.lockShard(interactionID)
+	_, exists := shard.conversations[interactionID]
+	if cm.maxBytes > 0 || (!exists && cm.maxConversations > 0) {
+		// Budgets span every conversation, so enforcing them needs the whole manager
+		shard.mu.Unlock()
+		cm.lockAll()
+		cm.recordExchange(interactionID, exchange)
+		cm.unlockAll()
+		cm.flushEvents()
+		return
+	}
 
+	cm.recordExchange(interactionID, exchange)
+	var notices evictionNotices
+	cm.takeNotices(shard, &notices)
+	shard.mu.Unlock()
+	notices.deliver()
+}
+
+// recordExchange appends an exchange, creating the conversation and evicting as needed
+// Callers hold every shard's write lock, or just the conversation's when no eviction
+// beyond its own history can be needed
+func (cm *ContextManager) recordExchange(interactionID string, exchange ConversationExchange) {
 	// Get or create conversation history
-	history, exists := cm.conversations[interactionID]
+	history, exists := cm.conversation(interactionID)
 	if !exists {
 		// Check if we need to evict old conversations to stay within limits
-		if cm.maxConversations > 0 && len(cm.conversations) >= cm.maxConversations {
+		if cm.maxConversations > 0 && cm.conversationCount() >= cm.maxConversations {
 			cm.evictOldestConversation()
 		}
 
@@ -88,31 +148,32 @@ func (cm *ContextManager) AddExchange(interactionID, trigger, response string) {
 			Exchanges:     make([]ConversationExchange, 0, cm.maxHistory),
 			MaxLength:     cm.maxHistory,
 		}
-		cm.conversations[interactionID] = history
+		cm.storeConversation(interactionID, history)
 	}
 
-	// Add new exchange
-	exchange := ConversationExchange{
-		Timestamp: time.Now(),
-		Trigger:   trigger,
-		Response:  response,
+	if exchange.Timestamp.IsZero() {
+		exchange.Timestamp = cm.now()
 	}
+	exchange = cm.redactor.redactExchange(exchange)
 
 	history.Exchanges = append(history.Exchanges, exchange)
-	history.LastUpdated = time.Now()
+	history.LastUpdated = cm.now()
 
-	// Maintain rolling window by removing oldest exchanges if needed
+	// Maintain rolling window by removing the least important (then oldest) exchange
 	if len(history.Exchanges) > history.MaxLength {
-		history.Exchanges = history.Exchanges[1:]
+		victim := cm.leastImportantExchangeIndex(history.Exchanges, cm.now())
+		history.Exchanges = append(history.Exchanges[:victim], history.Exchanges[victim+1:]...)
+		cm.noteEviction(interactionID, EvictionReasonHistoryLimit, 1, false)
 	}
+	cm.enforceByteBudget(interactionID)
 }
 
 // GetHistory retrieves recent conversation history for context building
 func (cm *ContextManager) GetHistory(interactionID string, maxExchanges int) []ConversationExchange {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
+	shard := cm.rlockShard(interactionID)
+	defer shard.mu.RUnlock()
 
-	history, exists := cm.conversations[interactionID]
+	history, exists := shard.conversations[interactionID]
 	if !exists {
 		return []ConversationExchange{}
 	}
@@ -133,10 +194,10 @@ func (cm *ContextManager) GetHistory(interactionID string, maxExchanges int) []C
 
 // UpdateFeedback records user feedback for the most recent exchange
 func (cm *ContextManager) UpdateFeedback(interactionID string, positive bool, engagement float64) {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	shard := cm.lockShard(interactionID)
+	defer shard.mu.Unlock()
 
-	history, exists := cm.conversations[interactionID]
+	history, exists := shard.conversations[interactionID]
 	if !exists || len(history.Exchanges) == 0 {
 		return
 	}
@@ -144,16 +205,18 @@ func (cm *ContextManager) UpdateFeedback(interactionID string, positive bool, en
 	// Update the most recent exchange
 	lastIdx := len(history.Exchanges) - 1
 	history.Exchanges[lastIdx].UserFeedback = positive
+	history.Exchanges[lastIdx].FeedbackReceived = true
 	history.Exchanges[lastIdx].EngagementScore = engagement
-	history.LastUpdated = time.Now()
+	history.Exchanges[lastIdx].Importance = boostImportance(history.Exchanges[lastIdx].Importance, positive, engagement)
+	history.LastUpdated = cm.now()
 }
 
 // GetConversationSummary provides a summary of the conversation for prompt building
 func (cm *ContextManager) GetConversationSummary(interactionID string) ConversationSummary {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
+	shard := cm.rlockShard(interactionID)
+	defer shard.mu.RUnlock()
 
-	history, exists := cm.conversations[interactionID]
+	history, exists := shard.conversations[interactionID]
 	if !exists {
 		return ConversationSummary{
 			ExchangeCount:    0,
@@ -220,79 +283,89 @@ func (cm *ContextManager) calculateSummary(history *ConversationHistory) Convers
 
 // ClearHistory removes all conversation history for a specific interaction
 func (cm *ContextManager) ClearHistory(interactionID string) {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
-	delete(cm.conversations, interactionID)
+	shard := cm.lockShard(interactionID)
+	defer shard.mu.Unlock()
+	delete(shard.conversations, interactionID)
 }
 
 // GetActiveConversations returns the number of active conversations being tracked
 func (cm *ContextManager) GetActiveConversations() int {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
-	return len(cm.conversations)
+	cm.rlockAll()
+	defer cm.runlockAll()
+	return cm.conversationCount()
 }
 
-// evictOldestConversation removes the least recently updated conversation (LRU eviction)
+// evictOldestConversation removes the conversation closest to expiry (importance-weighted LRU)
+// Without important exchanges this is the least recently updated conversation
 // This method assumes the caller already holds the write lock
 func (cm *ContextManager) evictOldestConversation() {
-	if len(cm.conversations) == 0 {
-		return
-	}
-
+	var oldest *ConversationHistory
 	var oldestID string
 	var oldestTime time.Time
-	first := true
-
-	// Find the conversation with the oldest LastUpdated time
-	for id, history := range cm.conversations {
-		if first || history.LastUpdated.Before(oldestTime) {
-			oldestID = id
-			oldestTime = history.LastUpdated
-			first = false
+	now := cm.now()
+
+	// Find the conversation that would expire first
+	for id, history := range cm.allConversations() {
+		expiry := cm.conversationExpiry(history, now)
+		if oldest == nil || expiry.Before(oldestTime) {
+			oldest, oldestID, oldestTime = history, id, expiry
 		}
 	}
 
 	// Remove the oldest conversation
-	if oldestID != "" {
-		delete(cm.conversations, oldestID)
+	if oldest != nil {
+		cm.noteEviction(oldestID, EvictionReasonCapacity, len(oldest.Exchanges), true)
+		cm.deleteConversation(oldestID)
 	}
 }
 
 // cleanupRoutine periodically removes old conversations to prevent memory leaks
 func (cm *ContextManager) cleanupRoutine() {
-	for range cm.cleanupTicker.C {
-		cm.cleanupOldConversations()
+	for {
+		select {
+		case <-cm.cleanupTicker.C:
+			cm.cleanupOldConversations()
+		case <-cm.stopCleanup:
+			return
+		}
 	}
 }
 
 // cleanupOldConversations removes conversations that haven't been active recently
 func (cm *ContextManager) cleanupOldConversations() {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	cm.lockAll()
+	defer cm.flushEvents()
+	defer cm.unlockAll()
 
-	cutoff := time.Now().Add(-cm.retentionPeriod) // Use configurable retention period
+	now := cm.now()
 
 	// Collect IDs to delete first to avoid modifying map during iteration
+	// Retention is extended for conversations holding important exchanges
 	var toDelete []string
-	for id, history := range cm.conversations {
-		if history.LastUpdated.Before(cutoff) {
+	for id, history := range cm.allConversations() {
+		if cm.conversationExpiry(history, now).Before(now) {
 			toDelete = append(toDelete, id)
 		}
 	}
 
 	// Now safely delete the collected IDs
 	for _, id := range toDelete {
-		delete(cm.conversations, id)
+		history, _ := cm.conversation(id)
+		cm.noteEviction(id, EvictionReasonExpired, len(history.Exchanges), true)
+		cm.deleteConversation(id)
 	}
 }
 
 // Close stops the cleanup routine and releases resources
 func (cm *ContextManager) Close() {
-	if cm.cleanupTicker != nil {
-		cm.cleanupTicker.Stop()
-	}
+	cm.closeOnce.Do(func() {
+		if cm.cleanupTicker != nil {
+			cm.cleanupTicker.Stop()
+		}
+		close(cm.stopCleanup)
+	})
 
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
-	cm.conversations = nil
+	cm.lockAll()
+	defer cm.unlockAll()
+	cm.resetConversations(true)
 }
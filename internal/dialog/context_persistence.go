@@ -0,0 +1,118 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConversationExportVersion is the current format version written by Export
+// Import rejects data written by newer versions it does not understand
+const ConversationExportVersion = 1
+
+// ConversationExport is the portable JSON document produced by ContextManager.Export
+type ConversationExport struct {
+	Version      int                 `json:"version"`
+	ExportedAt   time.Time           `json:"exportedAt"`
+	Conversation ConversationHistory `json:"conversation"`
+}
+
+// Export serializes the conversation history for an interaction so hosts can persist it
+func (cm *ContextManager) Export(interactionID string) ([]byte, error) {
+	shard := cm.rlockShard(interactionID)
+	history, exists := shard.conversations[interactionID]
+	if !exists {
+		shard.mu.RUnlock()
+		return nil, fmt.Errorf("no conversation found for interaction '%s'", interactionID)
+	}
+
+	export := ConversationExport{
+		Version:      ConversationExportVersion,
+		ExportedAt:   cm.now(),
+		Conversation: copyConversationHistory(history),
+	}
+	shard.mu.RUnlock()
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversation export: %w", err)
+	}
+	return data, nil
+}
+
+// Import restores a conversation previously produced by Export
+// Replaces any existing history for the same interaction ID
+func (cm *ContextManager) Import(data []byte) error {
+	var export ConversationExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse conversation export: %w", err)
+	}
+
+	if err := validateConversationExport(export); err != nil {
+		return err
+	}
+
+	cm.lockAll()
+	defer cm.flushEvents()
+	defer cm.unlockAll()
+
+	if cm.closed() {
+		return fmt.Errorf("context manager is closed")
+	}
+
+	cm.restoreConversation(export.Conversation)
+	return nil
+}
+
+// restoreConversation adds a persisted conversation, trimmed to this manager's window
+// It replaces any existing history for the same interaction ID; callers must hold every shard's write lock
+func (cm *ContextManager) restoreConversation(history ConversationHistory) {
+	history.MaxLength = cm.maxHistory
+
+	// Keep only the most recent exchanges that fit this manager's window
+	if len(history.Exchanges) > cm.maxHistory {
+		history.Exchanges = history.Exchanges[len(history.Exchanges)-cm.maxHistory:]
+	}
+	if history.LastUpdated.IsZero() {
+		history.LastUpdated = cm.now()
+	}
+	for i, exchange := range history.Exchanges {
+		history.Exchanges[i] = cm.redactor.redactExchange(exchange)
+	}
+
+	if _, exists := cm.conversation(history.InteractionID); !exists {
+		if cm.maxConversations > 0 && cm.conversationCount() >= cm.maxConversations {
+			cm.evictOldestConversation()
+		}
+	}
+
+	cm.storeConversation(history.InteractionID, &history)
+	cm.enforceByteBudget(history.InteractionID)
+}
+
+// validateConversationExport checks the version and identity of an imported document
+func validateConversationExport(export ConversationExport) error {
+	if export.Version <= 0 {
+		return fmt.Errorf("conversation export is missing a version")
+	}
+	if export.Version > ConversationExportVersion {
+		return fmt.Errorf("unsupported conversation export version %d (max %d)", export.Version, ConversationExportVersion)
+	}
+	if export.Conversation.InteractionID == "" {
+		return fmt.Errorf("conversation export is missing an interactionId")
+	}
+	return nil
+}
+
+// copyConversationHistory returns a deep copy safe to use outside the lock
+func copyConversationHistory(history *ConversationHistory) ConversationHistory {
+	exchanges := make([]ConversationExchange, len(history.Exchanges))
+	copy(exchanges, history.Exchanges)
+
+	return ConversationHistory{
+		InteractionID: history.InteractionID,
+		Exchanges:     exchanges,
+		LastUpdated:   history.LastUpdated,
+		MaxLength:     history.MaxLength,
+	}
+}
@@ -0,0 +1,120 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestContextManager_ExportImportRoundTrip(t *testing.T) {
+	source := NewContextManager(5)
+	defer source.Close()
+
+	source.AddExchange("user-1", "click", "Hello!")
+	source.AddExchange("user-1", "feed", "Yum!")
+	source.UpdateFeedback("user-1", true, 0.8)
+
+	data, err := source.Export("user-1")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	var export ConversationExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("Export should be valid JSON: %v", err)
+	}
+	if export.Version != ConversationExportVersion {
+		t.Errorf("Expected version %d, got %d", ConversationExportVersion, export.Version)
+	}
+
+	target := NewContextManager(5)
+	defer target.Close()
+
+	if err := target.Import(data); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	history := target.GetHistory("user-1", 0)
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 imported exchanges, got %d", len(history))
+	}
+	if history[1].Response != "Yum!" || !history[1].UserFeedback || history[1].EngagementScore != 0.8 {
+		t.Errorf("Imported exchange does not match original: %+v", history[1])
+	}
+}
+
+func TestContextManager_ExportUnknownInteraction(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+
+	if _, err := cm.Export("missing"); err == nil {
+		t.Error("Expected error exporting unknown interaction")
+	}
+}
+
+func TestContextManager_ImportTrimsToMaxHistory(t *testing.T) {
+	source := NewContextManager(10)
+	defer source.Close()
+	for i := 0; i < 6; i++ {
+		source.AddExchange("user-1", "click", string(rune('a'+i)))
+	}
+	data, _ := source.Export("user-1")
+
+	target := NewContextManager(3)
+	defer target.Close()
+	if err := target.Import(data); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	history := target.GetHistory("user-1", 0)
+	if len(history) != 3 {
+		t.Fatalf("Expected history trimmed to 3, got %d", len(history))
+	}
+	if history[0].Response != "d" {
+		t.Errorf("Expected most recent exchanges to be kept, first is %q", history[0].Response)
+	}
+}
+
+func TestContextManager_ImportRejectsInvalidData(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+
+	testCases := []struct {
+		name  string
+		data  string
+		error string
+	}{
+		{"malformed", `{not json`, "failed to parse"},
+		{"missing version", `{"conversation":{"interactionId":"a"}}`, "missing a version"},
+		{"future version", `{"version":99,"conversation":{"interactionId":"a"}}`, "unsupported"},
+		{"missing id", `{"version":1,"conversation":{}}`, "interactionId"},
+	}
+
+	for _, tc := range testCases {
+		err := cm.Import([]byte(tc.data))
+		if err == nil || !strings.Contains(err.Error(), tc.error) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.error, err)
+		}
+	}
+}
+
+func TestContextManager_ImportRespectsConversationLimit(t *testing.T) {
+	source := NewContextManager(5)
+	defer source.Close()
+	source.AddExchange("imported", "click", "Hi")
+	data, _ := source.Export("imported")
+
+	target := NewContextManagerWithConfig(5, 1, 0, 0)
+	defer target.Close()
+	target.AddExchange("existing", "click", "Hello")
+
+	if err := target.Import(data); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if target.GetActiveConversations() != 1 {
+		t.Errorf("Expected conversation limit of 1 to be respected, got %d", target.GetActiveConversations())
+	}
+	if len(target.GetHistory("imported", 0)) != 1 {
+		t.Error("Expected imported conversation to be present")
+	}
+}
@@ -0,0 +1,133 @@
+package dialog
+
+import (
+	"hash/maphash"
+	"iter"
+	"sync"
+)
+
+// contextShardCount is how many independently locked shards a ContextManager spreads its
+// conversations over; a power of two so a hash picks a shard with a mask
+const contextShardCount = 32
+
+// contextShardSeed randomizes which shard each interaction ID lands in per process
+var contextShardSeed = maphash.MakeSeed()
+
+// contextShard holds the conversations whose interaction IDs hash to it, with the
+// evictions among them waiting to be delivered
+type contextShard struct {
+	mu            sync.RWMutex
+	conversations map[string]*ConversationHistory
+	evictions     evictionLog   // Eviction tallies and queued OnEviction calls
+	pendingEvents []DialogEvent // Queued under mu, published after it is released
+}
+
+// Locking: every operation on a single conversation holds just its shard's lock, so
+// different users rarely contend. Operations that span conversations, such as eviction,
+// cleanup and snapshots, lock every shard in index order. The manager's settings are
+// changed only with every shard locked, so any one shard lock is enough to read them
+
+// shardFor returns the shard holding interactionID's conversation
+func (cm *ContextManager) shardFor(interactionID string) *contextShard {
+	return &cm.shards[maphash.String(contextShardSeed, interactionID)&(contextShardCount-1)]
+}
+
+// lockShard write-locks and returns the shard holding interactionID
+func (cm *ContextManager) lockShard(interactionID string) *contextShard {
+	shard := cm.shardFor(interactionID)
+	shard.mu.Lock()
+	return shard
+}
+
+// rlockShard read-locks and returns the shard holding interactionID
+func (cm *ContextManager) rlockShard(interactionID string) *contextShard {
+	shard := cm.shardFor(interactionID)
+	shard.mu.RLock()
+	return shard
+}
+
+// lockAll write-locks every shard, excluding all other users of the manager
+func (cm *ContextManager) lockAll() {
+	for i := range cm.shards {
+		cm.shards[i].mu.Lock()
+	}
+}
+
+// unlockAll releases a lock taken by lockAll
+func (cm *ContextManager) unlockAll() {
+	for i := range cm.shards {
+		cm.shards[i].mu.Unlock()
+	}
+}
+
+// rlockAll read-locks every shard for a consistent view across conversations
+func (cm *ContextManager) rlockAll() {
+	for i := range cm.shards {
+		cm.shards[i].mu.RLock()
+	}
+}
+
+// runlockAll releases a lock taken by rlockAll
+func (cm *ContextManager) runlockAll() {
+	for i := range cm.shards {
+		cm.shards[i].mu.RUnlock()
+	}
+}
+
+// conversation looks up interactionID's conversation; callers hold its shard's lock
+func (cm *ContextManager) conversation(interactionID string) (*ConversationHistory, bool) {
+	history, exists := cm.shardFor(interactionID).conversations[interactionID]
+	return history, exists
+}
+
+// storeConversation adds or replaces interactionID's conversation
+// Callers hold its shard's write lock
+func (cm *ContextManager) storeConversation(interactionID string, history *ConversationHistory) {
+	cm.shardFor(interactionID).conversations[interactionID] = history
+}
+
+// deleteConversation removes interactionID's conversation
+// Callers hold its shard's write lock
+func (cm *ContextManager) deleteConversation(interactionID string) {
+	delete(cm.shardFor(interactionID).conversations, interactionID)
+}
+
+// allConversations yields every stored conversation
+// Callers hold every shard's lock, for writing if they change or delete what it yields
+func (cm *ContextManager) allConversations() iter.Seq2[string, *ConversationHistory] {
+	return func(yield func(string, *ConversationHistory) bool) {
+		for i := range cm.shards {
+			for id, history := range cm.shards[i].conversations {
+				if !yield(id, history) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// conversationCount returns how many conversations are stored; callers hold every shard's lock
+func (cm *ContextManager) conversationCount() int {
+	count := 0
+	for i := range cm.shards {
+		count += len(cm.shards[i].conversations)
+	}
+	return count
+}
+
+// resetConversations empties every shard, or with closed leaves them unusable
+// Callers hold every shard's write lock
+func (cm *ContextManager) resetConversations(closed bool) {
+	for i := range cm.shards {
+		if closed {
+			cm.shards[i].conversations = nil
+		} else {
+			cm.shards[i].conversations = make(map[string]*ConversationHistory)
+		}
+	}
+}
+
+// closed reports whether Close has released the conversations; callers hold every shard's lock
+func (cm *ContextManager) closed() bool {
+	return cm.shards[0].conversations == nil
+}
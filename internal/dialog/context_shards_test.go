@@ -0,0 +1,106 @@
+package dialog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestContextManager_ShardsSpreadConversations(t *testing.T) {
+	cm := NewContextManager(5)
+	t.Cleanup(cm.Close)
+
+	for i := 0; i < 200; i++ {
+		cm.AddExchange(fmt.Sprintf("user-%d", i), "click", "Hi!")
+	}
+
+	used := 0
+	for i := range cm.shards {
+		if len(cm.shards[i].conversations) > 0 {
+			used++
+		}
+	}
+	if used < contextShardCount/2 {
+		t.Errorf("Expected 200 conversations to use most of the %d shards, got %d", contextShardCount, used)
+	}
+	if count := cm.GetActiveConversations(); count != 200 {
+		t.Errorf("Expected 200 active conversations, got %d", count)
+	}
+}
+
+func TestContextManager_ConcurrentConversations(t *testing.T) {
+	cm := NewContextManager(3)
+	t.Cleanup(cm.Close)
+	var evicted atomic.Int64
+	cm.OnEviction(func(Eviction) { evicted.Add(1) })
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				id := fmt.Sprintf("user-%d-%d", worker, i%10)
+				cm.AddExchange(id, "click", "Hi!")
+				cm.UpdateFeedback(id, true, 0.5)
+				cm.GetRelevantHistory(id, DialogContext{Trigger: "click"}, 2)
+				if i%10 == 0 {
+					cm.GetAnalytics()
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if count := cm.GetActiveConversations(); count != 80 {
+		t.Errorf("Expected 80 conversations, got %d", count)
+	}
+	// Each conversation holds 5 exchanges in a window of 3
+	want := int64(80 * 2)
+	if got := cm.EvictionStats()[EvictionReasonHistoryLimit].Exchanges; got != want {
+		t.Errorf("Expected %d history-limit evictions, got %d", want, got)
+	}
+	if evicted.Load() != want {
+		t.Errorf("Expected the handler to see %d evictions, got %d", want, evicted.Load())
+	}
+}
+
+func TestContextManager_CapacityAcrossShards(t *testing.T) {
+	cm := NewContextManagerWithConfig(5, 10, 0, 0)
+	t.Cleanup(cm.Close)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 4; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				cm.AddExchange(fmt.Sprintf("user-%d-%d", worker, i), "click", "Hi!")
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if count := cm.GetActiveConversations(); count != 10 {
+		t.Errorf("Expected the limit of 10 conversations across shards, got %d", count)
+	}
+	if got := cm.EvictionStats()[EvictionReasonCapacity].Conversations; got != 90 {
+		t.Errorf("Expected 90 capacity evictions, got %d", got)
+	}
+}
+
+func BenchmarkContextManager_RecordExchangeParallel(b *testing.B) {
+	cm := NewContextManager(10)
+	b.Cleanup(cm.Close)
+
+	var workers atomic.Int64
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		id := fmt.Sprintf("user-%d", workers.Add(1))
+		for pb.Next() {
+			cm.AddExchange(id, "click", "Hi!")
+			cm.GetHistory(id, 5)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package dialog
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time for timestamps, memory decay, retention and rate limiting
+// Latency measurements always use the system clock
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock reads the wall clock
+type systemClock struct{}
+
+// Now returns the system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is a Clock that only moves when told to, for tests and session replay
+type ManualClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+// NewManualClock creates a clock stopped at start
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Package-wide randomness and clock, replaceable for deterministic runs
+var (
+	determinismMu sync.Mutex
+	randomSource        = rand.New(rand.NewSource(time.Now().UnixNano()))
+	packageClock  Clock = systemClock{}
+)
+
+// SetRandomSeed reseeds every random choice the package makes: mock model replies,
+// fallback selection, experiment assignment and retry jitter
+func SetRandomSeed(seed int64) {
+	determinismMu.Lock()
+	defer determinismMu.Unlock()
+	randomSource = rand.New(rand.NewSource(seed))
+}
+
+// SetClock replaces the package clock; nil restores the system clock
+func SetClock(clock Clock) {
+	determinismMu.Lock()
+	defer determinismMu.Unlock()
+	if clock == nil {
+		clock = systemClock{}
+	}
+	packageClock = clock
+}
+
+// currentTime returns the time from the package clock
+func currentTime() time.Time {
+	determinismMu.Lock()
+	clock := packageClock
+	determinismMu.Unlock()
+	return clock.Now()
+}
+
+// randomIntn returns a random index in [0, n) from the package source
+func randomIntn(n int) int {
+	determinismMu.Lock()
+	defer determinismMu.Unlock()
+	return randomSource.Intn(n)
+}
+
+// randomFloat64 returns a random number in [0, 1) from the package source
+func randomFloat64() float64 {
+	determinismMu.Lock()
+	defer determinismMu.Unlock()
+	return randomSource.Float64()
+}
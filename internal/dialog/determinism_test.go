@@ -0,0 +1,111 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+)
+
+// restoreDeterminism returns the package to a time-seeded source and the system clock
+func restoreDeterminism(t *testing.T) {
+	t.Cleanup(func() {
+		SetRandomSeed(time.Now().UnixNano())
+		SetClock(nil)
+	})
+}
+
+func TestSetRandomSeed_RepeatsFallbackSelection(t *testing.T) {
+	restoreDeterminism(t)
+	dm := NewDialogManager(false)
+	context := DialogContext{FallbackResponses: []string{"a", "b", "c", "d", "e"}}
+
+	sequence := func() []string {
+		SetRandomSeed(42)
+		var texts []string
+		for i := 0; i < 10; i++ {
+			texts = append(texts, dm.createFallbackResponse(context).Text)
+		}
+		return texts
+	}
+
+	first, second := sequence(), sequence()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected identical fallback sequences for the same seed, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestSetRandomSeed_RepeatsMockModelResponses(t *testing.T) {
+	restoreDeterminism(t)
+	model := NewMockLLMModel()
+	model.delay = 0
+	model.Initialize()
+
+	sequence := func() []string {
+		SetRandomSeed(7)
+		var texts []string
+		for i := 0; i < 5; i++ {
+			text, err := model.Predict("Current situation:\n- something unusual happened\n")
+			if err != nil {
+				t.Fatalf("Predict() failed: %v", err)
+			}
+			texts = append(texts, text)
+		}
+		return texts
+	}
+
+	first, second := sequence(), sequence()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected identical mock responses for the same seed, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestSetClock_TimestampsExchangesAndEvents(t *testing.T) {
+	restoreDeterminism(t)
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	SetClock(clock)
+
+	cm := NewContextManager(1)
+	defer cm.Close()
+	bus := NewEventBus()
+	cm.SetEventBus(bus)
+	recorded, _ := eventRecorder(bus, EventMemoryEvicted)
+
+	cm.RecordExchange("clock", ConversationExchange{Trigger: "click", Response: "Hi"})
+	clock.Advance(time.Minute)
+	cm.RecordExchange("clock", ConversationExchange{Trigger: "feed", Response: "Yum"})
+
+	history := cm.GetHistory("clock", 0)
+	if len(history) != 1 || !history[0].Timestamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected exchange stamped by the manual clock, got %+v", history)
+	}
+
+	if len(*recorded) != 1 || !(*recorded)[0].Timestamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected eviction event stamped by the manual clock, got %+v", *recorded)
+	}
+
+	SetClock(nil)
+	if currentTime().Before(time.Now().Add(-time.Minute)) {
+		t.Error("Expected SetClock(nil) to restore the system clock")
+	}
+}
+
+func TestManualClock_SetAndAdvance(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("Expected %v, got %v", start, clock.Now())
+	}
+	clock.Advance(90 * time.Second)
+	if expected := start.Add(90 * time.Second); !clock.Now().Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, clock.Now())
+	}
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("Expected Set to move the clock back to %v, got %v", start, clock.Now())
+	}
+}
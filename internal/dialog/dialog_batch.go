@@ -0,0 +1,76 @@
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// GenerateDialogBatch generates a response for each context, returned in the same order,
+// for pre-generating idle chatter pools or serving several characters in one pass
+// The middleware chain is built once for the whole batch, and contexts run one at a time
+// grouped by character, so a model with prompt caching evaluates each character's shared
+// personality prefix once per group rather than once per context. Contexts sharing an
+// InteractionID keep their relative order. Set Priority on the contexts to let interactive
+// requests overtake the batch
+// Like GenerateDialog, a context that fails gets the canned fallback response; the
+// returned error joins every failure, each naming its context's index
+func (dm *DialogManager) GenerateDialogBatch(contexts []DialogContext) ([]DialogResponse, error) {
+	responses := make([]DialogResponse, len(contexts))
+	if !dm.beginRequest() {
+		for i, context := range contexts {
+			responses[i] = dm.createFallbackResponse(context)
+		}
+		return responses, ErrShuttingDown
+	}
+	defer dm.inFlight.Done()
+
+	handler := dm.buildHandlerChain()
+	errs := make([]error, len(contexts))
+	for _, i := range batchOrder(contexts) {
+		response, err := dm.generateDialogWith(handler, contexts[i])
+		responses[i] = response
+		if err != nil {
+			errs[i] = fmt.Errorf("batch context %d: %w", i, err)
+		}
+	}
+	return responses, errors.Join(errs...)
+}
+
+// batchGroupKey identifies contexts whose prompts share a prefix: the same character,
+// isolated history and tenant
+func batchGroupKey(context DialogContext) string {
+	return context.Speaker + "\x00" + context.CharacterID + "\x00" + context.TenantID
+}
+
+// batchOrder returns the indexes of contexts grouped by batchGroupKey, groups in order of
+// first appearance; a context joins the group of the first context with its InteractionID,
+// so one conversation's turns are never reordered
+func batchOrder(contexts []DialogContext) []int {
+	groupRank := make(map[string]int)
+	interactionRank := make(map[string]int)
+	ranks := make([]int, len(contexts))
+	for i, context := range contexts {
+		rank, seen := interactionRank[context.InteractionID]
+		if !seen || context.InteractionID == "" {
+			key := batchGroupKey(context)
+			if rank, seen = groupRank[key]; !seen {
+				rank = len(groupRank)
+				groupRank[key] = rank
+			}
+			if context.InteractionID != "" {
+				interactionRank[context.InteractionID] = rank
+			}
+		}
+		ranks[i] = rank
+	}
+
+	order := make([]int, len(contexts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return ranks[order[a]] < ranks[order[b]]
+	})
+	return order
+}
@@ -0,0 +1,85 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDialogManager_GenerateDialogBatch(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hi!")
+	var order []string
+	dm.Use(func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			order = append(order, context.InteractionID)
+			response, err := next(context)
+			response.Text = context.InteractionID + ": " + response.Text
+			return response, err
+		}
+	})
+
+	contexts := []DialogContext{
+		{Trigger: "idle", InteractionID: "a1", Speaker: "whiskers"},
+		{Trigger: "idle", InteractionID: "b1", Speaker: "buddy"},
+		{Trigger: "idle", InteractionID: "a2", Speaker: "whiskers"},
+		{Trigger: "idle", InteractionID: "b2", Speaker: "buddy"},
+	}
+	responses, err := dm.GenerateDialogBatch(contexts)
+	if err != nil {
+		t.Fatalf("GenerateDialogBatch failed: %v", err)
+	}
+
+	for i, context := range contexts {
+		if !strings.HasPrefix(responses[i].Text, context.InteractionID+": ") {
+			t.Errorf("Expected response %d to answer %s, got %q", i, context.InteractionID, responses[i].Text)
+		}
+	}
+	if want := []string{"a1", "a2", "b1", "b2"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected contexts grouped by character as %v, got %v", want, order)
+	}
+}
+
+func TestBatchOrder_KeepsConversationOrder(t *testing.T) {
+	contexts := []DialogContext{
+		{InteractionID: "room", Speaker: "whiskers"},
+		{InteractionID: "solo", Speaker: "buddy"},
+		{InteractionID: "room", Speaker: "buddy"},
+		{InteractionID: "other", Speaker: "whiskers"},
+	}
+
+	// The second room turn stays in the first group so the conversation is not reordered
+	if got, want := batchOrder(contexts), []int{0, 2, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+}
+
+func TestDialogManager_GenerateDialogBatchErrors(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hi!")
+	dm.Use(func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			if context.Trigger == "bad" {
+				return DialogResponse{}, ErrResponseVetoed
+			}
+			return next(context)
+		}
+	})
+
+	responses, err := dm.GenerateDialogBatch([]DialogContext{
+		{Trigger: "click", InteractionID: "a"},
+		{Trigger: "bad", InteractionID: "b", FallbackResponses: []string{"Hmm?"}},
+	})
+	if !errors.Is(err, ErrResponseVetoed) || !strings.Contains(err.Error(), "batch context 1") {
+		t.Errorf("Expected the vetoed context's error with its index, got %v", err)
+	}
+	if responses[0].Text != "Hi!" || responses[1].Text != "Hmm?" {
+		t.Errorf("Expected a response and a fallback, got %q and %q", responses[0].Text, responses[1].Text)
+	}
+
+	dm.Shutdown(context.Background())
+	responses, err = dm.GenerateDialogBatch([]DialogContext{{Trigger: "click", FallbackResponses: []string{"Bye"}}})
+	if !errors.Is(err, ErrShuttingDown) || len(responses) != 1 || responses[0].Text != "Bye" {
+		t.Errorf("Expected fallbacks with ErrShuttingDown, got %+v (%v)", responses, err)
+	}
+}
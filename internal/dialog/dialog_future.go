@@ -0,0 +1,78 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// DialogFuture is a generation started by GenerateDialogAsync
+// An event loop can poll Ready each frame or select on Done, then read Result
+type DialogFuture struct {
+	manager  *DialogManager
+	context  DialogContext
+	cancel   context.CancelFunc
+	done     chan struct{}
+	once     sync.Once
+	response DialogResponse
+	err      error
+}
+
+// GenerateDialogAsync starts GenerateDialog in the background and returns at once, so a
+// GUI can fire a request on click without blocking its render thread
+// It returns ErrShuttingDown instead of a future once Shutdown has started
+func (dm *DialogManager) GenerateDialogAsync(dialogContext DialogContext) (*DialogFuture, error) {
+	if !dm.beginRequest() {
+		return nil, ErrShuttingDown
+	}
+
+	future := &DialogFuture{manager: dm, context: dialogContext, done: make(chan struct{})}
+	dialogContext.requestCtx, future.cancel = context.WithCancel(dialogContext.requestContext())
+	go func() {
+		defer dm.inFlight.Done()
+		defer future.cancel()
+		future.resolve(dm.generateDialog(dialogContext))
+	}()
+	return future, nil
+}
+
+// resolve settles the future with its first result; later ones are ignored
+func (f *DialogFuture) resolve(response DialogResponse, err error) {
+	f.once.Do(func() {
+		f.response, f.err = response, err
+		close(f.done)
+	})
+}
+
+// Done returns a channel closed once the result is ready
+func (f *DialogFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Ready reports whether Result will return without blocking
+func (f *DialogFuture) Ready() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Result waits for the response; like GenerateDialog it returns the canned fallback
+// response together with any error
+func (f *DialogFuture) Result() (DialogResponse, error) {
+	<-f.done
+	return f.response, f.err
+}
+
+// Cancel abandons the generation, stopping model work still queued or running
+// An unfinished future resolves at once with the fallback response and an error matching
+// context.Canceled; a finished one keeps its result
+func (f *DialogFuture) Cancel() {
+	f.cancel()
+	if f.Ready() {
+		return
+	}
+	f.resolve(f.manager.createFallbackResponse(f.context), fmt.Errorf("dialog generation %w", context.Canceled))
+}
@@ -0,0 +1,109 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// cancelAwareTestModel blocks each prediction until its context ends and reports why
+type cancelAwareTestModel struct {
+	*scriptedTestModel
+	started chan struct{}
+	ended   chan error
+}
+
+func (m *cancelAwareTestModel) PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error) {
+	m.started <- struct{}{}
+	<-ctx.Done()
+	m.ended <- ctx.Err()
+	return "", ctx.Err()
+}
+
+func TestDialogManager_GenerateDialogAsync(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello there!")
+
+	future, err := dm.GenerateDialogAsync(DialogContext{Trigger: "click", InteractionID: "sam"})
+	if err != nil {
+		t.Fatalf("GenerateDialogAsync failed: %v", err)
+	}
+
+	select {
+	case <-future.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the future to resolve")
+	}
+	if !future.Ready() {
+		t.Error("Expected Ready once Done is closed")
+	}
+	response, err := future.Result()
+	if err != nil || response.Text != "Hello there!" {
+		t.Errorf("Expected the generated response, got %q (%v)", response.Text, err)
+	}
+
+	// Cancelling a finished future keeps its result
+	future.Cancel()
+	if response, err = future.Result(); err != nil || response.Text != "Hello there!" {
+		t.Errorf("Expected Cancel after completion to keep the result, got %q (%v)", response.Text, err)
+	}
+}
+
+func TestDialogFuture_Cancel(t *testing.T) {
+	model := &cancelAwareTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Too late"}},
+		started:           make(chan struct{}, 1),
+		ended:             make(chan error, 1),
+	}
+	backend := newScriptedBackend(t, LLMConfig{TimeoutMs: 5000}, model.scriptedTestModel)
+	backend.model = model
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+
+	future, err := dm.GenerateDialogAsync(DialogContext{
+		Trigger:           "click",
+		InteractionID:     "sam",
+		FallbackResponses: []string{"Hmm?"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateDialogAsync failed: %v", err)
+	}
+	<-model.started
+	if future.Ready() {
+		t.Fatal("Expected the future to be pending while the model runs")
+	}
+
+	future.Cancel()
+	if !future.Ready() {
+		t.Error("Expected Cancel to resolve the future at once")
+	}
+	response, err := future.Result()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected an error matching context.Canceled, got %v", err)
+	}
+	if response.Text != "Hmm?" {
+		t.Errorf("Expected the fallback response, got %q", response.Text)
+	}
+
+	select {
+	case err := <-model.ended:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the model's context to be canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Cancel to stop the model")
+	}
+}
+
+func TestDialogManager_GenerateDialogAsyncAfterShutdown(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello!")
+	if err := dm.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	future, err := dm.GenerateDialogAsync(DialogContext{Trigger: "click"})
+	if !errors.Is(err, ErrShuttingDown) || future != nil {
+		t.Errorf("Expected ErrShuttingDown and no future, got %v and %v", future, err)
+	}
+}
@@ -0,0 +1,227 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Personality drift defaults
+const (
+	defaultMaxDrift   = 0.2 // Largest change from a trait's configured value
+	neutralTraitValue = 0.5 // Starting value of traits the context does not set
+)
+
+// PersonalityDriftExportVersion is the current format version written by PersonalityDrift.Export
+const PersonalityDriftExportVersion = 1
+
+// DriftRule nudges a trait each time an interaction matches, e.g. play triggers
+// raising "playful" or night-time chats raising "night_owl"
+type DriftRule struct {
+	Trigger   string  `json:"trigger,omitempty"`   // Matching trigger, empty for any
+	TimeOfDay string  `json:"timeOfDay,omitempty"` // Matching time of day, empty for any
+	Trait     string  `json:"trait"`
+	Step      float64 `json:"step"` // Change per matching interaction; negative steps lower the trait
+}
+
+// DriftConfig configures how interaction patterns shift personality traits
+type DriftConfig struct {
+	Rules    []DriftRule `json:"rules"`
+	MaxDrift float64     `json:"maxDrift,omitempty"` // Largest change from a trait's configured value (default: 0.2)
+}
+
+// PersonalityDrift slowly shifts each conversation's personality traits as interaction
+// patterns repeat, within MaxDrift of the traits the host passes in the context
+type PersonalityDrift struct {
+	rules    []DriftRule
+	maxDrift float64
+	drifts   map[string]*traitDrift
+	mu       sync.Mutex
+}
+
+// traitDrift is one conversation's accumulated trait changes
+type traitDrift struct {
+	offsets map[string]float64
+	updated time.Time
+}
+
+// PersonalityDriftExport is the portable JSON document produced by PersonalityDrift.Export
+type PersonalityDriftExport struct {
+	Version       int                `json:"version"`
+	InteractionID string             `json:"interactionId"`
+	Offsets       map[string]float64 `json:"offsets"` // Change applied to each trait
+	UpdatedAt     time.Time          `json:"updatedAt"`
+}
+
+// NewPersonalityDrift creates a drift tracker, applying defaults for unset values
+func NewPersonalityDrift(config DriftConfig) (*PersonalityDrift, error) {
+	if len(config.Rules) == 0 {
+		return nil, fmt.Errorf("personality drift needs at least one rule")
+	}
+	for i, rule := range config.Rules {
+		if rule.Trait == "" {
+			return nil, fmt.Errorf("drift rule %d has no trait", i)
+		}
+		if rule.Step == 0 || math.IsNaN(rule.Step) || math.Abs(rule.Step) > 1 {
+			return nil, fmt.Errorf("drift rule %d step must be non-zero and between -1 and 1, got %f", i, rule.Step)
+		}
+	}
+	if config.MaxDrift < 0 || config.MaxDrift > 1 {
+		return nil, fmt.Errorf("maxDrift must be between 0 and 1, got %f", config.MaxDrift)
+	}
+	if config.MaxDrift == 0 {
+		config.MaxDrift = defaultMaxDrift
+	}
+
+	return &PersonalityDrift{
+		rules:    append([]DriftRule(nil), config.Rules...),
+		maxDrift: config.MaxDrift,
+		drifts:   make(map[string]*traitDrift),
+	}, nil
+}
+
+// Record applies the rules matching an interaction to its conversation's drift
+// An empty TimeOfDay is taken from the context Timestamp, or the package clock
+func (d *PersonalityDrift) Record(ctx DialogContext) {
+	timeOfDay := ctx.TimeOfDay
+	if timeOfDay == "" {
+		now := ctx.Timestamp
+		if now.IsZero() {
+			now = currentTime()
+		}
+		timeOfDay = TimeOfDayAt(now)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	drift := d.drift(ctx.InteractionID)
+	for _, rule := range d.rules {
+		if (rule.Trigger != "" && rule.Trigger != ctx.Trigger) || (rule.TimeOfDay != "" && rule.TimeOfDay != timeOfDay) {
+			continue
+		}
+		offset := drift.offsets[rule.Trait] + rule.Step
+		drift.offsets[rule.Trait] = math.Max(-d.maxDrift, math.Min(d.maxDrift, offset))
+	}
+	drift.updated = currentTime()
+}
+
+// Offsets returns the change each drifted trait has accumulated in a conversation
+func (d *PersonalityDrift) Offsets(interactionID string) map[string]float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	offsets := make(map[string]float64)
+	if drift, exists := d.drifts[interactionID]; exists {
+		for trait, offset := range drift.offsets {
+			offsets[trait] = offset
+		}
+	}
+	return offsets
+}
+
+// Apply returns ctx with its conversation's drift added to PersonalityTraits, clamped to
+// 0-1; drifted traits the context does not set start from 0.5
+func (d *PersonalityDrift) Apply(ctx DialogContext) DialogContext {
+	offsets := d.Offsets(ctx.InteractionID)
+	if len(offsets) == 0 {
+		return ctx
+	}
+
+	traits := make(map[string]float64, len(ctx.PersonalityTraits)+len(offsets))
+	for trait, value := range ctx.PersonalityTraits {
+		traits[trait] = value
+	}
+	for trait, offset := range offsets {
+		base, exists := traits[trait]
+		if !exists {
+			base = neutralTraitValue
+		}
+		traits[trait] = math.Max(0, math.Min(1, base+offset))
+	}
+	ctx.PersonalityTraits = traits
+	return ctx
+}
+
+// Export serializes a conversation's drift so hosts can persist it across sessions
+func (d *PersonalityDrift) Export(interactionID string) ([]byte, error) {
+	d.mu.Lock()
+	drift, exists := d.drifts[interactionID]
+	if !exists {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("no personality drift found for interaction '%s'", interactionID)
+	}
+	export := PersonalityDriftExport{
+		Version:       PersonalityDriftExportVersion,
+		InteractionID: interactionID,
+		Offsets:       make(map[string]float64, len(drift.offsets)),
+		UpdatedAt:     drift.updated,
+	}
+	for trait, offset := range drift.offsets {
+		export.Offsets[trait] = offset
+	}
+	d.mu.Unlock()
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal personality drift export: %w", err)
+	}
+	return data, nil
+}
+
+// Import restores a conversation's drift previously produced by Export
+// Offsets are clamped to this tracker's MaxDrift
+func (d *PersonalityDrift) Import(data []byte) error {
+	var export PersonalityDriftExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse personality drift export: %w", err)
+	}
+	if export.Version <= 0 {
+		return fmt.Errorf("personality drift export is missing a version")
+	}
+	if export.Version > PersonalityDriftExportVersion {
+		return fmt.Errorf("unsupported personality drift export version %d (max %d)", export.Version, PersonalityDriftExportVersion)
+	}
+	if export.InteractionID == "" {
+		return fmt.Errorf("personality drift export is missing an interactionId")
+	}
+
+	drift := &traitDrift{offsets: make(map[string]float64, len(export.Offsets)), updated: export.UpdatedAt}
+	for trait, offset := range export.Offsets {
+		drift.offsets[trait] = math.Max(-d.maxDrift, math.Min(d.maxDrift, offset))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.drifts[export.InteractionID] = drift
+	return nil
+}
+
+// drift returns a conversation's drift, creating it when missing
+func (d *PersonalityDrift) drift(interactionID string) *traitDrift {
+	drift, exists := d.drifts[interactionID]
+	if !exists {
+		drift = &traitDrift{offsets: make(map[string]float64)}
+		d.drifts[interactionID] = drift
+	}
+	return drift
+}
+
+// wrap records each interaction and generates with the drifted traits
+func (d *PersonalityDrift) wrap(next DialogHandler) DialogHandler {
+	return func(context DialogContext) (DialogResponse, error) {
+		d.Record(context)
+		return next(d.Apply(context))
+	}
+}
+
+// SetPersonalityDrift shifts personality traits for GenerateDialog as interaction patterns
+// repeat, giving each conversation a subtly different character; nil disables it
+// Persist each conversation's drift with PersonalityDrift.Export and Import
+func (dm *DialogManager) SetPersonalityDrift(drift *PersonalityDrift) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.drift = drift
+}
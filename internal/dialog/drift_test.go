@@ -0,0 +1,148 @@
+package dialog
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestDrift(t *testing.T) *PersonalityDrift {
+	t.Helper()
+	drift, err := NewPersonalityDrift(DriftConfig{
+		Rules: []DriftRule{
+			{Trigger: "play", Trait: "playful", Step: 0.05},
+			{TimeOfDay: "night", Trait: "night_owl", Step: 0.1},
+			{Trigger: "ignore", Trait: "playful", Step: -0.05},
+		},
+		MaxDrift: 0.15,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create drift: %v", err)
+	}
+	return drift
+}
+
+func TestNewPersonalityDriftValidation(t *testing.T) {
+	invalid := []DriftConfig{
+		{},
+		{Rules: []DriftRule{{Step: 0.1}}},
+		{Rules: []DriftRule{{Trait: "playful"}}},
+		{Rules: []DriftRule{{Trait: "playful", Step: 2}}},
+		{Rules: []DriftRule{{Trait: "playful", Step: 0.1}}, MaxDrift: -0.1},
+	}
+	for _, config := range invalid {
+		if _, err := NewPersonalityDrift(config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+func TestPersonalityDrift_RecordWithinBounds(t *testing.T) {
+	drift := newTestDrift(t)
+	morning := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		drift.Record(DialogContext{Trigger: "play", InteractionID: "pet", Timestamp: morning})
+	}
+	if offset := drift.Offsets("pet")["playful"]; math.Abs(offset-0.1) > 1e-9 {
+		t.Errorf("Expected playful offset 0.1, got %f", offset)
+	}
+
+	for i := 0; i < 10; i++ {
+		drift.Record(DialogContext{Trigger: "play", InteractionID: "pet", Timestamp: morning})
+	}
+	if offset := drift.Offsets("pet")["playful"]; offset != 0.15 {
+		t.Errorf("Expected playful offset capped at maxDrift, got %f", offset)
+	}
+
+	drift.Record(DialogContext{Trigger: "click", InteractionID: "pet", TimeOfDay: "night"})
+	if offset := drift.Offsets("pet")["night_owl"]; offset != 0.1 {
+		t.Errorf("Expected night_owl offset from a night-time chat, got %f", offset)
+	}
+
+	if offsets := drift.Offsets("other"); len(offsets) != 0 {
+		t.Errorf("Expected drift kept per interaction, got %v", offsets)
+	}
+}
+
+func TestPersonalityDrift_Apply(t *testing.T) {
+	drift := newTestDrift(t)
+	for i := 0; i < 3; i++ {
+		drift.Record(DialogContext{Trigger: "play", InteractionID: "pet", TimeOfDay: "night"})
+	}
+
+	base := map[string]float64{"playful": 0.95, "shy": 0.4}
+	ctx := drift.Apply(DialogContext{InteractionID: "pet", PersonalityTraits: base})
+
+	if ctx.PersonalityTraits["playful"] != 1 {
+		t.Errorf("Expected playful clamped to 1, got %f", ctx.PersonalityTraits["playful"])
+	}
+	if ctx.PersonalityTraits["shy"] != 0.4 {
+		t.Errorf("Expected undrifted trait unchanged, got %f", ctx.PersonalityTraits["shy"])
+	}
+	if value := ctx.PersonalityTraits["night_owl"]; math.Abs(value-0.65) > 1e-9 {
+		t.Errorf("Expected missing trait to drift from 0.5, got %f", value)
+	}
+	if base["playful"] != 0.95 {
+		t.Error("Expected the host's trait map left unmodified")
+	}
+}
+
+func TestPersonalityDrift_ExportImport(t *testing.T) {
+	drift := newTestDrift(t)
+	drift.Record(DialogContext{Trigger: "play", InteractionID: "pet", TimeOfDay: "morning"})
+
+	data, err := drift.Export("pet")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if _, err := drift.Export("missing"); err == nil {
+		t.Error("Expected error exporting an unknown interaction")
+	}
+
+	restored := newTestDrift(t)
+	if err := restored.Import(data); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if offset := restored.Offsets("pet")["playful"]; offset != 0.05 {
+		t.Errorf("Expected restored playful offset 0.05, got %f", offset)
+	}
+
+	for _, bad := range []string{`not json`, `{"interactionId": "pet"}`, `{"version": 99, "interactionId": "pet"}`, `{"version": 1}`} {
+		if err := restored.Import([]byte(bad)); err == nil {
+			t.Errorf("Expected error importing %s", bad)
+		}
+	}
+
+	if err := restored.Import([]byte(`{"version": 1, "interactionId": "pet", "offsets": {"playful": 0.9}}`)); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if offset := restored.Offsets("pet")["playful"]; offset != 0.15 {
+		t.Errorf("Expected imported offset clamped to maxDrift, got %f", offset)
+	}
+}
+
+func TestDialogManager_SetPersonalityDrift(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Let's play!", "Again!")
+	drift := newTestDrift(t)
+	dm.SetPersonalityDrift(drift)
+
+	var seen DialogContext
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		seen = *context
+		return nil, nil
+	}))
+
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "pet", TimeOfDay: "morning",
+		PersonalityTraits: map[string]float64{"playful": 0.5}})
+	if math.Abs(seen.PersonalityTraits["playful"]-0.55) > 1e-9 {
+		t.Errorf("Expected the handler to see drifted traits, got %v", seen.PersonalityTraits)
+	}
+
+	dm.SetPersonalityDrift(nil)
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "pet", TimeOfDay: "morning",
+		PersonalityTraits: map[string]float64{"playful": 0.5}})
+	if seen.PersonalityTraits["playful"] != 0.5 {
+		t.Errorf("Expected no drift once disabled, got %v", seen.PersonalityTraits)
+	}
+}
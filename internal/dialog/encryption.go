@@ -0,0 +1,165 @@
+package dialog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EnvStateKey holds a hex-encoded AES key for encrypting saved dialog state
+const EnvStateKey = "MINILM_STATE_KEY"
+
+// Encrypted state format identifiers
+const (
+	EncryptedStateVersion   = 1         // Written by EncryptState
+	EncryptedStateAlgorithm = "aes-gcm" // The only supported algorithm
+)
+
+// stateAdditionalData binds ciphertexts to this format, so they cannot be passed off as other AES-GCM data
+var stateAdditionalData = []byte("minilm-state")
+
+// ErrStateEncrypted is returned when encrypted state is loaded without a key
+var ErrStateEncrypted = errors.New("dialog state is encrypted and no key was provided")
+
+// EncryptedState is the JSON envelope written by EncryptState
+// Nonce and Ciphertext are base64 encoded by encoding/json
+type EncryptedState struct {
+	Version    int    `json:"version"`
+	Algorithm  string `json:"algorithm"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ParseStateKey decodes a hex-encoded 16, 24 or 32 byte AES key, as stored in EnvStateKey
+func ParseStateKey(encoded string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("state key must be hex encoded: %w", err)
+	}
+	if _, err := newStateCipher(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// newStateCipher builds the AES-GCM cipher for a 16, 24 or 32 byte key
+func newStateCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("state key must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptState seals persisted data, such as a SaveState checkpoint or a conversation
+// Export, with AES-GCM under key, using a fresh random nonce
+func EncryptState(key, plaintext []byte) ([]byte, error) {
+	aead, err := newStateCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return sealState(aead, plaintext)
+}
+
+// DecryptState opens data written by EncryptState; a wrong key or modified data fails authentication
+func DecryptState(key, data []byte) ([]byte, error) {
+	aead, err := newStateCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return openState(aead, data)
+}
+
+// IsEncryptedState reports whether data is an EncryptState envelope
+func IsEncryptedState(data []byte) bool {
+	var envelope EncryptedState
+	return json.Unmarshal(data, &envelope) == nil && envelope.Algorithm != "" && envelope.Ciphertext != nil
+}
+
+func sealState(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data, err := json.Marshal(EncryptedState{
+		Version:    EncryptedStateVersion,
+		Algorithm:  EncryptedStateAlgorithm,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, stateAdditionalData),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal encrypted state: %w", err)
+	}
+	return data, nil
+}
+
+func openState(aead cipher.AEAD, data []byte) ([]byte, error) {
+	var envelope EncryptedState
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse encrypted state: %w", err)
+	}
+	if envelope.Version <= 0 || envelope.Version > EncryptedStateVersion {
+		return nil, fmt.Errorf("unsupported encrypted state version %d (max %d)", envelope.Version, EncryptedStateVersion)
+	}
+	if envelope.Algorithm != EncryptedStateAlgorithm {
+		return nil, fmt.Errorf("unsupported encryption algorithm %q", envelope.Algorithm)
+	}
+	if len(envelope.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted state has a %d byte nonce, expected %d", len(envelope.Nonce), aead.NonceSize())
+	}
+
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, stateAdditionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state (wrong key or corrupted data): %w", err)
+	}
+	return plaintext, nil
+}
+
+// SetStateEncryptionKey encrypts SaveState checkpoints with AES-GCM under a host-provided
+// 16, 24 or 32 byte key, and decrypts them in LoadState. A nil key turns encryption off.
+// Unencrypted checkpoints still load, so existing state is encrypted on its next save
+func (dm *DialogManager) SetStateEncryptionKey(key []byte) error {
+	var aead cipher.AEAD
+	if key != nil {
+		var err error
+		if aead, err = newStateCipher(key); err != nil {
+			return err
+		}
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.stateCipher = aead
+	return nil
+}
+
+// encryptState seals a checkpoint when a state key is set
+func (dm *DialogManager) encryptState(data []byte) ([]byte, error) {
+	dm.mu.RLock()
+	aead := dm.stateCipher
+	dm.mu.RUnlock()
+	if aead == nil {
+		return data, nil
+	}
+	return sealState(aead, data)
+}
+
+// decryptState opens an encrypted checkpoint, passing unencrypted ones through
+func (dm *DialogManager) decryptState(data []byte) ([]byte, error) {
+	if !IsEncryptedState(data) {
+		return data, nil
+	}
+	dm.mu.RLock()
+	aead := dm.stateCipher
+	dm.mu.RUnlock()
+	if aead == nil {
+		return nil, ErrStateEncrypted
+	}
+	return openState(aead, data)
+}
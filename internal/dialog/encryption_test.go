@@ -0,0 +1,87 @@
+package dialog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testStateKey = bytes.Repeat([]byte{7}, 32)
+
+func TestEncryptState_RoundTrip(t *testing.T) {
+	plaintext := []byte(`{"secret":"my cat is called Whiskers"}`)
+	data, err := EncryptState(testStateKey, plaintext)
+	if err != nil {
+		t.Fatalf("EncryptState failed: %v", err)
+	}
+	if bytes.Contains(data, []byte("Whiskers")) || !IsEncryptedState(data) {
+		t.Errorf("Expected an opaque envelope, got %s", data)
+	}
+
+	decrypted, err := DecryptState(testStateKey, data)
+	if err != nil || !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected the plaintext back, got %q, %v", decrypted, err)
+	}
+	if _, err := DecryptState(bytes.Repeat([]byte{8}, 32), data); err == nil {
+		t.Error("Expected a wrong key to fail authentication")
+	}
+	if again, _ := EncryptState(testStateKey, plaintext); bytes.Equal(again, data) {
+		t.Error("Expected a fresh nonce for every encryption")
+	}
+}
+
+func TestParseStateKey(t *testing.T) {
+	if key, err := ParseStateKey(strings.Repeat("ab", 16) + "\n"); err != nil || len(key) != 16 {
+		t.Errorf("Expected a 16 byte key, got %d bytes, %v", len(key), err)
+	}
+	if _, err := ParseStateKey("not hex"); err == nil {
+		t.Error("Expected error for a non-hex key")
+	}
+	if _, err := ParseStateKey("abcd"); err == nil {
+		t.Error("Expected error for a key of the wrong size")
+	}
+}
+
+func TestDialogManager_EncryptedState(t *testing.T) {
+	source, _ := newRateLimitTestManager(t, "Nice to meet you, Sam")
+	if err := source.SetStateEncryptionKey(testStateKey); err != nil {
+		t.Fatalf("SetStateEncryptionKey failed: %v", err)
+	}
+	source.GenerateDialog(DialogContext{Trigger: "chat", InteractionID: "pet", UserMessage: "I'm Sam"})
+	data, err := source.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	if bytes.Contains(data, []byte("Sam")) {
+		t.Errorf("Expected conversation text unreadable in the checkpoint, got %s", data)
+	}
+
+	target, _ := newRateLimitTestManager(t)
+	if err := target.LoadState(data); !errors.Is(err, ErrStateEncrypted) {
+		t.Errorf("Expected ErrStateEncrypted without a key, got %v", err)
+	}
+	target.SetStateEncryptionKey(testStateKey)
+	if err := target.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	backend, _ := target.GetBackend("llm")
+	if history := backend.(*LLMBackend).GetContextManager().GetHistory("pet", 0); len(history) != 1 {
+		t.Errorf("Expected the conversation restored, got %+v", history)
+	}
+}
+
+func TestDialogManager_EncryptedStateReadsPlaintext(t *testing.T) {
+	source, _ := newRateLimitTestManager(t, "Hello")
+	source.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet"})
+	data, _ := source.SaveState()
+
+	target, _ := newRateLimitTestManager(t)
+	target.SetStateEncryptionKey(testStateKey)
+	if err := target.LoadState(data); err != nil {
+		t.Errorf("Expected an unencrypted checkpoint to load, got %v", err)
+	}
+	if err := target.SetStateEncryptionKey([]byte("short")); err == nil {
+		t.Error("Expected error for an invalid key size")
+	}
+}
@@ -0,0 +1,263 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metadata keys set on responses chosen by an EnsembleBackend
+const (
+	MetadataEnsembleBackend    = "ensembleBackend"    // Member backend that produced the response
+	MetadataEnsembleScore      = "ensembleScore"      // Score the response won with
+	MetadataEnsembleCandidates = "ensembleCandidates" // Candidates that passed validation
+)
+
+// EnsembleConfig configures an EnsembleBackend
+type EnsembleConfig struct {
+	Backends     []string         `json:"backends"`               // Registered backends asked for candidates
+	Validation   ValidationConfig `json:"validation,omitempty"`   // Built-in validators; rejected candidates are discarded
+	RerankWeight float64          `json:"rerankWeight,omitempty"` // Share of the score given to the reranker, 0-1 (default: 0.5)
+}
+
+// EnsembleCandidate is one member backend's response, as scored by the ensemble
+type EnsembleCandidate struct {
+	Backend  string         `json:"backend"`
+	Response DialogResponse `json:"response"`
+	Latency  time.Duration  `json:"latency"`
+	Score    float64        `json:"score"` // Higher is better
+}
+
+// EnsembleBackend asks several registered backends for a response to the same context and
+// returns the best one, trading latency and compute for quality. Candidates are scored by
+// confidence, blended with a Reranker when one is set; validators discard candidates outright
+type EnsembleBackend struct {
+	manager      *DialogManager
+	backends     []string
+	validators   []ResponseValidator
+	reranker     Reranker
+	rerankWeight float64
+	initialized  bool
+	mu           sync.RWMutex
+}
+
+// NewEnsembleBackend creates an ensemble over backends registered with manager
+// Initialize it with an EnsembleConfig before registering it
+func NewEnsembleBackend(manager *DialogManager) *EnsembleBackend {
+	return &EnsembleBackend{manager: manager, rerankWeight: defaultRerankWeight}
+}
+
+// Initialize applies an EnsembleConfig; every member must already be registered
+func (e *EnsembleBackend) Initialize(config json.RawMessage) error {
+	var cfg EnsembleConfig
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		return fmt.Errorf("failed to parse ensemble config: %w", err)
+	}
+	if len(cfg.Backends) < 2 {
+		return configErrorf("backends", "an ensemble needs at least two backends, got %d", len(cfg.Backends))
+	}
+	for _, name := range cfg.Backends {
+		backend, exists := e.manager.GetBackend(name)
+		if !exists {
+			return configErrorf("backends", "ensemble backend '%s' not registered", name)
+		}
+		if backend == DialogBackend(e) {
+			return configErrorf("backends", "ensemble backend '%s' cannot include itself", name)
+		}
+	}
+	if cfg.RerankWeight < 0 || cfg.RerankWeight > 1 {
+		return configErrorf("rerankWeight", "rerankWeight must be between 0 and 1, got %g", cfg.RerankWeight)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.backends = append([]string(nil), cfg.Backends...)
+	e.validators = buildValidators(cfg.Validation)
+	if cfg.RerankWeight > 0 {
+		e.rerankWeight = cfg.RerankWeight
+	}
+	e.initialized = true
+	return nil
+}
+
+// AddValidator registers an additional validator; candidates it rejects are discarded
+func (e *EnsembleBackend) AddValidator(validator ResponseValidator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if validator != nil {
+		e.validators = append(e.validators, validator)
+	}
+}
+
+// SetReranker sets the reranker blended into candidate scores (nil = confidence only)
+func (e *EnsembleBackend) SetReranker(reranker Reranker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reranker = reranker
+}
+
+// GenerateResponse asks every member that can handle the context in parallel and returns
+// the highest-scoring candidate, tagged with the member that produced it
+func (e *EnsembleBackend) GenerateResponse(context DialogContext) (DialogResponse, error) {
+	candidates, err := e.Candidates(context)
+	if err != nil {
+		return DialogResponse{}, err
+	}
+
+	best := candidates[0]
+	response := best.Response
+	metadata := make(map[string]interface{}, len(response.Metadata)+3)
+	for key, value := range response.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataEnsembleBackend] = best.Backend
+	metadata[MetadataEnsembleScore] = best.Score
+	metadata[MetadataEnsembleCandidates] = len(candidates)
+	response.Metadata = metadata
+	return response, nil
+}
+
+// Candidates returns every member's validated response to the context, best first
+func (e *EnsembleBackend) Candidates(context DialogContext) ([]EnsembleCandidate, error) {
+	e.mu.RLock()
+	if !e.initialized {
+		e.mu.RUnlock()
+		return nil, fmt.Errorf("ensemble backend %w", ErrNotInitialized)
+	}
+	names := e.backends
+	validators := e.validators
+	reranker, weight := e.reranker, e.rerankWeight
+	e.mu.RUnlock()
+
+	results := make([]EnsembleCandidate, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		backend, exists := e.manager.GetBackend(name)
+		if !exists || !backend.CanHandle(context) {
+			errs[i] = fmt.Errorf("backend '%s' cannot handle the request", name)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			response, err := backend.GenerateResponse(context)
+			results[i] = EnsembleCandidate{Backend: name, Response: response, Latency: time.Since(start)}
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	var candidates []EnsembleCandidate
+	var responses []DialogResponse
+	for i, candidate := range results {
+		if errs[i] == nil {
+			errs[i] = validateWith(validators, context, candidate.Response.Text)
+		}
+		if errs[i] != nil {
+			continue
+		}
+		candidates = append(candidates, candidate)
+		responses = append(responses, candidate.Response)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no ensemble backend produced an acceptable response: %w", errors.Join(errs...))
+	}
+
+	for i, score := range rerankScores(reranker, context, responses, weight) {
+		candidates[i].Score = score
+	}
+
+	// Ties keep configuration order, so earlier members win
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates, nil
+}
+
+// GetBackendInfo describes the ensemble; it learns when any member does
+func (e *EnsembleBackend) GetBackendInfo() BackendInfo {
+	info := BackendInfo{
+		Name:         "ensemble",
+		Version:      "1.0.0",
+		Description:  "Returns the best of several backends' responses",
+		Capabilities: []string{CapabilityContextAware},
+	}
+	for _, backend := range e.members() {
+		if backend.GetBackendInfo().HasCapability(CapabilityLearning) {
+			info.Capabilities = append(info.Capabilities, CapabilityLearning)
+			break
+		}
+	}
+	return info
+}
+
+// CanHandle reports whether any member can handle the context
+func (e *EnsembleBackend) CanHandle(context DialogContext) bool {
+	for _, backend := range e.members() {
+		if backend.CanHandle(context) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateMemory passes feedback to the member that produced the response
+func (e *EnsembleBackend) UpdateMemory(context DialogContext, response DialogResponse, userFeedback *UserFeedback) error {
+	name, _ := response.Metadata[MetadataEnsembleBackend].(string)
+	backend, exists := e.manager.GetBackend(name)
+	if !exists || !backend.GetBackendInfo().HasCapability(CapabilityLearning) {
+		return nil
+	}
+	return backend.UpdateMemory(context, response, userFeedback)
+}
+
+// HealthCheck succeeds when at least one member is healthy
+func (e *EnsembleBackend) HealthCheck(ctx context.Context) error {
+	e.mu.RLock()
+	initialized := e.initialized
+	e.mu.RUnlock()
+	if !initialized {
+		return fmt.Errorf("ensemble backend %w", ErrNotInitialized)
+	}
+
+	var errs []error
+	for _, backend := range e.members() {
+		err := backend.HealthCheck(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("no healthy ensemble backend: %w", errors.Join(errs...))
+}
+
+// SaveState returns an empty state; members are checkpointed under their own names
+func (e *EnsembleBackend) SaveState() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+// LoadState accepts any state, since the ensemble keeps none of its own
+func (e *EnsembleBackend) LoadState(data []byte) error {
+	return nil
+}
+
+// members returns the registered member backends
+func (e *EnsembleBackend) members() []DialogBackend {
+	e.mu.RLock()
+	names := e.backends
+	e.mu.RUnlock()
+
+	backends := make([]DialogBackend, 0, len(names))
+	for _, name := range names {
+		if backend, exists := e.manager.GetBackend(name); exists {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
@@ -0,0 +1,143 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// fixedTestBackend always answers with the same response or error
+type fixedTestBackend struct {
+	response DialogResponse
+	err      error
+	feedback int
+}
+
+func (b *fixedTestBackend) Initialize(json.RawMessage) error { return nil }
+func (b *fixedTestBackend) GenerateResponse(DialogContext) (DialogResponse, error) {
+	return b.response, b.err
+}
+func (b *fixedTestBackend) GetBackendInfo() BackendInfo {
+	return BackendInfo{Name: "fixed", Capabilities: []string{CapabilityLearning}}
+}
+func (b *fixedTestBackend) CanHandle(DialogContext) bool { return true }
+func (b *fixedTestBackend) UpdateMemory(DialogContext, DialogResponse, *UserFeedback) error {
+	b.feedback++
+	return nil
+}
+func (b *fixedTestBackend) HealthCheck(context.Context) error { return b.err }
+func (b *fixedTestBackend) SaveState() ([]byte, error)        { return nil, nil }
+func (b *fixedTestBackend) LoadState([]byte) error            { return nil }
+
+// lengthReranker prefers longer responses
+type lengthReranker struct{}
+
+func (lengthReranker) Rerank(ctx DialogContext, candidates []DialogResponse) []float64 {
+	scores := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		scores[i] = min(float64(len(candidate.Text))/40, 1)
+	}
+	return scores
+}
+
+// newEnsembleTestManager registers fixed backends "a", "b" and "c" and an ensemble over them
+func newEnsembleTestManager(t *testing.T, config string, a, b, c *fixedTestBackend) (*DialogManager, *EnsembleBackend) {
+	t.Helper()
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("a", a)
+	dm.RegisterBackend("b", b)
+	dm.RegisterBackend("c", c)
+
+	ensemble := NewEnsembleBackend(dm)
+	if err := ensemble.Initialize(json.RawMessage(config)); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	dm.RegisterBackend("ensemble", ensemble)
+	dm.SetDefaultBackend("ensemble")
+	return dm, ensemble
+}
+
+func TestEnsembleBackend_PicksMostConfident(t *testing.T) {
+	a := &fixedTestBackend{response: DialogResponse{Text: "Hi.", Confidence: 0.6}}
+	b := &fixedTestBackend{response: DialogResponse{Text: "Hello there, friend!", Confidence: 0.9}}
+	c := &fixedTestBackend{err: errors.New("model crashed")}
+	dm, _ := newEnsembleTestManager(t, `{"backends": ["a", "b", "c"]}`, a, b, c)
+
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet"})
+	if err != nil {
+		t.Fatalf("GenerateDialog failed: %v", err)
+	}
+	if response.Text != "Hello there, friend!" || response.Metadata[MetadataEnsembleBackend] != "b" {
+		t.Errorf("Expected backend b's response, got %q from %v", response.Text, response.Metadata[MetadataEnsembleBackend])
+	}
+	if response.Metadata[MetadataEnsembleCandidates] != 2 {
+		t.Errorf("Expected the failed backend left out, got %v candidates", response.Metadata[MetadataEnsembleCandidates])
+	}
+
+	// Feedback reaches the member that produced the response
+	if err := dm.UpdateBackendMemory(DialogContext{InteractionID: "pet"}, response, &UserFeedback{Positive: true}); err != nil {
+		t.Fatalf("UpdateBackendMemory failed: %v", err)
+	}
+	if b.feedback != 1 || a.feedback != 0 {
+		t.Errorf("Expected feedback for backend b only, got a=%d b=%d", a.feedback, b.feedback)
+	}
+}
+
+func TestEnsembleBackend_ValidatorsAndReranker(t *testing.T) {
+	a := &fixedTestBackend{response: DialogResponse{Text: "I will not answer that.", Confidence: 0.95}}
+	b := &fixedTestBackend{response: DialogResponse{Text: "Sure!", Confidence: 0.8}}
+	c := &fixedTestBackend{response: DialogResponse{Text: "Of course, let me think about that with you.", Confidence: 0.7}}
+	_, ensemble := newEnsembleTestManager(t,
+		`{"backends": ["a", "b", "c"], "validation": {"bannedPhrases": ["will not"]}, "rerankWeight": 0.6}`, a, b, c)
+
+	candidates, err := ensemble.Candidates(DialogContext{Trigger: "click"})
+	if err != nil {
+		t.Fatalf("Candidates failed: %v", err)
+	}
+	if len(candidates) != 2 || candidates[0].Backend != "b" {
+		t.Errorf("Expected the rejected candidate dropped and confidence to decide, got %+v", candidates)
+	}
+
+	ensemble.SetReranker(lengthReranker{})
+	candidates, _ = ensemble.Candidates(DialogContext{Trigger: "click"})
+	if candidates[0].Backend != "c" {
+		t.Errorf("Expected the reranker to favour the longer response, got %+v", candidates)
+	}
+}
+
+func TestEnsembleBackend_NoAcceptableCandidate(t *testing.T) {
+	failing := errors.New("offline")
+	a := &fixedTestBackend{err: failing}
+	b := &fixedTestBackend{err: failing}
+	c := &fixedTestBackend{err: failing}
+	_, ensemble := newEnsembleTestManager(t, `{"backends": ["a", "b"]}`, a, b, c)
+
+	if _, err := ensemble.GenerateResponse(DialogContext{}); !errors.Is(err, failing) {
+		t.Errorf("Expected the member errors, got %v", err)
+	}
+	if err := ensemble.HealthCheck(context.Background()); err == nil {
+		t.Error("Expected an unhealthy ensemble when no member is healthy")
+	}
+}
+
+func TestEnsembleBackend_InvalidConfig(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("a", &fixedTestBackend{})
+	ensemble := NewEnsembleBackend(dm)
+	dm.RegisterBackend("ensemble", ensemble)
+
+	for _, config := range []string{
+		`{"backends": ["a"]}`,
+		`{"backends": ["a", "missing"]}`,
+		`{"backends": ["a", "ensemble"]}`,
+		`{"backends": ["a", "a"], "rerankWeight": 2}`,
+	} {
+		if err := ensemble.Initialize(json.RawMessage(config)); !errors.Is(err, ErrConfigInvalid) {
+			t.Errorf("Expected %s to be rejected, got %v", config, err)
+		}
+	}
+	if _, err := ensemble.GenerateResponse(DialogContext{}); !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Expected an uninitialized ensemble to fail, got %v", err)
+	}
+}
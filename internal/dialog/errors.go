@@ -0,0 +1,98 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by the dialog system
+// Callers should compare with errors.Is since returned errors are usually wrapped
+var (
+	// ErrTimeout indicates generation did not finish within its time budget
+	ErrTimeout = errors.New("dialog generation timed out")
+
+	// ErrBackendBusy indicates the model could not accept the request right now
+	ErrBackendBusy = errors.New("dialog backend busy")
+
+	// ErrShuttingDown indicates the dialog manager no longer accepts requests
+	ErrShuttingDown = errors.New("dialog manager is shutting down")
+
+	// ErrUnknownTenant indicates a request named a TenantID that was never registered
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrNotInitialized indicates a backend or model was used before Initialize succeeded
+	ErrNotInitialized = errors.New("not initialized")
+
+	// ErrModelNotFound indicates the configured model file does not exist
+	ErrModelNotFound = errors.New("model file not found")
+
+	// ErrInvalidContext indicates ContextBuilder.Build was given an invalid value
+	ErrInvalidContext = errors.New("invalid dialog context")
+
+	// ErrConfigInvalid matches every ConfigError with errors.Is
+	ErrConfigInvalid = errors.New("invalid configuration")
+)
+
+// ConfigError reports a configuration value that failed validation
+// Field is the JSON name of the offending setting; Err is the underlying cause, if any
+type ConfigError struct {
+	Field   string
+	Message string
+	Err     error
+}
+
+// Error returns the validation message
+func (e *ConfigError) Error() string {
+	return e.Message
+}
+
+// Is makes errors.Is(err, ErrConfigInvalid) true for any ConfigError
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrConfigInvalid
+}
+
+// Unwrap returns the underlying cause
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// configErrorf builds a ConfigError for field with a formatted message
+func configErrorf(field, format string, args ...interface{}) error {
+	return &ConfigError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// invalidConfig reports err as a ConfigError for field, keeping err as its cause
+func invalidConfig(field string, err error) error {
+	return &ConfigError{Field: field, Message: err.Error(), Err: err}
+}
+
+// deadlineError returns ctx's error, also matching ErrTimeout when its deadline passed
+func deadlineError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+	}
+	return ctx.Err()
+}
+
+// temporaryError is implemented by errors that know whether they are transient
+type temporaryError interface {
+	Temporary() bool
+}
+
+// IsTransientError reports whether an error is worth retrying
+// Timeouts and busy backends are transient; configuration or model loading errors are permanent
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrTimeout) || errors.Is(err, ErrBackendBusy) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var temporary temporaryError
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+)
+
+func TestLLMBackend_NotInitializedError(t *testing.T) {
+	llm := NewLLMBackend()
+	_, err := llm.GenerateResponse(DialogContext{Trigger: "click"})
+	if !errors.Is(err, ErrNotInitialized) {
+		t.Errorf("Expected ErrNotInitialized, got %v", err)
+	}
+}
+
+func TestNewLlamaModel_ModelNotFound(t *testing.T) {
+	_, err := NewLlamaModel(LlamaConfig{ModelPath: "/nonexistent/model.gguf"})
+	if !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+	if IsTransientError(err) {
+		t.Error("Expected a missing model not to be transient")
+	}
+}
+
+func TestConfigError_ReportsField(t *testing.T) {
+	testCases := []struct {
+		name  string
+		err   error
+		field string
+	}{
+		{"missing model path", NewLLMBackend().Initialize([]byte(`{}`)), "modelPath"},
+		{"bad history selection", NewLLMBackend().Initialize([]byte(`{"modelPath": "m.gguf", "historySelection": "random"}`)), "historySelection"},
+		{"bad threshold", ValidateBackendConfig(DialogBackendConfig{Enabled: true, DefaultBackend: "llm", ConfidenceThreshold: 2}), "confidenceThreshold"},
+		{"bad persona", NewLLMBackend().Initialize([]byte(`{"modelPath": "m.gguf", "personas": [{"weight": 1}]}`)), "personas"},
+	}
+
+	for _, tc := range testCases {
+		if !errors.Is(tc.err, ErrConfigInvalid) {
+			t.Errorf("%s: expected ErrConfigInvalid, got %v", tc.name, tc.err)
+			continue
+		}
+		var configErr *ConfigError
+		if !errors.As(tc.err, &configErr) || configErr.Field != tc.field {
+			t.Errorf("%s: expected ConfigError for %q, got %v", tc.name, tc.field, tc.err)
+		}
+	}
+}
+
+func TestConfigError_KeepsCause(t *testing.T) {
+	err := NewLLMBackend().Initialize([]byte(`{"modelPath": "m.gguf", "grammarFile": "/nonexistent/reply.gbnf"}`))
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Field != "grammarFile" {
+		t.Fatalf("Expected ConfigError for grammarFile, got %v", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the missing file to stay visible through errors.Is, got %v", err)
+	}
+}
+
+func TestDeadlineError_MatchesErrTimeoutOnlyForDeadlines(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-expired.Done()
+	if err := deadlineError(expired); !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout and DeadlineExceeded, got %v", err)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := deadlineError(canceled); errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected a cancellation not to be a timeout, got %v", err)
+	}
+}
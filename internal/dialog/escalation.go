@@ -0,0 +1,76 @@
+package dialog
+
+import "time"
+
+// MetadataEscalated marks a below-threshold response chosen because no backend in the
+// chain met the confidence threshold; its value is the backend that produced it
+const MetadataEscalated = "escalated"
+
+// escalation collects below-threshold responses while the fallback chain is tried
+type escalation struct {
+	threshold  float64
+	reranker   Reranker  // Blended with confidence when picking the best (nil = confidence only)
+	deadline   time.Time // When the response timeout runs out (zero = never)
+	candidates []escalationCandidate
+}
+
+// escalationCandidate is a below-threshold response and where it came from
+type escalationCandidate struct {
+	backend  string
+	fallback bool // Produced by the fallback chain rather than the default backend
+	response DialogResponse
+	latency  time.Duration
+}
+
+// accept reports whether response meets the threshold, remembering it otherwise
+func (e *escalation) accept(backend string, fallback bool, response DialogResponse, latency time.Duration) bool {
+	if response.Confidence >= e.threshold {
+		return true
+	}
+	e.candidates = append(e.candidates, escalationCandidate{backend: backend, fallback: fallback, response: response, latency: latency})
+	return false
+}
+
+// best returns the highest-scoring remembered response
+// Ties keep the earlier response, so the default backend wins over fallbacks
+func (e *escalation) best(context DialogContext) *escalationCandidate {
+	if len(e.candidates) == 0 {
+		return nil
+	}
+	responses := make([]DialogResponse, len(e.candidates))
+	for i, candidate := range e.candidates {
+		responses[i] = candidate.response
+	}
+	scores := rerankScores(e.reranker, context, responses, defaultRerankWeight)
+
+	best := 0
+	for i, score := range scores {
+		if score > scores[best] {
+			best = i
+		}
+	}
+	return &e.candidates[best]
+}
+
+// useBest returns the highest-scoring below-threshold response, if any backend produced one
+func (dm *DialogManager) useBest(context DialogContext, esc *escalation) (DialogResponse, bool) {
+	best := esc.best(context)
+	if best == nil {
+		return DialogResponse{}, false
+	}
+
+	response := best.response
+	metadata := make(map[string]interface{}, len(response.Metadata)+1)
+	for key, value := range response.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataEscalated] = best.backend
+	response.Metadata = metadata
+
+	dm.stats.record(best.backend, response)
+	dm.publishResponse(EventResponseGenerated, best.backend, context, response, best.latency)
+	if best.fallback {
+		dm.publishResponse(EventFallbackUsed, best.backend, context, response, best.latency)
+	}
+	return response, true
+}
@@ -0,0 +1,51 @@
+package dialog
+
+import "testing"
+
+// newLowConfidenceBackend returns a backend whose response scores below the default threshold
+// lower meanLogprob values score lower
+func newLowConfidenceBackend(t *testing.T, text string, meanLogprob float64) *LLMBackend {
+	t.Helper()
+	model := &statsTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{text}},
+		stats:             PredictionStats{HasLogprobs: true, MeanLogprob: meanLogprob},
+	}
+	backend := newScriptedBackend(t, LLMConfig{}, model.scriptedTestModel)
+	backend.model = model
+	return backend
+}
+
+func TestDialogManager_FallbackChainEscalatesLowConfidence(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("primary", newLowConfidenceBackend(t, "Uh, what?", -2))
+	dm.RegisterBackend("secondary", newLowConfidenceBackend(t, "Hmm, maybe?", -3))
+	dm.RegisterBackend("tertiary", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hello there!"}}))
+	dm.SetDefaultBackend("primary")
+	dm.SetFallbackChain([]string{"secondary", "tertiary"})
+
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"})
+	if response.Text != "Hello there!" {
+		t.Errorf("Expected a low-confidence fallback to escalate to the next backend, got %q", response.Text)
+	}
+}
+
+func TestDialogManager_EscalationPicksBestScoring(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("primary", newLowConfidenceBackend(t, "Uh, what?", -3))
+	dm.RegisterBackend("secondary", newLowConfidenceBackend(t, "Hmm, maybe?", -1.5))
+	dm.SetDefaultBackend("primary")
+	dm.SetFallbackChain([]string{"secondary"})
+
+	var fallbacks []string
+	dm.Events().Subscribe(func(event DialogEvent) {
+		fallbacks = append(fallbacks, event.Backend)
+	}, EventFallbackUsed)
+
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "click", FallbackResponses: []string{"Canned"}})
+	if response.Text != "Hmm, maybe?" || response.Metadata[MetadataEscalated] != "secondary" {
+		t.Errorf("Expected the best-scoring response instead of the canned fallback, got %q (%v)", response.Text, response.Metadata)
+	}
+	if len(fallbacks) != 1 || fallbacks[0] != "secondary" {
+		t.Errorf("Expected one fallback event for the chosen backend, got %v", fallbacks)
+	}
+}
@@ -0,0 +1,252 @@
+package dialog
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies a dialog lifecycle event
+type EventType string
+
+// Dialog lifecycle events
+const (
+	EventGenerationStarted   EventType = "generation_started"   // GenerateDialog accepted a request
+	EventGenerationCompleted EventType = "generation_completed" // GenerateDialog is returning a response
+	EventResponseGenerated   EventType = "response_generated"   // A backend produced an accepted response
+	EventFallbackUsed        EventType = "fallback_used"        // The default backend was bypassed
+	EventBackendError        EventType = "backend_error"        // A backend returned an error
+	EventMemoryEvicted       EventType = "memory_evicted"       // Conversation memory was dropped
+	EventSpeechError         EventType = "speech_error"         // A TextToSpeech engine failed to speak a response
+)
+
+// Eviction reasons reported with EventMemoryEvicted
+const (
+	EvictionReasonHistoryLimit = "history_limit" // An exchange was dropped to keep a conversation within maxHistory
+	EvictionReasonCapacity     = "capacity"      // A conversation was dropped to stay within maxConversations
+	EvictionReasonExpired      = "expired"       // A conversation outlived its retention period
+	EvictionReasonMemoryBudget = "memory_budget" // Memory was dropped to stay within the byte budget set by SetMaxBytes
+)
+
+// DialogEvent describes something that happened in the dialog system
+// Fields that do not apply to an event type are left at their zero value
+type DialogEvent struct {
+	Type          EventType       `json:"type"`
+	Timestamp     time.Time       `json:"timestamp"`
+	InteractionID string          `json:"interactionId,omitempty"`
+	Trigger       string          `json:"trigger,omitempty"`
+	Backend       string          `json:"backend,omitempty"`  // Backend involved, empty for the canned fallback
+	Response      *DialogResponse `json:"response,omitempty"` // Generated, fallback or completed response
+	Error         error           `json:"-"`
+	Latency       time.Duration   `json:"latency,omitempty"`
+	Reason        string          `json:"reason,omitempty"` // Eviction reason for EventMemoryEvicted
+	Count         int             `json:"count,omitempty"`  // Exchanges removed for EventMemoryEvicted
+}
+
+// EventHandler receives dialog events
+// Handlers run synchronously on the goroutine that emitted the event and should return quickly
+type EventHandler func(event DialogEvent)
+
+// EventBus dispatches dialog events to subscribers
+// A nil *EventBus is valid and discards all events
+type EventBus struct {
+	subscribers map[int]eventSubscription
+	nextID      int
+	mu          sync.RWMutex
+}
+
+// eventSubscription is a handler with an optional event type filter
+type eventSubscription struct {
+	handler EventHandler
+	types   map[EventType]bool // nil = all types
+}
+
+// NewEventBus creates an event bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]eventSubscription)}
+}
+
+// Subscribe registers a handler for the given event types, or all events when none are given
+// The returned function removes the subscription
+func (b *EventBus) Subscribe(handler EventHandler, types ...EventType) (unsubscribe func()) {
+	if b == nil || handler == nil {
+		return func() {}
+	}
+
+	subscription := eventSubscription{handler: handler}
+	if len(types) > 0 {
+		subscription.types = make(map[EventType]bool, len(types))
+		for _, eventType := range types {
+			subscription.types[eventType] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = subscription
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// SubscribeChannel delivers matching events on a buffered channel
+// Events are dropped rather than blocking when the channel is full
+// The returned function removes the subscription and closes the channel
+func (b *EventBus) SubscribeChannel(buffer int, types ...EventType) (<-chan DialogEvent, func()) {
+	events := make(chan DialogEvent, buffer)
+	var mu sync.Mutex
+	closed := false
+
+	unsubscribe := b.Subscribe(func(event DialogEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	}, types...)
+
+	return events, func() {
+		unsubscribe()
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			closed = true
+			close(events)
+		}
+	}
+}
+
+// Publish delivers an event to all matching subscribers
+// The timestamp is set to the current time when left empty
+func (b *EventBus) Publish(event DialogEvent) {
+	if b == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = currentTime()
+	}
+
+	b.mu.RLock()
+	handlers := make([]EventHandler, 0, len(b.subscribers))
+	for _, subscription := range b.subscribers {
+		if subscription.types == nil || subscription.types[event.Type] {
+			handlers = append(handlers, subscription.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// publishAll delivers a batch of events in order
+func (b *EventBus) publishAll(events []DialogEvent) {
+	for _, event := range events {
+		b.Publish(event)
+	}
+}
+
+// EventPublisher is implemented by backends that emit their own events, such as memory evictions
+// DialogManager connects registered backends to its event bus
+type EventPublisher interface {
+	SetEventBus(bus *EventBus)
+}
+
+// Events returns the manager's event bus for subscribing to lifecycle events
+func (dm *DialogManager) Events() *EventBus {
+	return dm.events
+}
+
+// SetEventBus connects the backend and its conversation memory to an event bus
+func (llm *LLMBackend) SetEventBus(bus *EventBus) {
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+
+	llm.events = bus
+	if llm.contextManager != nil {
+		llm.contextManager.SetEventBus(bus)
+	}
+	for _, cm := range llm.isolatedContextManagers() {
+		cm.SetEventBus(bus)
+	}
+}
+
+// SetEventBus publishes memory eviction events to the bus
+func (cm *ContextManager) SetEventBus(bus *EventBus) {
+	cm.lockAll()
+	defer cm.unlockAll()
+	cm.events = bus
+}
+
+// noteEviction tallies an eviction and queues its event and handler call on the conversation's
+// shard; callers hold that shard's write lock
+// conversation reports whether the whole conversation was dropped
+func (cm *ContextManager) noteEviction(interactionID, reason string, count int, conversation bool) {
+	shard := cm.shardFor(interactionID)
+	shard.evictions.record(Eviction{InteractionID: interactionID, Reason: reason, Exchanges: count, Conversation: conversation}, cm.onEviction != nil)
+	if cm.events == nil {
+		return
+	}
+	shard.pendingEvents = append(shard.pendingEvents, DialogEvent{
+		Type:          EventMemoryEvicted,
+		Timestamp:     currentTime(),
+		InteractionID: interactionID,
+		Reason:        reason,
+		Count:         count,
+	})
+}
+
+// evictionNotices are queued events and eviction handler calls, taken under the manager's
+// locks and delivered once they are released
+type evictionNotices struct {
+	bus       *EventBus
+	handler   func(Eviction)
+	events    []DialogEvent
+	evictions []Eviction
+}
+
+// takeNotices moves the shard's queued events and handler calls into notices
+// Callers hold the shard's write lock
+func (cm *ContextManager) takeNotices(shard *contextShard, notices *evictionNotices) {
+	notices.bus, notices.handler = cm.events, cm.onEviction
+	notices.events = append(notices.events, shard.pendingEvents...)
+	notices.evictions = append(notices.evictions, shard.evictions.pending...)
+	shard.pendingEvents = nil
+	shard.evictions.pending = nil
+}
+
+// deliver calls the eviction handler and publishes the events; callers must not hold any
+// shard lock so receivers can safely call back into the ContextManager
+func (n *evictionNotices) deliver() {
+	if n.handler != nil {
+		for _, eviction := range n.evictions {
+			n.handler(eviction)
+		}
+	}
+	n.bus.publishAll(n.events)
+}
+
+// flushEvents delivers the events and eviction handler calls queued on every shard;
+// callers must not hold any shard lock
+func (cm *ContextManager) flushEvents() {
+	var notices evictionNotices
+	for i := range cm.shards {
+		shard := &cm.shards[i]
+		shard.mu.Lock()
+		cm.takeNotices(shard, &notices)
+		shard.mu.Unlock()
+	}
+	notices.deliver()
+}
@@ -0,0 +1,154 @@
+package dialog
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// eventRecorder collects published events in order
+func eventRecorder(bus *EventBus, types ...EventType) (*[]DialogEvent, func()) {
+	var events []DialogEvent
+	unsubscribe := bus.Subscribe(func(event DialogEvent) {
+		events = append(events, event)
+	}, types...)
+	return &events, unsubscribe
+}
+
+func TestEventBus_SubscribeFiltersAndUnsubscribes(t *testing.T) {
+	bus := NewEventBus()
+	all, unsubscribeAll := eventRecorder(bus)
+	errorsOnly, _ := eventRecorder(bus, EventBackendError)
+
+	bus.Publish(DialogEvent{Type: EventGenerationStarted})
+	bus.Publish(DialogEvent{Type: EventBackendError})
+
+	if len(*all) != 2 {
+		t.Errorf("Expected 2 events for unfiltered subscriber, got %d", len(*all))
+	}
+	if len(*errorsOnly) != 1 || (*errorsOnly)[0].Type != EventBackendError {
+		t.Errorf("Expected only the backend error event, got %+v", *errorsOnly)
+	}
+	if (*all)[0].Timestamp.IsZero() {
+		t.Error("Expected Publish to set the timestamp")
+	}
+
+	unsubscribeAll()
+	unsubscribeAll()
+	bus.Publish(DialogEvent{Type: EventGenerationStarted})
+	if len(*all) != 2 {
+		t.Errorf("Expected no events after unsubscribe, got %d", len(*all))
+	}
+}
+
+func TestEventBus_SubscribeChannel(t *testing.T) {
+	bus := NewEventBus()
+	events, unsubscribe := bus.SubscribeChannel(1, EventFallbackUsed)
+
+	bus.Publish(DialogEvent{Type: EventFallbackUsed, Backend: "first"})
+	bus.Publish(DialogEvent{Type: EventFallbackUsed, Backend: "dropped"}) // Buffer full
+
+	select {
+	case event := <-events:
+		if event.Backend != "first" {
+			t.Errorf("Expected first event, got %q", event.Backend)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Error("Expected channel to be closed after unsubscribe")
+	}
+	bus.Publish(DialogEvent{Type: EventFallbackUsed})
+}
+
+func TestEventBus_NilIsNoop(t *testing.T) {
+	var bus *EventBus
+	bus.Publish(DialogEvent{Type: EventGenerationStarted})
+	bus.Subscribe(func(DialogEvent) {})()
+}
+
+func TestDialogManager_PublishesLifecycleEvents(t *testing.T) {
+	dm := NewDialogManager(false)
+	failing := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{errors: []error{errors.New("boom")}})
+	dm.RegisterBackend("primary", failing)
+	dm.RegisterBackend("secondary", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Backup reply"}}))
+	dm.SetDefaultBackend("primary")
+	dm.SetFallbackChain([]string{"secondary"})
+
+	events, _ := eventRecorder(dm.Events())
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "events"})
+
+	expected := []EventType{
+		EventGenerationStarted,
+		EventBackendError,
+		EventResponseGenerated,
+		EventFallbackUsed,
+		EventGenerationCompleted,
+	}
+	if len(*events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(expected), len(*events), *events)
+	}
+	for i, eventType := range expected {
+		if (*events)[i].Type != eventType {
+			t.Errorf("Event %d: expected %s, got %s", i, eventType, (*events)[i].Type)
+		}
+	}
+
+	if (*events)[1].Backend != "primary" || (*events)[1].Error == nil {
+		t.Errorf("Expected backend error from primary, got %+v", (*events)[1])
+	}
+	if (*events)[3].Backend != "secondary" {
+		t.Errorf("Expected fallback from secondary, got %q", (*events)[3].Backend)
+	}
+	completed := (*events)[4]
+	if completed.Response == nil || completed.Response.Text != "Backup reply" || completed.InteractionID != "events" {
+		t.Errorf("Expected completed event with the backup reply, got %+v", completed)
+	}
+}
+
+func TestDialogManager_PublishesMemoryEvictions(t *testing.T) {
+	dm := NewDialogManager(false)
+	backend := newScriptedBackend(t, LLMConfig{MaxHistoryLength: 2}, &scriptedTestModel{responses: []string{"Hi!"}})
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+
+	evictions, _ := eventRecorder(dm.Events(), EventMemoryEvicted)
+	for i := 0; i < 3; i++ {
+		dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "memory"})
+	}
+
+	if len(*evictions) != 1 {
+		t.Fatalf("Expected 1 eviction event, got %d", len(*evictions))
+	}
+	event := (*evictions)[0]
+	if event.Reason != EvictionReasonHistoryLimit || event.InteractionID != "memory" || event.Count != 1 {
+		t.Errorf("Unexpected eviction event: %+v", event)
+	}
+}
+
+func TestContextManager_EvictionEventsForCapacityAndExpiry(t *testing.T) {
+	cm := NewContextManagerWithConfig(5, 1, time.Hour, time.Millisecond)
+	defer cm.Close()
+
+	bus := NewEventBus()
+	cm.SetEventBus(bus)
+	evictions, _ := eventRecorder(bus, EventMemoryEvicted)
+
+	cm.AddExchange("first", "click", "Hello")
+	cm.AddExchange("second", "click", "Hello")
+	time.Sleep(5 * time.Millisecond)
+	cm.cleanupOldConversations()
+
+	if len(*evictions) != 2 {
+		t.Fatalf("Expected 2 eviction events, got %d", len(*evictions))
+	}
+	if (*evictions)[0].Reason != EvictionReasonCapacity || (*evictions)[0].InteractionID != "first" {
+		t.Errorf("Expected capacity eviction of 'first', got %+v", (*evictions)[0])
+	}
+	if (*evictions)[1].Reason != EvictionReasonExpired || (*evictions)[1].InteractionID != "second" {
+		t.Errorf("Expected expiry of 'second', got %+v", (*evictions)[1])
+	}
+}
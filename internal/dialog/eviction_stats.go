@@ -0,0 +1,81 @@
+package dialog
+
+// EvictionCounts tallies the memory a ContextManager dropped for one eviction reason
+type EvictionCounts struct {
+	Evictions     int64 `json:"evictions"`     // Times memory was dropped
+	Conversations int64 `json:"conversations"` // Whole conversations dropped
+	Exchanges     int64 `json:"exchanges"`     // Exchanges lost, including those of dropped conversations
+}
+
+// Eviction describes memory a ContextManager dropped, as passed to an OnEviction handler
+type Eviction struct {
+	InteractionID string
+	Reason        string // One of the EvictionReason constants
+	Exchanges     int    // Exchanges lost
+	Conversation  bool   // Whether the whole conversation was dropped
+}
+
+// evictionLog keeps eviction tallies and the OnEviction handler calls queued for them
+type evictionLog struct {
+	counts  map[string]EvictionCounts
+	pending []Eviction
+}
+
+// record tallies an eviction, queueing it for the handler when queue is set
+func (l *evictionLog) record(eviction Eviction, queue bool) {
+	if l.counts == nil {
+		l.counts = make(map[string]EvictionCounts)
+	}
+	counts := l.counts[eviction.Reason]
+	counts.Evictions++
+	counts.Exchanges += int64(eviction.Exchanges)
+	if eviction.Conversation {
+		counts.Conversations++
+	}
+	l.counts[eviction.Reason] = counts
+
+	if queue {
+		l.pending = append(l.pending, eviction)
+	}
+}
+
+// OnEviction calls handler whenever conversations or exchanges are evicted or expire, after
+// the manager's lock is released so it may call back into the ContextManager. A nil handler
+// removes it. EventMemoryEvicted carries the same information for EventBus subscribers
+func (cm *ContextManager) OnEviction(handler func(Eviction)) {
+	cm.lockAll()
+	defer cm.unlockAll()
+	cm.onEviction = handler
+}
+
+// EvictionStats returns the evictions since the manager was created, keyed by reason
+func (cm *ContextManager) EvictionStats() map[string]EvictionCounts {
+	cm.rlockAll()
+	defer cm.runlockAll()
+	return cm.evictionCounts()
+}
+
+// evictionCounts sums the shards' eviction tallies, returning nil when there are none
+// This method assumes the caller already holds the lock
+func (cm *ContextManager) evictionCounts() map[string]EvictionCounts {
+	var total map[string]EvictionCounts
+	for i := range cm.shards {
+		total = addEvictionCounts(total, cm.shards[i].evictions.counts)
+	}
+	return total
+}
+
+// addEvictionCounts adds counts into total, creating it when needed
+func addEvictionCounts(total map[string]EvictionCounts, counts map[string]EvictionCounts) map[string]EvictionCounts {
+	for reason, count := range counts {
+		if total == nil {
+			total = make(map[string]EvictionCounts, len(counts))
+		}
+		sum := total[reason]
+		sum.Evictions += count.Evictions
+		sum.Conversations += count.Conversations
+		sum.Exchanges += count.Exchanges
+		total[reason] = sum
+	}
+	return total
+}
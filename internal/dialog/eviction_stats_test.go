@@ -0,0 +1,93 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContextManager_EvictionStatsCountReasonsAndLostExchanges(t *testing.T) {
+	cm := NewContextManagerWithConfig(2, 1, time.Hour, time.Millisecond)
+	defer cm.Close()
+
+	cm.AddExchange("first", "click", "Hello")
+	cm.AddExchange("first", "feed", "Yum")
+	cm.AddExchange("first", "pet", "Purr")  // Trims one exchange past the history limit
+	cm.AddExchange("second", "click", "Hi") // Evicts "first" with its 2 remaining exchanges
+	time.Sleep(5 * time.Millisecond)
+	cm.cleanupOldConversations() // Expires "second"
+
+	stats := cm.EvictionStats()
+	expected := map[string]EvictionCounts{
+		EvictionReasonHistoryLimit: {Evictions: 1, Exchanges: 1},
+		EvictionReasonCapacity:     {Evictions: 1, Conversations: 1, Exchanges: 2},
+		EvictionReasonExpired:      {Evictions: 1, Conversations: 1, Exchanges: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("Expected %d eviction reasons, got %+v", len(expected), stats)
+	}
+	for reason, want := range expected {
+		if stats[reason] != want {
+			t.Errorf("Expected %s counts %+v, got %+v", reason, want, stats[reason])
+		}
+	}
+
+	if memory := cm.MemoryStats(); memory.Evictions[EvictionReasonCapacity] != expected[EvictionReasonCapacity] {
+		t.Errorf("Expected MemoryStats to include eviction counts, got %+v", memory.Evictions)
+	}
+}
+
+func TestContextManager_EvictionStatsEmptyWithoutEvictions(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+
+	cm.AddExchange("user", "click", "Hello")
+	if stats := cm.EvictionStats(); stats != nil {
+		t.Errorf("Expected no eviction stats, got %+v", stats)
+	}
+}
+
+func TestContextManager_OnEvictionCallsHandlerOutsideLock(t *testing.T) {
+	cm := NewContextManagerWithConfig(5, 1, time.Hour, time.Hour)
+	defer cm.Close()
+
+	var evictions []Eviction
+	cm.OnEviction(func(eviction Eviction) {
+		// Calling back into the manager would deadlock if the lock were still held
+		cm.MemoryStats()
+		evictions = append(evictions, eviction)
+	})
+
+	cm.AddExchange("first", "click", "Hello")
+	cm.AddExchange("first", "feed", "Yum")
+	cm.AddExchange("second", "click", "Hi")
+
+	if len(evictions) != 1 {
+		t.Fatalf("Expected 1 eviction, got %d", len(evictions))
+	}
+	want := Eviction{InteractionID: "first", Reason: EvictionReasonCapacity, Exchanges: 2, Conversation: true}
+	if evictions[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, evictions[0])
+	}
+
+	cm.OnEviction(nil)
+	cm.AddExchange("third", "click", "Hey")
+	if len(evictions) != 1 {
+		t.Errorf("Expected removed handler not to be called, got %d evictions", len(evictions))
+	}
+	if stats := cm.EvictionStats(); stats[EvictionReasonCapacity].Evictions != 2 {
+		t.Errorf("Expected counting to continue without a handler, got %+v", stats)
+	}
+}
+
+func TestLLMBackend_ResourceStatsSumEvictions(t *testing.T) {
+	llm := newScriptedBackend(t, LLMConfig{MaxHistoryBytes: 1}, &scriptedTestModel{})
+	defer llm.Close()
+
+	llm.GetContextManager().AddExchange("user", "click", "Hello")
+	llm.GetContextManager().AddExchange("user", "feed", "Yum")
+
+	stats := llm.GetResourceStats()
+	if counts := stats.Memory.Evictions[EvictionReasonMemoryBudget]; counts.Exchanges != 1 || counts.Conversations != 0 {
+		t.Errorf("Expected one trimmed exchange in resource stats, got %+v", stats.Memory.Evictions)
+	}
+}
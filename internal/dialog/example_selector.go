@@ -0,0 +1,162 @@
+package dialog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ExampleSelector retrieves the training examples most relevant to the current situation
+// Uses bag-of-words cosine similarity so it runs cheaply on CPU without an embedding model
+type ExampleSelector struct {
+	examples []string
+	vectors  []map[string]float64
+}
+
+// stopWords lists common words that carry no signal for example similarity
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "at": true, "be": true,
+	"for": true, "i": true, "i'm": true, "im": true, "in": true, "is": true,
+	"it": true, "me": true, "my": true, "of": true, "on": true, "so": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true,
+	"with": true, "you": true, "your": true, "you're": true,
+}
+
+// NewExampleSelector creates a selector over the given training examples
+func NewExampleSelector(examples []string) *ExampleSelector {
+	selector := &ExampleSelector{
+		examples: examples,
+		vectors:  make([]map[string]float64, len(examples)),
+	}
+
+	for i, example := range examples {
+		selector.vectors[i] = termVector(example)
+	}
+
+	return selector
+}
+
+// Select returns up to k examples ranked by similarity to the query
+// Falls back to the original training order when nothing in the query matches
+func (es *ExampleSelector) Select(query string, k int) []string {
+	if k <= 0 || len(es.examples) == 0 {
+		return []string{}
+	}
+	if k > len(es.examples) {
+		k = len(es.examples)
+	}
+
+	queryVector := termVector(query)
+
+	type scoredExample struct {
+		index int
+		score float64
+	}
+
+	scored := make([]scoredExample, len(es.examples))
+	for i := range es.examples {
+		scored[i] = scoredExample{index: i, score: cosineSimilarity(queryVector, es.vectors[i])}
+	}
+
+	// Stable sort keeps training order for ties, so unmatched queries behave as before
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	selected := make([]string, k)
+	for i := 0; i < k; i++ {
+		selected[i] = es.examples[scored[i].index]
+	}
+	return selected
+}
+
+// buildExampleQuery describes the current situation as text for example retrieval
+func buildExampleQuery(ctx DialogContext) string {
+	parts := []string{ctx.Trigger, triggerDescription(ctx.Trigger), userUtterance(ctx)}
+
+	for topic, value := range ctx.TopicContext {
+		parts = append(parts, topic)
+		if text, ok := value.(string); ok {
+			parts = append(parts, text)
+		} else if value != nil {
+			parts = append(parts, fmt.Sprint(value))
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// termVector converts text into a term frequency vector
+func termVector(text string) map[string]float64 {
+	vector := make(map[string]float64)
+	addTerms(vector, text)
+	return vector
+}
+
+// addTerms counts the words of text into vector
+func addTerms(vector map[string]float64, text string) {
+	lower := strings.ToLower(text)
+	start := -1
+	for i, r := range lower {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == '\'' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			addTerm(vector, lower[start:i])
+			start = -1
+		}
+	}
+	if start >= 0 {
+		addTerm(vector, lower[start:])
+	}
+}
+
+// addTerm counts one lowercased word, dropping apostrophes at its ends and stop words
+func addTerm(vector map[string]float64, word string) {
+	word = strings.Trim(word, "'")
+	if word == "" || stopWords[word] {
+		return
+	}
+	vector[stemWord(word)]++
+}
+
+// stemWord applies light suffix stripping so "feeding" and "feeds" both match "feed"
+func stemWord(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if len(word) > len(suffix)+2 && strings.HasSuffix(word, suffix) {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
+
+// cosineSimilarity computes the cosine of the angle between two term vectors
+func cosineSimilarity(a, b map[string]float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	dot := 0.0
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	if dot == 0 {
+		return 0
+	}
+
+	return dot / (vectorNorm(a) * vectorNorm(b))
+}
+
+// vectorNorm returns the Euclidean length of a term vector
+func vectorNorm(v map[string]float64) float64 {
+	sum := 0.0
+	for _, weight := range v {
+		sum += weight * weight
+	}
+	return math.Sqrt(sum)
+}
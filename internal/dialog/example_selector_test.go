@@ -0,0 +1,99 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExampleSelector_SelectRanksBySimilarity(t *testing.T) {
+	selector := NewExampleSelector([]string{
+		"Hello! Nice to see you again!",
+		"Let's play a game together!",
+		"Yum, thanks for feeding me! *nom nom*",
+		"I love when you play with me!",
+	})
+
+	selected := selector.Select("feed fed you food", 1)
+	if len(selected) != 1 {
+		t.Fatalf("Expected 1 example, got %d", len(selected))
+	}
+	if !strings.Contains(selected[0], "feeding") {
+		t.Errorf("Expected feeding example to be selected, got %q", selected[0])
+	}
+
+	selected = selector.Select("play wants to play", 2)
+	for _, example := range selected {
+		if !strings.Contains(example, "play") {
+			t.Errorf("Expected play examples, got %q", example)
+		}
+	}
+}
+
+func TestExampleSelector_SelectFallsBackToTrainingOrder(t *testing.T) {
+	examples := []string{"First line", "Second line", "Third line", "Fourth line"}
+	selector := NewExampleSelector(examples)
+
+	selected := selector.Select("zzz unrelated", 3)
+	for i, example := range selected {
+		if example != examples[i] {
+			t.Errorf("Expected training order for unmatched query, position %d got %q", i, example)
+		}
+	}
+}
+
+func TestExampleSelector_SelectLimits(t *testing.T) {
+	selector := NewExampleSelector([]string{"one", "two"})
+
+	if got := selector.Select("one", 5); len(got) != 2 {
+		t.Errorf("Expected selection capped at 2 examples, got %d", len(got))
+	}
+	if got := selector.Select("one", 0); len(got) != 0 {
+		t.Errorf("Expected empty selection for k=0, got %d", len(got))
+	}
+	if got := NewExampleSelector(nil).Select("one", 3); len(got) != 0 {
+		t.Errorf("Expected empty selection with no examples, got %d", len(got))
+	}
+}
+
+func TestBuildExampleQuery(t *testing.T) {
+	query := buildExampleQuery(DialogContext{
+		Trigger:      "feed",
+		TopicContext: map[string]interface{}{"snack": "cookies"},
+	})
+
+	for _, expected := range []string{"feed", "fed you", "snack", "cookies"} {
+		if !strings.Contains(query, expected) {
+			t.Errorf("Expected query to contain %q, got %q", expected, query)
+		}
+	}
+}
+
+func TestLLMBackend_ExtractPersonalityUsesRelevantExamples(t *testing.T) {
+	backend := NewLLMBackend()
+	config := LLMConfig{
+		ModelPath:       "/fake/path.gguf",
+		FewShotExamples: 2,
+		MarkovConfig: MarkovChainConfig{
+			TrainingData: []string{
+				"Hello there, friend!",
+				"Good morning sunshine!",
+				"How was your day?",
+				"Thanks for feeding me, that was yummy!",
+			},
+		},
+	}
+	configJSON, _ := json.Marshal(config)
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	defer backend.Close()
+
+	personality := backend.extractPersonality(DialogContext{Trigger: "feed"})
+	if !strings.Contains(personality, "Thanks for feeding me") {
+		t.Errorf("Expected feeding example in personality, got %q", personality)
+	}
+	if strings.Count(personality, "\n- ") != 2 {
+		t.Errorf("Expected 2 few-shot examples, got personality %q", personality)
+	}
+}
@@ -0,0 +1,234 @@
+package dialog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Metadata keys used to tag responses served by an experiment arm
+const (
+	MetadataExperiment        = "experiment"
+	MetadataExperimentArm     = "experimentArm"
+	MetadataExperimentBackend = "experimentBackend"
+)
+
+// ExperimentConfig describes an A/B split between two registered backends
+// The experiment replaces the default backend; the fallback chain still applies when an arm fails
+type ExperimentConfig struct {
+	Name            string  `json:"name"`
+	BackendA        string  `json:"backendA"`        // Control arm
+	BackendB        string  `json:"backendB"`        // Treatment arm
+	SplitPercent    float64 `json:"splitPercent"`    // Share of traffic sent to BackendB (0-100)
+	ByInteractionID bool    `json:"byInteractionId"` // Sticky assignment by hashing InteractionID instead of per request
+}
+
+// ExperimentArmResult reports outcomes for one experiment arm
+type ExperimentArmResult struct {
+	Arm              string  `json:"arm"` // "A" or "B"
+	Backend          string  `json:"backend"`
+	Assignments      int     `json:"assignments"` // Requests routed to this arm
+	Responses        int     `json:"responses"`   // Requests the arm answered successfully
+	Failures         int     `json:"failures"`    // Requests that fell through to the fallback chain
+	FeedbackCount    int     `json:"feedbackCount"`
+	PositiveFeedback int     `json:"positiveFeedback"`
+	PositiveRatio    float64 `json:"positiveRatio"`
+	AvgEngagement    float64 `json:"avgEngagement"`
+	AvgConfidence    float64 `json:"avgConfidence"`
+}
+
+// ExperimentResults reports the current state of an A/B experiment
+type ExperimentResults struct {
+	Name      string              `json:"name"`
+	Active    bool                `json:"active"`
+	StartedAt time.Time           `json:"startedAt"`
+	StoppedAt time.Time           `json:"stoppedAt,omitempty"`
+	ArmA      ExperimentArmResult `json:"armA"`
+	ArmB      ExperimentArmResult `json:"armB"`
+}
+
+// experiment holds the live state of a running A/B experiment
+type experiment struct {
+	config    ExperimentConfig
+	startedAt time.Time
+	arms      [2]*experimentArm
+}
+
+// experimentArm accumulates outcomes for one side of an experiment
+type experimentArm struct {
+	label         string
+	backend       string
+	assignments   int
+	responses     int
+	failures      int
+	feedbackCount int
+	positive      int
+	engagement    float64
+	confidence    float64
+	mu            sync.Mutex
+}
+
+// StartExperiment begins routing default-backend traffic between two registered backends
+// Any running experiment is replaced and its results discarded
+func (dm *DialogManager) StartExperiment(config ExperimentConfig) error {
+	if config.Name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if config.SplitPercent < 0 || config.SplitPercent > 100 {
+		return fmt.Errorf("splitPercent must be between 0 and 100, got %f", config.SplitPercent)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, name := range []string{config.BackendA, config.BackendB} {
+		if _, exists := dm.backends[name]; !exists {
+			return fmt.Errorf("experiment backend '%s' not registered", name)
+		}
+	}
+
+	dm.experiment = &experiment{
+		config:    config,
+		startedAt: currentTime(),
+		arms: [2]*experimentArm{
+			{label: "A", backend: config.BackendA},
+			{label: "B", backend: config.BackendB},
+		},
+	}
+	return nil
+}
+
+// StopExperiment ends the running experiment and returns its final results
+func (dm *DialogManager) StopExperiment() (ExperimentResults, error) {
+	dm.mu.Lock()
+	exp := dm.experiment
+	dm.experiment = nil
+	dm.mu.Unlock()
+
+	if exp == nil {
+		return ExperimentResults{}, fmt.Errorf("no experiment running")
+	}
+
+	results := exp.results()
+	results.StoppedAt = currentTime()
+	return results, nil
+}
+
+// GetExperimentResults returns results for the running experiment
+func (dm *DialogManager) GetExperimentResults() (ExperimentResults, bool) {
+	dm.mu.RLock()
+	exp := dm.experiment
+	dm.mu.RUnlock()
+
+	if exp == nil {
+		return ExperimentResults{}, false
+	}
+
+	results := exp.results()
+	results.Active = true
+	return results, true
+}
+
+// assign picks the arm for a request
+func (e *experiment) assign(context DialogContext) *experimentArm {
+	var bucket float64
+	if e.config.ByInteractionID {
+		hash := fnv.New32a()
+		hash.Write([]byte(e.config.Name + ":" + context.InteractionID))
+		bucket = float64(hash.Sum32()%10000) / 100
+	} else {
+		bucket = randomFloat64() * 100
+	}
+
+	arm := e.arms[0]
+	if bucket < e.config.SplitPercent {
+		arm = e.arms[1]
+	}
+
+	arm.mu.Lock()
+	arm.assignments++
+	arm.mu.Unlock()
+	return arm
+}
+
+// armFor returns the arm that served a response, if it belongs to this experiment
+func (e *experiment) armFor(response DialogResponse) *experimentArm {
+	if response.Metadata == nil || response.Metadata[MetadataExperiment] != e.config.Name {
+		return nil
+	}
+	for _, arm := range e.arms {
+		if response.Metadata[MetadataExperimentArm] == arm.label {
+			return arm
+		}
+	}
+	return nil
+}
+
+// results snapshots the experiment outcome
+func (e *experiment) results() ExperimentResults {
+	return ExperimentResults{
+		Name:      e.config.Name,
+		StartedAt: e.startedAt,
+		ArmA:      e.arms[0].result(),
+		ArmB:      e.arms[1].result(),
+	}
+}
+
+// annotate tags a response with the experiment and arm that produced it
+func (a *experimentArm) annotate(response DialogResponse, experimentName string) DialogResponse {
+	metadata := make(map[string]interface{}, len(response.Metadata)+3)
+	for key, value := range response.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataExperiment] = experimentName
+	metadata[MetadataExperimentArm] = a.label
+	metadata[MetadataExperimentBackend] = a.backend
+	response.Metadata = metadata
+	return response
+}
+
+// recordResponse counts a successful response from this arm
+func (a *experimentArm) recordResponse(response DialogResponse) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.responses++
+	a.confidence += response.Confidence
+}
+
+// recordFailure counts a request this arm could not answer
+func (a *experimentArm) recordFailure() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures++
+}
+
+// recordFeedback folds user feedback into the arm's engagement totals
+func (a *experimentArm) recordFeedback(feedback *UserFeedback) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.feedbackCount++
+	a.engagement += feedback.Engagement
+	if feedback.Positive {
+		a.positive++
+	}
+}
+
+// result snapshots the arm's outcome
+func (a *experimentArm) result() ExperimentArmResult {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return ExperimentArmResult{
+		Arm:              a.label,
+		Backend:          a.backend,
+		Assignments:      a.assignments,
+		Responses:        a.responses,
+		Failures:         a.failures,
+		FeedbackCount:    a.feedbackCount,
+		PositiveFeedback: a.positive,
+		PositiveRatio:    safeRatio(float64(a.positive), a.feedbackCount),
+		AvgEngagement:    safeRatio(a.engagement, a.feedbackCount),
+		AvgConfidence:    safeRatio(a.confidence, a.responses),
+	}
+}
@@ -0,0 +1,138 @@
+package dialog
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newExperimentTestManager registers two scripted backends named "control" and "treatment"
+func newExperimentTestManager(t *testing.T) *DialogManager {
+	t.Helper()
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("control", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Control reply"}}))
+	dm.RegisterBackend("treatment", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Treatment reply"}}))
+	dm.SetDefaultBackend("control")
+	return dm
+}
+
+func TestDialogManager_StartExperimentValidation(t *testing.T) {
+	dm := newExperimentTestManager(t)
+
+	tests := []struct {
+		name   string
+		config ExperimentConfig
+	}{
+		{"missing name", ExperimentConfig{BackendA: "control", BackendB: "treatment"}},
+		{"unknown backend", ExperimentConfig{Name: "x", BackendA: "control", BackendB: "missing"}},
+		{"split too high", ExperimentConfig{Name: "x", BackendA: "control", BackendB: "treatment", SplitPercent: 150}},
+	}
+
+	for _, tt := range tests {
+		if err := dm.StartExperiment(tt.config); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+		}
+	}
+
+	if _, active := dm.GetExperimentResults(); active {
+		t.Error("Expected no experiment after failed starts")
+	}
+}
+
+func TestDialogManager_ExperimentSplitsByInteractionID(t *testing.T) {
+	dm := newExperimentTestManager(t)
+
+	err := dm.StartExperiment(ExperimentConfig{
+		Name:            "prompt-v2",
+		BackendA:        "control",
+		BackendB:        "treatment",
+		SplitPercent:    50,
+		ByInteractionID: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start experiment: %v", err)
+	}
+
+	// Assignment is sticky per interaction ID
+	first, _ := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "user-42"})
+	for i := 0; i < 5; i++ {
+		response, _ := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "user-42"})
+		if response.Text != first.Text {
+			t.Fatalf("Expected sticky assignment %q, got %q", first.Text, response.Text)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: fmt.Sprintf("user-%d", i)})
+	}
+
+	results, active := dm.GetExperimentResults()
+	if !active {
+		t.Fatal("Expected experiment to be active")
+	}
+	if results.ArmA.Assignments+results.ArmB.Assignments != 106 {
+		t.Errorf("Expected 106 assignments, got %d", results.ArmA.Assignments+results.ArmB.Assignments)
+	}
+	if results.ArmA.Responses == 0 || results.ArmB.Responses == 0 {
+		t.Errorf("Expected both arms to serve traffic, got A=%d B=%d", results.ArmA.Responses, results.ArmB.Responses)
+	}
+}
+
+func TestDialogManager_ExperimentSplitExtremes(t *testing.T) {
+	dm := newExperimentTestManager(t)
+
+	dm.StartExperiment(ExperimentConfig{Name: "all-b", BackendA: "control", BackendB: "treatment", SplitPercent: 100})
+	for i := 0; i < 10; i++ {
+		response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"})
+		if response.Text != "Treatment reply" {
+			t.Fatalf("Expected treatment reply, got %q", response.Text)
+		}
+		if response.Metadata[MetadataExperimentArm] != "B" {
+			t.Errorf("Expected arm B metadata, got %v", response.Metadata[MetadataExperimentArm])
+		}
+	}
+
+	dm.StartExperiment(ExperimentConfig{Name: "all-a", BackendA: "control", BackendB: "treatment", SplitPercent: 0})
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"})
+	if response.Text != "Control reply" {
+		t.Errorf("Expected control reply, got %q", response.Text)
+	}
+}
+
+func TestDialogManager_ExperimentRecordsFeedback(t *testing.T) {
+	dm := newExperimentTestManager(t)
+	dm.StartExperiment(ExperimentConfig{Name: "feedback", BackendA: "control", BackendB: "treatment", SplitPercent: 100})
+
+	context := DialogContext{Trigger: "click", InteractionID: "session"}
+	response, _ := dm.GenerateDialog(context)
+	dm.UpdateBackendMemory(context, response, &UserFeedback{Positive: true, Engagement: 0.9})
+	dm.UpdateBackendMemory(context, response, &UserFeedback{Positive: false, Engagement: 0.3})
+
+	// Responses from outside the experiment are ignored
+	dm.UpdateBackendMemory(context, DialogResponse{Text: "other"}, &UserFeedback{Positive: true, Engagement: 1})
+
+	results, err := dm.StopExperiment()
+	if err != nil {
+		t.Fatalf("Failed to stop experiment: %v", err)
+	}
+
+	if results.ArmB.FeedbackCount != 2 {
+		t.Errorf("Expected 2 feedback records for arm B, got %d", results.ArmB.FeedbackCount)
+	}
+	if results.ArmB.PositiveRatio != 0.5 {
+		t.Errorf("Expected positive ratio 0.5, got %f", results.ArmB.PositiveRatio)
+	}
+	if results.ArmB.AvgEngagement < 0.59 || results.ArmB.AvgEngagement > 0.61 {
+		t.Errorf("Expected average engagement 0.6, got %f", results.ArmB.AvgEngagement)
+	}
+	if results.ArmA.FeedbackCount != 0 {
+		t.Errorf("Expected no feedback for arm A, got %d", results.ArmA.FeedbackCount)
+	}
+	if results.StoppedAt.IsZero() {
+		t.Error("Expected StoppedAt to be set")
+	}
+
+	if _, err := dm.StopExperiment(); err == nil {
+		t.Error("Expected error stopping an experiment twice")
+	}
+}
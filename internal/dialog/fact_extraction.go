@@ -0,0 +1,364 @@
+package dialog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fact extraction defaults
+const (
+	defaultAcceptConfidence = 0.8
+	defaultReviewConfidence = 0.5
+	defaultFactLifetime     = 30 * 24 * time.Hour
+	maxPreferenceWords      = 4 // Longer "I like ..." phrases are too unreliable to remember
+)
+
+// ExtractionConfig sets how extracted facts are trusted and how long they are kept
+type ExtractionConfig struct {
+	AcceptConfidence float64 `json:"acceptConfidence,omitempty"` // Facts at or above this are remembered directly (default: 0.8)
+	ReviewConfidence float64 `json:"reviewConfidence,omitempty"` // Facts at or above this wait in Pending for review (default: 0.5)
+	ExpireAfterHours float64 `json:"expireAfterHours,omitempty"` // Extracted facts are forgotten after this long unless confirmed; negative keeps them (default: 720)
+}
+
+// factPattern extracts one kind of fact from text
+type factPattern struct {
+	pattern    *regexp.Regexp
+	key        string             // Fact key, or "" to choose by verb
+	verbs      map[string]string  // Lowercased verb to fact key when key is ""
+	confidence map[string]float64 // Confidence by lowercased verb, or by "" for a fixed value
+}
+
+var (
+	// userFactPatterns match what users say about themselves
+	userFactPatterns = []factPattern{
+		{
+			pattern:    regexp.MustCompile(`(?i:\b(my name is|i'm called|i am called|call me))\s+(\p{Lu}[\p{L}'-]+)`),
+			key:        FactName,
+			confidence: map[string]float64{"my name is": 0.95, "i'm called": 0.9, "i am called": 0.9, "call me": 0.85},
+		},
+		{
+			pattern:    regexp.MustCompile(`(?i)\bmy birthday is(?: on)?\s+([a-z0-9/ -]+)`),
+			key:        FactBirthday,
+			confidence: map[string]float64{"": 0.9},
+		},
+		{
+			pattern:    regexp.MustCompile(`(?i)\bi (?:really )?(love|adore|like|enjoy|hate|dislike|don't like|can't stand)\s+([^.,!?;:]+)`),
+			verbs:      map[string]string{"love": FactLikes, "adore": FactLikes, "like": FactLikes, "enjoy": FactLikes, "hate": FactDislikes, "dislike": FactDislikes, "don't like": FactDislikes, "can't stand": FactDislikes},
+			confidence: map[string]float64{"love": 0.9, "adore": 0.9, "like": 0.8, "enjoy": 0.8, "hate": 0.9, "dislike": 0.8, "don't like": 0.8, "can't stand": 0.85},
+		},
+	}
+
+	// responseFactPatterns match what the character repeats back about the user
+	responseFactPatterns = []factPattern{
+		{
+			pattern:    regexp.MustCompile(`(?i:\b(nice to meet you)),?\s+(\p{Lu}[\p{L}'-]+)`),
+			key:        FactName,
+			confidence: map[string]float64{"nice to meet you": 0.6},
+		},
+		{
+			pattern:    regexp.MustCompile(`(?i)\byou (?:really )?(love|like|enjoy|hate|don't like)\s+([^.,!?;:]+)`),
+			verbs:      map[string]string{"love": FactLikes, "like": FactLikes, "enjoy": FactLikes, "hate": FactDislikes, "don't like": FactDislikes},
+			confidence: map[string]float64{"": 0.55},
+		},
+	}
+
+	// vaguePreferences are objects too vague to remember as preferences
+	vaguePreferences = map[string]bool{"it": true, "that": true, "this": true, "you": true, "them": true, "him": true, "her": true, "to": true}
+
+	birthdayLayouts = []string{"January 2", "2 January", "Jan 2", "2 Jan", "01-02", "1/2"}
+	ordinalSuffix   = regexp.MustCompile(`(\d)(st|nd|rd|th)\b`)
+)
+
+// FactExtractor pulls names, birthdays and simple preferences out of exchanges
+type FactExtractor struct {
+	acceptConfidence float64
+	reviewConfidence float64
+	lifetime         time.Duration // 0 = extracted facts never expire
+}
+
+// NewFactExtractor creates an extractor, applying defaults for unset values
+func NewFactExtractor(config ExtractionConfig) (*FactExtractor, error) {
+	if config.AcceptConfidence < 0 || config.AcceptConfidence > 1 || config.ReviewConfidence < 0 || config.ReviewConfidence > 1 {
+		return nil, fmt.Errorf("extraction confidence thresholds must be between 0 and 1")
+	}
+	if config.AcceptConfidence == 0 {
+		config.AcceptConfidence = defaultAcceptConfidence
+	}
+	if config.ReviewConfidence == 0 {
+		config.ReviewConfidence = defaultReviewConfidence
+	}
+	if config.ReviewConfidence > config.AcceptConfidence {
+		return nil, fmt.Errorf("reviewConfidence %.2f exceeds acceptConfidence %.2f", config.ReviewConfidence, config.AcceptConfidence)
+	}
+
+	lifetime := time.Duration(config.ExpireAfterHours * float64(time.Hour))
+	switch {
+	case config.ExpireAfterHours == 0:
+		lifetime = defaultFactLifetime
+	case config.ExpireAfterHours < 0:
+		lifetime = 0
+	}
+
+	return &FactExtractor{
+		acceptConfidence: config.AcceptConfidence,
+		reviewConfidence: config.ReviewConfidence,
+		lifetime:         lifetime,
+	}, nil
+}
+
+// Extract returns the facts found in an exchange: userText is what the user said,
+// response is the character's reply; facts the character merely repeats back get
+// lower confidence
+func (e *FactExtractor) Extract(userText, response string) []UserFact {
+	facts := extractFacts(userText, userFactPatterns)
+	return append(facts, extractFacts(response, responseFactPatterns)...)
+}
+
+// extractFacts applies the patterns to text
+func extractFacts(text string, patterns []factPattern) []UserFact {
+	var facts []UserFact
+	for _, p := range patterns {
+		for _, match := range p.pattern.FindAllStringSubmatch(text, -1) {
+			verb, value := "", match[len(match)-1]
+			if len(match) > 2 {
+				verb = strings.ToLower(match[1])
+			}
+
+			key := p.key
+			if key == "" {
+				key = p.verbs[verb]
+			}
+			confidence, fixed := p.confidence[""]
+			if !fixed {
+				confidence = p.confidence[verb]
+			}
+
+			value, ok := cleanFactValue(key, value)
+			if !ok {
+				continue
+			}
+			facts = append(facts, UserFact{Key: key, Value: value, Source: FactSourceExtracted, Confidence: confidence})
+		}
+	}
+	return facts
+}
+
+// cleanFactValue normalizes an extracted value, rejecting vague or unparseable ones
+func cleanFactValue(key, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	switch key {
+	case FactBirthday:
+		return parseBirthday(value)
+	case FactLikes, FactDislikes:
+		words := strings.Fields(strings.ToLower(value))
+		if len(words) == 0 || len(words) > maxPreferenceWords || vaguePreferences[words[0]] {
+			return "", false
+		}
+		return strings.Join(words, " "), true
+	}
+	return value, value != ""
+}
+
+// parseBirthday reads a day and month such as "June 1st" or "06-01" as "June 1"
+func parseBirthday(value string) (string, bool) {
+	value = ordinalSuffix.ReplaceAllString(strings.TrimSpace(value), "$1")
+	words := strings.Fields(value)
+	for n := min(len(words), 2); n > 0; n-- {
+		candidate := strings.Join(words[:n], " ")
+		for _, layout := range birthdayLayouts {
+			if parsed, err := time.Parse(layout, candidate); err == nil {
+				return parsed.Format("January 2"), true
+			}
+		}
+	}
+	return "", false
+}
+
+// EnableExtraction makes the memory learn facts from each GenerateDialog exchange:
+// confident facts are remembered with an expiry, less confident ones wait in Pending
+// User text is read from the string values of DialogContext.TopicContext
+func (m *UserMemory) EnableExtraction(config ExtractionConfig) error {
+	extractor, err := NewFactExtractor(config)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.extractor = extractor
+	return nil
+}
+
+// learn extracts facts from an exchange when extraction is enabled
+func (m *UserMemory) learn(context DialogContext, response DialogResponse) {
+	m.mu.RLock()
+	extractor := m.extractor
+	m.mu.RUnlock()
+	if extractor == nil {
+		return
+	}
+
+	for _, fact := range extractor.Extract(contextUserText(context), response.Text) {
+		m.Observe(context.InteractionID, fact)
+	}
+}
+
+// contextUserText joins what the user typed or said with the string values of the
+// context's topics in key order
+func contextUserText(context DialogContext) string {
+	keys := make([]string, 0, len(context.TopicContext))
+	for key := range context.TopicContext {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var text []string
+	if utterance := userUtterance(context); utterance != "" {
+		text = append(text, utterance)
+	}
+	for _, key := range keys {
+		if value, ok := context.TopicContext[key].(string); ok {
+			text = append(text, value)
+		}
+	}
+	return strings.Join(text, "\n")
+}
+
+// Observe routes an extracted fact by confidence: at or above the accept threshold it
+// is remembered (unless it would overwrite a host-set value), above the review
+// threshold it waits in Pending, and below it is dropped
+// It does nothing when extraction is not enabled
+func (m *UserMemory) Observe(interactionID string, fact UserFact) {
+	fact.Source = FactSourceExtracted
+	fact, err := normalizeUserFact(fact)
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	extractor := m.extractor
+	if extractor == nil || fact.Confidence < extractor.reviewConfidence {
+		return
+	}
+	if extractor.lifetime > 0 {
+		fact.ExpiresAt = fact.UpdatedAt.Add(extractor.lifetime)
+	}
+
+	if fact.Confidence >= extractor.acceptConfidence && !m.overridesHostFact(interactionID, fact) {
+		m.store(interactionID, fact)
+		m.removePending(interactionID, fact)
+		return
+	}
+
+	m.removePending(interactionID, fact)
+	m.pending[interactionID] = append(m.pending[interactionID], fact)
+}
+
+// overridesHostFact reports whether fact would replace a value the host set
+func (m *UserMemory) overridesHostFact(interactionID string, fact UserFact) bool {
+	for _, existing := range m.facts[interactionID] {
+		if existing.Source == FactSourceHost && fact.replaces(existing) && !strings.EqualFold(existing.Value, fact.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// removePending drops pending facts that fact replaces
+func (m *UserMemory) removePending(interactionID string, fact UserFact) {
+	kept := m.pending[interactionID][:0]
+	for _, pending := range m.pending[interactionID] {
+		if !(pending.Key == fact.Key && strings.EqualFold(pending.Value, fact.Value)) {
+			kept = append(kept, pending)
+		}
+	}
+	if len(kept) == 0 {
+		delete(m.pending, interactionID)
+		return
+	}
+	m.pending[interactionID] = kept
+}
+
+// Pending returns extracted facts awaiting review for an interaction, oldest first
+func (m *UserMemory) Pending(interactionID string) []UserFact {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := currentTime()
+	var pending []UserFact
+	for _, fact := range m.pending[interactionID] {
+		if !fact.expired(now) {
+			pending = append(pending, fact)
+		}
+	}
+	return pending
+}
+
+// Confirm remembers a reviewed fact as if the host set it, without an expiry
+func (m *UserMemory) Confirm(interactionID string, fact UserFact) error {
+	fact.Source = FactSourceHost
+	fact.Confidence = 1
+	fact.ExpiresAt = time.Time{}
+	fact.UpdatedAt = time.Time{}
+	fact, err := normalizeUserFact(fact)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store(interactionID, fact)
+	m.removePending(interactionID, fact)
+	return nil
+}
+
+// Reject discards a fact from review and from memory
+func (m *UserMemory) Reject(interactionID string, fact UserFact) {
+	fact.Key = strings.ToLower(strings.TrimSpace(fact.Key))
+	fact.Value = strings.TrimSpace(fact.Value)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removePending(interactionID, fact)
+
+	kept := m.facts[interactionID][:0]
+	for _, existing := range m.facts[interactionID] {
+		if !(existing.Key == fact.Key && strings.EqualFold(existing.Value, fact.Value)) {
+			kept = append(kept, existing)
+		}
+	}
+	m.facts[interactionID] = kept
+}
+
+// ExpireFacts drops expired facts and pending facts for every interaction,
+// returning how many were removed
+func (m *UserMemory) ExpireFacts() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := currentTime()
+	removed := 0
+	for _, store := range []map[string][]UserFact{m.facts, m.pending} {
+		for interactionID, facts := range store {
+			kept := facts[:0]
+			for _, fact := range facts {
+				if fact.expired(now) {
+					removed++
+					continue
+				}
+				kept = append(kept, fact)
+			}
+			if len(kept) == 0 {
+				delete(store, interactionID)
+			} else {
+				store[interactionID] = kept
+			}
+		}
+	}
+	return removed
+}
@@ -0,0 +1,150 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFactExtractor_Extract(t *testing.T) {
+	extractor, err := NewFactExtractor(ExtractionConfig{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	facts := extractor.Extract("Hi! My name is Sam and I really love hot chocolate. My birthday is June 1st. I like it.", "")
+	expected := []UserFact{
+		{Key: FactName, Value: "Sam", Confidence: 0.95},
+		{Key: FactBirthday, Value: "June 1", Confidence: 0.9},
+		{Key: FactLikes, Value: "hot chocolate", Confidence: 0.9},
+	}
+	if len(facts) != len(expected) {
+		t.Fatalf("Expected %d facts, got %+v", len(expected), facts)
+	}
+	for i, fact := range facts {
+		if fact.Key != expected[i].Key || fact.Value != expected[i].Value || fact.Confidence != expected[i].Confidence || fact.Source != FactSourceExtracted {
+			t.Errorf("Expected %+v, got %+v", expected[i], fact)
+		}
+	}
+
+	facts = extractor.Extract("I can't stand mornings! My birthday is 12-25", "Nice to meet you, Alex! So you like puzzles.")
+	if len(facts) != 4 {
+		t.Fatalf("Expected 4 facts, got %+v", facts)
+	}
+	if facts[0].Key != FactBirthday || facts[0].Value != "December 25" {
+		t.Errorf("Expected numeric birthday, got %+v", facts[0])
+	}
+	if facts[1].Key != FactDislikes || facts[1].Value != "mornings" {
+		t.Errorf("Expected dislike, got %+v", facts[1])
+	}
+	if facts[2].Key != FactName || facts[2].Value != "Alex" || facts[2].Confidence != 0.6 {
+		t.Errorf("Expected low-confidence name from the response, got %+v", facts[2])
+	}
+	if facts[3].Key != FactLikes || facts[3].Value != "puzzles" || facts[3].Confidence != 0.55 {
+		t.Errorf("Expected low-confidence like from the response, got %+v", facts[3])
+	}
+
+	if facts := extractor.Extract("call me maybe. I like to run around the park every day. My birthday is soon", ""); len(facts) != 0 {
+		t.Errorf("Expected vague statements ignored, got %+v", facts)
+	}
+}
+
+func TestNewFactExtractorValidation(t *testing.T) {
+	for _, config := range []ExtractionConfig{{AcceptConfidence: 2}, {ReviewConfidence: -1}, {AcceptConfidence: 0.5, ReviewConfidence: 0.7}} {
+		if _, err := NewFactExtractor(config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+func TestUserMemory_ObserveByConfidence(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	memory := NewUserMemory()
+	memory.Observe("pet", UserFact{Key: FactName, Value: "Sam", Confidence: 0.95})
+	if len(memory.Facts("pet")) != 0 {
+		t.Fatal("Expected facts ignored until extraction is enabled")
+	}
+
+	if err := memory.EnableExtraction(ExtractionConfig{ExpireAfterHours: 24}); err != nil {
+		t.Fatalf("EnableExtraction failed: %v", err)
+	}
+	memory.Observe("pet", UserFact{Key: FactName, Value: "Sam", Confidence: 0.95})
+	memory.Observe("pet", UserFact{Key: FactLikes, Value: "puzzles", Confidence: 0.6})
+	memory.Observe("pet", UserFact{Key: FactLikes, Value: "naps", Confidence: 0.3})
+
+	facts := memory.Facts("pet")
+	if len(facts) != 1 || facts[0].Value != "Sam" || facts[0].Source != FactSourceExtracted {
+		t.Fatalf("Expected the confident fact remembered, got %+v", facts)
+	}
+	if !facts[0].ExpiresAt.Equal(clock.Now().Add(24 * time.Hour)) {
+		t.Errorf("Expected extracted fact to expire in 24h, got %v", facts[0].ExpiresAt)
+	}
+	pending := memory.Pending("pet")
+	if len(pending) != 1 || pending[0].Value != "puzzles" {
+		t.Fatalf("Expected the uncertain fact pending review, got %+v", pending)
+	}
+
+	memory.Remember("pet", UserFact{Key: FactName, Value: "Samantha"})
+	memory.Observe("pet", UserFact{Key: FactName, Value: "Sammy", Confidence: 0.95})
+	if facts := memory.Facts("pet"); facts[len(facts)-1].Value != "Samantha" {
+		t.Errorf("Expected host-set name kept, got %+v", facts)
+	}
+	if pending := memory.Pending("pet"); len(pending) != 2 || pending[1].Value != "Sammy" {
+		t.Errorf("Expected conflicting name sent to review, got %+v", pending)
+	}
+
+	if err := memory.Confirm("pet", pending[0]); err != nil {
+		t.Fatalf("Confirm failed: %v", err)
+	}
+	memory.Reject("pet", UserFact{Key: FactName, Value: "Sammy"})
+	if pending := memory.Pending("pet"); len(pending) != 0 {
+		t.Errorf("Expected review queue emptied, got %+v", pending)
+	}
+	facts = memory.Facts("pet")
+	confirmed := facts[len(facts)-1]
+	if confirmed.Value != "puzzles" || confirmed.Source != FactSourceHost || confirmed.Confidence != 1 || !confirmed.ExpiresAt.IsZero() {
+		t.Errorf("Expected confirmed fact kept as a host fact, got %+v", confirmed)
+	}
+
+	clock.Advance(25 * time.Hour)
+	if facts := memory.Facts("pet"); len(facts) != 2 {
+		t.Errorf("Expected host and confirmed facts to outlive the extraction expiry, got %+v", facts)
+	}
+}
+
+func TestUserMemory_ExpireFacts(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	memory := NewUserMemory()
+	memory.EnableExtraction(ExtractionConfig{ExpireAfterHours: 1})
+	memory.Observe("pet", UserFact{Key: FactName, Value: "Sam", Confidence: 0.9})
+	memory.Observe("pet", UserFact{Key: FactLikes, Value: "tea", Confidence: 0.6})
+	memory.Remember("pet", UserFact{Key: FactBirthday, Value: "June 1"})
+
+	clock.Advance(2 * time.Hour)
+	if removed := memory.ExpireFacts(); removed != 2 {
+		t.Errorf("Expected 2 expired facts removed, got %d", removed)
+	}
+	if facts := memory.Facts("pet"); len(facts) != 1 || facts[0].Key != FactBirthday {
+		t.Errorf("Expected only the host fact left, got %+v", facts)
+	}
+}
+
+func TestDialogManager_UserMemoryExtraction(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Nice to meet you, Sam!")
+	memory := NewUserMemory()
+	memory.EnableExtraction(ExtractionConfig{})
+	dm.SetUserMemory(memory)
+
+	dm.GenerateDialog(DialogContext{Trigger: "talk", InteractionID: "pet",
+		TopicContext: map[string]interface{}{"message": "My name is Sam and I love coffee", "turns": 3}})
+
+	facts := memory.Facts("pet")
+	if len(facts) != 2 || facts[0].Value != "Sam" || facts[1].Value != "coffee" {
+		t.Errorf("Expected name and preference extracted from the exchange, got %+v", facts)
+	}
+}
@@ -0,0 +1,303 @@
+package dialog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ModelFixture scripts the output of a FixtureModel for deterministic testing
+// Scripted sequence steps are returned first, then the first matching rule, then Default
+type ModelFixture struct {
+	Sequence     []FixtureStep `json:"sequence,omitempty"`     // Replies returned in order, one per call
+	LoopSequence bool          `json:"loopSequence,omitempty"` // Restart the sequence once exhausted
+	Rules        []FixtureRule `json:"rules,omitempty"`        // Trigger or text matches, checked in order
+	Default      string        `json:"default,omitempty"`      // Reply when nothing matches (empty = error)
+	LatencyMs    int           `json:"latencyMs,omitempty"`    // Delay before every reply
+	FailureRate  float64       `json:"failureRate,omitempty"`  // Probability (0-1) that a call fails
+	FailureError string        `json:"failureError,omitempty"` // Error for injected failures (default: "busy")
+	Seed         int64         `json:"seed,omitempty"`         // Seed for failure injection (default: 1)
+	ContextSize  int           `json:"contextSize,omitempty"`  // Reported context window (default: 2048)
+
+	source string // File the fixture was loaded from
+}
+
+// FixtureRule maps a trigger or prompt text to scripted replies
+type FixtureRule struct {
+	Trigger   string   `json:"trigger,omitempty"`   // DialogContext trigger, e.g. "feed"
+	Contains  string   `json:"contains,omitempty"`  // Case-insensitive text in the current situation
+	Responses []string `json:"responses,omitempty"` // Replies returned in rotation
+	Error     string   `json:"error,omitempty"`     // Fail instead of replying
+	LatencyMs int      `json:"latencyMs,omitempty"` // Overrides the fixture latency
+}
+
+// FixtureStep is one scripted reply or failure in a sequence
+type FixtureStep struct {
+	Response  string `json:"response,omitempty"`
+	Error     string `json:"error,omitempty"`     // "timeout", "busy" or any error message
+	LatencyMs int    `json:"latencyMs,omitempty"` // Overrides the fixture latency
+}
+
+// FixtureModel is a ProductionLLMModel that replays responses from a ModelFixture
+// Unlike MockLLMModel its output depends only on the fixture and call order
+type FixtureModel struct {
+	fixture     ModelFixture
+	step        int
+	ruleCalls   []int
+	calls       int
+	rng         *rand.Rand
+	initialized bool
+	mu          sync.Mutex
+}
+
+// Ensure FixtureModel implements ProductionLLMModel
+var _ ProductionLLMModel = (*FixtureModel)(nil)
+
+// LoadModelFixture reads a ModelFixture from a JSON file
+func LoadModelFixture(path string) (ModelFixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ModelFixture{}, fmt.Errorf("failed to read model fixture: %w", err)
+	}
+
+	var fixture ModelFixture
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixture); err != nil {
+		return ModelFixture{}, fmt.Errorf("failed to parse model fixture %s: %w", path, err)
+	}
+	if err := fixture.validate(); err != nil {
+		return ModelFixture{}, fmt.Errorf("invalid model fixture %s: %w", path, err)
+	}
+	fixture.source = path
+	return fixture, nil
+}
+
+// validate checks the fixture can produce replies
+func (f ModelFixture) validate() error {
+	if f.FailureRate < 0 || f.FailureRate > 1 {
+		return fmt.Errorf("failureRate must be between 0 and 1")
+	}
+	if f.LatencyMs < 0 {
+		return fmt.Errorf("latencyMs must be non-negative")
+	}
+	for i, rule := range f.Rules {
+		if rule.Trigger == "" && rule.Contains == "" {
+			return fmt.Errorf("rule %d must set trigger or contains", i)
+		}
+		if len(rule.Responses) == 0 && rule.Error == "" {
+			return fmt.Errorf("rule %d must set responses or error", i)
+		}
+	}
+	return nil
+}
+
+// NewFixtureModel creates a model that replays the given fixture
+func NewFixtureModel(fixture ModelFixture) (*FixtureModel, error) {
+	if err := fixture.validate(); err != nil {
+		return nil, err
+	}
+	if fixture.Seed == 0 {
+		fixture.Seed = 1
+	}
+	if fixture.ContextSize <= 0 {
+		fixture.ContextSize = 2048
+	}
+	return &FixtureModel{
+		fixture:   fixture,
+		ruleCalls: make([]int, len(fixture.Rules)),
+		rng:       rand.New(rand.NewSource(fixture.Seed)),
+	}, nil
+}
+
+// Initialize marks the model ready
+func (m *FixtureModel) Initialize() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.initialized = true
+	return nil
+}
+
+// Predict returns the next scripted reply for the prompt
+func (m *FixtureModel) Predict(prompt string) (string, error) {
+	return m.PredictWithOptions(context.Background(), prompt, PredictOptions{})
+}
+
+// PredictWithTimeout returns the next scripted reply, honoring the context deadline
+func (m *FixtureModel) PredictWithTimeout(ctx context.Context, prompt string) (string, error) {
+	return m.PredictWithOptions(ctx, prompt, PredictOptions{})
+}
+
+// PredictWithOptions returns the next scripted reply; sampling options are ignored
+func (m *FixtureModel) PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error) {
+	response, failure, latency, err := m.next(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return "", fmt.Errorf("fixture prediction interrupted: %w", deadlineError(ctx))
+		}
+	}
+
+	if failure != "" {
+		return "", fixtureError(failure)
+	}
+	return response, nil
+}
+
+// next selects the reply, injected failure and latency for a call
+func (m *FixtureModel) next(prompt string) (string, string, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.initialized {
+		return "", "", 0, fmt.Errorf("fixture model %w", ErrNotInitialized)
+	}
+	m.calls++
+	latency := fixtureLatency(0, m.fixture.LatencyMs)
+
+	// Draw on every call so the failure pattern depends only on the call count
+	if m.rng.Float64() < m.fixture.FailureRate {
+		failure := m.fixture.FailureError
+		if failure == "" {
+			failure = "busy"
+		}
+		return "", failure, latency, nil
+	}
+
+	if m.fixture.LoopSequence && len(m.fixture.Sequence) > 0 && m.step >= len(m.fixture.Sequence) {
+		m.step = 0
+	}
+	if m.step < len(m.fixture.Sequence) {
+		step := m.fixture.Sequence[m.step]
+		m.step++
+		return step.Response, step.Error, fixtureLatency(step.LatencyMs, m.fixture.LatencyMs), nil
+	}
+
+	situation := extractCurrentSituation(prompt)
+	performed := performedAction(prompt)
+	for i, rule := range m.fixture.Rules {
+		if !rule.matches(situation, performed) {
+			continue
+		}
+		latency = fixtureLatency(rule.LatencyMs, m.fixture.LatencyMs)
+		if rule.Error != "" {
+			return "", rule.Error, latency, nil
+		}
+		response := rule.Responses[m.ruleCalls[i]%len(rule.Responses)]
+		m.ruleCalls[i]++
+		return response, "", latency, nil
+	}
+
+	if m.fixture.Default != "" {
+		return m.fixture.Default, "", latency, nil
+	}
+	return "", "no fixture response matches the prompt", latency, nil
+}
+
+// matches reports whether the rule applies to the prompt's current situation
+func (r FixtureRule) matches(situation, performed string) bool {
+	if r.Trigger != "" && performed != NewPromptBuilder().describeTrigger(r.Trigger) {
+		return false
+	}
+	return r.Contains == "" || strings.Contains(strings.ToLower(situation), strings.ToLower(r.Contains))
+}
+
+// performedAction returns the trigger description the prompt builder wrote for this request
+func performedAction(prompt string) string {
+	const marker = "- The user just performed: "
+	for _, line := range strings.Split(prompt, "\n") {
+		if action, found := strings.CutPrefix(strings.TrimSpace(line), marker); found {
+			return strings.TrimSpace(action)
+		}
+	}
+	return ""
+}
+
+// Calls returns how many predictions have been requested
+func (m *FixtureModel) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// Reset rewinds the sequence, rule rotation and failure injection to their initial state
+func (m *FixtureModel) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.step = 0
+	m.calls = 0
+	m.ruleCalls = make([]int, len(m.fixture.Rules))
+	m.rng = rand.New(rand.NewSource(m.fixture.Seed))
+}
+
+// EstimateTokens uses the same four-characters-per-token estimate as the mock model
+func (m *FixtureModel) EstimateTokens(text string) int {
+	return len(text) / 4
+}
+
+// GetContextSize returns the fixture's reported context size
+func (m *FixtureModel) GetContextSize() int {
+	return m.fixture.ContextSize
+}
+
+// GetModelInfo returns information about the fixture model
+func (m *FixtureModel) GetModelInfo() ModelInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path := "fixture://inline"
+	if m.fixture.source != "" {
+		path = "fixture://" + m.fixture.source
+	}
+	return ModelInfo{
+		ModelPath:   path,
+		ContextSize: m.fixture.ContextSize,
+		Threads:     1,
+		Initialized: m.initialized,
+		ModelType:   "fixture",
+		Backend:     "CPU",
+	}
+}
+
+// Free marks the model uninitialized
+func (m *FixtureModel) Free() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.initialized = false
+	return nil
+}
+
+// fixtureLatency returns the step or rule latency, falling back to the fixture default
+func fixtureLatency(override, fallback int) time.Duration {
+	if override > 0 {
+		return time.Duration(override) * time.Millisecond
+	}
+	return time.Duration(fallback) * time.Millisecond
+}
+
+// fixtureError maps fixture error names to the package's sentinel errors
+// so retries and fallbacks behave as they would for a real model
+func fixtureError(name string) error {
+	switch strings.ToLower(name) {
+	case "timeout":
+		return fmt.Errorf("fixture: %w", ErrTimeout)
+	case "busy":
+		return fmt.Errorf("fixture: %w", ErrBackendBusy)
+	default:
+		return errors.New(name)
+	}
+}
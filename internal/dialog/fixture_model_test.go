@@ -0,0 +1,224 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixturePrompt builds the prompt the LLM backend would send for a trigger
+func fixturePrompt(trigger string) string {
+	builder := NewPromptBuilder()
+	builder.AddContext(DialogContext{Trigger: trigger, CurrentMood: 70})
+	return builder.Build()
+}
+
+func newTestFixtureModel(t *testing.T, fixture ModelFixture) *FixtureModel {
+	t.Helper()
+	model, err := NewFixtureModel(fixture)
+	if err != nil {
+		t.Fatalf("NewFixtureModel() failed: %v", err)
+	}
+	model.Initialize()
+	return model
+}
+
+func writeFixture(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	return path
+}
+
+func TestFixtureModel_SequenceThenRules(t *testing.T) {
+	model := newTestFixtureModel(t, ModelFixture{
+		Sequence: []FixtureStep{{Response: "First!"}, {Error: "timeout"}},
+		Rules: []FixtureRule{
+			{Trigger: "feed", Responses: []string{"Yum!", "More please!"}},
+			{Contains: "petted", Responses: []string{"Purr"}},
+		},
+		Default: "Hello.",
+	})
+
+	expected := []struct {
+		trigger  string
+		response string
+		err      error
+	}{
+		{"click", "First!", nil},
+		{"click", "", ErrTimeout},
+		{"feed", "Yum!", nil},
+		{"feed", "More please!", nil},
+		{"feed", "Yum!", nil},
+		{"pet", "Purr", nil},
+		{"click", "Hello.", nil},
+	}
+	for i, want := range expected {
+		got, err := model.Predict(fixturePrompt(want.trigger))
+		if want.err != nil {
+			if !errors.Is(err, want.err) {
+				t.Errorf("Call %d: expected error %v, got %v", i, want.err, err)
+			}
+			continue
+		}
+		if err != nil || got != want.response {
+			t.Errorf("Call %d: expected %q, got %q (err %v)", i, want.response, got, err)
+		}
+	}
+
+	if model.Calls() != len(expected) {
+		t.Errorf("Expected %d calls, got %d", len(expected), model.Calls())
+	}
+}
+
+func TestFixtureModel_TriggerMatchIsExact(t *testing.T) {
+	model := newTestFixtureModel(t, ModelFixture{
+		Rules: []FixtureRule{{Trigger: "click", Responses: []string{"Click!"}}},
+	})
+
+	if _, err := model.Predict(fixturePrompt("rightclick")); err == nil {
+		t.Error("Expected rightclick not to match a click rule")
+	}
+	if got, _ := model.Predict(fixturePrompt("click")); got != "Click!" {
+		t.Errorf("Expected click rule to match, got %q", got)
+	}
+}
+
+func TestFixtureModel_LoopSequenceAndReset(t *testing.T) {
+	model := newTestFixtureModel(t, ModelFixture{
+		Sequence:     []FixtureStep{{Response: "A"}, {Response: "B"}},
+		LoopSequence: true,
+	})
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		response, _ := model.Predict(fixturePrompt("click"))
+		got = append(got, response)
+	}
+	if strings.Join(got, "") != "ABA" {
+		t.Errorf("Expected looping sequence ABA, got %v", got)
+	}
+
+	model.Reset()
+	if response, _ := model.Predict(fixturePrompt("click")); response != "A" {
+		t.Errorf("Expected Reset to rewind the sequence, got %q", response)
+	}
+}
+
+func TestFixtureModel_FailureInjectionIsDeterministic(t *testing.T) {
+	fixture := ModelFixture{Default: "ok", FailureRate: 0.5, Seed: 7}
+
+	pattern := func() string {
+		model := newTestFixtureModel(t, fixture)
+		var result strings.Builder
+		for i := 0; i < 20; i++ {
+			if _, err := model.Predict(fixturePrompt("click")); err != nil {
+				if !errors.Is(err, ErrBackendBusy) {
+					t.Fatalf("Expected injected failures to be ErrBackendBusy, got %v", err)
+				}
+				result.WriteByte('x')
+			} else {
+				result.WriteByte('.')
+			}
+		}
+		return result.String()
+	}
+
+	first, second := pattern(), pattern()
+	if first != second {
+		t.Errorf("Expected identical failure patterns for the same seed, got %s and %s", first, second)
+	}
+	if !strings.Contains(first, "x") || !strings.Contains(first, ".") {
+		t.Errorf("Expected a mix of failures and successes, got %s", first)
+	}
+}
+
+func TestFixtureModel_LatencyHonorsContext(t *testing.T) {
+	model := newTestFixtureModel(t, ModelFixture{Default: "slow", LatencyMs: 500})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := model.PredictWithTimeout(ctx, fixturePrompt("click")); err == nil {
+		t.Error("Expected prediction to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("Expected cancellation to interrupt latency, took %v", elapsed)
+	}
+}
+
+func TestLoadModelFixture_Validation(t *testing.T) {
+	if _, err := LoadModelFixture(writeFixture(t, `{"default": "hi", "unknown": 1}`)); err == nil {
+		t.Error("Expected unknown fields to be rejected")
+	}
+	if _, err := LoadModelFixture(writeFixture(t, `{"rules": [{"responses": ["hi"]}]}`)); err == nil {
+		t.Error("Expected a rule without trigger or contains to be rejected")
+	}
+	if _, err := LoadModelFixture(writeFixture(t, `{"failureRate": 2}`)); err == nil {
+		t.Error("Expected failureRate above 1 to be rejected")
+	}
+
+	fixture, err := LoadModelFixture(writeFixture(t, `{"rules": [{"trigger": "feed", "responses": ["Yum!"]}]}`))
+	if err != nil {
+		t.Fatalf("LoadModelFixture() failed: %v", err)
+	}
+	model := newTestFixtureModel(t, fixture)
+	if info := model.GetModelInfo(); info.ModelType != "fixture" || !strings.HasPrefix(info.ModelPath, "fixture://") {
+		t.Errorf("Expected fixture model info, got %+v", info)
+	}
+}
+
+func TestLLMBackend_MockFixtureConfig(t *testing.T) {
+	path := writeFixture(t, `{
+		"sequence": [{"error": "model exploded"}],
+		"rules": [{"trigger": "feed", "responses": ["Thanks for the snack!"]}]
+	}`)
+
+	configJSON, _ := json.Marshal(LLMConfig{
+		ModelPath:       "/models/missing.gguf",
+		MockFixture:     path,
+		FallbackEnabled: true,
+		MarkovConfig:    MarkovChainConfig{FallbackPhrases: []string{"Fallback line"}},
+	})
+	backend := NewLLMBackend()
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	defer backend.Close()
+
+	context := DialogContext{Trigger: "feed", InteractionID: "fixture", FallbackResponses: []string{"Fallback line"}}
+
+	first, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("Expected fallback instead of error, got %v", err)
+	}
+	if first.Text == "Thanks for the snack!" {
+		t.Error("Expected the scripted failure to produce a fallback response")
+	}
+
+	second, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.Text != "Thanks for the snack!" {
+		t.Errorf("Expected fixture response, got %q", second.Text)
+	}
+}
+
+func TestLLMBackend_MockFixtureMissingFile(t *testing.T) {
+	configJSON, _ := json.Marshal(LLMConfig{
+		ModelPath:   "/models/missing.gguf",
+		MockFixture: filepath.Join(t.TempDir(), "missing.json"),
+	})
+	if err := NewLLMBackend().Initialize(configJSON); err == nil {
+		t.Error("Expected a missing fixture file to fail initialization")
+	}
+}
@@ -0,0 +1,512 @@
+package dialog
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Grammar is a parsed GBNF grammar, the format llama.cpp uses to constrain sampling
+// Production models receive the source through PredictOptions.Grammar; Match checks
+// output against it for models without grammar support
+type Grammar struct {
+	source string
+	rules  map[string]*grammarNode
+}
+
+// grammarNodeKind identifies the expression a grammarNode represents
+type grammarNodeKind int
+
+const (
+	grammarLiteral grammarNodeKind = iota
+	grammarClass
+	grammarAny
+	grammarRef
+	grammarSequence
+	grammarChoice
+	grammarRepeat
+)
+
+// grammarNode is one expression in a grammar rule
+type grammarNode struct {
+	kind     grammarNodeKind
+	text     []rune         // grammarLiteral
+	ranges   [][2]rune      // grammarClass, inclusive
+	negated  bool           // grammarClass
+	name     string         // grammarRef
+	children []*grammarNode // grammarSequence and grammarChoice; grammarRepeat has one
+	min, max int            // grammarRepeat bounds (max -1 = unbounded)
+}
+
+// ParseGrammar parses GBNF source, which must define a root rule
+// Left-recursive rules are not supported, as in llama.cpp
+func ParseGrammar(source string) (*Grammar, error) {
+	p := &grammarParser{src: []rune(source)}
+	rules := make(map[string]*grammarNode)
+
+	for p.skipSpace(); !p.done(); p.skipSpace() {
+		name := p.parseName()
+		if name == "" {
+			return nil, p.errorf("expected rule name")
+		}
+		p.skipSpace()
+		if !p.consume("::=") {
+			return nil, p.errorf("expected ::= after rule %q", name)
+		}
+		node, err := p.parseChoice()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := rules[name]; exists {
+			return nil, fmt.Errorf("grammar rule %q is defined twice", name)
+		}
+		rules[name] = node
+	}
+
+	if rules["root"] == nil {
+		return nil, fmt.Errorf("grammar must define a root rule")
+	}
+	for _, node := range rules {
+		if name := undefinedReference(node, rules); name != "" {
+			return nil, fmt.Errorf("grammar references undefined rule %q", name)
+		}
+	}
+	return &Grammar{source: source, rules: rules}, nil
+}
+
+// LoadGrammarFile reads and parses a .gbnf file
+func LoadGrammarFile(path string) (*Grammar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grammar: %w", err)
+	}
+	grammar, err := ParseGrammar(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid grammar %s: %w", path, err)
+	}
+	return grammar, nil
+}
+
+// String returns the GBNF source the grammar was parsed from
+func (g *Grammar) String() string {
+	return g.source
+}
+
+// Match reports whether the root rule matches the whole of text
+func (g *Grammar) Match(text string) bool {
+	m := &grammarMatcher{rules: g.rules, input: []rune(text), memo: make(map[grammarMemoKey][]int)}
+	for _, end := range m.ends(g.rules["root"], 0) {
+		if end == len(m.input) {
+			return true
+		}
+	}
+	return false
+}
+
+// undefinedReference returns the first rule name node refers to that is not defined
+func undefinedReference(node *grammarNode, rules map[string]*grammarNode) string {
+	if node.kind == grammarRef && rules[node.name] == nil {
+		return node.name
+	}
+	for _, child := range node.children {
+		if name := undefinedReference(child, rules); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// grammarParser is a recursive descent parser for GBNF
+type grammarParser struct {
+	src []rune
+	pos int
+}
+
+func (p *grammarParser) done() bool { return p.pos >= len(p.src) }
+
+func (p *grammarParser) peek() rune {
+	if p.done() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// consume advances past token when the input continues with it
+func (p *grammarParser) consume(token string) bool {
+	runes := []rune(token)
+	if len(p.src)-p.pos < len(runes) || string(p.src[p.pos:p.pos+len(runes)]) != token {
+		return false
+	}
+	p.pos += len(runes)
+	return true
+}
+
+// skipSpace skips whitespace, including newlines, and # comments
+func (p *grammarParser) skipSpace() {
+	for !p.done() {
+		switch r := p.peek(); {
+		case r == '#':
+			for !p.done() && p.peek() != '\n' {
+				p.pos++
+			}
+		case unicode.IsSpace(r):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseName reads a rule name, returning "" when none is present
+func (p *grammarParser) parseName() string {
+	start := p.pos
+	for !p.done() && (p.peek() == '-' || p.peek() == '_' || unicode.IsLetter(p.peek()) || unicode.IsDigit(p.peek())) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// atRuleStart reports whether the input continues with "name ::=", ending the current rule
+func (p *grammarParser) atRuleStart() bool {
+	start := p.pos
+	defer func() { p.pos = start }()
+	if p.parseName() == "" {
+		return false
+	}
+	p.skipSpace()
+	return p.consume("::=")
+}
+
+// errorf reports a syntax error at the current line
+func (p *grammarParser) errorf(format string, args ...any) error {
+	line := 1 + strings.Count(string(p.src[:p.pos]), "\n")
+	return fmt.Errorf("grammar line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// parseChoice parses alternatives separated by |
+func (p *grammarParser) parseChoice() (*grammarNode, error) {
+	var alternatives []*grammarNode
+	for {
+		sequence, err := p.parseSequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, sequence)
+		p.skipSpace()
+		if !p.consume("|") {
+			break
+		}
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return &grammarNode{kind: grammarChoice, children: alternatives}, nil
+}
+
+// parseSequence parses terms up to the next |, ) or rule definition
+func (p *grammarParser) parseSequence() (*grammarNode, error) {
+	sequence := &grammarNode{kind: grammarSequence}
+	for {
+		p.skipSpace()
+		if p.done() || p.peek() == '|' || p.peek() == ')' || p.atRuleStart() {
+			return sequence, nil
+		}
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		sequence.children = append(sequence.children, term)
+	}
+}
+
+// parseTerm parses an atom and an optional repetition operator
+func (p *grammarParser) parseTerm() (*grammarNode, error) {
+	atom, err := p.parseAtom()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.consume("*"):
+		return &grammarNode{kind: grammarRepeat, children: []*grammarNode{atom}, min: 0, max: -1}, nil
+	case p.consume("+"):
+		return &grammarNode{kind: grammarRepeat, children: []*grammarNode{atom}, min: 1, max: -1}, nil
+	case p.consume("?"):
+		return &grammarNode{kind: grammarRepeat, children: []*grammarNode{atom}, min: 0, max: 1}, nil
+	case p.peek() == '{':
+		minCount, maxCount, err := p.parseBounds()
+		if err != nil {
+			return nil, err
+		}
+		return &grammarNode{kind: grammarRepeat, children: []*grammarNode{atom}, min: minCount, max: maxCount}, nil
+	}
+	return atom, nil
+}
+
+// parseBounds parses {m}, {m,} or {m,n}
+func (p *grammarParser) parseBounds() (int, int, error) {
+	end := p.pos
+	for end < len(p.src) && p.src[end] != '}' {
+		end++
+	}
+	if end == len(p.src) {
+		return 0, 0, p.errorf("unterminated repetition bounds")
+	}
+	body := strings.ReplaceAll(string(p.src[p.pos+1:end]), " ", "")
+	first, last, ranged := strings.Cut(body, ",")
+
+	minCount, err := strconv.Atoi(first)
+	if err != nil || minCount < 0 {
+		return 0, 0, p.errorf("invalid repetition bounds {%s}", body)
+	}
+	maxCount := minCount
+	if ranged {
+		maxCount = -1
+		if last != "" {
+			if maxCount, err = strconv.Atoi(last); err != nil || maxCount < minCount {
+				return 0, 0, p.errorf("invalid repetition bounds {%s}", body)
+			}
+		}
+	}
+	p.pos = end + 1
+	return minCount, maxCount, nil
+}
+
+// parseAtom parses a literal, character class, group, wildcard or rule reference
+func (p *grammarParser) parseAtom() (*grammarNode, error) {
+	switch {
+	case p.consume(`"`):
+		return p.parseLiteral()
+	case p.consume("["):
+		return p.parseClass()
+	case p.consume("("):
+		group, err := p.parseChoice()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+		return group, nil
+	case p.consume("."):
+		return &grammarNode{kind: grammarAny}, nil
+	}
+
+	if name := p.parseName(); name != "" {
+		return &grammarNode{kind: grammarRef, name: name}, nil
+	}
+	return nil, p.errorf("unexpected %q", p.peek())
+}
+
+// parseLiteral parses a quoted string after its opening quote
+func (p *grammarParser) parseLiteral() (*grammarNode, error) {
+	var text []rune
+	for !p.consume(`"`) {
+		if p.done() {
+			return nil, p.errorf("unterminated string")
+		}
+		r, err := p.parseChar()
+		if err != nil {
+			return nil, err
+		}
+		text = append(text, r)
+	}
+	return &grammarNode{kind: grammarLiteral, text: text}, nil
+}
+
+// parseClass parses a character class such as [a-z0-9_] or [^"] after its opening bracket
+func (p *grammarParser) parseClass() (*grammarNode, error) {
+	class := &grammarNode{kind: grammarClass, negated: p.consume("^")}
+	for !p.consume("]") {
+		if p.done() {
+			return nil, p.errorf("unterminated character class")
+		}
+		low, err := p.parseChar()
+		if err != nil {
+			return nil, err
+		}
+		high := low
+		if p.peek() == '-' && p.pos+1 < len(p.src) && p.src[p.pos+1] != ']' {
+			p.pos++
+			if high, err = p.parseChar(); err != nil {
+				return nil, err
+			}
+		}
+		class.ranges = append(class.ranges, [2]rune{low, high})
+	}
+	return class, nil
+}
+
+// parseChar reads one possibly escaped character inside a literal or class
+func (p *grammarParser) parseChar() (rune, error) {
+	r := p.peek()
+	p.pos++
+	if r != '\\' {
+		return r, nil
+	}
+	if p.done() {
+		return 0, p.errorf("unterminated escape")
+	}
+
+	escape := p.peek()
+	p.pos++
+	switch escape {
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'x', 'u', 'U':
+		digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[escape]
+		if len(p.src)-p.pos < digits {
+			return 0, p.errorf("incomplete \\%c escape", escape)
+		}
+		value, err := strconv.ParseUint(string(p.src[p.pos:p.pos+digits]), 16, 32)
+		if err != nil {
+			return 0, p.errorf("invalid \\%c escape", escape)
+		}
+		p.pos += digits
+		return rune(value), nil
+	default:
+		return escape, nil // \\ \" \[ \] \- and other literal escapes
+	}
+}
+
+// grammarMemoKey identifies a rule matched from an input position
+type grammarMemoKey struct {
+	rule string
+	pos  int
+}
+
+// grammarMatcher finds every input position a grammar expression can end at
+type grammarMatcher struct {
+	rules map[string]*grammarNode
+	input []rune
+	memo  map[grammarMemoKey][]int
+}
+
+// ends returns the sorted positions where node can finish when started at pos
+func (m *grammarMatcher) ends(node *grammarNode, pos int) []int {
+	switch node.kind {
+	case grammarLiteral:
+		if len(m.input)-pos >= len(node.text) && string(m.input[pos:pos+len(node.text)]) == string(node.text) {
+			return []int{pos + len(node.text)}
+		}
+	case grammarClass:
+		if pos < len(m.input) && node.classMatches(m.input[pos]) {
+			return []int{pos + 1}
+		}
+	case grammarAny:
+		if pos < len(m.input) {
+			return []int{pos + 1}
+		}
+	case grammarRef:
+		key := grammarMemoKey{node.name, pos}
+		if cached, ok := m.memo[key]; ok {
+			return cached
+		}
+		m.memo[key] = nil // Stops left recursion
+		result := m.ends(m.rules[node.name], pos)
+		m.memo[key] = result
+		return result
+	case grammarSequence:
+		positions := []int{pos}
+		for _, child := range node.children {
+			if positions = m.endsFrom(child, positions); len(positions) == 0 {
+				return nil
+			}
+		}
+		return positions
+	case grammarChoice:
+		var result []int
+		for _, child := range node.children {
+			result = append(result, m.ends(child, pos)...)
+		}
+		return uniquePositions(result)
+	case grammarRepeat:
+		return m.repeatEnds(node, pos)
+	}
+	return nil
+}
+
+// endsFrom returns the positions node can finish at when started from any of starts
+func (m *grammarMatcher) endsFrom(node *grammarNode, starts []int) []int {
+	var result []int
+	for _, start := range starts {
+		result = append(result, m.ends(node, start)...)
+	}
+	return uniquePositions(result)
+}
+
+// repeatEnds matches between min and max repetitions of the node's child
+func (m *grammarMatcher) repeatEnds(node *grammarNode, pos int) []int {
+	seen := make(map[int]bool)
+	var result []int
+	if node.min == 0 {
+		seen[pos] = true
+		result = append(result, pos)
+	}
+
+	current := []int{pos}
+	for count := 1; node.max < 0 || count <= node.max; count++ {
+		next := m.endsFrom(node.children[0], current)
+		if count >= node.min {
+			// Positions already reached with fewer repetitions add nothing new
+			fresh := next[:0]
+			for _, end := range next {
+				if !seen[end] {
+					seen[end] = true
+					fresh = append(fresh, end)
+				}
+			}
+			next = fresh
+			result = append(result, next...)
+		}
+		if len(next) == 0 {
+			break
+		}
+		current = next
+	}
+	return uniquePositions(result)
+}
+
+// classMatches reports whether r is in the character class
+func (n *grammarNode) classMatches(r rune) bool {
+	for _, span := range n.ranges {
+		if r >= span[0] && r <= span[1] {
+			return !n.negated
+		}
+	}
+	return n.negated
+}
+
+// uniquePositions sorts positions and removes duplicates
+func uniquePositions(positions []int) []int {
+	sort.Ints(positions)
+	unique := positions[:0]
+	for i, position := range positions {
+		if i == 0 || position != positions[i-1] {
+			unique = append(unique, position)
+		}
+	}
+	return unique
+}
+
+// GrammarValidator rejects responses the grammar does not match
+// It guards models that cannot constrain sampling themselves
+type GrammarValidator struct {
+	Grammar *Grammar
+}
+
+// Validate matches the whole response against the grammar's root rule
+func (v GrammarValidator) Validate(ctx DialogContext, response string) error {
+	if v.Grammar != nil && !v.Grammar.Match(response) {
+		return fmt.Errorf("response does not match the grammar")
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package dialog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const moodGrammar = `# A mood report such as {"mood": "happy", "energy": 7}
+root   ::= "{" ws "\"mood\":" ws mood "," ws "\"energy\":" ws energy ws "}"
+mood   ::= "\"" ("happy" | "sleepy" | "hungry") "\""
+energy ::= [0-9] | "10"
+ws     ::= [ \t\n]*
+`
+
+func TestParseGrammar_Match(t *testing.T) {
+	grammar, err := ParseGrammar(moodGrammar)
+	if err != nil {
+		t.Fatalf("ParseGrammar() failed: %v", err)
+	}
+
+	tests := []struct {
+		text  string
+		match bool
+	}{
+		{`{"mood": "happy", "energy": 7}`, true},
+		{"{\n  \"mood\":\"sleepy\",\"energy\": 10\n}", true},
+		{`{"mood": "angry", "energy": 7}`, false},
+		{`{"mood": "happy", "energy": 11}`, false},
+		{`{"mood": "happy", "energy": 7} trailing`, false},
+		{`I'm happy!`, false},
+	}
+	for _, tt := range tests {
+		if got := grammar.Match(tt.text); got != tt.match {
+			t.Errorf("Match(%q) = %v, expected %v", tt.text, got, tt.match)
+		}
+	}
+	if grammar.String() != moodGrammar {
+		t.Error("Expected String to return the grammar source")
+	}
+}
+
+func TestParseGrammar_Operators(t *testing.T) {
+	tests := []struct {
+		source string
+		text   string
+		match  bool
+	}{
+		{`root ::= "a"+ "b"?`, "aaa", true},
+		{`root ::= "a"+ "b"?`, "b", false},
+		{`root ::= [a-c]{2,3}`, "abc", true},
+		{`root ::= [a-c]{2,3}`, "a", false},
+		{`root ::= [a-c]{2,3}`, "abca", false},
+		{`root ::= [a-c]{2,}`, "abcabc", true},
+		{`root ::= "x"{2}`, "xx", true},
+		{`root ::= [^"]* "!"`, "hi there!", true},
+		{`root ::= [^"]* "!"`, `say "hi"!`, false},
+		{`root ::= . . .`, "é😊x", true},
+		{`root ::= "\x41é\n"`, "Aé\n", true},
+		{`root ::= ("ha" | "he")* "!"`, "hahehe!", true},
+		{"root ::= greeting\n  \" friend\"\ngreeting ::= \"hi\" | \"hello\"", "hello friend", true},
+		{`root ::= ("a"?)* "b"`, "b", true},
+		{`root ::= | "a"`, "", true},
+	}
+	for _, tt := range tests {
+		grammar, err := ParseGrammar(tt.source)
+		if err != nil {
+			t.Errorf("ParseGrammar(%q) failed: %v", tt.source, err)
+			continue
+		}
+		if got := grammar.Match(tt.text); got != tt.match {
+			t.Errorf("%s: Match(%q) = %v, expected %v", tt.source, tt.text, got, tt.match)
+		}
+	}
+}
+
+func TestParseGrammar_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing root":       `greeting ::= "hi"`,
+		"undefined rule":     `root ::= greeting`,
+		"duplicate rule":     "root ::= \"a\"\nroot ::= \"b\"",
+		"missing ::=":        `root "a"`,
+		"unterminated":       `root ::= "abc`,
+		"unterminated class": `root ::= [a-z`,
+		"unclosed group":     `root ::= ("a" | "b"`,
+		"bad bounds":         `root ::= "a"{3,1}`,
+		"bad escape":         `root ::= "\xZZ"`,
+	}
+	for name, source := range tests {
+		if _, err := ParseGrammar(source); err == nil {
+			t.Errorf("%s: expected ParseGrammar(%q) to fail", name, source)
+		}
+	}
+
+	_, err := ParseGrammar("root ::= \"a\"\n\nroot2 ::= @")
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected the error to name line 3, got %v", err)
+	}
+}
+
+func TestLoadGrammarFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mood.gbnf")
+	if err := os.WriteFile(path, []byte(moodGrammar), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGrammarFile(path); err != nil {
+		t.Errorf("LoadGrammarFile() failed: %v", err)
+	}
+	if _, err := LoadGrammarFile(filepath.Join(t.TempDir(), "missing.gbnf")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestLLMBackend_Grammar(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{
+		"I feel happy!",
+		`{"mood": "happy", "energy": 7}`,
+	}}
+	backend := newScriptedBackend(t, LLMConfig{Grammar: moodGrammar, FallbackEnabled: false}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "status"})
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+	if response.Text != `{"mood": "happy", "energy": 7}` {
+		t.Errorf("Expected the matching response to be kept verbatim, got %q", response.Text)
+	}
+	if model.callCount() != 2 || model.calls[0].Grammar != moodGrammar {
+		t.Errorf("Expected the grammar passed to the model and a regeneration, got %+v", model.calls)
+	}
+}
+
+func TestLLMBackend_GrammarConfigErrors(t *testing.T) {
+	configs := map[string]string{
+		"invalid grammar": `{"modelPath": "/fake/path.gguf", "grammar": "root ::= missing"}`,
+		"both sources":    `{"modelPath": "/fake/path.gguf", "grammar": "root ::= \"a\"", "grammarFile": "a.gbnf"}`,
+		"missing file":    `{"modelPath": "/fake/path.gguf", "grammarFile": "/nonexistent/a.gbnf"}`,
+	}
+	for name, config := range configs {
+		backend := NewLLMBackend()
+		if err := backend.Initialize([]byte(config)); err == nil {
+			t.Errorf("%s: expected Initialize to fail", name)
+			backend.Close()
+		}
+	}
+}
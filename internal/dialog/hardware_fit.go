@@ -0,0 +1,343 @@
+package dialog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrInsufficientMemory indicates a model would not fit in available system memory
+var ErrInsufficientMemory = errors.New("model does not fit in available memory")
+
+// Hardware fit warning codes reported in HardwareWarning.Code
+const (
+	WarningInsufficientMemory  = "insufficient_memory"  // Estimated RAM exceeds available memory
+	WarningLowMemoryHeadroom   = "low_memory_headroom"  // Estimated RAM uses most of available memory
+	WarningThreadsExceedCPUs   = "threads_exceed_cpus"  // More inference threads than logical CPUs
+	WarningUnquantizedModel    = "unquantized_model"    // Full-precision weights are slow and large on CPU
+	WarningUnknownQuantization = "unknown_quantization" // Neither metadata nor file name gives the quantization
+	WarningUnreadableMetadata  = "unreadable_metadata"  // GGUF header could not be parsed; estimates are rough
+	WarningContextTooLong      = "context_too_long"     // Configured contextSize exceeds the model's trained context and was clamped
+)
+
+// Memory estimation and GGUF parsing parameters
+const (
+	memoryHeadroomRatio    = 0.8     // Share of available memory above which headroom is low
+	computeOverheadRatio   = 0.1     // Scratch buffers relative to weight size
+	kvBytesPerElement      = 2       // KV cache entries are f16
+	maxGGUFStringLength    = 1 << 20 // Longest metadata string accepted when parsing
+	maxGGUFMetadataEntries = 1 << 16 // Most metadata entries accepted when parsing
+	ggufMagic              = "GGUF"  // File signature of GGUF models
+	ggufStringType         = 8       // GGUF metadata value type for strings
+	ggufArrayType          = 9       // GGUF metadata value type for arrays
+)
+
+// ModelFileInfo describes a model file as read from its GGUF header
+// Fields the header does not provide are left at their zero value
+type ModelFileInfo struct {
+	Path            string `json:"path"`
+	SizeBytes       int64  `json:"sizeBytes"`
+	Architecture    string `json:"architecture,omitempty"`    // e.g. "llama", "phi3"
+	Type            string `json:"type,omitempty"`            // "model" or "adapter", when the file says
+	AdapterType     string `json:"adapterType,omitempty"`     // e.g. "lora", for adapters
+	Quantization    string `json:"quantization,omitempty"`    // e.g. "Q4_K_M", "F16"
+	Layers          int    `json:"layers,omitempty"`          // Transformer blocks
+	EmbeddingLength int    `json:"embeddingLength,omitempty"` // Hidden size
+	ContextLength   int    `json:"contextLength,omitempty"`   // Context window the model was trained with
+}
+
+// HardwareWarning is one structured finding from a hardware fit check
+type HardwareWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// HardwareFitReport compares a model's estimated requirements with the host machine
+type HardwareFitReport struct {
+	Model             ModelFileInfo     `json:"model"`
+	EstimatedRAMBytes uint64            `json:"estimatedRamBytes"`
+	AvailableRAMBytes uint64            `json:"availableRamBytes"` // 0 when the platform does not report it
+	Threads           int               `json:"threads"`
+	CPUs              int               `json:"cpus"`
+	Warnings          []HardwareWarning `json:"warnings,omitempty"`
+}
+
+// Fits reports whether the model is expected to fit in available memory
+// An unknown amount of available memory is treated as fitting
+func (r HardwareFitReport) Fits() bool {
+	return r.AvailableRAMBytes == 0 || r.EstimatedRAMBytes <= r.AvailableRAMBytes
+}
+
+// Err returns an error wrapping ErrInsufficientMemory when the model does not fit, nil otherwise
+func (r HardwareFitReport) Err() error {
+	if r.Fits() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s needs about %d MB, %d MB available",
+		ErrInsufficientMemory, r.Model.Path, r.EstimatedRAMBytes>>20, r.AvailableRAMBytes>>20)
+}
+
+// HardwareFitReporter is implemented by models and backends that check whether their model
+// fits the host machine; ok is false when no check was made (e.g. mock models)
+type HardwareFitReporter interface {
+	HardwareFit() (report HardwareFitReport, ok bool)
+}
+
+// availableMemory reports free system memory in bytes, replaceable in tests
+var availableMemory = systemAvailableMemory
+
+// systemAvailableMemory reads MemAvailable from /proc/meminfo, returning 0 where unavailable
+func systemAvailableMemory() uint64 {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// CheckHardwareFit inspects a model file and compares its estimated RAM needs for the given
+// context size with available memory, and the thread count with available CPUs
+func CheckHardwareFit(modelPath string, contextSize, threads int) (HardwareFitReport, error) {
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		return HardwareFitReport{}, fmt.Errorf("%w: %s", ErrModelNotFound, modelPath)
+	} else if err != nil {
+		return HardwareFitReport{}, fmt.Errorf("failed to inspect model file: %w", err)
+	}
+	info, err := InspectModelFile(modelPath)
+
+	report := HardwareFitReport{
+		Model:             info,
+		EstimatedRAMBytes: estimateModelRAM(info, contextSize),
+		AvailableRAMBytes: availableMemory(),
+		Threads:           threads,
+		CPUs:              runtime.NumCPU(),
+	}
+	warn := func(code, format string, args ...interface{}) {
+		report.Warnings = append(report.Warnings, HardwareWarning{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err != nil {
+		warn(WarningUnreadableMetadata, "could not read GGUF metadata (%v); RAM estimate is based on file size only", err)
+	}
+	switch quantization := info.Quantization; {
+	case quantization == "":
+		warn(WarningUnknownQuantization, "quantization of %s is unknown", info.Path)
+	case quantization == "F32" || quantization == "F16" || quantization == "BF16":
+		warn(WarningUnquantizedModel, "%s weights are unquantized; a Q4 or Q8 model is smaller and faster on CPU", quantization)
+	}
+
+	if available := report.AvailableRAMBytes; available > 0 {
+		estimated := report.EstimatedRAMBytes
+		switch {
+		case estimated > available:
+			warn(WarningInsufficientMemory, "model needs about %d MB but only %d MB is available", estimated>>20, available>>20)
+		case float64(estimated) > memoryHeadroomRatio*float64(available):
+			warn(WarningLowMemoryHeadroom, "model needs about %d MB of %d MB available; the system may swap", estimated>>20, available>>20)
+		}
+	}
+	if threads > report.CPUs {
+		warn(WarningThreadsExceedCPUs, "%d threads configured but only %d CPUs available", threads, report.CPUs)
+	}
+	return report, nil
+}
+
+// estimateModelRAM approximates resident memory: mapped weights, an f16 KV cache for the
+// context and scratch buffers. Grouped-query attention makes the KV estimate an upper bound
+func estimateModelRAM(info ModelFileInfo, contextSize int) uint64 {
+	weights := uint64(max(info.SizeBytes, 0))
+	kvCache := uint64(2 * info.Layers * contextSize * info.EmbeddingLength * kvBytesPerElement)
+	return weights + kvCache + uint64(float64(weights)*computeOverheadRatio)
+}
+
+// InspectModelFile reads the size and GGUF metadata of a model file
+// When the header cannot be parsed the returned info still carries the size and any
+// quantization named in the file name, alongside the parse error
+func InspectModelFile(path string) (ModelFileInfo, error) {
+	info := ModelFileInfo{Path: path}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return info, fmt.Errorf("failed to inspect model file: %w", err)
+	}
+	info.SizeBytes = stat.Size()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return info, fmt.Errorf("failed to open model file: %w", err)
+	}
+	defer file.Close()
+
+	metadataErr := readGGUFMetadata(bufio.NewReader(file), &info)
+	if info.Quantization == "" {
+		info.Quantization = quantizationFromFilename(path)
+	}
+	return info, metadataErr
+}
+
+// ggufFileTypes names the general.file_type values written by llama.cpp
+var ggufFileTypes = map[uint64]string{
+	0: "F32", 1: "F16", 2: "Q4_0", 3: "Q4_1", 7: "Q8_0", 8: "Q5_0", 9: "Q5_1",
+	10: "Q2_K", 11: "Q3_K_S", 12: "Q3_K_M", 13: "Q3_K_L", 14: "Q4_K_S", 15: "Q4_K_M",
+	16: "Q5_K_S", 17: "Q5_K_M", 18: "Q6_K", 19: "IQ2_XXS", 20: "IQ2_XS", 21: "Q2_K_S",
+	22: "IQ3_XS", 23: "IQ3_XXS", 24: "IQ1_S", 25: "IQ4_NL", 26: "IQ3_S", 27: "IQ3_M",
+	28: "IQ2_S", 29: "IQ2_M", 30: "IQ4_XS", 31: "IQ1_M", 32: "BF16",
+}
+
+// quantizationPattern matches quantization labels in model file names, e.g. "model.Q4_K_M.gguf"
+var quantizationPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(iq\d_[a-z]+|q\d_k(?:_[sml])?|q\d_\d|bf16|f16|f32)(?:[^a-z0-9]|$)`)
+
+// quantizationFromFilename extracts a quantization label from a model file name
+func quantizationFromFilename(path string) string {
+	name := path[strings.LastIndexAny(path, `/\`)+1:]
+	if match := quantizationPattern.FindStringSubmatch(name); match != nil {
+		return strings.ToUpper(match[1])
+	}
+	return ""
+}
+
+// readGGUFMetadata parses the GGUF header and fills architecture, quantization and shape fields
+func readGGUFMetadata(r *bufio.Reader, info *ModelFileInfo) error {
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != ggufMagic {
+		return fmt.Errorf("not a GGUF file")
+	}
+
+	var header struct {
+		Version     uint32
+		TensorCount uint64
+		KVCount     uint64
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return fmt.Errorf("failed to read GGUF header: %w", err)
+	}
+	if header.Version < 2 {
+		return fmt.Errorf("unsupported GGUF version %d", header.Version)
+	}
+	if header.KVCount > maxGGUFMetadataEntries {
+		return fmt.Errorf("GGUF header declares %d metadata entries", header.KVCount)
+	}
+
+	numbers := make(map[string]uint64)
+	for i := uint64(0); i < header.KVCount; i++ {
+		key, err := readGGUFString(r)
+		if err != nil {
+			return err
+		}
+		var valueType uint32
+		if err := binary.Read(r, binary.LittleEndian, &valueType); err != nil {
+			return fmt.Errorf("failed to read GGUF metadata %s: %w", key, err)
+		}
+
+		if valueType == ggufStringType {
+			value, err := readGGUFString(r)
+			if err != nil {
+				return err
+			}
+			switch key {
+			case "general.architecture":
+				info.Architecture = value
+			case "general.type":
+				info.Type = value
+			case "adapter.type":
+				info.AdapterType = value
+			}
+			continue
+		}
+		number, err := readGGUFValue(r, valueType)
+		if err != nil {
+			return fmt.Errorf("failed to read GGUF metadata %s: %w", key, err)
+		}
+		numbers[key] = number
+	}
+
+	if fileType, ok := numbers["general.file_type"]; ok {
+		info.Quantization = ggufFileTypes[fileType]
+	}
+	info.Layers = int(numbers[info.Architecture+".block_count"])
+	info.EmbeddingLength = int(numbers[info.Architecture+".embedding_length"])
+	info.ContextLength = int(numbers[info.Architecture+".context_length"])
+	return nil
+}
+
+// readGGUFString reads a length-prefixed GGUF string
+func readGGUFString(r *bufio.Reader) (string, error) {
+	var length uint64
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return "", fmt.Errorf("failed to read GGUF string: %w", err)
+	}
+	if length > maxGGUFStringLength {
+		return "", fmt.Errorf("GGUF string of %d bytes exceeds limit", length)
+	}
+	value := make([]byte, length)
+	if _, err := io.ReadFull(r, value); err != nil {
+		return "", fmt.Errorf("failed to read GGUF string: %w", err)
+	}
+	return string(value), nil
+}
+
+// readGGUFValue reads a scalar metadata value as an unsigned integer, skipping arrays,
+// strings and floats (reported as 0)
+func readGGUFValue(r *bufio.Reader, valueType uint32) (uint64, error) {
+	switch valueType {
+	case 0, 1, 7: // uint8, int8, bool
+		b, err := r.ReadByte()
+		return uint64(b), err
+	case 2, 3: // uint16, int16
+		var v uint16
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case 4, 5: // uint32, int32
+		var v uint32
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), err
+	case 6: // float32
+		_, err := r.Discard(4)
+		return 0, err
+	case 10, 11: // uint64, int64
+		var v uint64
+		err := binary.Read(r, binary.LittleEndian, &v)
+		return v, err
+	case 12: // float64
+		_, err := r.Discard(8)
+		return 0, err
+	case ggufStringType:
+		_, err := readGGUFString(r)
+		return 0, err
+	case ggufArrayType:
+		var elementType uint32
+		var count uint64
+		if err := binary.Read(r, binary.LittleEndian, &elementType); err != nil {
+			return 0, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+			return 0, err
+		}
+		for j := uint64(0); j < count; j++ {
+			if _, err := readGGUFValue(r, elementType); err != nil {
+				return 0, err
+			}
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unknown GGUF value type %d", valueType)
+	}
+}
@@ -0,0 +1,221 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeGGUF writes a GGUF header with the given metadata followed by padding weights
+func writeGGUF(t *testing.T, name string, metadata map[string]interface{}, weightBytes int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	write := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+	writeString := func(s string) {
+		write(uint64(len(s)))
+		buf.WriteString(s)
+	}
+
+	buf.WriteString("GGUF")
+	write(uint32(3))
+	write(uint64(0))
+	write(uint64(len(metadata) + 1))
+
+	// An array value exercises skipping of tokenizer-style metadata
+	writeString("tokenizer.ggml.tokens")
+	write(uint32(ggufArrayType))
+	write(uint32(ggufStringType))
+	write(uint64(2))
+	writeString("<s>")
+	writeString("</s>")
+
+	for key, value := range metadata {
+		writeString(key)
+		switch v := value.(type) {
+		case string:
+			write(uint32(ggufStringType))
+			writeString(v)
+		case uint32:
+			write(uint32(4))
+			write(v)
+		case float32:
+			write(uint32(6))
+			write(v)
+		}
+	}
+	buf.Write(make([]byte, weightBytes))
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write GGUF file: %v", err)
+	}
+	return path
+}
+
+// stubAvailableMemory replaces the system memory probe for the duration of a test
+func stubAvailableMemory(t *testing.T, bytes uint64) {
+	original := availableMemory
+	availableMemory = func() uint64 { return bytes }
+	t.Cleanup(func() { availableMemory = original })
+}
+
+func TestInspectModelFile_ReadsGGUFMetadata(t *testing.T) {
+	path := writeGGUF(t, "tiny.gguf", map[string]interface{}{
+		"general.architecture":     "llama",
+		"general.file_type":        uint32(15),
+		"llama.block_count":        uint32(22),
+		"llama.embedding_length":   uint32(2048),
+		"llama.context_length":     uint32(4096),
+		"llama.rope.freq_base":     float32(10000),
+		"general.quantization_ver": uint32(2),
+	}, 1024)
+
+	info, err := InspectModelFile(path)
+	if err != nil {
+		t.Fatalf("InspectModelFile() failed: %v", err)
+	}
+	if info.Architecture != "llama" || info.Quantization != "Q4_K_M" {
+		t.Errorf("Expected llama Q4_K_M, got %s %s", info.Architecture, info.Quantization)
+	}
+	if info.Layers != 22 || info.EmbeddingLength != 2048 || info.ContextLength != 4096 {
+		t.Errorf("Expected 22 layers, 2048 embedding and 4096 context, got %+v", info)
+	}
+}
+
+func TestInspectModelFile_FallsBackToFilename(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tinyllama-1.1b-chat.Q8_0.gguf")
+	os.WriteFile(path, []byte("not a gguf header"), 0o644)
+
+	info, err := InspectModelFile(path)
+	if err == nil {
+		t.Error("Expected an error for an invalid GGUF header")
+	}
+	if info.Quantization != "Q8_0" || info.SizeBytes == 0 {
+		t.Errorf("Expected quantization and size despite the bad header, got %+v", info)
+	}
+
+	for name, expected := range map[string]string{
+		"model-q4_k_m.gguf":   "Q4_K_M",
+		"phi-2.IQ3_XXS.gguf":  "IQ3_XXS",
+		"model-f16.gguf":      "F16",
+		"model_v2_q4_0.gguf":  "Q4_0",
+		"modelq40.gguf":       "",
+		"qwen2-0_5b-chat.bin": "",
+	} {
+		if got := quantizationFromFilename("/models/" + name); got != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, name, got)
+		}
+	}
+}
+
+func TestCheckHardwareFit(t *testing.T) {
+	path := writeGGUF(t, "fit.gguf", map[string]interface{}{
+		"general.architecture":   "llama",
+		"general.file_type":      uint32(1),
+		"llama.block_count":      uint32(2),
+		"llama.embedding_length": uint32(64),
+	}, 4096)
+
+	stubAvailableMemory(t, 1<<30)
+	report, err := CheckHardwareFit(path, 512, 1)
+	if err != nil {
+		t.Fatalf("CheckHardwareFit() failed: %v", err)
+	}
+	if !report.Fits() || report.Err() != nil {
+		t.Errorf("Expected a small model to fit in 1 GB, got %+v", report)
+	}
+	if expectedKV := uint64(2 * 2 * 512 * 64 * 2); report.EstimatedRAMBytes < expectedKV {
+		t.Errorf("Expected the estimate to include a %d byte KV cache, got %d", expectedKV, report.EstimatedRAMBytes)
+	}
+	if !hasWarning(report, WarningUnquantizedModel) {
+		t.Errorf("Expected an unquantized model warning for F16, got %+v", report.Warnings)
+	}
+
+	stubAvailableMemory(t, 1024)
+	report, _ = CheckHardwareFit(path, 512, report.CPUs+1)
+	if report.Fits() || !errors.Is(report.Err(), ErrInsufficientMemory) {
+		t.Errorf("Expected ErrInsufficientMemory with 1 KB available, got %v", report.Err())
+	}
+	if !hasWarning(report, WarningInsufficientMemory) || !hasWarning(report, WarningThreadsExceedCPUs) {
+		t.Errorf("Expected memory and thread warnings, got %+v", report.Warnings)
+	}
+
+	if _, err := CheckHardwareFit(filepath.Join(t.TempDir(), "missing.gguf"), 512, 1); err == nil {
+		t.Error("Expected an error for a missing model file")
+	}
+}
+
+func TestLLMBackend_RefusesModelThatDoesNotFit(t *testing.T) {
+	path := writeGGUF(t, "large.Q4_K_M.gguf", map[string]interface{}{"general.file_type": uint32(15)}, 4096)
+	stubAvailableMemory(t, 1024)
+
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: path})
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.Initialize(configJSON); !errors.Is(err, ErrInsufficientMemory) {
+		t.Errorf("Expected ErrInsufficientMemory, got %v", err)
+	}
+
+	configJSON, _ = json.Marshal(LLMConfig{ModelPath: path, AllowMemoryOvercommit: true})
+	overcommitted := NewLLMBackend()
+	defer overcommitted.Close()
+	if err := overcommitted.Initialize(configJSON); err != nil {
+		t.Fatalf("Expected allowMemoryOvercommit to load the model, got %v", err)
+	}
+	report, ok := overcommitted.HardwareFit()
+	if !ok || !hasWarning(report, WarningInsufficientMemory) {
+		t.Errorf("Expected the fit report to carry the memory warning, got %+v", report)
+	}
+	if info := overcommitted.model.GetModelInfo(); info.Quantization != "Q4_K_M" {
+		t.Errorf("Expected model info to report Q4_K_M, got %q", info.Quantization)
+	}
+}
+
+func TestLLMBackend_ContextSizeFollowsModelMetadata(t *testing.T) {
+	path := writeGGUF(t, "short.Q4_K_M.gguf", map[string]interface{}{
+		"general.architecture": "llama",
+		"general.file_type":    uint32(15),
+		"llama.context_length": uint32(1024),
+	}, 4096)
+	stubAvailableMemory(t, 1<<30)
+
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: path})
+	auto := NewLLMBackend()
+	defer auto.Close()
+	if err := auto.Initialize(configJSON); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if auto.contextSize != 1024 || auto.model.GetContextSize() != 1024 {
+		t.Errorf("Expected an unset contextSize to follow the 1024 token model, got %d", auto.contextSize)
+	}
+	if report, _ := auto.HardwareFit(); hasWarning(report, WarningContextTooLong) {
+		t.Errorf("Expected no warning for an unset contextSize, got %+v", report.Warnings)
+	}
+
+	configJSON, _ = json.Marshal(LLMConfig{ModelPath: path, ContextSize: 8192})
+	clamped := NewLLMBackend()
+	defer clamped.Close()
+	if err := clamped.Initialize(configJSON); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if clamped.contextSize != 1024 {
+		t.Errorf("Expected contextSize 8192 to be clamped to 1024, got %d", clamped.contextSize)
+	}
+	if report, _ := clamped.HardwareFit(); !hasWarning(report, WarningContextTooLong) {
+		t.Errorf("Expected a context warning, got %+v", report.Warnings)
+	}
+}
+
+// hasWarning reports whether a fit report contains a warning with the given code
+func hasWarning(report HardwareFitReport, code string) bool {
+	for _, warning := range report.Warnings {
+		if warning.Code == code {
+			return true
+		}
+	}
+	return false
+}
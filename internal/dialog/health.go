@@ -0,0 +1,175 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BackendHealth reports the operational state of a single backend
+type BackendHealth struct {
+	Name         string    `json:"name"`
+	Healthy      bool      `json:"healthy"`
+	Error        string    `json:"error,omitempty"` // HealthCheck failure, if any
+	ModelLoaded  bool      `json:"modelLoaded"`
+	QueueDepth   int       `json:"queueDepth"` // Generations currently in flight
+	Queued       int       `json:"queued"`     // Generations waiting for a slot (maxConcurrentGenerations)
+	Requests     int       `json:"requests"`
+	Failures     int       `json:"failures"`
+	LastError    string    `json:"lastError,omitempty"`
+	LastErrorAt  time.Time `json:"lastErrorAt,omitempty"`
+	AvgLatencyMs float64   `json:"avgLatencyMs"`
+	MaxTokens    int       `json:"maxTokens,omitempty"`  // Response length adaptive quality currently allows
+	Overloaded   bool      `json:"overloaded,omitempty"` // Too slow even at the lowest adaptive quality
+
+	PromptCache *PromptCacheStats `json:"promptCache,omitempty"` // KV cache reuse, for models that cache prompts
+}
+
+// HealthReport aggregates backend health for diagnostics screens and server mode
+type HealthReport struct {
+	Healthy   bool            `json:"healthy"` // True when the default backend is healthy
+	Default   string          `json:"defaultBackend"`
+	Backends  []BackendHealth `json:"backends"`
+	CheckedAt time.Time       `json:"checkedAt"`
+}
+
+// HealthReporter is implemented by backends that track runtime health statistics
+type HealthReporter interface {
+	GetHealth() BackendHealth
+}
+
+// healthTracker records in-flight requests, errors and latency for a backend
+type healthTracker struct {
+	inFlight     int
+	requests     int
+	failures     int
+	totalLatency time.Duration
+	lastError    string
+	lastErrorAt  time.Time
+	mu           sync.Mutex
+}
+
+// begin marks the start of a generation and returns a function that records its outcome
+func (h *healthTracker) begin() func(err error) {
+	start := time.Now()
+
+	h.mu.Lock()
+	h.inFlight++
+	h.mu.Unlock()
+
+	return func(err error) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		h.inFlight--
+		h.requests++
+		h.totalLatency += time.Since(start)
+		if err != nil {
+			h.failures++
+			h.lastError = err.Error()
+			h.lastErrorAt = currentTime()
+		}
+	}
+}
+
+// snapshot fills the runtime statistics of a BackendHealth
+func (h *healthTracker) snapshot(health *BackendHealth) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health.QueueDepth = h.inFlight
+	health.Requests = h.requests
+	health.Failures = h.failures
+	health.LastError = h.lastError
+	health.LastErrorAt = h.lastErrorAt
+	if h.requests > 0 {
+		health.AvgLatencyMs = float64(h.totalLatency.Milliseconds()) / float64(h.requests)
+	}
+}
+
+// HealthCheck verifies the backend is initialized and its model is loaded
+func (llm *LLMBackend) HealthCheck(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+
+	if !llm.initialized {
+		return fmt.Errorf("LLM backend %w", ErrNotInitialized)
+	}
+	if llm.model == nil {
+		return fmt.Errorf("no model loaded")
+	}
+	if info := llm.model.GetModelInfo(); !info.Initialized {
+		return fmt.Errorf("model %s %w", info.ModelPath, ErrNotInitialized)
+	}
+	return nil
+}
+
+// GetHealth returns runtime health statistics for the backend
+func (llm *LLMBackend) GetHealth() BackendHealth {
+	llm.mu.RLock()
+	health := BackendHealth{
+		Name:        llm.info.Name,
+		ModelLoaded: llm.model != nil && llm.model.GetModelInfo().Initialized,
+	}
+	if cacher, ok := llm.model.(PromptCacher); ok {
+		stats := cacher.PromptCacheStats()
+		health.PromptCache = &stats
+	}
+	if llm.adaptive != nil {
+		health.MaxTokens = llm.adaptive.maxTokens(llm.maxTokens)
+		health.Overloaded = llm.adaptive.overloaded()
+	}
+	queue := llm.queue
+	llm.mu.RUnlock()
+
+	health.Queued = queue.queued()
+	llm.health.snapshot(&health)
+	return health
+}
+
+// Health checks every registered backend and reports their combined state
+func (dm *DialogManager) Health(ctx context.Context) HealthReport {
+	dm.mu.RLock()
+	backends := make(map[string]DialogBackend, len(dm.backends))
+	for name, backend := range dm.backends {
+		backends[name] = backend
+	}
+	defaultBackend := dm.defaultBackend
+	dm.mu.RUnlock()
+
+	report := HealthReport{
+		Default:   defaultBackend,
+		Backends:  make([]BackendHealth, 0, len(backends)),
+		CheckedAt: currentTime(),
+	}
+
+	for name, backend := range backends {
+		var health BackendHealth
+		if reporter, ok := backend.(HealthReporter); ok {
+			health = reporter.GetHealth()
+		}
+		health.Name = name
+
+		if err := backend.HealthCheck(ctx); err != nil {
+			health.Error = err.Error()
+		} else {
+			health.Healthy = true
+		}
+
+		if name == defaultBackend {
+			report.Healthy = health.Healthy
+		}
+		report.Backends = append(report.Backends, health)
+	}
+
+	sort.Slice(report.Backends, func(i, j int) bool {
+		return report.Backends[i].Name < report.Backends[j].Name
+	})
+	return report
+}
@@ -0,0 +1,85 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLLMBackend_HealthCheck(t *testing.T) {
+	backend := NewLLMBackend()
+	if err := backend.HealthCheck(context.Background()); err == nil {
+		t.Error("Expected uninitialized backend to fail health check")
+	}
+
+	backend = newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hi!"}})
+	if err := backend.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Expected healthy backend, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := backend.HealthCheck(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLLMBackend_GetHealthTracksRequests(t *testing.T) {
+	model := &scriptedTestModel{
+		responses: []string{"", "Hello again!"},
+		errors:    []error{errors.New("model crashed")},
+	}
+	backend := newScriptedBackend(t, LLMConfig{FallbackEnabled: true}, model)
+
+	backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "health"})
+	backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "health"})
+
+	health := backend.GetHealth()
+	if !health.ModelLoaded {
+		t.Error("Expected model to be reported as loaded")
+	}
+	if health.Requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", health.Requests)
+	}
+	if health.Failures != 1 {
+		t.Errorf("Expected 1 failure, got %d", health.Failures)
+	}
+	if health.LastError != "model crashed" {
+		t.Errorf("Expected last error 'model crashed', got %q", health.LastError)
+	}
+	if health.LastErrorAt.IsZero() {
+		t.Error("Expected LastErrorAt to be set")
+	}
+	if health.QueueDepth != 0 {
+		t.Errorf("Expected empty queue after requests finish, got %d", health.QueueDepth)
+	}
+}
+
+func TestDialogManager_Health(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("primary", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hi!"}}))
+	dm.RegisterBackend("broken", NewLLMBackend())
+	dm.SetDefaultBackend("primary")
+
+	report := dm.Health(context.Background())
+	if !report.Healthy {
+		t.Error("Expected report to be healthy when the default backend is healthy")
+	}
+	if len(report.Backends) != 2 {
+		t.Fatalf("Expected 2 backends, got %d", len(report.Backends))
+	}
+
+	// Backends are sorted by name
+	broken, primary := report.Backends[0], report.Backends[1]
+	if broken.Name != "broken" || broken.Healthy || broken.Error == "" {
+		t.Errorf("Expected broken backend to report an error, got %+v", broken)
+	}
+	if primary.Name != "primary" || !primary.Healthy || !primary.ModelLoaded {
+		t.Errorf("Expected primary backend to be healthy, got %+v", primary)
+	}
+
+	dm.SetDefaultBackend("broken")
+	if dm.Health(context.Background()).Healthy {
+		t.Error("Expected report to be unhealthy when the default backend fails its check")
+	}
+}
@@ -0,0 +1,91 @@
+package dialog
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// History selection strategies for LLMConfig.HistorySelection
+const (
+	HistorySelectionImportant = "important" // Highest decayed importance (default)
+	HistorySelectionRelevant  = "relevant"  // Most related to the current trigger and topics
+)
+
+// Weights combining the signals that make a past exchange relevant to the current request
+const (
+	relevanceTriggerWeight    = 0.4
+	relevanceTopicWeight      = 0.3
+	relevanceEngagementWeight = 0.2
+	relevanceImportanceWeight = 0.1
+)
+
+// GetRelevantHistory retrieves up to maxExchanges exchanges most relevant to the current context
+// Exchanges score higher for the same trigger, shared topic words and user engagement;
+// the most recent exchange is always included and results are returned in chronological order
+func (cm *ContextManager) GetRelevantHistory(interactionID string, ctx DialogContext, maxExchanges int) []ConversationExchange {
+	shard := cm.rlockShard(interactionID)
+	defer shard.mu.RUnlock()
+
+	history, exists := shard.conversations[interactionID]
+	if !exists || len(history.Exchanges) == 0 {
+		return []ConversationExchange{}
+	}
+
+	exchanges := history.Exchanges
+	if maxExchanges <= 0 || len(exchanges) <= maxExchanges {
+		result := make([]ConversationExchange, len(exchanges))
+		copy(result, exchanges)
+		return result
+	}
+
+	now := cm.now()
+	query := termVector(buildExampleQuery(ctx))
+	newest := len(exchanges) - 1
+
+	scores := make([]float64, newest)
+	candidates := make([]int, 0, newest)
+	terms := make(map[string]float64) // Reused for each exchange's term vector
+	for i := newest - 1; i >= 0; i-- {
+		candidates = append(candidates, i)
+		scores[i] = cm.relevanceScore(exchanges[i], ctx.Trigger, query, terms, now)
+	}
+	// Candidates run newest first, so the stable sort favours recent exchanges on ties
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+
+	selected := append(candidates[:maxExchanges-1], newest)
+	slices.Sort(selected)
+
+	result := make([]ConversationExchange, len(selected))
+	for i, index := range selected {
+		result[i] = exchanges[index]
+	}
+	return result
+}
+
+// relevanceScore rates how useful a past exchange is as context for the current request,
+// using terms as scratch space for the exchange's term vector
+// This method assumes the caller already holds the lock
+func (cm *ContextManager) relevanceScore(exchange ConversationExchange, trigger string, query, terms map[string]float64, now time.Time) float64 {
+	score := 0.0
+	if exchange.Trigger == trigger {
+		score += relevanceTriggerWeight
+	}
+
+	clear(terms)
+	for _, text := range [...]string{exchange.Trigger, triggerDescription(exchange.Trigger), exchange.UserMessage, exchange.Response} {
+		addTerms(terms, text)
+	}
+	score += relevanceTopicWeight * cosineSimilarity(query, terms)
+
+	engagement := exchange.EngagementScore
+	if exchange.FeedbackReceived && exchange.UserFeedback {
+		engagement = max(engagement, 0.5)
+	}
+	score += relevanceEngagementWeight * engagement
+
+	score += relevanceImportanceWeight * cm.effectiveImportance(exchange, now)
+	return score
+}
@@ -0,0 +1,72 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestContextManager_GetRelevantHistory(t *testing.T) {
+	cm := NewContextManager(10)
+	defer cm.Close()
+
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "feed", Response: "Yum, cookies!"})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "Hi there"})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "play", Response: "Catch!", EngagementScore: 0.9})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "Hello again"})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "hover", Response: "Oh, you're back"})
+
+	history := cm.GetRelevantHistory("user-1", DialogContext{Trigger: "feed"}, 3)
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 exchanges, got %d", len(history))
+	}
+
+	expected := []string{"Yum, cookies!", "Catch!", "Oh, you're back"}
+	for i, exchange := range history {
+		if exchange.Response != expected[i] {
+			t.Errorf("Expected %q at position %d, got %q", expected[i], i, exchange.Response)
+		}
+	}
+}
+
+func TestContextManager_GetRelevantHistoryShortConversation(t *testing.T) {
+	cm := NewContextManager(10)
+	defer cm.Close()
+
+	if history := cm.GetRelevantHistory("missing", DialogContext{Trigger: "click"}, 3); len(history) != 0 {
+		t.Errorf("Expected empty history for unknown interaction, got %d", len(history))
+	}
+
+	cm.AddExchange("user-1", "click", "first")
+	cm.AddExchange("user-1", "feed", "second")
+	if history := cm.GetRelevantHistory("user-1", DialogContext{Trigger: "feed"}, 5); len(history) != 2 || history[0].Response != "first" {
+		t.Errorf("Expected the full history in order, got %+v", history)
+	}
+}
+
+func TestLLMBackend_RelevantHistorySelection(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{HistorySelection: HistorySelectionRelevant, HistoryExchanges: 2}, &scriptedTestModel{})
+
+	backend.contextManager.AddExchange("user-1", "feed", "Yum, cookies!")
+	backend.contextManager.AddExchange("user-1", "click", "Hi there")
+	backend.contextManager.AddExchange("user-1", "click", "Hello again")
+	backend.contextManager.AddExchange("user-1", "hover", "Oh, you're back")
+
+	prompt := backend.buildPrompt(DialogContext{Trigger: "feed", InteractionID: "user-1"})
+	if !strings.Contains(prompt, "Yum, cookies!") || !strings.Contains(prompt, "Oh, you're back") {
+		t.Errorf("Expected the matching and newest exchanges in the prompt, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Hi there") || strings.Contains(prompt, "Hello again") {
+		t.Errorf("Expected unrelated exchanges to be left out, got:\n%s", prompt)
+	}
+}
+
+func TestLLMBackend_RejectsUnknownHistorySelection(t *testing.T) {
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: "/fake/path.gguf", HistorySelection: "random"})
+	backend := NewLLMBackend()
+	defer backend.Close()
+
+	if err := backend.Initialize(configJSON); err == nil {
+		t.Error("Expected an unknown historySelection to be rejected")
+	}
+}
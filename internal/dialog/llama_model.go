@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -15,43 +16,76 @@ type LlamaModel struct {
 	modelPath   string
 	contextSize int
 	threads     int
+	lowPriority bool
 	temperature float32
 	topP        float32
 	initialized bool
 	mu          sync.RWMutex
 
+	autoContext     bool              // contextSize was not configured and follows the model
+	allowOvercommit bool              // Load even when the model is estimated not to fit in memory
+	hardwareFit     HardwareFitReport // Result of the fit check made when loading
+	fitChecked      bool
+
 	// Model state (in production this would be actual llama.cpp context)
 	modelContext interface{} // Placeholder for actual model context
 	tokenizer    interface{} // Placeholder for tokenizer
+
+	promptCache *promptCache // Prompt held in the KV cache; nil when caching is disabled
+
+	loraPath  string  // LoRA adapter applied over the base weights ("" = none)
+	loraScale float32 // Strength the adapter is applied with
 }
 
 // LlamaConfig represents configuration for the Llama model
 type LlamaConfig struct {
 	ModelPath   string  `json:"modelPath"`
-	ContextSize int     `json:"contextSize"`
+	ContextSize int     `json:"contextSize"` // Clamped to the model's trained context (0 = trained context, at most 2048)
 	Threads     int     `json:"threads"`
+	LowPriority bool    `json:"lowPriority"` // Lower the OS priority of inference threads
 	Temperature float32 `json:"temperature"`
 	TopP        float32 `json:"topP"`
 	UseGPU      bool    `json:"useGpu"`
 	GPULayers   int     `json:"gpuLayers"`
 	UseMmap     bool    `json:"useMmap"`
 	UseMlock    bool    `json:"useMlock"`
+	PromptCache bool    `json:"promptCache"` // Reuse the KV cache for prompt prefixes shared with the previous request
+
+	AllowMemoryOvercommit bool `json:"allowMemoryOvercommit"` // Load even if estimated RAM exceeds available memory
+
+	// LoraPath is a GGUF LoRA adapter applied over the base model, letting characters share
+	// one base file with a small personality adapter each
+	LoraPath  string  `json:"loraPath,omitempty"`
+	LoraScale float32 `json:"loraScale,omitempty"` // Adapter strength (default: 1)
 }
 
+// defaultContextSize is the context window used when none is configured, if the model allows it
+const defaultContextSize = 2048
+
 // NewLlamaModel creates a new Llama model instance
 func NewLlamaModel(config LlamaConfig) (*LlamaModel, error) {
 	if config.ModelPath == "" {
-		return nil, fmt.Errorf("model path is required")
+		return nil, configErrorf("modelPath", "model path is required")
 	}
 
 	// Validate model file exists
 	if _, err := os.Stat(config.ModelPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("model file not found: %s", config.ModelPath)
+		return nil, fmt.Errorf("%w: %s", ErrModelNotFound, config.ModelPath)
+	}
+
+	if config.LoraPath != "" {
+		if _, err := os.Stat(config.LoraPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: LoRA adapter %s", ErrModelNotFound, config.LoraPath)
+		}
+	}
+	if config.LoraScale < 0 {
+		return nil, configErrorf("loraScale", "loraScale must be non-negative, got %g", config.LoraScale)
 	}
 
 	// Set defaults
-	if config.ContextSize <= 0 {
-		config.ContextSize = 2048
+	autoContext := config.ContextSize <= 0
+	if autoContext {
+		config.ContextSize = defaultContextSize
 	}
 	if config.Threads <= 0 {
 		config.Threads = 4
@@ -62,14 +96,27 @@ func NewLlamaModel(config LlamaConfig) (*LlamaModel, error) {
 	if config.TopP <= 0 {
 		config.TopP = 0.9
 	}
+	if config.LoraScale == 0 {
+		config.LoraScale = 1
+	}
 
 	model := &LlamaModel{
 		modelPath:   config.ModelPath,
 		contextSize: config.ContextSize,
 		threads:     config.Threads,
+		lowPriority: config.LowPriority,
 		temperature: config.Temperature,
 		topP:        config.TopP,
 		initialized: false,
+
+		autoContext:     autoContext,
+		allowOvercommit: config.AllowMemoryOvercommit,
+	}
+	if config.LoraPath != "" {
+		model.loraPath, model.loraScale = config.LoraPath, config.LoraScale
+	}
+	if config.PromptCache {
+		model.promptCache = &promptCache{}
 	}
 
 	return model, nil
@@ -95,6 +142,33 @@ func (l *LlamaModel) Initialize() error {
 		return fmt.Errorf("model file must be in GGUF format: %s", l.modelPath)
 	}
 
+	// Fit the context window to what the model was trained with; unreadable metadata is
+	// reported by the hardware fit check
+	requested := l.contextSize
+	base, baseErr := InspectModelFile(l.modelPath)
+	if baseErr == nil && base.ContextLength > 0 {
+		l.contextSize = min(l.contextSize, base.ContextLength)
+	}
+	if err := l.checkLoraAdapter(base); err != nil {
+		return err
+	}
+
+	// Refuse models that would push the system into swap mid-conversation
+	report, err := CheckHardwareFit(l.modelPath, l.contextSize, l.threads)
+	if err != nil {
+		return err
+	}
+	if l.contextSize < requested && !l.autoContext {
+		report.Warnings = append(report.Warnings, HardwareWarning{
+			Code:    WarningContextTooLong,
+			Message: fmt.Sprintf("contextSize %d exceeds the %d tokens the model was trained with; using %d", requested, l.contextSize, l.contextSize),
+		})
+	}
+	l.hardwareFit, l.fitChecked = report, true
+	if err := report.Err(); err != nil && !l.allowOvercommit {
+		return err
+	}
+
 	// Simulate model loading delay
 	time.Sleep(100 * time.Millisecond)
 
@@ -105,6 +179,9 @@ func (l *LlamaModel) Initialize() error {
 	//     UseMmap:     true,
 	//     UseMlock:    false,
 	// })
+	// if l.loraPath != "" {
+	//     l.modelContext.ApplyLoraAdapter(l.loraPath, l.loraScale)
+	// }
 
 	l.modelContext = fmt.Sprintf("mock_context_%s", l.modelPath)
 	l.tokenizer = "mock_tokenizer"
@@ -113,12 +190,35 @@ func (l *LlamaModel) Initialize() error {
 	return nil
 }
 
+// checkLoraAdapter verifies the adapter is a LoRA for the base model's architecture
+// Files whose metadata cannot be read are left for llama.cpp to reject
+func (l *LlamaModel) checkLoraAdapter(base ModelFileInfo) error {
+	if l.loraPath == "" {
+		return nil
+	}
+	if !strings.HasSuffix(l.loraPath, ".gguf") {
+		return fmt.Errorf("LoRA adapter must be in GGUF format: %s", l.loraPath)
+	}
+	adapter, err := InspectModelFile(l.loraPath)
+	switch {
+	case err != nil:
+		return nil
+	case adapter.Type != "" && adapter.Type != "adapter":
+		return fmt.Errorf("%s is a %s, not a LoRA adapter", l.loraPath, adapter.Type)
+	case adapter.AdapterType != "" && adapter.AdapterType != "lora":
+		return fmt.Errorf("%s is a %s adapter, not a LoRA adapter", l.loraPath, adapter.AdapterType)
+	case adapter.Architecture != "" && base.Architecture != "" && adapter.Architecture != base.Architecture:
+		return fmt.Errorf("LoRA adapter %s is for %s models but %s is %s", l.loraPath, adapter.Architecture, l.modelPath, base.Architecture)
+	}
+	return nil
+}
+
 // Predict generates text using the loaded model
 func (l *LlamaModel) Predict(prompt string) (string, error) {
 	l.mu.RLock()
 	if !l.initialized {
 		l.mu.RUnlock()
-		return "", fmt.Errorf("model not initialized")
+		return "", fmt.Errorf("model %w", ErrNotInitialized)
 	}
 	l.mu.RUnlock()
 
@@ -131,15 +231,25 @@ func (l *LlamaModel) Predict(prompt string) (string, error) {
 		return "", fmt.Errorf("prompt too long for context window")
 	}
 
+	// Only the part of the prompt after the prefix shared with the previous request
+	// needs evaluating; personality and character state usually stay cached
+	reused := 0
+	if l.promptCache != nil {
+		reused, _ = l.promptCache.evaluate(prompt, l.EstimateTokens)
+	}
+
 	// In production, this would perform actual inference:
 	// 1. Tokenize the prompt
-	// 2. Run inference with temperature/top_p sampling
-	// 3. Decode tokens back to text
-	// 4. Return generated text
+	// 2. Drop cached tokens past the reused prefix and evaluate the remainder
+	// 3. Run inference with temperature/top_p sampling
+	// 4. Decode tokens back to text
 	//
 	// tokens := l.tokenizer.Encode(prompt)
-	// output := l.modelContext.Generate(tokens, l.temperature, l.topP)
+	// l.modelContext.KVCacheSeqRemove(0, reused, -1)
+	// l.modelContext.Decode(tokens[reused:], reused)
+	// output := l.modelContext.Generate(l.temperature, l.topP)
 	// return l.tokenizer.Decode(output), nil
+	_ = reused
 
 	// For now, return context-aware mock responses
 	return l.generateMockResponse(prompt), nil
@@ -158,7 +268,7 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"Mmm, that was tasty! I feel much better now!",
 			"You always know what I like to eat! 🍽️",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
 
 	case strings.Contains(prompt, "happy") || strings.Contains(prompt, "cheerful"):
 		responses := []string{
@@ -166,7 +276,7 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"Your presence always brightens my mood! ✨",
 			"Life is so much better when you're around! 💕",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
 
 	case strings.Contains(prompt, "sad") || strings.Contains(prompt, "down"):
 		responses := []string{
@@ -174,7 +284,7 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"It's okay to feel sad. I'm here for you. 💙",
 			"Let's try to turn that frown upside down together! 😌",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
 
 	case strings.Contains(prompt, "romantic") || strings.Contains(prompt, "love"):
 		responses := []string{
@@ -182,7 +292,7 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"Every moment with you feels like magic... ✨💕",
 			"I treasure our special connection! 🌹",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
 
 	case strings.Contains(prompt, "talk") || strings.Contains(prompt, "conversation"):
 		responses := []string{
@@ -190,7 +300,7 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"I love our conversations! They mean so much to me. 💭",
 			"Let's share some thoughts together! 🗨️",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
 
 	case strings.Contains(prompt, "click") || strings.Contains(prompt, "hello"):
 		responses := []string{
@@ -198,7 +308,7 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"Hi! How has your day been treating you? 😊",
 			"Welcome back! I've been thinking about you! 💭",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
 
 	default:
 		// General responses for unmatched prompts
@@ -209,8 +319,42 @@ func (l *LlamaModel) generateMockResponse(prompt string) string {
 			"You always give me something new to consider! ✨",
 			"I'm grateful for our time together! 💕",
 		}
-		return responses[int(time.Now().UnixNano())%len(responses)]
+		return responses[randomIntn(len(responses))]
+	}
+}
+
+// PredictWithOptions generates text with per-request sampling overrides
+// Zero-valued options fall back to the model's configured defaults
+func (l *LlamaModel) PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error) {
+	l.mu.RLock()
+	sampling := opts.withDefaults(l.temperature, l.topP)
+	l.mu.RUnlock()
+
+	// In production, the resolved sampling parameters are passed to the llama.cpp sampler:
+	// grammar := llama.NewGrammar(sampling.Grammar, "root") // when sampling.Grammar is set
+	// for _, token := range l.tokenizer.Encode(strings.Join(sampling.AvoidText, " ")) {
+	//     logitBias[token] -= sampling.AvoidPenalty
+	// }
+	// output := l.modelContext.Generate(tokens, sampling.Temperature, sampling.TopP, sampling.MaxTokens, sampling.Seed, logitBias, grammar)
+	_ = sampling
+
+	return l.PredictWithTimeout(ctx, prompt)
+}
+
+// PredictWithStats generates text and reports token usage for confidence scoring
+func (l *LlamaModel) PredictWithStats(ctx context.Context, prompt string, opts PredictOptions) (string, PredictionStats, error) {
+	result, err := l.PredictWithOptions(ctx, prompt, opts)
+	if err != nil {
+		return "", PredictionStats{}, err
 	}
+
+	// In production, per-token logprobs are accumulated from the llama.cpp sampler
+	// and reported through MeanLogprob with HasLogprobs set
+	tokens := l.EstimateTokens(result)
+	return result, PredictionStats{
+		TokenCount:   tokens,
+		HitMaxTokens: opts.MaxTokens > 0 && tokens >= opts.MaxTokens,
+	}, nil
 }
 
 // PredictWithTimeout generates text with a timeout context
@@ -219,6 +363,13 @@ func (l *LlamaModel) PredictWithTimeout(ctx context.Context, prompt string) (str
 	errorChan := make(chan error, 1)
 
 	go func() {
+		if l.lowPriority {
+			// The locked thread is discarded when this goroutine exits, so the lowered
+			// priority never leaks to other goroutines. In production the llama.cpp
+			// threadpool is created with a low scheduling priority instead
+			runtime.LockOSThread()
+			lowerThreadPriority()
+		}
 		result, err := l.Predict(prompt)
 		if err != nil {
 			errorChan <- err
@@ -233,7 +384,7 @@ func (l *LlamaModel) PredictWithTimeout(ctx context.Context, prompt string) (str
 	case err := <-errorChan:
 		return "", err
 	case <-ctx.Done():
-		return "", fmt.Errorf("prediction timed out: %w", ctx.Err())
+		return "", fmt.Errorf("prediction interrupted: %w", deadlineError(ctx))
 	}
 }
 
@@ -262,9 +413,20 @@ func (l *LlamaModel) GetModelInfo() ModelInfo {
 		Initialized: l.initialized,
 		ModelType:   "llama.cpp",
 		Backend:     "CPU",
+
+		Quantization: l.hardwareFit.Model.Quantization,
+		LoraPath:     l.loraPath,
+		LoraScale:    l.loraScale,
 	}
 }
 
+// HardwareFit returns the memory and CPU fit check made when the model was loaded
+func (l *LlamaModel) HardwareFit() (HardwareFitReport, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.hardwareFit, l.fitChecked
+}
+
 // Free releases model resources
 func (l *LlamaModel) Free() error {
 	l.mu.Lock()
@@ -282,10 +444,28 @@ func (l *LlamaModel) Free() error {
 	l.modelContext = nil
 	l.tokenizer = nil
 	l.initialized = false
+	if l.promptCache != nil {
+		l.promptCache.reset()
+	}
 
 	return nil
 }
 
+// PromptCacheStats reports KV cache reuse across predictions
+func (l *LlamaModel) PromptCacheStats() PromptCacheStats {
+	if l.promptCache == nil {
+		return PromptCacheStats{}
+	}
+	return l.promptCache.snapshot()
+}
+
+// ResetPromptCache discards the cached prompt so the next prediction evaluates it in full
+func (l *LlamaModel) ResetPromptCache() {
+	if l.promptCache != nil {
+		l.promptCache.reset()
+	}
+}
+
 // ModelInfo provides information about a loaded model
 type ModelInfo struct {
 	ModelPath   string  `json:"modelPath"`
@@ -296,6 +476,35 @@ type ModelInfo struct {
 	Initialized bool    `json:"initialized"`
 	ModelType   string  `json:"modelType"`
 	Backend     string  `json:"backend"`
+
+	Quantization string  `json:"quantization,omitempty"` // Weight quantization, e.g. "Q4_K_M", when known
+	LoraPath     string  `json:"loraPath,omitempty"`     // LoRA adapter applied over the weights, if any
+	LoraScale    float32 `json:"loraScale,omitempty"`    // Strength of the LoRA adapter
+}
+
+// PredictOptions carries per-request sampling overrides
+// Zero values mean "use the model's configured default"
+type PredictOptions struct {
+	Temperature float32 `json:"temperature,omitempty"`
+	TopP        float32 `json:"topP,omitempty"`
+	MaxTokens   int     `json:"maxTokens,omitempty"`
+	Seed        int64   `json:"seed,omitempty"` // Sampling seed, for reproducible or deliberately different samples (0 = random)
+
+	AvoidText    []string `json:"avoidText,omitempty"`    // Text whose tokens the sampler penalizes, e.g. a rejected reply
+	AvoidPenalty float32  `json:"avoidPenalty,omitempty"` // Logit bias subtracted from AvoidText tokens (0 = none)
+
+	Grammar string `json:"grammar,omitempty"` // GBNF grammar the sampler must follow (empty = unconstrained)
+}
+
+// withDefaults fills unset sampling options from the model configuration
+func (o PredictOptions) withDefaults(temperature, topP float32) PredictOptions {
+	if o.Temperature <= 0 {
+		o.Temperature = temperature
+	}
+	if o.TopP <= 0 {
+		o.TopP = topP
+	}
+	return o
 }
 
 // ProductionLLMModel interface defines the contract for production LLM models
@@ -303,6 +512,7 @@ type ProductionLLMModel interface {
 	Initialize() error
 	Predict(prompt string) (string, error)
 	PredictWithTimeout(ctx context.Context, prompt string) (string, error)
+	PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error)
 	EstimateTokens(text string) int
 	GetContextSize() int
 	GetModelInfo() ModelInfo
@@ -311,3 +521,12 @@ type ProductionLLMModel interface {
 
 // Ensure LlamaModel implements ProductionLLMModel
 var _ ProductionLLMModel = (*LlamaModel)(nil)
+
+// Ensure LlamaModel reports generation statistics
+var _ StatsPredictor = (*LlamaModel)(nil)
+
+// Ensure LlamaModel reuses cached prompt prefixes
+var _ PromptCacher = (*LlamaModel)(nil)
+
+// Ensure LlamaModel reports its hardware fit
+var _ HardwareFitReporter = (*LlamaModel)(nil)
@@ -2,6 +2,8 @@ package dialog
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -337,6 +339,65 @@ func TestLlamaModel_GetModelInfo(t *testing.T) {
 	}
 }
 
+func TestLlamaModel_LoraAdapter(t *testing.T) {
+	stubAvailableMemory(t, 1<<30)
+	base := writeGGUF(t, "base.Q4_K_M.gguf", map[string]interface{}{"general.architecture": "llama"}, 1024)
+	adapter := writeGGUF(t, "cheerful.gguf", map[string]interface{}{
+		"general.architecture": "llama",
+		"general.type":         "adapter",
+		"adapter.type":         "lora",
+	}, 64)
+
+	model, err := NewLlamaModel(LlamaConfig{ModelPath: base, LoraPath: adapter})
+	if err != nil {
+		t.Fatalf("Failed to create LlamaModel: %v", err)
+	}
+	if err := model.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize model with adapter: %v", err)
+	}
+	defer model.Free()
+	if info := model.GetModelInfo(); info.LoraPath != adapter || info.LoraScale != 1 {
+		t.Errorf("Expected the adapter at scale 1 in model info, got %q at %g", info.LoraPath, info.LoraScale)
+	}
+
+	for name, metadata := range map[string]map[string]interface{}{
+		"other architecture": {"general.architecture": "phi3", "general.type": "adapter", "adapter.type": "lora"},
+		"full model":         {"general.architecture": "llama", "general.type": "model"},
+	} {
+		wrong := writeGGUF(t, "wrong.gguf", metadata, 64)
+		model, err := NewLlamaModel(LlamaConfig{ModelPath: base, LoraPath: wrong})
+		if err != nil {
+			t.Fatalf("Failed to create LlamaModel: %v", err)
+		}
+		if err := model.Initialize(); err == nil {
+			t.Errorf("Expected an adapter for a %s to be rejected", name)
+		}
+	}
+
+	if _, err := NewLlamaModel(LlamaConfig{ModelPath: base, LoraPath: "/missing/adapter.gguf"}); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound for a missing adapter, got %v", err)
+	}
+	if _, err := NewLlamaModel(LlamaConfig{ModelPath: base, LoraPath: adapter, LoraScale: -1}); err == nil {
+		t.Error("Expected a negative loraScale to be rejected")
+	}
+}
+
+func TestLLMBackend_LoraPath(t *testing.T) {
+	stubAvailableMemory(t, 1<<30)
+	base := writeGGUF(t, "base.Q4_K_M.gguf", map[string]interface{}{"general.architecture": "llama"}, 1024)
+	adapter := writeGGUF(t, "cheerful.gguf", map[string]interface{}{"general.type": "adapter", "adapter.type": "lora"}, 64)
+
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: base, LoraPath: adapter, LoraScale: 0.6})
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if info := backend.model.GetModelInfo(); info.LoraPath != adapter || info.LoraScale != 0.6 {
+		t.Errorf("Expected the configured adapter at scale 0.6, got %q at %g", info.LoraPath, info.LoraScale)
+	}
+}
+
 func TestLlamaModel_Free(t *testing.T) {
 	tempDir := t.TempDir()
 	modelPath := filepath.Join(tempDir, "test_model.gguf")
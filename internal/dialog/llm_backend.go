@@ -3,7 +3,10 @@ package dialog
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -53,7 +56,7 @@ func (m *MockLLMModel) Predict(prompt string) (string, error) {
 	m.mu.RLock()
 	if !m.initialized {
 		m.mu.RUnlock()
-		return "", fmt.Errorf("mock model not initialized")
+		return "", fmt.Errorf("mock model %w", ErrNotInitialized)
 	}
 	m.mu.RUnlock()
 
@@ -61,7 +64,7 @@ func (m *MockLLMModel) Predict(prompt string) (string, error) {
 	time.Sleep(m.delay)
 
 	// Extract only the current situation to avoid contamination from conversation history
-	currentSituation := m.extractCurrentSituation(prompt)
+	currentSituation := extractCurrentSituation(prompt)
 
 	// Simple keyword-based response selection for more realistic behavior
 	currentSituation = strings.ToLower(currentSituation)
@@ -83,10 +86,30 @@ func (m *MockLLMModel) Predict(prompt string) (string, error) {
 		return "Your happiness makes me happy too! 😄✨", nil
 	default:
 		// Return a random response for unmatched prompts
-		return m.responses[int(time.Now().UnixNano())%len(m.responses)], nil
+		return m.responses[randomIntn(len(m.responses))], nil
 	}
 }
 
+// PredictWithOptions generates text honoring the context deadline
+// The mock has no sampler; it only honors AvoidText by switching to a different canned reply
+func (m *MockLLMModel) PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error) {
+	result, err := m.PredictWithTimeout(ctx, prompt)
+	if err != nil || !slices.Contains(opts.AvoidText, result) {
+		return result, err
+	}
+
+	var alternatives []string
+	for _, response := range m.responses {
+		if !slices.Contains(opts.AvoidText, response) {
+			alternatives = append(alternatives, response)
+		}
+	}
+	if len(alternatives) == 0 {
+		return result, nil
+	}
+	return alternatives[randomIntn(len(alternatives))], nil
+}
+
 // PredictWithTimeout generates text with a timeout context
 func (m *MockLLMModel) PredictWithTimeout(ctx context.Context, prompt string) (string, error) {
 	resultChan := make(chan string, 1)
@@ -107,7 +130,7 @@ func (m *MockLLMModel) PredictWithTimeout(ctx context.Context, prompt string) (s
 	case err := <-errorChan:
 		return "", err
 	case <-ctx.Done():
-		return "", fmt.Errorf("mock prediction timed out: %w", ctx.Err())
+		return "", fmt.Errorf("mock prediction interrupted: %w", deadlineError(ctx))
 	}
 }
 
@@ -149,7 +172,7 @@ func (m *MockLLMModel) Free() error {
 
 // extractCurrentSituation extracts just the "Current situation" section from the prompt
 // to avoid contamination from conversation history when detecting triggers
-func (m *MockLLMModel) extractCurrentSituation(prompt string) string {
+func extractCurrentSituation(prompt string) string {
 	// Look for the "Current situation:" section
 	lines := strings.Split(prompt, "\n")
 	inCurrentSituation := false
@@ -183,28 +206,78 @@ type LLMBackend struct {
 	model              ProductionLLMModel // Production model interface (LlamaModel or MockLLMModel)
 	mockModel          *MockLLMModel      // Legacy mock for fallback
 	useProductionModel bool               // Whether to use production or mock model
+	customModel        ProductionLLMModel // Supplied with SetModel, replacing loading from modelPath
 	modelPath          string
+	mockFixture        string         // Fixture file replacing the model, if set
+	loraPath           string         // LoRA adapter over the model, local or hf:// ("" = none)
+	loraScale          float32        // LoRA adapter strength (0 = the model's default)
+	modelRegistry      *ModelRegistry // Resolves LLMConfig.Model (nil = the registry at DefaultModelRegistryPath)
 	maxTokens          int
 	temperature        float32
 	topP               float32
 	contextSize        int
+	configuredContext  int // contextSize from the config (0 = follow the model's trained context)
 	threads            int
+	lowPriority        bool                      // Inference threads yield to foreground apps
+	disablePromptCache bool                      // Evaluate every prompt in full instead of reusing the KV cache
+	allowOvercommit    bool                      // Load models estimated not to fit in available memory
+	grammar            *Grammar                  // Constrains output when set
+	patterns           *PatternValidator         // Blocked and required response patterns, nil when not configured
+	structured         *structuredFormat         // JSON reply format, nil for plain text
+	tools              []ToolDefinition          // Host actions the model may request
+	toolSet            map[string]ToolDefinition // tools by name
+	calendar           []CalendarEvent           // Dated occasions mentioned in prompts on their day
+	persona            *PersonaBlend             // Weighted personality profiles, nil when not configured
+	systemPrompt       string                    // Instructions opening every prompt ("" = none)
+	promptStrategy     PromptStrategy            // Lays out prompts (nil = PromptBuilder.Build)
 
 	// Markov-based personality configuration (reuses existing character data)
 	markovConfig    MarkovChainConfig
-	trainingData    []string // Personality examples from Markov training data
-	fallbackPhrases []string // Fallback responses from Markov config
+	trainingData    []string         // Personality examples from Markov training data
+	fallbackPhrases []string         // Fallback responses from Markov config
+	exampleSelector *ExampleSelector // Retrieves training examples relevant to the situation
+	fewShotExamples int              // Number of examples included in the prompt
+
+	personaSummaryMode string // How training data is distilled into a persona ("" = list examples)
+	personaSummary     string // Persona paragraph cached at Initialize ("" = none)
 
 	// Context management
 	contextManager   *ContextManager
+	redactor         *Redactor
 	maxHistoryLength int
+	maxHistoryBytes  int64
+	compressHistory  bool
+	historySelection string
+	historyExchanges int
+
+	// Isolated history per DialogContext.CharacterID and TenantID, created on first use
+	characterContexts map[string]*ContextManager
+	tenantContexts    map[string]*tenantHistory
+	historyMu         sync.Mutex
+
+	// Response validation and regeneration
+	validators       []ResponseValidator // Built from ValidationConfig
+	customValidators []ResponseValidator // Registered via AddValidator
+	reranker         Reranker            // Blended into variant scores (nil = confidence only)
+	maxRegenerations int
+	temperatureStep  float32
 
 	// Performance and reliability
 	timeout         time.Duration
+	retryPolicy     retryPolicy
+	queue           *generationQueue // Bounds concurrent generations (nil = unlimited)
+	adaptive        *adaptiveQuality // Trims maxTokens under load (nil = disabled)
+	health          healthTracker
+	events          *EventBus
 	fallbackEnabled bool
 	initialized     bool
 	mu              sync.RWMutex
 
+	// Set by LLMOptions
+	baseConfig LLMConfig   // Configuration Initialize's JSON is applied over
+	logger     *log.Logger // Receives warnings (nil = standard output)
+	clock      Clock       // Timestamps history and dates prompts (nil = the package clock)
+
 	// Backend metadata
 	info BackendInfo
 }
@@ -213,22 +286,117 @@ type LLMBackend struct {
 // Uses existing Markov chain configuration for personality and training data
 type LLMConfig struct {
 	// Model configuration
-	ModelPath   string  `json:"modelPath"`   // Path to GGUF model file
-	MaxTokens   int     `json:"maxTokens"`   // Maximum tokens per response (default: 50)
-	Temperature float32 `json:"temperature"` // Sampling temperature (default: 0.7)
-	TopP        float32 `json:"topP"`        // Top-p sampling (default: 0.9)
-	ContextSize int     `json:"contextSize"` // Model context window (default: 2048)
-	Threads     int     `json:"threads"`     // CPU threads to use (default: 4)
+	ModelPath   string  `json:"modelPath"`             // Path to GGUF model file, or hf://<owner>/<repo>/<file> to download it from the HuggingFace Hub
+	Model       string  `json:"model,omitempty"`       // Model alias from the models.json registry, used when modelPath is unset
+	MaxTokens   int     `json:"maxTokens"`             // Maximum tokens per response (default: 50)
+	Temperature float32 `json:"temperature"`           // Sampling temperature (default: 0.7)
+	TopP        float32 `json:"topP"`                  // Top-p sampling (default: 0.9)
+	ContextSize int     `json:"contextSize"`           // Model context window, clamped to the model's trained context (default: trained context, at most 2048)
+	Threads     int     `json:"threads"`               // CPU threads to use; 0 matches the CPU topology (default: 0, see AutoThreadCount)
+	LowPriority bool    `json:"lowPriority,omitempty"` // Run inference at low OS priority so foreground apps stay responsive
+
+	// ModelCacheDir keeps models downloaded for hf:// paths; later runs load them from here
+	// without network access (default: $MINILM_MODEL_CACHE, or minilm/models in the user cache directory)
+	ModelCacheDir string `json:"modelCacheDir,omitempty"`
+
+	// ModelSHA256 is the expected checksum of an hf:// download; when unset the checksum
+	// the Hub reports for the file is used
+	ModelSHA256 string `json:"modelSha256,omitempty"`
+
+	// DownloadTimeoutMs limits each hf:// download made while initializing (default: 30 minutes)
+	DownloadTimeoutMs int `json:"downloadTimeoutMs,omitempty"`
+
+	// LoraPath applies a GGUF LoRA adapter (local or hf://) over the model, so characters
+	// can ship a small personality adapter on top of one shared base model
+	LoraPath  string  `json:"loraPath,omitempty"`
+	LoraScale float32 `json:"loraScale,omitempty"` // Adapter strength (default: 1)
+
+	// DisablePromptCache evaluates every prompt in full; by default the KV cache is
+	// reused for the prefix (personality and character state) shared with the previous turn
+	DisablePromptCache bool `json:"disablePromptCache,omitempty"`
+
+	// AllowMemoryOvercommit loads the model even when its estimated RAM needs exceed
+	// available memory; otherwise Initialize fails with ErrInsufficientMemory
+	AllowMemoryOvercommit bool `json:"allowMemoryOvercommit,omitempty"`
+
+	// Grammar constrains output to a GBNF grammar (e.g. a JSON shape or fixed sentence
+	// forms); GrammarFile reads it from a .gbnf file instead. Responses that do not
+	// match are regenerated, then replaced by the fallback
+	Grammar     string `json:"grammar,omitempty"`
+	GrammarFile string `json:"grammarFile,omitempty"`
+
+	// BlockedPatterns and RequiredPatterns are Go regular expressions checked against
+	// each response, e.g. "(?i)as an ai" or "~$". A response matching a blocked pattern
+	// or missing a required one is regenerated, then replaced by the fallback
+	BlockedPatterns  []string `json:"blockedPatterns,omitempty"`
+	RequiredPatterns []string `json:"requiredPatterns,omitempty"`
+
+	// ResponseFormat "json" asks and constrains the model to reply with
+	// {"text", "emotion", "animation"}, which replaces the keyword heuristics for
+	// animation and tone; values must come from the lists below (default: "text")
+	ResponseFormat     string   `json:"responseFormat,omitempty"`
+	ResponseAnimations []string `json:"responseAnimations,omitempty"` // Animations the model may choose (default: talking, happy, sad, eating)
+	ResponseEmotions   []string `json:"responseEmotions,omitempty"`   // Emotions the model may choose (default: happy, sad, excited, shy, flirty, neutral)
+
+	// Tools are host actions the character may request; they are listed in the prompt
+	// and requests parsed from the output are returned in DialogResponse.Actions
+	Tools []ToolDefinition `json:"tools,omitempty"`
+
+	// Events are dated occasions (birthdays, holidays, anniversaries); on an event's date
+	// the prompt mentions it and DialogManager.DueCalendarEvents reports it for a greeting
+	Events []CalendarEvent `json:"events,omitempty"`
+
+	// Triggers describe host-defined triggers (e.g. "homework_done") for the prompt and
+	// give them a default animation; they are registered package-wide like RegisterTrigger
+	Triggers []TriggerDefinition `json:"triggers,omitempty"`
+
+	// MockFixture replaces the model with a FixtureModel loaded from this JSON file,
+	// giving host applications deterministic responses, latency and failures in tests
+	MockFixture string `json:"mockFixture,omitempty"`
+
+	// SystemPrompt opens every prompt with the character's standing instructions,
+	// e.g. the persona and scenario of an imported character card
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+
+	// Personas blends weighted personality profiles (e.g. 70% cheerful companion,
+	// 30% sarcastic gamer) into the prompt; training data examples are still included
+	Personas []PersonaProfile `json:"personas,omitempty"`
 
 	// Markov-based personality configuration (compatible with existing character format)
 	MarkovConfig MarkovChainConfig `json:"markov_chain"` // Reuse existing Markov configuration
 
+	FewShotExamples int `json:"fewShotExamples,omitempty"` // Training examples selected per prompt (default: 3)
+
+	// PersonaSummary distills trainingData into a short persona paragraph at Initialize,
+	// used in prompts with one example line instead of fewShotExamples raw lines:
+	// "heuristic" or "model" (default: "", raw lines only)
+	PersonaSummary string `json:"personaSummary,omitempty"`
+
 	// Context management
-	MaxHistoryLength int `json:"maxHistoryLength"` // Max conversation history (default: 10)
+	MaxHistoryLength int    `json:"maxHistoryLength"`           // Max conversation history (default: 10)
+	MaxHistoryBytes  int    `json:"maxHistoryBytes,omitempty"`  // Estimated memory cap per history store (0 = unlimited)
+	CompressHistory  bool   `json:"compressHistory,omitempty"`  // Summarize history instead of dropping it when the prompt is over budget
+	HistorySelection string `json:"historySelection,omitempty"` // "important" or "relevant" exchanges in the prompt (default: "important")
+	HistoryExchanges int    `json:"historyExchanges,omitempty"` // Past exchanges included in the prompt (default: 5)
 
 	// Performance settings
 	TimeoutMs       int  `json:"timeoutMs"`       // Response timeout in ms (default: 2000)
 	FallbackEnabled bool `json:"fallbackEnabled"` // Enable fallback on failure (default: true)
+
+	// Generations run at once; further requests wait, interactive before background (0 = unlimited)
+	MaxConcurrentGenerations int `json:"maxConcurrentGenerations,omitempty"`
+
+	// Latency-adaptive maxTokens, shortening responses to stay under an interactive target
+	AdaptiveQuality AdaptiveQualityConfig `json:"adaptiveQuality,omitempty"`
+
+	// Response validation and regeneration policy
+	Validation ValidationConfig `json:"validation,omitempty"`
+
+	// Retry policy for transient failures (timeouts, busy model)
+	Retry RetryConfig `json:"retry,omitempty"`
+
+	// PII redaction of conversation history
+	Redaction RedactionConfig `json:"redaction,omitempty"`
 }
 
 // MarkovChainConfig represents the existing Markov chain configuration
@@ -245,16 +413,23 @@ type MarkovChainConfig struct {
 }
 
 // NewLLMBackend creates a new LLM-powered dialog backend
-// Uses conservative defaults optimized for consumer CPU hardware
-func NewLLMBackend() *LLMBackend {
-	return &LLMBackend{
+// Uses conservative defaults optimized for consumer CPU hardware; options such as
+// WithModelPath configure it without building JSON
+func NewLLMBackend(opts ...LLMOption) *LLMBackend {
+	llm := &LLMBackend{
 		maxTokens:        50,
 		temperature:      0.7,
 		topP:             0.9,
-		contextSize:      2048,
+		contextSize:      defaultContextSize,
 		threads:          4,
 		maxHistoryLength: 10,
+		historySelection: HistorySelectionImportant,
+		historyExchanges: defaultPromptHistory,
+		fewShotExamples:  3,
+		maxRegenerations: 2,
+		temperatureStep:  0.15,
 		timeout:          2 * time.Second,
+		retryPolicy:      defaultRetryPolicy(),
 		fallbackEnabled:  true,
 		contextManager:   NewContextManager(10),
 		info: BackendInfo{
@@ -262,28 +437,61 @@ func NewLLMBackend() *LLMBackend {
 			Version:     "1.0.0",
 			Description: "LLM-powered dialog backend using llama.cpp for CPU inference",
 			Capabilities: []string{
-				"context_aware",
-				"personality_driven",
-				"learning_enabled",
-				"streaming_capable",
+				CapabilityContextAware,
+				CapabilityPersonalityDriven,
+				CapabilityLearning,
 			},
 			Author:  "MiniLM Project",
 			License: "MIT",
 		},
 	}
+	for _, opt := range opts {
+		opt(llm)
+	}
+	return llm
 }
 
 // Initialize sets up the LLM backend with the provided JSON configuration
-// This method handles model loading and validation
+// This method handles model loading and validation; the JSON is applied over the
+// configuration given by options, and may be empty when options say everything
 func (llm *LLMBackend) Initialize(config json.RawMessage) error {
-	llm.mu.Lock()
-	defer llm.mu.Unlock()
+	cfg := llm.baseConfig
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return fmt.Errorf("failed to parse LLM config: %w", err)
+		}
+	}
+	return llm.InitializeConfig(cfg)
+}
+
+// InitializeContext is Initialize with a context that cancels hf:// model downloads
+func (llm *LLMBackend) InitializeContext(ctx context.Context, config json.RawMessage) error {
+	cfg := llm.baseConfig
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &cfg); err != nil {
+			return fmt.Errorf("failed to parse LLM config: %w", err)
+		}
+	}
+	return llm.initializeConfig(ctx, cfg)
+}
 
-	var cfg LLMConfig
-	if err := json.Unmarshal(config, &cfg); err != nil {
-		return fmt.Errorf("failed to parse LLM config: %w", err)
+// InitializeConfig sets up the LLM backend from a configuration struct, for hosts that
+// build it in code rather than decoding JSON
+func (llm *LLMBackend) InitializeConfig(cfg LLMConfig) error {
+	return llm.initializeConfig(context.Background(), cfg)
+}
+
+// initializeConfig downloads hf:// models, then applies the config and loads the model
+// Downloads can take minutes, so they run before the backend is locked
+func (llm *LLMBackend) initializeConfig(ctx context.Context, cfg LLMConfig) error {
+	cfg, err := llm.fetchHubModels(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load model: %w", err)
 	}
 
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+
 	// Apply configuration with defaults
 	if err := llm.applyConfig(cfg); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
@@ -293,6 +501,7 @@ func (llm *LLMBackend) Initialize(config json.RawMessage) error {
 	if err := llm.loadModel(); err != nil {
 		return fmt.Errorf("failed to load model: %w", err)
 	}
+	llm.summarizePersona()
 
 	llm.initialized = true
 	return nil
@@ -300,25 +509,130 @@ func (llm *LLMBackend) Initialize(config json.RawMessage) error {
 
 // applyConfig applies the provided configuration with sensible defaults
 func (llm *LLMBackend) applyConfig(cfg LLMConfig) error {
+	cfg, err := llm.resolveModelAlias(cfg)
+	if err != nil {
+		return err
+	}
 	if err := llm.validateAndSetModelPath(cfg.ModelPath); err != nil {
 		return err
 	}
+	llm.mockFixture = cfg.MockFixture
+	if err := llm.applyModelSource(cfg); err != nil {
+		return err
+	}
+
+	if err := llm.applyHistorySelection(cfg); err != nil {
+		return err
+	}
+
+	if err := llm.applyGrammar(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyPatterns(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyTools(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyResponseFormat(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyCalendar(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyTriggers(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyPersonas(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyPersonaSummary(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyRedaction(cfg.Redaction); err != nil {
+		return err
+	}
+	if err := llm.applyGenerationQueue(cfg); err != nil {
+		return err
+	}
+	if err := llm.applyAdaptiveQuality(cfg.AdaptiveQuality); err != nil {
+		return err
+	}
+	if cfg.MaxHistoryBytes < 0 {
+		return configErrorf("maxHistoryBytes", "maxHistoryBytes must be non-negative, got %d", cfg.MaxHistoryBytes)
+	}
+	if cfg.Threads < 0 {
+		return configErrorf("threads", "threads must be non-negative, got %d", cfg.Threads)
+	}
 
 	llm.applyOptionalParameters(cfg)
 	llm.configureMarkovSettings(cfg)
+	llm.configureValidation(cfg.Validation)
 
 	return nil
 }
 
 // validateAndSetModelPath validates the model path and sets it on the backend
 func (llm *LLMBackend) validateAndSetModelPath(modelPath string) error {
-	if modelPath == "" {
-		return fmt.Errorf("modelPath is required")
+	if modelPath == "" && llm.customModel == nil {
+		return configErrorf("modelPath", "modelPath or model is required")
 	}
 	llm.modelPath = modelPath
 	return nil
 }
 
+// applyHistorySelection configures which past exchanges are included in prompts
+func (llm *LLMBackend) applyHistorySelection(cfg LLMConfig) error {
+	switch cfg.HistorySelection {
+	case "":
+	case HistorySelectionImportant, HistorySelectionRelevant:
+		llm.historySelection = cfg.HistorySelection
+	default:
+		return configErrorf("historySelection", "historySelection must be %q or %q, got %q",
+			HistorySelectionImportant, HistorySelectionRelevant, cfg.HistorySelection)
+	}
+	if cfg.HistoryExchanges > 0 {
+		llm.historyExchanges = cfg.HistoryExchanges
+	}
+	return nil
+}
+
+// applyGrammar parses the configured output grammar, if any
+func (llm *LLMBackend) applyGrammar(cfg LLMConfig) error {
+	var err error
+	switch {
+	case cfg.Grammar != "" && cfg.GrammarFile != "":
+		return configErrorf("grammar", "grammar and grammarFile are mutually exclusive")
+	case cfg.Grammar != "":
+		if llm.grammar, err = ParseGrammar(cfg.Grammar); err != nil {
+			return invalidConfig("grammar", err)
+		}
+	case cfg.GrammarFile != "":
+		if llm.grammar, err = LoadGrammarFile(cfg.GrammarFile); err != nil {
+			return invalidConfig("grammarFile", err)
+		}
+	}
+	return nil
+}
+
+// applyPatterns compiles the configured blocked and required response patterns
+func (llm *LLMBackend) applyPatterns(cfg LLMConfig) error {
+	llm.patterns = nil
+	if len(cfg.BlockedPatterns) == 0 && len(cfg.RequiredPatterns) == 0 {
+		return nil
+	}
+	blocked, err := compilePatterns(cfg.BlockedPatterns)
+	if err != nil {
+		return invalidConfig("blockedPatterns", err)
+	}
+	required, err := compilePatterns(cfg.RequiredPatterns)
+	if err != nil {
+		return invalidConfig("requiredPatterns", err)
+	}
+	llm.patterns = &PatternValidator{Blocked: blocked, Required: required}
+	return nil
+}
+
 // applyOptionalParameters applies optional configuration parameters with defaults
 func (llm *LLMBackend) applyOptionalParameters(cfg LLMConfig) {
 	llm.applyLLMParameters(cfg)
@@ -337,20 +651,29 @@ func (llm *LLMBackend) applyLLMParameters(cfg LLMConfig) {
 	if cfg.TopP > 0 {
 		llm.topP = cfg.TopP
 	}
+	llm.systemPrompt = strings.TrimSpace(cfg.SystemPrompt)
 }
 
 // applyContextParameters configures context and execution parameters
 func (llm *LLMBackend) applyContextParameters(cfg LLMConfig) {
+	llm.configuredContext = max(cfg.ContextSize, 0)
 	if cfg.ContextSize > 0 {
 		llm.contextSize = cfg.ContextSize
 	}
-	if cfg.Threads > 0 {
-		llm.threads = cfg.Threads
+	llm.threads = cfg.Threads
+	if cfg.Threads == 0 {
+		llm.threads = AutoThreadCount()
 	}
+	llm.lowPriority = cfg.LowPriority
+	llm.compressHistory = cfg.CompressHistory
+	llm.disablePromptCache = cfg.DisablePromptCache
+	llm.allowOvercommit = cfg.AllowMemoryOvercommit
+	llm.maxHistoryBytes = int64(cfg.MaxHistoryBytes)
 	if cfg.MaxHistoryLength > 0 {
 		llm.maxHistoryLength = cfg.MaxHistoryLength
-		llm.contextManager = NewContextManager(cfg.MaxHistoryLength)
+		llm.contextManager = llm.newHistoryStore(0)
 	}
+	llm.contextManager.SetMaxBytes(llm.maxHistoryBytes)
 }
 
 // applyTimeoutParameters configures timeout-related parameters
@@ -358,6 +681,40 @@ func (llm *LLMBackend) applyTimeoutParameters(cfg LLMConfig) {
 	if cfg.TimeoutMs > 0 {
 		llm.timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
 	}
+	llm.retryPolicy = newRetryPolicy(cfg.Retry)
+}
+
+// responseTimeout is the request's TimeoutMs when set, otherwise the configured timeout
+func (llm *LLMBackend) responseTimeout(ctx DialogContext) time.Duration {
+	if ctx.TimeoutMs > 0 {
+		return time.Duration(ctx.TimeoutMs) * time.Millisecond
+	}
+	return llm.timeout
+}
+
+// configureValidation builds response validators and the regeneration policy
+func (llm *LLMBackend) configureValidation(cfg ValidationConfig) {
+	llm.validators = buildValidators(cfg)
+	if cfg.RepetitionWindow > 0 {
+		llm.validators = append(llm.validators, RepetitionValidator{
+			History:    llm.contextManager,
+			Window:     cfg.RepetitionWindow,
+			Threshold:  cfg.RepetitionThreshold,
+			historyFor: llm.contextFor,
+		})
+	}
+	if llm.grammar != nil {
+		llm.validators = append(llm.validators, GrammarValidator{Grammar: llm.grammar})
+	}
+	if llm.patterns != nil {
+		llm.validators = append(llm.validators, *llm.patterns)
+	}
+	if cfg.MaxRegenerations > 0 {
+		llm.maxRegenerations = cfg.MaxRegenerations
+	}
+	if cfg.TemperatureStep > 0 {
+		llm.temperatureStep = cfg.TemperatureStep
+	}
 }
 
 // configureMarkovSettings configures Markov-based personality settings
@@ -366,11 +723,22 @@ func (llm *LLMBackend) configureMarkovSettings(cfg LLMConfig) {
 	llm.trainingData = cfg.MarkovConfig.TrainingData
 	llm.fallbackPhrases = cfg.MarkovConfig.FallbackPhrases
 	llm.fallbackEnabled = cfg.FallbackEnabled
+	llm.exampleSelector = NewExampleSelector(cfg.MarkovConfig.TrainingData)
+	if cfg.FewShotExamples > 0 {
+		llm.fewShotExamples = cfg.FewShotExamples
+	}
 }
 
 // loadModel initializes either production LLM model or mock model
 // Attempts to load production model first, falls back to mock if needed
 func (llm *LLMBackend) loadModel() error {
+	if llm.customModel != nil {
+		return llm.useCustomModel(llm.customModel)
+	}
+	if llm.mockFixture != "" {
+		return llm.loadFixtureModel()
+	}
+
 	// Try to load production model if path points to actual GGUF file
 	if strings.HasSuffix(llm.modelPath, ".gguf") {
 		// Check if file exists to determine if we should attempt production loading
@@ -379,12 +747,17 @@ func (llm *LLMBackend) loadModel() error {
 		if err == nil {
 			llm.model = productionModel
 			llm.useProductionModel = true
+			// Budget prompts for the window the model actually got
+			llm.contextSize = productionModel.GetContextSize()
 			return nil
 		}
+		if errors.Is(err, ErrInsufficientMemory) {
+			return err
+		}
 
 		// Log the production model failure but continue with mock
 		// In production, you might want to return the error instead
-		fmt.Printf("Production model loading failed (%v), falling back to mock model\n", err)
+		llm.logf("Production model loading failed (%v), falling back to mock model", err)
 	}
 
 	// Use mock model as fallback or if not using production model
@@ -399,15 +772,80 @@ func (llm *LLMBackend) loadModel() error {
 	return nil
 }
 
+// SetModel supplies the model implementation, e.g. remote inference or a custom runtime,
+// instead of loading one from modelPath; modelPath and model become optional. Before
+// Initialize the model is used once configuration succeeds; afterwards it is initialized
+// and replaces the current model at once. Close frees it like a loaded model
+func (llm *LLMBackend) SetModel(model ProductionLLMModel) error {
+	if model == nil {
+		return fmt.Errorf("model must not be nil")
+	}
+
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+
+	llm.customModel = model
+	if !llm.initialized {
+		return nil
+	}
+	previous := llm.model
+	if err := llm.useCustomModel(model); err != nil {
+		return err
+	}
+	if previous != nil && previous != model {
+		previous.Free()
+	}
+	return nil
+}
+
+// useCustomModel initializes and switches to a model supplied with SetModel
+func (llm *LLMBackend) useCustomModel(model ProductionLLMModel) error {
+	if err := model.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize model: %w", err)
+	}
+	llm.model = model
+	llm.useProductionModel = true
+	// Budget prompts for the smaller of the configured and the model's window
+	if size := model.GetContextSize(); size > 0 {
+		llm.contextSize = min(llm.contextSize, size)
+	}
+	return nil
+}
+
+// loadFixtureModel replaces the model with the configured response fixture
+func (llm *LLMBackend) loadFixtureModel() error {
+	fixture, err := LoadModelFixture(llm.mockFixture)
+	if err != nil {
+		return err
+	}
+	model, err := NewFixtureModel(fixture)
+	if err != nil {
+		return err
+	}
+	if err := model.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize fixture model: %w", err)
+	}
+
+	llm.model = model
+	llm.useProductionModel = false
+	return nil
+}
+
 // tryLoadProductionModel attempts to load a production LLM model
 // NOTE: Currently always returns mock implementation - real llama.cpp integration planned
 func (llm *LLMBackend) tryLoadProductionModel() (ProductionLLMModel, error) {
 	config := LlamaConfig{
 		ModelPath:   llm.modelPath,
-		ContextSize: llm.contextSize,
+		ContextSize: llm.configuredContext,
 		Threads:     llm.threads,
+		LowPriority: llm.lowPriority,
 		Temperature: llm.temperature,
 		TopP:        llm.topP,
+		PromptCache: !llm.disablePromptCache,
+		LoraPath:    llm.loraPath,
+		LoraScale:   llm.loraScale,
+
+		AllowMemoryOvercommit: llm.allowOvercommit,
 	}
 
 	// NOTE: This creates a mock model, not a real llama.cpp model
@@ -430,7 +868,7 @@ func (llm *LLMBackend) GenerateResponse(ctx DialogContext) (DialogResponse, erro
 	llm.mu.RLock()
 	if !llm.initialized {
 		llm.mu.RUnlock()
-		return DialogResponse{}, fmt.Errorf("LLM backend not initialized")
+		return DialogResponse{}, fmt.Errorf("LLM backend %w", ErrNotInitialized)
 	}
 	llm.mu.RUnlock()
 
@@ -438,10 +876,12 @@ func (llm *LLMBackend) GenerateResponse(ctx DialogContext) (DialogResponse, erro
 	prompt := llm.buildPrompt(ctx)
 
 	// Generate response with timeout
-	responseCtx, cancel := context.WithTimeout(context.Background(), llm.timeout)
+	responseCtx, cancel := context.WithTimeout(ctx.requestContext(), llm.responseTimeout(ctx))
 	defer cancel()
 
-	response, err := llm.generateWithTimeout(responseCtx, prompt)
+	generation, err := llm.scheduleGeneration(responseCtx, ctx.Priority, func() (generationResult, error) {
+		return llm.generateValidated(responseCtx, ctx, prompt)
+	})
 	if err != nil {
 		if llm.fallbackEnabled {
 			return llm.createFallbackResponse(ctx), nil
@@ -449,14 +889,18 @@ func (llm *LLMBackend) GenerateResponse(ctx DialogContext) (DialogResponse, erro
 		return DialogResponse{}, fmt.Errorf("failed to generate response: %w", err)
 	}
 
-	// Update conversation context
-	llm.contextManager.AddExchange(ctx.InteractionID, ctx.Trigger, response)
+	dialogResponse := llm.newDialogResponse(ctx, generation)
+	llm.recordResponse(ctx, dialogResponse)
+	return dialogResponse, nil
+}
 
-	// Create structured response
+// newDialogResponse builds a structured response from generated text
+func (llm *LLMBackend) newDialogResponse(ctx DialogContext, generation generationResult) DialogResponse {
+	response := generation.text
 	dialogResponse := DialogResponse{
 		Text:             response,
 		Animation:        llm.selectAnimation(ctx, response),
-		Confidence:       0.8, // High confidence for successful LLM generation
+		Confidence:       generation.confidence(),
 		ResponseType:     llm.classifyResponse(response),
 		EmotionalTone:    llm.detectEmotionalTone(response),
 		Topics:           llm.extractTopics(response),
@@ -464,90 +908,180 @@ func (llm *LLMBackend) GenerateResponse(ctx DialogContext) (DialogResponse, erro
 		LearningValue:    0.6,
 	}
 
-	return dialogResponse, nil
+	if llm.structured == nil && len(llm.tools) == 0 {
+		return dialogResponse
+	}
+
+	output, err := llm.parseOutput(response)
+	if err != nil {
+		return dialogResponse
+	}
+	dialogResponse.Text = output.text
+	dialogResponse.Actions = output.actions
+	dialogResponse.ResponseType = llm.classifyResponse(output.text)
+	dialogResponse.Topics = llm.extractTopics(output.text)
+	if llm.structured != nil {
+		// In JSON mode the model chose the animation and tone itself
+		dialogResponse.Animation = output.animation
+		dialogResponse.EmotionalTone = output.emotion
+	} else {
+		dialogResponse.Animation = llm.selectAnimation(ctx, output.text)
+		dialogResponse.EmotionalTone = llm.detectEmotionalTone(output.text)
+	}
+	return dialogResponse
 }
 
-// generateWithTimeout generates a response with the given context and timeout
-func (llm *LLMBackend) generateWithTimeout(ctx context.Context, prompt string) (string, error) {
-	// Channel to receive the result
-	resultChan := make(chan string, 1)
-	errorChan := make(chan error, 1)
+// recordResponse adds a response to the conversation context
+func (llm *LLMBackend) recordResponse(ctx DialogContext, response DialogResponse) {
+	llm.contextFor(ctx).RecordExchange(ctx.InteractionID, ConversationExchange{
+		Trigger:      ctx.Trigger,
+		UserMessage:  userUtterance(ctx),
+		Response:     response.Text,
+		ResponseType: response.ResponseType,
+		Importance:   response.MemoryImportance,
+	})
+}
+
+// generateWithTimeout generates a response with the given context, timeout and sampling options
+func (llm *LLMBackend) generateWithTimeout(ctx context.Context, prompt string, opts PredictOptions) (generationResult, error) {
+	// Channel to receive the result; buffered so the goroutine never blocks after a timeout
+	type outcome struct {
+		result generationResult
+		err    error
+	}
+	done := make(chan outcome, 1)
 
 	// Generate response in a goroutine
 	go func() {
-		// Use mock model - in production this would use actual LLM prediction
-		result, err := llm.model.Predict(prompt)
+		result, stats, err := llm.predict(ctx, prompt, opts)
 		if err != nil {
-			errorChan <- err
+			done <- outcome{err: err}
 			return
 		}
 
 		// Clean and validate the response
-		cleaned := llm.cleanResponse(result)
-		resultChan <- cleaned
+		cleaned, truncated, empty := llm.cleanResponseText(result)
+		done <- outcome{result: generationResult{text: cleaned, stats: stats, truncated: truncated, empty: empty}}
 	}()
 
 	// Wait for result or timeout
 	select {
-	case result := <-resultChan:
-		return result, nil
-	case err := <-errorChan:
-		return "", err
+	case outcome := <-done:
+		return outcome.result, outcome.err
 	case <-ctx.Done():
-		return "", fmt.Errorf("response generation timed out after %v", llm.timeout)
+		return generationResult{}, fmt.Errorf("response generation timed out: %w", ErrTimeout)
 	}
 }
 
 // buildPrompt constructs a prompt from the dialog context and character configuration
 func (llm *LLMBackend) buildPrompt(ctx DialogContext) string {
+	return llm.renderPrompt(llm.newPromptBuilder(ctx))
+}
+
+// newPromptBuilder prepares a prompt builder with personality, history and context for ctx
+func (llm *LLMBackend) newPromptBuilder(ctx DialogContext) *PromptBuilder {
 	builder := NewPromptBuilder()
+	builder.SetClock(llm.clock)
+	builder.SetHistoryCompression(llm.compressHistory)
+	var format []string
+	if llm.structured != nil {
+		format = append(format, llm.structured.instruction())
+	}
+	if len(llm.tools) > 0 {
+		format = append(format, toolInstructions(llm.tools, llm.structured != nil))
+	}
+	builder.SetResponseFormat(strings.Join(format, "\n"))
+	if llm.systemPrompt != "" {
+		builder.AddSystemPrompt(llm.systemPrompt)
+	}
+
+	// Leave room in the context window for the reply
+	if budget := llm.contextSize - llm.maxTokens; budget > 0 && budget < defaultPromptTokens {
+		builder.SetMaxTokens(budget)
+	}
 
-	// Extract personality from Markov training data
-	personality := llm.extractPersonality()
-	if personality != "" {
-		builder.AddPersonality(personality)
+	// Extract personality from the training examples most relevant to this situation,
+	// on top of the persona blend when one is configured
+	if llm.persona != nil {
+		builder.SetPersonaBlend(llm.persona)
+	}
+	if llm.persona == nil || len(llm.markovConfig.TrainingData) > 0 {
+		if personality := llm.extractPersonality(ctx); personality != "" {
+			builder.AddPersonality(personality)
+		}
 	}
 
-	// Add conversation history
-	history := llm.contextManager.GetHistory(ctx.InteractionID, 5)
+	// Add conversation history, keeping the most important or most relevant exchanges;
+	// a shared multi-character transcript takes the place of this backend's history
+	var history []ConversationExchange
+	if len(ctx.Conversation) > 0 {
+		history = ctx.Conversation
+	} else if llm.historySelection == HistorySelectionRelevant {
+		history = llm.contextFor(ctx).GetRelevantHistory(ctx.InteractionID, ctx, llm.historyExchanges)
+	} else {
+		history = llm.contextFor(ctx).GetImportantHistory(ctx.InteractionID, llm.historyExchanges)
+	}
+	builder.SetMaxHistory(llm.historyExchanges)
 	builder.AddHistory(history)
 
+	// Mention occasions falling on the context's date
+	date := ctx.Timestamp
+	if date.IsZero() {
+		date = llm.now()
+	}
+	for _, event := range calendarEventsOn(llm.calendar, date) {
+		builder.AddEvent(describeCalendarEvent(event, date))
+	}
+
 	// Add current context
 	builder.AddContext(ctx)
 
-	return builder.Build()
+	return builder
 }
 
 // extractPersonality creates a personality description from Markov training data
-func (llm *LLMBackend) extractPersonality() string {
+// Selects the examples most similar to the current trigger and topics as few-shot guidance
+func (llm *LLMBackend) extractPersonality(ctx DialogContext) string {
 	if len(llm.markovConfig.TrainingData) == 0 {
 		return "You are a helpful AI assistant."
 	}
-
-	// Take first few training examples as personality indicators
-	var personalityExamples []string
-	limit := 3
-	if len(llm.markovConfig.TrainingData) < limit {
-		limit = len(llm.markovConfig.TrainingData)
+	if llm.personaSummary != "" {
+		return llm.summarizedPersonality(ctx)
 	}
 
-	for i := 0; i < limit; i++ {
-		personalityExamples = append(personalityExamples, llm.markovConfig.TrainingData[i])
+	selector := llm.exampleSelector
+	if selector == nil {
+		selector = NewExampleSelector(llm.markovConfig.TrainingData)
 	}
+	personalityExamples := selector.Select(buildExampleQuery(ctx), llm.fewShotExamples)
 
 	// Create personality description from examples
-	personality := "Based on these example responses, respond in a similar tone and style:\n"
+	var personality strings.Builder
+	personality.WriteString("Based on these example responses, respond in a similar tone and style:\n")
 	for _, example := range personalityExamples {
-		personality += "- " + example + "\n"
+		personality.WriteString("- ")
+		personality.WriteString(example)
+		personality.WriteString("\n")
 	}
 
-	return personality
+	return personality.String()
 }
 
 // cleanResponse processes the raw LLM output to ensure it's suitable for display
 func (llm *LLMBackend) cleanResponse(response string) string {
+	cleaned, _, _ := llm.cleanResponseText(response)
+	return cleaned
+}
+
+// cleanResponseText cleans a response and reports whether it was truncated or replaced
+func (llm *LLMBackend) cleanResponseText(response string) (cleaned string, truncated, empty bool) {
 	// Remove common LLM artifacts
-	cleaned := strings.TrimSpace(response)
+	cleaned = strings.TrimSpace(response)
+
+	// Grammar-constrained and JSON output is structured; quotes and length are part of it
+	if llm.grammar != nil || llm.structured != nil {
+		return cleaned, false, cleaned == ""
+	}
 
 	// Remove leading/trailing quotes if present
 	if (strings.HasPrefix(cleaned, `"`) && strings.HasSuffix(cleaned, `"`)) ||
@@ -560,15 +1094,17 @@ func (llm *LLMBackend) cleanResponse(response string) string {
 		sentences := strings.Split(cleaned, ". ")
 		if len(sentences) > 2 {
 			cleaned = strings.Join(sentences[:2], ". ") + "."
+			truncated = true
 		}
 	}
 
 	// Ensure we have some content
 	if len(strings.TrimSpace(cleaned)) == 0 {
 		cleaned = "Hello! 👋"
+		empty = true
 	}
 
-	return cleaned
+	return cleaned, truncated, empty
 }
 
 // selectAnimation chooses an appropriate animation based on response content
@@ -586,7 +1122,10 @@ func (llm *LLMBackend) selectAnimation(ctx DialogContext, response string) strin
 		return "eating"
 	}
 
-	// Default to talking animation
+	// Default to the trigger's registered animation, then talking
+	if animation := triggerAnimation(ctx.Trigger); animation != "" {
+		return animation
+	}
 	return "talking"
 }
 
@@ -625,38 +1164,29 @@ func (llm *LLMBackend) detectEmotionalTone(response string) string {
 	return "neutral"
 }
 
+// topicKeywords maps response keywords to topics, in the order topics are reported
+var topicKeywords = []struct{ keyword, topic string }{
+	{"food", "food"},
+	{"eat", "food"},
+	{"hungry", "food"},
+	{"game", "gaming"},
+	{"play", "gaming"},
+	{"love", "romance"},
+	{"heart", "romance"},
+	{"work", "work"},
+	{"study", "study"},
+	{"learn", "study"},
+}
+
 // extractTopics identifies key topics mentioned in the response
 func (llm *LLMBackend) extractTopics(response string) []string {
 	topics := []string{}
 	response = strings.ToLower(response)
 
 	// Simple keyword-based topic extraction
-	topicKeywords := map[string]string{
-		"food":   "food",
-		"eat":    "food",
-		"hungry": "food",
-		"game":   "gaming",
-		"play":   "gaming",
-		"love":   "romance",
-		"heart":  "romance",
-		"work":   "work",
-		"study":  "study",
-		"learn":  "study",
-	}
-
-	for keyword, topic := range topicKeywords {
-		if strings.Contains(response, keyword) {
-			// Avoid duplicates
-			found := false
-			for _, existing := range topics {
-				if existing == topic {
-					found = true
-					break
-				}
-			}
-			if !found {
-				topics = append(topics, topic)
-			}
+	for _, entry := range topicKeywords {
+		if strings.Contains(response, entry.keyword) && !slices.Contains(topics, entry.topic) {
+			topics = append(topics, entry.topic)
 		}
 	}
 
@@ -683,7 +1213,7 @@ func (llm *LLMBackend) createFallbackResponse(ctx DialogContext) DialogResponse
 	case "rightclick":
 		response = "What's up?"
 	default:
-		response = responses[int(time.Now().UnixNano())%len(responses)]
+		response = responses[randomIntn(len(responses))]
 	}
 
 	return DialogResponse{
@@ -709,12 +1239,29 @@ func (llm *LLMBackend) GetBackendInfo() BackendInfo {
 	return llm.info
 }
 
+// HardwareFit returns the memory and CPU fit check of the loaded model, when it made one
+func (llm *LLMBackend) HardwareFit() (HardwareFitReport, bool) {
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+	if reporter, ok := llm.model.(HardwareFitReporter); ok {
+		return reporter.HardwareFit()
+	}
+	return HardwareFitReport{}, false
+}
+
+// GetContextManager returns the conversation history store used by this backend
+func (llm *LLMBackend) GetContextManager() *ContextManager {
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+	return llm.contextManager
+}
+
 // UpdateMemory records interaction outcomes for potential future learning
 // Currently a placeholder for future learning implementations
 func (llm *LLMBackend) UpdateMemory(ctx DialogContext, response DialogResponse, feedback *UserFeedback) error {
 	// Record the interaction for potential future learning
 	if feedback != nil {
-		llm.contextManager.UpdateFeedback(ctx.InteractionID, feedback.Positive, feedback.Engagement)
+		llm.contextFor(ctx).UpdateFeedback(ctx.InteractionID, feedback.Positive, feedback.Engagement)
 	}
 
 	// TODO: Implement actual learning mechanisms (fine-tuning, prompt adaptation, etc.)
@@ -731,6 +1278,12 @@ func (llm *LLMBackend) Close() error {
 		llm.model = nil
 	}
 
+	if llm.contextManager != nil {
+		llm.contextManager.Close()
+	}
+	llm.closeCharacterContexts()
+	llm.closeTenantContexts()
+
 	llm.initialized = false
 	return nil
 }
@@ -2,6 +2,7 @@ package dialog
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -554,6 +555,10 @@ func TestLLMBackend_test_bug4_production_model_claims_mismatch(t *testing.T) {
 	// The system may report using "production" model but it's still mock responses
 	// This is now documented behavior rather than misleading claims
 
+	// Seed the package random source so the mock reply is the same on every run
+	restoreDeterminism(t)
+	SetRandomSeed(2)
+
 	// Generate a response to confirm behavior
 	context := DialogContext{
 		Trigger:           "click",
@@ -588,6 +593,33 @@ func TestLLMBackend_test_bug4_production_model_claims_mismatch(t *testing.T) {
 	t.Logf("System correctly uses mock responses as documented")
 }
 
+func TestLLMBackend_RequestTimeoutOverridesConfig(t *testing.T) {
+	backend := NewLLMBackend()
+	config, _ := json.Marshal(LLMConfig{
+		ModelPath:   "/fake/path.gguf",
+		MockFixture: writeFixture(t, `{"default": "Slow hello", "latencyMs": 150}`),
+		TimeoutMs:   5000,
+	})
+	if err := backend.Initialize(config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer backend.Close()
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "idle", InteractionID: "slow"})
+	if err != nil || response.Text != "Slow hello" {
+		t.Errorf("Expected the configured timeout to allow the slow reply, got %q, %v", response.Text, err)
+	}
+
+	start := time.Now()
+	_, err = backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "fast", TimeoutMs: 20})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected the request timeout to cut generation short, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 120*time.Millisecond {
+		t.Errorf("Expected the 20ms request timeout to apply, took %v", elapsed)
+	}
+}
+
 // Helper function to create a fake GGUF file for testing
 func createFakeGGUFFile(path string) error {
 	file, err := os.Create(path)
@@ -600,3 +632,74 @@ func createFakeGGUFFile(path string) error {
 	_, err = file.WriteString("GGUF\x00\x00\x00\x03") // Fake GGUF magic + version
 	return err
 }
+
+// freeTrackingModel is a scripted model that records whether it was freed
+type freeTrackingModel struct {
+	*scriptedTestModel
+	freed bool
+}
+
+func (m *freeTrackingModel) Free() error {
+	m.freed = true
+	return nil
+}
+
+func TestLLMBackend_SetModel(t *testing.T) {
+	first := &freeTrackingModel{scriptedTestModel: &scriptedTestModel{responses: []string{"Hi from the remote model!"}}}
+	backend := NewLLMBackend(WithProductionModel(first))
+	defer backend.Close()
+
+	if err := backend.InitializeConfig(LLMConfig{}); err != nil {
+		t.Fatalf("Expected a supplied model to make modelPath optional, got %v", err)
+	}
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "injected"})
+	if err != nil || response.Text != "Hi from the remote model!" {
+		t.Errorf("Expected the supplied model's response, got %q (%v)", response.Text, err)
+	}
+
+	second := &scriptedTestModel{responses: []string{"Hello from the new runtime!"}}
+	if err := backend.SetModel(second); err != nil {
+		t.Fatalf("SetModel() failed: %v", err)
+	}
+	if !first.freed {
+		t.Error("Expected the replaced model to be freed")
+	}
+	response, _ = backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "injected"})
+	if response.Text != "Hello from the new runtime!" {
+		t.Errorf("Expected the replacement model's response, got %q", response.Text)
+	}
+
+	if err := backend.SetModel(nil); err == nil {
+		t.Error("Expected a nil model to be rejected")
+	}
+}
+
+// BenchmarkLLMBackend_GenerateResponse measures one request of a long-running chat,
+// excluding model time
+func BenchmarkLLMBackend_GenerateResponse(b *testing.B) {
+	backend := newScriptedBackend(b, LLMConfig{
+		MarkovConfig: MarkovChainConfig{TrainingData: []string{
+			"Yay, snack time is the best time!",
+			"You always know how to cheer me up!",
+			"Hehe, that tickles!",
+			"I was hoping you'd come say hi.",
+		}},
+	}, &scriptedTestModel{responses: []string{"Mmm, thank you! I love playing games with you!"}})
+	ctx := DialogContext{
+		Trigger:           "talk",
+		UserMessage:       "Want to play a game?",
+		InteractionID:     "bench",
+		CurrentMood:       70,
+		TimeOfDay:         "evening",
+		RelationshipLevel: "friend",
+		PersonalityTraits: map[string]float64{"cheerful": 0.9, "playful": 0.8},
+		FallbackResponses: []string{"Hi"},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := backend.GenerateResponse(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultTriggerMix approximates how desktop pet users interact: mostly clicks and hovers
+var defaultTriggerMix = map[string]int{
+	"click":      40,
+	"hover":      20,
+	"feed":       15,
+	"pet":        15,
+	"rightclick": 10,
+}
+
+// LoadTestConfig configures a simulated multi-session load test
+type LoadTestConfig struct {
+	Sessions    int            `json:"sessions"`              // Distinct InteractionIDs (default: 100)
+	Workers     int            `json:"workers"`               // Concurrent callers (default: Sessions)
+	DurationMs  int            `json:"durationMs"`            // How long to generate load (default: 10000)
+	MaxRequests int            `json:"maxRequests,omitempty"` // Stop early after this many requests (0 = no limit)
+	ThinkTimeMs int            `json:"thinkTimeMs,omitempty"` // Pause between a worker's requests
+	TriggerMix  map[string]int `json:"triggerMix,omitempty"`  // Relative trigger weights (default: click-heavy mix)
+	Seed        int64          `json:"seed,omitempty"`        // Random seed for reproducible runs (0 = time-based)
+}
+
+// LoadTestReport summarizes a load test run
+type LoadTestReport struct {
+	Requests       int            `json:"requests"`
+	Errors         int            `json:"errors"`
+	Fallbacks      int            `json:"fallbacks"` // Responses served by any fallback path
+	Duration       time.Duration  `json:"duration"`
+	Throughput     float64        `json:"throughput"` // Requests per second
+	P50            time.Duration  `json:"p50"`
+	P95            time.Duration  `json:"p95"`
+	P99            time.Duration  `json:"p99"`
+	Triggers       map[string]int `json:"triggers"`  // Requests sent per trigger
+	Evictions      map[string]int `json:"evictions"` // Memory evictions by reason
+	PeakQueueDepth int            `json:"peakQueueDepth"`
+	HeapStart      uint64         `json:"heapStart"` // Bytes in use before the run
+	HeapEnd        uint64         `json:"heapEnd"`   // Bytes in use after the run (after GC)
+	HeapPeak       uint64         `json:"heapPeak"`  // Highest sampled heap during the run
+}
+
+// HeapGrowth returns the retained heap growth over the run in bytes
+func (r LoadTestReport) HeapGrowth() int64 {
+	return int64(r.HeapEnd) - int64(r.HeapStart)
+}
+
+// withDefaults fills unset load test values
+func (c LoadTestConfig) withDefaults() LoadTestConfig {
+	if c.Sessions <= 0 {
+		c.Sessions = 100
+	}
+	if c.Workers <= 0 {
+		c.Workers = c.Sessions
+	}
+	if c.DurationMs <= 0 {
+		c.DurationMs = 10000
+	}
+	if len(c.TriggerMix) == 0 {
+		c.TriggerMix = defaultTriggerMix
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	return c
+}
+
+// loadTestResult is one request's outcome
+type loadTestResult struct {
+	latency  time.Duration
+	trigger  string
+	err      error
+	fallback bool
+}
+
+// RunLoadTest drives the manager with many concurrent simulated sessions, exercising
+// conversation memory eviction and backend queueing, until the duration elapses,
+// MaxRequests is reached or ctx is cancelled
+func RunLoadTest(ctx context.Context, dm *DialogManager, config LoadTestConfig) (LoadTestReport, error) {
+	config = config.withDefaults()
+	for trigger, weight := range config.TriggerMix {
+		if weight < 0 {
+			return LoadTestReport{}, fmt.Errorf("trigger weight for %q must be non-negative", trigger)
+		}
+	}
+	picker := newTriggerPicker(config.TriggerMix)
+	if picker.total == 0 {
+		return LoadTestReport{}, fmt.Errorf("trigger mix must have at least one positive weight")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.DurationMs)*time.Millisecond)
+	defer cancel()
+
+	report := LoadTestReport{
+		Triggers:  make(map[string]int),
+		Evictions: make(map[string]int),
+	}
+	var evictionMu sync.Mutex
+	unsubscribe := dm.Events().Subscribe(func(event DialogEvent) {
+		evictionMu.Lock()
+		report.Evictions[event.Reason] += event.Count
+		evictionMu.Unlock()
+	}, EventMemoryEvicted)
+	defer unsubscribe()
+
+	runtime.GC()
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	report.HeapStart = memStats.HeapAlloc
+
+	sampler := startLoadSampler(dm)
+
+	var (
+		results []loadTestResult
+		mu      sync.Mutex
+		sent    int
+		wg      sync.WaitGroup
+	)
+	start := time.Now()
+	for w := 0; w < config.Workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(config.Seed + int64(worker)))
+			for turn := 1; ctx.Err() == nil; turn++ {
+				mu.Lock()
+				if config.MaxRequests > 0 && sent >= config.MaxRequests {
+					mu.Unlock()
+					return
+				}
+				sent++
+				mu.Unlock()
+
+				trigger := picker.pick(rng)
+				dialogCtx := loadTestContext(trigger, rng.Intn(config.Sessions), turn, rng)
+				began := time.Now()
+				response, err := dm.GenerateDialog(dialogCtx)
+				result := loadTestResult{
+					latency:  time.Since(began),
+					trigger:  trigger,
+					err:      err,
+					fallback: response.ResponseType == "fallback",
+				}
+
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+
+				if config.ThinkTimeMs > 0 {
+					select {
+					case <-ctx.Done():
+					case <-time.After(time.Duration(config.ThinkTimeMs) * time.Millisecond):
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+	unsubscribe()
+
+	report.HeapPeak, report.PeakQueueDepth = sampler.stop()
+	runtime.GC()
+	runtime.ReadMemStats(&memStats)
+	report.HeapEnd = memStats.HeapAlloc
+
+	evictionMu.Lock()
+	defer evictionMu.Unlock()
+	summarizeLoadResults(&report, results)
+	return report, nil
+}
+
+// summarizeLoadResults fills request counts, rates and latency percentiles
+func summarizeLoadResults(report *LoadTestReport, results []loadTestResult) {
+	latencies := make([]time.Duration, len(results))
+	for i, result := range results {
+		latencies[i] = result.latency
+		report.Triggers[result.trigger]++
+		if result.err != nil {
+			report.Errors++
+		}
+		if result.fallback {
+			report.Fallbacks++
+		}
+	}
+
+	report.Requests = len(results)
+	if report.Duration > 0 {
+		report.Throughput = float64(report.Requests) / report.Duration.Seconds()
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = latencyPercentile(latencies, 50)
+	report.P95 = latencyPercentile(latencies, 95)
+	report.P99 = latencyPercentile(latencies, 99)
+}
+
+// latencyPercentile returns the nearest-rank percentile of sorted latencies
+func latencyPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
+
+// loadTestContext builds a context for one simulated session
+func loadTestContext(trigger string, session, turn int, rng *rand.Rand) DialogContext {
+	return DialogContext{
+		Trigger:       trigger,
+		InteractionID: fmt.Sprintf("loadtest-%d", session),
+		Timestamp:     time.Now(),
+		CurrentStats: map[string]float64{
+			"happiness": float64(rng.Intn(100)),
+			"energy":    float64(rng.Intn(100)),
+		},
+		CurrentMood:       float64(rng.Intn(100)),
+		CurrentAnimation:  "idle",
+		ConversationTurn:  turn,
+		FallbackResponses: []string{"Hello!"},
+		FallbackAnimation: "talking",
+	}
+}
+
+// triggerPicker selects triggers according to relative weights
+type triggerPicker struct {
+	triggers []string
+	weights  []int
+	total    int
+}
+
+// newTriggerPicker sorts triggers so a seed always produces the same sequence
+func newTriggerPicker(mix map[string]int) triggerPicker {
+	var picker triggerPicker
+	for trigger := range mix {
+		picker.triggers = append(picker.triggers, trigger)
+	}
+	sort.Strings(picker.triggers)
+	for _, trigger := range picker.triggers {
+		picker.weights = append(picker.weights, mix[trigger])
+		picker.total += mix[trigger]
+	}
+	return picker
+}
+
+// pick returns a weighted random trigger
+func (p triggerPicker) pick(rng *rand.Rand) string {
+	n := rng.Intn(p.total)
+	for i, weight := range p.weights {
+		if n < weight {
+			return p.triggers[i]
+		}
+		n -= weight
+	}
+	return p.triggers[len(p.triggers)-1]
+}
+
+// loadSampler periodically records peak heap usage and backend queue depth
+type loadSampler struct {
+	done       chan struct{}
+	finished   chan struct{}
+	heapPeak   uint64
+	queuePeak  int
+	dm         *DialogManager
+	sampleRate time.Duration
+}
+
+// startLoadSampler begins sampling in the background until stop is called
+func startLoadSampler(dm *DialogManager) *loadSampler {
+	s := &loadSampler{
+		done:       make(chan struct{}),
+		finished:   make(chan struct{}),
+		dm:         dm,
+		sampleRate: 50 * time.Millisecond,
+	}
+	go s.run()
+	return s
+}
+
+// run samples until stopped
+func (s *loadSampler) run() {
+	defer close(s.finished)
+	ticker := time.NewTicker(s.sampleRate)
+	defer ticker.Stop()
+
+	for {
+		s.sample()
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample records the current heap size and total in-flight generations
+func (s *loadSampler) sample() {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	s.heapPeak = max(s.heapPeak, memStats.HeapAlloc)
+
+	depth := 0
+	for _, name := range s.dm.GetRegisteredBackends() {
+		if backend, exists := s.dm.GetBackend(name); exists {
+			if reporter, ok := backend.(HealthReporter); ok {
+				depth += reporter.GetHealth().QueueDepth
+			}
+		}
+	}
+	s.queuePeak = max(s.queuePeak, depth)
+}
+
+// stop ends sampling and returns the peaks
+func (s *loadSampler) stop() (uint64, int) {
+	close(s.done)
+	<-s.finished
+	return s.heapPeak, s.queuePeak
+}
@@ -0,0 +1,85 @@
+package dialog
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestRunLoadTest_ReportsRequestsAndEvictions(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hi there!"}}
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", newScriptedBackend(t, LLMConfig{MaxHistoryLength: 1}, model))
+	dm.SetDefaultBackend("llm")
+
+	report, err := RunLoadTest(context.Background(), dm, LoadTestConfig{
+		Sessions:    5,
+		Workers:     3,
+		DurationMs:  5000,
+		MaxRequests: 40,
+		TriggerMix:  map[string]int{"click": 3, "feed": 1},
+		Seed:        42,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Requests != 40 {
+		t.Errorf("Expected 40 requests, got %d", report.Requests)
+	}
+	if report.Triggers["click"]+report.Triggers["feed"] != 40 {
+		t.Errorf("Expected only click and feed triggers, got %v", report.Triggers)
+	}
+	if report.Evictions[EvictionReasonHistoryLimit] == 0 {
+		t.Errorf("Expected history-limit evictions with MaxHistoryLength 1, got %v", report.Evictions)
+	}
+	if report.P50 > report.P95 || report.P95 > report.P99 {
+		t.Errorf("Expected ordered percentiles, got p50=%v p95=%v p99=%v", report.P50, report.P95, report.P99)
+	}
+	if report.Throughput <= 0 || report.HeapPeak == 0 {
+		t.Errorf("Expected throughput and heap samples, got %+v", report)
+	}
+}
+
+func TestRunLoadTest_StopsOnContextCancel(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hi!"}}))
+	dm.SetDefaultBackend("llm")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := RunLoadTest(ctx, dm, LoadTestConfig{Sessions: 2, DurationMs: 60000})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Requests != 0 {
+		t.Errorf("Expected no requests after cancel, got %d", report.Requests)
+	}
+}
+
+func TestRunLoadTest_RejectsEmptyTriggerMix(t *testing.T) {
+	dm := NewDialogManager(false)
+	if _, err := RunLoadTest(context.Background(), dm, LoadTestConfig{TriggerMix: map[string]int{"click": 0}}); err == nil {
+		t.Error("Expected error for a trigger mix without positive weights")
+	}
+}
+
+func TestTriggerPicker_FollowsWeights(t *testing.T) {
+	picker := newTriggerPicker(map[string]int{"click": 9, "feed": 1})
+	rng := rand.New(rand.NewSource(1))
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[picker.pick(rng)]++
+	}
+	if counts["click"] < 850 || counts["feed"] < 50 {
+		t.Errorf("Expected roughly a 9:1 split, got %v", counts)
+	}
+}
+
+func TestLatencyPercentile(t *testing.T) {
+	if latencyPercentile(nil, 95) != 0 {
+		t.Error("Expected zero percentile for no samples")
+	}
+}
@@ -0,0 +1,99 @@
+package dialog
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetMaxBytes caps the estimated memory of stored conversations (0 = unlimited)
+// Over budget, whole conversations are evicted closest-to-expiry first, sparing the one
+// just written; if that conversation alone is over budget its least important exchanges
+// are dropped, always keeping the newest one
+func (cm *ContextManager) SetMaxBytes(maxBytes int64) error {
+	if maxBytes < 0 {
+		return fmt.Errorf("max bytes must be non-negative, got %d", maxBytes)
+	}
+
+	cm.lockAll()
+	defer cm.flushEvents()
+	defer cm.unlockAll()
+	cm.maxBytes = maxBytes
+	cm.enforceByteBudget("")
+	return nil
+}
+
+// exchangeBytes estimates the heap held by one stored exchange
+func exchangeBytes(exchange ConversationExchange) int64 {
+	return exchangeSize + int64(len(exchange.Trigger)+len(exchange.UserMessage)+len(exchange.Response)+
+		len(exchange.Speaker)+len(exchange.ResponseType))
+}
+
+// conversationBytes estimates the heap held by one stored conversation
+func conversationBytes(interactionID string, history *ConversationHistory) int64 {
+	size := conversationSize + int64(len(interactionID))
+	for _, exchange := range history.Exchanges {
+		size += exchangeBytes(exchange)
+	}
+	return size
+}
+
+// enforceByteBudget evicts until stored conversations fit maxBytes, sparing current
+// This method assumes the caller already holds every shard's write lock
+func (cm *ContextManager) enforceByteBudget(current string) {
+	if cm.maxBytes <= 0 {
+		return
+	}
+
+	var total int64
+	count := 0
+	for id, history := range cm.allConversations() {
+		total += conversationBytes(id, history)
+		count++
+	}
+
+	for ; total > cm.maxBytes && count > 1; count-- {
+		victim := cm.conversationClosestToExpiry(current)
+		history, _ := cm.conversation(victim)
+		total -= conversationBytes(victim, history)
+		cm.noteEviction(victim, EvictionReasonMemoryBudget, len(history.Exchanges), true)
+		cm.deleteConversation(victim)
+	}
+
+	// A single conversation over budget gives up its least important exchanges instead
+	for id, history := range cm.allConversations() {
+		dropped := 0
+		now := cm.now()
+		for total > cm.maxBytes && len(history.Exchanges) > 1 {
+			victim := cm.leastImportantExchangeIndex(history.Exchanges, now)
+			total -= exchangeBytes(history.Exchanges[victim])
+			history.Exchanges = append(history.Exchanges[:victim], history.Exchanges[victim+1:]...)
+			dropped++
+		}
+		if dropped > 0 {
+			cm.noteEviction(id, EvictionReasonMemoryBudget, dropped, false)
+		}
+	}
+}
+
+// conversationClosestToExpiry returns the conversation that would expire first, other than exclude
+// Without important exchanges this is the least recently updated conversation
+// This method assumes the caller already holds the lock
+func (cm *ContextManager) conversationClosestToExpiry(exclude string) string {
+	var oldestID string
+	var oldestTime time.Time
+	first := true
+	now := cm.now()
+
+	for id, history := range cm.allConversations() {
+		if id == exclude {
+			continue
+		}
+		expiry := cm.conversationExpiry(history, now)
+		if first || expiry.Before(oldestTime) {
+			oldestID = id
+			oldestTime = expiry
+			first = false
+		}
+	}
+	return oldestID
+}
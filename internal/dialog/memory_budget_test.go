@@ -0,0 +1,80 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextManager_ByteBudgetEvictsConversations(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	cm := NewContextManager(10)
+	defer cm.Close()
+	bus := NewEventBus()
+	cm.SetEventBus(bus)
+	evictions, _ := eventRecorder(bus, EventMemoryEvicted)
+
+	for _, id := range []string{"a", "b", "c"} {
+		cm.AddExchange(id, "click", strings.Repeat("x", 200))
+		clock.Advance(time.Minute)
+	}
+	budget := cm.MemoryStats().Bytes - 1
+	if err := cm.SetMaxBytes(budget); err != nil {
+		t.Fatalf("SetMaxBytes failed: %v", err)
+	}
+
+	if stats := cm.MemoryStats(); stats.Conversations != 2 || stats.Bytes > budget {
+		t.Errorf("Expected one conversation evicted to fit %d bytes, got %+v", budget, stats)
+	}
+	if len(cm.GetHistory("a", 0)) != 0 {
+		t.Error("Expected the least recently updated conversation evicted")
+	}
+	if len(*evictions) != 1 || (*evictions)[0].Reason != EvictionReasonMemoryBudget || (*evictions)[0].InteractionID != "a" {
+		t.Errorf("Expected a memory budget eviction of 'a', got %+v", *evictions)
+	}
+}
+
+func TestContextManager_ByteBudgetTrimsChattyConversation(t *testing.T) {
+	cm := NewContextManager(50)
+	defer cm.Close()
+	cm.AddExchange("quiet", "click", "Hi")
+	if err := cm.SetMaxBytes(4096); err != nil {
+		t.Fatalf("SetMaxBytes failed: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		cm.AddExchange("chatty", "chat", strings.Repeat("blah ", 100))
+	}
+	stats := cm.MemoryStats()
+	if stats.Bytes > 4096 || stats.Conversations != 1 {
+		t.Errorf("Expected the chatty conversation alone within budget, got %+v", stats)
+	}
+	if history := cm.GetHistory("chatty", 0); len(history) == 0 || len(history) >= 20 {
+		t.Errorf("Expected the chatty conversation trimmed but kept, got %d exchanges", len(history))
+	}
+
+	// The newest exchange is kept even when it alone is over budget
+	cm.AddExchange("chatty", "chat", strings.Repeat("z", 8192))
+	if history := cm.GetHistory("chatty", 0); len(history) != 1 || !strings.HasPrefix(history[0].Response, "z") {
+		t.Errorf("Expected only the newest exchange kept, got %d exchanges", len(history))
+	}
+	if err := cm.SetMaxBytes(-1); err == nil {
+		t.Error("Expected error for a negative budget")
+	}
+}
+
+func TestLLMBackend_MaxHistoryBytes(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{MaxHistoryBytes: 2048}, &scriptedTestModel{responses: []string{strings.Repeat("Purr ", 60)}})
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		backend.GenerateResponse(DialogContext{Trigger: "pet", InteractionID: id, CharacterID: "cat"})
+	}
+	if stats := backend.GetCharacterContextManager("cat").MemoryStats(); stats.Bytes > 2048 || stats.Conversations == 0 {
+		t.Errorf("Expected the character's history within budget, got %+v", stats)
+	}
+	if err := backend.applyConfig(LLMConfig{ModelPath: "mock://model", MaxHistoryBytes: -1}); err == nil {
+		t.Error("Expected error for negative maxHistoryBytes")
+	}
+}
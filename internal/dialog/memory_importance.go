@@ -0,0 +1,125 @@
+package dialog
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+)
+
+// defaultImportanceHalfLife is how long it takes an exchange's importance to halve
+const defaultImportanceHalfLife = 24 * time.Hour
+
+// SetImportanceHalfLife configures how quickly exchange importance decays over time
+// A non-positive value disables decay so importance stays constant
+func (cm *ContextManager) SetImportanceHalfLife(halfLife time.Duration) {
+	cm.lockAll()
+	defer cm.unlockAll()
+	cm.importanceHalfLife = halfLife
+}
+
+// effectiveImportance returns the exchange importance after time-based decay
+func (cm *ContextManager) effectiveImportance(exchange ConversationExchange, now time.Time) float64 {
+	if exchange.Importance <= 0 {
+		return 0
+	}
+	if cm.importanceHalfLife <= 0 {
+		return exchange.Importance
+	}
+
+	age := now.Sub(exchange.Timestamp)
+	if age <= 0 {
+		return exchange.Importance
+	}
+	return exchange.Importance * math.Pow(0.5, float64(age)/float64(cm.importanceHalfLife))
+}
+
+// leastImportantExchangeIndex picks the exchange to drop when a conversation overflows
+// The newest exchange is never dropped; ties go to the oldest exchange
+// This method assumes the caller already holds the lock
+func (cm *ContextManager) leastImportantExchangeIndex(exchanges []ConversationExchange, now time.Time) int {
+	victim := 0
+	lowest := math.Inf(1)
+
+	for i := 0; i < len(exchanges)-1; i++ {
+		importance := cm.effectiveImportance(exchanges[i], now)
+		if importance < lowest {
+			lowest = importance
+			victim = i
+		}
+	}
+	return victim
+}
+
+// peakImportance returns the highest decayed importance within a conversation
+// This method assumes the caller already holds the lock
+func (cm *ContextManager) peakImportance(history *ConversationHistory, now time.Time) float64 {
+	peak := 0.0
+	for _, exchange := range history.Exchanges {
+		if importance := cm.effectiveImportance(exchange, now); importance > peak {
+			peak = importance
+		}
+	}
+	return math.Min(peak, 1)
+}
+
+// conversationExpiry returns when a conversation should be cleaned up
+// Important conversations are retained for up to twice the retention period
+// This method assumes the caller already holds the lock
+func (cm *ContextManager) conversationExpiry(history *ConversationHistory, now time.Time) time.Time {
+	extension := time.Duration(float64(cm.retentionPeriod) * cm.peakImportance(history, now))
+	return history.LastUpdated.Add(cm.retentionPeriod + extension)
+}
+
+// boostImportance folds user feedback into an exchange's stored importance
+// Engaged, positive reactions make an exchange more worth remembering
+func boostImportance(current float64, positive bool, engagement float64) float64 {
+	importance := current*0.5 + engagement*0.5
+	if positive {
+		importance += 0.1
+	}
+	return math.Max(0, math.Min(1, importance))
+}
+
+// GetImportantHistory retrieves up to maxExchanges exchanges prioritized by decayed importance
+// The most recent exchange is always included and results are returned in chronological order
+// Ties are resolved in favour of more recent exchanges
+func (cm *ContextManager) GetImportantHistory(interactionID string, maxExchanges int) []ConversationExchange {
+	shard := cm.rlockShard(interactionID)
+	defer shard.mu.RUnlock()
+
+	history, exists := shard.conversations[interactionID]
+	if !exists || len(history.Exchanges) == 0 {
+		return []ConversationExchange{}
+	}
+
+	exchanges := history.Exchanges
+	if maxExchanges <= 0 || len(exchanges) <= maxExchanges {
+		result := make([]ConversationExchange, len(exchanges))
+		copy(result, exchanges)
+		return result
+	}
+
+	now := cm.now()
+	newest := len(exchanges) - 1
+
+	// Score each exchange once rather than on every comparison
+	scores := make([]float64, newest)
+	candidates := make([]int, 0, newest)
+	for i := newest - 1; i >= 0; i-- {
+		candidates = append(candidates, i)
+		scores[i] = cm.effectiveImportance(exchanges[i], now)
+	}
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+
+	selected := append(candidates[:maxExchanges-1], newest)
+	slices.Sort(selected)
+
+	result := make([]ConversationExchange, len(selected))
+	for i, index := range selected {
+		result[i] = exchanges[index]
+	}
+	return result
+}
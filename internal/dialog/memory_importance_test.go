@@ -0,0 +1,162 @@
+package dialog
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestContextManager_EffectiveImportanceDecay(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+	cm.SetImportanceHalfLife(time.Hour)
+
+	now := time.Now()
+	exchange := ConversationExchange{Timestamp: now.Add(-time.Hour), Importance: 0.8}
+
+	if got := cm.effectiveImportance(exchange, now); math.Abs(got-0.4) > 1e-9 {
+		t.Errorf("Expected importance to halve after one half-life, got %f", got)
+	}
+
+	cm.SetImportanceHalfLife(0)
+	if got := cm.effectiveImportance(exchange, now); got != 0.8 {
+		t.Errorf("Expected no decay when half-life disabled, got %f", got)
+	}
+}
+
+func TestContextManager_OverflowDropsLeastImportant(t *testing.T) {
+	cm := NewContextManager(3)
+	defer cm.Close()
+
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "gift", Response: "important", Importance: 0.9})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "trivial", Importance: 0.1})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "middle", Importance: 0.5})
+	cm.RecordExchange("user-1", ConversationExchange{Trigger: "click", Response: "newest", Importance: 0.0})
+
+	history := cm.GetHistory("user-1", 0)
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 exchanges, got %d", len(history))
+	}
+
+	for _, exchange := range history {
+		if exchange.Response == "trivial" {
+			t.Error("Expected the least important exchange to be evicted")
+		}
+	}
+	if history[0].Response != "important" || history[2].Response != "newest" {
+		t.Errorf("Expected important exchange kept and newest appended, got %+v", history)
+	}
+}
+
+func TestContextManager_OverflowWithoutImportanceDropsOldest(t *testing.T) {
+	cm := NewContextManager(2)
+	defer cm.Close()
+
+	cm.AddExchange("user-1", "click", "first")
+	cm.AddExchange("user-1", "click", "second")
+	cm.AddExchange("user-1", "click", "third")
+
+	history := cm.GetHistory("user-1", 0)
+	if history[0].Response != "second" || history[1].Response != "third" {
+		t.Errorf("Expected rolling window of most recent exchanges, got %+v", history)
+	}
+}
+
+func TestContextManager_GetImportantHistory(t *testing.T) {
+	cm := NewContextManager(10)
+	defer cm.Close()
+
+	cm.RecordExchange("user-1", ConversationExchange{Response: "birthday", Importance: 0.9})
+	cm.RecordExchange("user-1", ConversationExchange{Response: "hello", Importance: 0.1})
+	cm.RecordExchange("user-1", ConversationExchange{Response: "favorite food", Importance: 0.8})
+	cm.RecordExchange("user-1", ConversationExchange{Response: "weather", Importance: 0.2})
+	cm.RecordExchange("user-1", ConversationExchange{Response: "latest", Importance: 0.0})
+
+	history := cm.GetImportantHistory("user-1", 3)
+	expected := []string{"birthday", "favorite food", "latest"}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d exchanges, got %d", len(expected), len(history))
+	}
+	for i, response := range expected {
+		if history[i].Response != response {
+			t.Errorf("Position %d: expected %q, got %q", i, response, history[i].Response)
+		}
+	}
+
+	if got := cm.GetImportantHistory("missing", 3); len(got) != 0 {
+		t.Errorf("Expected empty history for unknown interaction, got %d", len(got))
+	}
+}
+
+func TestContextManager_GetImportantHistoryPrefersRecentOnTies(t *testing.T) {
+	cm := NewContextManager(10)
+	defer cm.Close()
+
+	for _, response := range []string{"a", "b", "c", "d"} {
+		cm.AddExchange("user-1", "click", response)
+	}
+
+	history := cm.GetImportantHistory("user-1", 2)
+	if len(history) != 2 || history[0].Response != "c" || history[1].Response != "d" {
+		t.Errorf("Expected most recent exchanges on equal importance, got %+v", history)
+	}
+}
+
+func TestContextManager_FeedbackBoostsImportance(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+
+	cm.RecordExchange("user-1", ConversationExchange{Response: "Hi", Importance: 0.4})
+	cm.UpdateFeedback("user-1", true, 1.0)
+
+	history := cm.GetHistory("user-1", 0)
+	if history[0].Importance <= 0.4 {
+		t.Errorf("Expected positive engaged feedback to raise importance, got %f", history[0].Importance)
+	}
+}
+
+func TestContextManager_ImportantConversationsSurviveCleanup(t *testing.T) {
+	cm := NewContextManagerWithConfig(5, 0, time.Hour, time.Hour)
+	defer cm.Close()
+	cm.SetImportanceHalfLife(0)
+
+	stale := time.Now().Add(-90 * time.Minute)
+	cm.lockAll()
+	cm.storeConversation("important", &ConversationHistory{
+		InteractionID: "important",
+		Exchanges:     []ConversationExchange{{Timestamp: stale, Importance: 1.0}},
+		LastUpdated:   stale,
+	})
+	cm.storeConversation("trivial", &ConversationHistory{
+		InteractionID: "trivial",
+		Exchanges:     []ConversationExchange{{Timestamp: stale}},
+		LastUpdated:   stale,
+	})
+	cm.unlockAll()
+
+	cm.cleanupOldConversations()
+
+	if len(cm.GetHistory("important", 0)) == 0 {
+		t.Error("Expected important conversation to survive cleanup")
+	}
+	if len(cm.GetHistory("trivial", 0)) != 0 {
+		t.Error("Expected trivial conversation to be cleaned up")
+	}
+}
+
+func TestContextManager_EvictionPrefersUnimportantConversations(t *testing.T) {
+	cm := NewContextManagerWithConfig(5, 2, time.Hour, time.Hour)
+	defer cm.Close()
+
+	cm.RecordExchange("important", ConversationExchange{Response: "remember me", Importance: 1.0})
+	time.Sleep(time.Millisecond)
+	cm.AddExchange("trivial", "click", "hi")
+	cm.AddExchange("newcomer", "click", "hello")
+
+	if len(cm.GetHistory("important", 0)) == 0 {
+		t.Error("Expected older but important conversation to survive eviction")
+	}
+	if len(cm.GetHistory("trivial", 0)) != 0 {
+		t.Error("Expected unimportant conversation to be evicted")
+	}
+}
@@ -0,0 +1,159 @@
+package dialog
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// ErrResponseVetoed is a convenience error for hooks that reject a response
+var ErrResponseVetoed = errors.New("dialog response vetoed by hook")
+
+// DialogHandler produces a dialog response for a context
+// The innermost handler is the DialogManager's backend selection and fallback chain
+type DialogHandler func(context DialogContext) (DialogResponse, error)
+
+// Middleware wraps a DialogHandler to add cross-cutting behavior such as logging,
+// context rewriting, vetoing responses or latency metrics
+// A middleware may short-circuit by returning without calling next
+type Middleware func(next DialogHandler) DialogHandler
+
+// PreHookFunc runs before generation and may mutate the context in place
+// Returning a non-nil response short-circuits generation with that response;
+// returning an error aborts generation and GenerateDialog returns the fallback response
+type PreHookFunc func(context *DialogContext) (*DialogResponse, error)
+
+// PostHookFunc runs after generation and may mutate the response in place
+// Returning an error vetoes the response and GenerateDialog returns the fallback response
+type PostHookFunc func(context DialogContext, response *DialogResponse) error
+
+// Use appends middleware to the manager's chain
+// Middleware runs in registration order: the first registered is the outermost
+func (dm *DialogManager) Use(middleware ...Middleware) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	for _, mw := range middleware {
+		if mw != nil {
+			dm.middleware = append(dm.middleware, mw)
+		}
+	}
+}
+
+// buildHandlerChain wraps the backend handler with all registered middleware
+// Rate limiting, when enabled, runs outside the middleware so repeats are handled cheaply;
+// the mood engine runs outermost so every interaction, even a debounced one, moves mood.
+// Tenant checks wrap everything, so an unregistered tenant is rejected before any state changes
+func (dm *DialogManager) buildHandlerChain() DialogHandler {
+	return dm.buildHandlerChainWith(dm.generateWithBackends)
+}
+
+// buildHandlerChainWith wraps inner, in place of backend selection, with all registered middleware
+func (dm *DialogManager) buildHandlerChainWith(inner DialogHandler) DialogHandler {
+	dm.mu.RLock()
+	middleware := make([]Middleware, len(dm.middleware))
+	copy(middleware, dm.middleware)
+	limiter := dm.rateLimiter
+	moodEngine := dm.moodEngine
+	drift := dm.drift
+	userMemory := dm.userMemory
+	world := dm.world
+	speech := dm.speech
+	tenants := dm.tenants
+	dm.mu.RUnlock()
+
+	handler := inner
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	if limiter != nil {
+		handler = limiter.wrap(handler, dm.createFallbackResponse)
+	}
+	if world != nil {
+		handler = world.wrap(handler)
+	}
+	if userMemory != nil {
+		handler = userMemory.wrap(handler)
+	}
+	if drift != nil {
+		handler = drift.wrap(handler)
+	}
+	if moodEngine != nil {
+		handler = moodEngine.wrap(handler)
+	}
+	if speech != nil {
+		handler = speech.wrap(handler)
+	}
+	return dm.tenantGate(tenants, handler)
+}
+
+// PreHook adapts a PreHookFunc into Middleware
+func PreHook(hook PreHookFunc) Middleware {
+	return func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			shortCircuit, err := hook(&context)
+			if err != nil {
+				return DialogResponse{}, err
+			}
+			if shortCircuit != nil {
+				return *shortCircuit, nil
+			}
+			return next(context)
+		}
+	}
+}
+
+// PostHook adapts a PostHookFunc into Middleware
+func PostHook(hook PostHookFunc) Middleware {
+	return func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			response, err := next(context)
+			if err != nil {
+				return response, err
+			}
+			if err := hook(context, &response); err != nil {
+				return DialogResponse{}, err
+			}
+			return response, nil
+		}
+	}
+}
+
+// LoggingMiddleware logs each trigger with the resulting response type and latency
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			start := time.Now()
+			response, err := next(context)
+			latency := time.Since(start)
+
+			if err != nil {
+				logger.Printf("dialog trigger=%s interaction=%s latency=%v error=%v",
+					context.Trigger, context.InteractionID, latency, err)
+			} else {
+				logger.Printf("dialog trigger=%s interaction=%s latency=%v type=%s confidence=%.2f",
+					context.Trigger, context.InteractionID, latency, response.ResponseType, response.Confidence)
+			}
+			return response, err
+		}
+	}
+}
+
+// LatencyMiddleware reports the duration of every generation to the callback
+func LatencyMiddleware(record func(context DialogContext, latency time.Duration)) Middleware {
+	return func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			start := time.Now()
+			response, err := next(context)
+			if record != nil {
+				record(context, time.Since(start))
+			}
+			return response, err
+		}
+	}
+}
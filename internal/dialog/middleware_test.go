@@ -0,0 +1,138 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newMiddlewareTestManager creates a manager with an initialized LLM backend
+func newMiddlewareTestManager(t *testing.T) *DialogManager {
+	t.Helper()
+
+	backend := NewLLMBackend()
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: "/fake/path.gguf"})
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	t.Cleanup(func() { backend.Close() })
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+	return dm
+}
+
+func TestDialogManager_UseOrdering(t *testing.T) {
+	dm := NewDialogManager(false)
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next DialogHandler) DialogHandler {
+			return func(context DialogContext) (DialogResponse, error) {
+				order = append(order, name+":before")
+				response, err := next(context)
+				order = append(order, name+":after")
+				return response, err
+			}
+		}
+	}
+
+	dm.Use(trace("outer"), trace("inner"))
+	dm.GenerateDialog(DialogContext{Trigger: "click"})
+
+	expected := "outer:before,inner:before,inner:after,outer:after"
+	if got := strings.Join(order, ","); got != expected {
+		t.Errorf("Expected order %s, got %s", expected, got)
+	}
+}
+
+func TestPreHook_MutatesContext(t *testing.T) {
+	dm := newMiddlewareTestManager(t)
+
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		context.Trigger = "feed"
+		return nil, nil
+	}))
+
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "user-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(response.Text, "meal") {
+		t.Errorf("Expected rewritten feed trigger to drive response, got %q", response.Text)
+	}
+}
+
+func TestPreHook_ShortCircuits(t *testing.T) {
+	dm := newMiddlewareTestManager(t)
+
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		return &DialogResponse{Text: "cached", Confidence: 1.0}, nil
+	}))
+
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "click"})
+	if err != nil || response.Text != "cached" {
+		t.Errorf("Expected short-circuit response, got %q (err %v)", response.Text, err)
+	}
+	if dm.GetAnalytics().BackendUsage["llm"] != 0 {
+		t.Error("Backend should not run when a pre hook short-circuits")
+	}
+}
+
+func TestPostHook_VetoReturnsFallback(t *testing.T) {
+	dm := newMiddlewareTestManager(t)
+
+	dm.Use(PostHook(func(context DialogContext, response *DialogResponse) error {
+		return ErrResponseVetoed
+	}))
+
+	response, err := dm.GenerateDialog(DialogContext{
+		Trigger:           "click",
+		FallbackResponses: []string{"Safe fallback"},
+	})
+	if !errors.Is(err, ErrResponseVetoed) {
+		t.Errorf("Expected veto error, got %v", err)
+	}
+	if response.Text != "Safe fallback" || response.ResponseType != "fallback" {
+		t.Errorf("Expected fallback response after veto, got %+v", response)
+	}
+}
+
+func TestPostHook_RewritesResponse(t *testing.T) {
+	dm := newMiddlewareTestManager(t)
+
+	dm.Use(PostHook(func(context DialogContext, response *DialogResponse) error {
+		response.Text = strings.ToUpper(response.Text)
+		return nil
+	}))
+
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "feed"})
+	if response.Text != strings.ToUpper(response.Text) {
+		t.Errorf("Expected post hook to rewrite response, got %q", response.Text)
+	}
+}
+
+func TestLoggingAndLatencyMiddleware(t *testing.T) {
+	dm := NewDialogManager(false)
+
+	var buf bytes.Buffer
+	var recorded time.Duration
+	dm.Use(
+		LoggingMiddleware(log.New(&buf, "", 0)),
+		LatencyMiddleware(func(context DialogContext, latency time.Duration) { recorded = latency }),
+	)
+
+	dm.GenerateDialog(DialogContext{Trigger: "hover", InteractionID: "user-9"})
+
+	if !strings.Contains(buf.String(), "trigger=hover") || !strings.Contains(buf.String(), "interaction=user-9") {
+		t.Errorf("Expected log line with trigger and interaction, got %q", buf.String())
+	}
+	if recorded <= 0 {
+		t.Error("Expected latency to be recorded")
+	}
+}
@@ -0,0 +1,289 @@
+package dialog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HubScheme prefixes modelPath values naming a file in a HuggingFace Hub repository:
+// hf://<owner>/<repo>/<file>, optionally pinned with @<revision> after the repository
+const HubScheme = "hf://"
+
+// Environment variables read when resolving Hub models
+const (
+	EnvModelCache = "MINILM_MODEL_CACHE" // Directory downloaded models are cached in
+	EnvHubToken   = "HF_TOKEN"           // Access token for gated or private repositories
+)
+
+// Hub download settings
+const (
+	defaultHubRevision        = "main" // Branch downloaded when a Hub path names no revision
+	maxHubRedirects           = 10
+	defaultHubDownloadTimeout = 30 * time.Minute // Limit on each download while initializing
+)
+
+// hubBaseURL is the HuggingFace Hub endpoint, replaceable in tests
+var hubBaseURL = "https://huggingface.co"
+
+// HubModelRef identifies one file in a HuggingFace Hub repository
+type HubModelRef struct {
+	Repo     string // e.g. "TheBloke/TinyLlama-1.1B-Chat-GGUF"
+	File     string // Path inside the repository, e.g. "tinyllama.Q4_K_M.gguf"
+	Revision string // Branch, tag or commit (default: "main")
+}
+
+// IsHubModelPath reports whether a modelPath names a HuggingFace Hub file
+func IsHubModelPath(modelPath string) bool {
+	return strings.HasPrefix(modelPath, HubScheme)
+}
+
+// ParseHubModelPath splits an hf:// modelPath into repository, file and revision
+func ParseHubModelPath(modelPath string) (HubModelRef, error) {
+	rest, ok := strings.CutPrefix(modelPath, HubScheme)
+	if !ok {
+		return HubModelRef{}, fmt.Errorf("model path %q does not start with %s", modelPath, HubScheme)
+	}
+
+	parts := strings.SplitN(rest, "/", 3)
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return HubModelRef{}, fmt.Errorf("model path %q must look like %s<owner>/<repo>/<file>", modelPath, HubScheme)
+	}
+	ref := HubModelRef{Repo: parts[0] + "/" + parts[1], File: parts[2], Revision: defaultHubRevision}
+	if repo, revision, pinned := strings.Cut(ref.Repo, "@"); pinned {
+		if revision == "" {
+			return HubModelRef{}, fmt.Errorf("model path %q has an empty revision", modelPath)
+		}
+		ref.Repo, ref.Revision = repo, revision
+	}
+	// Every part becomes a directory under the model cache, so none may climb out of it
+	owner, name, _ := strings.Cut(ref.Repo, "/")
+	if !validHubSegment(owner) || !validHubSegment(name) {
+		return HubModelRef{}, fmt.Errorf("model path %q has an invalid repository", modelPath)
+	}
+	if !validHubSegment(ref.Revision) || strings.Contains(ref.Revision, "..") {
+		return HubModelRef{}, fmt.Errorf("model path %q has an invalid revision", modelPath)
+	}
+	for _, segment := range strings.Split(ref.File, "/") {
+		if !validHubSegment(segment) {
+			return HubModelRef{}, fmt.Errorf("model path %q has an invalid file path", modelPath)
+		}
+	}
+	return ref, nil
+}
+
+// validHubSegment reports whether one path segment of a Hub reference is safe to use
+// as a cache directory name
+func validHubSegment(segment string) bool {
+	return segment != "" && segment != "." && segment != ".." && !strings.ContainsAny(segment, `/\`)
+}
+
+// URL returns the download address of the file
+func (r HubModelRef) URL() string {
+	return fmt.Sprintf("%s/%s/resolve/%s/%s", hubBaseURL, r.Repo, r.Revision, r.File)
+}
+
+// CachePath returns where the file is kept under a cache directory
+func (r HubModelRef) CachePath(cacheDir string) string {
+	return filepath.Join(cacheDir, filepath.FromSlash(r.Repo), r.Revision, filepath.FromSlash(r.File))
+}
+
+// DefaultModelCacheDir returns $MINILM_MODEL_CACHE, or minilm/models in the user cache directory
+func DefaultModelCacheDir() (string, error) {
+	if dir := os.Getenv(EnvModelCache); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no model cache directory (set %s): %w", EnvModelCache, err)
+	}
+	return filepath.Join(dir, "minilm", "models"), nil
+}
+
+// ResolveHubModel returns the cached copy of an hf:// model, downloading it first when
+// it is not cached. Downloads are verified against expectedSHA256 when given, otherwise
+// against the SHA-256 the Hub reports for the file, and are only cached once verified
+func ResolveHubModel(ctx context.Context, modelPath, cacheDir, expectedSHA256 string) (string, error) {
+	ref, err := ParseHubModelPath(modelPath)
+	if err != nil {
+		return "", err
+	}
+	if cacheDir == "" {
+		if cacheDir, err = DefaultModelCacheDir(); err != nil {
+			return "", err
+		}
+	}
+
+	path := ref.CachePath(cacheDir)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := downloadHubModel(ctx, ref, path, strings.ToLower(expectedSHA256)); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", modelPath, err)
+	}
+	return path, nil
+}
+
+// downloadHubModel fetches a Hub file into a temporary file beside path, verifies it and
+// moves it into place
+func downloadHubModel(ctx context.Context, ref HubModelRef, path, expectedSHA256 string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, ref.URL(), nil)
+	if err != nil {
+		return err
+	}
+	if token := os.Getenv(EnvHubToken); token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	// The Hub answers with a redirect to its file store; the checksum is on the redirect
+	var reportedSHA256 string
+	client := &http.Client{CheckRedirect: func(next *http.Request, via []*http.Request) error {
+		if reportedSHA256 == "" && next.Response != nil {
+			reportedSHA256 = hubFileSHA256(next.Response.Header)
+		}
+		if len(via) >= maxHubRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxHubRedirects)
+		}
+		return nil
+	}}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s not found in %s at %s", ErrModelNotFound, ref.File, ref.Repo, ref.Revision)
+	case response.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected response %s", response.Status)
+	}
+
+	if expectedSHA256 == "" {
+		expectedSHA256 = reportedSHA256
+	}
+	if expectedSHA256 == "" {
+		expectedSHA256 = hubFileSHA256(response.Header)
+	}
+	if expectedSHA256 == "" {
+		return fmt.Errorf("the Hub reported no checksum for %s; set modelSha256 to verify it", ref.File)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	partial, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.partial")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(partial.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(partial, hash), response.Body)
+	if closeErr := partial.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expectedSHA256 {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", expectedSHA256, actual)
+	}
+	return os.Rename(partial.Name(), path)
+}
+
+// hubFileSHA256 returns the SHA-256 the Hub reports for a large (LFS) file in its ETag
+func hubFileSHA256(header http.Header) string {
+	for _, name := range []string{"X-Linked-Etag", "ETag"} {
+		etag := strings.Trim(strings.TrimPrefix(header.Get(name), "W/"), `"`)
+		if len(etag) == sha256.Size*2 {
+			if _, err := hex.DecodeString(etag); err == nil {
+				return strings.ToLower(etag)
+			}
+		}
+	}
+	return ""
+}
+
+// applyModelSource validates hf:// model and adapter paths and the settings their downloads
+// are verified and limited with
+func (llm *LLMBackend) applyModelSource(cfg LLMConfig) error {
+	if IsHubModelPath(cfg.ModelPath) {
+		if _, err := ParseHubModelPath(cfg.ModelPath); err != nil {
+			return invalidConfig("modelPath", err)
+		}
+	}
+	if IsHubModelPath(cfg.LoraPath) {
+		if _, err := ParseHubModelPath(cfg.LoraPath); err != nil {
+			return invalidConfig("loraPath", err)
+		}
+	}
+	if cfg.LoraScale < 0 {
+		return configErrorf("loraScale", "loraScale must be non-negative, got %g", cfg.LoraScale)
+	}
+	llm.loraPath, llm.loraScale = cfg.LoraPath, cfg.LoraScale
+	if cfg.ModelSHA256 != "" {
+		if sum, err := hex.DecodeString(cfg.ModelSHA256); err != nil || len(sum) != sha256.Size {
+			return configErrorf("modelSha256", "modelSha256 must be 64 hex characters, got %q", cfg.ModelSHA256)
+		}
+	}
+	if cfg.DownloadTimeoutMs < 0 {
+		return configErrorf("downloadTimeoutMs", "downloadTimeoutMs must be non-negative, got %d", cfg.DownloadTimeoutMs)
+	}
+	return nil
+}
+
+// fetchHubModels returns cfg with hf:// modelPath and loraPath replaced by their cached
+// local copies, downloading them within the configured download timeout
+// Configs that applyConfig will reject are returned unchanged for it to report
+func (llm *LLMBackend) fetchHubModels(ctx context.Context, cfg LLMConfig) (LLMConfig, error) {
+	llm.mu.RLock()
+	resolved, err := llm.resolveModelAlias(cfg)
+	skip := llm.customModel != nil
+	llm.mu.RUnlock()
+	if err != nil || skip || resolved.MockFixture != "" || resolved.DownloadTimeoutMs < 0 {
+		return cfg, nil
+	}
+	if !IsHubModelPath(resolved.ModelPath) && !IsHubModelPath(resolved.LoraPath) {
+		return cfg, nil
+	}
+	resolved.Model = "" // Resolved here; applyConfig must not look the alias up again
+
+	timeout := defaultHubDownloadTimeout
+	if resolved.DownloadTimeoutMs > 0 {
+		timeout = time.Duration(resolved.DownloadTimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if IsHubModelPath(resolved.ModelPath) {
+		if _, err := ParseHubModelPath(resolved.ModelPath); err != nil {
+			return cfg, nil
+		}
+		if sum, err := hex.DecodeString(resolved.ModelSHA256); err != nil || (len(sum) != 0 && len(sum) != sha256.Size) {
+			return cfg, nil
+		}
+		path, err := ResolveHubModel(ctx, resolved.ModelPath, resolved.ModelCacheDir, resolved.ModelSHA256)
+		if err != nil {
+			return cfg, err
+		}
+		resolved.ModelPath = path
+	}
+	if IsHubModelPath(resolved.LoraPath) {
+		if _, err := ParseHubModelPath(resolved.LoraPath); err != nil {
+			return cfg, nil
+		}
+		path, err := ResolveHubModel(ctx, resolved.LoraPath, resolved.ModelCacheDir, "")
+		if err != nil {
+			return cfg, err
+		}
+		resolved.LoraPath = path
+	}
+	return resolved, nil
+}
@@ -0,0 +1,219 @@
+package dialog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubHub serves files from a fake Hub that redirects downloads to a file store,
+// reporting each file's SHA-256 on the redirect as the real Hub does for LFS files
+func stubHub(t *testing.T, files map[string][]byte, reportChecksum bool) *atomic.Int32 {
+	t.Helper()
+	downloads := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := strings.CutPrefix(r.URL.Path, "/store/"); ok {
+			downloads.Add(1)
+			w.Write(files[name])
+			return
+		}
+		for name, content := range files {
+			if strings.HasSuffix(r.URL.Path, "/resolve/main/"+name) {
+				if reportChecksum {
+					sum := sha256.Sum256(content)
+					w.Header().Set("X-Linked-Etag", `"`+hex.EncodeToString(sum[:])+`"`)
+				}
+				http.Redirect(w, r, "/store/"+name, http.StatusFound)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	original := hubBaseURL
+	hubBaseURL = server.URL
+	t.Cleanup(func() { hubBaseURL = original })
+	return downloads
+}
+
+func TestParseHubModelPath(t *testing.T) {
+	ref, err := ParseHubModelPath("hf://TheBloke/TinyLlama-1.1B-Chat-GGUF/tinyllama.Q4_K_M.gguf")
+	if err != nil {
+		t.Fatalf("ParseHubModelPath failed: %v", err)
+	}
+	expected := HubModelRef{Repo: "TheBloke/TinyLlama-1.1B-Chat-GGUF", File: "tinyllama.Q4_K_M.gguf", Revision: "main"}
+	if ref != expected {
+		t.Errorf("Expected %+v, got %+v", expected, ref)
+	}
+
+	ref, err = ParseHubModelPath("hf://owner/repo@v1.0/quantized/model.gguf")
+	if err != nil || ref.Repo != "owner/repo" || ref.Revision != "v1.0" || ref.File != "quantized/model.gguf" {
+		t.Errorf("Expected a pinned revision and nested file, got %+v, %v", ref, err)
+	}
+
+	invalid := []struct {
+		name string
+		path string
+	}{
+		{"local path", "/models/model.gguf"},
+		{"no repository", "hf://owner/model.gguf"},
+		{"empty revision", "hf://owner/repo@/model.gguf"},
+		{"parent file segment", "hf://owner/repo/../model.gguf"},
+		{"parent owner", "hf://../repo/model.gguf"},
+		{"current directory owner", "hf://./repo/model.gguf"},
+		{"parent repository", "hf://owner/..@main/model.gguf"},
+		{"parent revision", "hf://owner/repo@../model.gguf"},
+		{"revision containing ..", "hf://owner/repo@v1..2/model.gguf"},
+		{"backslash in repository", `hf://owner/re\po/model.gguf`},
+		{"traversal everywhere", "hf://../../x@../../../etc/file"},
+	}
+	for _, tt := range invalid {
+		if _, err := ParseHubModelPath(tt.path); err == nil {
+			t.Errorf("%s: expected %q to be rejected", tt.name, tt.path)
+		}
+	}
+}
+
+func TestResolveHubModel_DownloadsOnceAndVerifies(t *testing.T) {
+	downloads := stubHub(t, map[string][]byte{"model.gguf": []byte("weights")}, true)
+	cacheDir := t.TempDir()
+
+	path, err := ResolveHubModel(context.Background(), "hf://owner/repo/model.gguf", cacheDir, "")
+	if err != nil {
+		t.Fatalf("ResolveHubModel failed: %v", err)
+	}
+	if expected := filepath.Join(cacheDir, "owner", "repo", "main", "model.gguf"); path != expected {
+		t.Errorf("Expected the model cached at %s, got %s", expected, path)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "weights" {
+		t.Errorf("Expected the downloaded content, got %q", content)
+	}
+
+	if _, err := ResolveHubModel(context.Background(), "hf://owner/repo/model.gguf", cacheDir, ""); err != nil || downloads.Load() != 1 {
+		t.Errorf("Expected the second run to load from cache, got %d downloads, %v", downloads.Load(), err)
+	}
+}
+
+func TestResolveHubModel_RejectsUnverifiedDownloads(t *testing.T) {
+	stubHub(t, map[string][]byte{"model.gguf": []byte("weights")}, false)
+	cacheDir := t.TempDir()
+
+	if _, err := ResolveHubModel(context.Background(), "hf://owner/repo/model.gguf", cacheDir, ""); err == nil {
+		t.Error("Expected a download without any checksum to fail")
+	}
+	wrong := strings.Repeat("0", 64)
+	if _, err := ResolveHubModel(context.Background(), "hf://owner/repo/model.gguf", cacheDir, wrong); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(HubModelRef{Repo: "owner/repo", File: "model.gguf", Revision: "main"}.CachePath(cacheDir)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected nothing cached after failed verification, got %v", err)
+	}
+
+	if _, err := ResolveHubModel(context.Background(), "hf://owner/repo/missing.gguf", cacheDir, ""); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound for a missing file, got %v", err)
+	}
+}
+
+func TestLLMBackend_LoadsHubModel(t *testing.T) {
+	local := writeGGUF(t, "tiny.Q4_K_M.gguf", map[string]interface{}{"general.file_type": uint32(15)}, 1024)
+	content, _ := os.ReadFile(local)
+	stubHub(t, map[string][]byte{"tiny.Q4_K_M.gguf": content}, true)
+	stubAvailableMemory(t, 1<<30)
+
+	cacheDir := t.TempDir()
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: "hf://owner/repo/tiny.Q4_K_M.gguf", ModelCacheDir: cacheDir})
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if !backend.useProductionModel || !strings.HasPrefix(backend.modelPath, cacheDir) {
+		t.Errorf("Expected the production model loaded from the cache, got %s", backend.modelPath)
+	}
+
+	invalid, _ := json.Marshal(LLMConfig{ModelPath: "hf://owner/repo/tiny.gguf", ModelSHA256: "abc"})
+	if err := NewLLMBackend().Initialize(invalid); !errors.Is(err, ErrConfigInvalid) {
+		t.Errorf("Expected an invalid modelSha256 to be rejected, got %v", err)
+	}
+}
+
+// stallingHub serves a Hub whose downloads never finish until release is closed
+func stallingHub(t *testing.T) (started <-chan struct{}, release chan struct{}) {
+	t.Helper()
+	startedCh, release := make(chan struct{}, 1), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case startedCh <- struct{}{}:
+		default:
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	original := hubBaseURL
+	hubBaseURL = server.URL
+	t.Cleanup(func() { hubBaseURL = original })
+	return startedCh, release
+}
+
+func TestLLMBackend_HubDownloadDoesNotHoldLock(t *testing.T) {
+	started, release := stallingHub(t)
+	backend := NewLLMBackend()
+	defer backend.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- backend.InitializeConfig(LLMConfig{ModelPath: "hf://owner/repo/model.gguf", ModelCacheDir: t.TempDir()})
+	}()
+	<-started
+
+	// Callers needing the backend lock are not blocked behind the download
+	locked := make(chan struct{})
+	go func() {
+		backend.GetContextManager()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the backend lock to be free while downloading")
+	}
+	close(release)
+	<-done
+}
+
+func TestLLMBackend_HubDownloadCancellation(t *testing.T) {
+	stallingHub(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: "hf://owner/repo/model.gguf", ModelCacheDir: t.TempDir()})
+	if err := NewLLMBackend().InitializeContext(ctx, configJSON); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's context to stop the download, got %v", err)
+	}
+
+	err := NewLLMBackend().InitializeConfig(LLMConfig{ModelPath: "hf://owner/repo/model.gguf", ModelCacheDir: t.TempDir(), DownloadTimeoutMs: 50})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected downloadTimeoutMs to stop the download, got %v", err)
+	}
+
+	err = NewLLMBackend().InitializeConfig(LLMConfig{ModelPath: "hf://owner/repo/model.gguf", DownloadTimeoutMs: -1})
+	if !errors.Is(err, ErrConfigInvalid) {
+		t.Errorf("Expected a negative downloadTimeoutMs to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package dialog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// EnvModelRegistry names the models.json file read when a config references a model alias
+const EnvModelRegistry = "MINILM_MODEL_REGISTRY"
+
+// ModelRegistry maps model aliases such as "tinyllama-q4" to model files, so character
+// files can name a model with LLMConfig.Model instead of a host-specific modelPath
+type ModelRegistry struct {
+	Models map[string]ModelRegistryEntry `json:"models"`
+}
+
+// ModelRegistryEntry describes one registered model
+type ModelRegistryEntry struct {
+	Path         string              `json:"path"`                   // GGUF file, relative to the registry file, or hf://<owner>/<repo>/<file>
+	Quantization string              `json:"quantization,omitempty"` // e.g. "Q4_K_M"; informational
+	SHA256       string              `json:"sha256,omitempty"`       // Expected checksum of an hf:// download
+	Recommended  RecommendedSettings `json:"recommended,omitempty"`  // Used for settings the config leaves unset
+}
+
+// RecommendedSettings are a model's suggested generation settings
+type RecommendedSettings struct {
+	ContextSize int     `json:"contextSize,omitempty"`
+	MaxTokens   int     `json:"maxTokens,omitempty"`
+	Temperature float32 `json:"temperature,omitempty"`
+	TopP        float32 `json:"topP,omitempty"`
+	Threads     int     `json:"threads,omitempty"`
+}
+
+// LoadModelRegistry reads a models.json file
+// Relative local paths are resolved against the registry file's directory
+func LoadModelRegistry(path string) (*ModelRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model registry: %w", err)
+	}
+
+	var registry ModelRegistry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse model registry %s: %w", path, err)
+	}
+	for alias, entry := range registry.Models {
+		if entry.Path == "" {
+			return nil, fmt.Errorf("model registry %s: model '%s' has no path", path, alias)
+		}
+		if !IsHubModelPath(entry.Path) && !filepath.IsAbs(entry.Path) {
+			entry.Path = filepath.Join(filepath.Dir(path), entry.Path)
+			registry.Models[alias] = entry
+		}
+	}
+	return &registry, nil
+}
+
+// DefaultModelRegistryPath returns $MINILM_MODEL_REGISTRY, or minilm/models.json in the user config directory
+func DefaultModelRegistryPath() (string, error) {
+	if path := os.Getenv(EnvModelRegistry); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no model registry location (set %s): %w", EnvModelRegistry, err)
+	}
+	return filepath.Join(dir, "minilm", "models.json"), nil
+}
+
+// Lookup returns the entry registered under alias
+func (r *ModelRegistry) Lookup(alias string) (ModelRegistryEntry, bool) {
+	if r == nil {
+		return ModelRegistryEntry{}, false
+	}
+	entry, exists := r.Models[alias]
+	return entry, exists
+}
+
+// Aliases lists the registered model aliases
+func (r *ModelRegistry) Aliases() []string {
+	if r == nil {
+		return nil
+	}
+	aliases := make([]string, 0, len(r.Models))
+	for alias := range r.Models {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// Resolve fills a config naming a model alias from its registry entry
+// An explicit modelPath (for example a MINILM_MODEL_PATH override) is kept, and only
+// settings the config leaves unset take the model's recommended values
+func (r *ModelRegistry) Resolve(cfg LLMConfig) (LLMConfig, error) {
+	if cfg.Model == "" {
+		return cfg, nil
+	}
+	entry, exists := r.Lookup(cfg.Model)
+	if !exists {
+		known := "none registered"
+		if aliases := r.Aliases(); len(aliases) > 0 {
+			known = "known: " + strings.Join(aliases, ", ")
+		}
+		return cfg, configErrorf("model", "unknown model '%s' (%s)", cfg.Model, known)
+	}
+
+	if cfg.ModelPath == "" {
+		cfg.ModelPath = entry.Path
+		if cfg.ModelSHA256 == "" {
+			cfg.ModelSHA256 = entry.SHA256
+		}
+	}
+	recommended := entry.Recommended
+	if cfg.ContextSize == 0 {
+		cfg.ContextSize = recommended.ContextSize
+	}
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = recommended.MaxTokens
+	}
+	if cfg.Temperature == 0 {
+		cfg.Temperature = recommended.Temperature
+	}
+	if cfg.TopP == 0 {
+		cfg.TopP = recommended.TopP
+	}
+	if cfg.Threads == 0 {
+		cfg.Threads = recommended.Threads
+	}
+	return cfg, nil
+}
+
+// SetModelRegistry sets the registry used to resolve LLMConfig.Model; without one,
+// Initialize reads the registry at DefaultModelRegistryPath
+func (llm *LLMBackend) SetModelRegistry(registry *ModelRegistry) {
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	llm.modelRegistry = registry
+}
+
+// resolveModelAlias fills a config naming a model alias from the backend's registry
+// A missing default registry only fails configs that have no modelPath to fall back on
+func (llm *LLMBackend) resolveModelAlias(cfg LLMConfig) (LLMConfig, error) {
+	if cfg.Model == "" {
+		return cfg, nil
+	}
+	registry := llm.modelRegistry
+	if registry == nil {
+		path, err := DefaultModelRegistryPath()
+		if err != nil {
+			return cfg, invalidConfig("model", err)
+		}
+		registry, err = LoadModelRegistry(path)
+		if errors.Is(err, os.ErrNotExist) {
+			if cfg.ModelPath != "" {
+				llm.logf("Model registry %s does not exist; using modelPath %s for model '%s'", path, cfg.ModelPath, cfg.Model)
+				return cfg, nil
+			}
+			return cfg, configErrorf("model", "model '%s' needs a model registry, but %s does not exist (set %s)",
+				cfg.Model, path, EnvModelRegistry)
+		}
+		if err != nil {
+			return cfg, invalidConfig("model", err)
+		}
+	}
+	return registry.Resolve(cfg)
+}
+
+// missingModelRegistry returns the default registry path when cfg names an alias that
+// resolveModelAlias will skip because the registry does not exist
+func (llm *LLMBackend) missingModelRegistry(cfg LLMConfig) (string, bool) {
+	if cfg.Model == "" || cfg.ModelPath == "" || llm.modelRegistry != nil {
+		return "", false
+	}
+	path, err := DefaultModelRegistryPath()
+	if err != nil {
+		return "", false
+	}
+	_, err = os.Stat(path)
+	return path, errors.Is(err, os.ErrNotExist)
+}
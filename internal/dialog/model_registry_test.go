@@ -0,0 +1,147 @@
+package dialog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeModelRegistry writes a models.json file and points MINILM_MODEL_REGISTRY at it
+func writeModelRegistry(t *testing.T, registry ModelRegistry) string {
+	t.Helper()
+	data, err := json.Marshal(registry)
+	if err != nil {
+		t.Fatalf("Failed to marshal registry: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "models.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write registry: %v", err)
+	}
+	t.Setenv(EnvModelRegistry, path)
+	return path
+}
+
+func TestLoadModelRegistry(t *testing.T) {
+	path := writeModelRegistry(t, ModelRegistry{Models: map[string]ModelRegistryEntry{
+		"tinyllama-q4": {Path: "tinyllama.Q4_K_M.gguf", Quantization: "Q4_K_M"},
+		"phi-q8":       {Path: "hf://owner/repo/phi.Q8_0.gguf"},
+	}})
+
+	registry, err := LoadModelRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadModelRegistry failed: %v", err)
+	}
+	entry, exists := registry.Lookup("tinyllama-q4")
+	if expected := filepath.Join(filepath.Dir(path), "tinyllama.Q4_K_M.gguf"); !exists || entry.Path != expected {
+		t.Errorf("Expected a relative path resolved to %s, got %+v", expected, entry)
+	}
+	if entry, _ := registry.Lookup("phi-q8"); entry.Path != "hf://owner/repo/phi.Q8_0.gguf" {
+		t.Errorf("Expected hf:// paths kept as they are, got %s", entry.Path)
+	}
+	if aliases := registry.Aliases(); len(aliases) != 2 || aliases[0] != "phi-q8" {
+		t.Errorf("Expected sorted aliases, got %v", aliases)
+	}
+
+	os.WriteFile(path, []byte(`{"models": {"broken": {}}}`), 0o644)
+	if _, err := LoadModelRegistry(path); err == nil {
+		t.Error("Expected an entry without a path to be rejected")
+	}
+}
+
+func TestModelRegistry_Resolve(t *testing.T) {
+	registry := &ModelRegistry{Models: map[string]ModelRegistryEntry{
+		"tinyllama-q4": {
+			Path:        "/models/tinyllama.gguf",
+			SHA256:      "abc",
+			Recommended: RecommendedSettings{ContextSize: 1024, MaxTokens: 40, Temperature: 0.6, Threads: 6},
+		},
+	}}
+
+	cfg, err := registry.Resolve(LLMConfig{Model: "tinyllama-q4", MaxTokens: 80})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if cfg.ModelPath != "/models/tinyllama.gguf" || cfg.ModelSHA256 != "abc" {
+		t.Errorf("Expected the registered path and checksum, got %q, %q", cfg.ModelPath, cfg.ModelSHA256)
+	}
+	if cfg.ContextSize != 1024 || cfg.Temperature != 0.6 || cfg.Threads != 6 {
+		t.Errorf("Expected recommended settings for unset fields, got %+v", cfg)
+	}
+	if cfg.MaxTokens != 80 {
+		t.Errorf("Expected the configured maxTokens kept, got %d", cfg.MaxTokens)
+	}
+
+	cfg, _ = registry.Resolve(LLMConfig{Model: "tinyllama-q4", ModelPath: "/override.gguf"})
+	if cfg.ModelPath != "/override.gguf" || cfg.ModelSHA256 != "" {
+		t.Errorf("Expected an explicit modelPath to win, got %q, %q", cfg.ModelPath, cfg.ModelSHA256)
+	}
+
+	_, err = registry.Resolve(LLMConfig{Model: "missing"})
+	var configErr *ConfigError
+	if !errors.Is(err, ErrConfigInvalid) || !errors.As(err, &configErr) || configErr.Field != "model" {
+		t.Errorf("Expected a ConfigError for field model, got %v", err)
+	}
+}
+
+func TestLLMBackend_ModelAlias(t *testing.T) {
+	writeModelRegistry(t, ModelRegistry{Models: map[string]ModelRegistryEntry{
+		"tiny": {Path: "tiny.bin", Recommended: RecommendedSettings{MaxTokens: 30}},
+	}})
+
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.Initialize(json.RawMessage(`{"model": "tiny"}`)); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if filepath.Base(backend.modelPath) != "tiny.bin" || backend.maxTokens != 30 {
+		t.Errorf("Expected the registry's model and maxTokens, got %s and %d", backend.modelPath, backend.maxTokens)
+	}
+
+	// An explicitly set registry replaces the default one
+	other := NewLLMBackend()
+	defer other.Close()
+	other.SetModelRegistry(&ModelRegistry{})
+	if err := other.Initialize(json.RawMessage(`{"model": "tiny"}`)); !errors.Is(err, ErrConfigInvalid) {
+		t.Errorf("Expected an unknown alias to fail, got %v", err)
+	}
+}
+
+func TestLLMBackend_ModelAliasWithoutRegistry(t *testing.T) {
+	t.Setenv(EnvModelRegistry, filepath.Join(t.TempDir(), "models.json"))
+
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.Initialize(json.RawMessage(`{"model": "tiny"}`)); !errors.Is(err, ErrConfigInvalid) {
+		t.Errorf("Expected a missing registry to fail as invalid config, got %v", err)
+	}
+
+	// A modelPath (for example from MINILM_MODEL_PATH) stands in for the missing registry
+	withPath := NewLLMBackend()
+	defer withPath.Close()
+	if err := withPath.Initialize(json.RawMessage(`{"model": "tiny", "modelPath": "/fake/path.gguf"}`)); err != nil {
+		t.Errorf("Expected modelPath to be used without a registry, got %v", err)
+	}
+
+	diags := DiagnoseConfig([]byte(`{"model": "tiny", "modelPath": "mock://model"}`))
+	if diag := findDiagnostic(diags, "$.model"); diag == nil || diag.Severity != SeverityWarning {
+		t.Errorf("Expected a warning about the missing registry, got %v", diags)
+	}
+}
+
+func TestDiagnoseConfig_ModelAlias(t *testing.T) {
+	writeModelRegistry(t, ModelRegistry{Models: map[string]ModelRegistryEntry{
+		"tiny": {Path: "missing.gguf"},
+	}})
+
+	diags := DiagnoseConfig([]byte(`{"model": "tiny"}`))
+	if findDiagnostic(diags, "$.modelPath") == nil {
+		t.Errorf("Expected the registered model file to be checked, got %v", diags)
+	}
+
+	diags = DiagnoseConfig([]byte(`{"model": "unknown"}`))
+	if findDiagnostic(diags, "$.model") == nil || findDiagnostic(diags, "$.modelPath") != nil {
+		t.Errorf("Expected only the unknown alias reported, got %v", diags)
+	}
+}
@@ -0,0 +1,187 @@
+package dialog
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Mood engine defaults
+const (
+	defaultMoodBaseline         = 60
+	defaultMoodHalfLife         = 30 * time.Minute
+	defaultPositiveFeedbackMood = 5
+	defaultNegativeFeedbackMood = -8
+	moodTrendThreshold          = 3 // Smallest change described as a mood trend in prompts
+)
+
+// defaultTriggerMoodEffects are the mood changes for common triggers
+var defaultTriggerMoodEffects = map[string]float64{
+	"feed":       8,
+	"pet":        6,
+	"compliment": 8,
+	"play":       5,
+	"click":      1,
+	"idle":       -3,
+}
+
+// MoodConfig configures how a MoodEngine evolves mood
+type MoodConfig struct {
+	Baseline         float64            `json:"baseline,omitempty"`         // Mood drifted back to over time (default: 60)
+	HalfLifeMinutes  float64            `json:"halfLifeMinutes,omitempty"`  // Time for half the distance to the baseline to fade (default: 30)
+	TriggerEffects   map[string]float64 `json:"triggerEffects,omitempty"`   // Mood change per trigger, merged over the defaults
+	PositiveFeedback float64            `json:"positiveFeedback,omitempty"` // Mood change after positive feedback (default: 5)
+	NegativeFeedback float64            `json:"negativeFeedback,omitempty"` // Mood change after negative feedback (default: -8)
+}
+
+// MoodEngine evolves each conversation's mood from triggers, feedback and elapsed time
+// Hosts that track mood themselves pass it in DialogContext.CurrentMood and apply
+// DialogResponse.MoodDelta; hosts that leave CurrentMood at 0 let the engine own it
+type MoodEngine struct {
+	baseline         float64
+	halfLife         time.Duration
+	triggerEffects   map[string]float64
+	positiveFeedback float64
+	negativeFeedback float64
+	states           map[string]*moodState
+	mu               sync.Mutex
+}
+
+// moodState is the engine's view of one conversation's mood
+type moodState struct {
+	mood    float64
+	pending float64 // Feedback effects not yet reported in a response
+	updated time.Time
+}
+
+// NewMoodEngine creates a mood engine, applying defaults for unset values
+func NewMoodEngine(config MoodConfig) (*MoodEngine, error) {
+	if config.Baseline < 0 || config.Baseline > 100 {
+		return nil, fmt.Errorf("mood baseline must be between 0 and 100, got %f", config.Baseline)
+	}
+	if config.HalfLifeMinutes < 0 {
+		return nil, fmt.Errorf("mood half-life must be non-negative")
+	}
+	if config.Baseline == 0 {
+		config.Baseline = defaultMoodBaseline
+	}
+	halfLife := time.Duration(config.HalfLifeMinutes * float64(time.Minute))
+	if halfLife == 0 {
+		halfLife = defaultMoodHalfLife
+	}
+	if config.PositiveFeedback == 0 {
+		config.PositiveFeedback = defaultPositiveFeedbackMood
+	}
+	if config.NegativeFeedback == 0 {
+		config.NegativeFeedback = defaultNegativeFeedbackMood
+	}
+
+	effects := make(map[string]float64, len(defaultTriggerMoodEffects)+len(config.TriggerEffects))
+	for trigger, effect := range defaultTriggerMoodEffects {
+		effects[trigger] = effect
+	}
+	for trigger, effect := range config.TriggerEffects {
+		effects[trigger] = effect
+	}
+
+	return &MoodEngine{
+		baseline:         config.Baseline,
+		halfLife:         halfLife,
+		triggerEffects:   effects,
+		positiveFeedback: config.PositiveFeedback,
+		negativeFeedback: config.NegativeFeedback,
+		states:           make(map[string]*moodState),
+	}, nil
+}
+
+// Mood returns a conversation's current mood, including drift toward the baseline
+func (e *MoodEngine) Mood(interactionID string) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, exists := e.states[interactionID]
+	if !exists {
+		return e.baseline
+	}
+	return e.decay(state.mood, currentTime().Sub(state.updated))
+}
+
+// RecordFeedback applies the feedback effect; it is reported in the next response's MoodDelta
+func (e *MoodEngine) RecordFeedback(interactionID string, positive bool) {
+	effect := e.negativeFeedback
+	if positive {
+		effect = e.positiveFeedback
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	state := e.state(interactionID, 0)
+	state.pending += effect
+}
+
+// Update advances a conversation's mood for a trigger, returning the mood before and after
+// A non-zero CurrentMood in ctx replaces the engine's value as the starting point
+func (e *MoodEngine) Update(ctx DialogContext) (before, after float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := currentTime()
+	state := e.state(ctx.InteractionID, ctx.CurrentMood)
+	before = state.mood
+	if ctx.CurrentMood > 0 {
+		before = ctx.CurrentMood
+	}
+
+	after = e.decay(before, now.Sub(state.updated)) + state.pending + e.triggerEffects[ctx.Trigger]
+	after = math.Max(0, math.Min(100, after))
+
+	state.mood = after
+	state.pending = 0
+	state.updated = now
+	return before, after
+}
+
+// state returns a conversation's state, starting new ones at initial or the baseline
+func (e *MoodEngine) state(interactionID string, initial float64) *moodState {
+	state, exists := e.states[interactionID]
+	if !exists {
+		if initial <= 0 {
+			initial = e.baseline
+		}
+		state = &moodState{mood: initial, updated: currentTime()}
+		e.states[interactionID] = state
+	}
+	return state
+}
+
+// decay moves mood toward the baseline by the half-life over elapsed
+func (e *MoodEngine) decay(mood float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return mood
+	}
+	remaining := math.Pow(0.5, float64(elapsed)/float64(e.halfLife))
+	return e.baseline + (mood-e.baseline)*remaining
+}
+
+// wrap updates mood before generation and reports the change in the response
+func (e *MoodEngine) wrap(next DialogHandler) DialogHandler {
+	return func(context DialogContext) (DialogResponse, error) {
+		before, after := e.Update(context)
+		context.CurrentMood = after
+		context.MoodChange = after - before
+
+		response, err := next(context)
+		response.MoodDelta = after - before
+		return response, err
+	}
+}
+
+// SetMoodEngine evolves mood for GenerateDialog: the prompt sees the updated mood and
+// responses carry the MoodDelta for the host to apply; nil disables it
+// Feedback passed to UpdateBackendMemory is forwarded to the engine
+func (dm *DialogManager) SetMoodEngine(engine *MoodEngine) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.moodEngine = engine
+}
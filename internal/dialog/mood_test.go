@@ -0,0 +1,109 @@
+package dialog
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewMoodEngine_Validation(t *testing.T) {
+	if _, err := NewMoodEngine(MoodConfig{Baseline: 120}); err == nil {
+		t.Error("Expected an out-of-range baseline to be rejected")
+	}
+	if _, err := NewMoodEngine(MoodConfig{HalfLifeMinutes: -1}); err == nil {
+		t.Error("Expected a negative half-life to be rejected")
+	}
+}
+
+func TestMoodEngine_TriggersAndDecay(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	engine, _ := NewMoodEngine(MoodConfig{TriggerEffects: map[string]float64{"poke": -10}})
+	if mood := engine.Mood("pet"); mood != defaultMoodBaseline {
+		t.Errorf("Expected new conversations at the baseline, got %f", mood)
+	}
+
+	before, after := engine.Update(DialogContext{InteractionID: "pet", Trigger: "feed"})
+	if before != 60 || after != 68 {
+		t.Errorf("Expected feeding to raise mood from 60 to 68, got %f -> %f", before, after)
+	}
+	if _, after = engine.Update(DialogContext{InteractionID: "pet", Trigger: "poke"}); after != 58 {
+		t.Errorf("Expected a configured trigger effect, got %f", after)
+	}
+
+	// After one half-life, half the distance to the baseline remains
+	engine.Update(DialogContext{InteractionID: "pet", Trigger: "feed", CurrentMood: 80})
+	clock.Advance(30 * time.Minute)
+	if mood := engine.Mood("pet"); math.Abs(mood-(60+(88-60)/2.0)) > 1e-9 {
+		t.Errorf("Expected mood to drift halfway back to the baseline, got %f", mood)
+	}
+}
+
+func TestMoodEngine_ClampsAndFeedback(t *testing.T) {
+	restoreDeterminism(t)
+	SetClock(NewManualClock(time.Now()))
+
+	engine, _ := NewMoodEngine(MoodConfig{})
+	if _, after := engine.Update(DialogContext{InteractionID: "pet", Trigger: "feed", CurrentMood: 97}); after != 100 {
+		t.Errorf("Expected mood clamped to 100, got %f", after)
+	}
+
+	engine.RecordFeedback("pet", false)
+	engine.RecordFeedback("pet", false)
+	before, after := engine.Update(DialogContext{InteractionID: "pet", Trigger: "hover"})
+	if before != 100 || after != 84 {
+		t.Errorf("Expected negative feedback applied on the next update, got %f -> %f", before, after)
+	}
+}
+
+func TestDialogManager_MoodEngine(t *testing.T) {
+	restoreDeterminism(t)
+	SetClock(NewManualClock(time.Now()))
+
+	dm, _ := newRateLimitTestManager(t, "Yum!")
+	engine, _ := NewMoodEngine(MoodConfig{})
+	dm.SetMoodEngine(engine)
+
+	var seen DialogContext
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		seen = *context
+		return nil, nil
+	}))
+
+	context := DialogContext{Trigger: "feed", InteractionID: "pet", CurrentMood: 50}
+	response, err := dm.GenerateDialog(context)
+	if err != nil {
+		t.Fatalf("GenerateDialog() failed: %v", err)
+	}
+	if response.MoodDelta != 8 || seen.CurrentMood != 58 || seen.MoodChange != 8 {
+		t.Errorf("Expected the updated mood in the context and delta in the response, got %f (context %+v)", response.MoodDelta, seen)
+	}
+
+	dm.UpdateBackendMemory(context, response, &UserFeedback{Positive: true})
+	context.CurrentMood = 58
+	context.Trigger = "hover"
+	if response, _ = dm.GenerateDialog(context); response.MoodDelta != 5 {
+		t.Errorf("Expected feedback forwarded to the engine, got delta %f", response.MoodDelta)
+	}
+
+	dm.SetMoodEngine(nil)
+	if response, _ = dm.GenerateDialog(context); response.MoodDelta != 0 {
+		t.Errorf("Expected no delta without an engine, got %f", response.MoodDelta)
+	}
+}
+
+func TestPromptBuilder_MoodTrend(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{Trigger: "feed", CurrentMood: 70, MoodChange: 8})
+	if prompt := pb.Build(); !strings.Contains(prompt, "Mood: happy (70.0/100, just improved)") {
+		t.Errorf("Expected the mood trend in the prompt, got %q", prompt)
+	}
+
+	pb.AddContext(DialogContext{Trigger: "feed", CurrentMood: 70, MoodChange: 1})
+	if prompt := pb.Build(); !strings.Contains(prompt, "Mood: happy (70.0/100)\n") {
+		t.Errorf("Expected small changes not described, got %q", prompt)
+	}
+}
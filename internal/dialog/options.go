@@ -0,0 +1,190 @@
+package dialog
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// LLMOption configures an LLMBackend created by NewLLMBackend
+type LLMOption func(*LLMBackend)
+
+// WithConfig starts from cfg instead of an empty configuration; options after it
+// change individual settings, and JSON passed to Initialize is applied on top
+func WithConfig(cfg LLMConfig) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.baseConfig = cfg
+	}
+}
+
+// WithModelPath sets the GGUF model file, or an hf:// path to download
+func WithModelPath(path string) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.baseConfig.ModelPath = path
+	}
+}
+
+// WithModel sets a model alias looked up in the models.json registry
+func WithModel(alias string) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.baseConfig.Model = alias
+	}
+}
+
+// WithModelRegistry resolves model aliases with registry instead of the default file
+func WithModelRegistry(registry *ModelRegistry) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.modelRegistry = registry
+	}
+}
+
+// WithProductionModel supplies the model implementation, as SetModel does
+func WithProductionModel(model ProductionLLMModel) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.customModel = model
+	}
+}
+
+// WithPromptStrategy replaces the default prompt layout, as SetPromptStrategy does
+func WithPromptStrategy(strategy PromptStrategy) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.promptStrategy = strategy
+	}
+}
+
+// WithTimeout sets the response time budget
+func WithTimeout(timeout time.Duration) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.baseConfig.TimeoutMs = int(timeout / time.Millisecond)
+	}
+}
+
+// WithLogger sends the backend's warnings, such as falling back to the mock model,
+// to logger instead of standard output
+func WithLogger(logger *log.Logger) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.logger = logger
+	}
+}
+
+// WithClock timestamps and ages this backend's history and dates its prompts with
+// clock instead of the package clock (SetClock)
+func WithClock(clock Clock) LLMOption {
+	return func(llm *LLMBackend) {
+		llm.clock = clock
+		llm.contextManager.SetClock(clock)
+	}
+}
+
+// logf writes a warning to the backend's logger, or standard output without one
+func (llm *LLMBackend) logf(format string, args ...interface{}) {
+	if llm.logger != nil {
+		llm.logger.Printf(format, args...)
+		return
+	}
+	fmt.Printf(format+"\n", args...)
+}
+
+// now returns the time from the backend's clock, or the package clock when unset
+func (llm *LLMBackend) now() time.Time {
+	if llm.clock != nil {
+		return llm.clock.Now()
+	}
+	return currentTime()
+}
+
+// ManagerOption configures a DialogManager created by NewDialogManagerWithOptions
+type ManagerOption func(*DialogManager) error
+
+// NewDialogManagerWithOptions creates a dialog manager configured by opts, applied in
+// order, so backends must be added before they are made the default or chained
+func NewDialogManagerWithOptions(opts ...ManagerOption) (*DialogManager, error) {
+	dm := NewDialogManager(false)
+	for _, opt := range opts {
+		if err := opt(dm); err != nil {
+			return nil, err
+		}
+	}
+	return dm, nil
+}
+
+// WithDebug enables debug mode
+func WithDebug(debug bool) ManagerOption {
+	return func(dm *DialogManager) error {
+		dm.debug = debug
+		return nil
+	}
+}
+
+// WithBackend registers a backend; the first one registered becomes the default
+// unless WithDefaultBackend names another
+func WithBackend(name string, backend DialogBackend) ManagerOption {
+	return func(dm *DialogManager) error {
+		if backend == nil {
+			return fmt.Errorf("backend '%s' is nil", name)
+		}
+		dm.RegisterBackend(name, backend)
+		if dm.defaultBackend == "" {
+			return dm.SetDefaultBackend(name)
+		}
+		return nil
+	}
+}
+
+// WithDefaultBackend sets the primary backend
+func WithDefaultBackend(name string) ManagerOption {
+	return func(dm *DialogManager) error {
+		return dm.SetDefaultBackend(name)
+	}
+}
+
+// WithFallbackChain sets the backends tried when the default one fails
+func WithFallbackChain(names ...string) ManagerOption {
+	return func(dm *DialogManager) error {
+		return dm.SetFallbackChain(names)
+	}
+}
+
+// WithConfidenceThreshold sets the confidence below which the fallback chain is tried
+func WithConfidenceThreshold(threshold float64) ManagerOption {
+	return func(dm *DialogManager) error {
+		return dm.SetConfidenceThreshold(threshold)
+	}
+}
+
+// WithResponseTimeout bounds the time spent across the default backend and its fallbacks
+func WithResponseTimeout(timeout time.Duration) ManagerOption {
+	return func(dm *DialogManager) error {
+		return dm.SetResponseTimeout(timeout)
+	}
+}
+
+// WithBackendTimeout bounds each call to the named backend
+func WithBackendTimeout(name string, timeout time.Duration) ManagerOption {
+	return func(dm *DialogManager) error {
+		return dm.SetBackendTimeout(name, timeout)
+	}
+}
+
+// WithOverloadBackend routes requests to the named backend while the chosen one is overloaded
+func WithOverloadBackend(name string) ManagerOption {
+	return func(dm *DialogManager) error {
+		return dm.SetOverloadBackend(name)
+	}
+}
+
+// WithMiddleware wraps generation with middleware, outermost first
+func WithMiddleware(middleware ...Middleware) ManagerOption {
+	return func(dm *DialogManager) error {
+		dm.Use(middleware...)
+		return nil
+	}
+}
+
+// WithReranker sets the reranker used to pick the best below-threshold response
+func WithReranker(reranker Reranker) ManagerOption {
+	return func(dm *DialogManager) error {
+		dm.SetReranker(reranker)
+		return nil
+	}
+}
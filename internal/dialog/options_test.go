@@ -0,0 +1,85 @@
+package dialog
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLLMBackend_Options(t *testing.T) {
+	var logs bytes.Buffer
+	backend := NewLLMBackend(
+		WithConfig(LLMConfig{MaxTokens: 30}),
+		WithModelPath("/fake/path.gguf"),
+		WithTimeout(1500*time.Millisecond),
+		WithLogger(log.New(&logs, "", 0)),
+	)
+	defer backend.Close()
+
+	if err := backend.Initialize(nil); err != nil {
+		t.Fatalf("Initialize() with options only failed: %v", err)
+	}
+	if backend.modelPath != "/fake/path.gguf" || backend.maxTokens != 30 || backend.timeout != 1500*time.Millisecond {
+		t.Errorf("Expected the option settings, got path %q, %d tokens, %v timeout", backend.modelPath, backend.maxTokens, backend.timeout)
+	}
+	if !strings.Contains(logs.String(), "falling back to mock model") {
+		t.Errorf("Expected the mock fallback warning in the logger, got %q", logs.String())
+	}
+}
+
+func TestNewLLMBackend_JSONOverridesOptions(t *testing.T) {
+	backend := NewLLMBackend(WithModelPath("/fake/path.gguf"), WithConfig(LLMConfig{MaxTokens: 30}))
+	defer backend.Close()
+
+	if err := backend.Initialize([]byte(`{"modelPath": "/fake/other.gguf", "maxTokens": 40}`)); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	if backend.modelPath != "/fake/other.gguf" || backend.maxTokens != 40 {
+		t.Errorf("Expected JSON to override options, got %q and %d tokens", backend.modelPath, backend.maxTokens)
+	}
+}
+
+func TestNewLLMBackend_WithClock(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	backend := NewLLMBackend(WithModelPath("/fake/path.gguf"), WithClock(clock), WithLogger(log.New(&bytes.Buffer{}, "", 0)))
+	defer backend.Close()
+	if err := backend.InitializeConfig(LLMConfig{ModelPath: "/fake/path.gguf"}); err != nil {
+		t.Fatalf("InitializeConfig() failed: %v", err)
+	}
+
+	backend.GetContextManager().RecordExchange("clocked", ConversationExchange{Trigger: "click", Response: "Hi!"})
+	history := backend.GetContextManager().GetHistory("clocked", 1)
+	if len(history) != 1 || !history[0].Timestamp.Equal(start) {
+		t.Fatalf("Expected the exchange stamped with the backend's clock, got %+v", history)
+	}
+
+	clock.Advance(10 * time.Minute)
+	if prompt := backend.buildPrompt(DialogContext{Trigger: "click", InteractionID: "clocked"}); !strings.Contains(prompt, "10 minutes ago") {
+		t.Errorf("Expected history aged by the backend's clock, got:\n%s", prompt)
+	}
+}
+
+func TestNewDialogManagerWithOptions(t *testing.T) {
+	primary := &fixedTestBackend{response: DialogResponse{Text: "Uh, what?", Confidence: 0.4}}
+	secondary := &fixedTestBackend{response: DialogResponse{Text: "Hello there!", Confidence: 0.9}}
+
+	dm, err := NewDialogManagerWithOptions(
+		WithBackend("primary", primary),
+		WithBackend("secondary", secondary),
+		WithFallbackChain("secondary"),
+		WithConfidenceThreshold(0.6),
+	)
+	if err != nil {
+		t.Fatalf("NewDialogManagerWithOptions() failed: %v", err)
+	}
+	if response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"}); response.Text != "Hello there!" {
+		t.Errorf("Expected the first backend as default with the fallback chain, got %q", response.Text)
+	}
+
+	if _, err := NewDialogManagerWithOptions(WithFallbackChain("missing")); err == nil {
+		t.Error("Expected an error for an unregistered fallback backend")
+	}
+}
@@ -0,0 +1,164 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultCharsPerSecond is a relaxed typing speed for on-screen speech bubbles
+const defaultCharsPerSecond = 30
+
+// PacingConfig controls how fast a response is revealed to simulate typing
+type PacingConfig struct {
+	CharsPerSecond     float64 `json:"charsPerSecond,omitempty"`     // Typing speed (default: 30)
+	Jitter             float64 `json:"jitter,omitempty"`             // Random variation of each word's delay, 0-1 (0 = steady)
+	PunctuationPauseMs int     `json:"punctuationPauseMs,omitempty"` // Extra pause after . ! ? and … (0 = none)
+}
+
+// TypingUpdate is one step of a paced response
+type TypingUpdate struct {
+	Text  string // Everything typed so far
+	Delta string // Text added by this update
+	Done  bool   // The full response has been typed
+}
+
+// Pacer reveals responses word by word at a typing speed
+type Pacer struct {
+	charsPerSecond   float64
+	jitter           float64
+	punctuationPause time.Duration
+	sleep            func(ctx context.Context, d time.Duration) error // Replaceable in tests
+}
+
+// NewPacer creates a pacer, applying defaults for unset values
+func NewPacer(config PacingConfig) (*Pacer, error) {
+	if config.CharsPerSecond < 0 || config.PunctuationPauseMs < 0 {
+		return nil, fmt.Errorf("pacing values must be non-negative")
+	}
+	if config.Jitter < 0 || config.Jitter > 1 {
+		return nil, fmt.Errorf("pacing jitter must be between 0 and 1, got %f", config.Jitter)
+	}
+	if config.CharsPerSecond == 0 {
+		config.CharsPerSecond = defaultCharsPerSecond
+	}
+
+	return &Pacer{
+		charsPerSecond:   config.CharsPerSecond,
+		jitter:           config.Jitter,
+		punctuationPause: time.Duration(config.PunctuationPauseMs) * time.Millisecond,
+		sleep:            sleepContext,
+	}, nil
+}
+
+// Duration returns how long typing text takes without jitter
+func (p *Pacer) Duration(text string) time.Duration {
+	var total time.Duration
+	for _, word := range splitTypingChunks(text) {
+		total += p.wordDelay(word, 0)
+	}
+	return total
+}
+
+// Type emits text progressively, one word per update, and returns when the final update
+// (Done) has been emitted or ctx is cancelled
+func (p *Pacer) Type(ctx context.Context, text string, emit func(TypingUpdate)) error {
+	return p.typeAfter(ctx, text, 0, emit)
+}
+
+// Stream types text on a channel that is closed after the final update or on cancellation
+func (p *Pacer) Stream(ctx context.Context, text string) <-chan TypingUpdate {
+	updates := make(chan TypingUpdate)
+	go func() {
+		defer close(updates)
+		p.Type(ctx, text, func(update TypingUpdate) {
+			select {
+			case updates <- update:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return updates
+}
+
+// typeAfter types text, treating credit as typing time that has already passed so
+// slow generations are not delayed further
+func (p *Pacer) typeAfter(ctx context.Context, text string, credit time.Duration, emit func(TypingUpdate)) error {
+	words := splitTypingChunks(text)
+	if len(words) == 0 {
+		emit(TypingUpdate{Done: true})
+		return nil
+	}
+
+	var typed strings.Builder
+	for i, word := range words {
+		delay := p.wordDelay(word, p.jitter*(2*randomFloat64()-1))
+		if credit >= delay {
+			credit -= delay
+		} else {
+			if err := p.sleep(ctx, delay-credit); err != nil {
+				return err
+			}
+			credit = 0
+		}
+
+		typed.WriteString(word)
+		emit(TypingUpdate{Text: typed.String(), Delta: word, Done: i == len(words)-1})
+	}
+	return nil
+}
+
+// wordDelay is the time to type one chunk, scaled by (1 + variation)
+func (p *Pacer) wordDelay(word string, variation float64) time.Duration {
+	seconds := float64(utf8.RuneCountInString(word)) / p.charsPerSecond * (1 + variation)
+	delay := time.Duration(seconds * float64(time.Second))
+	if last, _ := utf8.DecodeLastRuneInString(strings.TrimRightFunc(word, unicode.IsSpace)); strings.ContainsRune(".!?…", last) {
+		delay += p.punctuationPause
+	}
+	return delay
+}
+
+// splitTypingChunks splits text into words, each keeping its trailing whitespace
+func splitTypingChunks(text string) []string {
+	var chunks []string
+	start := 0
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if inSpace && !space {
+			chunks = append(chunks, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		chunks = append(chunks, text[start:])
+	}
+	return chunks
+}
+
+// sleepContext waits for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GenerateDialogPaced generates a response and types it out through emit
+// Time spent generating counts toward typing, so only fast (mock or cached) responses are slowed
+func (dm *DialogManager) GenerateDialogPaced(ctx context.Context, dialogContext DialogContext, pacer *Pacer, emit func(TypingUpdate)) (DialogResponse, error) {
+	start := time.Now()
+	response, err := dm.GenerateDialog(dialogContext)
+	if typeErr := pacer.typeAfter(ctx, response.Text, time.Since(start), emit); typeErr != nil && err == nil {
+		err = typeErr
+	}
+	return response, err
+}
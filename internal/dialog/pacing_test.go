@@ -0,0 +1,120 @@
+package dialog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSleeps replaces the pacer's sleep with one that records delays without waiting
+func recordSleeps(p *Pacer) *[]time.Duration {
+	var sleeps []time.Duration
+	p.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return &sleeps
+}
+
+func TestNewPacer_Validation(t *testing.T) {
+	if _, err := NewPacer(PacingConfig{CharsPerSecond: -1}); err == nil {
+		t.Error("Expected negative speed to be rejected")
+	}
+	if _, err := NewPacer(PacingConfig{Jitter: 1.5}); err == nil {
+		t.Error("Expected jitter above 1 to be rejected")
+	}
+	pacer, err := NewPacer(PacingConfig{})
+	if err != nil || pacer.charsPerSecond != defaultCharsPerSecond {
+		t.Errorf("Expected default speed, got %+v (%v)", pacer, err)
+	}
+}
+
+func TestPacer_TypeEmitsWordsAtSpeed(t *testing.T) {
+	pacer, _ := NewPacer(PacingConfig{CharsPerSecond: 10, PunctuationPauseMs: 500})
+	sleeps := recordSleeps(pacer)
+
+	var updates []TypingUpdate
+	if err := pacer.Type(context.Background(), "Hi there. Okay", func(u TypingUpdate) { updates = append(updates, u) }); err != nil {
+		t.Fatalf("Type() failed: %v", err)
+	}
+
+	if len(updates) != 3 || updates[1].Text != "Hi there. " || updates[1].Delta != "there. " {
+		t.Fatalf("Expected word-by-word updates, got %+v", updates)
+	}
+	if !updates[2].Done || updates[2].Text != "Hi there. Okay" || updates[0].Done {
+		t.Errorf("Expected only the last update to be done with the full text, got %+v", updates)
+	}
+
+	expected := []time.Duration{300 * time.Millisecond, 1200 * time.Millisecond, 400 * time.Millisecond}
+	for i, want := range expected {
+		if (*sleeps)[i] != want {
+			t.Errorf("Expected delay %v for word %d, got %v", want, i, (*sleeps)[i])
+		}
+	}
+	if total := pacer.Duration("Hi there. Okay"); total != 1900*time.Millisecond {
+		t.Errorf("Expected 1.9s typing duration, got %v", total)
+	}
+}
+
+func TestPacer_JitterIsBounded(t *testing.T) {
+	restoreDeterminism(t)
+	SetRandomSeed(3)
+	pacer, _ := NewPacer(PacingConfig{CharsPerSecond: 10, Jitter: 0.5})
+	sleeps := recordSleeps(pacer)
+
+	pacer.Type(context.Background(), strings.Repeat("word ", 20), func(TypingUpdate) {})
+	varied := false
+	for _, d := range *sleeps {
+		if d < 250*time.Millisecond || d > 750*time.Millisecond {
+			t.Errorf("Expected delays within ±50%% of 500ms, got %v", d)
+		}
+		varied = varied || d != (*sleeps)[0]
+	}
+	if !varied {
+		t.Error("Expected jitter to vary delays")
+	}
+}
+
+func TestPacer_StreamStopsOnCancel(t *testing.T) {
+	pacer, _ := NewPacer(PacingConfig{CharsPerSecond: 1000})
+	var texts []string
+	for update := range pacer.Stream(context.Background(), "one two three") {
+		texts = append(texts, update.Text)
+	}
+	if len(texts) != 3 || texts[2] != "one two three" {
+		t.Errorf("Expected three streamed updates, got %v", texts)
+	}
+
+	slow, _ := NewPacer(PacingConfig{CharsPerSecond: 0.1})
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := slow.Stream(ctx, "never typed")
+	cancel()
+	for range updates {
+		t.Error("Expected no updates after cancellation")
+	}
+}
+
+func TestDialogManager_GenerateDialogPacedCreditsGenerationTime(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello there friend")
+	pacer, _ := NewPacer(PacingConfig{CharsPerSecond: 1})
+	sleeps := recordSleeps(pacer)
+
+	var final TypingUpdate
+	response, err := dm.GenerateDialogPaced(context.Background(), DialogContext{Trigger: "click", InteractionID: "pet"}, pacer,
+		func(u TypingUpdate) { final = u })
+	if err != nil {
+		t.Fatalf("GenerateDialogPaced() failed: %v", err)
+	}
+	if !final.Done || final.Text != response.Text {
+		t.Errorf("Expected the typed text to match the response, got %+v", final)
+	}
+
+	var slept time.Duration
+	for _, d := range *sleeps {
+		slept += d
+	}
+	if slept >= pacer.Duration(response.Text) || slept == 0 {
+		t.Errorf("Expected generation time to be credited against %v of typing, slept %v", pacer.Duration(response.Text), slept)
+	}
+}
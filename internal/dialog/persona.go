@@ -0,0 +1,178 @@
+package dialog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// defaultPersonaExamples is the number of persona example lines a blended prompt includes
+const defaultPersonaExamples = 4
+
+// PersonaProfile is a personality that can be blended with others by weight,
+// such as a "cheerful companion" or a "sarcastic gamer"
+type PersonaProfile struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"` // How this persona talks and behaves
+	Traits      map[string]float64 `json:"traits,omitempty"`      // Trait values (0-1) this persona brings
+	Examples    []string           `json:"examples,omitempty"`    // Lines in this persona's voice
+	Weight      float64            `json:"weight"`                // Relative share of the blend
+}
+
+// PersonaBlend is a weighted mix of persona profiles with weights summing to 1
+type PersonaBlend struct {
+	profiles []PersonaProfile
+}
+
+// NewPersonaBlend normalizes the profile weights into a blend, strongest profile first
+func NewPersonaBlend(profiles []PersonaProfile) (*PersonaBlend, error) {
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("persona blend needs at least one profile")
+	}
+
+	total := 0.0
+	seen := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		if strings.TrimSpace(profile.Name) == "" {
+			return nil, fmt.Errorf("persona profile has no name")
+		}
+		if seen[profile.Name] {
+			return nil, fmt.Errorf("persona %q is listed twice", profile.Name)
+		}
+		seen[profile.Name] = true
+		if profile.Weight <= 0 || math.IsNaN(profile.Weight) || math.IsInf(profile.Weight, 0) {
+			return nil, fmt.Errorf("persona %q weight must be positive, got %f", profile.Name, profile.Weight)
+		}
+		total += profile.Weight
+	}
+
+	normalized := make([]PersonaProfile, len(profiles))
+	for i, profile := range profiles {
+		profile.Weight /= total
+		normalized[i] = profile
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].Weight > normalized[j].Weight
+	})
+	return &PersonaBlend{profiles: normalized}, nil
+}
+
+// Profiles returns the blended profiles with normalized weights, strongest first
+func (b *PersonaBlend) Profiles() []PersonaProfile {
+	return append([]PersonaProfile(nil), b.profiles...)
+}
+
+// Traits returns the weighted average of the profiles' traits
+// A profile that does not define a trait contributes 0 to it
+func (b *PersonaBlend) Traits() map[string]float64 {
+	traits := make(map[string]float64)
+	for _, profile := range b.profiles {
+		for trait, value := range profile.Traits {
+			traits[trait] += value * profile.Weight
+		}
+	}
+	return traits
+}
+
+// Describe lists each profile with its share of the blend
+func (b *PersonaBlend) Describe() string {
+	var description strings.Builder
+	description.WriteString("a blend of these personas:\n")
+	for _, profile := range b.profiles {
+		description.WriteString(fmt.Sprintf("- %d%% %s", int(math.Round(profile.Weight*100)), profile.Name))
+		if profile.Description != "" {
+			description.WriteString(": " + profile.Description)
+		}
+		description.WriteString("\n")
+	}
+	return description.String()
+}
+
+// Examples picks up to n example lines, giving each profile a share proportional to its
+// weight (largest remainder first) and passing unused shares to profiles with more lines
+func (b *PersonaBlend) Examples(n int) []string {
+	available := 0
+	for _, profile := range b.profiles {
+		available += len(profile.Examples)
+	}
+	n = min(n, available)
+
+	counts := make([]int, len(b.profiles))
+	remainders := make([]float64, len(b.profiles))
+	assigned := 0
+	for i, profile := range b.profiles {
+		share := profile.Weight * float64(n)
+		counts[i] = min(int(share), len(profile.Examples))
+		remainders[i] = share - float64(counts[i])
+		assigned += counts[i]
+	}
+
+	order := make([]int, len(b.profiles))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for assigned < n {
+		for _, i := range order {
+			if assigned < n && counts[i] < len(b.profiles[i].Examples) {
+				counts[i]++
+				assigned++
+			}
+		}
+	}
+
+	var examples []string
+	for i, profile := range b.profiles {
+		examples = append(examples, profile.Examples[:counts[i]]...)
+	}
+	return examples
+}
+
+// applyPersonas blends the configured persona profiles, if any
+func (llm *LLMBackend) applyPersonas(cfg LLMConfig) error {
+	llm.persona = nil
+	if len(cfg.Personas) == 0 {
+		return nil
+	}
+	blend, err := NewPersonaBlend(cfg.Personas)
+	if err != nil {
+		return invalidConfig("personas", err)
+	}
+	llm.persona = blend
+	return nil
+}
+
+// SetPersonaBlend describes the character as a weighted blend of personas; blended
+// traits fill in traits the context does not set, and persona example lines are
+// shown before any personality added with AddPersonality
+func (pb *PromptBuilder) SetPersonaBlend(blend *PersonaBlend) {
+	pb.persona = blend
+}
+
+// personalityTraits returns the context's traits over the persona blend's traits
+func (pb *PromptBuilder) personalityTraits() map[string]float64 {
+	if pb.persona == nil {
+		return pb.context.PersonalityTraits
+	}
+	traits := pb.persona.Traits()
+	for trait, value := range pb.context.PersonalityTraits {
+		traits[trait] = value
+	}
+	return traits
+}
+
+// personaHeader describes the persona blend and its example lines
+func (pb *PromptBuilder) personaHeader() string {
+	var header strings.Builder
+	header.WriteString("You are a desktop pet character whose personality is " + pb.persona.Describe())
+	if examples := pb.persona.Examples(defaultPersonaExamples); len(examples) > 0 {
+		header.WriteString("Example lines in this blended voice:\n")
+		for _, example := range examples {
+			header.WriteString("- " + example + "\n")
+		}
+	}
+	return header.String()
+}
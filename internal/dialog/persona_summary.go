@@ -0,0 +1,230 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Persona summary modes for LLMConfig.PersonaSummary
+const (
+	PersonaSummaryHeuristic = "heuristic" // Describe tone, sentence length, topics and flourishes found in the lines
+	PersonaSummaryModel     = "model"     // Ask the model to describe the character, falling back to the heuristic
+)
+
+// personaSummaryLines caps the training lines shown to the model when it summarizes
+const personaSummaryLines = 20
+
+// personaFillerWords are common words that say nothing about what a character talks about
+var personaFillerWords = map[string]bool{
+	"about": true, "also": true, "been": true, "can't": true, "could": true, "does": true,
+	"don't": true, "from": true, "going": true, "gonna": true, "have": true, "here": true,
+	"into": true, "it's": true, "just": true, "know": true, "let's": true, "like": true,
+	"make": true, "more": true, "much": true, "only": true, "really": true, "some": true,
+	"than": true, "that's": true, "them": true, "then": true, "there": true, "they": true,
+	"thing": true, "things": true, "want": true, "well": true, "were": true, "what": true,
+	"when": true, "will": true, "would": true, "yeah": true, "yours": true,
+}
+
+// applyPersonaSummary validates the persona summary mode
+func (llm *LLMBackend) applyPersonaSummary(cfg LLMConfig) error {
+	switch cfg.PersonaSummary {
+	case "", PersonaSummaryHeuristic, PersonaSummaryModel:
+		llm.personaSummaryMode = cfg.PersonaSummary
+		llm.personaSummary = ""
+		return nil
+	default:
+		return configErrorf("personaSummary", "personaSummary must be %q or %q, got %q",
+			PersonaSummaryHeuristic, PersonaSummaryModel, cfg.PersonaSummary)
+	}
+}
+
+// summarizePersona distills the training data into the cached persona paragraph once the
+// model is loaded; a model summary that fails or looks unusable falls back to the heuristic
+func (llm *LLMBackend) summarizePersona() {
+	llm.personaSummary = ""
+	if llm.personaSummaryMode == "" || len(llm.markovConfig.TrainingData) == 0 {
+		return
+	}
+	if llm.personaSummaryMode == PersonaSummaryModel {
+		summary, err := llm.modelPersonaSummary()
+		if err == nil {
+			llm.personaSummary = summary
+			return
+		}
+		llm.logf("Model persona summary failed (%v), using the heuristic summary", err)
+	}
+	llm.personaSummary = heuristicPersonaSummary(llm.markovConfig.TrainingData)
+}
+
+// modelPersonaSummary asks the loaded model to describe the character behind the lines
+func (llm *LLMBackend) modelPersonaSummary() (string, error) {
+	if llm.model == nil {
+		return "", fmt.Errorf("no model loaded")
+	}
+	var prompt strings.Builder
+	prompt.WriteString("Here are lines a character said:\n")
+	for _, line := range llm.markovConfig.TrainingData[:min(len(llm.markovConfig.TrainingData), personaSummaryLines)] {
+		prompt.WriteString("- ")
+		prompt.WriteString(line)
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("\nDescribe this character's personality and way of speaking in two or three sentences, " +
+		"addressed to the character and starting with \"You are\".\nDescription:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), llm.timeout)
+	defer cancel()
+	summary, err := llm.model.PredictWithOptions(ctx, prompt.String(), PredictOptions{Temperature: 0.3, MaxTokens: 120})
+	if err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+	if words := len(strings.Fields(summary)); words < 5 || words > 120 {
+		return "", fmt.Errorf("summary of %d words", words)
+	}
+	return summary, nil
+}
+
+// PersonaSummary returns the persona paragraph distilled from the training data at
+// Initialize, or "" when personaSummary is off
+func (llm *LLMBackend) PersonaSummary() string {
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+	return llm.personaSummary
+}
+
+// summarizedPersonality is the prompt's personality section when a persona summary is
+// cached: the paragraph and the single most relevant example line to anchor the voice
+func (llm *LLMBackend) summarizedPersonality(ctx DialogContext) string {
+	var personality strings.Builder
+	personality.WriteString(llm.personaSummary)
+	if llm.exampleSelector != nil {
+		if examples := llm.exampleSelector.Select(buildExampleQuery(ctx), 1); len(examples) > 0 {
+			personality.WriteString("\nFor example: ")
+			personality.WriteString(examples[0])
+		}
+	}
+	personality.WriteString("\n")
+	return personality.String()
+}
+
+// heuristicPersonaSummary describes the voice of the lines: tone from punctuation,
+// sentence length, recurring topics and decorative symbols; "" when nothing stands out
+func heuristicPersonaSummary(lines []string) string {
+	var exclaims, questions, ellipses, lowercase, words int
+	flourishes := make(map[string]int)
+	topics := make(map[string]int)
+
+	for _, line := range lines {
+		words += len(strings.Fields(line))
+		if strings.Contains(line, "!") {
+			exclaims++
+		}
+		if strings.Contains(line, "?") {
+			questions++
+		}
+		if strings.Contains(line, "...") || strings.Contains(line, "…") {
+			ellipses++
+		}
+		if strings.ToLower(line) == line && strings.IndexFunc(line, unicode.IsLetter) >= 0 {
+			lowercase++
+		}
+		for flourish := range lineFlourishes(line) {
+			flourishes[flourish]++
+		}
+		for topic := range lineTopics(line) {
+			topics[topic]++
+		}
+	}
+
+	count := float64(len(lines))
+	var traits []string
+	if float64(exclaims)/count >= 0.4 {
+		traits = append(traits, "enthusiastic")
+	}
+	if float64(questions)/count >= 0.3 {
+		traits = append(traits, "curious")
+	}
+	if float64(ellipses)/count >= 0.25 {
+		traits = append(traits, "a little hesitant")
+	}
+	if float64(lowercase)/count >= 0.5 {
+		traits = append(traits, "casual")
+	}
+
+	var sentences []string
+	if len(traits) > 0 {
+		sentences = append(sentences, "You are "+joinPersonaList(traits)+".")
+	}
+	switch average := float64(words) / count; {
+	case average <= 8:
+		sentences = append(sentences, "You speak in short, snappy sentences.")
+	case average >= 18:
+		sentences = append(sentences, "You speak in long, detailed sentences.")
+	}
+	if recurring := mostFrequent(topics, 2, 3); len(recurring) > 0 {
+		sentences = append(sentences, "You often talk about "+joinPersonaList(recurring)+".")
+	}
+	if recurring := mostFrequent(flourishes, 2, 3); len(recurring) > 0 {
+		sentences = append(sentences, "You like to add "+joinPersonaList(recurring)+" to what you say.")
+	}
+	return strings.Join(sentences, " ")
+}
+
+// lineFlourishes returns the emoticons and decorative symbols in a line, such as "~", "♪"
+// or ":)"
+func lineFlourishes(line string) map[string]bool {
+	found := make(map[string]bool)
+	for _, emoticon := range []string{":)", ":(", ":D", ":P", ";)", "<3", "^^", "xD"} {
+		if strings.Contains(line, emoticon) {
+			found[emoticon] = true
+		}
+	}
+	for _, r := range line {
+		if r == '~' || unicode.Is(unicode.So, r) {
+			found[string(r)] = true
+		}
+	}
+	return found
+}
+
+// lineTopics returns the distinctive words of a line: four letters or more, not filler
+func lineTopics(line string) map[string]bool {
+	found := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		word = strings.Trim(word, "'")
+		if len(word) >= 4 && !stopWords[word] && !personaFillerWords[word] {
+			found[word] = true
+		}
+	}
+	return found
+}
+
+// mostFrequent returns up to limit keys seen at least minCount times, most frequent first
+func mostFrequent(counts map[string]int, minCount, limit int) []string {
+	var keys []string
+	for key, count := range counts {
+		if count >= minCount {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys[:min(len(keys), limit)]
+}
+
+// joinPersonaList joins items as an English list: "a", "a and b", "a, b and c"
+func joinPersonaList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,92 @@
+package dialog
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+var catLines = []string{
+	"Treats? Did someone say treats?! ~",
+	"Naps are the best part of the day! ~",
+	"Ooh, a sunbeam! Time for naps!",
+	"Give me treats and I'll purr for you~",
+	"Play with me! Please?",
+}
+
+func TestHeuristicPersonaSummary(t *testing.T) {
+	summary := heuristicPersonaSummary(catLines)
+	for _, want := range []string{"You are enthusiastic and curious.", "short, snappy sentences", "naps and treats", "add ~"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected the summary to contain %q, got %q", want, summary)
+		}
+	}
+
+	if summary := heuristicPersonaSummary([]string{"The weather report said it will rain over the eastern hills later this evening.", "Please remember to water the garden and close the upstairs windows before bed tonight."}); summary != "" {
+		t.Errorf("Expected no summary for lines without a distinctive voice, got %q", summary)
+	}
+}
+
+func TestLLMBackend_PersonaSummaryInPrompt(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{
+		PersonaSummary: PersonaSummaryHeuristic,
+		MarkovConfig:   MarkovChainConfig{TrainingData: catLines},
+	}, &scriptedTestModel{responses: []string{"Mrrp!"}})
+
+	summary := backend.PersonaSummary()
+	if summary == "" {
+		t.Fatal("Expected a persona summary after Initialize")
+	}
+	prompt := backend.buildPrompt(DialogContext{Trigger: "feed", InteractionID: "sam"})
+	if !strings.Contains(prompt, summary) || !strings.Contains(prompt, "For example: ") {
+		t.Errorf("Expected the summary and one example in the prompt, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Based on these example responses") {
+		t.Errorf("Expected the summary to replace the raw example list, got:\n%s", prompt)
+	}
+}
+
+func TestLLMBackend_ModelPersonaSummary(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"  You are a playful cat who loves treats and naps and speaks in excited bursts.  "}}
+	backend := NewLLMBackend(WithProductionModel(model), WithLogger(log.New(&strings.Builder{}, "", 0)))
+	defer backend.Close()
+	err := backend.InitializeConfig(LLMConfig{
+		PersonaSummary: PersonaSummaryModel,
+		MarkovConfig:   MarkovChainConfig{TrainingData: catLines},
+	})
+	if err != nil {
+		t.Fatalf("InitializeConfig failed: %v", err)
+	}
+	if got := backend.PersonaSummary(); got != "You are a playful cat who loves treats and naps and speaks in excited bursts." {
+		t.Errorf("Expected the model's summary, got %q", got)
+	}
+	if model.calls[0].MaxTokens != 120 {
+		t.Errorf("Expected a bounded summary request, got %+v", model.calls[0])
+	}
+
+	failing := &scriptedTestModel{errors: []error{errors.New("model busy")}}
+	backend = NewLLMBackend(WithProductionModel(failing), WithLogger(log.New(&strings.Builder{}, "", 0)))
+	defer backend.Close()
+	err = backend.InitializeConfig(LLMConfig{
+		PersonaSummary: PersonaSummaryModel,
+		MarkovConfig:   MarkovChainConfig{TrainingData: catLines},
+	})
+	if err != nil {
+		t.Fatalf("InitializeConfig failed: %v", err)
+	}
+	if got := backend.PersonaSummary(); got != heuristicPersonaSummary(catLines) {
+		t.Errorf("Expected the heuristic summary when the model fails, got %q", got)
+	}
+}
+
+func TestLLMBackend_PersonaSummaryConfig(t *testing.T) {
+	backend := NewLLMBackend(WithProductionModel(&scriptedTestModel{}))
+	defer backend.Close()
+	if err := backend.InitializeConfig(LLMConfig{PersonaSummary: "llm"}); err == nil {
+		t.Error("Expected an unknown personaSummary mode to be rejected")
+	}
+	if err := backend.InitializeConfig(LLMConfig{MarkovConfig: MarkovChainConfig{TrainingData: catLines}}); err != nil || backend.PersonaSummary() != "" {
+		t.Errorf("Expected no summary by default, got %q (%v)", backend.PersonaSummary(), err)
+	}
+}
@@ -0,0 +1,126 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var testPersonas = []PersonaProfile{
+	{
+		Name:        "sarcastic gamer",
+		Description: "dry humor, gaming references",
+		Traits:      map[string]float64{"sarcasm": 0.9, "playfulness": 0.6},
+		Examples:    []string{"Wow, a click. Groundbreaking.", "GG, I guess.", "Respawning my patience..."},
+		Weight:      3,
+	},
+	{
+		Name:        "cheerful companion",
+		Description: "warm and encouraging",
+		Traits:      map[string]float64{"cheerfulness": 0.9, "playfulness": 0.8},
+		Examples:    []string{"Yay, you're here!", "You've got this!", "Hugs!", "Best day ever!"},
+		Weight:      7,
+	},
+}
+
+func TestNewPersonaBlend(t *testing.T) {
+	blend, err := NewPersonaBlend(testPersonas)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	profiles := blend.Profiles()
+	if profiles[0].Name != "cheerful companion" || profiles[0].Weight != 0.7 || profiles[1].Weight != 0.3 {
+		t.Errorf("Expected normalized weights strongest first, got %+v", profiles)
+	}
+
+	traits := blend.Traits()
+	if diff := traits["playfulness"] - (0.7*0.8 + 0.3*0.6); diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected weighted playfulness, got %f", traits["playfulness"])
+	}
+	if diff := traits["sarcasm"] - 0.27; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected sarcasm weighted against the profile lacking it, got %f", traits["sarcasm"])
+	}
+
+	description := blend.Describe()
+	if !strings.Contains(description, "- 70% cheerful companion: warm and encouraging") ||
+		!strings.Contains(description, "- 30% sarcastic gamer: dry humor, gaming references") {
+		t.Errorf("Unexpected description:\n%s", description)
+	}
+}
+
+func TestNewPersonaBlendValidation(t *testing.T) {
+	invalid := [][]PersonaProfile{
+		nil,
+		{{Name: "", Weight: 1}},
+		{{Name: "a", Weight: 0}},
+		{{Name: "a", Weight: -1}},
+		{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}},
+	}
+	for _, profiles := range invalid {
+		if _, err := NewPersonaBlend(profiles); err == nil {
+			t.Errorf("Expected error for %+v", profiles)
+		}
+	}
+}
+
+func TestPersonaBlend_Examples(t *testing.T) {
+	blend, _ := NewPersonaBlend(testPersonas)
+
+	examples := blend.Examples(4)
+	if len(examples) != 4 {
+		t.Fatalf("Expected 4 examples, got %v", examples)
+	}
+	cheerful := 0
+	for _, example := range examples {
+		if strings.Contains(strings.Join(testPersonas[1].Examples, "|"), example) {
+			cheerful++
+		}
+	}
+	if cheerful != 3 {
+		t.Errorf("Expected 3 of 4 examples from the 70%% persona, got %d: %v", cheerful, examples)
+	}
+
+	if all := blend.Examples(100); len(all) != 7 {
+		t.Errorf("Expected every example when asking for more than exist, got %d", len(all))
+	}
+	if none := blend.Examples(0); len(none) != 0 {
+		t.Errorf("Expected no examples, got %v", none)
+	}
+}
+
+func TestPromptBuilder_PersonaBlend(t *testing.T) {
+	blend, _ := NewPersonaBlend(testPersonas)
+
+	pb := NewPromptBuilder()
+	pb.SetPersonaBlend(blend)
+	pb.AddContext(DialogContext{Trigger: "click", PersonalityTraits: map[string]float64{"shyness": 0.9}})
+	prompt := pb.Build()
+
+	if !strings.Contains(prompt, "whose personality is a blend of these personas:\n- 70% cheerful companion") {
+		t.Errorf("Expected blend description in prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Example lines in this blended voice:\n- Yay, you're here!") {
+		t.Errorf("Expected persona examples in prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "- Key traits: shyness (0.9), playfulness (0.7)") {
+		t.Errorf("Expected context traits merged over blended traits, got:\n%s", prompt)
+	}
+}
+
+func TestLLMBackend_Personas(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{Personas: testPersonas}, &scriptedTestModel{})
+	prompt := backend.buildPrompt(DialogContext{Trigger: "click"})
+
+	if !strings.Contains(prompt, "30% sarcastic gamer") {
+		t.Errorf("Expected blended personas in prompt, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "helpful AI assistant") {
+		t.Errorf("Expected no generic personality alongside personas, got:\n%s", prompt)
+	}
+
+	config := `{"modelPath": "/fake/path.gguf", "personas": [{"name": "grumpy", "weight": -1}]}`
+	if err := NewLLMBackend().Initialize(json.RawMessage(config)); err == nil {
+		t.Error("Expected error for negative persona weight")
+	}
+}
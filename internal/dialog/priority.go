@@ -0,0 +1,131 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Request priorities for DialogContext.Priority
+const (
+	PriorityInteractive = "interactive" // A user is waiting, e.g. a click or chat message (default)
+	PriorityBackground  = "background"  // Nobody is waiting, e.g. scheduled idle chatter
+)
+
+// generationQueue bounds concurrent generations; waiting interactive requests always get
+// the next free slot before background ones, first come first served within a priority
+// A nil queue is unlimited
+type generationQueue struct {
+	limit   int
+	active  int
+	waiting [2][]chan struct{} // Indexed by priorityLevel
+	mu      sync.Mutex
+}
+
+// newGenerationQueue returns a queue running up to limit generations, or nil for unlimited
+func newGenerationQueue(limit int) *generationQueue {
+	if limit <= 0 {
+		return nil
+	}
+	return &generationQueue{limit: limit}
+}
+
+// priorityLevel orders priorities; anything but PriorityBackground is interactive
+func priorityLevel(priority string) int {
+	if priority == PriorityBackground {
+		return 1
+	}
+	return 0
+}
+
+// acquire waits for a generation slot until ctx ends, returning the function that frees it
+func (q *generationQueue) acquire(ctx context.Context, priority string) (func(), error) {
+	if q == nil {
+		return func() {}, nil
+	}
+
+	q.mu.Lock()
+	if q.active < q.limit && len(q.waiting[0])+len(q.waiting[1]) == 0 {
+		q.active++
+		q.mu.Unlock()
+		return q.release, nil
+	}
+	level := priorityLevel(priority)
+	ready := make(chan struct{})
+	q.waiting[level] = append(q.waiting[level], ready)
+	q.mu.Unlock()
+
+	select {
+	case <-ready:
+		return q.release, nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	for i, waiter := range q.waiting[level] {
+		if waiter == ready {
+			q.waiting[level] = append(q.waiting[level][:i], q.waiting[level][i+1:]...)
+			q.mu.Unlock()
+			return nil, fmt.Errorf("no generation slot became free: %w", deadlineError(ctx))
+		}
+	}
+	q.mu.Unlock()
+
+	// The slot was handed over as the context ended; pass it on
+	q.release()
+	return nil, fmt.Errorf("no generation slot became free: %w", deadlineError(ctx))
+}
+
+// release hands the slot to the next waiter, interactive first, or frees it
+func (q *generationQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for level := range q.waiting {
+		if len(q.waiting[level]) > 0 {
+			next := q.waiting[level][0]
+			q.waiting[level] = q.waiting[level][1:]
+			close(next)
+			return
+		}
+	}
+	q.active--
+}
+
+// queued returns how many generations are waiting for a slot
+func (q *generationQueue) queued() int {
+	if q == nil {
+		return 0
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiting[0]) + len(q.waiting[1])
+}
+
+// applyGenerationQueue bounds concurrent generations to MaxConcurrentGenerations
+func (llm *LLMBackend) applyGenerationQueue(cfg LLMConfig) error {
+	if cfg.MaxConcurrentGenerations < 0 {
+		return configErrorf("maxConcurrentGenerations", "maxConcurrentGenerations must be non-negative, got %d", cfg.MaxConcurrentGenerations)
+	}
+	llm.queue = newGenerationQueue(cfg.MaxConcurrentGenerations)
+	return nil
+}
+
+// scheduleGeneration waits for a generation slot at the request's priority, then runs
+// generate with health accounting; time spent waiting counts against ctx
+func (llm *LLMBackend) scheduleGeneration(ctx context.Context, priority string, generate func() (generationResult, error)) (generationResult, error) {
+	start := time.Now()
+	release, err := llm.queue.acquire(ctx, priority)
+	if err != nil {
+		llm.observeLatency(time.Since(start), err)
+		return generationResult{}, err
+	}
+	defer release()
+
+	done := llm.health.begin()
+	generation, err := generate()
+	done(err)
+	llm.observeLatency(time.Since(start), err)
+	return generation, err
+}
@@ -0,0 +1,112 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitForQueued polls until n generations are waiting
+func waitForQueued(t *testing.T, q *generationQueue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for q.queued() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued generations, got %d", n, q.queued())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGenerationQueue_InteractiveBeforeBackground(t *testing.T) {
+	q := newGenerationQueue(1)
+	release, err := q.acquire(context.Background(), PriorityInteractive)
+	if err != nil {
+		t.Fatalf("Expected a free slot, got %v", err)
+	}
+
+	order := make(chan string, 3)
+	enqueue := func(name, priority string) {
+		go func() {
+			next, err := q.acquire(context.Background(), priority)
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", name, err)
+				return
+			}
+			order <- name
+			next()
+		}()
+	}
+	enqueue("idle-1", PriorityBackground)
+	waitForQueued(t, q, 1)
+	enqueue("idle-2", PriorityBackground)
+	waitForQueued(t, q, 2)
+	enqueue("click", "")
+	waitForQueued(t, q, 3)
+
+	release()
+	for _, want := range []string{"click", "idle-1", "idle-2"} {
+		if got := <-order; got != want {
+			t.Errorf("Expected %s next, got %s", want, got)
+		}
+	}
+}
+
+func TestGenerationQueue_WaitEndsWithContext(t *testing.T) {
+	q := newGenerationQueue(1)
+	release, _ := q.acquire(context.Background(), PriorityInteractive)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := q.acquire(ctx, PriorityBackground); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout while waiting for a slot, got %v", err)
+	}
+	if q.queued() != 0 {
+		t.Errorf("Expected the timed out request to leave the queue, got %d queued", q.queued())
+	}
+
+	release()
+	if next, err := q.acquire(context.Background(), PriorityInteractive); err != nil {
+		t.Errorf("Expected the slot to be free again, got %v", err)
+	} else {
+		next()
+	}
+}
+
+func TestGenerationQueue_NilIsUnlimited(t *testing.T) {
+	q := newGenerationQueue(0)
+	for i := 0; i < 3; i++ {
+		if _, err := q.acquire(context.Background(), PriorityBackground); err != nil {
+			t.Fatalf("Expected an unlimited queue never to wait, got %v", err)
+		}
+	}
+	if q.queued() != 0 {
+		t.Errorf("Expected nothing queued, got %d", q.queued())
+	}
+}
+
+func TestLLMBackend_MaxConcurrentGenerations(t *testing.T) {
+	if err := NewLLMBackend().Initialize([]byte(`{"modelPath": "m.gguf", "maxConcurrentGenerations": -1}`)); !errors.Is(err, ErrConfigInvalid) {
+		t.Errorf("Expected a negative limit to be rejected, got %v", err)
+	}
+
+	backend := newScriptedBackend(t, LLMConfig{MaxConcurrentGenerations: 1}, &scriptedTestModel{responses: []string{"Hello there!"}})
+	defer backend.Close()
+
+	release, _ := backend.queue.acquire(context.Background(), PriorityInteractive)
+	done := make(chan error, 1)
+	go func() {
+		_, err := backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user"})
+		done <- err
+	}()
+	waitForQueued(t, backend.queue, 1)
+	if health := backend.GetHealth(); health.Queued != 1 {
+		t.Errorf("Expected health to report 1 queued generation, got %d", health.Queued)
+	}
+
+	release()
+	if err := <-done; err != nil {
+		t.Errorf("Expected the queued request to run, got %v", err)
+	}
+}
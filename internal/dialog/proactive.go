@@ -0,0 +1,228 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Triggers used for unprompted dialog
+const (
+	ProactiveTriggerIdle     = "idle"     // Chatter after the user has been away
+	ProactiveTriggerCheckIn  = "check_in" // Periodic check-in while the user is away
+	ProactiveTriggerReminder = "reminder" // A scheduled reminder; the text is in TopicContext["reminder"]
+)
+
+// Proactive scheduler defaults
+const (
+	defaultIdleMinutes      = 10
+	defaultMinGapMinutes    = 5
+	defaultLowMoodThreshold = 30
+	reminderWindow          = time.Hour // Reminders missed by longer than this are skipped
+)
+
+// Reminder is a daily line the character says at a fixed local time
+type Reminder struct {
+	At   string `json:"at"`   // Local time as "15:04"
+	Text string `json:"text"` // What to remind the user about, e.g. "drink some water"
+}
+
+// ProactiveConfig sets when a ProactiveScheduler speaks up unprompted
+type ProactiveConfig struct {
+	IdleMinutes      float64    `json:"idleMinutes,omitempty"`      // Idle chatter once the user is away this long (default: 10)
+	CheckInMinutes   float64    `json:"checkInMinutes,omitempty"`   // Check in this often while the user stays away (0 = never)
+	LowMoodThreshold float64    `json:"lowMoodThreshold,omitempty"` // Below this mood, check-ins come twice as often (default: 30)
+	MinGapMinutes    float64    `json:"minGapMinutes,omitempty"`    // Shortest time between idle chatter and check-ins (default: 5)
+	Reminders        []Reminder `json:"reminders,omitempty"`
+}
+
+// ProactiveHandler receives each unprompted response with the context it was generated for
+type ProactiveHandler func(context DialogContext, response DialogResponse)
+
+// ProactiveScheduler lets a character start conversations: idle chatter, check-ins
+// and reminders are generated through the DialogManager and passed to a handler
+type ProactiveScheduler struct {
+	manager         *DialogManager
+	base            func() DialogContext // Current character state supplied by the host
+	handler         ProactiveHandler
+	idleAfter       time.Duration
+	checkInEvery    time.Duration
+	lowMood         float64
+	minGap          time.Duration
+	reminders       []scheduledReminder
+	lastInteraction time.Time
+	lastProactive   time.Time
+	idleSent        bool              // Idle chatter already sent since the last interaction
+	remindersSent   map[string]string // Reminder key to the date it was last sent
+	mu              sync.Mutex
+}
+
+// scheduledReminder is a Reminder with its parsed time of day
+type scheduledReminder struct {
+	Reminder
+	hour, minute int
+}
+
+// NewProactiveScheduler creates a scheduler, applying defaults for unset values
+// base supplies the character state (InteractionID, mood, stats) for each generation
+func NewProactiveScheduler(manager *DialogManager, config ProactiveConfig, base func() DialogContext, handler ProactiveHandler) (*ProactiveScheduler, error) {
+	if manager == nil || base == nil || handler == nil {
+		return nil, fmt.Errorf("proactive scheduler needs a manager, a base context and a handler")
+	}
+	if config.IdleMinutes < 0 || config.CheckInMinutes < 0 || config.MinGapMinutes < 0 {
+		return nil, fmt.Errorf("proactive intervals must be non-negative")
+	}
+	if config.LowMoodThreshold < 0 || config.LowMoodThreshold > 100 {
+		return nil, fmt.Errorf("lowMoodThreshold must be between 0 and 100, got %f", config.LowMoodThreshold)
+	}
+	if config.IdleMinutes == 0 {
+		config.IdleMinutes = defaultIdleMinutes
+	}
+	if config.MinGapMinutes == 0 {
+		config.MinGapMinutes = defaultMinGapMinutes
+	}
+	if config.LowMoodThreshold == 0 {
+		config.LowMoodThreshold = defaultLowMoodThreshold
+	}
+
+	reminders := make([]scheduledReminder, len(config.Reminders))
+	for i, reminder := range config.Reminders {
+		at, err := time.Parse("15:04", reminder.At)
+		if err != nil {
+			return nil, fmt.Errorf("reminder %d time %q must be HH:MM", i, reminder.At)
+		}
+		if reminder.Text == "" {
+			return nil, fmt.Errorf("reminder %d has no text", i)
+		}
+		reminders[i] = scheduledReminder{Reminder: reminder, hour: at.Hour(), minute: at.Minute()}
+	}
+
+	return &ProactiveScheduler{
+		manager:         manager,
+		base:            base,
+		handler:         handler,
+		idleAfter:       minutesDuration(config.IdleMinutes),
+		checkInEvery:    minutesDuration(config.CheckInMinutes),
+		lowMood:         config.LowMoodThreshold,
+		minGap:          minutesDuration(config.MinGapMinutes),
+		reminders:       reminders,
+		lastInteraction: currentTime(),
+		remindersSent:   make(map[string]string),
+	}, nil
+}
+
+// minutesDuration converts fractional minutes to a duration
+func minutesDuration(minutes float64) time.Duration {
+	return time.Duration(minutes * float64(time.Minute))
+}
+
+// RecordInteraction tells the scheduler the user just interacted, restarting the idle timers
+func (s *ProactiveScheduler) RecordInteraction() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastInteraction = currentTime()
+	s.idleSent = false
+}
+
+// Tick generates at most one due proactive response, passing it to the handler
+// Reminders come first, then check-ins, then idle chatter; it reports whether one was sent
+func (s *ProactiveScheduler) Tick() (bool, error) {
+	context, ok := s.due(currentTime())
+	if !ok {
+		return false, nil
+	}
+
+	response, err := s.manager.GenerateDialog(context)
+	if err != nil {
+		return false, err
+	}
+	s.handler(context, response)
+	return true, nil
+}
+
+// Run ticks every interval until ctx is cancelled; generation errors are skipped
+func (s *ProactiveScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Tick()
+		}
+	}
+}
+
+// due picks the proactive trigger due at now and records it as sent
+func (s *ProactiveScheduler) due(now time.Time) (DialogContext, bool) {
+	base := s.base()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	context := base
+	context.Timestamp = now
+	context.IdleDuration = now.Sub(s.lastInteraction)
+	if context.Priority == "" {
+		// Nobody is waiting, so a user's click should be answered first
+		context.Priority = PriorityBackground
+	}
+
+	if reminder, ok := s.dueReminder(now); ok {
+		topics := make(map[string]interface{}, len(base.TopicContext)+1)
+		for topic, value := range base.TopicContext {
+			topics[topic] = value
+		}
+		topics[ProactiveTriggerReminder] = reminder.Text
+		context.Trigger = ProactiveTriggerReminder
+		context.TopicContext = topics
+		s.lastProactive = now
+		return context, true
+	}
+
+	if !s.lastProactive.IsZero() && now.Sub(s.lastProactive) < s.minGap {
+		return DialogContext{}, false
+	}
+
+	if s.checkInEvery > 0 {
+		interval := s.checkInEvery
+		if base.CurrentMood > 0 && base.CurrentMood < s.lowMood {
+			interval /= 2
+		}
+		since := s.lastInteraction
+		if s.lastProactive.After(since) {
+			since = s.lastProactive
+		}
+		if now.Sub(s.lastInteraction) >= interval && now.Sub(since) >= interval {
+			context.Trigger = ProactiveTriggerCheckIn
+			s.lastProactive = now
+			return context, true
+		}
+	}
+
+	if !s.idleSent && now.Sub(s.lastInteraction) >= s.idleAfter {
+		context.Trigger = ProactiveTriggerIdle
+		s.idleSent = true
+		s.lastProactive = now
+		return context, true
+	}
+	return DialogContext{}, false
+}
+
+// dueReminder returns the first reminder whose time passed within the last hour
+// and that has not been sent today, marking it sent
+func (s *ProactiveScheduler) dueReminder(now time.Time) (Reminder, bool) {
+	day := now.Format("2006-01-02")
+	for _, reminder := range s.reminders {
+		at := time.Date(now.Year(), now.Month(), now.Day(), reminder.hour, reminder.minute, 0, 0, now.Location())
+		key := reminder.At + "\x00" + reminder.Text
+		if now.Before(at) || now.Sub(at) > reminderWindow || s.remindersSent[key] == day {
+			continue
+		}
+		s.remindersSent[key] = day
+		return reminder.Reminder, true
+	}
+	return Reminder{}, false
+}
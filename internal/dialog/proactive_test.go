@@ -0,0 +1,176 @@
+package dialog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// proactiveRecorder collects the triggers a scheduler emits
+type proactiveRecorder struct {
+	triggers []string
+	contexts []DialogContext
+	mu       sync.Mutex
+}
+
+func (r *proactiveRecorder) handle(context DialogContext, response DialogResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.triggers = append(r.triggers, context.Trigger)
+	r.contexts = append(r.contexts, context)
+}
+
+func (r *proactiveRecorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.triggers)
+}
+
+func newTestScheduler(t *testing.T, config ProactiveConfig, mood float64) (*ProactiveScheduler, *proactiveRecorder, *ManualClock) {
+	t.Helper()
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 3, 9, 0, 0, 0, time.Local))
+	SetClock(clock)
+
+	dm, _ := newRateLimitTestManager(t, "Hey!", "Still there?", "Hello?", "Hi!", "Psst!")
+	recorder := &proactiveRecorder{}
+	base := func() DialogContext {
+		return DialogContext{InteractionID: "pet", CurrentMood: mood}
+	}
+	scheduler, err := NewProactiveScheduler(dm, config, base, recorder.handle)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	return scheduler, recorder, clock
+}
+
+func TestNewProactiveSchedulerValidation(t *testing.T) {
+	dm := NewDialogManager(false)
+	base := func() DialogContext { return DialogContext{} }
+	handler := func(DialogContext, DialogResponse) {}
+
+	if _, err := NewProactiveScheduler(nil, ProactiveConfig{}, base, handler); err == nil {
+		t.Error("Expected error without a manager")
+	}
+	invalid := []ProactiveConfig{
+		{IdleMinutes: -1},
+		{LowMoodThreshold: 150},
+		{Reminders: []Reminder{{At: "25:00", Text: "water"}}},
+		{Reminders: []Reminder{{At: "12:00"}}},
+	}
+	for _, config := range invalid {
+		if _, err := NewProactiveScheduler(dm, config, base, handler); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+func TestProactiveScheduler_IdleChatterOncePerAbsence(t *testing.T) {
+	scheduler, recorder, clock := newTestScheduler(t, ProactiveConfig{IdleMinutes: 10}, 60)
+
+	clock.Advance(5 * time.Minute)
+	if sent, _ := scheduler.Tick(); sent {
+		t.Error("Expected nothing before the idle time")
+	}
+	clock.Advance(6 * time.Minute)
+	if sent, err := scheduler.Tick(); !sent || err != nil {
+		t.Fatalf("Expected idle chatter, got sent=%v err=%v", sent, err)
+	}
+	clock.Advance(30 * time.Minute)
+	if sent, _ := scheduler.Tick(); sent {
+		t.Error("Expected idle chatter only once per absence")
+	}
+
+	scheduler.RecordInteraction()
+	clock.Advance(11 * time.Minute)
+	scheduler.Tick()
+
+	if strings.Join(recorder.triggers, ",") != "idle,idle" {
+		t.Errorf("Expected idle chatter after each absence, got %v", recorder.triggers)
+	}
+	if recorder.contexts[0].IdleDuration != 11*time.Minute || recorder.contexts[0].InteractionID != "pet" {
+		t.Errorf("Expected the base context with idle duration, got %+v", recorder.contexts[0])
+	}
+	if recorder.contexts[0].Priority != PriorityBackground {
+		t.Errorf("Expected idle chatter to run at background priority, got %q", recorder.contexts[0].Priority)
+	}
+}
+
+func TestProactiveScheduler_CheckInsFollowMood(t *testing.T) {
+	config := ProactiveConfig{IdleMinutes: 600, CheckInMinutes: 60, MinGapMinutes: 1}
+
+	scheduler, recorder, clock := newTestScheduler(t, config, 60)
+	for i := 0; i < 12; i++ {
+		clock.Advance(10 * time.Minute)
+		scheduler.Tick()
+	}
+	if recorder.count() != 2 {
+		t.Errorf("Expected 2 hourly check-ins over 2 hours, got %v", recorder.triggers)
+	}
+
+	scheduler, recorder, clock = newTestScheduler(t, config, 20)
+	for i := 0; i < 12; i++ {
+		clock.Advance(10 * time.Minute)
+		scheduler.Tick()
+	}
+	if recorder.count() != 4 || recorder.triggers[0] != ProactiveTriggerCheckIn {
+		t.Errorf("Expected check-ins every 30 minutes when mood is low, got %v", recorder.triggers)
+	}
+}
+
+func TestProactiveScheduler_Reminders(t *testing.T) {
+	config := ProactiveConfig{IdleMinutes: 600, Reminders: []Reminder{{At: "09:30", Text: "drink some water"}, {At: "06:00", Text: "stretch"}}}
+	scheduler, recorder, clock := newTestScheduler(t, config, 60)
+
+	if sent, _ := scheduler.Tick(); sent {
+		t.Error("Expected reminders missed by over an hour to be skipped")
+	}
+	clock.Advance(31 * time.Minute)
+	if sent, _ := scheduler.Tick(); !sent {
+		t.Fatal("Expected the 09:30 reminder")
+	}
+	if sent, _ := scheduler.Tick(); sent {
+		t.Error("Expected each reminder once per day")
+	}
+
+	reminder := recorder.contexts[0]
+	if reminder.Trigger != ProactiveTriggerReminder || reminder.TopicContext[ProactiveTriggerReminder] != "drink some water" {
+		t.Errorf("Expected reminder context, got %+v", reminder)
+	}
+
+	pb := NewPromptBuilder()
+	pb.AddContext(reminder)
+	if prompt := pb.Build(); !strings.Contains(prompt, "- Remind the user: drink some water") {
+		t.Errorf("Expected reminder text in prompt, got:\n%s", prompt)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if sent, _ := scheduler.Tick(); !sent {
+		t.Error("Expected the reminder again the next day")
+	}
+}
+
+func TestProactiveScheduler_Run(t *testing.T) {
+	scheduler, recorder, clock := newTestScheduler(t, ProactiveConfig{IdleMinutes: 1}, 60)
+	clock.Advance(2 * time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for recorder.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if recorder.count() != 1 {
+		t.Errorf("Expected one idle line from the run loop, got %v", recorder.triggers)
+	}
+}
@@ -1,12 +1,24 @@
 package dialog
 
 import (
+	"bytes"
+	"cmp"
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 )
 
+// Conservative prompt defaults for small models
+const (
+	defaultPromptTokens  = 1500 // Prompt budget in estimated tokens
+	defaultPromptHistory = 5    // Most recent exchanges included
+)
+
 // PromptBuilder constructs prompts for LLM inference from dialog context
 // Designed to create effective prompts for small models with limited context windows
 type PromptBuilder struct {
@@ -16,12 +28,20 @@ type PromptBuilder struct {
 	context      DialogContext
 	template     string
 	maxTokens    int
+	maxHistory   int      // Exchanges eligible for the prompt
+	compress     bool     // Summarize history before dropping it when over budget
+	avoid        []string // Rejected replies the response must not repeat
+	format       string   // Guideline describing a required reply format
+	events       []string // Occasions happening today
+	persona      *PersonaBlend
+	clock        Clock // Ages history timestamps (nil = the package clock)
 }
 
 // NewPromptBuilder creates a new prompt builder with default settings
 func NewPromptBuilder() *PromptBuilder {
 	return &PromptBuilder{
-		maxTokens: 1500, // Conservative limit for small models
+		maxTokens:  defaultPromptTokens,
+		maxHistory: defaultPromptHistory,
 	}
 }
 
@@ -55,45 +75,245 @@ func (pb *PromptBuilder) SetMaxTokens(maxTokens int) {
 	pb.maxTokens = maxTokens
 }
 
+// SetMaxHistory sets how many of the most recent added exchanges the prompt may include
+func (pb *PromptBuilder) SetMaxHistory(maxHistory int) {
+	if maxHistory > 0 {
+		pb.maxHistory = maxHistory
+	}
+}
+
+// AvoidResponse instructs the model not to repeat a reply the user rejected
+// The instruction is part of the always-kept prompt tail
+func (pb *PromptBuilder) AvoidResponse(response string) {
+	if response = strings.TrimSpace(response); response != "" {
+		pb.avoid = append(pb.avoid, response)
+	}
+}
+
+// SetResponseFormat adds a guideline describing the required reply format, such as JSON
+func (pb *PromptBuilder) SetResponseFormat(instruction string) {
+	pb.format = strings.TrimSpace(instruction)
+}
+
+// AddEvent mentions an occasion happening today, such as a birthday or holiday
+// Events are part of the always-kept prompt tail
+func (pb *PromptBuilder) AddEvent(description string) {
+	if description = strings.TrimSpace(description); description != "" {
+		pb.events = append(pb.events, description)
+	}
+}
+
+// SetClock sets the clock history timestamps are described against (nil = the package clock)
+func (pb *PromptBuilder) SetClock(clock Clock) {
+	pb.clock = clock
+}
+
+// SetHistoryCompression makes Build summarize conversation history into compact
+// lines when the prompt is over budget, before dropping any exchanges
+func (pb *PromptBuilder) SetHistoryCompression(enabled bool) {
+	pb.compress = enabled
+}
+
 // Build constructs the final prompt using default structure
+// When the prompt exceeds the token budget, history is first summarized if compression
+// is enabled, then whole sections are dropped by priority (oldest history first, then
+// personality traits, then character state) and the personality is shortened last;
+// the current situation and response instructions are always kept
 func (pb *PromptBuilder) Build() string {
-	var prompt strings.Builder
+	budget := pb.maxTokens * 4 // Rough token estimation: 1 token ≈ 4 characters
+	scratch := promptScratchPool.Get().(*promptScratch)
+	defer scratch.release()
+
+	// Each section is rendered once; dropping sections only changes which are copied out
+	sections := promptSections{history: pb.historyWindow(), traits: true, state: true}
+	scratch.render(pb, sections.history)
+	for scratch.length(sections) > budget {
+		switch {
+		case pb.compress && !sections.compressed && pb.compressionHelps(sections.history):
+			sections.compressed = true
+			scratch.compressed = pb.formatCompressedHistory(sections.history)
+		case len(sections.history) > 0:
+			sections.history = sections.history[1:]
+			if sections.compressed {
+				scratch.compressed = pb.formatCompressedHistory(sections.history)
+			}
+		case sections.traits:
+			sections.traits = false
+		case sections.state:
+			sections.state = false
+		default:
+			tail := scratch.tail.String()
+			return pb.truncateHeader(scratch.header.String(), budget-len(tail)) + tail
+		}
+	}
+	return scratch.assemble(sections)
+}
 
-	// Add system prompt if available
-	if pb.systemPrompt != "" {
-		prompt.WriteString(pb.systemPrompt)
-		prompt.WriteString("\n\n")
+// promptSections records which optional sections survive token budgeting
+type promptSections struct {
+	history    []ConversationExchange // Exchanges still included, oldest first
+	compressed bool                   // Summarize history instead of listing each exchange
+	traits     bool                   // Include the key traits line
+	state      bool                   // Include the character state section
+}
+
+// historyHeading opens the conversation history section
+const historyHeading = "Recent conversation:\n"
+
+// promptWriter is what prompt sections are rendered into: a strings.Builder for a
+// single section, or a pooled bytes.Buffer while Build measures them
+type promptWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
+// promptScratch holds the rendered sections Build measures against the budget before
+// copying the surviving ones into the prompt
+type promptScratch struct {
+	header     bytes.Buffer
+	state      bytes.Buffer // Character state with personality traits
+	plainState bytes.Buffer // Character state without them
+	history    bytes.Buffer // One line per exchange, oldest first
+	lineEnds   []int        // Offset in history where each exchange's line ends
+	compressed string       // Summarized history, once Build switches to it
+	tail       bytes.Buffer // Current situation and response instructions
+}
+
+// promptScratchPool reuses scratch buffers across requests, so sustained traffic only
+// allocates the finished prompt and the values formatted into it
+var promptScratchPool = sync.Pool{New: func() interface{} { return new(promptScratch) }}
+
+// maxPooledPromptBytes keeps unusually large scratch buffers from being pooled
+const maxPooledPromptBytes = 64 * 1024
+
+// render writes every section for exchanges
+func (s *promptScratch) render(pb *PromptBuilder, exchanges []ConversationExchange) {
+	pb.writeHeader(&s.header)
+	pb.writeCharacterState(&s.state, true)
+	pb.writeCharacterState(&s.plainState, false)
+	for _, exchange := range exchanges {
+		pb.writeExchangeLine(&s.history, exchange)
+		s.lineEnds = append(s.lineEnds, s.history.Len())
 	}
+	pb.writeCurrentSituation(&s.tail)
+	pb.writeResponseInstructions(&s.tail)
+}
 
-	// Add character personality
-	if pb.personality != "" {
-		prompt.WriteString(fmt.Sprintf("You are a desktop pet character with the following personality: %s\n", pb.personality))
-	} else {
-		prompt.WriteString("You are a friendly desktop pet character.\n")
+// historyLines returns the lines of the newest kept exchanges
+func (s *promptScratch) historyLines(kept int) []byte {
+	if kept == 0 {
+		return nil
+	}
+	start := 0
+	if dropped := len(s.lineEnds) - kept; dropped > 0 {
+		start = s.lineEnds[dropped-1]
 	}
+	return s.history.Bytes()[start:]
+}
 
-	// Add character state context
-	prompt.WriteString(pb.buildCharacterState())
+// stateFor returns the character state section with or without traits
+func (s *promptScratch) stateFor(traits bool) *bytes.Buffer {
+	if traits {
+		return &s.state
+	}
+	return &s.plainState
+}
 
-	// Add conversation history if available
-	if len(pb.history) > 0 {
-		prompt.WriteString(pb.buildConversationHistory())
+// length returns the size of the prompt assembled from sections
+func (s *promptScratch) length(sections promptSections) int {
+	n := s.header.Len() + s.tail.Len()
+	if sections.state {
+		n += s.stateFor(sections.traits).Len()
+	}
+	if sections.compressed {
+		n += len(s.compressed)
+	} else if lines := s.historyLines(len(sections.history)); len(lines) > 0 {
+		n += len(historyHeading) + len(lines) + 1
 	}
+	return n
+}
 
-	// Add current situation
-	prompt.WriteString(pb.buildCurrentSituation())
+// assemble joins the surviving sections in their fixed order
+func (s *promptScratch) assemble(sections promptSections) string {
+	var prompt strings.Builder
+	prompt.Grow(s.length(sections))
+	prompt.Write(s.header.Bytes())
+	if sections.state {
+		prompt.Write(s.stateFor(sections.traits).Bytes())
+	}
+	if sections.compressed {
+		prompt.WriteString(s.compressed)
+	} else if lines := s.historyLines(len(sections.history)); len(lines) > 0 {
+		prompt.WriteString(historyHeading)
+		prompt.Write(lines)
+		prompt.WriteByte('\n')
+	}
+	prompt.Write(s.tail.Bytes())
+	return prompt.String()
+}
 
-	// Add response instructions
-	prompt.WriteString(pb.buildResponseInstructions())
+// release returns the scratch buffers to the pool
+func (s *promptScratch) release() {
+	size := s.header.Cap() + s.state.Cap() + s.plainState.Cap() + s.history.Cap() + s.tail.Cap()
+	if size > maxPooledPromptBytes {
+		return
+	}
+	s.header.Reset()
+	s.state.Reset()
+	s.plainState.Reset()
+	s.history.Reset()
+	s.tail.Reset()
+	s.lineEnds = s.lineEnds[:0]
+	s.compressed = ""
+	promptScratchPool.Put(s)
+}
 
-	result := prompt.String()
+// buildHeader combines the system prompt and personality description
+func (pb *PromptBuilder) buildHeader() string {
+	var header strings.Builder
+	pb.writeHeader(&header)
+	return header.String()
+}
 
-	// Truncate if too long (rough token estimation: 1 token ≈ 4 characters)
-	if len(result) > pb.maxTokens*4 {
-		result = pb.safelyTruncatePrompt(result, pb.maxTokens*4)
+// writeHeader writes the system prompt, personality and facts about the user
+func (pb *PromptBuilder) writeHeader(header promptWriter) {
+	// Add system prompt if available
+	if pb.systemPrompt != "" {
+		header.WriteString(pb.systemPrompt)
+		header.WriteString("\n\n")
 	}
 
-	return result
+	// Add character personality
+	if pb.persona != nil {
+		header.WriteString(pb.personaHeader())
+		if pb.personality != "" {
+			header.WriteString(pb.personality)
+			header.WriteString("\n")
+		}
+	} else if pb.personality != "" {
+		header.WriteString("You are a desktop pet character with the following personality: ")
+		header.WriteString(pb.personality)
+		header.WriteString("\n")
+	} else {
+		header.WriteString("You are a friendly desktop pet character.\n")
+	}
+
+	// Add what the character remembers about the user
+	if len(pb.context.UserFacts) > 0 {
+		header.WriteString("\n")
+		header.WriteString(describeUserFacts(pb.context.UserFacts))
+	}
+}
+
+// truncateHeader shortens the header to fit the space left after the preserved tail
+func (pb *PromptBuilder) truncateHeader(header string, room int) string {
+	if room <= 1 {
+		return ""
+	}
+	if len(header) <= room {
+		return header
+	}
+	return strings.TrimRight(pb.safelyTruncatePrompt(header, room-1), "\n") + "\n"
 }
 
 // BuildFromTemplate constructs the prompt using a custom template
@@ -111,6 +331,7 @@ func (pb *PromptBuilder) BuildFromTemplate(template string) string {
 		"{trigger}":              pb.context.Trigger,
 		"{mood}":                 fmt.Sprintf("%.1f", pb.context.CurrentMood),
 		"{timeOfDay}":            pb.context.TimeOfDay,
+		"{dayOfWeek}":            pb.context.DayOfWeek,
 		"{relationshipLevel}":    pb.context.RelationshipLevel,
 	}
 
@@ -123,133 +344,306 @@ func (pb *PromptBuilder) BuildFromTemplate(template string) string {
 
 // buildCharacterState creates a description of the character's current state
 func (pb *PromptBuilder) buildCharacterState() string {
+	return pb.characterState(true)
+}
+
+// characterState describes the character's state, optionally without personality traits
+func (pb *PromptBuilder) characterState(includeTraits bool) string {
 	var state strings.Builder
+	pb.writeCharacterState(&state, includeTraits)
+	return state.String()
+}
 
+// writeCharacterState writes the character state section
+func (pb *PromptBuilder) writeCharacterState(state promptWriter, includeTraits bool) {
 	state.WriteString("Current character state:\n")
 
-	pb.addMoodInfo(&state)
-	pb.addTimeInfo(&state)
-	pb.addRelationshipInfo(&state)
-	pb.addPersonalityTraits(&state)
-	pb.addAnimationInfo(&state)
+	pb.addMoodInfo(state)
+	pb.addTimeInfo(state)
+	pb.addRelationshipInfo(state)
+	if includeTraits {
+		pb.addPersonalityTraits(state)
+	}
+	pb.addAnimationInfo(state)
 
 	state.WriteString("\n")
-	return state.String()
 }
 
 // addMoodInfo adds mood information to the character state
-func (pb *PromptBuilder) addMoodInfo(state *strings.Builder) {
+func (pb *PromptBuilder) addMoodInfo(state promptWriter) {
 	if pb.context.CurrentMood > 0 {
 		moodDesc := pb.describeMood(pb.context.CurrentMood)
-		state.WriteString(fmt.Sprintf("- Mood: %s (%.1f/100)\n", moodDesc, pb.context.CurrentMood))
+		trend := ""
+		switch {
+		case pb.context.MoodChange >= moodTrendThreshold:
+			trend = ", just improved"
+		case pb.context.MoodChange <= -moodTrendThreshold:
+			trend = ", just worsened"
+		}
+		fmt.Fprintf(state, "- Mood: %s (%.1f/100%s)\n", moodDesc, pb.context.CurrentMood, trend)
 	}
 }
 
 // addTimeInfo adds time context to the character state
-func (pb *PromptBuilder) addTimeInfo(state *strings.Builder) {
+func (pb *PromptBuilder) addTimeInfo(state promptWriter) {
 	if pb.context.TimeOfDay != "" {
-		state.WriteString(fmt.Sprintf("- Time of day: %s\n", pb.context.TimeOfDay))
+		fmt.Fprintf(state, "- Time of day: %s\n", pb.context.TimeOfDay)
+	}
+	if pb.context.DayOfWeek != "" {
+		dayType := "weekday"
+		if pb.context.IsWeekend {
+			dayType = "weekend"
+		}
+		fmt.Fprintf(state, "- Day: %s (%s)\n", pb.context.DayOfWeek, dayType)
 	}
 }
 
 // addRelationshipInfo adds relationship context to the character state
-func (pb *PromptBuilder) addRelationshipInfo(state *strings.Builder) {
+func (pb *PromptBuilder) addRelationshipInfo(state promptWriter) {
 	if pb.context.RelationshipLevel != "" {
-		state.WriteString(fmt.Sprintf("- Relationship level: %s\n", pb.context.RelationshipLevel))
+		fmt.Fprintf(state, "- Relationship level: %s\n", pb.context.RelationshipLevel)
 	}
 }
 
 // addPersonalityTraits adds key personality traits to the character state
-func (pb *PromptBuilder) addPersonalityTraits(state *strings.Builder) {
-	if len(pb.context.PersonalityTraits) > 0 {
-		state.WriteString("- Key traits: ")
-		traits := pb.extractTopTraits()
-		state.WriteString(strings.Join(traits, ", "))
-		state.WriteString("\n")
+func (pb *PromptBuilder) addPersonalityTraits(state promptWriter) {
+	traits := pb.personalityTraits()
+	top := pb.extractTopTraits(traits)
+	if len(top) == 0 {
+		return
+	}
+	state.WriteString("- Key traits: ")
+	for i, trait := range top {
+		if i > 0 {
+			state.WriteString(", ")
+		}
+		fmt.Fprintf(state, "%s (%.1f)", trait, traits[trait])
 	}
+	state.WriteString("\n")
 }
 
-// extractTopTraits extracts the top 3 personality traits above threshold
-func (pb *PromptBuilder) extractTopTraits() []string {
-	traits := make([]string, 0, 3)
-	for trait, value := range pb.context.PersonalityTraits {
+// extractTopTraits returns the names of the top 3 personality traits above threshold
+// Traits are ordered by strength, then name, so the same context always yields the same prompt
+func (pb *PromptBuilder) extractTopTraits(traits map[string]float64) []string {
+	var strong []string
+	for trait, value := range traits {
 		if value > 0.6 { // Only include strong traits
-			traits = append(traits, fmt.Sprintf("%s (%.1f)", trait, value))
-			if len(traits) >= 3 {
-				break
-			}
+			strong = append(strong, trait)
 		}
 	}
-	return traits
+	slices.SortFunc(strong, func(a, b string) int {
+		if c := cmp.Compare(traits[b], traits[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+	return strong[:min(len(strong), 3)]
 }
 
 // addAnimationInfo adds current animation state to the character state
-func (pb *PromptBuilder) addAnimationInfo(state *strings.Builder) {
+func (pb *PromptBuilder) addAnimationInfo(state promptWriter) {
 	if pb.context.CurrentAnimation != "" {
-		state.WriteString(fmt.Sprintf("- Current animation: %s\n", pb.context.CurrentAnimation))
+		fmt.Fprintf(state, "- Current animation: %s\n", pb.context.CurrentAnimation)
 	}
 }
 
 // buildConversationHistory creates a summary of recent conversation
 func (pb *PromptBuilder) buildConversationHistory() string {
-	if len(pb.history) == 0 {
+	return pb.formatConversationHistory(pb.historyWindow())
+}
+
+// historyWindow returns the exchanges eligible for the prompt: the most recent up to maxHistory
+func (pb *PromptBuilder) historyWindow() []ConversationExchange {
+	return pb.history[max(0, len(pb.history)-pb.maxHistory):]
+}
+
+// formatConversationHistory writes one line per exchange, or nothing when there are none
+func (pb *PromptBuilder) formatConversationHistory(exchanges []ConversationExchange) string {
+	if len(exchanges) == 0 {
 		return ""
 	}
 
 	var history strings.Builder
-	history.WriteString("Recent conversation:\n")
+	history.WriteString(historyHeading)
+	for _, exchange := range exchanges {
+		pb.writeExchangeLine(&history, exchange)
+	}
+	history.WriteString("\n")
+	return history.String()
+}
 
-	// Include up to 5 most recent exchanges for context
-	start := 0
-	if len(pb.history) > 5 {
-		start = len(pb.history) - 5
+// writeExchangeLine writes the history line for one exchange
+// Called for every remembered exchange on every request, so it avoids fmt
+func (pb *PromptBuilder) writeExchangeLine(history promptWriter, exchange ConversationExchange) {
+	history.WriteString("- ")
+	history.WriteString(pb.formatTimeAgo(exchange.Timestamp))
+	switch {
+	case exchange.Speaker != "":
+		history.WriteString(": ")
+		history.WriteString(pb.speakerName(exchange.Speaker))
+		history.WriteString(" said: \"")
+	case exchange.UserMessage != "":
+		history.WriteString(": User said: \"")
+		history.WriteString(exchange.UserMessage)
+		history.WriteString("\" → You said: \"")
+	default:
+		history.WriteString(" (")
+		history.WriteString(exchange.Trigger)
+		history.WriteString("): User ")
+		history.WriteString(exchange.Trigger)
+		history.WriteString(" → You said: \"")
 	}
+	history.WriteString(exchange.Response)
+	history.WriteString("\"\n")
+}
 
-	for i := start; i < len(pb.history); i++ {
-		exchange := pb.history[i]
-		timeAgo := pb.formatTimeAgo(exchange.Timestamp)
-		history.WriteString(fmt.Sprintf("- %s (%s): User %s → You said: \"%s\"\n",
-			timeAgo, exchange.Trigger, exchange.Trigger, exchange.Response))
+// formatCompressedHistory summarizes runs of repeated triggers in one line each,
+// e.g. "User fed you ×3; you replied casually", keeping only the latest reply's opening
+func (pb *PromptBuilder) formatCompressedHistory(exchanges []ConversationExchange) string {
+	if len(exchanges) == 0 {
+		return ""
+	}
+
+	var history strings.Builder
+	history.WriteString("Recent conversation (summarized):\n")
+
+	for start := 0; start < len(exchanges); {
+		end := start + 1
+		for end < len(exchanges) && exchanges[start].UserMessage == "" && exchanges[end].UserMessage == "" &&
+			exchanges[end].Trigger == exchanges[start].Trigger && exchanges[end].Speaker == exchanges[start].Speaker {
+			end++
+		}
+		latest := exchanges[end-1]
+
+		if latest.Speaker != "" {
+			fmt.Fprintf(&history, "- %s: %s said \"%s\"\n", pb.formatTimeAgo(latest.Timestamp), pb.speakerName(latest.Speaker), clipWords(latest.Response, 6))
+			start = end
+			continue
+		}
+
+		if latest.UserMessage != "" {
+			fmt.Fprintf(&history, "- %s: User said \"%s\"", pb.formatTimeAgo(latest.Timestamp), clipWords(latest.UserMessage, 6))
+		} else {
+			fmt.Fprintf(&history, "- %s: User %s", pb.formatTimeAgo(latest.Timestamp), pb.describeTrigger(latest.Trigger))
+		}
+		if count := end - start; count > 1 {
+			fmt.Fprintf(&history, " ×%d", count)
+		}
+		fmt.Fprintf(&history, "; you replied %s (last: \"%s\")\n", describeReplyStyle(latest.ResponseType), clipWords(latest.Response, 6))
+		start = end
 	}
 
 	history.WriteString("\n")
 	return history.String()
 }
 
+// compressionHelps reports whether summarizing the exchanges makes the history shorter
+func (pb *PromptBuilder) compressionHelps(exchanges []ConversationExchange) bool {
+	return len(pb.formatCompressedHistory(exchanges)) < len(pb.formatConversationHistory(exchanges))
+}
+
+// describeReplyStyle turns a response classification into a short phrase for summaries
+func describeReplyStyle(responseType string) string {
+	switch responseType {
+	case "romantic":
+		return "affectionately"
+	case "helpful":
+		return "helpfully"
+	case "inquisitive":
+		return "with a question"
+	case "fallback":
+		return "briefly"
+	default:
+		return "casually"
+	}
+}
+
+// clipWords keeps the first n words of text, marking omitted words with an ellipsis
+func clipWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return strings.Join(words, " ")
+	}
+	return strings.Join(words[:n], " ") + "..."
+}
+
 // buildCurrentSituation describes what just happened to trigger this response
 func (pb *PromptBuilder) buildCurrentSituation() string {
 	var situation strings.Builder
+	pb.writeCurrentSituation(&situation)
+	return situation.String()
+}
 
+// writeCurrentSituation writes the current situation section
+func (pb *PromptBuilder) writeCurrentSituation(situation promptWriter) {
 	situation.WriteString("Current situation:\n")
-	situation.WriteString(fmt.Sprintf("- The user just performed: %s\n", pb.describeTrigger(pb.context.Trigger)))
+	if chat := pb.buildCharacterChatInstructions(); chat != "" {
+		situation.WriteString(chat)
+	} else if voice := pb.buildVoiceSituation(); voice != "" {
+		situation.WriteString(voice)
+	} else {
+		message := pb.buildMessageSituation()
+		if pb.context.Trigger != "" || message == "" {
+			fmt.Fprintf(situation, "- The user just performed: %s\n", pb.describeTrigger(pb.context.Trigger))
+		}
+		situation.WriteString(message)
+	}
 
 	// Add turn information if this is part of an ongoing conversation
 	if pb.context.ConversationTurn > 1 {
-		situation.WriteString(fmt.Sprintf("- This is turn %d of the current conversation\n", pb.context.ConversationTurn))
+		fmt.Fprintf(situation, "- This is turn %d of the current conversation\n", pb.context.ConversationTurn)
+	}
+
+	situation.WriteString(pb.buildStarterInstructions())
+	if reminder, ok := pb.context.TopicContext[ProactiveTriggerReminder].(string); ok && pb.context.Trigger == ProactiveTriggerReminder {
+		fmt.Fprintf(situation, "- Remind the user: %s\n", reminder)
+	}
+
+	for _, event := range pb.events {
+		fmt.Fprintf(situation, "- Today is a special day: %s\n", event)
+	}
+
+	for _, event := range pb.context.WorldEvents {
+		fmt.Fprintf(situation, "- Meanwhile, %s %s (%s)\n", event.Character, event.Description, pb.formatTimeAgo(event.Timestamp))
+	}
+
+	if pb.context.IdleDuration >= minDescribedIdle {
+		fmt.Fprintf(situation, "- The user's previous interaction was %s ago\n", describeDuration(pb.context.IdleDuration))
 	}
 
 	// Add last response context if available
 	if pb.context.LastResponse != "" {
-		situation.WriteString(fmt.Sprintf("- Your last response was: \"%s\"\n", pb.context.LastResponse))
+		fmt.Fprintf(situation, "- Your last response was: \"%s\"\n", pb.context.LastResponse)
 	}
 
 	situation.WriteString("\n")
-	return situation.String()
 }
 
 // buildResponseInstructions provides guidance for generating appropriate responses
 func (pb *PromptBuilder) buildResponseInstructions() string {
-	instructions := `Response guidelines:
+	var instructions strings.Builder
+	pb.writeResponseInstructions(&instructions)
+	return instructions.String()
+}
+
+// writeResponseInstructions writes the response guidelines section
+func (pb *PromptBuilder) writeResponseInstructions(instructions promptWriter) {
+	instructions.WriteString(`Response guidelines:
 - Keep responses short and natural (1-2 sentences maximum)
 - Match your personality and current mood
 - Respond appropriately to the user's action
 - Use simple, conversational language
 - Include an emoji if it fits naturally
 - Stay in character as a desktop pet
-
-Your response:`
-
-	return instructions
+`)
+	if pb.format != "" {
+		instructions.WriteString(pb.format)
+		instructions.WriteString("\n")
+	}
+	for _, avoided := range pb.avoid {
+		fmt.Fprintf(instructions, "- The user disliked the reply \"%s\"; say something clearly different\n", avoided)
+	}
+	instructions.WriteString("\nYour response:")
 }
 
 // describeMood converts numeric mood to descriptive text
@@ -270,53 +664,35 @@ func (pb *PromptBuilder) describeMood(mood float64) string {
 
 // describeTrigger converts trigger codes to natural language
 func (pb *PromptBuilder) describeTrigger(trigger string) string {
-	triggers := map[string]string{
-		"click":      "clicked on you",
-		"rightclick": "right-clicked on you",
-		"hover":      "hovered over you",
-		"feed":       "fed you",
-		"pet":        "petted you",
-		"play":       "wants to play",
-		"talk":       "wants to talk",
-		"gift":       "gave you a gift",
-		"compliment": "complimented you",
-		"ignore":     "ignored you",
-		"idle":       "you've been idle",
-		"timer":      "time passed",
-	}
-
-	if description, exists := triggers[trigger]; exists {
-		return description
-	}
-	return trigger // Fallback to original trigger name
+	return triggerDescription(trigger)
 }
 
 // formatTimeAgo converts timestamp to relative time description
 func (pb *PromptBuilder) formatTimeAgo(timestamp time.Time) string {
-	duration := time.Since(timestamp)
+	now := currentTime()
+	if pb.clock != nil {
+		now = pb.clock.Now()
+	}
+	duration := now.Sub(timestamp)
 
 	switch {
 	case duration < time.Minute:
 		return "just now"
 	case duration < time.Hour:
-		minutes := int(duration.Minutes())
-		if minutes == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", minutes)
+		return pluralAgo(int(duration.Minutes()), "minute")
 	case duration < 24*time.Hour:
-		hours := int(duration.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
+		return pluralAgo(int(duration.Hours()), "hour")
 	default:
-		days := int(duration.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
+		return pluralAgo(int(duration.Hours()/24), "day")
+	}
+}
+
+// pluralAgo formats "1 minute ago" or "5 minutes ago"
+func pluralAgo(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit + " ago"
 	}
+	return strconv.Itoa(n) + " " + unit + "s ago"
 }
 
 // EstimateTokenCount provides a rough estimate of token count for the prompt
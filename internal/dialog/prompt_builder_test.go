@@ -490,3 +490,191 @@ func TestDDS_test_bug7_unsafe_truncation_breaks_prompt_structure(t *testing.T) {
 		}
 	}
 }
+
+// budgetTestBuilder creates a builder with history, traits and state for truncation tests
+func budgetTestBuilder() *PromptBuilder {
+	pb := NewPromptBuilder()
+	pb.AddPersonality("cheerful and curious")
+
+	var history []ConversationExchange
+	for i := 1; i <= 5; i++ {
+		history = append(history, ConversationExchange{
+			Timestamp: time.Now(),
+			Trigger:   "click",
+			Response:  fmt.Sprintf("response number %d with some padding text", i),
+		})
+	}
+	pb.AddHistory(history)
+	pb.AddContext(DialogContext{
+		Trigger:           "feed",
+		CurrentMood:       70,
+		RelationshipLevel: "friend",
+		PersonalityTraits: map[string]float64{"cheerful": 0.9},
+	})
+	return pb
+}
+
+func TestPromptBuilder_BudgetDropsOldestHistoryFirst(t *testing.T) {
+	pb := budgetTestBuilder()
+	full := pb.Build()
+
+	// Leave room for everything except roughly two history lines
+	pb.SetMaxTokens((len(full) - 100) / 4)
+	prompt := pb.Build()
+
+	if strings.Contains(prompt, "response number 1 ") {
+		t.Error("Expected the oldest exchange to be dropped first")
+	}
+	if !strings.Contains(prompt, "response number 5 ") {
+		t.Error("Expected the newest exchange to be kept")
+	}
+	if !strings.Contains(prompt, "Key traits:") || !strings.Contains(prompt, "Relationship level:") {
+		t.Error("Expected traits and state to be kept while history can be dropped")
+	}
+	if !strings.HasSuffix(prompt, "Your response:") {
+		t.Errorf("Expected the instruction tail to be preserved, got %q", prompt[len(prompt)-30:])
+	}
+}
+
+func TestPromptBuilder_BudgetDropsTraitsThenState(t *testing.T) {
+	pb := budgetTestBuilder()
+	tail := pb.buildCurrentSituation() + pb.buildResponseInstructions()
+	header := pb.buildHeader()
+
+	// Room for the header, state without traits, and tail but no history
+	withoutTraits := len(header) + len(pb.characterState(false)) + len(tail)
+	pb.SetMaxTokens((withoutTraits + 3) / 4)
+	prompt := pb.Build()
+	if strings.Contains(prompt, "Recent conversation:") || strings.Contains(prompt, "Key traits:") {
+		t.Error("Expected history and traits to be dropped")
+	}
+	if !strings.Contains(prompt, "Current character state:") {
+		t.Error("Expected character state to be kept once traits are dropped")
+	}
+
+	pb.SetMaxTokens((len(header) + len(tail)) / 4)
+	prompt = pb.Build()
+	if strings.Contains(prompt, "Current character state:") {
+		t.Error("Expected character state to be dropped after traits")
+	}
+	if !strings.HasSuffix(prompt, "Your response:") || !strings.Contains(prompt, "Current situation:") {
+		t.Error("Expected situation and instructions to be preserved")
+	}
+}
+
+func TestPromptBuilder_BudgetAlwaysKeepsInstructionTail(t *testing.T) {
+	pb := budgetTestBuilder()
+	pb.AddPersonality(strings.Repeat("an extremely verbose personality description ", 40))
+	pb.SetMaxTokens(10)
+
+	prompt := pb.Build()
+	if !strings.HasSuffix(prompt, "Your response:") {
+		t.Errorf("Expected the instruction tail to survive a tiny budget, got %q", prompt)
+	}
+	if !strings.Contains(prompt, "- The user just performed: fed you") {
+		t.Error("Expected the current situation to survive a tiny budget")
+	}
+	if strings.Contains(prompt, "verbose personality") {
+		t.Error("Expected the personality to be cut when nothing else fits")
+	}
+}
+
+func TestPromptBuilder_CompressedHistorySummarizesRepeats(t *testing.T) {
+	pb := NewPromptBuilder()
+	now := time.Now()
+	history := []ConversationExchange{
+		{Timestamp: now, Trigger: "click", Response: "Oh hi there!", ResponseType: "casual"},
+		{Timestamp: now, Trigger: "feed", Response: "Thanks for the food!", ResponseType: "casual"},
+		{Timestamp: now, Trigger: "feed", Response: "Yum, more please!", ResponseType: "casual"},
+		{Timestamp: now, Trigger: "feed", Response: "I could eat all day long, you spoil me so much", ResponseType: "romantic"},
+	}
+
+	summary := pb.formatCompressedHistory(history)
+	expectedLines := []string{
+		"- just now: User clicked on you; you replied casually (last: \"Oh hi there!\")",
+		"- just now: User fed you ×3; you replied affectionately (last: \"I could eat all day long,...\")",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", line, summary)
+		}
+	}
+	if len(summary) >= len(pb.formatConversationHistory(history)) {
+		t.Error("Expected the summary to be shorter than the full history")
+	}
+}
+
+func TestPromptBuilder_CompressionOnlyWhenOverBudget(t *testing.T) {
+	pb := budgetTestBuilder()
+	pb.SetHistoryCompression(true)
+
+	full := pb.Build()
+	if strings.Contains(full, "(summarized)") {
+		t.Error("Expected full history when the prompt fits the budget")
+	}
+
+	pb.SetMaxTokens((len(full) - 60) / 4)
+	compressed := pb.Build()
+	if !strings.Contains(compressed, "Recent conversation (summarized):") {
+		t.Fatalf("Expected history to be summarized when over budget, got:\n%s", compressed)
+	}
+	if !strings.Contains(compressed, "User clicked on you ×5") {
+		t.Errorf("Expected all five exchanges to be kept in summary form, got:\n%s", compressed)
+	}
+	if !strings.HasSuffix(compressed, "Your response:") {
+		t.Error("Expected the instruction tail to be preserved")
+	}
+
+	pb.SetHistoryCompression(false)
+	if dropped := pb.Build(); strings.Contains(dropped, "(summarized)") || strings.Contains(dropped, "response number 1 ") {
+		t.Error("Expected the oldest exchange to be dropped when compression is disabled")
+	}
+}
+
+// newBenchmarkPromptBuilder returns a builder with a full history window and a typical context
+func newBenchmarkPromptBuilder() *PromptBuilder {
+	now := time.Now()
+	history := make([]ConversationExchange, 8)
+	for i := range history {
+		history[i] = ConversationExchange{
+			Timestamp:   now.Add(time.Duration(i-len(history)) * time.Minute),
+			Trigger:     "talk",
+			UserMessage: fmt.Sprintf("Tell me about your day, part %d", i),
+			Response:    "It was lovely! I napped in the sun and chased a butterfly.",
+		}
+	}
+
+	pb := NewPromptBuilder()
+	pb.AddPersonality("Based on these example responses, respond in a similar tone and style:\n- Yay!\n- Hehe, that tickles!\n")
+	pb.AddHistory(history)
+	pb.AddContext(DialogContext{
+		Trigger:           "feed",
+		CurrentMood:       75,
+		TimeOfDay:         "evening",
+		DayOfWeek:         "Friday",
+		RelationshipLevel: "friend",
+		PersonalityTraits: map[string]float64{"cheerful": 0.9, "playful": 0.8, "shy": 0.3},
+		CurrentAnimation:  "idle",
+		ConversationTurn:  9,
+		LastResponse:      "It was lovely!",
+	})
+	return pb
+}
+
+func BenchmarkPromptBuilder_Build(b *testing.B) {
+	pb := newBenchmarkPromptBuilder()
+	b.ReportAllocs()
+	for b.Loop() {
+		pb.Build()
+	}
+}
+
+func BenchmarkPromptBuilder_BuildOverBudget(b *testing.B) {
+	pb := newBenchmarkPromptBuilder()
+	pb.SetMaxHistory(8)
+	pb.SetMaxTokens(200)
+	b.ReportAllocs()
+	for b.Loop() {
+		pb.Build()
+	}
+}
@@ -0,0 +1,80 @@
+package dialog
+
+import "sync"
+
+// PromptCacheStats reports how much prompt evaluation the KV cache saved
+type PromptCacheStats struct {
+	Lookups         int `json:"lookups"`         // Prompts evaluated
+	Hits            int `json:"hits"`            // Prompts that reused a cached prefix
+	ReusedTokens    int `json:"reusedTokens"`    // Prompt tokens served from the cache
+	EvaluatedTokens int `json:"evaluatedTokens"` // Prompt tokens that had to be evaluated
+}
+
+// HitRate returns the fraction of prompt tokens served from the cache
+func (s PromptCacheStats) HitRate() float64 {
+	total := s.ReusedTokens + s.EvaluatedTokens
+	if total == 0 {
+		return 0
+	}
+	return float64(s.ReusedTokens) / float64(total)
+}
+
+// PromptCacher is implemented by models that keep the evaluated prompt in their KV cache
+// between requests, so a prompt sharing a prefix with the previous one only evaluates the rest
+type PromptCacher interface {
+	PromptCacheStats() PromptCacheStats
+	ResetPromptCache()
+}
+
+// promptCache tracks the prompt currently held in a model's KV cache
+// Reuse is token-granular: a partially matching token is evaluated again
+type promptCache struct {
+	cached string // Prompt whose tokens are in the KV cache
+	stats  PromptCacheStats
+	mu     sync.Mutex
+}
+
+// evaluate records a new prompt and returns how many of its tokens were reused from the cache
+// and how many must be evaluated; the prompt replaces the cached one
+func (c *promptCache) evaluate(prompt string, estimateTokens func(string) int) (reused, evaluated int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	common := commonPrefixLength(c.cached, prompt)
+	reused = estimateTokens(prompt[:common])
+	evaluated = estimateTokens(prompt) - reused
+
+	c.cached = prompt
+	c.stats.Lookups++
+	if reused > 0 {
+		c.stats.Hits++
+	}
+	c.stats.ReusedTokens += reused
+	c.stats.EvaluatedTokens += evaluated
+	return reused, evaluated
+}
+
+// snapshot returns the accumulated cache statistics
+func (c *promptCache) snapshot() PromptCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// reset empties the cache, keeping its statistics
+func (c *promptCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cached = ""
+}
+
+// commonPrefixLength returns the length in bytes of the longest shared prefix of a and b
+func commonPrefixLength(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
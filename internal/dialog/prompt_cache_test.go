@@ -0,0 +1,120 @@
+package dialog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newCachingLlamaModel creates an initialized LlamaModel backed by an empty GGUF file
+func newCachingLlamaModel(t *testing.T, promptCache bool) *LlamaModel {
+	t.Helper()
+	modelPath := filepath.Join(t.TempDir(), "cache.gguf")
+	if err := os.WriteFile(modelPath, nil, 0o644); err != nil {
+		t.Fatalf("Failed to create test model: %v", err)
+	}
+
+	model, err := NewLlamaModel(LlamaConfig{ModelPath: modelPath, PromptCache: promptCache})
+	if err != nil {
+		t.Fatalf("Failed to create LlamaModel: %v", err)
+	}
+	if err := model.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize LlamaModel: %v", err)
+	}
+	t.Cleanup(func() { model.Free() })
+	return model
+}
+
+func TestPromptCache_ReusesSharedPrefix(t *testing.T) {
+	cache := &promptCache{}
+	estimate := func(text string) int { return len(text) / 4 }
+	prefix := "You are a cheerful desktop pet.\nCurrent character state:\n"
+
+	if reused, evaluated := cache.evaluate(prefix+"User clicked.", estimate); reused != 0 || evaluated != estimate(prefix+"User clicked.") {
+		t.Errorf("Expected a cold cache to evaluate everything, got reused=%d evaluated=%d", reused, evaluated)
+	}
+
+	reused, evaluated := cache.evaluate(prefix+"User fed you.", estimate)
+	if reused != estimate(prefix+"User ") {
+		t.Errorf("Expected the shared prefix to be reused, got %d tokens", reused)
+	}
+	if evaluated != estimate(prefix+"User fed you.")-reused {
+		t.Errorf("Expected only the new suffix to be evaluated, got %d tokens", evaluated)
+	}
+
+	stats := cache.snapshot()
+	if stats.Lookups != 2 || stats.Hits != 1 {
+		t.Errorf("Expected 2 lookups and 1 hit, got %+v", stats)
+	}
+	if stats.HitRate() <= 0 || stats.HitRate() >= 1 {
+		t.Errorf("Expected a partial hit rate, got %f", stats.HitRate())
+	}
+
+	cache.reset()
+	if reused, _ := cache.evaluate(prefix, estimate); reused != 0 {
+		t.Errorf("Expected a reset cache to reuse nothing, got %d tokens", reused)
+	}
+}
+
+func TestLlamaModel_PromptCache(t *testing.T) {
+	restoreDeterminism(t)
+	SetRandomSeed(1)
+	model := newCachingLlamaModel(t, true)
+	prefix := "You are a friendly desktop pet character.\nCurrent character state:\n- Mood: happy\n\n"
+
+	for _, situation := range []string{"User clicked on you", "User fed you", "User hovered over you"} {
+		if _, err := model.Predict(prefix + "Current situation:\n- " + situation + "\n"); err != nil {
+			t.Fatalf("Predict() failed: %v", err)
+		}
+	}
+
+	stats := model.PromptCacheStats()
+	if stats.Lookups != 3 || stats.Hits != 2 {
+		t.Errorf("Expected 3 lookups with 2 cache hits, got %+v", stats)
+	}
+	if stats.ReusedTokens < 2*model.EstimateTokens(prefix) {
+		t.Errorf("Expected the shared prefix to be reused on every later turn, got %+v", stats)
+	}
+
+	model.ResetPromptCache()
+	model.Predict(prefix + "Current situation:\n- User clicked on you\n")
+	if after := model.PromptCacheStats(); after.Hits != stats.Hits {
+		t.Errorf("Expected no hit after ResetPromptCache, got %+v", after)
+	}
+
+	uncached := newCachingLlamaModel(t, false)
+	uncached.Predict(prefix)
+	if stats := uncached.PromptCacheStats(); stats.Lookups != 0 {
+		t.Errorf("Expected no cache activity when disabled, got %+v", stats)
+	}
+}
+
+func TestLLMBackend_HealthReportsPromptCache(t *testing.T) {
+	modelPath := newCachingLlamaModel(t, false).modelPath
+
+	newBackend := func(disable bool) *LLMBackend {
+		configJSON, _ := json.Marshal(LLMConfig{ModelPath: modelPath, DisablePromptCache: disable})
+		backend := NewLLMBackend()
+		if err := backend.Initialize(configJSON); err != nil {
+			t.Fatalf("Failed to initialize backend: %v", err)
+		}
+		t.Cleanup(func() { backend.Close() })
+		return backend
+	}
+
+	backend := newBackend(false)
+	backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user-1"})
+	backend.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user-1"})
+
+	health := backend.GetHealth()
+	if health.PromptCache == nil || health.PromptCache.Hits == 0 {
+		t.Errorf("Expected prompt cache hits in backend health, got %+v", health.PromptCache)
+	}
+
+	disabled := newBackend(true)
+	disabled.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user-1"})
+	if health := disabled.GetHealth(); health.PromptCache == nil || health.PromptCache.Lookups != 0 {
+		t.Errorf("Expected no cache lookups with disablePromptCache, got %+v", health.PromptCache)
+	}
+}
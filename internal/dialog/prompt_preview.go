@@ -0,0 +1,52 @@
+package dialog
+
+// TokenEstimate sizes a previewed prompt against the model's context window
+// Counts come from the loaded model's EstimateTokens, or four characters per token
+// before the backend is initialized
+type TokenEstimate struct {
+	Prompt      int            `json:"prompt"`      // Tokens in the prompt as sent
+	Response    int            `json:"response"`    // Tokens reserved for the reply (maxTokens)
+	ContextSize int            `json:"contextSize"` // The model's context window
+	Budget      int            `json:"budget"`      // Tokens the default layout fits the prompt into
+	Sections    map[string]int `json:"sections"`    // Tokens per section before fitting: header, state, history, situation, instructions
+	Trimmed     bool           `json:"trimmed"`     // Sections were compressed, dropped or shortened to fit the budget
+}
+
+// Fits reports whether the prompt and the reserved reply fit in the context window
+func (e TokenEstimate) Fits() bool {
+	return e.Prompt+e.Response <= e.ContextSize
+}
+
+// PreviewPrompt returns the exact prompt GenerateResponse would send for ctx, with token
+// estimates, without generating or recording anything, so character authors can see
+// what the model is given
+func (llm *LLMBackend) PreviewPrompt(ctx DialogContext) (string, TokenEstimate) {
+	builder := llm.newPromptBuilder(ctx)
+	prompt := llm.renderPrompt(builder)
+	parts := builder.Parts()
+
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+	estimate := func(text string) int {
+		if llm.model != nil {
+			return llm.model.EstimateTokens(text)
+		}
+		return len(text) / 4
+	}
+
+	full := parts.Header + parts.State + parts.History + parts.Situation + parts.Instructions
+	return prompt, TokenEstimate{
+		Prompt:      estimate(prompt),
+		Response:    llm.maxTokens,
+		ContextSize: llm.contextSize,
+		Budget:      parts.MaxTokens,
+		Sections: map[string]int{
+			"header":       estimate(parts.Header),
+			"state":        estimate(parts.State),
+			"history":      estimate(parts.History),
+			"situation":    estimate(parts.Situation),
+			"instructions": estimate(parts.Instructions),
+		},
+		Trimmed: llm.promptStrategy == nil && prompt != full,
+	}
+}
@@ -0,0 +1,44 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLLMBackend_PreviewPrompt(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hi!"}}
+	backend := newScriptedBackend(t, LLMConfig{}, model)
+	ctx := DialogContext{Trigger: "pet", InteractionID: "preview", CurrentMood: 70}
+
+	prompt, estimate := backend.PreviewPrompt(ctx)
+	if prompt != backend.buildPrompt(ctx) {
+		t.Errorf("Expected the prompt GenerateResponse sends, got:\n%s", prompt)
+	}
+	if model.callCount() != 0 || len(backend.GetContextManager().GetHistory("preview", 10)) != 0 {
+		t.Error("Expected previewing not to generate or record anything")
+	}
+	if estimate.Prompt != len(prompt)/4 || estimate.Response != backend.maxTokens || !estimate.Fits() || estimate.Trimmed {
+		t.Errorf("Expected an untrimmed estimate that fits, got %+v", estimate)
+	}
+	if estimate.Sections["situation"] == 0 || estimate.Sections["history"] != 0 {
+		t.Errorf("Expected per-section estimates, got %v", estimate.Sections)
+	}
+}
+
+func TestLLMBackend_PreviewPromptTrimmed(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{ContextSize: 350, MaxTokens: 50}, &scriptedTestModel{})
+	for i := 0; i < 5; i++ {
+		backend.GetContextManager().RecordExchange("long", ConversationExchange{
+			Trigger:  "chat",
+			Response: strings.Repeat("I remember that lovely afternoon so well! ", 4),
+		})
+	}
+
+	prompt, estimate := backend.PreviewPrompt(DialogContext{Trigger: "click", InteractionID: "long"})
+	if !estimate.Trimmed || estimate.Sections["history"] == 0 {
+		t.Errorf("Expected history to be trimmed to the budget, got %+v", estimate)
+	}
+	if estimate.Prompt > estimate.Budget {
+		t.Errorf("Expected the prompt within its %d token budget, got %d:\n%s", estimate.Budget, estimate.Prompt, prompt)
+	}
+}
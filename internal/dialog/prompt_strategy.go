@@ -0,0 +1,100 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromptStrategy lays out the prompt LLMBackend sends to the model from the sections
+// PromptBuilder renders, so integrators can reorder sections or switch to a role-based
+// chat format without replacing the backend
+type PromptStrategy interface {
+	BuildPrompt(parts PromptParts) string
+}
+
+// PromptStrategyFunc adapts an ordinary function into a PromptStrategy
+type PromptStrategyFunc func(parts PromptParts) string
+
+// BuildPrompt calls the underlying function
+func (f PromptStrategyFunc) BuildPrompt(parts PromptParts) string {
+	return f(parts)
+}
+
+// PromptParts are the rendered sections of a prompt, each ending in a newline or empty
+// The default layout is Header, State, History, Situation, Instructions; custom
+// strategies are responsible for keeping within MaxTokens themselves
+type PromptParts struct {
+	Header       string                 // System prompt, personality and facts about the user
+	State        string                 // "Current character state:" section
+	History      string                 // "Recent conversation:" section
+	Situation    string                 // "Current situation:" section
+	Instructions string                 // "Response guidelines:" section, including reply format
+	Exchanges    []ConversationExchange // The exchanges History lists, oldest first
+	Context      DialogContext          // The context being responded to
+	MaxTokens    int                    // Prompt budget in estimated tokens
+}
+
+// Parts renders the prompt sections without fitting them to the token budget
+func (pb *PromptBuilder) Parts() PromptParts {
+	exchanges := pb.historyWindow()
+	return PromptParts{
+		Header:       pb.buildHeader(),
+		State:        pb.buildCharacterState(),
+		History:      pb.formatConversationHistory(exchanges),
+		Situation:    pb.buildCurrentSituation(),
+		Instructions: pb.buildResponseInstructions(),
+		Exchanges:    exchanges,
+		Context:      pb.context,
+		MaxTokens:    pb.maxTokens,
+	}
+}
+
+// ChatMLPromptStrategy formats prompts as ChatML turns for chat-tuned models: the header,
+// state and guidelines form the system message, history becomes alternating user and
+// assistant turns and the current situation is the final user message
+type ChatMLPromptStrategy struct{}
+
+// BuildPrompt lays out parts as ChatML
+func (ChatMLPromptStrategy) BuildPrompt(parts PromptParts) string {
+	var prompt strings.Builder
+	turn := func(role, content string) {
+		fmt.Fprintf(&prompt, "<|im_start|>%s\n%s<|im_end|>\n", role, strings.TrimRight(content, "\n"))
+	}
+
+	turn("system", parts.Header+"\n"+parts.State+parts.Instructions)
+	for _, exchange := range parts.Exchanges {
+		if exchange.Speaker != "" {
+			turn("user", fmt.Sprintf("%s: %s", exchange.Speaker, exchange.Response))
+			continue
+		}
+		if exchange.UserMessage != "" {
+			turn("user", exchange.UserMessage)
+		} else {
+			turn("user", fmt.Sprintf("(The user %s)", triggerDescription(exchange.Trigger)))
+		}
+		turn("assistant", exchange.Response)
+	}
+	turn("user", parts.Situation)
+	prompt.WriteString("<|im_start|>assistant\n")
+	return prompt.String()
+}
+
+// SetPromptStrategy replaces the default prompt layout (nil restores it)
+func (llm *LLMBackend) SetPromptStrategy(strategy PromptStrategy) {
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	llm.promptStrategy = strategy
+}
+
+// renderPrompt builds the prompt with the configured strategy, or the default
+// budget-fitting layout
+func (llm *LLMBackend) renderPrompt(builder *PromptBuilder) string {
+	llm.mu.RLock()
+	strategy := llm.promptStrategy
+	llm.mu.RUnlock()
+
+	if strategy == nil {
+		return builder.Build()
+	}
+	return strategy.BuildPrompt(builder.Parts())
+}
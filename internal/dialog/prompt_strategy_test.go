@@ -0,0 +1,51 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLLMBackend_PromptStrategy(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hi!"}})
+	ctx := DialogContext{Trigger: "pet", InteractionID: "strategy", CurrentMood: 70}
+
+	backend.SetPromptStrategy(PromptStrategyFunc(func(parts PromptParts) string {
+		return parts.Situation + parts.Header + parts.State
+	}))
+	prompt := backend.buildPrompt(ctx)
+	if !strings.HasPrefix(prompt, "Current situation:") || strings.Contains(prompt, "Response guidelines:") {
+		t.Errorf("Expected the custom section order, got:\n%s", prompt)
+	}
+
+	backend.SetPromptStrategy(nil)
+	if prompt := backend.buildPrompt(ctx); !strings.HasPrefix(prompt, "You are") || !strings.Contains(prompt, "Response guidelines:") {
+		t.Errorf("Expected the default layout after clearing the strategy, got:\n%s", prompt)
+	}
+}
+
+func TestChatMLPromptStrategy(t *testing.T) {
+	prompt := ChatMLPromptStrategy{}.BuildPrompt(PromptParts{
+		Header:       "You are a friendly desktop pet character.\n",
+		Instructions: "Response guidelines:\n- Be brief\n",
+		Situation:    "Current situation:\n- The user just performed: petted you\n",
+		Exchanges: []ConversationExchange{
+			{Trigger: "feed", Response: "Yum, thanks!"},
+			{Trigger: "chat", UserMessage: "How are you?", Response: "Great!"},
+		},
+	})
+
+	expected := []string{
+		"<|im_start|>system\nYou are a friendly desktop pet character.",
+		"<|im_start|>user\n(The user fed you)<|im_end|>\n<|im_start|>assistant\nYum, thanks!<|im_end|>",
+		"<|im_start|>user\nHow are you?<|im_end|>\n<|im_start|>assistant\nGreat!<|im_end|>",
+		"<|im_start|>user\nCurrent situation:\n- The user just performed: petted you<|im_end|>\n<|im_start|>assistant\n",
+	}
+	for _, part := range expected {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("Expected the prompt to contain %q, got:\n%s", part, prompt)
+		}
+	}
+	if !strings.HasSuffix(prompt, "<|im_start|>assistant\n") {
+		t.Errorf("Expected the prompt to end with an open assistant turn, got:\n%s", prompt)
+	}
+}
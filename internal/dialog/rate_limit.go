@@ -0,0 +1,217 @@
+package dialog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MetadataRateLimit marks responses that were not freshly generated because of rate limiting
+// Values are RateLimitCoalesced, RateLimitDebounced or RateLimitExceeded
+const MetadataRateLimit = "rateLimit"
+
+// Rate limit outcomes recorded in DialogResponse.Metadata
+const (
+	RateLimitCoalesced = "coalesced"    // Shared the result of an identical in-flight generation
+	RateLimitDebounced = "debounced"    // Reused the previous response inside the debounce window
+	RateLimitExceeded  = "rate_limited" // Over the per-key limit; cached or canned response returned
+)
+
+// RateLimitConfig configures per-interaction coalescing, debouncing and rate limiting
+// Requests are keyed by InteractionID and Trigger so spam-clicking one trigger
+// does not block other interactions
+type RateLimitConfig struct {
+	DebounceMs   int  `json:"debounceMs,omitempty"`   // Repeats within this window reuse the last response (0 = disabled)
+	MaxPerWindow int  `json:"maxPerWindow,omitempty"` // Generations allowed per key per window (0 = unlimited)
+	WindowMs     int  `json:"windowMs,omitempty"`     // Length of the rate window (default: 60000)
+	Coalesce     bool `json:"coalesce,omitempty"`     // Share in-flight generations between identical requests (always on with debouncing or a limit)
+}
+
+// enabled reports whether the config needs a rate limiter at all
+func (c RateLimitConfig) enabled() bool {
+	return c.Coalesce || c.DebounceMs > 0 || c.MaxPerWindow > 0
+}
+
+// rateLimiter tracks in-flight generations, recent responses and generation timestamps per key
+type rateLimiter struct {
+	debounce  time.Duration
+	maxCount  int
+	window    time.Duration
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// rateLimitEntry is the state for one InteractionID+Trigger key
+type rateLimitEntry struct {
+	pending     *pendingGeneration
+	last        DialogResponse
+	lastAt      time.Time
+	hasLast     bool
+	generations []time.Time // Start times of generations inside the window
+}
+
+// pendingGeneration lets coalesced requests wait for the leader's result
+type pendingGeneration struct {
+	done     chan struct{}
+	response DialogResponse
+	err      error
+}
+
+// SetRateLimit enables coalescing, debouncing and rate limiting for GenerateDialog
+// A zero config disables rate limiting
+func (dm *DialogManager) SetRateLimit(config RateLimitConfig) error {
+	if config.DebounceMs < 0 || config.MaxPerWindow < 0 || config.WindowMs < 0 {
+		return fmt.Errorf("rate limit values must be non-negative")
+	}
+
+	var limiter *rateLimiter
+	if config.enabled() {
+		limiter = newRateLimiter(config)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.rateLimiter = limiter
+	return nil
+}
+
+// newRateLimiter resolves a RateLimitConfig, applying defaults for unset values
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	window := time.Duration(config.WindowMs) * time.Millisecond
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	return &rateLimiter{
+		debounce:  time.Duration(config.DebounceMs) * time.Millisecond,
+		maxCount:  config.MaxPerWindow,
+		window:    window,
+		entries:   make(map[string]*rateLimitEntry),
+		lastSweep: currentTime(),
+	}
+}
+
+// rateLimitKey identifies identical requests: the same trigger in the same tenant,
+// character and conversation, and for typed or spoken input the same words
+func rateLimitKey(context DialogContext) string {
+	key := context.TenantID + "\x00" + context.CharacterID + "\x00" + context.InteractionID + "\x00" + context.Trigger
+	if utterance := userUtterance(context); utterance != "" {
+		key += "\x00" + utterance
+	} else if context.VoiceInput != nil {
+		key += "\x00" + context.VoiceInput.Transcript
+	}
+	return key
+}
+
+// wrap applies rate limiting around a handler, using canned for requests with nothing cached
+func (rl *rateLimiter) wrap(next DialogHandler, canned func(DialogContext) DialogResponse) DialogHandler {
+	return func(context DialogContext) (response DialogResponse, err error) {
+		key := rateLimitKey(context)
+		now := currentTime()
+
+		rl.mu.Lock()
+		rl.sweep(now)
+		entry, exists := rl.entries[key]
+		if !exists {
+			entry = &rateLimitEntry{}
+			rl.entries[key] = entry
+		}
+
+		// Identical request already generating: wait for it instead of starting another
+		if pending := entry.pending; pending != nil {
+			rl.mu.Unlock()
+			<-pending.done
+			return markRateLimited(pending.response, RateLimitCoalesced), pending.err
+		}
+
+		if rl.debounce > 0 && entry.hasLast && now.Sub(entry.lastAt) < rl.debounce {
+			response := entry.last
+			rl.mu.Unlock()
+			return markRateLimited(response, RateLimitDebounced), nil
+		}
+
+		entry.generations = pruneBefore(entry.generations, now.Add(-rl.window))
+		if rl.maxCount > 0 && len(entry.generations) >= rl.maxCount {
+			response := canned(context)
+			if entry.hasLast {
+				response = entry.last
+			}
+			rl.mu.Unlock()
+			return markRateLimited(response, RateLimitExceeded), nil
+		}
+
+		pending := &pendingGeneration{done: make(chan struct{})}
+		entry.pending = pending
+		entry.generations = append(entry.generations, now)
+		rl.mu.Unlock()
+
+		// Release waiters even if next panics, so identical requests do not block forever
+		finished := false
+		defer func() {
+			rl.mu.Lock()
+			pending.response, pending.err = response, err
+			if !finished {
+				pending.err = fmt.Errorf("identical in-flight request for '%s' panicked", context.Trigger)
+			}
+			entry.pending = nil
+			if finished && err == nil {
+				entry.last = response
+				entry.lastAt = currentTime()
+				entry.hasLast = true
+			}
+			rl.mu.Unlock()
+			close(pending.done)
+		}()
+
+		response, err = next(context)
+		finished = true
+		return response, err
+	}
+}
+
+// sweep drops idle entries so the key map does not grow without bound; callers must hold rl.mu
+func (rl *rateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rl.window {
+		return
+	}
+	rl.lastSweep = now
+
+	idle := rl.window
+	if rl.debounce > idle {
+		idle = rl.debounce
+	}
+
+	for key, entry := range rl.entries {
+		if entry.pending != nil {
+			continue
+		}
+		lastActive := entry.lastAt
+		if n := len(entry.generations); n > 0 && entry.generations[n-1].After(lastActive) {
+			lastActive = entry.generations[n-1]
+		}
+		if now.Sub(lastActive) >= idle {
+			delete(rl.entries, key)
+		}
+	}
+}
+
+// pruneBefore removes timestamps older than the cutoff from a sorted slice
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	keep := 0
+	for keep < len(times) && times[keep].Before(cutoff) {
+		keep++
+	}
+	return times[keep:]
+}
+
+// markRateLimited returns a copy of the response tagged with the rate limit outcome
+func markRateLimited(response DialogResponse, outcome string) DialogResponse {
+	metadata := make(map[string]interface{}, len(response.Metadata)+1)
+	for key, value := range response.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataRateLimit] = outcome
+	response.Metadata = metadata
+	return response
+}
@@ -0,0 +1,237 @@
+package dialog
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRateLimitTestManager creates a manager whose default backend counts model calls
+func newRateLimitTestManager(t *testing.T, responses ...string) (*DialogManager, *scriptedTestModel) {
+	t.Helper()
+
+	model := &scriptedTestModel{responses: responses}
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", newScriptedBackend(t, LLMConfig{}, model))
+	dm.SetDefaultBackend("llm")
+	return dm, model
+}
+
+func TestDialogManager_SetRateLimitValidation(t *testing.T) {
+	dm := NewDialogManager(false)
+	if err := dm.SetRateLimit(RateLimitConfig{DebounceMs: -1}); err == nil {
+		t.Error("Expected error for negative debounce")
+	}
+	if err := dm.SetRateLimit(RateLimitConfig{}); err != nil {
+		t.Errorf("Expected zero config to disable rate limiting, got %v", err)
+	}
+}
+
+func TestDialogManager_DebounceReusesResponse(t *testing.T) {
+	dm, model := newRateLimitTestManager(t, "First!", "Second!")
+	dm.SetRateLimit(RateLimitConfig{DebounceMs: 50})
+
+	context := DialogContext{Trigger: "click", InteractionID: "pet"}
+	first, _ := dm.GenerateDialog(context)
+	repeat, _ := dm.GenerateDialog(context)
+
+	if repeat.Text != first.Text || repeat.Metadata[MetadataRateLimit] != RateLimitDebounced {
+		t.Errorf("Expected debounced repeat of %q, got %q (%v)", first.Text, repeat.Text, repeat.Metadata)
+	}
+	if model.callCount() != 1 {
+		t.Errorf("Expected 1 model call, got %d", model.callCount())
+	}
+
+	// Other triggers and interactions are keyed separately
+	dm.GenerateDialog(DialogContext{Trigger: "feed", InteractionID: "pet"})
+	if model.callCount() != 2 {
+		t.Errorf("Expected a different trigger to generate, got %d calls", model.callCount())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	dm.GenerateDialog(context)
+	if model.callCount() != 3 {
+		t.Errorf("Expected generation after the debounce window, got %d calls", model.callCount())
+	}
+}
+
+func TestDialogManager_RateLimitReturnsCachedResponse(t *testing.T) {
+	dm, model := newRateLimitTestManager(t, "Only once")
+	dm.SetRateLimit(RateLimitConfig{MaxPerWindow: 1, WindowMs: 60000})
+
+	first, _ := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "a"})
+	limited, _ := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "a"})
+	if limited.Text != first.Text || limited.Metadata[MetadataRateLimit] != RateLimitExceeded {
+		t.Errorf("Expected cached response when limited, got %q (%v)", limited.Text, limited.Metadata)
+	}
+	if model.callCount() != 1 {
+		t.Errorf("Expected 1 model call, got %d", model.callCount())
+	}
+}
+
+func TestDialogManager_RateLimitCoalescesInFlight(t *testing.T) {
+	model := &blockingTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Shared reply"}},
+		started:           make(chan struct{}, 1),
+		release:           make(chan struct{}),
+	}
+	backend := newScriptedBackend(t, LLMConfig{TimeoutMs: 5000}, model.scriptedTestModel)
+	backend.model = model
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+	dm.SetRateLimit(RateLimitConfig{DebounceMs: 1})
+
+	context := DialogContext{Trigger: "click", InteractionID: "spam"}
+	responses := make([]DialogResponse, 5)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		responses[0], _ = dm.GenerateDialog(context)
+	}()
+	<-model.started
+
+	for i := 1; i < len(responses); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = dm.GenerateDialog(context)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(model.release)
+	wg.Wait()
+
+	if model.callCount() != 1 {
+		t.Errorf("Expected a single generation, got %d", model.callCount())
+	}
+	for i, response := range responses {
+		if response.Text != "Shared reply" {
+			t.Errorf("Response %d: expected shared reply, got %q", i, response.Text)
+		}
+	}
+	if responses[1].Metadata[MetadataRateLimit] != RateLimitCoalesced {
+		t.Errorf("Expected coalesced metadata, got %v", responses[1].Metadata)
+	}
+}
+
+func TestDialogManager_CoalesceWithoutRateLimit(t *testing.T) {
+	model := &blockingTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Shared reply", "Fresh reply"}},
+		started:           make(chan struct{}, 1),
+		release:           make(chan struct{}),
+	}
+	backend := newScriptedBackend(t, LLMConfig{TimeoutMs: 5000}, model.scriptedTestModel)
+	backend.model = model
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+	if err := dm.SetRateLimit(RateLimitConfig{Coalesce: true}); err != nil {
+		t.Fatalf("SetRateLimit failed: %v", err)
+	}
+
+	context := DialogContext{Trigger: "click", InteractionID: "double"}
+	var leader, follower DialogResponse
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		leader, _ = dm.GenerateDialog(context)
+	}()
+	<-model.started
+	go func() {
+		defer wg.Done()
+		follower, _ = dm.GenerateDialog(context)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(model.release)
+	wg.Wait()
+
+	if model.callCount() != 1 || leader.Text != "Shared reply" || follower.Text != "Shared reply" {
+		t.Errorf("Expected both callers to share one generation, got %d calls, %q and %q", model.callCount(), leader.Text, follower.Text)
+	}
+	if follower.Metadata[MetadataRateLimit] != RateLimitCoalesced {
+		t.Errorf("Expected coalesced metadata, got %v", follower.Metadata)
+	}
+
+	// Without debouncing, a later identical request generates again
+	if again, _ := dm.GenerateDialog(context); again.Text != "Fresh reply" || model.callCount() != 2 {
+		t.Errorf("Expected a fresh generation once the first finished, got %q after %d calls", again.Text, model.callCount())
+	}
+}
+
+func TestRateLimiter_SweepDropsIdleKeys(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{MaxPerWindow: 5, WindowMs: 10})
+	handler := limiter.wrap(func(DialogContext) (DialogResponse, error) {
+		return DialogResponse{Text: "ok"}, nil
+	}, func(DialogContext) DialogResponse { return DialogResponse{} })
+
+	handler(DialogContext{InteractionID: "old", Trigger: "click"})
+	time.Sleep(20 * time.Millisecond)
+	handler(DialogContext{InteractionID: "new", Trigger: "click"})
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if _, exists := limiter.entries["old\x00click"]; exists {
+		t.Error("Expected idle key to be swept")
+	}
+	if len(limiter.entries) != 1 {
+		t.Errorf("Expected 1 tracked key, got %d", len(limiter.entries))
+	}
+}
+
+func TestRateLimiter_PanicReleasesWaiters(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{Coalesce: true})
+	canned := func(DialogContext) DialogResponse { return DialogResponse{} }
+	started, release := make(chan struct{}), make(chan struct{})
+	panicking := limiter.wrap(func(DialogContext) (DialogResponse, error) {
+		close(started)
+		<-release
+		panic("backend crashed")
+	}, canned)
+	context := DialogContext{InteractionID: "pet", Trigger: "click"}
+
+	go func() {
+		defer func() { recover() }()
+		panicking(context)
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := panicking(context)
+		waiter <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-waiter:
+		if err == nil {
+			t.Error("Expected the coalesced request to report the panic")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the coalesced request released after the panic")
+	}
+
+	handler := limiter.wrap(func(DialogContext) (DialogResponse, error) {
+		return DialogResponse{Text: "ok"}, nil
+	}, canned)
+	done := make(chan DialogResponse, 1)
+	go func() {
+		response, _ := handler(context)
+		done <- response
+	}()
+	select {
+	case response := <-done:
+		if response.Text != "ok" {
+			t.Errorf("Expected a fresh generation after the panic, got %q", response.Text)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected a later identical request not to block")
+	}
+}
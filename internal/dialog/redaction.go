@@ -0,0 +1,235 @@
+package dialog
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Built-in PII detector names for RedactionConfig.Detectors
+const (
+	DetectorEmail   = "email"
+	DetectorPhone   = "phone"
+	DetectorAddress = "address"
+)
+
+// builtinDetectors match common personal details; they favour missing an odd format over
+// redacting ordinary numbers and words
+var builtinDetectors = map[string]RegexDetector{
+	DetectorEmail: {
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		Replacement: "[email]",
+	},
+	DetectorPhone: {
+		Pattern:     regexp.MustCompile(`(?:\+\d{1,3}[\s.\-]?)?(?:\(\d{2,4}\)|\b\d{2,4})[\s.\-]?\d{3,4}[\s.\-]?\d{3,4}\b`),
+		Replacement: "[phone]",
+	},
+	DetectorAddress: {
+		Pattern:     regexp.MustCompile(`\b\d{1,5}\s+(?:[A-Z][A-Za-z]*\s+){1,3}(?i:street|st|avenue|ave|road|rd|boulevard|blvd|lane|ln|drive|dr|court|ct|way|place|pl)\b\.?`),
+		Replacement: "[address]",
+	},
+}
+
+// builtinDetectorOrder runs emails first, so their digits are never taken for phone numbers
+var builtinDetectorOrder = []string{DetectorEmail, DetectorPhone, DetectorAddress}
+
+// RedactionConfig configures PII redaction of stored history (LLMConfig.Redaction)
+type RedactionConfig struct {
+	Enabled   bool               `json:"enabled,omitempty"`   // Redact exchanges before they are stored, persisted or reused in prompts
+	Detectors []string           `json:"detectors,omitempty"` // Built-in detectors to run: "email", "phone", "address" (default: all)
+	Patterns  []RedactionPattern `json:"patterns,omitempty"`  // Additional regular expressions
+}
+
+// RedactionPattern is a custom regular expression detector
+type RedactionPattern struct {
+	Name        string `json:"name"`                  // Used in the default replacement and in errors
+	Pattern     string `json:"pattern"`               // Go regular expression
+	Replacement string `json:"replacement,omitempty"` // Text substituted for each match (default: "[name]")
+}
+
+// PIIDetector replaces the personal details it recognizes in text
+// Implement it to plug in detectors beyond regular expressions, such as a name list or NER model
+type PIIDetector interface {
+	Redact(text string) string
+}
+
+// RegexDetector replaces every match of Pattern with Replacement
+type RegexDetector struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// Redact replaces each match
+func (d RegexDetector) Redact(text string) string {
+	return d.Pattern.ReplaceAllLiteralString(text, d.Replacement)
+}
+
+// Redactor runs PII detectors over text in order
+// A nil Redactor leaves text unchanged
+type Redactor struct {
+	detectors []PIIDetector
+	mu        sync.RWMutex
+}
+
+// NewRedactor builds a redactor from the configured built-in detectors and patterns
+// Enabled is not consulted, so NewRedactor(RedactionConfig{}) runs every built-in detector
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	names := config.Detectors
+	if len(names) == 0 {
+		names = builtinDetectorOrder
+	}
+
+	r := &Redactor{}
+	for _, name := range names {
+		detector, exists := builtinDetectors[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown PII detector '%s' (expected email, phone or address)", name)
+		}
+		r.detectors = append(r.detectors, detector)
+	}
+	for _, pattern := range config.Patterns {
+		if pattern.Name == "" {
+			return nil, fmt.Errorf("redaction pattern %q needs a name", pattern.Pattern)
+		}
+		compiled, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern '%s': %w", pattern.Name, err)
+		}
+		replacement := pattern.Replacement
+		if replacement == "" {
+			replacement = "[" + pattern.Name + "]"
+		}
+		r.detectors = append(r.detectors, RegexDetector{Pattern: compiled, Replacement: replacement})
+	}
+	return r, nil
+}
+
+// AddDetector appends a custom detector, run after the configured ones
+func (r *Redactor) AddDetector(detector PIIDetector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detectors = append(r.detectors, detector)
+}
+
+// Redact returns text with every detected personal detail replaced
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, detector := range r.detectors {
+		text = detector.Redact(text)
+	}
+	return text
+}
+
+// redactExchange redacts what the user and the character said in an exchange
+func (r *Redactor) redactExchange(exchange ConversationExchange) ConversationExchange {
+	exchange.UserMessage = r.Redact(exchange.UserMessage)
+	exchange.Response = r.Redact(exchange.Response)
+	return exchange
+}
+
+// RedactContext returns a copy of context with the user's words, past responses and
+// remembered facts redacted, leaving the caller's slices untouched
+func (r *Redactor) RedactContext(context DialogContext) DialogContext {
+	if r == nil {
+		return context
+	}
+
+	context.UserMessage = r.Redact(context.UserMessage)
+	context.LastResponse = r.Redact(context.LastResponse)
+	if context.VoiceInput != nil {
+		voice := *context.VoiceInput
+		voice.Transcript = r.Redact(voice.Transcript)
+		context.VoiceInput = &voice
+	}
+	if context.InteractionHistory != nil {
+		history := make([]InteractionRecord, len(context.InteractionHistory))
+		for i, record := range context.InteractionHistory {
+			record.Response = r.Redact(record.Response)
+			history[i] = record
+		}
+		context.InteractionHistory = history
+	}
+	if context.UserFacts != nil {
+		facts := make([]UserFact, len(context.UserFacts))
+		for i, fact := range context.UserFacts {
+			fact.Value = r.Redact(fact.Value)
+			facts[i] = fact
+		}
+		context.UserFacts = facts
+	}
+	if context.Conversation != nil {
+		conversation := make([]ConversationExchange, len(context.Conversation))
+		for i, exchange := range context.Conversation {
+			conversation[i] = r.redactExchange(exchange)
+		}
+		context.Conversation = conversation
+	}
+	return context
+}
+
+// redactingBackend hands a backend only redacted contexts
+type redactingBackend struct {
+	DialogBackend
+	redactor *Redactor
+}
+
+// NewRedactingBackend wraps a backend, typically one calling a third-party API, so the
+// contexts it generates from and learns from never contain detected personal details
+func NewRedactingBackend(backend DialogBackend, redactor *Redactor) DialogBackend {
+	return redactingBackend{DialogBackend: backend, redactor: redactor}
+}
+
+func (b redactingBackend) GenerateResponse(context DialogContext) (DialogResponse, error) {
+	return b.DialogBackend.GenerateResponse(b.redactor.RedactContext(context))
+}
+
+func (b redactingBackend) CanHandle(context DialogContext) bool {
+	return b.DialogBackend.CanHandle(b.redactor.RedactContext(context))
+}
+
+func (b redactingBackend) UpdateMemory(context DialogContext, response DialogResponse, userFeedback *UserFeedback) error {
+	return b.DialogBackend.UpdateMemory(b.redactor.RedactContext(context), response, userFeedback)
+}
+
+// GenerateResponseStream streams from the wrapped backend when it supports streaming
+func (b redactingBackend) GenerateResponseStream(context DialogContext, emit func(chunk string)) (DialogResponse, error) {
+	streaming, ok := b.DialogBackend.(StreamingBackend)
+	if !ok {
+		return DialogResponse{}, &CapabilityError{Backend: b.GetBackendInfo().Name, Capability: CapabilityStreaming}
+	}
+	return streaming.GenerateResponseStream(b.redactor.RedactContext(context), emit)
+}
+
+// SetEventBus forwards the event bus to the wrapped backend
+func (b redactingBackend) SetEventBus(bus *EventBus) {
+	if publisher, ok := b.DialogBackend.(EventPublisher); ok {
+		publisher.SetEventBus(bus)
+	}
+}
+
+// SetRedactor redacts exchanges as they are recorded or restored, so neither saved state
+// nor prompts built from history contain detected personal details. Existing history is
+// left as it is
+func (cm *ContextManager) SetRedactor(redactor *Redactor) {
+	cm.lockAll()
+	defer cm.unlockAll()
+	cm.redactor = redactor
+}
+
+// applyRedaction builds the redactor for stored history
+func (llm *LLMBackend) applyRedaction(cfg RedactionConfig) error {
+	llm.redactor = nil
+	if cfg.Enabled {
+		redactor, err := NewRedactor(cfg)
+		if err != nil {
+			return invalidConfig("redaction", err)
+		}
+		llm.redactor = redactor
+	}
+	llm.contextManager.SetRedactor(llm.redactor)
+	return nil
+}
@@ -0,0 +1,115 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactor_BuiltinDetectors(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{})
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Mail me at sam.lee+pets@example.co.uk!", "Mail me at [email]!"},
+		{"Call 555-123-4567 or +44 20 7946 0958", "Call [phone] or [phone]"},
+		{"I live at 221 Baker Street, London", "I live at [address], London"},
+		{"I have 3 cats and I'm 42 years old", "I have 3 cats and I'm 42 years old"},
+		{"It happened in 2024", "It happened in 2024"},
+	}
+	for _, test := range tests {
+		if got := redactor.Redact(test.input); got != test.expected {
+			t.Errorf("Redact(%q): expected %q, got %q", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestNewRedactor_Config(t *testing.T) {
+	redactor, err := NewRedactor(RedactionConfig{
+		Detectors: []string{DetectorEmail},
+		Patterns:  []RedactionPattern{{Name: "account", Pattern: `ACC-\d+`}},
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	if got := redactor.Redact("ACC-991 sam@example.com 555-123-4567"); got != "[account] [email] 555-123-4567" {
+		t.Errorf("Expected only the selected detectors applied, got %q", got)
+	}
+
+	if _, err := NewRedactor(RedactionConfig{Detectors: []string{"ssn"}}); err == nil {
+		t.Error("Expected error for an unknown detector")
+	}
+	if _, err := NewRedactor(RedactionConfig{Patterns: []RedactionPattern{{Name: "bad", Pattern: "("}}}); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+}
+
+// petNameDetector is a custom detector for the pluggable detector test
+type petNameDetector struct{}
+
+func (petNameDetector) Redact(text string) string {
+	return strings.ReplaceAll(text, "Whiskers", "[pet]")
+}
+
+func TestRedactor_RedactContext(t *testing.T) {
+	redactor, _ := NewRedactor(RedactionConfig{})
+	redactor.AddDetector(petNameDetector{})
+
+	facts := []UserFact{{Key: "email", Value: "sam@example.com"}}
+	context := DialogContext{
+		UserMessage: "Whiskers says hi, reach me at sam@example.com",
+		VoiceInput:  &VoiceInput{Transcript: "call 555-123-4567"},
+		UserFacts:   facts,
+	}
+	redacted := redactor.RedactContext(context)
+	if redacted.UserMessage != "[pet] says hi, reach me at [email]" || redacted.VoiceInput.Transcript != "call [phone]" {
+		t.Errorf("Expected the user's words redacted, got %q / %q", redacted.UserMessage, redacted.VoiceInput.Transcript)
+	}
+	if redacted.UserFacts[0].Value != "[email]" || facts[0].Value != "sam@example.com" {
+		t.Errorf("Expected facts redacted in a copy, got %q (original %q)", redacted.UserFacts[0].Value, facts[0].Value)
+	}
+	if context.VoiceInput.Transcript != "call 555-123-4567" {
+		t.Error("Expected the caller's voice input left unchanged")
+	}
+}
+
+func TestLLMBackend_RedactsStoredHistory(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{Redaction: RedactionConfig{Enabled: true}}, &scriptedTestModel{responses: []string{"I'll write to sam@example.com!"}})
+	context := DialogContext{Trigger: "chat", InteractionID: "user", UserMessage: "My email is sam@example.com"}
+	response, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if !strings.Contains(response.Text, "sam@example.com") {
+		t.Errorf("Expected the live response left intact, got %q", response.Text)
+	}
+
+	history := backend.GetContextManager().GetHistory("user", 0)
+	if len(history) != 1 || history[0].UserMessage != "My email is [email]" || history[0].Response != "I'll write to [email]!" {
+		t.Errorf("Expected the stored exchange redacted, got %+v", history)
+	}
+	data, _ := backend.SaveState()
+	if strings.Contains(string(data), "sam@example.com") {
+		t.Errorf("Expected no email in saved state, got %s", data)
+	}
+	if prompt := backend.buildPrompt(DialogContext{Trigger: "click", InteractionID: "user"}); strings.Contains(prompt, "sam@example.com") {
+		t.Errorf("Expected no email in the prompt history, got:\n%s", prompt)
+	}
+}
+
+func TestRedactingBackend(t *testing.T) {
+	inner := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Got it"}})
+	redactor, _ := NewRedactor(RedactionConfig{})
+	backend := NewRedactingBackend(inner, redactor)
+
+	if _, err := backend.GenerateResponse(DialogContext{Trigger: "chat", InteractionID: "user", UserMessage: "Call me on 555-123-4567"}); err != nil {
+		t.Fatalf("GenerateResponse failed: %v", err)
+	}
+	if history := inner.GetContextManager().GetHistory("user", 0); len(history) != 1 || history[0].UserMessage != "Call me on [phone]" {
+		t.Errorf("Expected the wrapped backend to see only the redacted message, got %+v", history)
+	}
+}
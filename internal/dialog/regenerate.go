@@ -0,0 +1,103 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Regeneration constraints
+const (
+	regenerateAvoidPenalty        = 2.0 // Logit bias against tokens of the rejected reply
+	regenerateSimilarityThreshold = 0.8 // Candidates at least this similar to the rejected reply are discarded
+)
+
+// errTooSimilar rejects regenerated responses that repeat the reply the user disliked
+var errTooSimilar = errors.New("response too similar to the rejected reply")
+
+// Regenerator is implemented by backends that can replace a response the user disliked
+type Regenerator interface {
+	Regenerate(ctx DialogContext, previous DialogResponse) (DialogResponse, error)
+}
+
+// Regenerate produces a replacement for a response the user disliked
+// The rejected reply is named in the prompt, penalized during sampling and, when it was the
+// latest exchange in memory, replaced there by the new response
+func (llm *LLMBackend) Regenerate(ctx DialogContext, previous DialogResponse) (DialogResponse, error) {
+	llm.mu.RLock()
+	if !llm.initialized {
+		llm.mu.RUnlock()
+		return DialogResponse{}, fmt.Errorf("LLM backend %w", ErrNotInitialized)
+	}
+	llm.mu.RUnlock()
+
+	// Forget the rejected exchange first so the prompt does not present it as history
+	llm.contextFor(ctx).removeLatestResponse(ctx.InteractionID, previous.Text)
+
+	builder := llm.newPromptBuilder(ctx)
+	builder.AvoidResponse(previous.Text)
+	prompt := llm.renderPrompt(builder)
+
+	opts := llm.samplingOptions()
+	opts.Temperature = min(opts.Temperature+llm.temperatureStep, maxRegenerationTemperature)
+	opts.AvoidText = []string{previous.Text}
+	opts.AvoidPenalty = regenerateAvoidPenalty
+	differs := func(_ DialogContext, response string) error {
+		if responseSimilarity(response, previous.Text) >= regenerateSimilarityThreshold {
+			return errTooSimilar
+		}
+		return nil
+	}
+
+	responseCtx, cancel := context.WithTimeout(ctx.requestContext(), llm.responseTimeout(ctx))
+	defer cancel()
+
+	generation, err := llm.scheduleGeneration(responseCtx, ctx.Priority, func() (generationResult, error) {
+		return llm.generateValidatedWith(responseCtx, ctx, prompt, opts, differs)
+	})
+	if err != nil {
+		if llm.fallbackEnabled {
+			return llm.createFallbackResponse(ctx), nil
+		}
+		return DialogResponse{}, fmt.Errorf("failed to regenerate response: %w", err)
+	}
+
+	dialogResponse := llm.newDialogResponse(ctx, generation)
+	llm.recordResponse(ctx, dialogResponse)
+	return dialogResponse, nil
+}
+
+// removeLatestResponse drops the most recent exchange of a conversation when its response
+// is text, reporting whether it did
+func (cm *ContextManager) removeLatestResponse(interactionID, text string) bool {
+	shard := cm.lockShard(interactionID)
+	defer shard.mu.Unlock()
+
+	history, exists := shard.conversations[interactionID]
+	if !exists || len(history.Exchanges) == 0 {
+		return false
+	}
+	last := len(history.Exchanges) - 1
+	if history.Exchanges[last].Response != cm.redactor.Redact(text) {
+		return false
+	}
+	history.Exchanges = history.Exchanges[:last]
+	return true
+}
+
+// RegenerateDialog asks the default backend for a different response than previous
+// Backends without regeneration support generate a fresh response instead
+func (dm *DialogManager) RegenerateDialog(context DialogContext, previous DialogResponse) (DialogResponse, error) {
+	dm.mu.RLock()
+	regenerator, ok := dm.backends[dm.defaultBackend].(Regenerator)
+	dm.mu.RUnlock()
+	if !ok {
+		return dm.GenerateDialog(context)
+	}
+
+	if !dm.beginRequest() {
+		return DialogResponse{}, ErrShuttingDown
+	}
+	defer dm.inFlight.Done()
+	return regenerator.Regenerate(context, previous)
+}
@@ -0,0 +1,92 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLLMBackend_Regenerate(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hello there, friend!", "hello there, FRIEND!", "Oh, a visitor! 👀"}}
+	backend := newScriptedBackend(t, LLMConfig{Temperature: 0.6, FallbackEnabled: false}, model)
+	context := DialogContext{Trigger: "click", InteractionID: "user-1"}
+
+	previous, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+
+	response, err := backend.Regenerate(context, previous)
+	if err != nil {
+		t.Fatalf("Regenerate() failed: %v", err)
+	}
+	if response.Text != "Oh, a visitor! 👀" {
+		t.Errorf("Expected the near-duplicate to be skipped, got %q", response.Text)
+	}
+
+	first := model.calls[1]
+	if first.Temperature <= model.calls[0].Temperature {
+		t.Errorf("Expected a higher temperature when regenerating, got %+v", model.calls)
+	}
+	if len(first.AvoidText) != 1 || first.AvoidText[0] != previous.Text || first.AvoidPenalty <= 0 {
+		t.Errorf("Expected the rejected reply to be penalized, got %+v", first)
+	}
+
+	history := backend.contextManager.GetHistory("user-1", 0)
+	if len(history) != 1 || history[0].Response != response.Text {
+		t.Errorf("Expected the rejected exchange to be replaced, got %+v", history)
+	}
+}
+
+func TestLLMBackend_RegenerateFailsWhenEveryCandidateRepeats(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Same old reply!"}}
+	backend := newScriptedBackend(t, LLMConfig{FallbackEnabled: false}, model)
+
+	_, err := backend.Regenerate(DialogContext{Trigger: "click"}, DialogResponse{Text: "Same old reply!"})
+	if err == nil || !strings.Contains(err.Error(), errTooSimilar.Error()) {
+		t.Errorf("Expected a too-similar error, got %v", err)
+	}
+}
+
+func TestPromptBuilder_AvoidResponse(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AvoidResponse("  ")
+	pb.AvoidResponse("Thanks for the meal!")
+	pb.AddContext(DialogContext{Trigger: "feed"})
+
+	prompt := pb.Build()
+	if !strings.Contains(prompt, `disliked the reply "Thanks for the meal!"`) {
+		t.Errorf("Expected the rejected reply in the instructions, got %q", prompt)
+	}
+	if strings.Count(prompt, "disliked the reply") != 1 {
+		t.Errorf("Expected blank replies to be ignored, got %q", prompt)
+	}
+	if !strings.HasSuffix(prompt, "Your response:") {
+		t.Errorf("Expected the prompt to end with the response cue, got %q", prompt)
+	}
+}
+
+func TestMockLLMModel_AvoidText(t *testing.T) {
+	model := NewMockLLMModel()
+	model.Initialize()
+	prompt := "Current situation: The user clicked on you"
+
+	first, _ := model.Predict(prompt)
+	second, err := model.PredictWithOptions(t.Context(), prompt, PredictOptions{AvoidText: []string{first}})
+	if err != nil || second == first {
+		t.Errorf("Expected a different reply when the first is avoided, got %q (%v)", second, err)
+	}
+}
+
+func TestDialogManager_RegenerateDialog(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "First reply!", "A different reply!")
+	context := DialogContext{Trigger: "pet", InteractionID: "pet"}
+
+	previous, err := dm.GenerateDialog(context)
+	if err != nil {
+		t.Fatalf("GenerateDialog() failed: %v", err)
+	}
+	response, err := dm.RegenerateDialog(context, previous)
+	if err != nil || response.Text != "A different reply!" {
+		t.Errorf("Expected the regenerated reply, got %q (%v)", response.Text, err)
+	}
+}
@@ -0,0 +1,49 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultRepetitionThreshold is the similarity at which a response counts as a repeat
+const defaultRepetitionThreshold = 0.8
+
+// RepetitionValidator rejects responses nearly identical to one of the last replies
+// in the same conversation, so small models do not loop on a favorite line
+type RepetitionValidator struct {
+	History   *ContextManager
+	Window    int     // Recent replies compared against
+	Threshold float64 // Similarity (0-1) at which a response is a repeat (default: 0.8)
+
+	historyFor func(ctx DialogContext) *ContextManager // Picks the tenant or character history when set
+}
+
+// Validate compares the response with the conversation's last Window replies
+func (v RepetitionValidator) Validate(ctx DialogContext, response string) error {
+	history := v.History
+	if v.historyFor != nil {
+		history = v.historyFor(ctx)
+	}
+	if history == nil || v.Window <= 0 {
+		return nil
+	}
+	threshold := v.Threshold
+	if threshold <= 0 {
+		threshold = defaultRepetitionThreshold
+	}
+
+	for _, exchange := range history.GetHistory(ctx.InteractionID, v.Window) {
+		if similarity := responseSimilarity(response, exchange.Response); similarity >= threshold {
+			return fmt.Errorf("response repeats a recent reply (similarity %.2f): %q", similarity, exchange.Response)
+		}
+	}
+	return nil
+}
+
+// responseSimilarity scores how alike two replies are, from 0 (unrelated) to 1 (same text)
+func responseSimilarity(a, b string) float64 {
+	if strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b)) {
+		return 1
+	}
+	return cosineSimilarity(termVector(a), termVector(b))
+}
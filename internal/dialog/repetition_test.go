@@ -0,0 +1,74 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepetitionValidator(t *testing.T) {
+	history := NewContextManager(10)
+	defer history.Close()
+	history.AddExchange("pet", "click", "Oh! You got my attention!")
+	history.AddExchange("pet", "feed", "Thanks for the meal!")
+	history.AddExchange("pet", "pet", "That feels wonderful!")
+
+	v := RepetitionValidator{History: history, Window: 2}
+	context := DialogContext{InteractionID: "pet"}
+
+	if err := v.Validate(context, "thanks for the MEAL!"); err == nil {
+		t.Error("Expected a near-identical reply to be rejected")
+	}
+	if err := v.Validate(context, "Oh! You got my attention!"); err != nil {
+		t.Errorf("Expected replies outside the window to be allowed, got %v", err)
+	}
+	if err := v.Validate(DialogContext{InteractionID: "other"}, "Thanks for the meal!"); err != nil {
+		t.Errorf("Expected other conversations to be ignored, got %v", err)
+	}
+	if err := (RepetitionValidator{History: history}).Validate(context, "Thanks for the meal!"); err != nil {
+		t.Errorf("Expected a zero window to disable the check, got %v", err)
+	}
+}
+
+func TestLLMBackend_RepetitionTriggersRegeneration(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hello there, friend!", "Hello there friend", "Want to play a game?"}}
+	backend := newScriptedBackend(t, LLMConfig{FallbackEnabled: false, Validation: ValidationConfig{RepetitionWindow: 3}}, model)
+	context := DialogContext{Trigger: "click", InteractionID: "user-1"}
+
+	backend.GenerateResponse(context)
+	response, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+	if response.Text != "Want to play a game?" || model.callCount() != 3 {
+		t.Errorf("Expected the repeat to be regenerated, got %q after %d calls", response.Text, model.callCount())
+	}
+}
+
+func TestLLMBackend_RepetitionFallsBack(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Same old reply!"}}
+	config := LLMConfig{FallbackEnabled: true, Validation: ValidationConfig{RepetitionWindow: 3}}
+	backend := newScriptedBackend(t, config, model)
+	context := DialogContext{Trigger: "click", InteractionID: "user-1"}
+
+	backend.GenerateResponse(context)
+	response, err := backend.GenerateResponse(context)
+	if err != nil || response.ResponseType != "fallback" {
+		t.Errorf("Expected the fallback when every candidate repeats, got %+v (%v)", response, err)
+	}
+
+	config.FallbackEnabled = false
+	strict := newScriptedBackend(t, config, &scriptedTestModel{responses: model.responses})
+	strict.GenerateResponse(context)
+	if _, err := strict.GenerateResponse(context); err == nil || !strings.Contains(err.Error(), "repeats a recent reply") {
+		t.Errorf("Expected a repetition error without fallback, got %v", err)
+	}
+}
+
+func TestResponseSimilarity(t *testing.T) {
+	if got := responseSimilarity(" Hi there! ", "hi there!"); got != 1 {
+		t.Errorf("Expected identical replies to score 1, got %f", got)
+	}
+	if got := responseSimilarity("I love cookies", "The weather is cold"); got != 0 {
+		t.Errorf("Expected unrelated replies to score 0, got %f", got)
+	}
+}
@@ -0,0 +1,131 @@
+package dialog
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Reranker scores candidate responses to a context, one score between 0 and 1 per candidate
+// Scores are blended with each candidate's confidence wherever candidates are compared:
+// response variants, EnsembleBackend and the fallback chain's best-of selection
+type Reranker interface {
+	Rerank(ctx DialogContext, candidates []DialogResponse) []float64
+}
+
+// RerankerFunc adapts an ordinary function into a Reranker
+type RerankerFunc func(ctx DialogContext, candidates []DialogResponse) []float64
+
+// Rerank calls the underlying function
+func (f RerankerFunc) Rerank(ctx DialogContext, candidates []DialogResponse) []float64 {
+	return f(ctx, candidates)
+}
+
+// defaultRerankWeight is the share of a candidate's score given to the reranker
+const defaultRerankWeight = 0.5
+
+// Weights combining the signals HeuristicReranker scores responses on
+const (
+	rerankRelevanceWeight    = 0.35
+	rerankNoveltyWeight      = 0.3
+	rerankLengthWeight       = 0.2
+	rerankCompletenessWeight = 0.15
+)
+
+// Preferred response length in characters when HeuristicReranker leaves it unset
+const (
+	defaultRerankMinLength = 15
+	defaultRerankMaxLength = 160
+)
+
+// HeuristicReranker is the built-in Reranker: it prefers responses that share words with
+// the trigger, user message and topics, differ from the last response, fit the preferred
+// length and end as a complete sentence
+type HeuristicReranker struct {
+	MinLength int // Shorter responses score lower (default: 15)
+	MaxLength int // Longer responses score lower (default: 160)
+}
+
+// Rerank scores each candidate's text
+func (r HeuristicReranker) Rerank(ctx DialogContext, candidates []DialogResponse) []float64 {
+	minLength, maxLength := r.MinLength, r.MaxLength
+	if minLength <= 0 {
+		minLength = defaultRerankMinLength
+	}
+	if maxLength <= 0 {
+		maxLength = defaultRerankMaxLength
+	}
+	query := termVector(buildExampleQuery(ctx))
+
+	scores := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		text := strings.TrimSpace(candidate.Text)
+		novelty := 1.0
+		if ctx.LastResponse != "" {
+			novelty -= responseSimilarity(text, ctx.LastResponse)
+		}
+		scores[i] = rerankRelevanceWeight*cosineSimilarity(query, termVector(text)) +
+			rerankNoveltyWeight*novelty +
+			rerankLengthWeight*lengthFit(utf8.RuneCountInString(text), minLength, maxLength) +
+			rerankCompletenessWeight*completeness(text)
+	}
+	return scores
+}
+
+// lengthFit is 1 inside [minLength, maxLength] and falls off proportionally outside it
+func lengthFit(length, minLength, maxLength int) float64 {
+	switch {
+	case length < minLength:
+		return float64(length) / float64(minLength)
+	case length > maxLength:
+		return max(0, 1-float64(length-maxLength)/float64(maxLength))
+	}
+	return 1
+}
+
+// completeness is 1 for text ending like a finished sentence
+func completeness(text string) float64 {
+	if text == "" {
+		return 0
+	}
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if strings.ContainsRune(".!?~)\"'", last) || unicode.IsSymbol(last) {
+		return 1 // Punctuation, or an emoji as pets often end with
+	}
+	return 0
+}
+
+// rerankScores blends each response's confidence with the reranker's score, weighted by
+// weight; a nil reranker leaves confidence as the score
+func rerankScores(reranker Reranker, ctx DialogContext, responses []DialogResponse, weight float64) []float64 {
+	scores := make([]float64, len(responses))
+	for i, response := range responses {
+		scores[i] = response.Confidence
+	}
+	if reranker == nil || len(responses) == 0 {
+		return scores
+	}
+	reranked := reranker.Rerank(ctx, responses)
+	for i := range scores {
+		if i < len(reranked) {
+			scores[i] = (1-weight)*scores[i] + weight*reranked[i]
+		}
+	}
+	return scores
+}
+
+// SetReranker sets the reranker blended into the scores of GenerateResponseVariants
+// (nil = confidence only)
+func (llm *LLMBackend) SetReranker(reranker Reranker) {
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+	llm.reranker = reranker
+}
+
+// SetReranker sets the reranker used to pick the best below-threshold response when no
+// backend in the fallback chain meets the confidence threshold (nil = highest confidence)
+func (dm *DialogManager) SetReranker(reranker Reranker) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.reranker = reranker
+}
@@ -0,0 +1,90 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+// preferReranker scores responses containing word highest
+func preferReranker(word string) Reranker {
+	return RerankerFunc(func(ctx DialogContext, candidates []DialogResponse) []float64 {
+		scores := make([]float64, len(candidates))
+		for i, candidate := range candidates {
+			if strings.Contains(candidate.Text, word) {
+				scores[i] = 1
+			}
+		}
+		return scores
+	})
+}
+
+func TestHeuristicReranker(t *testing.T) {
+	ctx := DialogContext{Trigger: "chat", UserMessage: "Do you like pizza?", LastResponse: "I love naps!"}
+	scores := HeuristicReranker{}.Rerank(ctx, []DialogResponse{
+		{Text: "I love naps!"},
+		{Text: "Pizza is my favorite food, I like pizza!"},
+		{Text: "ok"},
+		{Text: "Pizza is my favorite food and"},
+	})
+
+	if scores[1] <= scores[0] || scores[1] <= scores[2] {
+		t.Errorf("Expected the relevant, new response to score highest, got %v", scores)
+	}
+	if scores[1] <= scores[3] {
+		t.Errorf("Expected a complete sentence to beat a cut-off one, got %v", scores)
+	}
+	for _, score := range scores {
+		if score < 0 || score > 1 {
+			t.Errorf("Expected scores between 0 and 1, got %v", scores)
+		}
+	}
+}
+
+func TestLengthFit(t *testing.T) {
+	for _, test := range []struct {
+		length   int
+		expected float64
+	}{
+		{5, 0.5}, {10, 1}, {20, 1}, {30, 0.5}, {60, 0},
+	} {
+		if fit := lengthFit(test.length, 10, 20); fit != test.expected {
+			t.Errorf("Expected lengthFit(%d) = %g, got %g", test.length, test.expected, fit)
+		}
+	}
+}
+
+func TestDialogManager_EscalationUsesReranker(t *testing.T) {
+	newManager := func() *DialogManager {
+		dm := NewDialogManager(false)
+		dm.RegisterBackend("primary", &fixedTestBackend{response: DialogResponse{Text: "Uh, what?", Confidence: 0.4}})
+		dm.RegisterBackend("secondary", &fixedTestBackend{response: DialogResponse{Text: "Hmm, maybe?", Confidence: 0.3}})
+		dm.SetDefaultBackend("primary")
+		dm.SetFallbackChain([]string{"secondary"})
+		return dm
+	}
+
+	if response, _ := newManager().GenerateDialog(DialogContext{Trigger: "click"}); response.Text != "Uh, what?" {
+		t.Errorf("Expected the most confident response without a reranker, got %q", response.Text)
+	}
+
+	dm := newManager()
+	dm.SetReranker(preferReranker("maybe"))
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "click"})
+	if response.Text != "Hmm, maybe?" || response.Metadata[MetadataEscalated] != "secondary" {
+		t.Errorf("Expected the reranker to pick the fallback's response, got %q (%v)", response.Text, response.Metadata)
+	}
+}
+
+func TestLLMBackend_GenerateResponseVariantsUsesReranker(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hello there!", "Hi, friend!", "Nice to see you!"}}
+	backend := newScriptedBackend(t, LLMConfig{}, model)
+	backend.SetReranker(preferReranker("friend"))
+
+	variants, err := backend.GenerateResponseVariants(DialogContext{Trigger: "click"}, 3)
+	if err != nil {
+		t.Fatalf("GenerateResponseVariants() failed: %v", err)
+	}
+	if variants[0].Response.Text != "Hi, friend!" || variants[0].Score <= variants[0].Response.Confidence {
+		t.Errorf("Expected the reranked variant first with a blended score, got %+v", variants)
+	}
+}
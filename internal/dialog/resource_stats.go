@@ -0,0 +1,167 @@
+package dialog
+
+import (
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// MemoryStats summarizes the conversation memory held by a ContextManager
+type MemoryStats struct {
+	Conversations int   `json:"conversations"`
+	Exchanges     int   `json:"exchanges"`
+	Bytes         int64 `json:"bytes"` // Estimated heap bytes of stored conversations
+
+	Evictions map[string]EvictionCounts `json:"evictions,omitempty"` // Memory dropped so far, keyed by eviction reason
+}
+
+// add sums other into s
+func (s *MemoryStats) add(other MemoryStats) {
+	s.Conversations += other.Conversations
+	s.Exchanges += other.Exchanges
+	s.Bytes += other.Bytes
+	s.Evictions = addEvictionCounts(s.Evictions, other.Evictions)
+}
+
+// CacheStats describes one in-memory cache
+type CacheStats struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"` // Estimated heap bytes
+}
+
+// ResourceStats reports the memory and load of a backend, or of every backend for DialogManager
+type ResourceStats struct {
+	ModelBytes uint64                   `json:"modelBytes"` // Estimated resident model memory: weights, KV cache and scratch
+	Memory     MemoryStats              `json:"memory"`
+	Caches     []CacheStats             `json:"caches,omitempty"`
+	QueueDepth int                      `json:"queueDepth"` // Generations currently in flight
+	Backends   map[string]ResourceStats `json:"backends,omitempty"`
+}
+
+// TotalBytes returns the estimated memory of the model, conversations and caches combined
+func (s ResourceStats) TotalBytes() uint64 {
+	total := s.ModelBytes + uint64(s.Memory.Bytes)
+	for _, cache := range s.Caches {
+		total += uint64(cache.Bytes)
+	}
+	return total
+}
+
+// ResourceReporter is implemented by backends that report their memory usage and load
+type ResourceReporter interface {
+	GetResourceStats() ResourceStats
+}
+
+// Approximate fixed heap sizes used by memory estimates
+const (
+	exchangeSize     = int64(unsafe.Sizeof(ConversationExchange{}))
+	conversationSize = int64(unsafe.Sizeof(ConversationHistory{})) + 48 // Plus map entry overhead
+	rateLimitSize    = int64(unsafe.Sizeof(rateLimitEntry{})) + int64(unsafe.Sizeof(DialogResponse{}))
+	timeSize         = int64(unsafe.Sizeof(time.Time{}))
+)
+
+// MemoryStats estimates the memory used by stored conversations
+func (cm *ContextManager) MemoryStats() MemoryStats {
+	cm.rlockAll()
+	defer cm.runlockAll()
+
+	var stats MemoryStats
+	for id, history := range cm.allConversations() {
+		stats.Conversations++
+		stats.Exchanges += len(history.Exchanges)
+		stats.Bytes += conversationBytes(id, history)
+	}
+	stats.Evictions = cm.evictionCounts()
+	return stats
+}
+
+// size estimates the memory held by the selector's examples and term vectors
+func (s *ExampleSelector) size() CacheStats {
+	stats := CacheStats{Name: "examples", Entries: len(s.examples)}
+	for i, example := range s.examples {
+		stats.Bytes += int64(len(example))
+		for term := range s.vectors[i] {
+			stats.Bytes += int64(len(term)) + 24 // Key header and float64 value
+		}
+	}
+	return stats
+}
+
+// GetResourceStats reports estimated model memory, conversation memory, caches and queue depth
+func (llm *LLMBackend) GetResourceStats() ResourceStats {
+	llm.mu.RLock()
+	var stats ResourceStats
+	if reporter, ok := llm.model.(HardwareFitReporter); ok {
+		if report, checked := reporter.HardwareFit(); checked {
+			stats.ModelBytes = report.EstimatedRAMBytes
+		}
+	}
+	if llm.exampleSelector != nil {
+		stats.Caches = append(stats.Caches, llm.exampleSelector.size())
+	}
+	contextManager := llm.contextManager
+	llm.mu.RUnlock()
+
+	if contextManager != nil {
+		stats.Memory = contextManager.MemoryStats()
+	}
+	for _, cm := range llm.isolatedContextManagers() {
+		memory := cm.MemoryStats()
+		stats.Memory.add(memory)
+	}
+	var health BackendHealth
+	llm.health.snapshot(&health)
+	stats.QueueDepth = health.QueueDepth
+	return stats
+}
+
+// GetResourceStats sums resource usage across registered backends and adds the manager's own
+// rate limit cache; per-backend figures are listed under Backends
+func (dm *DialogManager) GetResourceStats() ResourceStats {
+	dm.mu.RLock()
+	reporters := make(map[string]ResourceReporter, len(dm.backends))
+	for name, backend := range dm.backends {
+		if reporter, ok := backend.(ResourceReporter); ok {
+			reporters[name] = reporter
+		}
+	}
+	limiter := dm.rateLimiter
+	dm.mu.RUnlock()
+
+	total := ResourceStats{Backends: make(map[string]ResourceStats, len(reporters))}
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		stats := reporters[name].GetResourceStats()
+		total.Backends[name] = stats
+		total.ModelBytes += stats.ModelBytes
+		total.Memory.add(stats.Memory)
+		total.QueueDepth += stats.QueueDepth
+		for _, cache := range stats.Caches {
+			cache.Name = name + "." + cache.Name
+			total.Caches = append(total.Caches, cache)
+		}
+	}
+
+	if limiter != nil {
+		total.Caches = append(total.Caches, limiter.size())
+	}
+	return total
+}
+
+// size estimates the memory held by rate limit entries and their cached responses
+func (rl *rateLimiter) size() CacheStats {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	stats := CacheStats{Name: "rateLimit", Entries: len(rl.entries)}
+	for key, entry := range rl.entries {
+		stats.Bytes += rateLimitSize + int64(len(key)+len(entry.last.Text)) + int64(cap(entry.generations))*timeSize
+	}
+	return stats
+}
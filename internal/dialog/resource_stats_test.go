@@ -0,0 +1,75 @@
+package dialog
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestContextManager_MemoryStats(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+
+	if stats := cm.MemoryStats(); stats.Conversations != 0 || stats.Bytes != 0 {
+		t.Errorf("Expected an empty manager to use nothing, got %+v", stats)
+	}
+
+	cm.AddExchange("user-1", "click", "Hello!")
+	cm.AddExchange("user-1", "feed", "Yum!")
+	cm.AddExchange("user-2", "click", "Hi")
+	small := cm.MemoryStats()
+	if small.Conversations != 2 || small.Exchanges != 3 || small.Bytes <= 0 {
+		t.Errorf("Expected 2 conversations with 3 exchanges, got %+v", small)
+	}
+
+	cm.AddExchange("user-2", "talk", string(make([]byte, 1000)))
+	if grown := cm.MemoryStats(); grown.Bytes < small.Bytes+1000 {
+		t.Errorf("Expected a long response to add at least 1000 bytes, got %d -> %d", small.Bytes, grown.Bytes)
+	}
+}
+
+func TestDialogManager_GetResourceStats(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hi there!")
+	dm.SetRateLimit(RateLimitConfig{DebounceMs: 1000})
+
+	backend := dm.backends["llm"].(*LLMBackend)
+	backend.exampleSelector = NewExampleSelector([]string{"I love snacks!", "Let's play a game"})
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "user-1"})
+	backend.UpdateMemory(DialogContext{Trigger: "click", InteractionID: "user-1"}, DialogResponse{Text: "Hi there!"}, nil)
+
+	stats := dm.GetResourceStats()
+	llmStats, ok := stats.Backends["llm"]
+	if !ok {
+		t.Fatalf("Expected per-backend stats for llm, got %+v", stats.Backends)
+	}
+	if llmStats.Memory.Conversations != 1 || stats.Memory.Exchanges != llmStats.Memory.Exchanges {
+		t.Errorf("Expected one conversation summed into the total, got %+v and %+v", llmStats.Memory, stats.Memory)
+	}
+	if llmStats.ModelBytes != 0 {
+		t.Errorf("Expected no model estimate for a scripted model, got %d", llmStats.ModelBytes)
+	}
+
+	caches := make(map[string]CacheStats)
+	for _, cache := range stats.Caches {
+		caches[cache.Name] = cache
+	}
+	if caches["llm.examples"].Entries != 2 || caches["rateLimit"].Entries != 1 {
+		t.Errorf("Expected example and rate limit caches, got %+v", stats.Caches)
+	}
+	if stats.TotalBytes() < uint64(stats.Memory.Bytes+caches["rateLimit"].Bytes) {
+		t.Errorf("Expected total to include memory and caches, got %d", stats.TotalBytes())
+	}
+}
+
+func TestLLMBackend_GetResourceStatsIncludesModelEstimate(t *testing.T) {
+	path := writeGGUF(t, "stats.Q4_0.gguf", map[string]interface{}{"general.file_type": uint32(2)}, 2048)
+	configJSON, _ := json.Marshal(LLMConfig{ModelPath: path})
+	backend := NewLLMBackend()
+	defer backend.Close()
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+
+	if stats := backend.GetResourceStats(); stats.ModelBytes < 2048 {
+		t.Errorf("Expected the model estimate to cover the weights, got %d", stats.ModelBytes)
+	}
+}
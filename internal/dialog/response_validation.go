@@ -0,0 +1,229 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ResponseValidator checks a generated response before it is shown to the user
+// Returning an error rejects the response and triggers regeneration
+type ResponseValidator interface {
+	Validate(ctx DialogContext, response string) error
+}
+
+// ResponseValidatorFunc adapts an ordinary function into a ResponseValidator
+type ResponseValidatorFunc func(ctx DialogContext, response string) error
+
+// Validate calls the underlying function
+func (f ResponseValidatorFunc) Validate(ctx DialogContext, response string) error {
+	return f(ctx, response)
+}
+
+// ValidationConfig configures the built-in validators and regeneration policy
+type ValidationConfig struct {
+	MinLength        int      `json:"minLength,omitempty"`        // Minimum response length in characters
+	MaxLength        int      `json:"maxLength,omitempty"`        // Maximum response length in characters (0 = unlimited)
+	BannedPhrases    []string `json:"bannedPhrases,omitempty"`    // Case-insensitive phrases that must not appear
+	RequiredMarkers  []string `json:"requiredMarkers,omitempty"`  // At least one must appear (e.g. persona catchphrases)
+	MaxRegenerations int      `json:"maxRegenerations,omitempty"` // Retries after a rejected response (default: 2)
+	TemperatureStep  float32  `json:"temperatureStep,omitempty"`  // Temperature increase per retry (default: 0.15)
+
+	RepetitionWindow    int     `json:"repetitionWindow,omitempty"`    // Recent replies per conversation a response must not repeat (0 = off)
+	RepetitionThreshold float64 `json:"repetitionThreshold,omitempty"` // Similarity (0-1) that counts as a repeat (default: 0.8)
+}
+
+// LengthValidator rejects responses outside a character length range
+type LengthValidator struct {
+	MinLength int
+	MaxLength int // 0 = unlimited
+}
+
+// Validate checks the response length in characters
+func (v LengthValidator) Validate(ctx DialogContext, response string) error {
+	length := utf8.RuneCountInString(strings.TrimSpace(response))
+	if length < v.MinLength {
+		return fmt.Errorf("response too short: %d characters (min %d)", length, v.MinLength)
+	}
+	if v.MaxLength > 0 && length > v.MaxLength {
+		return fmt.Errorf("response too long: %d characters (max %d)", length, v.MaxLength)
+	}
+	return nil
+}
+
+// BannedContentValidator rejects responses containing any banned phrase
+type BannedContentValidator struct {
+	Phrases []string
+}
+
+// Validate checks the response for banned phrases, ignoring case
+func (v BannedContentValidator) Validate(ctx DialogContext, response string) error {
+	lower := strings.ToLower(response)
+	for _, phrase := range v.Phrases {
+		if phrase != "" && strings.Contains(lower, strings.ToLower(phrase)) {
+			return fmt.Errorf("response contains banned phrase %q", phrase)
+		}
+	}
+	return nil
+}
+
+// PersonaMarkerValidator requires at least one persona marker in the response
+// Useful for characters with signature emoji, catchphrases or verbal tics
+type PersonaMarkerValidator struct {
+	Markers []string
+}
+
+// Validate checks that at least one marker appears, ignoring case
+func (v PersonaMarkerValidator) Validate(ctx DialogContext, response string) error {
+	if len(v.Markers) == 0 {
+		return nil
+	}
+
+	lower := strings.ToLower(response)
+	for _, marker := range v.Markers {
+		if strings.Contains(lower, strings.ToLower(marker)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("response is missing a persona marker (expected one of %v)", v.Markers)
+}
+
+// PatternValidator rejects responses matching any blocked regular expression or
+// missing any required one
+type PatternValidator struct {
+	Blocked  []*regexp.Regexp
+	Required []*regexp.Regexp
+}
+
+// Validate checks the response against every pattern
+func (v PatternValidator) Validate(ctx DialogContext, response string) error {
+	for _, pattern := range v.Blocked {
+		if match := pattern.FindStringIndex(response); match != nil {
+			return fmt.Errorf("response matches blocked pattern %q: %q", pattern, response[match[0]:match[1]])
+		}
+	}
+	for _, pattern := range v.Required {
+		if !pattern.MatchString(response) {
+			return fmt.Errorf("response does not match required pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// compilePatterns compiles configured regular expressions
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// buildValidators creates the built-in validators described by the configuration
+func buildValidators(cfg ValidationConfig) []ResponseValidator {
+	var validators []ResponseValidator
+
+	if cfg.MinLength > 0 || cfg.MaxLength > 0 {
+		validators = append(validators, LengthValidator{MinLength: cfg.MinLength, MaxLength: cfg.MaxLength})
+	}
+	if len(cfg.BannedPhrases) > 0 {
+		validators = append(validators, BannedContentValidator{Phrases: cfg.BannedPhrases})
+	}
+	if len(cfg.RequiredMarkers) > 0 {
+		validators = append(validators, PersonaMarkerValidator{Markers: cfg.RequiredMarkers})
+	}
+
+	return validators
+}
+
+// AddValidator registers an additional response validator on the backend
+func (llm *LLMBackend) AddValidator(validator ResponseValidator) {
+	llm.mu.Lock()
+	defer llm.mu.Unlock()
+
+	if validator != nil {
+		llm.customValidators = append(llm.customValidators, validator)
+	}
+}
+
+// validateResponse runs all configured and custom validators
+func (llm *LLMBackend) validateResponse(ctx DialogContext, response string) error {
+	llm.mu.RLock()
+	validators := make([]ResponseValidator, 0, len(llm.validators)+len(llm.customValidators))
+	validators = append(validators, llm.validators...)
+	validators = append(validators, llm.customValidators...)
+	llm.mu.RUnlock()
+
+	return validateWith(validators, ctx, response)
+}
+
+// validateWith runs validators in order, returning the first rejection
+func validateWith(validators []ResponseValidator, ctx DialogContext, response string) error {
+	for _, validator := range validators {
+		if err := validator.Validate(ctx, response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateValidated generates a response and regenerates with a higher temperature
+// while validators reject it, up to the configured number of regenerations
+func (llm *LLMBackend) generateValidated(responseCtx context.Context, ctx DialogContext, prompt string) (generationResult, error) {
+	return llm.generateValidatedWith(responseCtx, ctx, prompt, llm.samplingOptions(), nil)
+}
+
+// samplingOptions returns the backend's configured per-request sampling options
+func (llm *LLMBackend) samplingOptions() PredictOptions {
+	opts := PredictOptions{
+		Temperature: llm.temperature,
+		TopP:        llm.topP,
+		MaxTokens:   llm.adaptive.maxTokens(llm.maxTokens),
+	}
+	switch {
+	case llm.grammar != nil:
+		opts.Grammar = llm.grammar.String()
+	case llm.structured != nil:
+		opts.Grammar = llm.structured.grammar
+	}
+	return opts
+}
+
+// generateValidatedWith is generateValidated with explicit starting options and an extra
+// check applied after the registered validators (nil for none)
+func (llm *LLMBackend) generateValidatedWith(responseCtx context.Context, ctx DialogContext, prompt string, opts PredictOptions, extra ResponseValidatorFunc) (generationResult, error) {
+	var lastErr error
+	for attempt := 0; attempt <= llm.maxRegenerations; attempt++ {
+		result, err := llm.generateWithRetry(responseCtx, prompt, opts)
+		if err != nil {
+			return generationResult{}, err
+		}
+
+		var text string
+		text, lastErr = llm.validateGenerated(ctx, result.text)
+		if lastErr == nil && extra != nil {
+			lastErr = extra(ctx, text)
+		}
+		if lastErr == nil {
+			result.regenerations = attempt
+			return result, nil
+		}
+
+		// Raise temperature so the next attempt explores different wording
+		opts.Temperature += llm.temperatureStep
+		if opts.Temperature > maxRegenerationTemperature {
+			opts.Temperature = maxRegenerationTemperature
+		}
+	}
+
+	return generationResult{}, fmt.Errorf("response failed validation after %d attempts: %w", llm.maxRegenerations+1, lastErr)
+}
+
+// maxRegenerationTemperature caps temperature increases during regeneration
+const maxRegenerationTemperature = 1.5
@@ -0,0 +1,239 @@
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// scriptedTestModel returns queued responses in order and records sampling options
+type scriptedTestModel struct {
+	responses []string
+	errors    []error
+	calls     []PredictOptions
+	mu        sync.Mutex
+}
+
+func (m *scriptedTestModel) Initialize() error { return nil }
+
+func (m *scriptedTestModel) Predict(prompt string) (string, error) {
+	return m.PredictWithOptions(context.Background(), prompt, PredictOptions{})
+}
+
+func (m *scriptedTestModel) PredictWithTimeout(ctx context.Context, prompt string) (string, error) {
+	return m.PredictWithOptions(ctx, prompt, PredictOptions{})
+}
+
+func (m *scriptedTestModel) PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	index := len(m.calls)
+	m.calls = append(m.calls, opts)
+
+	if index < len(m.errors) && m.errors[index] != nil {
+		return "", m.errors[index]
+	}
+	if len(m.responses) == 0 {
+		return "", fmt.Errorf("no scripted response")
+	}
+	if index >= len(m.responses) {
+		index = len(m.responses) - 1
+	}
+	return m.responses[index], nil
+}
+
+func (m *scriptedTestModel) EstimateTokens(text string) int { return len(text) / 4 }
+func (m *scriptedTestModel) GetContextSize() int            { return 2048 }
+func (m *scriptedTestModel) GetModelInfo() ModelInfo {
+	return ModelInfo{ModelType: "scripted", Initialized: true}
+}
+func (m *scriptedTestModel) Free() error { return nil }
+
+func (m *scriptedTestModel) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+// newScriptedBackend creates an initialized backend whose model is replaced by a scripted one
+func newScriptedBackend(t testing.TB, config LLMConfig, model *scriptedTestModel) *LLMBackend {
+	t.Helper()
+
+	if config.ModelPath == "" {
+		config.ModelPath = "/fake/path.gguf"
+	}
+	configJSON, _ := json.Marshal(config)
+
+	backend := NewLLMBackend()
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	backend.model = model
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+func TestLengthValidator(t *testing.T) {
+	v := LengthValidator{MinLength: 3, MaxLength: 10}
+
+	if err := v.Validate(DialogContext{}, "Hi"); err == nil {
+		t.Error("Expected short response to be rejected")
+	}
+	if err := v.Validate(DialogContext{}, "This response is far too long"); err == nil {
+		t.Error("Expected long response to be rejected")
+	}
+	if err := v.Validate(DialogContext{}, "Hello 😊"); err != nil {
+		t.Errorf("Expected valid response, got %v", err)
+	}
+}
+
+func TestBannedContentValidator(t *testing.T) {
+	v := BannedContentValidator{Phrases: []string{"as an AI"}}
+
+	if err := v.Validate(DialogContext{}, "As an ai, I cannot play"); err == nil {
+		t.Error("Expected banned phrase to be rejected regardless of case")
+	}
+	if err := v.Validate(DialogContext{}, "Let's play!"); err != nil {
+		t.Errorf("Expected clean response to pass, got %v", err)
+	}
+}
+
+func TestPersonaMarkerValidator(t *testing.T) {
+	v := PersonaMarkerValidator{Markers: []string{"nya", "🐾"}}
+
+	if err := v.Validate(DialogContext{}, "Hello there"); err == nil {
+		t.Error("Expected response without marker to be rejected")
+	}
+	if err := v.Validate(DialogContext{}, "Hello there, NYA!"); err != nil {
+		t.Errorf("Expected response with marker to pass, got %v", err)
+	}
+	if err := (PersonaMarkerValidator{}).Validate(DialogContext{}, "anything"); err != nil {
+		t.Errorf("Expected no markers to accept everything, got %v", err)
+	}
+}
+
+func TestPatternValidator(t *testing.T) {
+	v := PatternValidator{
+		Blocked:  []*regexp.Regexp{regexp.MustCompile(`(?i)as an ai`), regexp.MustCompile(`https?://`)},
+		Required: []*regexp.Regexp{regexp.MustCompile(`[.!?~]$`)},
+	}
+
+	if err := v.Validate(DialogContext{}, "As an AI, I cannot play."); err == nil {
+		t.Error("Expected a blocked pattern to be rejected")
+	}
+	if err := v.Validate(DialogContext{}, "Look at http://example.com!"); err == nil {
+		t.Error("Expected a link to be rejected")
+	}
+	if err := v.Validate(DialogContext{}, "Let's play"); err == nil {
+		t.Error("Expected a response missing a required pattern to be rejected")
+	}
+	if err := v.Validate(DialogContext{}, "Let's play~"); err != nil {
+		t.Errorf("Expected a matching response to pass, got %v", err)
+	}
+}
+
+func TestLLMBackend_ResponsePatterns(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Visit https://shop.example now", "Let's play together!"}}
+	backend := newScriptedBackend(t, LLMConfig{
+		BlockedPatterns:  []string{`https?://`},
+		RequiredPatterns: []string{`!$`},
+	}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "play"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Text != "Let's play together!" || model.callCount() != 2 {
+		t.Errorf("Expected the blocked response regenerated, got %q after %d attempts", response.Text, model.callCount())
+	}
+
+	invalid := NewLLMBackend()
+	defer invalid.Close()
+	var configErr *ConfigError
+	err = invalid.Initialize(json.RawMessage(`{"modelPath": "/fake/path.gguf", "requiredPatterns": ["("]}`))
+	if !errors.As(err, &configErr) || configErr.Field != "requiredPatterns" {
+		t.Errorf("Expected an invalid pattern to be a requiredPatterns config error, got %v", err)
+	}
+}
+
+func TestLLMBackend_RegeneratesWithHigherTemperature(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{
+		"As an AI language model I cannot",
+		"Yay, let's play together! 🐾",
+	}}
+	backend := newScriptedBackend(t, LLMConfig{
+		Temperature: 0.6,
+		Validation: ValidationConfig{
+			BannedPhrases:   []string{"as an ai"},
+			TemperatureStep: 0.2,
+		},
+	}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "play", InteractionID: "user-1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(response.Text, "let's play") {
+		t.Errorf("Expected regenerated response, got %q", response.Text)
+	}
+	if model.callCount() != 2 {
+		t.Fatalf("Expected 2 generation attempts, got %d", model.callCount())
+	}
+	if model.calls[1].Temperature <= model.calls[0].Temperature {
+		t.Errorf("Expected retry temperature to increase: %v -> %v", model.calls[0].Temperature, model.calls[1].Temperature)
+	}
+}
+
+func TestLLMBackend_ValidationExhaustedFallsBack(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"bad"}}
+	backend := newScriptedBackend(t, LLMConfig{
+		FallbackEnabled: true,
+		Validation:      ValidationConfig{MinLength: 10, MaxRegenerations: 1},
+	}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click"})
+	if err != nil {
+		t.Fatalf("Expected fallback instead of error, got %v", err)
+	}
+	if response.ResponseType != "fallback" {
+		t.Errorf("Expected fallback response, got %+v", response)
+	}
+	if model.callCount() != 2 {
+		t.Errorf("Expected 1 regeneration (2 attempts), got %d", model.callCount())
+	}
+}
+
+func TestLLMBackend_ValidationExhaustedWithoutFallback(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"bad"}}
+	backend := newScriptedBackend(t, LLMConfig{
+		FallbackEnabled: false,
+		Validation:      ValidationConfig{MinLength: 10, MaxRegenerations: 1},
+	}, model)
+
+	if _, err := backend.GenerateResponse(DialogContext{Trigger: "click"}); err == nil {
+		t.Error("Expected validation error when fallback is disabled")
+	}
+}
+
+func TestLLMBackend_AddValidator(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Meow!", "Woof!"}}
+	backend := newScriptedBackend(t, LLMConfig{}, model)
+
+	backend.AddValidator(ResponseValidatorFunc(func(ctx DialogContext, response string) error {
+		if strings.Contains(response, "Meow") {
+			return fmt.Errorf("wrong species")
+		}
+		return nil
+	}))
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click"})
+	if err != nil || response.Text != "Woof!" {
+		t.Errorf("Expected custom validator to force regeneration, got %q (err %v)", response.Text, err)
+	}
+}
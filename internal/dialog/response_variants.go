@@ -0,0 +1,152 @@
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Variant sampling parameters
+const (
+	variantTemperatureStep = 0.1 // Temperature spread between successive variants
+	variantAttemptFactor   = 2   // Generation attempts allowed per requested variant
+)
+
+// ResponseVariant is one candidate response produced by GenerateResponseVariants
+type ResponseVariant struct {
+	Response    DialogResponse `json:"response"`
+	Score       float64        `json:"score"`       // Higher is better; the confidence, blended with the reranker's score when one is set
+	Temperature float32        `json:"temperature"` // Sampling temperature used
+	Seed        int64          `json:"seed"`        // Sampling seed used
+}
+
+// VariantGenerator is implemented by backends that can produce several candidate responses
+type VariantGenerator interface {
+	GenerateResponseVariants(ctx DialogContext, n int) ([]ResponseVariant, error)
+	AcceptResponse(ctx DialogContext, response DialogResponse)
+}
+
+// GenerateResponseVariants produces up to n distinct candidate responses, each sampled with its
+// own seed and a progressively higher temperature, ordered best first
+// Variants are not added to conversation memory; pass the chosen one to AcceptResponse
+func (llm *LLMBackend) GenerateResponseVariants(ctx DialogContext, n int) ([]ResponseVariant, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("variant count must be positive, got %d", n)
+	}
+	llm.mu.RLock()
+	if !llm.initialized {
+		llm.mu.RUnlock()
+		return nil, fmt.Errorf("LLM backend %w", ErrNotInitialized)
+	}
+	llm.mu.RUnlock()
+
+	prompt := llm.buildPrompt(ctx)
+	baseSeed := int64(randomIntn(1<<30)) + 1
+
+	var variants []ResponseVariant
+	seen := make(map[string]bool)
+	var lastErr error
+	for attempt := 0; attempt < n*variantAttemptFactor && len(variants) < n; attempt++ {
+		opts := llm.samplingOptions()
+		opts.Temperature = min(opts.Temperature+float32(attempt)*variantTemperatureStep, maxRegenerationTemperature)
+		opts.Seed = baseSeed + int64(attempt)
+
+		generation, err := llm.generateVariant(ctx, prompt, opts)
+		if err == nil {
+			_, err = llm.validateGenerated(ctx, generation.text)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimSpace(generation.text))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		response := llm.newDialogResponse(ctx, generation)
+		variants = append(variants, ResponseVariant{
+			Response:    response,
+			Score:       response.Confidence,
+			Temperature: opts.Temperature,
+			Seed:        opts.Seed,
+		})
+	}
+
+	if len(variants) == 0 {
+		if llm.fallbackEnabled {
+			fallback := llm.createFallbackResponse(ctx)
+			return []ResponseVariant{{Response: fallback, Score: fallback.Confidence}}, nil
+		}
+		return nil, fmt.Errorf("failed to generate response variants: %w", lastErr)
+	}
+
+	llm.mu.RLock()
+	reranker := llm.reranker
+	llm.mu.RUnlock()
+	if reranker != nil {
+		responses := make([]DialogResponse, len(variants))
+		for i, variant := range variants {
+			responses[i] = variant.Response
+		}
+		for i, score := range rerankScores(reranker, ctx, responses, defaultRerankWeight) {
+			variants[i].Score = score
+		}
+	}
+
+	sort.SliceStable(variants, func(i, j int) bool {
+		return variants[i].Score > variants[j].Score
+	})
+	return variants, nil
+}
+
+// generateVariant runs one generation with its own timeout, queue slot and health accounting
+func (llm *LLMBackend) generateVariant(ctx DialogContext, prompt string, opts PredictOptions) (generationResult, error) {
+	responseCtx, cancel := context.WithTimeout(ctx.requestContext(), llm.responseTimeout(ctx))
+	defer cancel()
+
+	return llm.scheduleGeneration(responseCtx, ctx.Priority, func() (generationResult, error) {
+		return llm.generateWithRetry(responseCtx, prompt, opts)
+	})
+}
+
+// AcceptResponse records a response chosen from GenerateResponseVariants in conversation memory
+func (llm *LLMBackend) AcceptResponse(ctx DialogContext, response DialogResponse) {
+	llm.recordResponse(ctx, response)
+}
+
+// GenerateDialogVariants asks the default backend for up to n candidate responses, best first
+// Backends without variant support return their single response
+func (dm *DialogManager) GenerateDialogVariants(context DialogContext, n int) ([]ResponseVariant, error) {
+	if generator, ok := dm.defaultVariantGenerator(); ok {
+		if !dm.beginRequest() {
+			return nil, ErrShuttingDown
+		}
+		defer dm.inFlight.Done()
+		return generator.GenerateResponseVariants(context, n)
+	}
+
+	response, err := dm.GenerateDialog(context)
+	if err != nil {
+		return nil, err
+	}
+	return []ResponseVariant{{Response: response, Score: response.Confidence}}, nil
+}
+
+// AcceptDialogResponse records the variant the user kept in the default backend's memory
+func (dm *DialogManager) AcceptDialogResponse(context DialogContext, response DialogResponse) {
+	if generator, ok := dm.defaultVariantGenerator(); ok {
+		generator.AcceptResponse(context, response)
+	}
+}
+
+// defaultVariantGenerator returns the default backend when it supports variants
+func (dm *DialogManager) defaultVariantGenerator() (VariantGenerator, bool) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	generator, ok := dm.backends[dm.defaultBackend].(VariantGenerator)
+	return generator, ok
+}
@@ -0,0 +1,86 @@
+package dialog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLLMBackend_GenerateResponseVariants(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{"Hello there!", "hello there!", "Hi, friend!", "Nice to see you!"}}
+	backend := newScriptedBackend(t, LLMConfig{Temperature: 0.6}, model)
+	context := DialogContext{Trigger: "click", InteractionID: "user-1"}
+
+	variants, err := backend.GenerateResponseVariants(context, 3)
+	if err != nil {
+		t.Fatalf("GenerateResponseVariants() failed: %v", err)
+	}
+	if len(variants) != 3 {
+		t.Fatalf("Expected 3 distinct variants, got %d: %+v", len(variants), variants)
+	}
+
+	texts := make(map[string]bool)
+	seeds := make(map[int64]bool)
+	for i, variant := range variants {
+		texts[variant.Response.Text] = true
+		seeds[variant.Seed] = true
+		if i > 0 && variant.Score > variants[i-1].Score {
+			t.Errorf("Expected variants ordered by score, got %+v", variants)
+		}
+	}
+	if texts["hello there!"] || len(seeds) != 3 {
+		t.Errorf("Expected duplicates dropped and distinct seeds, got %+v", variants)
+	}
+
+	if model.calls[0].Temperature >= model.calls[2].Temperature {
+		t.Errorf("Expected temperature to rise across variants, got %+v", model.calls)
+	}
+
+	if history := backend.contextManager.GetHistory("user-1", 0); len(history) != 0 {
+		t.Errorf("Expected variants to stay out of memory until accepted, got %+v", history)
+	}
+	backend.AcceptResponse(context, variants[1].Response)
+	if history := backend.contextManager.GetHistory("user-1", 0); len(history) != 1 || history[0].Response != variants[1].Response.Text {
+		t.Errorf("Expected the accepted variant in memory, got %+v", history)
+	}
+}
+
+func TestLLMBackend_GenerateResponseVariantsFailures(t *testing.T) {
+	failing := &scriptedTestModel{errors: []error{errors.New("boom"), errors.New("boom"), errors.New("boom"), errors.New("boom")}}
+	backend := newScriptedBackend(t, LLMConfig{FallbackEnabled: false}, failing)
+	if _, err := backend.GenerateResponseVariants(DialogContext{Trigger: "click"}, 2); err == nil {
+		t.Error("Expected an error when every attempt fails without fallback")
+	}
+	if _, err := backend.GenerateResponseVariants(DialogContext{Trigger: "click"}, 0); err == nil {
+		t.Error("Expected a non-positive count to be rejected")
+	}
+
+	withFallback := newScriptedBackend(t, LLMConfig{FallbackEnabled: true}, &scriptedTestModel{errors: failing.errors})
+	variants, err := withFallback.GenerateResponseVariants(DialogContext{Trigger: "click", FallbackResponses: []string{"Hi!"}}, 2)
+	if err != nil || len(variants) != 1 || variants[0].Response.Text == "" {
+		t.Errorf("Expected a single fallback variant, got %+v (%v)", variants, err)
+	}
+}
+
+func TestDialogManager_GenerateDialogVariants(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "One!", "Two!", "Three!")
+	context := DialogContext{Trigger: "click", InteractionID: "pet"}
+
+	variants, err := dm.GenerateDialogVariants(context, 2)
+	if err != nil || len(variants) != 2 {
+		t.Fatalf("Expected 2 variants from the LLM backend, got %+v (%v)", variants, err)
+	}
+	dm.AcceptDialogResponse(context, variants[0].Response)
+
+	backend := dm.backends["llm"].(*LLMBackend)
+	if history := backend.contextManager.GetHistory("pet", 0); len(history) != 1 {
+		t.Errorf("Expected the accepted response recorded, got %+v", history)
+	}
+
+	plain := NewDialogManager(false)
+	// Embedding only the interface hides the LLM backend's variant support
+	plain.RegisterBackend("simple", struct{ DialogBackend }{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Hi!"}})})
+	plain.SetDefaultBackend("simple")
+	if variants, err := plain.GenerateDialogVariants(context, 3); err != nil || len(variants) != 1 {
+		t.Errorf("Expected one response from a backend without variants, got %+v (%v)", variants, err)
+	}
+}
@@ -0,0 +1,131 @@
+package dialog
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RetryConfig configures retries of transient generation failures before fallback
+type RetryConfig struct {
+	MaxAttempts      int     `json:"maxAttempts,omitempty"`      // Total attempts including the first (default: 1, no retries)
+	InitialBackoffMs int     `json:"initialBackoffMs,omitempty"` // Delay before the first retry (default: 50)
+	MaxBackoffMs     int     `json:"maxBackoffMs,omitempty"`     // Upper bound on a single delay (default: 500)
+	Multiplier       float64 `json:"multiplier,omitempty"`       // Backoff growth factor (default: 2)
+	Jitter           float64 `json:"jitter,omitempty"`           // Random spread as a fraction of the delay, 0-1 (default: 0.2)
+	AttemptTimeoutMs int     `json:"attemptTimeoutMs,omitempty"` // Per-attempt timeout (default: 0, use the remaining response timeout)
+}
+
+// retryPolicy is the resolved form of RetryConfig used at generation time
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	attemptTimeout time.Duration
+}
+
+// defaultRetryPolicy returns a policy that performs a single attempt
+func defaultRetryPolicy() retryPolicy {
+	return retryPolicy{
+		maxAttempts:    1,
+		initialBackoff: 50 * time.Millisecond,
+		maxBackoff:     500 * time.Millisecond,
+		multiplier:     2,
+		jitter:         0.2,
+	}
+}
+
+// newRetryPolicy resolves a RetryConfig, applying defaults for unset values
+func newRetryPolicy(cfg RetryConfig) retryPolicy {
+	policy := defaultRetryPolicy()
+
+	if cfg.MaxAttempts > 0 {
+		policy.maxAttempts = cfg.MaxAttempts
+	}
+	if cfg.InitialBackoffMs > 0 {
+		policy.initialBackoff = time.Duration(cfg.InitialBackoffMs) * time.Millisecond
+	}
+	if cfg.MaxBackoffMs > 0 {
+		policy.maxBackoff = time.Duration(cfg.MaxBackoffMs) * time.Millisecond
+	}
+	if cfg.Multiplier >= 1 {
+		policy.multiplier = cfg.Multiplier
+	}
+	if cfg.Jitter > 0 {
+		policy.jitter = math.Min(cfg.Jitter, 1)
+	}
+	if cfg.AttemptTimeoutMs > 0 {
+		policy.attemptTimeout = time.Duration(cfg.AttemptTimeoutMs) * time.Millisecond
+	}
+
+	return policy
+}
+
+// backoff returns the delay before the given retry (1 = first retry), including jitter
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := float64(p.initialBackoff) * math.Pow(p.multiplier, float64(retry-1))
+	if max := float64(p.maxBackoff); delay > max {
+		delay = max
+	}
+
+	if p.jitter > 0 {
+		delay += delay * p.jitter * (randomFloat64()*2 - 1)
+	}
+	return time.Duration(delay)
+}
+
+// generateWithRetry retries transient generation failures with exponential backoff
+// Permanent errors and exhausted response budgets are returned immediately
+func (llm *LLMBackend) generateWithRetry(ctx context.Context, prompt string, opts PredictOptions) (generationResult, error) {
+	policy := llm.retryPolicy
+
+	var lastErr error
+	for attempt := 1; attempt <= policy.maxAttempts; attempt++ {
+		result, err := llm.generateAttempt(ctx, prompt, opts, policy.attemptTimeout)
+		if err == nil {
+			result.retries = attempt - 1
+			return result, nil
+		}
+
+		lastErr = err
+		if !IsTransientError(err) || attempt == policy.maxAttempts {
+			break
+		}
+
+		if !sleepWithContext(ctx, policy.backoff(attempt)) {
+			break
+		}
+	}
+
+	return generationResult{}, lastErr
+}
+
+// generateAttempt runs one generation, bounded by the per-attempt timeout when configured
+func (llm *LLMBackend) generateAttempt(ctx context.Context, prompt string, opts PredictOptions, attemptTimeout time.Duration) (generationResult, error) {
+	if attemptTimeout <= 0 {
+		return llm.generateWithTimeout(ctx, prompt, opts)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+	return llm.generateWithTimeout(attemptCtx, prompt, opts)
+}
+
+// sleepWithContext waits for the delay, returning false if the context ends first
+func sleepWithContext(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
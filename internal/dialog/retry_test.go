@@ -0,0 +1,140 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// temporaryTestError reports itself as transient via Temporary()
+type temporaryTestError struct{}
+
+func (temporaryTestError) Error() string   { return "temporary failure" }
+func (temporaryTestError) Temporary() bool { return true }
+
+func TestIsTransientError(t *testing.T) {
+	testCases := []struct {
+		err       error
+		transient bool
+	}{
+		{nil, false},
+		{fmt.Errorf("wrapped: %w", ErrTimeout), true},
+		{fmt.Errorf("wrapped: %w", ErrBackendBusy), true},
+		{context.DeadlineExceeded, true},
+		{temporaryTestError{}, true},
+		{errors.New("model not initialized"), false},
+	}
+
+	for _, tc := range testCases {
+		if got := IsTransientError(tc.err); got != tc.transient {
+			t.Errorf("IsTransientError(%v) = %v, want %v", tc.err, got, tc.transient)
+		}
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := newRetryPolicy(RetryConfig{InitialBackoffMs: 10, MaxBackoffMs: 35, Multiplier: 2})
+	policy.jitter = 0
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 35 * time.Millisecond}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("Retry %d: expected backoff %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestRetryPolicy_BackoffJitterBounds(t *testing.T) {
+	policy := newRetryPolicy(RetryConfig{InitialBackoffMs: 100, Jitter: 0.5})
+
+	for i := 0; i < 50; i++ {
+		got := policy.backoff(1)
+		if got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Jittered backoff %v outside expected range", got)
+		}
+	}
+}
+
+func TestRetryPolicy_Defaults(t *testing.T) {
+	policy := newRetryPolicy(RetryConfig{})
+	if policy.maxAttempts != 1 {
+		t.Errorf("Expected retries disabled by default, got %d attempts", policy.maxAttempts)
+	}
+}
+
+func TestLLMBackend_RetriesTransientErrors(t *testing.T) {
+	model := &scriptedTestModel{
+		errors:    []error{ErrBackendBusy, fmt.Errorf("slow: %w", ErrTimeout)},
+		responses: []string{"", "", "Finally here! 😊"},
+	}
+	backend := newScriptedBackend(t, LLMConfig{
+		FallbackEnabled: false,
+		Retry:           RetryConfig{MaxAttempts: 3, InitialBackoffMs: 1},
+	}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click"})
+	if err != nil {
+		t.Fatalf("Expected retries to succeed, got %v", err)
+	}
+	if response.Text != "Finally here! 😊" {
+		t.Errorf("Unexpected response %q", response.Text)
+	}
+	if model.callCount() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", model.callCount())
+	}
+}
+
+func TestLLMBackend_DoesNotRetryPermanentErrors(t *testing.T) {
+	model := &scriptedTestModel{
+		errors:    []error{errors.New("model not loaded")},
+		responses: []string{"", "unreachable"},
+	}
+	backend := newScriptedBackend(t, LLMConfig{
+		FallbackEnabled: true,
+		Retry:           RetryConfig{MaxAttempts: 3, InitialBackoffMs: 1},
+	}, model)
+
+	response, err := backend.GenerateResponse(DialogContext{Trigger: "click"})
+	if err != nil {
+		t.Fatalf("Expected fallback, got error %v", err)
+	}
+	if response.ResponseType != "fallback" {
+		t.Errorf("Expected fallback after permanent error, got %+v", response)
+	}
+	if model.callCount() != 1 {
+		t.Errorf("Expected permanent error not to be retried, got %d attempts", model.callCount())
+	}
+}
+
+func TestLLMBackend_RetryStopsAtMaxAttempts(t *testing.T) {
+	model := &scriptedTestModel{
+		errors:    []error{ErrBackendBusy, ErrBackendBusy, ErrBackendBusy, ErrBackendBusy},
+		responses: []string{"never"},
+	}
+	backend := newScriptedBackend(t, LLMConfig{
+		FallbackEnabled: false,
+		Retry:           RetryConfig{MaxAttempts: 2, InitialBackoffMs: 1},
+	}, model)
+
+	_, err := backend.GenerateResponse(DialogContext{Trigger: "click"})
+	if !errors.Is(err, ErrBackendBusy) {
+		t.Errorf("Expected busy error after exhausting retries, got %v", err)
+	}
+	if model.callCount() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", model.callCount())
+	}
+}
+
+func TestSleepWithContext(t *testing.T) {
+	if !sleepWithContext(context.Background(), time.Millisecond) {
+		t.Error("Expected sleep to complete without deadline")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if sleepWithContext(ctx, time.Second) {
+		t.Error("Expected sleep to be skipped when it would exceed the deadline")
+	}
+}
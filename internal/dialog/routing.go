@@ -0,0 +1,21 @@
+package dialog
+
+import "fmt"
+
+// SetTriggerRoutes sends each trigger in routes to the named backend instead of the default
+// Routed responses below the confidence threshold still fall through to the fallback chain;
+// a nil or empty map removes all routes
+func (dm *DialogManager) SetTriggerRoutes(routes map[string]string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	copied := make(map[string]string, len(routes))
+	for trigger, name := range routes {
+		if _, exists := dm.backends[name]; !exists {
+			return fmt.Errorf("backend '%s' for trigger '%s' not registered", name, trigger)
+		}
+		copied[trigger] = name
+	}
+	dm.routes = copied
+	return nil
+}
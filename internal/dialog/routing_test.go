@@ -0,0 +1,68 @@
+package dialog
+
+import "testing"
+
+// newRoutingTestManager registers scripted "llm" (default) and "cheap" backends
+func newRoutingTestManager(t *testing.T) *DialogManager {
+	t.Helper()
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Thoughtful reply"}}))
+	dm.RegisterBackend("cheap", newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Idle chatter"}}))
+	dm.SetDefaultBackend("llm")
+	return dm
+}
+
+func TestDialogManager_SetTriggerRoutesValidation(t *testing.T) {
+	dm := newRoutingTestManager(t)
+	if err := dm.SetTriggerRoutes(map[string]string{"idle": "missing"}); err == nil {
+		t.Error("Expected error for an unregistered backend")
+	}
+	if err := dm.SetTriggerRoutes(nil); err != nil {
+		t.Errorf("Expected nil routes to clear routing, got %v", err)
+	}
+}
+
+func TestDialogManager_TriggerRoutes(t *testing.T) {
+	dm := newRoutingTestManager(t)
+	dm.SetTriggerRoutes(map[string]string{"idle": "cheap"})
+
+	if response, _ := dm.GenerateDialog(DialogContext{Trigger: "idle"}); response.Text != "Idle chatter" {
+		t.Errorf("Expected the routed backend for idle, got %q", response.Text)
+	}
+	if response, _ := dm.GenerateDialog(DialogContext{Trigger: "talk"}); response.Text != "Thoughtful reply" {
+		t.Errorf("Expected the default backend for other triggers, got %q", response.Text)
+	}
+
+	// Routing takes precedence over a running experiment
+	dm.StartExperiment(ExperimentConfig{Name: "x", BackendA: "llm", BackendB: "llm", SplitPercent: 100})
+	if response, _ := dm.GenerateDialog(DialogContext{Trigger: "idle"}); response.Text != "Idle chatter" {
+		t.Errorf("Expected routes to bypass the experiment, got %q", response.Text)
+	}
+}
+
+func TestDialogManager_TriggerRouteFallsBack(t *testing.T) {
+	dm := newRoutingTestManager(t)
+	dm.RegisterBackend("broken", plainTestBackend{NewLLMBackend()})
+	dm.SetTriggerRoutes(map[string]string{"idle": "broken"})
+	dm.SetFallbackChain([]string{"cheap"})
+
+	if response, _ := dm.GenerateDialog(DialogContext{Trigger: "idle"}); response.Text != "Idle chatter" {
+		t.Errorf("Expected the fallback chain when the routed backend cannot answer, got %q", response.Text)
+	}
+}
+
+func TestLoadDialogBackendConfig_TriggerRoutes(t *testing.T) {
+	config, err := LoadDialogBackendConfig([]byte(`{"enabled": true, "defaultBackend": "llm", "triggerRoutes": {"idle": "markov_chain"}}`))
+	if err != nil || config.TriggerRoutes["idle"] != "markov_chain" {
+		t.Errorf("Expected trigger routes loaded, got %+v, %v", config.TriggerRoutes, err)
+	}
+	if _, err := LoadDialogBackendConfig([]byte(`{"enabled": true, "defaultBackend": "llm", "triggerRoutes": {"idle": ""}}`)); err == nil {
+		t.Error("Expected error for a route without a backend")
+	}
+
+	diagnostics := DiagnoseConfig([]byte(`{"enabled": true, "defaultBackend": "markov_chain", "backends": {"markov_chain": {}}, "triggerRoutes": {"talk": "llm"}}`))
+	if findDiagnostic(diagnostics, "$.triggerRoutes.talk") == nil {
+		t.Errorf("Expected a diagnostic for a route to an unconfigured backend, got %+v", diagnostics)
+	}
+}
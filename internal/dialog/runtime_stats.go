@@ -0,0 +1,210 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuntimeStats is a point-in-time view of the process and the dialog system, served
+// at /stats by NewDebugHandler
+type RuntimeStats struct {
+	CollectedAt    time.Time            `json:"collectedAt"`
+	Goroutines     int                  `json:"goroutines"`
+	HeapAllocBytes uint64               `json:"heapAllocBytes"` // Live heap objects
+	HeapInuseBytes uint64               `json:"heapInuseBytes"` // Heap spans in use, including fragmentation
+	SysBytes       uint64               `json:"sysBytes"`       // Memory obtained from the OS by the Go runtime
+	NumGC          uint32               `json:"numGC"`
+	GCPauseTotalMs float64              `json:"gcPauseTotalMs"`
+	QueueDepth     int                  `json:"queueDepth"` // Generations in flight across backends
+	Queued         int                  `json:"queued"`     // Generations waiting for a slot across backends
+	Models         map[string]ModelInfo `json:"models,omitempty"`
+	Backends       []BackendHealth      `json:"backends"`
+	Resources      ResourceStats        `json:"resources"`
+}
+
+// GetModelInfo describes the loaded model, reporting false before one is loaded
+func (llm *LLMBackend) GetModelInfo() (ModelInfo, bool) {
+	llm.mu.RLock()
+	defer llm.mu.RUnlock()
+	if llm.model == nil {
+		return ModelInfo{}, false
+	}
+	return llm.model.GetModelInfo(), true
+}
+
+// RuntimeStats collects goroutine and heap figures with backend load, model details
+// and resource usage; unlike Health it does not run backend health checks
+func (dm *DialogManager) RuntimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	stats := RuntimeStats{
+		CollectedAt:    currentTime(),
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapInuseBytes: mem.HeapInuse,
+		SysBytes:       mem.Sys,
+		NumGC:          mem.NumGC,
+		GCPauseTotalMs: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+		Backends:       []BackendHealth{},
+		Resources:      dm.GetResourceStats(),
+	}
+
+	dm.mu.RLock()
+	backends := make(map[string]DialogBackend, len(dm.backends))
+	for name, backend := range dm.backends {
+		backends[name] = backend
+	}
+	dm.mu.RUnlock()
+
+	for name, backend := range backends {
+		if reporter, ok := backend.(interface{ GetModelInfo() (ModelInfo, bool) }); ok {
+			if info, loaded := reporter.GetModelInfo(); loaded {
+				if stats.Models == nil {
+					stats.Models = make(map[string]ModelInfo)
+				}
+				stats.Models[name] = info
+			}
+		}
+		if reporter, ok := backend.(HealthReporter); ok {
+			health := reporter.GetHealth()
+			health.Name = name
+			stats.QueueDepth += health.QueueDepth
+			stats.Queued += health.Queued
+			stats.Backends = append(stats.Backends, health)
+		}
+	}
+	sort.Slice(stats.Backends, func(i, j int) bool {
+		return stats.Backends[i].Name < stats.Backends[j].Name
+	})
+	return stats
+}
+
+// DebugHandlerOptions controls what NewDebugHandler serves
+type DebugHandlerOptions struct {
+	Pprof bool // Also serve Go profiles under /debug/pprof/ (default: false)
+}
+
+// NewDebugHandler serves dm.RuntimeStats as JSON at /stats and, when enabled, CPU,
+// heap, goroutine and other profiles under /debug/pprof/ in the format go tool pprof
+// reads; mount it on an operator-only listener, since profiles expose internals
+func NewDebugHandler(dm *DialogManager, opts DebugHandlerOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(dm.RuntimeStats())
+	})
+	if opts.Pprof {
+		mux.HandleFunc("/debug/pprof/", servePprofProfile)
+		mux.HandleFunc("/debug/pprof/profile", servePprofCPU)
+		mux.HandleFunc("/debug/pprof/trace", servePprofTrace)
+	}
+	return mux
+}
+
+// Limits for the duration of CPU profiles and execution traces
+const (
+	defaultProfileSeconds = 30
+	maxProfileSeconds     = 300
+)
+
+// profileDuration reads the seconds query parameter used by go tool pprof
+func profileDuration(r *http.Request, fallback int) (time.Duration, error) {
+	value := r.URL.Query().Get("seconds")
+	if value == "" {
+		return time.Duration(fallback) * time.Second, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 || seconds > maxProfileSeconds {
+		return 0, fmt.Errorf("seconds must be between 0 and %d", maxProfileSeconds)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// servePprofProfile lists the runtime profiles, or writes the named one; debug=1 or 2
+// selects the text form instead of the protobuf go tool pprof reads
+func servePprofProfile(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/debug/pprof/")
+	if name == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		profiles := pprof.Profiles()
+		sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name() < profiles[j].Name() })
+		fmt.Fprintf(w, "Profiles (add ?debug=1 for text):\n")
+		for _, profile := range profiles {
+			fmt.Fprintf(w, "  %-14s %d\n", profile.Name(), profile.Count())
+		}
+		fmt.Fprintf(w, "  %-14s CPU profile, ?seconds=%d\n", "profile", defaultProfileSeconds)
+		fmt.Fprintf(w, "  %-14s execution trace, ?seconds=1\n", "trace")
+		return
+	}
+
+	profile := pprof.Lookup(name)
+	if profile == nil {
+		http.Error(w, fmt.Sprintf("unknown profile %q", name), http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if debug > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	if name == "heap" && r.URL.Query().Get("gc") != "" {
+		runtime.GC()
+	}
+	profile.WriteTo(w, debug)
+}
+
+// servePprofCPU records a CPU profile for the requested number of seconds
+func servePprofCPU(w http.ResponseWriter, r *http.Request) {
+	duration, err := profileDuration(r, defaultProfileSeconds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		http.Error(w, fmt.Sprintf("could not start CPU profile: %v", err), http.StatusInternalServerError)
+		return
+	}
+	sleepOrCancel(r, duration)
+	pprof.StopCPUProfile()
+}
+
+// servePprofTrace records an execution trace for the requested number of seconds
+func servePprofTrace(w http.ResponseWriter, r *http.Request) {
+	duration, err := profileDuration(r, 1)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		http.Error(w, fmt.Sprintf("could not start trace: %v", err), http.StatusInternalServerError)
+		return
+	}
+	sleepOrCancel(r, duration)
+	trace.Stop()
+}
+
+// sleepOrCancel waits for duration or until the client goes away
+func sleepOrCancel(r *http.Request, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+}
@@ -0,0 +1,86 @@
+package dialog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDialogManager_RuntimeStats(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello!")
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "sam"})
+
+	stats := dm.RuntimeStats()
+	if stats.Goroutines <= 0 || stats.HeapAllocBytes == 0 {
+		t.Errorf("Expected process figures, got %d goroutines and %d heap bytes", stats.Goroutines, stats.HeapAllocBytes)
+	}
+	if info, ok := stats.Models["llm"]; !ok || info.ModelType != "scripted" {
+		t.Errorf("Expected the scripted model under llm, got %+v", stats.Models)
+	}
+	if len(stats.Backends) != 1 || stats.Backends[0].Name != "llm" || stats.Backends[0].Requests != 1 {
+		t.Errorf("Expected one llm backend with one request, got %+v", stats.Backends)
+	}
+	if stats.Resources.Memory.Conversations != 1 {
+		t.Errorf("Expected 1 conversation in resource stats, got %d", stats.Resources.Memory.Conversations)
+	}
+}
+
+func TestLLMBackend_GetModelInfoBeforeInitialize(t *testing.T) {
+	if _, loaded := NewLLMBackend().GetModelInfo(); loaded {
+		t.Error("Expected no model info before Initialize")
+	}
+}
+
+func TestNewDebugHandler(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello!")
+	server := httptest.NewServer(NewDebugHandler(dm, DebugHandlerOptions{Pprof: true}))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.StatusCode, string(body)
+	}
+
+	status, body := get("/stats")
+	var stats RuntimeStats
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &stats) != nil || stats.Goroutines == 0 {
+		t.Errorf("Expected runtime stats JSON, got %d: %s", status, body)
+	}
+
+	status, body = get("/debug/pprof/")
+	if status != http.StatusOK || !strings.Contains(body, "goroutine") {
+		t.Errorf("Expected the profile index, got %d: %s", status, body)
+	}
+	status, body = get("/debug/pprof/goroutine?debug=1")
+	if status != http.StatusOK || !strings.Contains(body, "goroutine profile") {
+		t.Errorf("Expected a text goroutine profile, got %d: %.200s", status, body)
+	}
+	if status, _ = get("/debug/pprof/missing"); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown profile, got %d", status)
+	}
+	if status, _ = get("/debug/pprof/profile?seconds=-1"); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid duration, got %d", status)
+	}
+	if status, _ = get("/debug/pprof/profile?seconds=0.05"); status != http.StatusOK {
+		t.Errorf("Expected a CPU profile, got %d", status)
+	}
+}
+
+func TestNewDebugHandler_PprofDisabled(t *testing.T) {
+	handler := NewDebugHandler(NewDialogManager(false), DebugHandlerOptions{})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected profiles to be off by default, got %d", recorder.Code)
+	}
+}
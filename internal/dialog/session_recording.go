@@ -0,0 +1,346 @@
+package dialog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionRecord is one backend call captured by a SessionRecorder: the context the
+// backend saw, the prompt it built, what it returned and any feedback given later
+type SessionRecord struct {
+	Seq        int            `json:"seq"`
+	RecordedAt time.Time      `json:"recordedAt"`
+	Backend    string         `json:"backend"`
+	Context    DialogContext  `json:"context"`
+	Prompt     string         `json:"prompt,omitempty"` // Empty for backends that cannot show their prompt
+	Response   DialogResponse `json:"response"`
+	Error      string         `json:"error,omitempty"`
+	LatencyMs  int64          `json:"latencyMs"`
+	Feedback   *UserFeedback  `json:"feedback,omitempty"`
+}
+
+// feedbackLine is appended when feedback arrives for an already recorded call
+type feedbackLine struct {
+	Seq      int           `json:"seq"`
+	Feedback *UserFeedback `json:"feedback"`
+}
+
+// SessionRecorder appends every backend call made by DialogManager.GenerateDialog, and
+// the feedback later given on the responses, to a JSONL stream for ReplaySession
+type SessionRecorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+	seq     int
+	recent  map[string][]recordedResponse // Latest responses per InteractionID, for attaching feedback
+	err     error                         // First write error, reported by Close
+}
+
+// recordedResponse remembers which record produced a response text
+type recordedResponse struct {
+	seq  int
+	text string
+}
+
+// feedbackWindow is how many recent responses per conversation can still receive feedback
+const feedbackWindow = 16
+
+// NewSessionRecorder records to w
+func NewSessionRecorder(w io.Writer) *SessionRecorder {
+	return &SessionRecorder{encoder: json.NewEncoder(w), recent: make(map[string][]recordedResponse)}
+}
+
+// CreateSessionRecording records to a file, appending when it already exists
+func CreateSessionRecording(path string) (*SessionRecorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session recording: %w", err)
+	}
+	recorder := NewSessionRecorder(file)
+	recorder.closer = file
+	return recorder, nil
+}
+
+// Record numbers and writes a record
+func (r *SessionRecorder) Record(record SessionRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	record.Seq = r.seq
+	if record.RecordedAt.IsZero() {
+		record.RecordedAt = currentTime()
+	}
+	id := record.Context.InteractionID
+	r.recent[id] = append(r.recent[id], recordedResponse{seq: record.Seq, text: record.Response.Text})
+	if len(r.recent[id]) > feedbackWindow {
+		r.recent[id] = r.recent[id][1:]
+	}
+	return r.write(record)
+}
+
+// RecordFeedback attaches feedback to the newest recorded call in the context's
+// conversation that produced response; feedback on unrecorded responses is ignored
+func (r *SessionRecorder) RecordFeedback(context DialogContext, response DialogResponse, feedback UserFeedback) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recent := r.recent[context.InteractionID]
+	for i := len(recent) - 1; i >= 0; i-- {
+		if recent[i].text == response.Text {
+			return r.write(feedbackLine{Seq: recent[i].seq, Feedback: &feedback})
+		}
+	}
+	return nil
+}
+
+// write encodes one line, remembering the first failure
+func (r *SessionRecorder) write(line interface{}) error {
+	if err := r.encoder.Encode(line); err != nil {
+		if r.err == nil {
+			r.err = fmt.Errorf("failed to write session recording: %w", err)
+		}
+		return r.err
+	}
+	return nil
+}
+
+// Close closes the file opened by CreateSessionRecording and reports the first write error
+func (r *SessionRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closer != nil {
+		if err := r.closer.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+		r.closer = nil
+	}
+	return r.err
+}
+
+// SetSessionRecorder records every backend call GenerateDialog makes, with the prompt
+// when the backend can show it, and feedback passed to UpdateBackendMemory (nil = off)
+func (dm *DialogManager) SetSessionRecorder(recorder *SessionRecorder) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.recorder = recorder
+}
+
+// recordedPrompt builds the prompt a backend is about to use when calls are recorded
+func (dm *DialogManager) recordedPrompt(backend DialogBackend, context DialogContext) string {
+	dm.mu.RLock()
+	recording := dm.recorder != nil
+	dm.mu.RUnlock()
+	if previewer, ok := backend.(promptPreviewer); recording && ok {
+		return previewer.buildPrompt(context)
+	}
+	return ""
+}
+
+// recordCall records one backend call when a recorder is set; its prompt is built
+// before the call, since generating adds the exchange to the backend's history
+func (dm *DialogManager) recordCall(name string, context DialogContext, prompt string, response DialogResponse, latency time.Duration, err error) {
+	dm.mu.RLock()
+	recorder := dm.recorder
+	dm.mu.RUnlock()
+	if recorder == nil {
+		return
+	}
+
+	record := SessionRecord{
+		Backend:   name,
+		Context:   context,
+		Prompt:    prompt,
+		Response:  response,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	recorder.Record(record)
+}
+
+// LoadSessionRecording reads a JSONL session recording, attaching feedback lines to the
+// records they refer to
+func LoadSessionRecording(path string) ([]SessionRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session recording: %w", err)
+	}
+	defer file.Close()
+
+	var records []SessionRecord
+	index := make(map[int]int) // Seq to position in records
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record SessionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse %s line %d: %w", path, line, err)
+		}
+		if record.Backend == "" && record.Feedback != nil {
+			if i, ok := index[record.Seq]; ok {
+				records[i].Feedback = record.Feedback
+			}
+			continue
+		}
+		index[record.Seq] = len(records)
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// ReplayTurn compares a recorded call with the response the replayed backend gives
+type ReplayTurn struct {
+	Record   SessionRecord
+	Prompt   string // Prompt the replayed backend built, when it can show it
+	Response DialogResponse
+	Err      error
+	Latency  time.Duration
+}
+
+// Changed reports whether the replayed response text differs from the recorded one
+func (t ReplayTurn) Changed() bool {
+	return t.Response.Text != t.Record.Response.Text || (t.Err != nil) != (t.Record.Error != "")
+}
+
+// SessionReplay is the outcome of replaying a recording against a backend
+type SessionReplay struct {
+	Turns []ReplayTurn
+}
+
+// ReplaySummary aggregates a SessionReplay for before/after comparison
+type ReplaySummary struct {
+	Turns           int           `json:"turns"`
+	Changed         int           `json:"changed"`        // Responses whose text changed
+	PromptsChanged  int           `json:"promptsChanged"` // Prompts that differ, where both are known
+	ErrorsBefore    int           `json:"errorsBefore"`
+	ErrorsAfter     int           `json:"errorsAfter"`
+	LikedChanged    int           `json:"likedChanged"`    // Changed responses the user had liked
+	DislikedChanged int           `json:"dislikedChanged"` // Changed responses the user had disliked
+	LatencyBefore   time.Duration `json:"latencyBefore"`   // Mean recorded latency
+	LatencyAfter    time.Duration `json:"latencyAfter"`    // Mean replayed latency
+}
+
+// ReplaySession sends each recorded context to backend in order, so conversation history
+// builds up as it did when recorded, with the package clock held at each context's
+// timestamp; the system clock is restored afterwards
+func ReplaySession(backend DialogBackend, records []SessionRecord) SessionReplay {
+	clock := NewManualClock(currentTime())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	previewer, _ := backend.(promptPreviewer)
+	var replay SessionReplay
+	for _, record := range records {
+		if !record.Context.Timestamp.IsZero() {
+			clock.Set(record.Context.Timestamp)
+		}
+		turn := ReplayTurn{Record: record}
+		if previewer != nil {
+			turn.Prompt = previewer.buildPrompt(record.Context)
+		}
+		start := time.Now()
+		turn.Response, turn.Err = backend.GenerateResponse(record.Context)
+		turn.Latency = time.Since(start)
+		replay.Turns = append(replay.Turns, turn)
+	}
+	return replay
+}
+
+// Summary counts what changed between the recording and the replay
+func (r SessionReplay) Summary() ReplaySummary {
+	summary := ReplaySummary{Turns: len(r.Turns)}
+	if len(r.Turns) == 0 {
+		return summary
+	}
+
+	var before, after time.Duration
+	for _, turn := range r.Turns {
+		before += time.Duration(turn.Record.LatencyMs) * time.Millisecond
+		after += turn.Latency
+		if turn.Record.Error != "" {
+			summary.ErrorsBefore++
+		}
+		if turn.Err != nil {
+			summary.ErrorsAfter++
+		}
+		if turn.Prompt != "" && turn.Record.Prompt != "" && turn.Prompt != turn.Record.Prompt {
+			summary.PromptsChanged++
+		}
+		if !turn.Changed() {
+			continue
+		}
+		summary.Changed++
+		if feedback := turn.Record.Feedback; feedback != nil {
+			if feedback.Positive {
+				summary.LikedChanged++
+			} else {
+				summary.DislikedChanged++
+			}
+		}
+	}
+	summary.LatencyBefore = before / time.Duration(len(r.Turns))
+	summary.LatencyAfter = after / time.Duration(len(r.Turns))
+	return summary
+}
+
+// Report renders each turn's recorded and replayed response side by side, followed by
+// the summary, in a stable diff-friendly form
+func (r SessionReplay) Report() string {
+	var out strings.Builder
+	for _, turn := range r.Turns {
+		marker := "="
+		if turn.Changed() {
+			marker = "~"
+		}
+		fmt.Fprintf(&out, "%s #%d %s (%s)", marker, turn.Record.Seq, turn.Record.Context.Trigger, turn.Record.Context.InteractionID)
+		if feedback := turn.Record.Feedback; feedback != nil {
+			if feedback.Positive {
+				out.WriteString(" [liked]")
+			} else {
+				out.WriteString(" [disliked]")
+			}
+		}
+		out.WriteString("\n")
+		if turn.Record.Context.UserMessage != "" {
+			fmt.Fprintf(&out, "  user:   %s\n", turn.Record.Context.UserMessage)
+		}
+		fmt.Fprintf(&out, "  before: %s\n", describeReplayResponse(turn.Record.Response.Text, turn.Record.Error))
+		if turn.Changed() {
+			errText := ""
+			if turn.Err != nil {
+				errText = turn.Err.Error()
+			}
+			fmt.Fprintf(&out, "  after:  %s\n", describeReplayResponse(turn.Response.Text, errText))
+		}
+	}
+
+	s := r.Summary()
+	fmt.Fprintf(&out, "\n%d turns, %d changed (%d liked, %d disliked), %d prompts changed\n",
+		s.Turns, s.Changed, s.LikedChanged, s.DislikedChanged, s.PromptsChanged)
+	fmt.Fprintf(&out, "errors %d -> %d, mean latency %v -> %v\n",
+		s.ErrorsBefore, s.ErrorsAfter, s.LatencyBefore.Round(time.Millisecond), s.LatencyAfter.Round(time.Millisecond))
+	return out.String()
+}
+
+// describeReplayResponse shows a response's text, or its error
+func describeReplayResponse(text, err string) string {
+	if err != "" {
+		return "error: " + err
+	}
+	return text
+}
@@ -0,0 +1,110 @@
+package dialog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionRecordingAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recorder, err := CreateSessionRecording(path)
+	if err != nil {
+		t.Fatalf("CreateSessionRecording() failed: %v", err)
+	}
+
+	recorded := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{
+		responses: []string{"Yay, snacks are the best!", "I missed you so much!"},
+	})
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", recorded)
+	dm.SetDefaultBackend("llm")
+	dm.SetSessionRecorder(recorder)
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	contexts := []DialogContext{
+		{Trigger: "feed", InteractionID: "s1", Timestamp: start, FallbackResponses: []string{"Hi"}},
+		{Trigger: "chat", InteractionID: "s1", UserMessage: "I'm back", Timestamp: start.Add(time.Minute), FallbackResponses: []string{"Hi"}},
+	}
+	var responses []DialogResponse
+	for _, ctx := range contexts {
+		response, err := dm.GenerateDialog(ctx)
+		if err != nil {
+			t.Fatalf("GenerateDialog() failed: %v", err)
+		}
+		responses = append(responses, response)
+	}
+	dm.UpdateBackendMemory(contexts[0], responses[0], &UserFeedback{Positive: false})
+	dm.UpdateBackendMemory(contexts[1], responses[1], &UserFeedback{Positive: true})
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	records, err := LoadSessionRecording(path)
+	if err != nil {
+		t.Fatalf("LoadSessionRecording() failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0].Backend != "llm" || records[0].Seq != 1 {
+		t.Errorf("Expected seq 1 from llm, got seq %d from %q", records[0].Seq, records[0].Backend)
+	}
+	if records[0].Response.Text != responses[0].Text {
+		t.Errorf("Expected recorded response %q, got %q", responses[0].Text, records[0].Response.Text)
+	}
+	if !strings.Contains(records[1].Prompt, "I'm back") {
+		t.Errorf("Expected recorded prompt to include the user message, got %q", records[1].Prompt)
+	}
+	if records[0].Feedback == nil || records[0].Feedback.Positive {
+		t.Errorf("Expected negative feedback on the first record, got %+v", records[0].Feedback)
+	}
+	if records[1].Feedback == nil || !records[1].Feedback.Positive {
+		t.Errorf("Expected positive feedback on the second record, got %+v", records[1].Feedback)
+	}
+
+	// Replay against a model that only improves the disliked answer
+	candidate := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{
+		responses: []string{"Mmm, thank you for the snack!", "I missed you so much!"},
+	})
+	replay := ReplaySession(candidate, records)
+	if len(replay.Turns) != 2 {
+		t.Fatalf("Expected 2 replayed turns, got %d", len(replay.Turns))
+	}
+	if !replay.Turns[0].Changed() || replay.Turns[1].Changed() {
+		t.Errorf("Expected only the first turn to change, got %v and %v", replay.Turns[0].Changed(), replay.Turns[1].Changed())
+	}
+	if !strings.Contains(replay.Turns[1].Prompt, "Mmm, thank you for the snack!") {
+		t.Errorf("Expected the replayed history to carry the new response, got %q", replay.Turns[1].Prompt)
+	}
+
+	summary := replay.Summary()
+	if summary.Turns != 2 || summary.Changed != 1 || summary.DislikedChanged != 1 || summary.LikedChanged != 0 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if summary.PromptsChanged != 1 {
+		t.Errorf("Expected the second prompt to change with the history, got %d", summary.PromptsChanged)
+	}
+
+	report := replay.Report()
+	for _, want := range []string{"~ #1 feed (s1) [disliked]", "after:  Mmm, thank you for the snack!", "= #2 chat (s1) [liked]", "2 turns, 1 changed"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+}
+
+func TestSessionRecorderIgnoresUnknownFeedback(t *testing.T) {
+	var out strings.Builder
+	recorder := NewSessionRecorder(&out)
+
+	ctx := DialogContext{Trigger: "click", InteractionID: "a"}
+	recorder.Record(SessionRecord{Backend: "llm", Context: ctx, Response: DialogResponse{Text: "Hi!"}})
+	recorder.RecordFeedback(ctx, DialogResponse{Text: "Something else"}, UserFeedback{Positive: true})
+	recorder.RecordFeedback(DialogContext{InteractionID: "b"}, DialogResponse{Text: "Hi!"}, UserFeedback{Positive: true})
+
+	if lines := strings.Count(out.String(), "\n"); lines != 1 {
+		t.Errorf("Expected only the record to be written, got %d lines:\n%s", lines, out.String())
+	}
+}
@@ -0,0 +1,69 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// beginRequest registers an in-flight request, returning false once shutdown has started
+func (dm *DialogManager) beginRequest() bool {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	if dm.closing {
+		return false
+	}
+	dm.inFlight.Add(1)
+	return true
+}
+
+// Shutdown stops accepting requests, waits for in-flight generations to finish and
+// closes every registered backend that implements Close
+// If ctx expires before generations drain, backends are left open and ctx.Err() is returned
+func (dm *DialogManager) Shutdown(ctx context.Context) error {
+	dm.mu.Lock()
+	dm.closing = true
+	dm.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		dm.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown interrupted before in-flight requests finished: %w", ctx.Err())
+	}
+
+	return dm.closeBackends()
+}
+
+// closeBackends closes all registered backends in name order, joining any errors
+func (dm *DialogManager) closeBackends() error {
+	dm.mu.RLock()
+	names := make([]string, 0, len(dm.backends))
+	backends := make(map[string]DialogBackend, len(dm.backends))
+	for name, backend := range dm.backends {
+		names = append(names, name)
+		backends[name] = backend
+	}
+	dm.mu.RUnlock()
+
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		closer, ok := backends[name].(interface{ Close() error })
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close backend '%s': %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package dialog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingTestModel blocks every prediction until released
+type blockingTestModel struct {
+	*scriptedTestModel
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingTestModel) PredictWithOptions(ctx context.Context, prompt string, opts PredictOptions) (string, error) {
+	m.started <- struct{}{}
+	<-m.release
+	return m.scriptedTestModel.PredictWithOptions(ctx, prompt, opts)
+}
+
+func TestDialogManager_ShutdownDrainsAndCloses(t *testing.T) {
+	model := &blockingTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Goodbye!"}},
+		started:           make(chan struct{}, 1),
+		release:           make(chan struct{}),
+	}
+	backend := newScriptedBackend(t, LLMConfig{TimeoutMs: 5000}, model.scriptedTestModel)
+	backend.model = model
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+
+	var wg sync.WaitGroup
+	var inFlight DialogResponse
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		inFlight, _ = dm.GenerateDialog(DialogContext{Trigger: "click"})
+	}()
+	<-model.started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- dm.Shutdown(context.Background()) }()
+
+	// New requests are rejected while draining
+	time.Sleep(20 * time.Millisecond)
+	if _, err := dm.GenerateDialog(DialogContext{Trigger: "click"}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown, got %v", err)
+	}
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned before in-flight request finished: %v", err)
+	default:
+	}
+
+	close(model.release)
+	wg.Wait()
+
+	if err := <-shutdownErr; err != nil {
+		t.Errorf("Expected clean shutdown, got %v", err)
+	}
+	if inFlight.Text != "Goodbye!" {
+		t.Errorf("Expected in-flight request to complete, got %q", inFlight.Text)
+	}
+	if err := backend.HealthCheck(context.Background()); err == nil {
+		t.Error("Expected backend to be closed after shutdown")
+	}
+}
+
+func TestDialogManager_ShutdownContextExpires(t *testing.T) {
+	model := &blockingTestModel{
+		scriptedTestModel: &scriptedTestModel{responses: []string{"Still here"}},
+		started:           make(chan struct{}, 1),
+		release:           make(chan struct{}),
+	}
+	backend := newScriptedBackend(t, LLMConfig{TimeoutMs: 5000}, model.scriptedTestModel)
+	backend.model = model
+
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+
+	done := make(chan struct{})
+	go func() {
+		dm.GenerateDialog(DialogContext{Trigger: "click"})
+		close(done)
+	}()
+	<-model.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := dm.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if err := backend.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Expected backend to stay open after interrupted shutdown, got %v", err)
+	}
+
+	close(model.release)
+	<-done
+}
+
+func TestContextManager_CloseIsIdempotent(t *testing.T) {
+	cm := NewContextManagerWithConfig(5, 0, time.Millisecond, time.Hour)
+	cm.Close()
+	cm.Close()
+}
@@ -0,0 +1,167 @@
+package dialog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SpeechHints tell a text-to-speech engine how to say a response
+type SpeechHints struct {
+	Text     string   `json:"text"`               // What to say: the response without emoji or *actions*
+	SSML     string   `json:"ssml"`               // Text as SSML with prosody and emphasis applied
+	Voice    string   `json:"voice,omitempty"`    // Suggested voice
+	Emotion  string   `json:"emotion,omitempty"`  // Suggested speaking emotion, from the response's EmotionalTone
+	Rate     float64  `json:"rate"`               // Speaking rate (1 = normal)
+	Pitch    float64  `json:"pitch"`              // Pitch relative to the voice's own (1 = normal)
+	Emphasis []string `json:"emphasis,omitempty"` // Words to stress, in order
+}
+
+// SpeechConfig picks voices for speech hints
+type SpeechConfig struct {
+	Voice  string            `json:"voice,omitempty"`  // Voice for every response
+	Voices map[string]string `json:"voices,omitempty"` // Voice per EmotionalTone, overriding Voice
+}
+
+// TextToSpeech speaks responses; implementations wrap a host's speech engine
+// Speak should queue the audio and return promptly, since generation waits for it
+type TextToSpeech interface {
+	Speak(hints SpeechHints) error
+}
+
+// toneProsody adjusts rate and pitch for an emotional tone
+type toneProsody struct {
+	rate, pitch float64
+}
+
+// speechProsody maps EmotionalTone values to how they are spoken; other tones use 1, 1
+var speechProsody = map[string]toneProsody{
+	"excited": {rate: 1.15, pitch: 1.1},
+	"happy":   {rate: 1.05, pitch: 1.05},
+	"flirty":  {rate: 0.95, pitch: 1.0},
+	"shy":     {rate: 0.9, pitch: 1.05},
+	"sad":     {rate: 0.85, pitch: 0.9},
+}
+
+// speechMarkup matches *starred* or _underscored_ spans and ALL-CAPS words
+var speechMarkup = regexp.MustCompile(`\*([^*]+)\*|_([^_\s][^_]*)_|\b[A-Z]{2,}\b`)
+
+// DeriveSpeechHints works out how to say a response from its text and EmotionalTone
+// A single *starred* or _underscored_ word or an ALL-CAPS word is stressed; a starred
+// phrase such as "*wags tail*" is an action and is left unspoken, as are emoji
+func DeriveSpeechHints(response DialogResponse, config SpeechConfig) SpeechHints {
+	prosody, ok := speechProsody[response.EmotionalTone]
+	if !ok {
+		prosody = toneProsody{rate: 1, pitch: 1}
+	}
+	hints := SpeechHints{
+		Voice:   config.Voice,
+		Emotion: response.EmotionalTone,
+		Rate:    prosody.rate,
+		Pitch:   prosody.pitch,
+	}
+	if voice, ok := config.Voices[response.EmotionalTone]; ok {
+		hints.Voice = voice
+	}
+
+	var text, ssml strings.Builder
+	last := 0
+	for _, match := range speechMarkup.FindAllStringSubmatchIndex(response.Text, -1) {
+		text.WriteString(response.Text[last:match[0]])
+		ssml.WriteString(escapeSSML(response.Text[last:match[0]]))
+		last = match[1]
+
+		word := response.Text[match[0]:match[1]]
+		switch {
+		case match[2] >= 0:
+			word = response.Text[match[2]:match[3]]
+		case match[4] >= 0:
+			word = response.Text[match[4]:match[5]]
+		}
+		if strings.ContainsAny(strings.TrimSpace(word), " \t") {
+			continue // A starred phrase is a stage direction, not speech
+		}
+		hints.Emphasis = append(hints.Emphasis, word)
+		text.WriteString(word)
+		ssml.WriteString("<emphasis>" + escapeSSML(word) + "</emphasis>")
+	}
+	text.WriteString(response.Text[last:])
+	ssml.WriteString(escapeSSML(response.Text[last:]))
+
+	hints.Text = cleanSpokenText(text.String())
+	hints.SSML = fmt.Sprintf(`<speak><prosody rate="%s" pitch="%s">%s</prosody></speak>`,
+		relativePercent(hints.Rate, false), relativePercent(hints.Pitch, true), cleanSpokenText(ssml.String()))
+	return hints
+}
+
+// cleanSpokenText drops emoji (with their joiners and variation selectors) and
+// collapses the spacing they leave
+func cleanSpokenText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) || r == '\u200d' || r == '\ufe0f' {
+			return -1
+		}
+		return r
+	}, text)
+	text = strings.Join(strings.Fields(text), " ")
+	for _, punctuation := range []string{" .", " ,", " !", " ?"} {
+		text = strings.ReplaceAll(text, punctuation, punctuation[1:])
+	}
+	return text
+}
+
+// escapeSSML escapes the characters that are special in SSML text
+func escapeSSML(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// relativePercent renders a multiplier as an SSML value: "115%" for a rate, "+10%" for a pitch
+func relativePercent(value float64, signed bool) string {
+	if !signed {
+		return fmt.Sprintf("%.0f%%", value*100)
+	}
+	return fmt.Sprintf("%+.0f%%", (value-1)*100)
+}
+
+// speechHook adds speech hints to each response and hands it to the host's engine
+type speechHook struct {
+	config SpeechConfig
+	tts    TextToSpeech
+	events *EventBus
+}
+
+// wrap fills in Speech for responses that lack it and speaks them when an engine is set
+func (h *speechHook) wrap(next DialogHandler) DialogHandler {
+	return func(context DialogContext) (DialogResponse, error) {
+		response, err := next(context)
+		if err != nil || response.Text == "" {
+			return response, err
+		}
+		if response.Speech == nil {
+			hints := DeriveSpeechHints(response, h.config)
+			response.Speech = &hints
+		}
+		if h.tts != nil {
+			if speakErr := h.tts.Speak(*response.Speech); speakErr != nil {
+				h.events.Publish(DialogEvent{
+					Type:          EventSpeechError,
+					InteractionID: context.InteractionID,
+					Trigger:       context.Trigger,
+					Response:      &response,
+					Error:         speakErr,
+				})
+			}
+		}
+		return response, err
+	}
+}
+
+// SetSpeech makes GenerateDialog attach speech hints to responses and, when tts is
+// not nil, speak them; speaking errors are published as EventSpeechError
+// Pass a nil tts to only attach hints for the host to use
+func (dm *DialogManager) SetSpeech(config SpeechConfig, tts TextToSpeech) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.speech = &speechHook{config: config, tts: tts, events: dm.events}
+}
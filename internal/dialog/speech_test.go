@@ -0,0 +1,70 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recordingSpeaker collects the hints it is asked to speak
+type recordingSpeaker struct {
+	spoken []SpeechHints
+	err    error
+}
+
+func (s *recordingSpeaker) Speak(hints SpeechHints) error {
+	s.spoken = append(s.spoken, hints)
+	return s.err
+}
+
+func TestDeriveSpeechHints(t *testing.T) {
+	config := SpeechConfig{Voice: "soft", Voices: map[string]string{"excited": "bright"}}
+	hints := DeriveSpeechHints(DialogResponse{Text: "*wags tail* You're BACK! I *really* missed you 🐾💕", EmotionalTone: "excited"}, config)
+
+	if hints.Text != "You're BACK! I really missed you" {
+		t.Errorf("Expected spoken text without actions or emoji, got %q", hints.Text)
+	}
+	if strings.Join(hints.Emphasis, ",") != "BACK,really" {
+		t.Errorf("Expected stressed words, got %v", hints.Emphasis)
+	}
+	if hints.Voice != "bright" || hints.Emotion != "excited" || hints.Rate != 1.15 || hints.Pitch != 1.1 {
+		t.Errorf("Expected excited delivery, got %+v", hints)
+	}
+	expected := `<speak><prosody rate="115%" pitch="+10%">You're <emphasis>BACK</emphasis>! I <emphasis>really</emphasis> missed you</prosody></speak>`
+	if hints.SSML != expected {
+		t.Errorf("Expected SSML %s, got %s", expected, hints.SSML)
+	}
+
+	hints = DeriveSpeechHints(DialogResponse{Text: "Oh... <3 & hugs", EmotionalTone: "sad"}, config)
+	if hints.Voice != "soft" || hints.Rate != 0.85 || !strings.Contains(hints.SSML, `rate="85%" pitch="-10%">Oh... &lt;3 &amp; hugs<`) {
+		t.Errorf("Expected escaped, slower sad delivery, got %+v", hints)
+	}
+	if hints := DeriveSpeechHints(DialogResponse{Text: "Hello"}, SpeechConfig{}); hints.Rate != 1 || hints.Pitch != 1 {
+		t.Errorf("Expected neutral prosody for an unknown tone, got %+v", hints)
+	}
+}
+
+func TestDialogManager_SetSpeech(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Yay, snacks!", "Again?")
+	speaker := &recordingSpeaker{}
+	dm.SetSpeech(SpeechConfig{Voice: "kid"}, speaker)
+
+	response, _ := dm.GenerateDialog(DialogContext{Trigger: "feed", InteractionID: "pet"})
+	if response.Speech == nil || response.Speech.Text != "Yay, snacks!" || response.Speech.Voice != "kid" {
+		t.Fatalf("Expected speech hints on the response, got %+v", response.Speech)
+	}
+	if len(speaker.spoken) != 1 || speaker.spoken[0].Text != "Yay, snacks!" {
+		t.Errorf("Expected the response spoken, got %+v", speaker.spoken)
+	}
+
+	var failures []DialogEvent
+	dm.Events().Subscribe(func(event DialogEvent) { failures = append(failures, event) }, EventSpeechError)
+	speaker.err = fmt.Errorf("audio device busy")
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "feed", InteractionID: "pet"})
+	if err != nil || response.Text != "Again?" {
+		t.Errorf("Expected speech failures not to fail generation, got %q, %v", response.Text, err)
+	}
+	if len(failures) != 1 || failures[0].Error == nil {
+		t.Errorf("Expected a speech error event, got %+v", failures)
+	}
+}
@@ -0,0 +1,43 @@
+package dialog
+
+import "time"
+
+// ConversationStarterTrigger is the trigger GenerateConversationStarter generates for
+const ConversationStarterTrigger = "conversation_starter"
+
+// starterGreetings are fallback opening lines by time of day
+var starterGreetings = map[string][]string{
+	"morning":   {"Good morning! ☀️ Ready for today?", "Morning! Did you sleep well?"},
+	"afternoon": {"Good afternoon! How's your day going?", "Hey, welcome back! 😊"},
+	"evening":   {"Good evening! How was your day?", "Welcome back! Time to relax? 🌙"},
+	"night":     {"Still up? I missed you! 🌙", "Hi there, night owl! 🦉"},
+}
+
+// GenerateConversationStarter produces an opening line for when the user returns,
+// e.g. on desktop unlock, tailored to mood, time of day and recent history
+// Empty temporal fields are filled from the clock and InteractionHistory, and
+// time-of-day greetings are used as fallbacks when the context provides none
+func (dm *DialogManager) GenerateConversationStarter(context DialogContext) (DialogResponse, error) {
+	now := context.Timestamp
+	if now.IsZero() {
+		now = currentTime()
+	}
+	context = EnrichTemporalContext(context, now, time.Time{})
+	context.Trigger = ConversationStarterTrigger
+	if len(context.FallbackResponses) == 0 {
+		context.FallbackResponses = starterGreetings[context.TimeOfDay]
+	}
+	return dm.GenerateDialog(context)
+}
+
+// buildStarterInstructions asks for an opening line instead of a reaction
+func (pb *PromptBuilder) buildStarterInstructions() string {
+	if pb.context.Trigger != ConversationStarterTrigger {
+		return ""
+	}
+	instruction := "- Open the conversation: greet the user in a way that fits the time of day and your mood"
+	if len(pb.history) > 0 {
+		instruction += ", and mention something from your recent conversations"
+	}
+	return instruction + "\n"
+}
@@ -0,0 +1,73 @@
+package dialog
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateConversationStarter(t *testing.T) {
+	dm, model := newRateLimitTestManager(t, "Morning! Still thinking about our walk yesterday 🐾")
+	var seen DialogContext
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		seen = *context
+		return nil, nil
+	}))
+
+	morning := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	response, err := dm.GenerateConversationStarter(DialogContext{
+		Trigger:       "click",
+		InteractionID: "pet",
+		Timestamp:     morning,
+		CurrentMood:   75,
+		InteractionHistory: []InteractionRecord{
+			{Type: "play", Timestamp: morning.Add(-14 * time.Hour)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if response.Text != "Morning! Still thinking about our walk yesterday 🐾" || model.callCount() != 1 {
+		t.Errorf("Expected the generated opening line, got %q", response.Text)
+	}
+	if seen.Trigger != ConversationStarterTrigger || seen.TimeOfDay != "morning" || seen.IdleDuration != 14*time.Hour {
+		t.Errorf("Expected a starter context with temporal grounding, got %+v", seen)
+	}
+	if !slices.Equal(seen.FallbackResponses, starterGreetings["morning"]) {
+		t.Errorf("Expected morning greetings as fallbacks, got %v", seen.FallbackResponses)
+	}
+}
+
+func TestGenerateConversationStarter_Fallback(t *testing.T) {
+	dm := NewDialogManager(false)
+	response, _ := dm.GenerateConversationStarter(DialogContext{InteractionID: "pet", Timestamp: time.Date(2024, 6, 3, 23, 0, 0, 0, time.UTC)})
+	if !slices.Contains(starterGreetings["night"], response.Text) {
+		t.Errorf("Expected a night greeting without a backend, got %q", response.Text)
+	}
+
+	host := []string{"Welcome home!"}
+	response, _ = dm.GenerateConversationStarter(DialogContext{InteractionID: "pet", FallbackResponses: host})
+	if response.Text != "Welcome home!" {
+		t.Errorf("Expected the host's fallback kept, got %q", response.Text)
+	}
+}
+
+func TestPromptBuilder_StarterInstructions(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{Trigger: ConversationStarterTrigger, TimeOfDay: "evening"})
+	prompt := pb.Build()
+	if !strings.Contains(prompt, "- Open the conversation: greet the user in a way that fits the time of day and your mood\n") {
+		t.Errorf("Expected starter instructions, got:\n%s", prompt)
+	}
+
+	pb.AddHistory([]ConversationExchange{{Trigger: "feed", Response: "Yum!", Timestamp: time.Now()}})
+	if prompt := pb.Build(); !strings.Contains(prompt, "mention something from your recent conversations") {
+		t.Errorf("Expected history to be referenced when available, got:\n%s", prompt)
+	}
+
+	pb.AddContext(DialogContext{Trigger: "click"})
+	if prompt := pb.Build(); strings.Contains(prompt, "Open the conversation") {
+		t.Errorf("Expected no starter instructions for other triggers, got:\n%s", prompt)
+	}
+}
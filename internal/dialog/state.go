@@ -0,0 +1,206 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Checkpoint format versions; LoadState rejects data written by newer versions
+const (
+	ContextStateVersion = 1 // Written by ContextManager.SaveState
+	DialogStateVersion  = 1 // Written by DialogManager.SaveState
+)
+
+// ContextState is a checkpoint of every conversation held by a ContextManager
+type ContextState struct {
+	Version       int                   `json:"version"`
+	SavedAt       time.Time             `json:"savedAt"`
+	Conversations []ConversationHistory `json:"conversations"`
+}
+
+// DialogState is a checkpoint of every registered backend, keyed by backend name
+type DialogState struct {
+	Version  int                        `json:"version"`
+	SavedAt  time.Time                  `json:"savedAt"`
+	Backends map[string]json.RawMessage `json:"backends"`
+}
+
+// SaveState serializes all conversations, including feedback and importance, in interaction ID order
+func (cm *ContextManager) SaveState() ([]byte, error) {
+	cm.rlockAll()
+	state := ContextState{
+		Version:       ContextStateVersion,
+		SavedAt:       currentTime(),
+		Conversations: make([]ConversationHistory, 0, cm.conversationCount()),
+	}
+	for _, history := range cm.allConversations() {
+		state.Conversations = append(state.Conversations, copyConversationHistory(history))
+	}
+	cm.runlockAll()
+
+	sort.Slice(state.Conversations, func(i, j int) bool {
+		return state.Conversations[i].InteractionID < state.Conversations[j].InteractionID
+	})
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal context state: %w", err)
+	}
+	return data, nil
+}
+
+// LoadState replaces all conversations with those from SaveState
+// Conversations are trimmed to this manager's limits, keeping the most recently updated
+func (cm *ContextManager) LoadState(data []byte) error {
+	var state ContextState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse context state: %w", err)
+	}
+	if state.Version <= 0 {
+		return fmt.Errorf("context state is missing a version")
+	}
+	if state.Version > ContextStateVersion {
+		return fmt.Errorf("unsupported context state version %d (max %d)", state.Version, ContextStateVersion)
+	}
+	for i, history := range state.Conversations {
+		if history.InteractionID == "" {
+			return fmt.Errorf("context state conversation %d is missing an interactionId", i)
+		}
+	}
+
+	// Restore oldest first so capacity eviction drops the least recent conversations
+	sort.SliceStable(state.Conversations, func(i, j int) bool {
+		return state.Conversations[i].LastUpdated.Before(state.Conversations[j].LastUpdated)
+	})
+
+	cm.lockAll()
+	defer cm.flushEvents()
+	defer cm.unlockAll()
+
+	if cm.closed() {
+		return fmt.Errorf("context manager is closed")
+	}
+
+	cm.resetConversations(false)
+	for _, history := range state.Conversations {
+		cm.restoreConversation(history)
+	}
+	return nil
+}
+
+// SaveState checkpoints the conversation history, including the feedback this backend learns from
+// Each character's isolated history is saved under its CharacterID, and each tenant's under its TenantID
+func (llm *LLMBackend) SaveState() ([]byte, error) {
+	data, err := llm.contextManager.SaveState()
+	if err != nil {
+		return nil, err
+	}
+	characters, err := llm.saveCharacterStates()
+	if err != nil {
+		return nil, err
+	}
+	tenants, err := llm.saveTenantStates()
+	if err != nil {
+		return nil, err
+	}
+	if len(characters) == 0 && len(tenants) == 0 {
+		return data, nil
+	}
+
+	state := llmBackendState{Characters: characters, Tenants: tenants}
+	if err := json.Unmarshal(data, &state.ContextState); err != nil {
+		return nil, fmt.Errorf("failed to parse context state: %w", err)
+	}
+	data, err = json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal backend state: %w", err)
+	}
+	return data, nil
+}
+
+// LoadState restores a checkpoint from SaveState, replacing the current history of the
+// backend and of every character and tenant. Tenant conversation limits set with
+// ConfigureTenant are kept
+func (llm *LLMBackend) LoadState(data []byte) error {
+	if err := llm.contextManager.LoadState(data); err != nil {
+		return err
+	}
+	var state llmBackendState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse backend state: %w", err)
+	}
+	if err := llm.loadCharacterStates(state.Characters); err != nil {
+		return err
+	}
+	return llm.loadTenantStates(state.Tenants)
+}
+
+// SaveState checkpoints every registered backend so hosts can persist the dialog state at exit
+// The checkpoint is encrypted when SetStateEncryptionKey has been called
+func (dm *DialogManager) SaveState() ([]byte, error) {
+	dm.mu.RLock()
+	backends := make(map[string]DialogBackend, len(dm.backends))
+	for name, backend := range dm.backends {
+		backends[name] = backend
+	}
+	dm.mu.RUnlock()
+
+	state := DialogState{
+		Version:  DialogStateVersion,
+		SavedAt:  currentTime(),
+		Backends: make(map[string]json.RawMessage, len(backends)),
+	}
+	for name, backend := range backends {
+		data, err := backend.SaveState()
+		if err != nil {
+			return nil, fmt.Errorf("failed to save state of backend '%s': %w", name, err)
+		}
+		state.Backends[name] = data
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dialog state: %w", err)
+	}
+	return dm.encryptState(data)
+}
+
+// LoadState restores backends from a SaveState checkpoint
+// Backends missing from the checkpoint keep their state; saved backends that are no
+// longer registered are skipped
+func (dm *DialogManager) LoadState(data []byte) error {
+	data, err := dm.decryptState(data)
+	if err != nil {
+		return err
+	}
+
+	var state DialogState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse dialog state: %w", err)
+	}
+	if state.Version <= 0 {
+		return fmt.Errorf("dialog state is missing a version")
+	}
+	if state.Version > DialogStateVersion {
+		return fmt.Errorf("unsupported dialog state version %d (max %d)", state.Version, DialogStateVersion)
+	}
+
+	names := make([]string, 0, len(state.Backends))
+	for name := range state.Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		backend, exists := dm.GetBackend(name)
+		if !exists {
+			continue
+		}
+		if err := backend.LoadState(state.Backends[name]); err != nil {
+			return fmt.Errorf("failed to load state of backend '%s': %w", name, err)
+		}
+	}
+	return nil
+}
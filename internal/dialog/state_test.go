@@ -0,0 +1,106 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextManager_SaveLoadState(t *testing.T) {
+	source := NewContextManager(5)
+	defer source.Close()
+	source.AddExchange("user-2", "feed", "Yum!")
+	source.AddExchange("user-1", "click", "Hi!")
+	source.UpdateFeedback("user-1", true, 0.9)
+
+	data, err := source.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	var state ContextState
+	json.Unmarshal(data, &state)
+	if state.Version != ContextStateVersion || len(state.Conversations) != 2 || state.Conversations[0].InteractionID != "user-1" {
+		t.Errorf("Expected both conversations in interaction ID order, got %+v", state)
+	}
+
+	target := NewContextManager(5)
+	defer target.Close()
+	target.AddExchange("stale", "click", "Old")
+	if err := target.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if history := target.GetHistory("stale", 0); len(history) != 0 {
+		t.Errorf("Expected loading to replace existing conversations, got %+v", history)
+	}
+	restored := target.GetHistory("user-1", 0)
+	if len(restored) != 1 || !restored[0].FeedbackReceived || restored[0].EngagementScore != 0.9 {
+		t.Errorf("Expected feedback restored with the history, got %+v", restored)
+	}
+}
+
+func TestContextManager_LoadStateKeepsMostRecent(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	source := NewContextManager(5)
+	defer source.Close()
+	for _, id := range []string{"a", "b", "c"} {
+		source.AddExchange(id, "click", "Hi "+id)
+		clock.Advance(time.Minute)
+	}
+	data, _ := source.SaveState()
+
+	target := NewContextManagerWithConfig(5, 2, time.Hour, 24*time.Hour)
+	defer target.Close()
+	if err := target.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if len(target.GetHistory("a", 0)) != 0 || len(target.GetHistory("c", 0)) != 1 {
+		t.Error("Expected the least recent conversation dropped to fit maxConversations")
+	}
+}
+
+func TestContextManager_LoadStateValidation(t *testing.T) {
+	cm := NewContextManager(5)
+	defer cm.Close()
+	invalid := map[string]string{
+		"missing a version": `{"conversations": []}`,
+		"unsupported":       `{"version": 99, "conversations": []}`,
+		"interactionId":     `{"version": 1, "conversations": [{"exchanges": []}]}`,
+		"failed to parse":   `not json`,
+	}
+	for expected, data := range invalid {
+		if err := cm.LoadState([]byte(data)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestDialogManager_SaveLoadState(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hello!")
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet"})
+
+	data, err := dm.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	restored, _ := newRateLimitTestManager(t)
+	if err := restored.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	backend, _ := restored.GetBackend("llm")
+	history := backend.(*LLMBackend).GetContextManager().GetHistory("pet", 0)
+	if len(history) != 1 || history[0].Response != "Hello!" {
+		t.Errorf("Expected the backend's history restored, got %+v", history)
+	}
+
+	if err := NewDialogManager(false).LoadState(data); err != nil {
+		t.Errorf("Expected backends that are no longer registered to be skipped, got %v", err)
+	}
+	if err := restored.LoadState([]byte(`{"version": 2}`)); err == nil {
+		t.Error("Expected error for a newer dialog state version")
+	}
+}
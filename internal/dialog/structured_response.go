@@ -0,0 +1,183 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Response formats for LLMConfig.ResponseFormat
+const (
+	ResponseFormatText = "text" // Plain text; animation and tone are inferred from keywords (default)
+	ResponseFormatJSON = "json" // A {"text", "emotion", "animation"} object chosen by the model
+)
+
+// Values the model may choose from in JSON mode when the configuration does not list them
+var (
+	defaultResponseAnimations = []string{"talking", "happy", "sad", "eating"}
+	defaultResponseEmotions   = []string{"happy", "sad", "excited", "shy", "flirty", "neutral"}
+)
+
+// structuredReply is the JSON object the model emits in JSON mode
+type structuredReply struct {
+	Text      string   `json:"text"`
+	Emotion   string   `json:"emotion"`
+	Animation string   `json:"animation"`
+	Actions   []Action `json:"actions,omitempty"` // Only when tools are configured
+}
+
+// structuredFormat describes the JSON reply a backend asks for and accepts
+type structuredFormat struct {
+	animations []string
+	emotions   []string
+	tools      []string // Action names the reply may request
+	grammar    string   // GBNF passed to the sampler
+}
+
+// newStructuredFormat creates a JSON reply format, using defaults for empty value lists
+func newStructuredFormat(animations, emotions, tools []string) *structuredFormat {
+	if len(animations) == 0 {
+		animations = defaultResponseAnimations
+	}
+	if len(emotions) == 0 {
+		emotions = defaultResponseEmotions
+	}
+	return &structuredFormat{
+		animations: animations,
+		emotions:   emotions,
+		tools:      tools,
+		grammar:    structuredGrammar(animations, emotions, tools),
+	}
+}
+
+// structuredGrammar builds a GBNF grammar for a reply restricted to the given values,
+// with an optional actions list when tools are given
+func structuredGrammar(animations, emotions, tools []string) string {
+	choice := func(values []string) string {
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = strconv.Quote(strconv.Quote(value))
+		}
+		return strings.Join(quoted, " | ")
+	}
+
+	actions := ""
+	if len(tools) > 0 {
+		actions = " actions?"
+	}
+	grammar := `root      ::= "{" ws "\"text\":" ws string "," ws "\"emotion\":" ws emotion "," ws "\"animation\":" ws animation` + actions + ` ws "}"
+string    ::= "\"" ([^"\\\n] | "\\" ["\\/bfnrt])+ "\""
+emotion   ::= ` + choice(emotions) + `
+animation ::= ` + choice(animations) + `
+ws        ::= [ \t\n]*
+`
+	if len(tools) > 0 {
+		grammar += `actions   ::= "," ws "\"actions\":" ws "[" ws (action (ws "," ws action)*)? ws "]"
+action    ::= "{" ws "\"name\":" ws tool (ws "," ws "\"arguments\":" ws arguments)? ws "}"
+arguments ::= "{" ws (string ws ":" ws string (ws "," ws string ws ":" ws string)*)? ws "}"
+tool      ::= ` + choice(tools) + `
+`
+	}
+	return grammar
+}
+
+// parse decodes and checks a JSON reply, matching values case-insensitively
+func (f *structuredFormat) parse(raw string) (structuredReply, error) {
+	var reply structuredReply
+	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &reply); err != nil {
+		return structuredReply{}, fmt.Errorf("response is not a JSON reply: %w", err)
+	}
+
+	reply.Text = strings.TrimSpace(reply.Text)
+	if reply.Text == "" {
+		return structuredReply{}, fmt.Errorf("JSON reply has no text")
+	}
+	reply.Emotion = strings.ToLower(strings.TrimSpace(reply.Emotion))
+	if !slices.Contains(f.emotions, reply.Emotion) {
+		return structuredReply{}, fmt.Errorf("JSON reply has unknown emotion %q (expected one of %v)", reply.Emotion, f.emotions)
+	}
+	reply.Animation = strings.ToLower(strings.TrimSpace(reply.Animation))
+	if !slices.Contains(f.animations, reply.Animation) {
+		return structuredReply{}, fmt.Errorf("JSON reply has unknown animation %q (expected one of %v)", reply.Animation, f.animations)
+	}
+	return reply, nil
+}
+
+// instruction tells the model the reply format and allowed values
+func (f *structuredFormat) instruction() string {
+	actions := ""
+	if len(f.tools) > 0 {
+		actions = `, "actions": [{"name": "<action>", "arguments": {"<argument>": "<value>"}}]`
+	}
+	return fmt.Sprintf(`- Reply with only a JSON object: {"text": "<what you say>", "emotion": "<%s>", "animation": "<%s>"%s}`,
+		strings.Join(f.emotions, "|"), strings.Join(f.animations, "|"), actions)
+}
+
+// applyResponseFormat configures plain text or JSON replies
+func (llm *LLMBackend) applyResponseFormat(cfg LLMConfig) error {
+	switch cfg.ResponseFormat {
+	case "", ResponseFormatText:
+		llm.structured = nil
+	case ResponseFormatJSON:
+		if llm.grammar != nil {
+			return configErrorf("responseFormat", "grammar cannot be combined with responseFormat %q", ResponseFormatJSON)
+		}
+		tools := make([]string, len(llm.tools))
+		for i, tool := range llm.tools {
+			tools[i] = tool.Name
+		}
+		llm.structured = newStructuredFormat(lowerAll(cfg.ResponseAnimations), lowerAll(cfg.ResponseEmotions), tools)
+	default:
+		return configErrorf("responseFormat", "responseFormat must be %q or %q, got %q", ResponseFormatText, ResponseFormatJSON, cfg.ResponseFormat)
+	}
+	return nil
+}
+
+// lowerAll lowercases values so they compare case-insensitively
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, value := range values {
+		lowered[i] = strings.ToLower(value)
+	}
+	return lowered
+}
+
+// parsedOutput is generated output split into display text and the model's choices
+type parsedOutput struct {
+	text      string
+	emotion   string // JSON mode only
+	animation string // JSON mode only
+	actions   []Action
+}
+
+// parseOutput extracts the display text, JSON choices and requested actions from
+// generated output, rejecting malformed replies and unknown actions
+func (llm *LLMBackend) parseOutput(raw string) (parsedOutput, error) {
+	output := parsedOutput{text: raw}
+	switch {
+	case llm.structured != nil:
+		reply, err := llm.structured.parse(raw)
+		if err != nil {
+			return parsedOutput{}, err
+		}
+		output = parsedOutput{text: reply.Text, emotion: reply.Emotion, animation: reply.Animation, actions: reply.Actions}
+	case len(llm.tools) > 0:
+		output.text, output.actions = extractActionCalls(raw)
+	}
+
+	if err := llm.checkActions(output.actions); err != nil {
+		return parsedOutput{}, err
+	}
+	return output, nil
+}
+
+// validateGenerated checks generated output; validators see only its display text
+func (llm *LLMBackend) validateGenerated(ctx DialogContext, raw string) (string, error) {
+	output, err := llm.parseOutput(raw)
+	if err != nil {
+		return "", err
+	}
+	return output.text, llm.validateResponse(ctx, output.text)
+}
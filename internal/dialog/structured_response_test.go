@@ -0,0 +1,104 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStructuredFormat_Parse(t *testing.T) {
+	format := newStructuredFormat(nil, nil, nil)
+
+	reply, err := format.parse(` {"text": " Yum, thanks! 😋 ", "emotion": "Happy", "animation": "eating"} `)
+	if err != nil {
+		t.Fatalf("parse() failed: %v", err)
+	}
+	if reply.Text != "Yum, thanks! 😋" || reply.Emotion != "happy" || reply.Animation != "eating" {
+		t.Errorf("Expected a normalized reply, got %+v", reply)
+	}
+
+	invalid := []string{
+		`Yum, thanks!`,
+		`{"text": "", "emotion": "happy", "animation": "eating"}`,
+		`{"text": "Hi", "emotion": "furious", "animation": "eating"}`,
+		`{"text": "Hi", "emotion": "happy", "animation": "backflip"}`,
+	}
+	for _, raw := range invalid {
+		if _, err := format.parse(raw); err == nil {
+			t.Errorf("Expected parse(%q) to fail", raw)
+		}
+	}
+}
+
+func TestStructuredGrammar(t *testing.T) {
+	grammar, err := ParseGrammar(structuredGrammar([]string{"talking", "wave"}, []string{"happy", "shy"}, nil))
+	if err != nil {
+		t.Fatalf("Generated grammar does not parse: %v", err)
+	}
+	if !grammar.Match(`{"text": "Hi \"you\" 👋", "emotion": "shy", "animation": "wave"}`) {
+		t.Error("Expected a valid reply to match the grammar")
+	}
+	if grammar.Match(`{"text": "Hi", "emotion": "sad", "animation": "wave"}`) {
+		t.Error("Expected an unlisted emotion not to match the grammar")
+	}
+}
+
+func TestLLMBackend_JSONResponseFormat(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{
+		"Thanks for the food!",
+		`{"text": "Thanks for the food!", "emotion": "shy", "animation": "wave"}`,
+	}}
+	config := LLMConfig{
+		ResponseFormat:     ResponseFormatJSON,
+		ResponseAnimations: []string{"Talking", "Wave"},
+		FallbackEnabled:    false,
+	}
+	backend := newScriptedBackend(t, config, model)
+	context := DialogContext{Trigger: "feed", InteractionID: "user-1"}
+
+	response, err := backend.GenerateResponse(context)
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+	if response.Text != "Thanks for the food!" || response.Animation != "wave" || response.EmotionalTone != "shy" {
+		t.Errorf("Expected model-chosen animation and tone, got %+v", response)
+	}
+	if model.callCount() != 2 || !strings.Contains(model.calls[0].Grammar, `"\"wave\""`) {
+		t.Errorf("Expected the plain reply regenerated under the JSON grammar, got %+v", model.calls)
+	}
+	if history := backend.contextManager.GetHistory("user-1", 0); len(history) != 1 || history[0].Response != response.Text {
+		t.Errorf("Expected the reply text in memory, got %+v", history)
+	}
+
+	prompt := backend.buildPrompt(context)
+	if !strings.Contains(prompt, `"animation": "<talking|wave>"`) {
+		t.Errorf("Expected the prompt to ask for JSON, got %q", prompt)
+	}
+}
+
+func TestLLMBackend_JSONValidatorsSeeText(t *testing.T) {
+	model := &scriptedTestModel{responses: []string{`{"text": "Hi", "emotion": "happy", "animation": "talking"}`}}
+	config := LLMConfig{
+		ResponseFormat:  ResponseFormatJSON,
+		FallbackEnabled: false,
+		Validation:      ValidationConfig{MaxLength: 5},
+	}
+	backend := newScriptedBackend(t, config, model)
+
+	if _, err := backend.GenerateResponse(DialogContext{Trigger: "click"}); err != nil {
+		t.Errorf("Expected the length check to apply to the reply text only, got %v", err)
+	}
+}
+
+func TestLLMBackend_ResponseFormatErrors(t *testing.T) {
+	configs := map[string]string{
+		"unknown format": `{"modelPath": "/fake/path.gguf", "responseFormat": "xml"}`,
+		"with grammar":   `{"modelPath": "/fake/path.gguf", "responseFormat": "json", "grammar": "root ::= \"a\""}`,
+	}
+	for name, config := range configs {
+		backend := NewLLMBackend()
+		if err := backend.Initialize([]byte(config)); err == nil {
+			t.Errorf("%s: expected Initialize to fail", name)
+			backend.Close()
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minDescribedIdle is the shortest gap between interactions mentioned in prompts
+const minDescribedIdle = time.Minute
+
+// TimeOfDayAt maps a clock time to the DialogContext labels "morning", "afternoon",
+// "evening" and "night"
+func TimeOfDayAt(t time.Time) string {
+	switch hour := t.Hour(); {
+	case hour >= 5 && hour < 12:
+		return "morning"
+	case hour >= 12 && hour < 17:
+		return "afternoon"
+	case hour >= 17 && hour < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// EnrichTemporalContext fills TimeOfDay, DayOfWeek, IsWeekend and IdleDuration from now
+// and the previous interaction time when the host left them empty
+// A zero lastInteraction falls back to the newest InteractionHistory record
+func EnrichTemporalContext(ctx DialogContext, now, lastInteraction time.Time) DialogContext {
+	if ctx.TimeOfDay == "" {
+		ctx.TimeOfDay = TimeOfDayAt(now)
+	}
+	if ctx.DayOfWeek == "" {
+		ctx.DayOfWeek = strings.ToLower(now.Weekday().String())
+		ctx.IsWeekend = now.Weekday() == time.Saturday || now.Weekday() == time.Sunday
+	}
+
+	if ctx.IdleDuration == 0 {
+		if lastInteraction.IsZero() {
+			for _, record := range ctx.InteractionHistory {
+				if record.Timestamp.After(lastInteraction) {
+					lastInteraction = record.Timestamp
+				}
+			}
+		}
+		if !lastInteraction.IsZero() && now.After(lastInteraction) {
+			ctx.IdleDuration = now.Sub(lastInteraction)
+		}
+	}
+	return ctx
+}
+
+// TemporalContext returns middleware that grounds every request in time: it fills empty
+// temporal fields using the context Timestamp (or the package clock) and the previous
+// request for the same InteractionID
+func TemporalContext() Middleware {
+	var mu sync.Mutex
+	lastSeen := make(map[string]time.Time)
+
+	return func(next DialogHandler) DialogHandler {
+		return func(context DialogContext) (DialogResponse, error) {
+			now := context.Timestamp
+			if now.IsZero() {
+				now = currentTime()
+			}
+
+			mu.Lock()
+			last := lastSeen[context.InteractionID]
+			lastSeen[context.InteractionID] = now
+			mu.Unlock()
+
+			return next(EnrichTemporalContext(context, now, last))
+		}
+	}
+}
+
+// describeDuration renders a gap between interactions in friendly units
+func describeDuration(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case d >= 48*time.Hour:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d >= 2*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/time.Minute), "minute")
+	}
+}
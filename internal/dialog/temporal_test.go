@@ -0,0 +1,96 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeOfDayAt(t *testing.T) {
+	tests := map[int]string{5: "morning", 11: "morning", 12: "afternoon", 17: "evening", 21: "evening", 22: "night", 3: "night"}
+	for hour, expected := range tests {
+		if got := TimeOfDayAt(time.Date(2024, 6, 1, hour, 30, 0, 0, time.UTC)); got != expected {
+			t.Errorf("TimeOfDayAt(%d:30) = %q, expected %q", hour, got, expected)
+		}
+	}
+}
+
+func TestEnrichTemporalContext(t *testing.T) {
+	saturday := time.Date(2024, 6, 1, 19, 0, 0, 0, time.UTC)
+
+	ctx := EnrichTemporalContext(DialogContext{}, saturday, saturday.Add(-3*time.Hour))
+	if ctx.TimeOfDay != "evening" || ctx.DayOfWeek != "saturday" || !ctx.IsWeekend || ctx.IdleDuration != 3*time.Hour {
+		t.Errorf("Unexpected enrichment: %+v", ctx)
+	}
+
+	host := DialogContext{TimeOfDay: "night", DayOfWeek: "monday", IdleDuration: time.Minute}
+	if ctx := EnrichTemporalContext(host, saturday, saturday.Add(-time.Hour)); ctx.TimeOfDay != "night" ||
+		ctx.DayOfWeek != "monday" || ctx.IsWeekend || ctx.IdleDuration != time.Minute {
+		t.Errorf("Expected host values kept, got %+v", ctx)
+	}
+
+	withHistory := DialogContext{InteractionHistory: []InteractionRecord{
+		{Timestamp: saturday.Add(-5 * time.Hour)},
+		{Timestamp: saturday.Add(-2 * time.Hour)},
+	}}
+	if ctx := EnrichTemporalContext(withHistory, saturday, time.Time{}); ctx.IdleDuration != 2*time.Hour {
+		t.Errorf("Expected idle time from the newest history record, got %v", ctx.IdleDuration)
+	}
+	if ctx := EnrichTemporalContext(DialogContext{}, saturday, time.Time{}); ctx.IdleDuration != 0 {
+		t.Errorf("Expected unknown idle time left at 0, got %v", ctx.IdleDuration)
+	}
+}
+
+func TestTemporalContext_Middleware(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	var seen []DialogContext
+	handler := TemporalContext()(func(context DialogContext) (DialogResponse, error) {
+		seen = append(seen, context)
+		return DialogResponse{}, nil
+	})
+
+	handler(DialogContext{InteractionID: "pet"})
+	clock.Advance(90 * time.Minute)
+	handler(DialogContext{InteractionID: "pet"})
+	handler(DialogContext{InteractionID: "other"})
+
+	if seen[0].IdleDuration != 0 || seen[0].TimeOfDay != "morning" || seen[0].DayOfWeek != "monday" || seen[0].IsWeekend {
+		t.Errorf("Unexpected first request context: %+v", seen[0])
+	}
+	if seen[1].IdleDuration != 90*time.Minute {
+		t.Errorf("Expected idle time since the previous request, got %v", seen[1].IdleDuration)
+	}
+	if seen[2].IdleDuration != 0 {
+		t.Errorf("Expected interactions tracked separately, got %v", seen[2].IdleDuration)
+	}
+}
+
+func TestPromptBuilder_TemporalContext(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{Trigger: "click", TimeOfDay: "evening", DayOfWeek: "saturday", IsWeekend: true, IdleDuration: 3 * time.Hour})
+
+	prompt := pb.Build()
+	for _, expected := range []string{"- Day: saturday (weekend)", "previous interaction was 3 hours ago"} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected %q in the prompt, got %q", expected, prompt)
+		}
+	}
+}
+
+func TestDescribeDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		time.Minute:      "1 minute",
+		45 * time.Minute: "45 minutes",
+		90 * time.Minute: "90 minutes",
+		5 * time.Hour:    "5 hours",
+		72 * time.Hour:   "3 days",
+	}
+	for duration, expected := range tests {
+		if got := describeDuration(duration); got != expected {
+			t.Errorf("describeDuration(%v) = %q, expected %q", duration, got, expected)
+		}
+	}
+}
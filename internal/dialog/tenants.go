@@ -0,0 +1,281 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// defaultTenantConversations bounds each tenant's conversation memory when MaxConversations is unset
+const defaultTenantConversations = 100
+
+// TenantConfig limits one tenant namespace on a shared DialogManager
+type TenantConfig struct {
+	MaxConversations int             `json:"maxConversations,omitempty"` // Conversations kept per character before the oldest is evicted (default: 100)
+	RateLimit        RateLimitConfig `json:"rateLimit,omitempty"`        // Applied to this tenant's requests only (zero = no tenant limit)
+}
+
+// TenantHistoryStore is implemented by backends that keep each tenant's conversation history apart
+type TenantHistoryStore interface {
+	ConfigureTenant(tenantID string, maxConversations int)
+	RemoveTenant(tenantID string)
+}
+
+// tenant is a registered namespace and its own rate limiter
+type tenant struct {
+	config  TenantConfig
+	limiter *rateLimiter
+}
+
+// RegisterTenant adds or reconfigures a tenant namespace
+// Requests carrying its DialogContext.TenantID get isolated backend history, bounded to
+// MaxConversations per character, and their own rate limit on top of SetRateLimit.
+// Reconfiguring a tenant resets its rate limit windows but keeps its history. Mood, drift
+// and user memory stay keyed by InteractionID, so hosts should keep those IDs unique per tenant
+func (dm *DialogManager) RegisterTenant(id string, config TenantConfig) error {
+	if id == "" {
+		return fmt.Errorf("tenant ID must not be empty")
+	}
+	if config.MaxConversations < 0 {
+		return configErrorf("maxConversations", "tenant '%s' maxConversations must be non-negative", id)
+	}
+	if config.RateLimit.DebounceMs < 0 || config.RateLimit.MaxPerWindow < 0 || config.RateLimit.WindowMs < 0 {
+		return configErrorf("rateLimit", "tenant '%s' rate limit values must be non-negative", id)
+	}
+	if config.MaxConversations == 0 {
+		config.MaxConversations = defaultTenantConversations
+	}
+
+	registered := &tenant{config: config}
+	if config.RateLimit.enabled() {
+		registered.limiter = newRateLimiter(config.RateLimit)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	// Copy on write, so handler chains built earlier keep a consistent snapshot
+	tenants := make(map[string]*tenant, len(dm.tenants)+1)
+	for name, existing := range dm.tenants {
+		tenants[name] = existing
+	}
+	tenants[id] = registered
+	dm.tenants = tenants
+
+	for _, backend := range dm.backends {
+		if store, ok := backend.(TenantHistoryStore); ok {
+			store.ConfigureTenant(id, config.MaxConversations)
+		}
+	}
+	return nil
+}
+
+// RemoveTenant unregisters a tenant and discards its history in every backend
+// Later requests with its TenantID fail with ErrUnknownTenant
+func (dm *DialogManager) RemoveTenant(id string) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if _, exists := dm.tenants[id]; !exists {
+		return
+	}
+
+	tenants := make(map[string]*tenant, len(dm.tenants))
+	for name, existing := range dm.tenants {
+		if name != id {
+			tenants[name] = existing
+		}
+	}
+	dm.tenants = tenants
+
+	for _, backend := range dm.backends {
+		if store, ok := backend.(TenantHistoryStore); ok {
+			store.RemoveTenant(id)
+		}
+	}
+}
+
+// Tenants lists the registered tenant IDs
+func (dm *DialogManager) Tenants() []string {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	ids := make([]string, 0, len(dm.tenants))
+	for id := range dm.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// tenantGate rejects requests for unregistered tenants and applies each tenant's rate limit
+func (dm *DialogManager) tenantGate(tenants map[string]*tenant, next DialogHandler) DialogHandler {
+	var limited map[string]DialogHandler
+	for id, registered := range tenants {
+		if registered.limiter != nil {
+			if limited == nil {
+				limited = make(map[string]DialogHandler, len(tenants))
+			}
+			limited[id] = registered.limiter.wrap(next, dm.createFallbackResponse)
+		}
+	}
+
+	return func(context DialogContext) (DialogResponse, error) {
+		if context.TenantID == "" {
+			return next(context)
+		}
+		if _, exists := tenants[context.TenantID]; !exists {
+			return DialogResponse{}, fmt.Errorf("%w: '%s'", ErrUnknownTenant, context.TenantID)
+		}
+		if handler, ok := limited[context.TenantID]; ok {
+			return handler(context)
+		}
+		return next(context)
+	}
+}
+
+// tenantHistory is one tenant's conversation memory, one store per CharacterID
+type tenantHistory struct {
+	maxConversations int
+	contexts         map[string]*ContextManager
+}
+
+// ConfigureTenant sets how many conversations each of the tenant's stores keeps (0 = unlimited)
+// Existing stores adopt the new limit from their next new conversation
+func (llm *LLMBackend) ConfigureTenant(tenantID string, maxConversations int) {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	history := llm.tenantHistoryLocked(tenantID)
+	history.maxConversations = maxConversations
+	for _, cm := range history.contexts {
+		cm.lockAll()
+		cm.maxConversations = maxConversations
+		cm.unlockAll()
+	}
+}
+
+// RemoveTenant discards the tenant's conversation history
+func (llm *LLMBackend) RemoveTenant(tenantID string) {
+	llm.historyMu.Lock()
+	history := llm.tenantContexts[tenantID]
+	delete(llm.tenantContexts, tenantID)
+	llm.historyMu.Unlock()
+
+	if history != nil {
+		for _, cm := range history.contexts {
+			cm.Close()
+		}
+	}
+}
+
+// GetTenantContextManager returns the isolated history store for a tenant's character
+// The empty CharacterID is the tenant's shared store
+func (llm *LLMBackend) GetTenantContextManager(tenantID, characterID string) *ContextManager {
+	return llm.contextFor(DialogContext{TenantID: tenantID, CharacterID: characterID})
+}
+
+// tenantHistoryLocked returns a tenant's memory, creating it unbounded; historyMu must be held
+func (llm *LLMBackend) tenantHistoryLocked(tenantID string) *tenantHistory {
+	if history, exists := llm.tenantContexts[tenantID]; exists {
+		return history
+	}
+	if llm.tenantContexts == nil {
+		llm.tenantContexts = make(map[string]*tenantHistory)
+	}
+	history := &tenantHistory{contexts: make(map[string]*ContextManager)}
+	llm.tenantContexts[tenantID] = history
+	return history
+}
+
+// tenantContextLocked returns a tenant character's store, creating it on first use; historyMu must be held
+func (llm *LLMBackend) tenantContextLocked(tenantID, characterID string) *ContextManager {
+	history := llm.tenantHistoryLocked(tenantID)
+	if cm, exists := history.contexts[characterID]; exists {
+		return cm
+	}
+	cm := llm.newHistoryStore(history.maxConversations)
+	history.contexts[characterID] = cm
+	return cm
+}
+
+// closeTenantContexts stops every tenant store and forgets the tenants
+func (llm *LLMBackend) closeTenantContexts() {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	for _, history := range llm.tenantContexts {
+		for _, cm := range history.contexts {
+			cm.Close()
+		}
+	}
+	llm.tenantContexts = nil
+}
+
+// tenantContextManagers returns a snapshot of the per-tenant stores, keyed by tenant then character ID
+func (llm *LLMBackend) tenantContextManagers() map[string]map[string]*ContextManager {
+	llm.historyMu.Lock()
+	defer llm.historyMu.Unlock()
+
+	managers := make(map[string]map[string]*ContextManager, len(llm.tenantContexts))
+	for tenantID, history := range llm.tenantContexts {
+		if len(history.contexts) == 0 {
+			continue
+		}
+		managers[tenantID] = make(map[string]*ContextManager, len(history.contexts))
+		for characterID, cm := range history.contexts {
+			managers[tenantID][characterID] = cm
+		}
+	}
+	return managers
+}
+
+// saveTenantStates checkpoints each tenant's stores, keyed by tenant then character ID
+func (llm *LLMBackend) saveTenantStates() (map[string]map[string]json.RawMessage, error) {
+	managers := llm.tenantContextManagers()
+	if len(managers) == 0 {
+		return nil, nil
+	}
+
+	states := make(map[string]map[string]json.RawMessage, len(managers))
+	for tenantID, characters := range managers {
+		states[tenantID] = make(map[string]json.RawMessage, len(characters))
+		for characterID, cm := range characters {
+			data, err := cm.SaveState()
+			if err != nil {
+				return nil, fmt.Errorf("failed to save history of tenant '%s': %w", tenantID, err)
+			}
+			states[tenantID][characterID] = data
+		}
+	}
+	return states, nil
+}
+
+// loadTenantStates replaces every tenant's history with the checkpointed ones, keeping
+// configured conversation limits
+func (llm *LLMBackend) loadTenantStates(states map[string]map[string]json.RawMessage) error {
+	llm.historyMu.Lock()
+	for _, history := range llm.tenantContexts {
+		for _, cm := range history.contexts {
+			cm.Close()
+		}
+		history.contexts = make(map[string]*ContextManager)
+	}
+	llm.historyMu.Unlock()
+
+	tenantIDs := make([]string, 0, len(states))
+	for id := range states {
+		tenantIDs = append(tenantIDs, id)
+	}
+	sort.Strings(tenantIDs)
+
+	for _, tenantID := range tenantIDs {
+		if tenantID == "" {
+			return fmt.Errorf("backend state has a tenant without an ID")
+		}
+		for characterID, data := range states[tenantID] {
+			if err := llm.GetTenantContextManager(tenantID, characterID).LoadState(data); err != nil {
+				return fmt.Errorf("failed to load history of tenant '%s': %w", tenantID, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package dialog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDialogManager_RegisterTenantValidation(t *testing.T) {
+	dm := NewDialogManager(false)
+	if err := dm.RegisterTenant("", TenantConfig{}); err == nil {
+		t.Error("Expected error for an empty tenant ID")
+	}
+	if err := dm.RegisterTenant("acme", TenantConfig{MaxConversations: -1}); err == nil {
+		t.Error("Expected error for negative maxConversations")
+	}
+	if err := dm.RegisterTenant("acme", TenantConfig{RateLimit: RateLimitConfig{WindowMs: -1}}); err == nil {
+		t.Error("Expected error for a negative rate limit window")
+	}
+	if tenants := dm.Tenants(); len(tenants) != 0 {
+		t.Errorf("Expected rejected tenants left unregistered, got %v", tenants)
+	}
+}
+
+func TestDialogManager_UnknownTenantRejected(t *testing.T) {
+	dm, model := newRateLimitTestManager(t, "Hello!")
+	response, err := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "ghost", FallbackResponses: []string{"..."}})
+	if !errors.Is(err, ErrUnknownTenant) || response.Text != "..." {
+		t.Errorf("Expected the fallback with ErrUnknownTenant, got %q, %v", response.Text, err)
+	}
+	if model.callCount() != 0 {
+		t.Errorf("Expected no generation for an unknown tenant, got %d calls", model.callCount())
+	}
+}
+
+func TestDialogManager_TenantHistoryIsolated(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hi Alice!", "Hi Bob!")
+	dm.RegisterTenant("alice", TenantConfig{})
+	dm.RegisterTenant("bob", TenantConfig{})
+
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "alice"})
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "bob"})
+
+	backend, _ := dm.GetBackend("llm")
+	llm := backend.(*LLMBackend)
+	if history := llm.GetTenantContextManager("alice", "").GetHistory("pet", 0); len(history) != 1 || history[0].Response != "Hi Alice!" {
+		t.Errorf("Expected only alice's exchange in her history, got %+v", history)
+	}
+	if history := llm.GetContextManager().GetHistory("pet", 0); len(history) != 0 {
+		t.Errorf("Expected tenant exchanges kept out of the shared history, got %+v", history)
+	}
+
+	dm.RemoveTenant("bob")
+	if tenants := dm.Tenants(); len(tenants) != 1 || tenants[0] != "alice" {
+		t.Errorf("Expected only alice registered, got %v", tenants)
+	}
+	if history := llm.GetTenantContextManager("bob", "").GetHistory("pet", 0); len(history) != 0 {
+		t.Errorf("Expected bob's history discarded, got %+v", history)
+	}
+}
+
+func TestDialogManager_TenantConversationLimit(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "One", "Two", "Three")
+	dm.RegisterTenant("acme", TenantConfig{MaxConversations: 2})
+	for _, id := range []string{"a", "b", "c"} {
+		dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: id, TenantID: "acme"})
+	}
+
+	backend, _ := dm.GetBackend("llm")
+	if memory := backend.(*LLMBackend).GetTenantContextManager("acme", "").MemoryStats(); memory.Conversations != 2 {
+		t.Errorf("Expected the tenant capped at 2 conversations, got %d", memory.Conversations)
+	}
+}
+
+func TestDialogManager_TenantRateLimit(t *testing.T) {
+	dm, model := newRateLimitTestManager(t, "Limited", "Free", "Free again")
+	dm.RegisterTenant("small", TenantConfig{RateLimit: RateLimitConfig{MaxPerWindow: 1, WindowMs: 60000}})
+	dm.RegisterTenant("large", TenantConfig{})
+
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "small"})
+	limited, _ := dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "small"})
+	if limited.Metadata[MetadataRateLimit] != RateLimitExceeded {
+		t.Errorf("Expected the tenant's second request limited, got %v", limited.Metadata)
+	}
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "large"})
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", TenantID: "large"})
+	if model.callCount() != 3 {
+		t.Errorf("Expected other tenants unaffected by the limit, got %d model calls", model.callCount())
+	}
+}
+
+func TestDialogManager_TenantsAppliedToLateBackends(t *testing.T) {
+	dm := NewDialogManager(false)
+	dm.RegisterTenant("acme", TenantConfig{MaxConversations: 1})
+	backend := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"One", "Two"}})
+	dm.RegisterBackend("llm", backend)
+	dm.SetDefaultBackend("llm")
+
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "a", TenantID: "acme"})
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "b", TenantID: "acme"})
+	if memory := backend.GetTenantContextManager("acme", "").MemoryStats(); memory.Conversations != 1 {
+		t.Errorf("Expected the tenant limit applied to a backend registered later, got %d", memory.Conversations)
+	}
+}
+
+func TestLLMBackend_SaveLoadTenantState(t *testing.T) {
+	source := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"Meow"}})
+	source.GenerateResponse(DialogContext{Trigger: "click", InteractionID: "user", TenantID: "acme", CharacterID: "cat"})
+	data, err := source.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	target := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{})
+	target.ConfigureTenant("acme", 3)
+	if err := target.LoadState(data); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	restored := target.GetTenantContextManager("acme", "cat")
+	if history := restored.GetHistory("user", 0); len(history) != 1 || history[0].Response != "Meow" {
+		t.Errorf("Expected the tenant's history restored, got %+v", history)
+	}
+	if restored.maxConversations != 3 {
+		t.Errorf("Expected the configured tenant limit kept, got %d", restored.maxConversations)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build linux
+
+package dialog
+
+import "syscall"
+
+// lowPriorityNice is the nice value given to inference threads in low priority mode
+const lowPriorityNice = 10
+
+// lowerThreadPriority raises the nice value of the calling OS thread only; Linux
+// applies PRIO_PROCESS with a thread ID to that single thread
+func lowerThreadPriority() error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, syscall.Gettid(), lowPriorityNice)
+}
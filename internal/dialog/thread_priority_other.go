@@ -0,0 +1,8 @@
+//go:build !linux
+
+package dialog
+
+// lowerThreadPriority is a no-op where per-thread priorities are not available
+func lowerThreadPriority() error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package dialog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Automatic thread tuning limits
+const (
+	maxAutoThreads       = 8 // CPU inference is memory-bound beyond this
+	reserveCoreThreshold = 4 // With at least this many cores, one is left for foreground apps
+)
+
+// cpuInfoRoot is the filesystem root for CPU topology files, replaceable in tests
+var cpuInfoRoot = "/"
+
+// AutoThreadCount picks an inference thread count for this machine: one per physical
+// performance core where hybrid P/E cores are detectable, else one per physical core,
+// leaving a core free for foreground apps on larger machines
+func AutoThreadCount() int {
+	cores := performanceCoreCount()
+	if cores == 0 {
+		cores = physicalCoreCount(nil)
+	}
+	if cores == 0 || cores > runtime.NumCPU() {
+		cores = runtime.NumCPU()
+	}
+	if cores >= reserveCoreThreshold {
+		cores--
+	}
+	return max(1, min(cores, maxAutoThreads))
+}
+
+// performanceCoreCount counts physical performance cores on hybrid CPUs, or 0 when the
+// CPU is not hybrid or the topology is unavailable
+func performanceCoreCount() int {
+	data, err := os.ReadFile(filepath.Join(cpuInfoRoot, "sys/devices/cpu_core/cpus"))
+	if err != nil {
+		return 0
+	}
+	cpus, err := parseCPUList(strings.TrimSpace(string(data)))
+	if err != nil || len(cpus) == 0 {
+		return 0
+	}
+	if cores := physicalCoreCount(cpus); cores > 0 {
+		return cores
+	}
+	return len(cpus)
+}
+
+// physicalCoreCount counts distinct physical cores in /proc/cpuinfo, optionally restricted
+// to the given logical CPUs; 0 when the file is unavailable
+func physicalCoreCount(only map[int]bool) int {
+	file, err := os.Open(filepath.Join(cpuInfoRoot, "proc/cpuinfo"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	cores := make(map[string]bool)
+	processor, physicalID := -1, ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "processor":
+			processor, _ = strconv.Atoi(strings.TrimSpace(value))
+		case "physical id":
+			physicalID = strings.TrimSpace(value)
+		case "core id":
+			if only == nil || only[processor] {
+				cores[physicalID+"/"+strings.TrimSpace(value)] = true
+			}
+		}
+	}
+	return len(cores)
+}
+
+// parseCPUList parses a kernel CPU list such as "0-7,16-23"
+func parseCPUList(list string) (map[int]bool, error) {
+	cpus := make(map[int]bool)
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q", list)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid CPU list %q", list)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus[cpu] = true
+		}
+	}
+	return cpus, nil
+}
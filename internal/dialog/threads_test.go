@@ -0,0 +1,111 @@
+package dialog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeCPUInfo builds a topology root with a /proc/cpuinfo listing (physical id, core id)
+// per logical CPU and, when set, a hybrid performance core list
+func fakeCPUInfo(t *testing.T, cores [][2]int, performanceCPUs string) {
+	t.Helper()
+	root := t.TempDir()
+
+	var cpuinfo strings.Builder
+	for processor, core := range cores {
+		cpuinfo.WriteString("processor\t: " + strconv.Itoa(processor) + "\n")
+		cpuinfo.WriteString("physical id\t: " + strconv.Itoa(core[0]) + "\n")
+		cpuinfo.WriteString("core id\t\t: " + strconv.Itoa(core[1]) + "\n\n")
+	}
+	os.MkdirAll(filepath.Join(root, "proc"), 0o755)
+	os.WriteFile(filepath.Join(root, "proc", "cpuinfo"), []byte(cpuinfo.String()), 0o644)
+
+	if performanceCPUs != "" {
+		dir := filepath.Join(root, "sys", "devices", "cpu_core")
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, "cpus"), []byte(performanceCPUs+"\n"), 0o644)
+	}
+
+	original := cpuInfoRoot
+	cpuInfoRoot = root
+	t.Cleanup(func() { cpuInfoRoot = original })
+}
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,8,10-11")
+	if err != nil || len(cpus) != 7 || !cpus[8] || cpus[9] {
+		t.Errorf("Expected CPUs 0-3, 8, 10 and 11, got %v (%v)", cpus, err)
+	}
+	if _, err := parseCPUList("3-1"); err == nil {
+		t.Error("Expected a reversed range to be rejected")
+	}
+}
+
+func TestAutoThreadCount_Topology(t *testing.T) {
+	if runtime.NumCPU() < 2 {
+		t.Skip("needs at least 2 CPUs to tell physical cores from logical ones")
+	}
+
+	// Two physical cores with hyper-threading: one thread per physical core
+	fakeCPUInfo(t, [][2]int{{0, 0}, {0, 1}, {0, 0}, {0, 1}}, "")
+	if got := AutoThreadCount(); got != 2 {
+		t.Errorf("Expected 2 threads for 2 physical cores, got %d", got)
+	}
+
+	// Hybrid CPU: CPUs 0-3 are two hyper-threaded P-cores, 4-5 are E-cores
+	fakeCPUInfo(t, [][2]int{{0, 0}, {0, 0}, {0, 4}, {0, 4}, {0, 8}, {0, 9}}, "0-3")
+	if got := AutoThreadCount(); got != 2 {
+		t.Errorf("Expected 2 threads for 2 performance cores, got %d", got)
+	}
+}
+
+func TestAutoThreadCount_Bounds(t *testing.T) {
+	fakeCPUInfo(t, nil, "")
+	got := AutoThreadCount()
+	if got < 1 || got > maxAutoThreads || got > runtime.NumCPU() {
+		t.Errorf("Expected between 1 and %d threads, got %d", min(maxAutoThreads, runtime.NumCPU()), got)
+	}
+}
+
+func TestLLMBackend_AutoThreads(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{LowPriority: true}, &scriptedTestModel{})
+	if backend.threads != AutoThreadCount() {
+		t.Errorf("Expected %d auto threads, got %d", AutoThreadCount(), backend.threads)
+	}
+	if !backend.lowPriority {
+		t.Error("Expected lowPriority to be applied")
+	}
+
+	backend = newScriptedBackend(t, LLMConfig{Threads: 3}, &scriptedTestModel{})
+	if backend.threads != 3 {
+		t.Errorf("Expected 3 threads, got %d", backend.threads)
+	}
+}
+
+func TestLLMBackend_NegativeThreads(t *testing.T) {
+	for _, config := range []string{`{"modelPath": "/models/m.gguf", "threads": -1}`, `{"modelPath": "/models/m.gguf", "threads": -4}`} {
+		backend := NewLLMBackend()
+		if err := backend.Initialize(json.RawMessage(config)); !errors.Is(err, ErrConfigInvalid) {
+			t.Errorf("Expected %s to be a config error, got %v", config, err)
+		}
+	}
+}
+
+func TestLlamaModel_LowPriorityPrediction(t *testing.T) {
+	modelPath := newCachingLlamaModel(t, false).modelPath
+	model, _ := NewLlamaModel(LlamaConfig{ModelPath: modelPath, LowPriority: true})
+	if err := model.Initialize(); err != nil {
+		t.Fatalf("Failed to initialize model: %v", err)
+	}
+	defer model.Free()
+
+	if _, err := model.PredictWithTimeout(t.Context(), "User clicked on you"); err != nil {
+		t.Errorf("Expected low priority prediction to succeed, got %v", err)
+	}
+}
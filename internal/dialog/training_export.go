@@ -0,0 +1,118 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TrainingExample is an exchange users responded well to, as a fine-tuning pair
+// The prompt is what the user said, or a description of the action they took
+type TrainingExample struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response"`
+}
+
+// TrainingExportOptions selects which stored exchanges become training data
+type TrainingExportOptions struct {
+	MinEngagement float64 // Engagement an exchange needs when it got no feedback (default: 0.7)
+	FeedbackOnly  bool    // Only take exchanges with positive feedback
+	Speaker       string  // Only take responses by this character ("" = any)
+}
+
+// withDefaults fills in unset options
+func (o TrainingExportOptions) withDefaults() TrainingExportOptions {
+	if o.MinEngagement <= 0 {
+		o.MinEngagement = 0.7
+	}
+	return o
+}
+
+// accepts reports whether an exchange is worth learning from: positive feedback, or high
+// engagement without any feedback; negative feedback always rules it out
+func (o TrainingExportOptions) accepts(exchange ConversationExchange) bool {
+	if strings.TrimSpace(exchange.Response) == "" {
+		return false
+	}
+	if o.Speaker != "" && exchange.Speaker != o.Speaker {
+		return false
+	}
+	if exchange.FeedbackReceived {
+		return exchange.UserFeedback
+	}
+	return !o.FeedbackOnly && exchange.EngagementScore >= o.MinEngagement
+}
+
+// TrainingExamples mines every stored conversation for high-engagement exchanges, in
+// interaction ID then time order, with repeated prompt/response pairs exported once
+// Write them with WriteTrainingJSONL for fine-tuning, or fold the responses into a
+// character's MarkovChainConfig.TrainingData with AppendTrainingData
+func (cm *ContextManager) TrainingExamples(opts TrainingExportOptions) []TrainingExample {
+	opts = opts.withDefaults()
+
+	cm.rlockAll()
+	ids := make([]string, 0, cm.conversationCount())
+	for id := range cm.allConversations() {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var examples []TrainingExample
+	seen := make(map[TrainingExample]bool)
+	for _, id := range ids {
+		history, _ := cm.conversation(id)
+		for _, exchange := range history.Exchanges {
+			if !opts.accepts(exchange) {
+				continue
+			}
+			prompt, _ := transcriptUserLine(exchange)
+			example := TrainingExample{Prompt: prompt, Response: strings.TrimSpace(exchange.Response)}
+			if !seen[example] {
+				seen[example] = true
+				examples = append(examples, example)
+			}
+		}
+	}
+	cm.runlockAll()
+	return examples
+}
+
+// ExportTrainingData mines the backend's shared conversation history with TrainingExamples
+func (llm *LLMBackend) ExportTrainingData(opts TrainingExportOptions) []TrainingExample {
+	return llm.GetContextManager().TrainingExamples(opts)
+}
+
+// WriteTrainingJSONL writes examples as JSONL, one {"prompt", "response"} object per line,
+// the format most fine-tuning tools accept
+func WriteTrainingJSONL(w io.Writer, examples []TrainingExample) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for i, example := range examples {
+		if err := encoder.Encode(example); err != nil {
+			return fmt.Errorf("failed to write training example %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// AppendTrainingData adds the examples' responses to trainingData, skipping ones it
+// already has (ignoring case and surrounding space), for MarkovChainConfig.TrainingData
+func AppendTrainingData(trainingData []string, examples []TrainingExample) []string {
+	known := make(map[string]bool, len(trainingData)+len(examples))
+	for _, sentence := range trainingData {
+		known[strings.ToLower(strings.TrimSpace(sentence))] = true
+	}
+
+	merged := append([]string(nil), trainingData...)
+	for _, example := range examples {
+		key := strings.ToLower(strings.TrimSpace(example.Response))
+		if key == "" || known[key] {
+			continue
+		}
+		known[key] = true
+		merged = append(merged, strings.TrimSpace(example.Response))
+	}
+	return merged
+}
@@ -0,0 +1,61 @@
+package dialog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContextManager_TrainingExamples(t *testing.T) {
+	cm := NewContextManager(10)
+	t.Cleanup(cm.Close)
+
+	cm.RecordExchange("sam", ConversationExchange{Trigger: "feed", Response: "Yum, thank you!", FeedbackReceived: true, UserFeedback: true})
+	cm.RecordExchange("sam", ConversationExchange{Trigger: "talk", UserMessage: "Sing for me", Response: "La la la~", EngagementScore: 0.9})
+	cm.RecordExchange("sam", ConversationExchange{Trigger: "talk", UserMessage: "Hello", Response: "Go away.", EngagementScore: 0.9, FeedbackReceived: true})
+	cm.RecordExchange("sam", ConversationExchange{Trigger: "click", Response: "Hm?", EngagementScore: 0.2})
+	cm.RecordExchange("alex", ConversationExchange{Trigger: "feed", Response: "Yum, thank you!", FeedbackReceived: true, UserFeedback: true})
+
+	examples := cm.TrainingExamples(TrainingExportOptions{})
+	want := []TrainingExample{
+		{Prompt: triggerDescription("feed"), Response: "Yum, thank you!"},
+		{Prompt: "Sing for me", Response: "La la la~"},
+	}
+	if !reflect.DeepEqual(examples, want) {
+		t.Errorf("Expected positive and engaging exchanges once each, got %+v", examples)
+	}
+
+	if examples := cm.TrainingExamples(TrainingExportOptions{FeedbackOnly: true}); len(examples) != 1 || examples[0].Response != "Yum, thank you!" {
+		t.Errorf("Expected only the exchange with positive feedback, got %+v", examples)
+	}
+	if examples := cm.TrainingExamples(TrainingExportOptions{MinEngagement: 0.1}); len(examples) != 3 {
+		t.Errorf("Expected a lower engagement bar to take the click too, got %+v", examples)
+	}
+}
+
+func TestWriteTrainingJSONL(t *testing.T) {
+	var out strings.Builder
+	err := WriteTrainingJSONL(&out, []TrainingExample{
+		{Prompt: "Hi", Response: "Hello <3"},
+		{Prompt: "Bye", Response: "See you!"},
+	})
+	if err != nil {
+		t.Fatalf("WriteTrainingJSONL failed: %v", err)
+	}
+
+	want := `{"prompt":"Hi","response":"Hello <3"}` + "\n" + `{"prompt":"Bye","response":"See you!"}` + "\n"
+	if out.String() != want {
+		t.Errorf("Expected JSONL:\n%s\ngot:\n%s", want, out.String())
+	}
+}
+
+func TestAppendTrainingData(t *testing.T) {
+	merged := AppendTrainingData([]string{"Hello friend!"}, []TrainingExample{
+		{Prompt: "Hi", Response: " hello friend! "},
+		{Prompt: "Sing", Response: "La la la~"},
+		{Prompt: "Sing again", Response: "La la la~"},
+	})
+	if want := []string{"Hello friend!", "La la la~"}; !reflect.DeepEqual(merged, want) {
+		t.Errorf("Expected %v, got %v", want, merged)
+	}
+}
@@ -0,0 +1,207 @@
+package dialog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transcript is a recorded sequence of dialog contexts to replay through a backend
+type Transcript struct {
+	Name             string           `json:"name"`
+	Seed             int64            `json:"seed,omitempty"`             // Applied with SetRandomSeed before replay (0 = unchanged)
+	Start            time.Time        `json:"start,omitempty"`            // Manual clock start; zero keeps the current clock
+	Nondeterministic bool             `json:"nondeterministic,omitempty"` // Rely on expectations rather than golden output
+	Turns            []TranscriptTurn `json:"turns"`
+}
+
+// TranscriptTurn is one recorded request and the assertions on its response
+type TranscriptTurn struct {
+	AdvanceMs int                  `json:"advanceMs,omitempty"` // Manual clock advance before this turn
+	Context   DialogContext        `json:"context"`
+	Expect    *ResponseExpectation `json:"expect,omitempty"`
+}
+
+// ResponseExpectation holds structural assertions that hold even when generation varies
+type ResponseExpectation struct {
+	Text          string   `json:"text,omitempty"`          // Exact text (deterministic models only)
+	Contains      []string `json:"contains,omitempty"`      // Substrings the text must include
+	NotContains   []string `json:"notContains,omitempty"`   // Substrings the text must not include
+	MinLength     int      `json:"minLength,omitempty"`     // Minimum text length in characters
+	MaxLength     int      `json:"maxLength,omitempty"`     // Maximum text length in characters (0 = no limit)
+	ResponseType  string   `json:"responseType,omitempty"`  // Required response type, e.g. "fallback"
+	Animation     string   `json:"animation,omitempty"`     // Required animation
+	EmotionalTone string   `json:"emotionalTone,omitempty"` // Required emotional tone
+	MinConfidence float64  `json:"minConfidence,omitempty"` // Lowest acceptable confidence
+	Error         bool     `json:"error,omitempty"`         // The backend must return an error
+}
+
+// TranscriptResult records what a backend produced for every turn of a transcript
+type TranscriptResult struct {
+	Name             string
+	Nondeterministic bool
+	Turns            []TurnResult
+}
+
+// TurnResult is the outcome of one replayed turn
+type TurnResult struct {
+	Context  DialogContext
+	Prompt   string // Prompt sent to the model, when the backend exposes it
+	Response DialogResponse
+	Err      error
+	Failures []string // Unmet expectations
+}
+
+// promptPreviewer is implemented by backends that can show the prompt a context produces
+type promptPreviewer interface {
+	buildPrompt(ctx DialogContext) string
+}
+
+// LoadTranscript reads a transcript from a JSON file
+func LoadTranscript(path string) (Transcript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	var transcript Transcript
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&transcript); err != nil {
+		return Transcript{}, fmt.Errorf("failed to parse transcript %s: %w", path, err)
+	}
+	if len(transcript.Turns) == 0 {
+		return Transcript{}, fmt.Errorf("transcript %s has no turns", path)
+	}
+	return transcript, nil
+}
+
+// ReplayTranscript sends each turn's context to the backend in order and checks its expectations
+// When Start is set a ManualClock drives timestamps and the system clock is restored afterwards
+func ReplayTranscript(backend DialogBackend, transcript Transcript) TranscriptResult {
+	if transcript.Seed != 0 {
+		SetRandomSeed(transcript.Seed)
+	}
+	var clock *ManualClock
+	if !transcript.Start.IsZero() {
+		clock = NewManualClock(transcript.Start)
+		SetClock(clock)
+		defer SetClock(nil)
+	}
+
+	previewer, _ := backend.(promptPreviewer)
+	result := TranscriptResult{Name: transcript.Name, Nondeterministic: transcript.Nondeterministic}
+	for _, turn := range transcript.Turns {
+		if clock != nil && turn.AdvanceMs > 0 {
+			clock.Advance(time.Duration(turn.AdvanceMs) * time.Millisecond)
+		}
+		context := turn.Context
+		if context.Timestamp.IsZero() {
+			context.Timestamp = currentTime()
+		}
+
+		turnResult := TurnResult{Context: context}
+		if previewer != nil {
+			turnResult.Prompt = previewer.buildPrompt(context)
+		}
+		turnResult.Response, turnResult.Err = backend.GenerateResponse(context)
+		if turn.Expect != nil {
+			turnResult.Failures = turn.Expect.Check(turnResult.Response, turnResult.Err)
+		}
+		result.Turns = append(result.Turns, turnResult)
+	}
+	return result
+}
+
+// Check returns a description of every expectation the response does not meet
+func (e ResponseExpectation) Check(response DialogResponse, err error) []string {
+	if e.Error {
+		if err == nil {
+			return []string{"expected an error, got none"}
+		}
+		return nil
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("unexpected error: %v", err)}
+	}
+
+	var failures []string
+	failf := func(format string, args ...interface{}) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+
+	text := response.Text
+	if e.Text != "" && text != e.Text {
+		failf("expected text %q, got %q", e.Text, text)
+	}
+	for _, want := range e.Contains {
+		if !strings.Contains(text, want) {
+			failf("expected text to contain %q, got %q", want, text)
+		}
+	}
+	for _, unwanted := range e.NotContains {
+		if strings.Contains(text, unwanted) {
+			failf("expected text not to contain %q, got %q", unwanted, text)
+		}
+	}
+	if length := len([]rune(text)); length < e.MinLength || (e.MaxLength > 0 && length > e.MaxLength) {
+		failf("expected text length in [%d, %d], got %d", e.MinLength, e.MaxLength, length)
+	}
+	if e.ResponseType != "" && response.ResponseType != e.ResponseType {
+		failf("expected response type %q, got %q", e.ResponseType, response.ResponseType)
+	}
+	if e.Animation != "" && response.Animation != e.Animation {
+		failf("expected animation %q, got %q", e.Animation, response.Animation)
+	}
+	if e.EmotionalTone != "" && response.EmotionalTone != e.EmotionalTone {
+		failf("expected emotional tone %q, got %q", e.EmotionalTone, response.EmotionalTone)
+	}
+	if response.Confidence < e.MinConfidence {
+		failf("expected confidence >= %.2f, got %.2f", e.MinConfidence, response.Confidence)
+	}
+	return failures
+}
+
+// Failures lists every unmet expectation, prefixed with its turn
+func (r TranscriptResult) Failures() []string {
+	var failures []string
+	for i, turn := range r.Turns {
+		for _, failure := range turn.Failures {
+			failures = append(failures, fmt.Sprintf("%s turn %d (%s): %s", r.Name, i+1, turn.Context.Trigger, failure))
+		}
+	}
+	return failures
+}
+
+// GoldenText renders prompts and responses in a stable, diff-friendly form for golden files
+// Nondeterministic transcripts record only their turns, since prompts embed earlier replies;
+// their responses are covered by expectations instead
+func (r TranscriptResult) GoldenText() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# transcript: %s\n", r.Name)
+	for i, turn := range r.Turns {
+		fmt.Fprintf(&out, "\n## turn %d: %s (%s)\n", i+1, turn.Context.Trigger, turn.Context.InteractionID)
+		if turn.Context.UserMessage != "" {
+			fmt.Fprintf(&out, "user: %s\n", turn.Context.UserMessage)
+		}
+		if r.Nondeterministic {
+			continue
+		}
+		if turn.Prompt != "" {
+			fmt.Fprintf(&out, "### prompt\n%s\n", strings.TrimRight(turn.Prompt, "\n"))
+		}
+		fmt.Fprintf(&out, "### response\n")
+		if turn.Err != nil {
+			fmt.Fprintf(&out, "error: %v\n", turn.Err)
+			continue
+		}
+		fmt.Fprintf(&out, "text: %s\n", turn.Response.Text)
+		fmt.Fprintf(&out, "type: %s\n", turn.Response.ResponseType)
+		fmt.Fprintf(&out, "tone: %s\n", turn.Response.EmotionalTone)
+		fmt.Fprintf(&out, "animation: %s\n", turn.Response.Animation)
+	}
+	return out.String()
+}
@@ -0,0 +1,167 @@
+package dialog
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// TranscriptFormat selects how RenderTranscript lays out a conversation
+type TranscriptFormat string
+
+const (
+	TranscriptMarkdown TranscriptFormat = "markdown" // Markdown, for issues, chat and docs
+	TranscriptHTML     TranscriptFormat = "html"     // Standalone HTML page
+)
+
+// TranscriptOptions controls how a conversation is rendered for reading
+type TranscriptOptions struct {
+	Format        TranscriptFormat // Output format (default: markdown)
+	Title         string           // Heading (default: "Conversation with <CharacterName>")
+	CharacterName string           // Label for the character's lines (default: "Character")
+	UserName      string           // Label for the user's lines (default: "User")
+}
+
+// withDefaults fills in unset options
+func (o TranscriptOptions) withDefaults() TranscriptOptions {
+	if o.Format == "" {
+		o.Format = TranscriptMarkdown
+	}
+	if o.CharacterName == "" {
+		o.CharacterName = "Character"
+	}
+	if o.UserName == "" {
+		o.UserName = "User"
+	}
+	if o.Title == "" {
+		o.Title = "Conversation with " + o.CharacterName
+	}
+	return o
+}
+
+// transcriptTimeLayout is how exchange timestamps are shown, in their own time zone
+const transcriptTimeLayout = "2006-01-02 15:04:05 MST"
+
+// RenderTranscript renders a conversation as a readable transcript with each exchange's
+// time, trigger, what the user said, the response and any feedback, for sharing or
+// debugging character behavior
+func RenderTranscript(history ConversationHistory, opts TranscriptOptions) (string, error) {
+	opts = opts.withDefaults()
+	switch opts.Format {
+	case TranscriptMarkdown:
+		return renderTranscriptMarkdown(history, opts), nil
+	case TranscriptHTML:
+		return renderTranscriptHTML(history, opts), nil
+	default:
+		return "", fmt.Errorf("unknown transcript format %q (want %q or %q)", opts.Format, TranscriptMarkdown, TranscriptHTML)
+	}
+}
+
+// ExportTranscript renders an interaction's conversation history with RenderTranscript
+func (cm *ContextManager) ExportTranscript(interactionID string, opts TranscriptOptions) (string, error) {
+	history, exists := cm.conversationCopy(interactionID)
+	if !exists {
+		return "", fmt.Errorf("no conversation history for interaction '%s'", interactionID)
+	}
+	return RenderTranscript(history, opts)
+}
+
+// transcriptUserLine describes the user's side of an exchange: what they said, or the
+// action they took
+func transcriptUserLine(exchange ConversationExchange) (text string, spoken bool) {
+	if exchange.UserMessage != "" {
+		return exchange.UserMessage, true
+	}
+	return triggerDescription(exchange.Trigger), false
+}
+
+// transcriptFeedback describes the feedback on an exchange, or "" when there was none
+func transcriptFeedback(exchange ConversationExchange) string {
+	if !exchange.FeedbackReceived {
+		return ""
+	}
+	verdict := "👎 disliked"
+	if exchange.UserFeedback {
+		verdict = "👍 liked"
+	}
+	return fmt.Sprintf("%s (engagement %.2f)", verdict, exchange.EngagementScore)
+}
+
+// transcriptSummary is the line under the title: the interaction and the time span
+func transcriptSummary(history ConversationHistory) string {
+	summary := fmt.Sprintf("Interaction %s · %d exchanges", history.InteractionID, len(history.Exchanges))
+	if n := len(history.Exchanges); n > 0 {
+		summary += fmt.Sprintf(" · %s to %s",
+			history.Exchanges[0].Timestamp.Format(transcriptTimeLayout),
+			history.Exchanges[n-1].Timestamp.Format(transcriptTimeLayout))
+	}
+	return summary
+}
+
+// renderTranscriptMarkdown lays a conversation out as Markdown, quoting free text so
+// it cannot break the document structure
+func renderTranscriptMarkdown(history ConversationHistory, opts TranscriptOptions) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# %s\n\n%s\n", opts.Title, transcriptSummary(history))
+
+	for _, exchange := range history.Exchanges {
+		fmt.Fprintf(&out, "\n### %s · `%s`\n\n", exchange.Timestamp.Format(transcriptTimeLayout), exchange.Trigger)
+		if exchange.Speaker != "" {
+			fmt.Fprintf(&out, "**%s:**\n%s\n", exchange.Speaker, markdownQuote(exchange.Response))
+			continue
+		}
+		if text, spoken := transcriptUserLine(exchange); spoken {
+			fmt.Fprintf(&out, "**%s:**\n%s\n\n", opts.UserName, markdownQuote(text))
+		} else {
+			fmt.Fprintf(&out, "*%s %s*\n\n", opts.UserName, text)
+		}
+		fmt.Fprintf(&out, "**%s:**\n%s\n", opts.CharacterName, markdownQuote(exchange.Response))
+		if feedback := transcriptFeedback(exchange); feedback != "" {
+			fmt.Fprintf(&out, "\nFeedback: %s\n", feedback)
+		}
+	}
+	return out.String()
+}
+
+// markdownQuote renders text as a Markdown blockquote
+func markdownQuote(text string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n> ")
+}
+
+// transcriptStyle keeps exported HTML pages readable without external assets
+const transcriptStyle = `body{font-family:sans-serif;max-width:46em;margin:2em auto;padding:0 1em;color:#222}
+.meta{color:#666}.exchange{border-top:1px solid #ddd;padding:.6em 0}
+.time{color:#888;font-size:.85em}.trigger{font-family:monospace}
+.user{margin:.3em 0}.character{margin:.3em 0;padding:.3em .6em;background:#f3f0ff;border-radius:.4em}
+.action{font-style:italic;color:#555}.feedback{font-size:.85em;color:#555}`
+
+// renderTranscriptHTML lays a conversation out as a standalone HTML page
+func renderTranscriptHTML(history ConversationHistory, opts TranscriptOptions) string {
+	esc := html.EscapeString
+	var out strings.Builder
+	fmt.Fprintf(&out, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n",
+		esc(opts.Title), transcriptStyle)
+	fmt.Fprintf(&out, "<h1>%s</h1>\n<p class=\"meta\">%s</p>\n", esc(opts.Title), esc(transcriptSummary(history)))
+
+	for _, exchange := range history.Exchanges {
+		out.WriteString("<div class=\"exchange\">\n")
+		fmt.Fprintf(&out, "<div class=\"time\">%s · <span class=\"trigger\">%s</span></div>\n",
+			esc(exchange.Timestamp.Format(transcriptTimeLayout)), esc(exchange.Trigger))
+		if exchange.Speaker != "" {
+			fmt.Fprintf(&out, "<div class=\"character\"><b>%s:</b> %s</div>\n</div>\n", esc(exchange.Speaker), esc(exchange.Response))
+			continue
+		}
+		if text, spoken := transcriptUserLine(exchange); spoken {
+			fmt.Fprintf(&out, "<div class=\"user\"><b>%s:</b> %s</div>\n", esc(opts.UserName), esc(text))
+		} else {
+			fmt.Fprintf(&out, "<div class=\"user action\">%s %s</div>\n", esc(opts.UserName), esc(text))
+		}
+		fmt.Fprintf(&out, "<div class=\"character\"><b>%s:</b> %s</div>\n", esc(opts.CharacterName), esc(exchange.Response))
+		if feedback := transcriptFeedback(exchange); feedback != "" {
+			fmt.Fprintf(&out, "<div class=\"feedback\">Feedback: %s</div>\n", esc(feedback))
+		}
+		out.WriteString("</div>\n")
+	}
+	out.WriteString("</body>\n</html>\n")
+	return out.String()
+}
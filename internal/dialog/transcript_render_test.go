@@ -0,0 +1,106 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTranscriptTestManager returns a context manager holding a short conversation with feedback
+func newTranscriptTestManager(t *testing.T) *ContextManager {
+	t.Helper()
+	cm := NewContextManager(10)
+	t.Cleanup(cm.Close)
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	cm.RecordExchange("sam", ConversationExchange{Timestamp: start, Trigger: "feed", Response: "Yum, thank you!"})
+	cm.UpdateFeedback("sam", true, 0.8)
+	cm.RecordExchange("sam", ConversationExchange{
+		Timestamp:   start.Add(time.Minute),
+		Trigger:     "talk",
+		UserMessage: "Do you like <b>cake</b>?",
+		Response:    "Cake is great!\nEspecially with sprinkles.",
+	})
+	cm.UpdateFeedback("sam", false, 0.2)
+	return cm
+}
+
+func TestExportTranscript_Markdown(t *testing.T) {
+	cm := newTranscriptTestManager(t)
+
+	transcript, err := cm.ExportTranscript("sam", TranscriptOptions{CharacterName: "Buddy", UserName: "Sam"})
+	if err != nil {
+		t.Fatalf("ExportTranscript failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# Conversation with Buddy\n",
+		"Interaction sam · 2 exchanges · 2026-03-01 09:00:00 UTC to 2026-03-01 09:01:00 UTC",
+		"### 2026-03-01 09:00:00 UTC · `feed`",
+		"*Sam fed you*",
+		"**Buddy:**\n> Yum, thank you!",
+		"Feedback: 👍 liked (engagement 0.80)",
+		"**Sam:**\n> Do you like <b>cake</b>?",
+		"> Cake is great!\n> Especially with sprinkles.",
+		"Feedback: 👎 disliked (engagement 0.20)",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("Expected transcript to contain %q, got:\n%s", want, transcript)
+		}
+	}
+}
+
+func TestExportTranscript_HTML(t *testing.T) {
+	cm := newTranscriptTestManager(t)
+
+	transcript, err := cm.ExportTranscript("sam", TranscriptOptions{Format: TranscriptHTML, Title: "Bug <report>"})
+	if err != nil {
+		t.Fatalf("ExportTranscript failed: %v", err)
+	}
+
+	if !strings.HasPrefix(transcript, "<!DOCTYPE html>") || !strings.HasSuffix(transcript, "</html>\n") {
+		t.Errorf("Expected a complete HTML document, got:\n%s", transcript)
+	}
+	for _, want := range []string{
+		"<h1>Bug &lt;report&gt;</h1>",
+		"<div class=\"user action\">User fed you</div>",
+		"<b>User:</b> Do you like &lt;b&gt;cake&lt;/b&gt;?",
+		"<b>Character:</b> Yum, thank you!",
+		"Feedback: 👍 liked (engagement 0.80)",
+	} {
+		if !strings.Contains(transcript, want) {
+			t.Errorf("Expected transcript to contain %q, got:\n%s", want, transcript)
+		}
+	}
+	if strings.Contains(transcript, "<b>cake</b>") {
+		t.Error("Expected user text to be escaped")
+	}
+}
+
+func TestExportTranscript_Errors(t *testing.T) {
+	cm := newTranscriptTestManager(t)
+
+	if _, err := cm.ExportTranscript("nobody", TranscriptOptions{}); err == nil {
+		t.Error("Expected an error for an unknown interaction")
+	}
+	if _, err := cm.ExportTranscript("sam", TranscriptOptions{Format: "pdf"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestRenderTranscript_MultiCharacter(t *testing.T) {
+	history := ConversationHistory{
+		InteractionID: "room",
+		Exchanges: []ConversationExchange{
+			{Trigger: "talk", Speaker: "Whiskers", Response: "Meow."},
+		},
+	}
+
+	transcript, err := RenderTranscript(history, TranscriptOptions{})
+	if err != nil {
+		t.Fatalf("RenderTranscript failed: %v", err)
+	}
+	if !strings.Contains(transcript, "**Whiskers:**\n> Meow.") {
+		t.Errorf("Expected the speaker's line, got:\n%s", transcript)
+	}
+}
@@ -0,0 +1,169 @@
+package dialog
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Regenerate golden files after an intended prompt change with:
+//
+//	go test ./internal/dialog -run TestGoldenTranscripts -update
+var updateGolden = flag.Bool("update", false, "rewrite golden transcript files")
+
+// newTranscriptBackend creates an LLM backend answering from the shared test fixture
+func newTranscriptBackend(t *testing.T) *LLMBackend {
+	t.Helper()
+	configJSON, _ := json.Marshal(LLMConfig{
+		ModelPath:       "/models/transcript.gguf",
+		MockFixture:     filepath.Join("testdata", "model_fixture.json"),
+		FallbackEnabled: true,
+		MarkovConfig: MarkovChainConfig{
+			TrainingData: []string{
+				"Yay, snack time is the best time!",
+				"You always know how to cheer me up!",
+				"Hehe, that tickles!",
+				"I was hoping you'd come say hi.",
+			},
+		},
+	})
+
+	backend := NewLLMBackend()
+	if err := backend.Initialize(configJSON); err != nil {
+		t.Fatalf("Failed to initialize backend: %v", err)
+	}
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+// TestGoldenTranscripts replays every recorded transcript and compares prompts and
+// responses with its golden file, so prompt builder changes show up as diffs
+func TestGoldenTranscripts(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "transcripts", "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("No transcripts found: %v", err)
+	}
+	restoreDeterminism(t)
+
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			transcript, err := LoadTranscript(path)
+			if err != nil {
+				t.Fatalf("LoadTranscript() failed: %v", err)
+			}
+
+			result := ReplayTranscript(newTranscriptBackend(t), transcript)
+			for _, failure := range result.Failures() {
+				t.Error(failure)
+			}
+
+			goldenPath := strings.TrimSuffix(path, ".json") + ".golden"
+			actual := result.GoldenText()
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, []byte(actual), 0o644); err != nil {
+					t.Fatalf("Failed to write golden file: %v", err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if string(expected) != actual {
+				t.Errorf("Transcript differs from %s (run with -update if the change is intended):\n%s",
+					goldenPath, firstDifference(string(expected), actual))
+			}
+		})
+	}
+}
+
+// firstDifference describes the first line where two golden texts differ
+func firstDifference(expected, actual string) string {
+	expectedLines, actualLines := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	for i := 0; i < max(len(expectedLines), len(actualLines)); i++ {
+		var want, got string
+		if i < len(expectedLines) {
+			want = expectedLines[i]
+		}
+		if i < len(actualLines) {
+			got = actualLines[i]
+		}
+		if want != got {
+			return fmt.Sprintf("line %d:\n  expected: %s\n  actual:   %s", i+1, want, got)
+		}
+	}
+	return ""
+}
+
+func TestResponseExpectation_Check(t *testing.T) {
+	response := DialogResponse{Text: "Yum, thank you!", ResponseType: "casual", Animation: "eating", Confidence: 0.6}
+
+	passing := ResponseExpectation{
+		Contains:      []string{"Yum"},
+		NotContains:   []string{"sad"},
+		MinLength:     3,
+		MaxLength:     40,
+		ResponseType:  "casual",
+		Animation:     "eating",
+		MinConfidence: 0.5,
+	}
+	if failures := passing.Check(response, nil); len(failures) != 0 {
+		t.Errorf("Expected no failures, got %v", failures)
+	}
+
+	failing := ResponseExpectation{Text: "Hello", Contains: []string{"pizza"}, MaxLength: 5, MinConfidence: 0.9}
+	if failures := failing.Check(response, nil); len(failures) != 4 {
+		t.Errorf("Expected 4 failures, got %d: %v", len(failures), failures)
+	}
+
+	if failures := (ResponseExpectation{Error: true}).Check(response, nil); len(failures) != 1 {
+		t.Errorf("Expected a missing error to fail, got %v", failures)
+	}
+	if failures := (ResponseExpectation{}).Check(response, errors.New("boom")); len(failures) != 1 {
+		t.Errorf("Expected an unexpected error to fail, got %v", failures)
+	}
+}
+
+func TestReplayTranscript_NondeterministicGoldenOmitsResponses(t *testing.T) {
+	restoreDeterminism(t)
+	transcript := Transcript{
+		Name:             "varied",
+		Nondeterministic: true,
+		Turns: []TranscriptTurn{{
+			Context: DialogContext{Trigger: "click", InteractionID: "varied"},
+			Expect:  &ResponseExpectation{MinLength: 1},
+		}},
+	}
+
+	result := ReplayTranscript(newTranscriptBackend(t), transcript)
+	if failures := result.Failures(); len(failures) != 0 {
+		t.Errorf("Expected expectations to pass, got %v", failures)
+	}
+	if golden := result.GoldenText(); strings.Contains(golden, "text:") || strings.Contains(golden, "### prompt") {
+		t.Errorf("Expected nondeterministic golden text to omit prompts and responses, got:\n%s", golden)
+	}
+	if result.Turns[0].Prompt == "" {
+		t.Error("Expected the LLM backend prompt to be recorded")
+	}
+}
+
+func TestLoadTranscript_RejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	unknown := filepath.Join(dir, "unknown.json")
+	os.WriteFile(unknown, []byte(`{"name": "x", "turns": [{"context": {}}], "extra": true}`), 0o644)
+	if _, err := LoadTranscript(unknown); err == nil {
+		t.Error("Expected unknown fields to be rejected")
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	os.WriteFile(empty, []byte(`{"name": "x", "turns": []}`), 0o644)
+	if _, err := LoadTranscript(empty); err == nil {
+		t.Error("Expected a transcript without turns to be rejected")
+	}
+}
@@ -0,0 +1,116 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TriggerDefinition describes a host-defined trigger such as "homework_done" or
+// "battery_low" so prompts read naturally instead of showing the raw trigger name
+type TriggerDefinition struct {
+	Name        string `json:"name"`                // Trigger code the host raises
+	Description string `json:"description"`         // Completes "The user ...", e.g. "finished their homework"
+	Animation   string `json:"animation,omitempty"` // Played when the response suggests no other animation
+}
+
+// builtinTriggers describes the triggers the package and its hosts raise out of the box
+var builtinTriggers = map[string]string{
+	"click":      "clicked on you",
+	"rightclick": "right-clicked on you",
+	"hover":      "hovered over you",
+	"feed":       "fed you",
+	"pet":        "petted you",
+	"play":       "wants to play",
+	"talk":       "wants to talk",
+	"gift":       "gave you a gift",
+	"compliment": "complimented you",
+	"ignore":     "ignored you",
+	"idle":       "you've been idle",
+	"timer":      "time passed",
+	"check_in":   "has been away for a while, so you are checking in on them",
+	"reminder":   "asked you to remind them of something",
+
+	CalendarEventTrigger:       "came by on a special day",
+	ConversationStarterTrigger: "just came back to the computer",
+	CharacterChatTrigger:       "watched you chat with another character",
+	VoiceTrigger:               "talked to you",
+	VoiceUnclearTrigger:        "said something you couldn't quite hear",
+}
+
+// Package-wide custom triggers, shared by every backend
+var (
+	triggersMu     sync.RWMutex
+	customTriggers = map[string]TriggerDefinition{}
+)
+
+// RegisterTrigger adds or replaces a custom trigger; registering a built-in trigger's
+// name overrides its description
+func RegisterTrigger(definition TriggerDefinition) error {
+	if err := validateTrigger(definition); err != nil {
+		return err
+	}
+	triggersMu.Lock()
+	defer triggersMu.Unlock()
+	customTriggers[definition.Name] = definition
+	return nil
+}
+
+// UnregisterTrigger removes a custom trigger, restoring the built-in description if any
+func UnregisterTrigger(name string) {
+	triggersMu.Lock()
+	defer triggersMu.Unlock()
+	delete(customTriggers, name)
+}
+
+// LookupTrigger returns the registered definition of a custom trigger
+func LookupTrigger(name string) (TriggerDefinition, bool) {
+	triggersMu.RLock()
+	defer triggersMu.RUnlock()
+	definition, ok := customTriggers[name]
+	return definition, ok
+}
+
+// validateTrigger checks that a definition names and describes its trigger
+func validateTrigger(definition TriggerDefinition) error {
+	if strings.TrimSpace(definition.Name) == "" {
+		return fmt.Errorf("trigger name is required")
+	}
+	if strings.TrimSpace(definition.Description) == "" {
+		return fmt.Errorf("trigger %q needs a description", definition.Name)
+	}
+	return nil
+}
+
+// triggerDescription describes a trigger in natural language, preferring a registered
+// definition over the built-ins and falling back to the trigger name
+func triggerDescription(trigger string) string {
+	if definition, ok := LookupTrigger(trigger); ok {
+		return definition.Description
+	}
+	if description, ok := builtinTriggers[trigger]; ok {
+		return description
+	}
+	return trigger
+}
+
+// triggerAnimation returns the default animation registered for a trigger, if any
+func triggerAnimation(trigger string) string {
+	definition, _ := LookupTrigger(trigger)
+	return definition.Animation
+}
+
+// applyTriggers registers the custom triggers listed in the configuration
+func (llm *LLMBackend) applyTriggers(cfg LLMConfig) error {
+	for _, definition := range cfg.Triggers {
+		if err := validateTrigger(definition); err != nil {
+			return invalidConfig("triggers", err)
+		}
+	}
+	triggersMu.Lock()
+	defer triggersMu.Unlock()
+	for _, definition := range cfg.Triggers {
+		customTriggers[definition.Name] = definition
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package dialog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterTrigger(t *testing.T) {
+	defer UnregisterTrigger("battery_low")
+	defer UnregisterTrigger("feed")
+
+	if description := triggerDescription("battery_low"); description != "battery_low" {
+		t.Errorf("Expected an unknown trigger to fall back to its name, got %q", description)
+	}
+	if err := RegisterTrigger(TriggerDefinition{Name: "battery_low"}); err == nil {
+		t.Error("Expected a trigger without a description to be rejected")
+	}
+
+	if err := RegisterTrigger(TriggerDefinition{Name: "battery_low", Description: "let the battery run low", Animation: "sleepy"}); err != nil {
+		t.Fatalf("RegisterTrigger() failed: %v", err)
+	}
+	if description := triggerDescription("battery_low"); description != "let the battery run low" {
+		t.Errorf("Expected the registered description, got %q", description)
+	}
+	if animation := triggerAnimation("battery_low"); animation != "sleepy" {
+		t.Errorf("Expected the registered animation, got %q", animation)
+	}
+
+	RegisterTrigger(TriggerDefinition{Name: "feed", Description: "gave you a snack"})
+	if description := triggerDescription("feed"); description != "gave you a snack" {
+		t.Errorf("Expected a registration to override the built-in description, got %q", description)
+	}
+	UnregisterTrigger("feed")
+	if description := triggerDescription("feed"); description != "fed you" {
+		t.Errorf("Expected the built-in description after unregistering, got %q", description)
+	}
+}
+
+func TestLLMBackend_ConfigTriggers(t *testing.T) {
+	defer UnregisterTrigger("homework_done")
+
+	model := &scriptedTestModel{responses: []string{"Well done, I knew you could do it!"}}
+	backend := newScriptedBackend(t, LLMConfig{
+		Triggers: []TriggerDefinition{{Name: "homework_done", Description: "just finished their homework", Animation: "cheer"}},
+	}, model)
+
+	ctx := DialogContext{Trigger: "homework_done", InteractionID: "homework"}
+	if prompt := backend.buildPrompt(ctx); !strings.Contains(prompt, "just finished their homework") {
+		t.Errorf("Expected the prompt to describe the custom trigger, got:\n%s", prompt)
+	}
+	response, err := backend.GenerateResponse(ctx)
+	if err != nil {
+		t.Fatalf("GenerateResponse() failed: %v", err)
+	}
+	if response.Animation != "cheer" {
+		t.Errorf("Expected the trigger's default animation, got %q", response.Animation)
+	}
+}
+
+func TestLLMBackend_ConfigTriggersInvalid(t *testing.T) {
+	backend := NewLLMBackend()
+	err := backend.applyConfig(LLMConfig{ModelPath: "model.gguf", Triggers: []TriggerDefinition{{Description: "did something"}}})
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Field != "triggers" {
+		t.Errorf("Expected a triggers config error, got %v", err)
+	}
+}
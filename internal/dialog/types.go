@@ -1,8 +1,11 @@
 package dialog
 
 import (
+	"context"
+	"crypto/cipher"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -25,6 +28,15 @@ type DialogBackend interface {
 	// UpdateMemory allows the backend to record interaction outcomes for learning
 	// This enables backends to adapt based on user interactions
 	UpdateMemory(context DialogContext, response DialogResponse, userFeedback *UserFeedback) error
+
+	// HealthCheck reports whether the backend is ready to generate responses
+	// Implementations should be cheap enough to call from diagnostics screens
+	HealthCheck(ctx context.Context) error
+
+	// SaveState serializes everything the backend has learned (history, adaptations)
+	// so hosts can checkpoint it at exit; LoadState restores such a checkpoint
+	SaveState() ([]byte, error)
+	LoadState(data []byte) error
 }
 
 // DialogContext provides complete context for dialog generation
@@ -34,34 +46,66 @@ type DialogContext struct {
 	InteractionID string    `json:"interactionId"` // Unique identifier for this interaction
 	Timestamp     time.Time `json:"timestamp"`
 
+	// Multi-tenant server context
+	TenantID string `json:"tenantId,omitempty"` // Namespace registered with RegisterTenant ("" = no tenant)
+
 	// Character state context
-	CurrentStats      map[string]float64 `json:"currentStats"`      // Current stat values
-	PersonalityTraits map[string]float64 `json:"personalityTraits"` // Character personality
-	CurrentMood       float64            `json:"currentMood"`       // Overall mood (0-100)
-	CurrentAnimation  string             `json:"currentAnimation"`  // Current character state
+	CurrentStats      map[string]float64 `json:"currentStats"`         // Current stat values
+	PersonalityTraits map[string]float64 `json:"personalityTraits"`    // Character personality
+	CurrentMood       float64            `json:"currentMood"`          // Overall mood (0-100)
+	MoodChange        float64            `json:"moodChange,omitempty"` // Mood change just caused by this interaction (set by a MoodEngine)
+	CurrentAnimation  string             `json:"currentAnimation"`     // Current character state
 
 	// Relationship/game context
 	RelationshipLevel  string              `json:"relationshipLevel,omitempty"`  // Current relationship stage
 	InteractionHistory []InteractionRecord `json:"interactionHistory,omitempty"` // Recent interactions
 	AchievementStatus  map[string]bool     `json:"achievementStatus,omitempty"`  // Unlocked achievements
+	UserFacts          []UserFact          `json:"userFacts,omitempty"`          // What the character knows about the user
+	WorldEvents        []WorldEvent        `json:"worldEvents,omitempty"`        // What recently happened to other characters on the desktop
 	TimeOfDay          string              `json:"timeOfDay,omitempty"`          // "morning", "afternoon", "evening", "night"
+	DayOfWeek          string              `json:"dayOfWeek,omitempty"`          // "monday" ... "sunday"
+	IsWeekend          bool                `json:"isWeekend,omitempty"`          // Saturday or Sunday
+	IdleDuration       time.Duration       `json:"idleDuration,omitempty"`       // Time since the previous interaction (0 = unknown)
 
 	// Conversation context
+	UserMessage      string                 `json:"userMessage,omitempty"`  // What the user typed, e.g. in a chat box
 	LastResponse     string                 `json:"lastResponse,omitempty"` // Previous dialog response
 	ConversationTurn int                    `json:"conversationTurn"`       // Turn number in current conversation
 	TopicContext     map[string]interface{} `json:"topicContext,omitempty"` // Current conversation topics
+	VoiceInput       *VoiceInput            `json:"voiceInput,omitempty"`   // What the user said out loud, for VoiceTrigger
+
+	// Multi-character conversation context
+	CharacterID  string                 `json:"characterId,omitempty"`  // Character whose isolated history a shared backend uses ("" = shared history)
+	Speaker      string                 `json:"speaker,omitempty"`      // Registered character responding when characters take turns
+	Partners     []string               `json:"partners,omitempty"`     // Other characters in the conversation
+	Conversation []ConversationExchange `json:"conversation,omitempty"` // Shared transcript so far; replaces backend history in the prompt
+
+	// Request options
+	TimeoutMs  int             `json:"timeoutMs,omitempty"` // Generation time budget overriding the backend's timeoutMs (0 = backend default)
+	Priority   string          `json:"priority,omitempty"`  // PriorityInteractive or PriorityBackground, for backends queueing generations ("" = interactive)
+	requestCtx context.Context // Cancels generation early, set by GenerateDialogAsync (nil = never)
 
 	// Fallback configuration
 	FallbackResponses []string `json:"fallbackResponses"` // Default responses if backend fails
 	FallbackAnimation string   `json:"fallbackAnimation"` // Default animation if backend fails
 }
 
+// requestContext is the context generation for c runs under
+func (c DialogContext) requestContext() context.Context {
+	if c.requestCtx != nil {
+		return c.requestCtx
+	}
+	return context.Background()
+}
+
 // DialogResponse contains the generated response and associated metadata
 type DialogResponse struct {
 	// Response content
-	Text      string `json:"text"`                // The dialog text to display
-	Animation string `json:"animation,omitempty"` // Animation to trigger with response
-	Duration  int    `json:"duration,omitempty"`  // Display duration in seconds (0 = default)
+	Text      string   `json:"text"`                // The dialog text to display
+	Animation string   `json:"animation,omitempty"` // Animation to trigger with response
+	Duration  int      `json:"duration,omitempty"`  // Display duration in seconds (0 = default)
+	Actions   []Action `json:"actions,omitempty"`   // Host actions the character requested
+	MoodDelta float64  `json:"moodDelta,omitempty"` // Suggested change to CurrentMood for the host to apply
 
 	// Response metadata
 	Confidence    float64                `json:"confidence"`              // Backend confidence in response (0-1)
@@ -69,6 +113,7 @@ type DialogResponse struct {
 	EmotionalTone string                 `json:"emotionalTone,omitempty"` // "happy", "sad", "flirty", "shy", etc.
 	Topics        []string               `json:"topics,omitempty"`        // Topics covered in this response
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`      // Backend-specific metadata
+	Speech        *SpeechHints           `json:"speech,omitempty"`        // How to say the response (set by DialogManager.SetSpeech)
 
 	// Memory and learning
 	MemoryImportance float64 `json:"memoryImportance,omitempty"` // How important is this for memory (0-1)
@@ -105,10 +150,34 @@ type BackendInfo struct {
 
 // DialogManager orchestrates multiple backends and handles fallbacks
 type DialogManager struct {
-	backends       map[string]DialogBackend
-	defaultBackend string
-	fallbackChain  []string
-	debug          bool
+	backends        map[string]DialogBackend
+	defaultBackend  string
+	fallbackChain   []string
+	middleware      []Middleware
+	experiment      *experiment
+	rateLimiter     *rateLimiter
+	moodEngine      *MoodEngine
+	drift           *PersonalityDrift
+	userMemory      *UserMemory
+	characters      map[string]Character
+	world           *worldView
+	speech          *speechHook
+	routes          map[string]string
+	overloadBackend string           // Stands in for an overloaded backend ("" = none)
+	reranker        Reranker         // Picks among below-threshold responses (nil = highest confidence)
+	recorder        *SessionRecorder // Records backend calls and feedback (nil = off)
+	tenants         map[string]*tenant
+	stateCipher     cipher.AEAD
+	greetedEvents   map[string]string        // Date each conversation's calendar event was last reported due
+	threshold       float64                  // Minimum default-backend confidence before the fallback chain is tried
+	responseTimeout time.Duration            // Budget for the whole backend chain (0 = unbounded)
+	backendTimeouts map[string]time.Duration // Limit on each call to a backend, by name
+	closing         bool                     // Set by Shutdown; new requests are rejected
+	inFlight        sync.WaitGroup
+	debug           bool
+	stats           *responseStats
+	events          *EventBus
+	mu              sync.RWMutex
 }
 
 // NewDialogManager creates a new dialog manager with no backends registered
@@ -116,17 +185,34 @@ func NewDialogManager(debug bool) *DialogManager {
 	return &DialogManager{
 		backends:      make(map[string]DialogBackend),
 		fallbackChain: []string{},
+		threshold:     defaultConfidenceThreshold,
 		debug:         debug,
+		stats:         newResponseStats(),
+		events:        NewEventBus(),
 	}
 }
 
 // RegisterBackend adds a new dialog backend to the manager
 func (dm *DialogManager) RegisterBackend(name string, backend DialogBackend) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
 	dm.backends[name] = backend
+
+	if publisher, ok := backend.(EventPublisher); ok {
+		publisher.SetEventBus(dm.events)
+	}
+	if store, ok := backend.(TenantHistoryStore); ok {
+		for id, tenant := range dm.tenants {
+			store.ConfigureTenant(id, tenant.config.MaxConversations)
+		}
+	}
 }
 
 // SetDefaultBackend sets the primary backend to use for dialog generation
 func (dm *DialogManager) SetDefaultBackend(name string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
 	if _, exists := dm.backends[name]; !exists {
 		return fmt.Errorf("backend '%s' not registered", name)
 	}
@@ -136,6 +222,9 @@ func (dm *DialogManager) SetDefaultBackend(name string) error {
 
 // SetFallbackChain configures the order of backends to try if primary fails
 func (dm *DialogManager) SetFallbackChain(backends []string) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
 	for _, name := range backends {
 		if _, exists := dm.backends[name]; !exists {
 			return fmt.Errorf("fallback backend '%s' not registered", name)
@@ -145,49 +234,177 @@ func (dm *DialogManager) SetFallbackChain(backends []string) error {
 	return nil
 }
 
+// defaultConfidenceThreshold matches the DialogBackendConfig default
+const defaultConfidenceThreshold = 0.5
+
+// SetConfidenceThreshold sets the minimum confidence a default backend response needs
+// Responses below the threshold fall through to the fallback chain
+func (dm *DialogManager) SetConfidenceThreshold(threshold float64) error {
+	if threshold < 0 || threshold > 1 {
+		return fmt.Errorf("confidence threshold must be between 0 and 1, got %f", threshold)
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.threshold = threshold
+	return nil
+}
+
+// GetConfidenceThreshold returns the minimum confidence for default backend responses
+func (dm *DialogManager) GetConfidenceThreshold() float64 {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.threshold
+}
+
 // GenerateDialog produces a dialog response using the configured backend chain
+// Registered middleware runs around backend selection; if the chain returns an
+// error the canned fallback response is returned together with that error
 func (dm *DialogManager) GenerateDialog(context DialogContext) (DialogResponse, error) {
+	if !dm.beginRequest() {
+		return dm.createFallbackResponse(context), ErrShuttingDown
+	}
+	defer dm.inFlight.Done()
+	return dm.generateDialog(context)
+}
+
+// generateDialog runs GenerateDialog for a request already counted as in flight
+func (dm *DialogManager) generateDialog(context DialogContext) (DialogResponse, error) {
+	return dm.generateDialogWith(dm.buildHandlerChain(), context)
+}
+
+// generateDialogWith runs one request through a handler chain built by buildHandlerChain
+func (dm *DialogManager) generateDialogWith(handler DialogHandler, context DialogContext) (DialogResponse, error) {
+	start := time.Now()
+	dm.events.Publish(DialogEvent{
+		Type:          EventGenerationStarted,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+	})
+
+	response, err := handler(context)
+	if err != nil {
+		response = dm.createFallbackResponse(context)
+		dm.stats.record("", response)
+	}
+
+	dm.events.Publish(DialogEvent{
+		Type:          EventGenerationCompleted,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+		Response:      &response,
+		Error:         err,
+		Latency:       time.Since(start),
+	})
+	return response, err
+}
+
+// generateWithBackends runs the default backend, fallback chain and final fallback in order
+// Responses below the confidence threshold escalate to the next backend; when none meets
+// it, the best-scoring response is used before the canned fallback
+func (dm *DialogManager) generateWithBackends(context DialogContext) (DialogResponse, error) {
+	dm.mu.RLock()
+	esc := &escalation{threshold: dm.threshold, reranker: dm.reranker}
+	dm.mu.RUnlock()
+	esc.deadline = dm.responseDeadline()
+
 	// Attempt response generation using default backend first
-	if response, success := dm.tryDefaultBackend(context); success {
+	if response, success := dm.tryDefaultBackend(context, esc); success {
 		return response, nil
 	}
 
 	// Try fallback chain if default backend fails
-	if response, success := dm.tryFallbackChain(context); success {
+	if response, success := dm.tryFallbackChain(context, esc); success {
+		return response, nil
+	}
+
+	if response, success := dm.useBest(context, esc); success {
 		return response, nil
 	}
 
 	// Final fallback: use provided fallback responses
-	return dm.createFallbackResponse(context), nil
+	response := dm.createFallbackResponse(context)
+	dm.stats.record("", response)
+	dm.events.Publish(DialogEvent{
+		Type:          EventFallbackUsed,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+		Response:      &response,
+	})
+	return response, nil
 }
 
 // tryDefaultBackend attempts to generate response using the configured default backend
-func (dm *DialogManager) tryDefaultBackend(context DialogContext) (DialogResponse, bool) {
-	if dm.defaultBackend == "" {
+func (dm *DialogManager) tryDefaultBackend(context DialogContext, esc *escalation) (DialogResponse, bool) {
+	defaultBackend, arm, exp := dm.routeBackend(context)
+	if defaultBackend == "" {
 		return DialogResponse{}, false
 	}
 
-	backend, exists := dm.backends[dm.defaultBackend]
-	if !exists || backend == nil {
+	backend, exists := dm.GetBackend(defaultBackend)
+	if !exists || backend == nil || !backend.CanHandle(context) {
+		if arm != nil {
+			arm.recordFailure()
+		}
 		return DialogResponse{}, false
 	}
 
-	if !backend.CanHandle(context) {
+	response, latency, err := dm.callBackend(defaultBackend, backend, context, esc.deadline)
+	if err != nil || !esc.accept(defaultBackend, false, response, latency) {
+		if arm != nil {
+			arm.recordFailure()
+		}
 		return DialogResponse{}, false
 	}
 
-	response, err := backend.GenerateResponse(context)
-	if err != nil || response.Confidence <= 0.5 {
-		return DialogResponse{}, false
+	if arm != nil {
+		response = arm.annotate(response, exp.config.Name)
+		arm.recordResponse(response)
 	}
 
+	dm.stats.record(defaultBackend, response)
+	dm.publishResponse(EventResponseGenerated, defaultBackend, context, response, latency)
 	return response, true
 }
 
+// routeBackend picks the backend that answers context first, with the experiment arm it
+// was assigned from, if any
+func (dm *DialogManager) routeBackend(context DialogContext) (string, *experimentArm, *experiment) {
+	dm.mu.RLock()
+	defaultBackend := dm.defaultBackend
+	exp := dm.experiment
+	character := dm.characters[context.Speaker]
+	routed := dm.routes[context.Trigger]
+	dm.mu.RUnlock()
+
+	// A registered character's own backend speaks for it, then trigger routes
+	// apply; otherwise a running experiment takes over default routing
+	var arm *experimentArm
+	if character.Backend != "" {
+		defaultBackend = character.Backend
+	} else if routed != "" {
+		defaultBackend = routed
+	} else if exp != nil {
+		arm = exp.assign(context)
+		defaultBackend = arm.backend
+	}
+
+	// An overloaded backend hands the request to the cheaper overload backend, which is
+	// kept out of the experiment's results
+	if relieved := dm.relieveOverload(defaultBackend); relieved != defaultBackend {
+		defaultBackend, arm = relieved, nil
+	}
+	return defaultBackend, arm, exp
+}
+
 // tryFallbackChain attempts to generate response using the fallback backend chain
-func (dm *DialogManager) tryFallbackChain(context DialogContext) (DialogResponse, bool) {
-	for _, backendName := range dm.fallbackChain {
-		if response, success := dm.tryFallbackBackend(backendName, context); success {
+func (dm *DialogManager) tryFallbackChain(context DialogContext, esc *escalation) (DialogResponse, bool) {
+	dm.mu.RLock()
+	fallbackChain := dm.fallbackChain
+	dm.mu.RUnlock()
+
+	for _, backendName := range fallbackChain {
+		if response, success := dm.tryFallbackBackend(backendName, context, esc); success {
 			return response, true
 		}
 	}
@@ -195,8 +412,8 @@ func (dm *DialogManager) tryFallbackChain(context DialogContext) (DialogResponse
 }
 
 // tryFallbackBackend attempts to generate response using a specific fallback backend
-func (dm *DialogManager) tryFallbackBackend(backendName string, context DialogContext) (DialogResponse, bool) {
-	backend, exists := dm.backends[backendName]
+func (dm *DialogManager) tryFallbackBackend(backendName string, context DialogContext, esc *escalation) (DialogResponse, bool) {
+	backend, exists := dm.GetBackend(backendName)
 	if !exists || backend == nil {
 		return DialogResponse{}, false
 	}
@@ -205,27 +422,78 @@ func (dm *DialogManager) tryFallbackBackend(backendName string, context DialogCo
 		return DialogResponse{}, false
 	}
 
-	response, err := backend.GenerateResponse(context)
-	if err != nil {
+	response, latency, err := dm.callBackend(backendName, backend, context, esc.deadline)
+	if err != nil || !esc.accept(backendName, true, response, latency) {
 		return DialogResponse{}, false
 	}
 
+	dm.stats.record(backendName, response)
+	dm.publishResponse(EventResponseGenerated, backendName, context, response, latency)
+	dm.publishResponse(EventFallbackUsed, backendName, context, response, latency)
 	return response, true
 }
 
+// callBackend generates a response within the backend's timeout and what remains before
+// deadline, timing the call and publishing backend errors
+func (dm *DialogManager) callBackend(name string, backend DialogBackend, context DialogContext, deadline time.Time) (DialogResponse, time.Duration, error) {
+	return dm.timedCall(name, backend, context, deadline, func(context DialogContext, timeout time.Duration) (DialogResponse, error) {
+		return generateWithin(backend, context, timeout)
+	})
+}
+
+// timedCall runs generate for callBackend and its streaming counterpart
+func (dm *DialogManager) timedCall(name string, backend DialogBackend, context DialogContext, deadline time.Time, generate func(DialogContext, time.Duration) (DialogResponse, error)) (DialogResponse, time.Duration, error) {
+	timeout := dm.backendTimeout(name, deadline)
+	if timeout < 0 {
+		return DialogResponse{}, 0, fmt.Errorf("response timeout spent before backend '%s': %w", name, ErrTimeout)
+	}
+
+	prompt := dm.recordedPrompt(backend, context)
+	start := time.Now()
+	response, err := generate(context, timeout)
+	latency := time.Since(start)
+	dm.recordCall(name, context, prompt, response, latency, err)
+
+	if err != nil {
+		dm.events.Publish(DialogEvent{
+			Type:          EventBackendError,
+			InteractionID: context.InteractionID,
+			Trigger:       context.Trigger,
+			Backend:       name,
+			Error:         err,
+			Latency:       latency,
+		})
+	}
+	return response, latency, err
+}
+
+// publishResponse emits an event describing a response produced by a backend
+func (dm *DialogManager) publishResponse(eventType EventType, name string, context DialogContext, response DialogResponse, latency time.Duration) {
+	dm.events.Publish(DialogEvent{
+		Type:          eventType,
+		InteractionID: context.InteractionID,
+		Trigger:       context.Trigger,
+		Backend:       name,
+		Response:      &response,
+		Latency:       latency,
+	})
+}
+
 // createFallbackResponse generates a basic response when all backends fail
 func (dm *DialogManager) createFallbackResponse(context DialogContext) DialogResponse {
-	response := "Hello! 👋"
-	animation := "talking"
+	response := defaultFallbackResponse
+	animation := defaultFallbackAnimation
 
 	if len(context.FallbackResponses) > 0 {
-		// Simple time-based selection for fallback
-		index := int(time.Now().UnixNano()) % len(context.FallbackResponses)
+		// Random selection so repeated failures vary; seed with SetRandomSeed for replays
+		index := randomIntn(len(context.FallbackResponses))
 		response = context.FallbackResponses[index]
 	}
 
 	if context.FallbackAnimation != "" {
 		animation = context.FallbackAnimation
+	} else if triggerAnimation(context.Trigger) != "" {
+		animation = triggerAnimation(context.Trigger)
 	}
 
 	return DialogResponse{
@@ -238,6 +506,9 @@ func (dm *DialogManager) createFallbackResponse(context DialogContext) DialogRes
 
 // GetRegisteredBackends returns a list of all registered backend names
 func (dm *DialogManager) GetRegisteredBackends() []string {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
 	names := make([]string, 0, len(dm.backends))
 	for name := range dm.backends {
 		names = append(names, name)
@@ -247,7 +518,7 @@ func (dm *DialogManager) GetRegisteredBackends() []string {
 
 // GetBackendInfo returns information about a specific backend
 func (dm *DialogManager) GetBackendInfo(name string) (BackendInfo, error) {
-	backend, exists := dm.backends[name]
+	backend, exists := dm.GetBackend(name)
 	if !exists {
 		return BackendInfo{}, fmt.Errorf("backend '%s' not found", name)
 	}
@@ -255,29 +526,80 @@ func (dm *DialogManager) GetBackendInfo(name string) (BackendInfo, error) {
 }
 
 // UpdateBackendMemory records interaction outcomes for backend learning
-func (dm *DialogManager) UpdateBackendMemory(context DialogContext, response DialogResponse, feedback *UserFeedback) {
+// Only backends advertising CapabilityLearning receive updates; a CapabilityError is returned
+// when the backend that produced the response, or every registered backend, lacks it
+func (dm *DialogManager) UpdateBackendMemory(context DialogContext, response DialogResponse, feedback *UserFeedback) error {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	if dm.moodEngine != nil && feedback != nil {
+		dm.moodEngine.RecordFeedback(context.InteractionID, feedback.Positive)
+	}
+	if dm.recorder != nil && feedback != nil {
+		dm.recorder.RecordFeedback(context, response, *feedback)
+	}
+
+	// Experiment responses carry their arm, so feedback goes to the backend that produced them
+	if dm.experiment != nil {
+		if arm := dm.experiment.armFor(response); arm != nil {
+			if feedback != nil {
+				arm.recordFeedback(feedback)
+			}
+			if backend, exists := dm.backends[arm.backend]; exists {
+				if !backend.GetBackendInfo().HasCapability(CapabilityLearning) {
+					return &CapabilityError{Backend: arm.backend, Capability: CapabilityLearning}
+				}
+				return backend.UpdateMemory(context, response, feedback)
+			}
+		}
+	}
+
+	// Ensemble responses name the member that produced them
+	if name, ok := response.Metadata[MetadataEnsembleBackend].(string); ok {
+		if backend, exists := dm.backends[name]; exists && backend.GetBackendInfo().HasCapability(CapabilityLearning) {
+			return backend.UpdateMemory(context, response, feedback)
+		}
+	}
+
 	// Update memory for the backend that generated this response
+	learning := false
 	for _, backend := range dm.backends {
+		if !backend.GetBackendInfo().HasCapability(CapabilityLearning) {
+			continue
+		}
+		learning = true
 		if backend.CanHandle(context) {
-			_ = backend.UpdateMemory(context, response, feedback)
-			break
+			return backend.UpdateMemory(context, response, feedback)
 		}
 	}
+	if !learning {
+		return &CapabilityError{Capability: CapabilityLearning}
+	}
+	return nil
 }
 
 // GetBackend returns a specific registered backend by name
 func (dm *DialogManager) GetBackend(name string) (DialogBackend, bool) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
 	backend, exists := dm.backends[name]
 	return backend, exists
 }
 
 // DialogBackendConfig represents JSON configuration for dialog backends
 type DialogBackendConfig struct {
+	SchemaVersion int `json:"schemaVersion,omitempty"` // Config structure version (see CurrentConfigSchemaVersion)
+
 	// Backend selection
 	DefaultBackend string   `json:"defaultBackend"`          // Primary backend to use
 	FallbackChain  []string `json:"fallbackChain,omitempty"` // Ordered list of fallback backends
 	Enabled        bool     `json:"enabled"`                 // Whether to use advanced dialog system
 
+	// TriggerRoutes sends specific triggers to their own backend (e.g. "idle" to a cheap
+	// markov_chain, "talk" to llm); other triggers use defaultBackend
+	TriggerRoutes map[string]string `json:"triggerRoutes,omitempty"`
+
 	// Backend-specific configurations
 	Backends map[string]json.RawMessage `json:"backends,omitempty"` // Backend-specific config
 
@@ -285,8 +607,17 @@ type DialogBackendConfig struct {
 	MemoryEnabled       bool    `json:"memoryEnabled"`             // Enable interaction memory
 	LearningEnabled     bool    `json:"learningEnabled"`           // Enable backend learning
 	ConfidenceThreshold float64 `json:"confidenceThreshold"`       // Minimum confidence to accept response
-	ResponseTimeout     int     `json:"responseTimeout,omitempty"` // Max time to wait for response (ms)
-	DebugMode           bool    `json:"debugMode,omitempty"`       // Enable debug logging
+	ResponseTimeout     int     `json:"responseTimeout,omitempty"` // Max time to wait for response (ms), enforced across the chain once backendTimeouts is set
+
+	// BackendTimeouts limits each call to a backend (ms), e.g. {"llm": 2000, "markov_chain": 50},
+	// so a slow primary leaves time for its fallbacks
+	BackendTimeouts map[string]int `json:"backendTimeouts,omitempty"`
+
+	// OverloadBackend stands in for a backend that reports itself overloaded, e.g. a cheap
+	// markov_chain while an llm with adaptiveQuality cannot keep up
+	OverloadBackend string `json:"overloadBackend,omitempty"`
+
+	DebugMode bool `json:"debugMode,omitempty"` // Enable debug logging
 }
 
 // ValidateBackendConfig ensures the backend configuration is valid
@@ -296,24 +627,42 @@ func ValidateBackendConfig(config DialogBackendConfig) error {
 	}
 
 	if config.DefaultBackend == "" {
-		return fmt.Errorf("defaultBackend is required when dialog system is enabled")
+		return configErrorf("defaultBackend", "defaultBackend is required when dialog system is enabled")
 	}
 
 	if config.ConfidenceThreshold < 0 || config.ConfidenceThreshold > 1 {
-		return fmt.Errorf("confidenceThreshold must be between 0 and 1, got %f", config.ConfidenceThreshold)
+		return configErrorf("confidenceThreshold", "confidenceThreshold must be between 0 and 1, got %f", config.ConfidenceThreshold)
 	}
 
 	if config.ResponseTimeout < 0 {
-		return fmt.Errorf("responseTimeout must be non-negative, got %d", config.ResponseTimeout)
+		return configErrorf("responseTimeout", "responseTimeout must be non-negative, got %d", config.ResponseTimeout)
+	}
+
+	for backend, timeout := range config.BackendTimeouts {
+		if timeout < 0 {
+			return configErrorf("backendTimeouts", "backendTimeouts.%s must be non-negative, got %d", backend, timeout)
+		}
+	}
+
+	for trigger, backend := range config.TriggerRoutes {
+		if trigger == "" || backend == "" {
+			return configErrorf("triggerRoutes", "triggerRoutes entries need a trigger and a backend, got %q: %q", trigger, backend)
+		}
 	}
 
 	return nil
 }
 
 // LoadDialogBackendConfig loads backend configuration from JSON
+// Older configs are migrated to CurrentConfigSchemaVersion first
 func LoadDialogBackendConfig(data []byte) (DialogBackendConfig, error) {
 	var config DialogBackendConfig
 
+	data, err := MigrateDialogBackendConfig(data)
+	if err != nil {
+		return config, err
+	}
+
 	// Set defaults
 	config.ConfidenceThreshold = 0.5
 	config.ResponseTimeout = 1000
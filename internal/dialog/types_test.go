@@ -288,6 +288,15 @@ func TestValidateBackendConfig(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "negative backend timeout",
+			config: DialogBackendConfig{
+				DefaultBackend:  "test_backend",
+				Enabled:         true,
+				BackendTimeouts: map[string]int{"test_backend": -1},
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -512,3 +521,28 @@ func TestDialogManager_test_bug1_fallback_nil_pointer_dereference(t *testing.T)
 		t.Error("Expected fallback animation, got empty animation")
 	}
 }
+
+// BenchmarkDialogManager_GenerateDialog measures one request of a long-running chat
+// through the manager, excluding model time
+func BenchmarkDialogManager_GenerateDialog(b *testing.B) {
+	dm := NewDialogManager(false)
+	dm.RegisterBackend("llm", newScriptedBackend(b, LLMConfig{}, &scriptedTestModel{
+		responses: []string{"Mmm, thank you! I love playing games with you!"},
+	}))
+	dm.SetDefaultBackend("llm")
+	ctx := DialogContext{
+		Trigger:           "talk",
+		UserMessage:       "Want to play a game?",
+		InteractionID:     "bench",
+		CurrentMood:       70,
+		PersonalityTraits: map[string]float64{"cheerful": 0.9, "playful": 0.8},
+		FallbackResponses: []string{"Hi"},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := dm.GenerateDialog(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
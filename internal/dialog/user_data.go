@@ -0,0 +1,265 @@
+package dialog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UserDataExportVersion is the current format version written by ExportUserData
+const UserDataExportVersion = 1
+
+// UserDataStore is implemented by backends that hold per-interaction user data, so
+// DialogManager.ExportUserData and DeleteUserData can reach it
+type UserDataStore interface {
+	// ExportUserData returns everything held for the interaction as JSON, or nil when there is nothing
+	ExportUserData(interactionID string) (json.RawMessage, error)
+	// DeleteUserData erases everything held for the interaction
+	DeleteUserData(interactionID string) error
+}
+
+// UserDataExport is the machine-readable bundle returned by DialogManager.ExportUserData
+type UserDataExport struct {
+	Version             int                        `json:"version"`
+	InteractionID       string                     `json:"interactionId"`
+	ExportedAt          time.Time                  `json:"exportedAt"`
+	Backends            map[string]json.RawMessage `json:"backends,omitempty"`            // Data held by each UserDataStore backend
+	UnsupportedBackends []string                   `json:"unsupportedBackends,omitempty"` // Registered backends that cannot export user data
+	Facts               []UserFact                 `json:"facts,omitempty"`               // Remembered by the UserMemory
+	PendingFacts        []UserFact                 `json:"pendingFacts,omitempty"`        // Extracted facts awaiting review
+	PersonalityDrift    map[string]float64         `json:"personalityDrift,omitempty"`    // Learned trait offsets
+	Mood                *float64                   `json:"mood,omitempty"`                // Current mood tracked by the MoodEngine
+}
+
+// LLMUserData is the LLMBackend part of a UserDataExport: every conversation, with its
+// feedback, held for the interaction in the shared, per-character and per-tenant stores
+type LLMUserData struct {
+	Conversation *ConversationHistory                      `json:"conversation,omitempty"`
+	Characters   map[string]ConversationHistory            `json:"characters,omitempty"`
+	Tenants      map[string]map[string]ConversationHistory `json:"tenants,omitempty"`
+}
+
+// ExportUserData collects everything the dialog system holds about an interaction:
+// conversation history and feedback from backends, learned personality drift, mood and
+// user memory facts. Backends that do not implement UserDataStore are listed by name
+func (dm *DialogManager) ExportUserData(interactionID string) ([]byte, error) {
+	if interactionID == "" {
+		return nil, fmt.Errorf("interaction ID must not be empty")
+	}
+
+	dm.mu.RLock()
+	names := make([]string, 0, len(dm.backends))
+	backends := make(map[string]DialogBackend, len(dm.backends))
+	for name, backend := range dm.backends {
+		names = append(names, name)
+		backends[name] = backend
+	}
+	userMemory, drift, moodEngine := dm.userMemory, dm.drift, dm.moodEngine
+	dm.mu.RUnlock()
+	sort.Strings(names)
+
+	export := UserDataExport{
+		Version:       UserDataExportVersion,
+		InteractionID: interactionID,
+		ExportedAt:    currentTime(),
+	}
+	for _, name := range names {
+		store, ok := backends[name].(UserDataStore)
+		if !ok {
+			export.UnsupportedBackends = append(export.UnsupportedBackends, name)
+			continue
+		}
+		data, err := store.ExportUserData(interactionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export user data from backend '%s': %w", name, err)
+		}
+		if data == nil {
+			continue
+		}
+		if export.Backends == nil {
+			export.Backends = make(map[string]json.RawMessage)
+		}
+		export.Backends[name] = data
+	}
+
+	if userMemory != nil {
+		export.Facts = userMemory.Facts(interactionID)
+		export.PendingFacts = userMemory.Pending(interactionID)
+	}
+	if drift != nil {
+		export.PersonalityDrift = drift.Offsets(interactionID)
+	}
+	if moodEngine != nil {
+		if mood, exists := moodEngine.currentMood(interactionID); exists {
+			export.Mood = &mood
+		}
+	}
+
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user data export: %w", err)
+	}
+	return data, nil
+}
+
+// DeleteUserData erases an interaction from every subsystem: backend history and
+// feedback, personality drift, mood, user memory facts (pending ones included), calendar greetings and cached
+// rate-limited responses. Every subsystem is attempted even when a backend fails
+func (dm *DialogManager) DeleteUserData(interactionID string) error {
+	if interactionID == "" {
+		return fmt.Errorf("interaction ID must not be empty")
+	}
+
+	dm.mu.Lock()
+	backends := make(map[string]DialogBackend, len(dm.backends))
+	for name, backend := range dm.backends {
+		backends[name] = backend
+	}
+	userMemory, drift, moodEngine := dm.userMemory, dm.drift, dm.moodEngine
+	limiters := []*rateLimiter{dm.rateLimiter}
+	for _, registered := range dm.tenants {
+		limiters = append(limiters, registered.limiter)
+	}
+	for key := range dm.greetedEvents {
+		if strings.HasPrefix(key, interactionID+"\x00") {
+			delete(dm.greetedEvents, key)
+		}
+	}
+	dm.mu.Unlock()
+
+	var errs []error
+	for name, backend := range backends {
+		if store, ok := backend.(UserDataStore); ok {
+			if err := store.DeleteUserData(interactionID); err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete user data from backend '%s': %w", name, err))
+			}
+		}
+	}
+	if userMemory != nil {
+		userMemory.forgetInteraction(interactionID)
+	}
+	if drift != nil {
+		drift.forgetInteraction(interactionID)
+	}
+	if moodEngine != nil {
+		moodEngine.forgetInteraction(interactionID)
+	}
+	for _, limiter := range limiters {
+		if limiter != nil {
+			limiter.forgetInteraction(interactionID)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ExportUserData returns the interaction's conversations in every history store as LLMUserData
+func (llm *LLMBackend) ExportUserData(interactionID string) (json.RawMessage, error) {
+	var data LLMUserData
+	found := false
+	if history, exists := llm.contextManager.conversationCopy(interactionID); exists {
+		data.Conversation = &history
+		found = true
+	}
+	for characterID, cm := range llm.characterContextManagers() {
+		if history, exists := cm.conversationCopy(interactionID); exists {
+			if data.Characters == nil {
+				data.Characters = make(map[string]ConversationHistory)
+			}
+			data.Characters[characterID] = history
+			found = true
+		}
+	}
+	for tenantID, characters := range llm.tenantContextManagers() {
+		for characterID, cm := range characters {
+			history, exists := cm.conversationCopy(interactionID)
+			if !exists {
+				continue
+			}
+			if data.Tenants == nil {
+				data.Tenants = make(map[string]map[string]ConversationHistory)
+			}
+			if data.Tenants[tenantID] == nil {
+				data.Tenants[tenantID] = make(map[string]ConversationHistory)
+			}
+			data.Tenants[tenantID][characterID] = history
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversation history: %w", err)
+	}
+	return encoded, nil
+}
+
+// DeleteUserData clears the interaction's conversations in every history store
+func (llm *LLMBackend) DeleteUserData(interactionID string) error {
+	llm.contextManager.ClearHistory(interactionID)
+	for _, cm := range llm.isolatedContextManagers() {
+		cm.ClearHistory(interactionID)
+	}
+	return nil
+}
+
+// conversationCopy returns a copy of one conversation's history
+func (cm *ContextManager) conversationCopy(interactionID string) (ConversationHistory, bool) {
+	shard := cm.rlockShard(interactionID)
+	defer shard.mu.RUnlock()
+	history, exists := shard.conversations[interactionID]
+	if !exists {
+		return ConversationHistory{}, false
+	}
+	return copyConversationHistory(history), true
+}
+
+// forgetInteraction removes every fact about an interaction, pending ones included
+func (m *UserMemory) forgetInteraction(interactionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.facts, interactionID)
+	delete(m.pending, interactionID)
+}
+
+// forgetInteraction removes an interaction's drift
+func (d *PersonalityDrift) forgetInteraction(interactionID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.drifts, interactionID)
+}
+
+// currentMood returns an interaction's mood when the engine tracks it
+func (e *MoodEngine) currentMood(interactionID string) (float64, bool) {
+	e.mu.Lock()
+	_, exists := e.states[interactionID]
+	e.mu.Unlock()
+	if !exists {
+		return 0, false
+	}
+	return e.Mood(interactionID), true
+}
+
+// forgetInteraction removes an interaction's mood
+func (e *MoodEngine) forgetInteraction(interactionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.states, interactionID)
+}
+
+// forgetInteraction drops the cached responses and counters of an interaction's keys
+func (rl *rateLimiter) forgetInteraction(interactionID string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	for key := range rl.entries {
+		// Keys are TenantID, CharacterID, InteractionID, Trigger[, words]; see rateLimitKey
+		if parts := strings.SplitN(key, "\x00", 4); len(parts) > 2 && parts[2] == interactionID {
+			delete(rl.entries, key)
+		}
+	}
+}
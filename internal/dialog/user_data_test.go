@@ -0,0 +1,101 @@
+package dialog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// opaqueTestBackend hides every method of its backend beyond DialogBackend
+type opaqueTestBackend struct {
+	DialogBackend
+}
+
+// newUserDataTestManager returns a manager with every per-interaction subsystem enabled
+func newUserDataTestManager(t *testing.T) *DialogManager {
+	t.Helper()
+	dm, _ := newRateLimitTestManager(t, "Hi Sam!")
+	dm.SetRateLimit(RateLimitConfig{DebounceMs: 60000})
+	memory := NewUserMemory()
+	if err := memory.EnableExtraction(ExtractionConfig{}); err != nil {
+		t.Fatalf("EnableExtraction failed: %v", err)
+	}
+	dm.SetUserMemory(memory)
+	engine, _ := NewMoodEngine(MoodConfig{})
+	dm.SetMoodEngine(engine)
+	dm.SetPersonalityDrift(newTestDrift(t))
+	return dm
+}
+
+func TestDialogManager_ExportUserData(t *testing.T) {
+	dm := newUserDataTestManager(t)
+	dm.userMemory.Remember("sam", UserFact{Key: "name", Value: "Sam"})
+	dm.userMemory.Observe("sam", UserFact{Key: FactLikes, Value: "puzzles", Confidence: 0.6})
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "sam"})
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "sam", CharacterID: "cat"})
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "other"})
+	dm.RegisterBackend("plain", opaqueTestBackend{newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{})})
+
+	data, err := dm.ExportUserData("sam")
+	if err != nil {
+		t.Fatalf("ExportUserData failed: %v", err)
+	}
+	var export UserDataExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("Export is not valid JSON: %v", err)
+	}
+	if export.Version != UserDataExportVersion || export.InteractionID != "sam" {
+		t.Errorf("Expected a versioned bundle for sam, got %+v", export)
+	}
+	if len(export.Facts) != 1 || len(export.PendingFacts) != 1 || export.PersonalityDrift["playful"] == 0 || export.Mood == nil {
+		t.Errorf("Expected facts, pending facts, drift and mood exported, got %+v", export)
+	}
+	if len(export.UnsupportedBackends) != 1 || export.UnsupportedBackends[0] != "plain" {
+		t.Errorf("Expected the plain backend listed as unsupported, got %v", export.UnsupportedBackends)
+	}
+
+	var history LLMUserData
+	if err := json.Unmarshal(export.Backends["llm"], &history); err != nil {
+		t.Fatalf("LLM user data is not valid JSON: %v", err)
+	}
+	if history.Conversation == nil || len(history.Conversation.Exchanges) != 1 || len(history.Characters["cat"].Exchanges) != 1 {
+		t.Errorf("Expected shared and character conversations exported, got %+v", history)
+	}
+	if strings.Contains(string(data), `"other"`) {
+		t.Errorf("Expected other interactions left out, got %s", data)
+	}
+}
+
+func TestDialogManager_DeleteUserData(t *testing.T) {
+	dm := newUserDataTestManager(t)
+	dm.RegisterTenant("acme", TenantConfig{})
+	dm.userMemory.Remember("sam", UserFact{Key: "name", Value: "Sam"})
+	dm.userMemory.Observe("sam", UserFact{Key: FactLikes, Value: "puzzles", Confidence: 0.6})
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "sam"})
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "sam", TenantID: "acme"})
+	dm.GenerateDialog(DialogContext{Trigger: "play", InteractionID: "other"})
+
+	if err := dm.DeleteUserData("sam"); err != nil {
+		t.Fatalf("DeleteUserData failed: %v", err)
+	}
+	data, _ := dm.ExportUserData("sam")
+	var export UserDataExport
+	json.Unmarshal(data, &export)
+	if len(export.Backends) != 0 || len(export.Facts) != 0 || len(export.PendingFacts) != 0 || len(export.PersonalityDrift) != 0 || export.Mood != nil {
+		t.Errorf("Expected nothing left about sam, got %s", data)
+	}
+	if _, exists := dm.userMemory.pending["sam"]; exists {
+		t.Error("Expected sam's pending facts deleted")
+	}
+	if len(dm.rateLimiter.entries) != 1 {
+		t.Errorf("Expected only the other interaction's rate limit entry kept, got %d", len(dm.rateLimiter.entries))
+	}
+
+	backend, _ := dm.GetBackend("llm")
+	if history := backend.(*LLMBackend).GetContextManager().GetHistory("other", 0); len(history) != 1 {
+		t.Errorf("Expected other interactions kept, got %+v", history)
+	}
+	if err := dm.DeleteUserData(""); err == nil {
+		t.Error("Expected error for an empty interaction ID")
+	}
+}
@@ -0,0 +1,256 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Common user fact keys; any other key is allowed
+const (
+	FactName     = "name"
+	FactBirthday = "birthday"
+	FactLikes    = "likes"    // Accumulates values
+	FactDislikes = "dislikes" // Accumulates values
+)
+
+// Fact sources
+const (
+	FactSourceHost      = "host"      // Set by the host application
+	FactSourceExtracted = "extracted" // Extracted from conversation
+)
+
+// UserMemoryExportVersion is the current format version written by UserMemory.Export
+const UserMemoryExportVersion = 1
+
+// defaultMaxUserFacts is the number of facts kept per interaction before the oldest is dropped
+const defaultMaxUserFacts = 50
+
+// multiValueFactKeys are keys whose facts accumulate instead of replacing each other
+var multiValueFactKeys = map[string]bool{FactLikes: true, FactDislikes: true}
+
+// UserFact is something the character knows about the user, such as their name
+// ("name" = "Sam") or a preference ("likes" = "coffee")
+type UserFact struct {
+	Key        string    `json:"key"`
+	Value      string    `json:"value"`
+	Source     string    `json:"source,omitempty"`     // "host" or "extracted" (default: "host")
+	Confidence float64   `json:"confidence,omitempty"` // How sure the source is (0-1, default: 1)
+	UpdatedAt  time.Time `json:"updatedAt"`
+	ExpiresAt  time.Time `json:"expiresAt,omitempty"` // Forgotten after this time (zero = never)
+}
+
+// expired reports whether the fact should be forgotten at now
+func (f UserFact) expired(now time.Time) bool {
+	return !f.ExpiresAt.IsZero() && !now.Before(f.ExpiresAt)
+}
+
+// UserMemoryExport is the portable JSON document produced by UserMemory.Export
+type UserMemoryExport struct {
+	Version       int        `json:"version"`
+	InteractionID string     `json:"interactionId"`
+	Facts         []UserFact `json:"facts"`
+}
+
+// UserMemory stores facts about the user per InteractionID so prompts can mention them
+// Facts under "likes" and "dislikes" accumulate; other keys hold one value each
+type UserMemory struct {
+	facts     map[string][]UserFact // Oldest first
+	pending   map[string][]UserFact // Extracted facts awaiting review
+	maxFacts  int
+	extractor *FactExtractor // Extracts facts after each exchange when set
+	mu        sync.RWMutex
+}
+
+// NewUserMemory creates an empty user memory store
+func NewUserMemory() *UserMemory {
+	return &UserMemory{
+		facts:    make(map[string][]UserFact),
+		pending:  make(map[string][]UserFact),
+		maxFacts: defaultMaxUserFacts,
+	}
+}
+
+// Remember stores a fact for an interaction, replacing the previous value of a
+// single-valued key; the oldest fact is dropped when the interaction has too many
+func (m *UserMemory) Remember(interactionID string, fact UserFact) error {
+	fact, err := normalizeUserFact(fact)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store(interactionID, fact)
+	return nil
+}
+
+// store adds a normalized fact, dropping the fact it replaces and expired facts
+func (m *UserMemory) store(interactionID string, fact UserFact) {
+	now := currentTime()
+	facts := make([]UserFact, 0, len(m.facts[interactionID])+1)
+	for _, existing := range m.facts[interactionID] {
+		if !existing.expired(now) && !fact.replaces(existing) {
+			facts = append(facts, existing)
+		}
+	}
+	facts = append(facts, fact)
+	if len(facts) > m.maxFacts {
+		facts = facts[len(facts)-m.maxFacts:]
+	}
+	m.facts[interactionID] = facts
+}
+
+// replaces reports whether storing f overwrites existing
+func (f UserFact) replaces(existing UserFact) bool {
+	return existing.Key == f.Key && (!multiValueFactKeys[f.Key] || strings.EqualFold(existing.Value, f.Value))
+}
+
+// normalizeUserFact trims and lowercases the key and fills defaults
+func normalizeUserFact(fact UserFact) (UserFact, error) {
+	fact.Key = strings.ToLower(strings.TrimSpace(fact.Key))
+	fact.Value = strings.TrimSpace(fact.Value)
+	if fact.Key == "" || fact.Value == "" {
+		return UserFact{}, fmt.Errorf("user fact needs a key and a value")
+	}
+	if fact.Confidence < 0 || fact.Confidence > 1 {
+		return UserFact{}, fmt.Errorf("user fact confidence must be between 0 and 1, got %f", fact.Confidence)
+	}
+	if fact.Confidence == 0 {
+		fact.Confidence = 1
+	}
+	if fact.Source == "" {
+		fact.Source = FactSourceHost
+	}
+	if fact.UpdatedAt.IsZero() {
+		fact.UpdatedAt = currentTime()
+	}
+	return fact, nil
+}
+
+// Forget removes every fact under key for an interaction
+func (m *UserMemory) Forget(interactionID, key string) {
+	key = strings.ToLower(strings.TrimSpace(key))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.facts[interactionID][:0]
+	for _, fact := range m.facts[interactionID] {
+		if fact.Key != key {
+			kept = append(kept, fact)
+		}
+	}
+	if len(kept) == 0 {
+		delete(m.facts, interactionID)
+		return
+	}
+	m.facts[interactionID] = kept
+}
+
+// Facts returns an interaction's unexpired facts, oldest first
+func (m *UserMemory) Facts(interactionID string) []UserFact {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := currentTime()
+	var facts []UserFact
+	for _, fact := range m.facts[interactionID] {
+		if !fact.expired(now) {
+			facts = append(facts, fact)
+		}
+	}
+	return facts
+}
+
+// Export serializes an interaction's facts so hosts can persist them across sessions
+func (m *UserMemory) Export(interactionID string) ([]byte, error) {
+	facts := m.Facts(interactionID)
+	if len(facts) == 0 {
+		return nil, fmt.Errorf("no user facts found for interaction '%s'", interactionID)
+	}
+
+	data, err := json.Marshal(UserMemoryExport{
+		Version:       UserMemoryExportVersion,
+		InteractionID: interactionID,
+		Facts:         facts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user memory export: %w", err)
+	}
+	return data, nil
+}
+
+// Import restores facts previously produced by Export, replacing the interaction's facts
+func (m *UserMemory) Import(data []byte) error {
+	var export UserMemoryExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse user memory export: %w", err)
+	}
+	if export.Version <= 0 {
+		return fmt.Errorf("user memory export is missing a version")
+	}
+	if export.Version > UserMemoryExportVersion {
+		return fmt.Errorf("unsupported user memory export version %d (max %d)", export.Version, UserMemoryExportVersion)
+	}
+	if export.InteractionID == "" {
+		return fmt.Errorf("user memory export is missing an interactionId")
+	}
+
+	m.mu.Lock()
+	delete(m.facts, export.InteractionID)
+	m.mu.Unlock()
+
+	for _, fact := range export.Facts {
+		if err := m.Remember(export.InteractionID, fact); err != nil {
+			return fmt.Errorf("invalid fact in user memory export: %w", err)
+		}
+	}
+	return nil
+}
+
+// wrap adds the interaction's facts to contexts that do not carry their own and,
+// when extraction is enabled, learns new facts from the exchange
+func (m *UserMemory) wrap(next DialogHandler) DialogHandler {
+	return func(context DialogContext) (DialogResponse, error) {
+		if context.UserFacts == nil {
+			context.UserFacts = m.Facts(context.InteractionID)
+		}
+		response, err := next(context)
+		if err == nil {
+			m.learn(context, response)
+		}
+		return response, err
+	}
+}
+
+// SetUserMemory makes GenerateDialog pass the interaction's remembered facts to
+// backends in DialogContext.UserFacts; nil disables it
+func (dm *DialogManager) SetUserMemory(memory *UserMemory) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	dm.userMemory = memory
+}
+
+// describeUserFacts renders facts for the prompt, joining values that share a key
+func describeUserFacts(facts []UserFact) string {
+	var keys []string
+	values := make(map[string][]string)
+	for _, fact := range facts {
+		if _, seen := values[fact.Key]; !seen {
+			keys = append(keys, fact.Key)
+		}
+		values[fact.Key] = append(values[fact.Key], fact.Value)
+	}
+	sort.Strings(keys)
+
+	var description strings.Builder
+	description.WriteString("What you know about the user:\n")
+	for _, key := range keys {
+		description.WriteString(fmt.Sprintf("- %s: %s\n", key, strings.Join(values[key], ", ")))
+	}
+	return description.String()
+}
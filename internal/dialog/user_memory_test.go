@@ -0,0 +1,132 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUserMemory_Remember(t *testing.T) {
+	memory := NewUserMemory()
+	memory.Remember("pet", UserFact{Key: "Name", Value: "Sam"})
+	memory.Remember("pet", UserFact{Key: FactLikes, Value: "coffee"})
+	memory.Remember("pet", UserFact{Key: FactLikes, Value: "cats"})
+	memory.Remember("pet", UserFact{Key: FactName, Value: "Samantha"})
+	memory.Remember("pet", UserFact{Key: FactLikes, Value: "Coffee", Source: FactSourceExtracted, Confidence: 0.7})
+
+	facts := memory.Facts("pet")
+	if len(facts) != 3 {
+		t.Fatalf("Expected 3 facts, got %+v", facts)
+	}
+	if facts[0].Key != FactLikes || facts[0].Value != "cats" {
+		t.Errorf("Expected the oldest remaining fact first, got %+v", facts[0])
+	}
+	if facts[1].Key != FactName || facts[1].Value != "Samantha" || facts[1].Source != FactSourceHost || facts[1].Confidence != 1 {
+		t.Errorf("Expected the name replaced with host defaults, got %+v", facts[1])
+	}
+	if facts[2].Value != "Coffee" || facts[2].Source != FactSourceExtracted || facts[2].Confidence != 0.7 {
+		t.Errorf("Expected the repeated like replaced, got %+v", facts[2])
+	}
+	if facts[2].UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be filled in")
+	}
+
+	if len(memory.Facts("other")) != 0 {
+		t.Error("Expected facts kept per interaction")
+	}
+
+	for _, fact := range []UserFact{{Key: "name"}, {Value: "Sam"}, {Key: "name", Value: "Sam", Confidence: 2}} {
+		if err := memory.Remember("pet", fact); err == nil {
+			t.Errorf("Expected error for %+v", fact)
+		}
+	}
+}
+
+func TestUserMemory_LimitAndForget(t *testing.T) {
+	memory := NewUserMemory()
+	memory.maxFacts = 2
+	memory.Remember("pet", UserFact{Key: FactLikes, Value: "coffee"})
+	memory.Remember("pet", UserFact{Key: FactLikes, Value: "cats"})
+	memory.Remember("pet", UserFact{Key: FactName, Value: "Sam"})
+
+	facts := memory.Facts("pet")
+	if len(facts) != 2 || facts[0].Value != "cats" {
+		t.Errorf("Expected the oldest fact dropped, got %+v", facts)
+	}
+
+	memory.Forget("pet", "LIKES")
+	if facts := memory.Facts("pet"); len(facts) != 1 || facts[0].Key != FactName {
+		t.Errorf("Expected likes forgotten, got %+v", facts)
+	}
+	memory.Forget("pet", FactName)
+	if facts := memory.Facts("pet"); len(facts) != 0 {
+		t.Errorf("Expected no facts left, got %+v", facts)
+	}
+}
+
+func TestUserMemory_ExportImport(t *testing.T) {
+	memory := NewUserMemory()
+	memory.Remember("pet", UserFact{Key: FactName, Value: "Sam"})
+	memory.Remember("pet", UserFact{Key: FactLikes, Value: "coffee", Source: FactSourceExtracted, Confidence: 0.8})
+
+	data, err := memory.Export("pet")
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if _, err := memory.Export("missing"); err == nil {
+		t.Error("Expected error exporting an unknown interaction")
+	}
+
+	restored := NewUserMemory()
+	restored.Remember("pet", UserFact{Key: "stale", Value: "fact"})
+	if err := restored.Import(data); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	facts := restored.Facts("pet")
+	if len(facts) != 2 || facts[0].Value != "Sam" || facts[1].Confidence != 0.8 {
+		t.Errorf("Expected imported facts to replace existing ones, got %+v", facts)
+	}
+
+	for _, bad := range []string{`not json`, `{"interactionId": "pet"}`, `{"version": 99, "interactionId": "pet"}`, `{"version": 1}`} {
+		if err := restored.Import([]byte(bad)); err == nil {
+			t.Errorf("Expected error importing %s", bad)
+		}
+	}
+}
+
+func TestPromptBuilder_UserFacts(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{Trigger: "click", UserFacts: []UserFact{
+		{Key: FactName, Value: "Sam"},
+		{Key: FactLikes, Value: "coffee"},
+		{Key: FactLikes, Value: "cats"},
+	}})
+	prompt := pb.Build()
+
+	if !strings.Contains(prompt, "What you know about the user:\n- likes: coffee, cats\n- name: Sam\n") {
+		t.Errorf("Expected user facts in prompt, got:\n%s", prompt)
+	}
+}
+
+func TestDialogManager_SetUserMemory(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hi Sam!", "Hello!")
+	memory := NewUserMemory()
+	memory.Remember("pet", UserFact{Key: FactName, Value: "Sam"})
+	dm.SetUserMemory(memory)
+
+	var seen DialogContext
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		seen = *context
+		return nil, nil
+	}))
+
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet"})
+	if len(seen.UserFacts) != 1 || seen.UserFacts[0].Value != "Sam" {
+		t.Errorf("Expected remembered facts in the context, got %+v", seen.UserFacts)
+	}
+
+	host := []UserFact{{Key: FactName, Value: "Alex"}}
+	dm.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pet", UserFacts: host})
+	if len(seen.UserFacts) != 1 || seen.UserFacts[0].Value != "Alex" {
+		t.Errorf("Expected host-provided facts kept, got %+v", seen.UserFacts)
+	}
+}
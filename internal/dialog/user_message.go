@@ -0,0 +1,22 @@
+package dialog
+
+import "fmt"
+
+// userUtterance returns what the user typed or, for VoiceTrigger, said out loud
+func userUtterance(context DialogContext) string {
+	if context.UserMessage != "" {
+		return context.UserMessage
+	}
+	if context.VoiceInput != nil && context.Trigger == VoiceTrigger {
+		return context.VoiceInput.Transcript
+	}
+	return ""
+}
+
+// buildMessageSituation quotes what the user typed, or returns "" when there is no message
+func (pb *PromptBuilder) buildMessageSituation() string {
+	if pb.context.UserMessage == "" {
+		return ""
+	}
+	return fmt.Sprintf("- The user said: \"%s\"\n", pb.context.UserMessage)
+}
@@ -0,0 +1,49 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptBuilder_UserMessage(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{UserMessage: "do you like rainy days?"})
+	prompt := pb.Build()
+	if !strings.Contains(prompt, "- The user said: \"do you like rainy days?\"\n") || strings.Contains(prompt, "The user just performed") {
+		t.Errorf("Expected only the message for an untriggered chat, got:\n%s", prompt)
+	}
+
+	pb.AddContext(DialogContext{Trigger: "gift", UserMessage: "I picked this flower for you"})
+	prompt = pb.Build()
+	if !strings.Contains(prompt, "- The user just performed: gave you a gift\n- The user said: \"I picked this flower for you\"\n") {
+		t.Errorf("Expected the action followed by the message, got:\n%s", prompt)
+	}
+}
+
+func TestLLMBackend_UserMessageHistory(t *testing.T) {
+	backend := newScriptedBackend(t, LLMConfig{}, &scriptedTestModel{responses: []string{"I love them! 🌧️"}})
+	backend.GenerateResponse(DialogContext{Trigger: "talk", InteractionID: "pet", UserMessage: "do you like rainy days?"})
+
+	history := backend.GetContextManager().GetHistory("pet", 0)
+	if len(history) != 1 || history[0].UserMessage != "do you like rainy days?" {
+		t.Fatalf("Expected the message stored with the exchange, got %+v", history)
+	}
+
+	prompt := backend.buildPrompt(DialogContext{Trigger: "talk", InteractionID: "pet", UserMessage: "why?"})
+	if !strings.Contains(prompt, "User said: \"do you like rainy days?\" → You said: \"I love them! 🌧️\"") {
+		t.Errorf("Expected the earlier message in the history, got:\n%s", prompt)
+	}
+}
+
+func TestPromptBuilder_CompressedHistoryKeepsMessages(t *testing.T) {
+	now := time.Now()
+	pb := NewPromptBuilder()
+	summary := pb.formatCompressedHistory([]ConversationExchange{
+		{Trigger: "talk", UserMessage: "hi there", Response: "Hello!", Timestamp: now},
+		{Trigger: "talk", UserMessage: "how are you today my friend", Response: "Great!", Timestamp: now},
+	})
+	if !strings.Contains(summary, "User said \"hi there\"") || !strings.Contains(summary, "User said \"how are you today my friend\"") {
+		t.Errorf("Expected each message summarized on its own, got:\n%s", summary)
+	}
+}
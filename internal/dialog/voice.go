@@ -0,0 +1,93 @@
+package dialog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Triggers raised for spoken input
+const (
+	VoiceTrigger        = "voice"         // The user said something; the transcript is in VoiceInput
+	VoiceUnclearTrigger = "voice_unclear" // The recognizer was not confident enough to answer the words
+)
+
+// Voice pipeline defaults
+const (
+	defaultVoiceMinConfidence = 0.4
+	defaultVoiceMaxWords      = 60
+)
+
+// VoiceInput is a user utterance transcribed by a speech-to-text engine
+type VoiceInput struct {
+	Transcript string  `json:"transcript"`           // What the user said
+	Confidence float64 `json:"confidence,omitempty"` // Recognizer confidence (0-1, 0 = unknown)
+	Language   string  `json:"language,omitempty"`   // Spoken language as a BCP 47 tag, e.g. "en-US"
+}
+
+// VoiceConfig sets how a VoicePipeline treats transcripts
+type VoiceConfig struct {
+	MinConfidence float64 `json:"minConfidence,omitempty"` // Below this the character asks the user to repeat (default: 0.4)
+	MaxWords      int     `json:"maxWords,omitempty"`      // Longer transcripts are cut to this many words (default: 60)
+}
+
+// VoicePipeline turns transcribed utterances into dialog turns, so the character
+// answers what the user said rather than a canned trigger
+type VoicePipeline struct {
+	manager       *DialogManager
+	minConfidence float64
+	maxWords      int
+}
+
+// NewVoicePipeline creates a pipeline generating through manager, applying defaults for unset values
+func NewVoicePipeline(manager *DialogManager, config VoiceConfig) (*VoicePipeline, error) {
+	if manager == nil {
+		return nil, fmt.Errorf("voice pipeline needs a manager")
+	}
+	if config.MinConfidence < 0 || config.MinConfidence > 1 {
+		return nil, fmt.Errorf("minConfidence must be between 0 and 1, got %f", config.MinConfidence)
+	}
+	if config.MaxWords < 0 {
+		return nil, fmt.Errorf("maxWords must be non-negative, got %d", config.MaxWords)
+	}
+	if config.MinConfidence == 0 {
+		config.MinConfidence = defaultVoiceMinConfidence
+	}
+	if config.MaxWords == 0 {
+		config.MaxWords = defaultVoiceMaxWords
+	}
+	return &VoicePipeline{manager: manager, minConfidence: config.MinConfidence, maxWords: config.MaxWords}, nil
+}
+
+// Submit answers an utterance: context carries the character state, and its trigger
+// is replaced by VoiceTrigger, or VoiceUnclearTrigger when the recognizer's confidence
+// is below the minimum. Blank transcripts are rejected
+func (p *VoicePipeline) Submit(context DialogContext, input VoiceInput) (DialogResponse, error) {
+	words := strings.Fields(input.Transcript)
+	if len(words) == 0 {
+		return DialogResponse{}, fmt.Errorf("voice input has no transcript")
+	}
+	if len(words) > p.maxWords {
+		words = words[:p.maxWords]
+	}
+	input.Transcript = strings.Join(words, " ")
+
+	context.Trigger = VoiceTrigger
+	if input.Confidence > 0 && input.Confidence < p.minConfidence {
+		context.Trigger = VoiceUnclearTrigger
+	}
+	context.VoiceInput = &input
+	return p.manager.GenerateDialog(context)
+}
+
+// buildVoiceSituation describes what the user said, replacing the trigger line
+// It returns "" when the context has no voice input
+func (pb *PromptBuilder) buildVoiceSituation() string {
+	voice := pb.context.VoiceInput
+	if voice == nil || voice.Transcript == "" {
+		return ""
+	}
+	if pb.context.Trigger == VoiceUnclearTrigger {
+		return fmt.Sprintf("- The user said something you couldn't quite hear (maybe \"%s\"); ask them to say it again\n", voice.Transcript)
+	}
+	return fmt.Sprintf("- The user said out loud: \"%s\"\n", voice.Transcript)
+}
@@ -0,0 +1,85 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewVoicePipelineValidation(t *testing.T) {
+	dm := NewDialogManager(false)
+	if _, err := NewVoicePipeline(nil, VoiceConfig{}); err == nil {
+		t.Error("Expected error without a manager")
+	}
+	for _, config := range []VoiceConfig{{MinConfidence: 1.5}, {MaxWords: -1}} {
+		if _, err := NewVoicePipeline(dm, config); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+func TestVoicePipeline_Submit(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Pizza sounds great! 🍕", "Sorry, what was that?")
+	var seen []DialogContext
+	dm.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		seen = append(seen, *context)
+		return nil, nil
+	}))
+	pipeline, _ := NewVoicePipeline(dm, VoiceConfig{MaxWords: 5})
+
+	response, err := pipeline.Submit(DialogContext{Trigger: "click", InteractionID: "pet"},
+		VoiceInput{Transcript: "  what should we   have for dinner tonight ", Confidence: 0.9, Language: "en-US"})
+	if err != nil || response.Text != "Pizza sounds great! 🍕" {
+		t.Fatalf("Expected an answer to the utterance, got %q, %v", response.Text, err)
+	}
+	if seen[0].Trigger != VoiceTrigger || seen[0].VoiceInput.Transcript != "what should we have for" {
+		t.Errorf("Expected a voice turn with a normalized, capped transcript, got %+v", seen[0])
+	}
+
+	pipeline.Submit(DialogContext{InteractionID: "pet"}, VoiceInput{Transcript: "mumble", Confidence: 0.2})
+	if seen[1].Trigger != VoiceUnclearTrigger {
+		t.Errorf("Expected a low-confidence utterance to ask for a repeat, got %q", seen[1].Trigger)
+	}
+
+	if _, err := pipeline.Submit(DialogContext{InteractionID: "pet"}, VoiceInput{Transcript: "  "}); err == nil {
+		t.Error("Expected error for a blank transcript")
+	}
+}
+
+func TestPromptBuilder_VoiceInput(t *testing.T) {
+	pb := NewPromptBuilder()
+	pb.AddContext(DialogContext{Trigger: VoiceTrigger, VoiceInput: &VoiceInput{Transcript: "are you hungry?"}})
+	prompt := pb.Build()
+	if !strings.Contains(prompt, "- The user said out loud: \"are you hungry?\"\n") || strings.Contains(prompt, "The user just performed") {
+		t.Errorf("Expected the utterance in place of the trigger, got:\n%s", prompt)
+	}
+
+	pb.AddContext(DialogContext{Trigger: VoiceUnclearTrigger, VoiceInput: &VoiceInput{Transcript: "hungy"}})
+	if prompt := pb.Build(); !strings.Contains(prompt, "couldn't quite hear (maybe \"hungy\"); ask them to say it again") {
+		t.Errorf("Expected a request to repeat, got:\n%s", prompt)
+	}
+}
+
+func TestVoicePipeline_DistinctUtterancesNotDebounced(t *testing.T) {
+	dm, model := newRateLimitTestManager(t, "Hi!", "I'm great!")
+	dm.SetRateLimit(RateLimitConfig{DebounceMs: 10000})
+	pipeline, _ := NewVoicePipeline(dm, VoiceConfig{})
+
+	pipeline.Submit(DialogContext{InteractionID: "pet"}, VoiceInput{Transcript: "hello"})
+	response, _ := pipeline.Submit(DialogContext{InteractionID: "pet"}, VoiceInput{Transcript: "how are you"})
+	if response.Text != "I'm great!" || model.callCount() != 2 {
+		t.Errorf("Expected a new answer for a different utterance, got %q", response.Text)
+	}
+}
+
+func TestUserMemory_LearnsFromVoiceInput(t *testing.T) {
+	dm, _ := newRateLimitTestManager(t, "Hi Sam!")
+	memory := NewUserMemory()
+	memory.EnableExtraction(ExtractionConfig{})
+	dm.SetUserMemory(memory)
+	pipeline, _ := NewVoicePipeline(dm, VoiceConfig{})
+
+	pipeline.Submit(DialogContext{InteractionID: "pet"}, VoiceInput{Transcript: "my name is Sam"})
+	if facts := memory.Facts("pet"); len(facts) != 1 || facts[0].Value != "Sam" {
+		t.Errorf("Expected the name learned from speech, got %+v", facts)
+	}
+}
@@ -0,0 +1,154 @@
+package dialog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Shared world defaults
+const (
+	defaultWorldEvents           = 20 // Events kept in a WorldContext
+	defaultWorldRetentionMinutes = 60 // How long events stay relevant
+	promptWorldEvents            = 3  // Most recent events mentioned in a prompt
+)
+
+// worldTriggerDescriptions describe user interactions from another character's point of view
+// Interactions without a description are not recorded automatically
+var worldTriggerDescriptions = map[string]string{
+	"click":      "got clicked",
+	"rightclick": "got right-clicked",
+	"feed":       "got fed",
+	"pet":        "got petted",
+	"play":       "played with the user",
+	"talk":       "talked with the user",
+	"gift":       "got a gift",
+	"compliment": "got a compliment",
+	"ignore":     "got ignored",
+}
+
+// WorldEvent is something that happened to a character on the shared desktop
+type WorldEvent struct {
+	Character   string    `json:"character"`   // Who it happened to, e.g. "Pip"
+	Description string    `json:"description"` // What happened, e.g. "got fed"
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// WorldConfig sets how much a WorldContext remembers
+type WorldConfig struct {
+	MaxEvents        int     `json:"maxEvents,omitempty"`        // Events kept, oldest dropped first (default: 20)
+	RetentionMinutes float64 `json:"retentionMinutes,omitempty"` // Events older than this are forgotten (default: 60)
+}
+
+// WorldContext is a store of recent events shared by the characters on one desktop
+// so companions can mention each other ("your other pet just got fed")
+// It is safe to share between DialogManagers
+type WorldContext struct {
+	events    []WorldEvent
+	maxEvents int
+	retention time.Duration
+	mu        sync.RWMutex
+}
+
+// NewWorldContext creates an empty shared world, applying defaults for unset values
+func NewWorldContext(config WorldConfig) (*WorldContext, error) {
+	if config.MaxEvents < 0 || config.RetentionMinutes < 0 {
+		return nil, fmt.Errorf("world limits must be non-negative")
+	}
+	if config.MaxEvents == 0 {
+		config.MaxEvents = defaultWorldEvents
+	}
+	if config.RetentionMinutes == 0 {
+		config.RetentionMinutes = defaultWorldRetentionMinutes
+	}
+	return &WorldContext{
+		maxEvents: config.MaxEvents,
+		retention: minutesDuration(config.RetentionMinutes),
+	}, nil
+}
+
+// Record adds an event, timestamped now when left empty
+func (w *WorldContext) Record(event WorldEvent) error {
+	if event.Character == "" || event.Description == "" {
+		return fmt.Errorf("world event needs a character and a description")
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = currentTime()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.events = append(w.events, event)
+	if len(w.events) > w.maxEvents {
+		w.events = append([]WorldEvent(nil), w.events[len(w.events)-w.maxEvents:]...)
+	}
+	return nil
+}
+
+// Recent returns up to limit of the newest unexpired events, oldest first,
+// leaving out those about exclude (pass "" to include every character)
+func (w *WorldContext) Recent(exclude string, limit int) []WorldEvent {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	cutoff := currentTime().Add(-w.retention)
+	var recent []WorldEvent
+	for i := len(w.events) - 1; i >= 0 && (limit <= 0 || len(recent) < limit); i-- {
+		event := w.events[i]
+		if event.Timestamp.Before(cutoff) {
+			break
+		}
+		if exclude != "" && event.Character == exclude {
+			continue
+		}
+		recent = append(recent, event)
+	}
+	for i, j := 0, len(recent)-1; i < j; i, j = i+1, j-1 {
+		recent[i], recent[j] = recent[j], recent[i]
+	}
+	return recent
+}
+
+// worldView is a DialogManager's place in a shared world: the store and the character it speaks for
+type worldView struct {
+	world     *WorldContext
+	character string
+}
+
+// wrap adds what other characters did recently to contexts that do not carry their own,
+// then records the user's interaction with this character for the others
+func (v *worldView) wrap(next DialogHandler) DialogHandler {
+	return func(context DialogContext) (DialogResponse, error) {
+		character := v.character
+		if context.Speaker != "" {
+			character = context.Speaker
+		}
+		if context.WorldEvents == nil {
+			context.WorldEvents = v.world.Recent(character, promptWorldEvents)
+		}
+
+		response, err := next(context)
+		if description, ok := worldTriggerDescriptions[context.Trigger]; ok && err == nil {
+			v.world.Record(WorldEvent{Character: character, Description: description, Timestamp: context.Timestamp})
+		}
+		return response, err
+	}
+}
+
+// SetWorldContext shares world with the other characters on the desktop: prompts mention
+// what happened to them recently, and user interactions with this manager's character
+// are recorded for them; nil disables it
+func (dm *DialogManager) SetWorldContext(world *WorldContext, character string) error {
+	if world != nil && character == "" {
+		return fmt.Errorf("world context needs the name of this manager's character")
+	}
+
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	if world == nil {
+		dm.world = nil
+		return nil
+	}
+	dm.world = &worldView{world: world, character: character}
+	return nil
+}
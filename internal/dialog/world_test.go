@@ -0,0 +1,84 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWorldContextValidation(t *testing.T) {
+	if _, err := NewWorldContext(WorldConfig{MaxEvents: -1}); err == nil {
+		t.Error("Expected error for negative max events")
+	}
+	world, _ := NewWorldContext(WorldConfig{})
+	if err := world.Record(WorldEvent{Character: "Pip"}); err == nil {
+		t.Error("Expected error for an event without a description")
+	}
+	if err := NewDialogManager(false).SetWorldContext(world, ""); err == nil {
+		t.Error("Expected error without a character name")
+	}
+}
+
+func TestWorldContext_Recent(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	world, _ := NewWorldContext(WorldConfig{MaxEvents: 3, RetentionMinutes: 30})
+	world.Record(WorldEvent{Character: "Pip", Description: "got fed"})
+	clock.Advance(20 * time.Minute)
+	world.Record(WorldEvent{Character: "Mochi", Description: "got petted"})
+	world.Record(WorldEvent{Character: "Pip", Description: "got a gift"})
+	world.Record(WorldEvent{Character: "Tofu", Description: "played with the user"})
+
+	recent := world.Recent("", 0)
+	if len(recent) != 3 || recent[0].Description != "got petted" || recent[2].Character != "Tofu" {
+		t.Errorf("Expected the 3 newest events oldest first, got %+v", recent)
+	}
+	if recent := world.Recent("Mochi", 1); len(recent) != 1 || recent[0].Character != "Tofu" {
+		t.Errorf("Expected the newest event about someone else, got %+v", recent)
+	}
+
+	clock.Advance(15 * time.Minute)
+	world.Record(WorldEvent{Character: "Pip", Description: "got clicked"})
+	clock.Advance(20 * time.Minute)
+	if recent := world.Recent("", 0); len(recent) != 1 || recent[0].Description != "got clicked" {
+		t.Errorf("Expected events past the retention forgotten, got %+v", recent)
+	}
+}
+
+func TestDialogManager_SharedWorldContext(t *testing.T) {
+	restoreDeterminism(t)
+	clock := NewManualClock(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC))
+	SetClock(clock)
+
+	world, _ := NewWorldContext(WorldConfig{})
+	mochi, _ := newRateLimitTestManager(t, "Yum!", "Hi again!")
+	pip, _ := newRateLimitTestManager(t, "Hey, Mochi got a snack!")
+	mochi.SetWorldContext(world, "Mochi")
+	pip.SetWorldContext(world, "Pip")
+
+	var seen DialogContext
+	pip.Use(PreHook(func(context *DialogContext) (*DialogResponse, error) {
+		seen = *context
+		return nil, nil
+	}))
+
+	mochi.GenerateDialog(DialogContext{Trigger: "feed", InteractionID: "mochi"})
+	mochi.GenerateDialog(DialogContext{Trigger: "timer", InteractionID: "mochi"})
+	clock.Advance(2 * time.Minute)
+	pip.GenerateDialog(DialogContext{Trigger: "click", InteractionID: "pip"})
+
+	if len(seen.WorldEvents) != 1 || seen.WorldEvents[0].Character != "Mochi" || seen.WorldEvents[0].Description != "got fed" {
+		t.Fatalf("Expected Pip to hear that Mochi got fed, got %+v", seen.WorldEvents)
+	}
+	if recent := world.Recent("", 0); len(recent) != 2 || recent[1].Character != "Pip" || recent[1].Description != "got clicked" {
+		t.Errorf("Expected Pip's click recorded for the others, got %+v", recent)
+	}
+
+	pb := NewPromptBuilder()
+	pb.AddContext(seen)
+	if prompt := pb.Build(); !strings.Contains(prompt, "- Meanwhile, Mochi got fed (2 minutes ago)\n") {
+		t.Errorf("Expected the shared event in the prompt, got:\n%s", prompt)
+	}
+}